@@ -6,6 +6,8 @@ package main
 import (
 	"fmt"
 	"regexp"
+	"strings"
+	"time"
 
 	"os"
 	"path/filepath"
@@ -13,6 +15,7 @@ import (
 
 	"github.com/urfave/cli"
 
+	"github.com/SkycoinProject/cx-chains/src/cipher/bip39"
 	"github.com/SkycoinProject/cx-chains/src/fiber"
 	"github.com/SkycoinProject/cx-chains/src/util/logging"
 	"github.com/SkycoinProject/cx-chains/src/util/useragent"
@@ -34,6 +37,7 @@ func init() {
 	app.Version = Version
 	commands := cli.Commands{
 		createCoinCommand(),
+		genesisCommand(),
 	}
 
 	app.Commands = commands
@@ -216,6 +220,143 @@ func createCoinCommand() cli.Command {
 	}
 }
 
+func genesisCommand() cli.Command {
+	name := "genesis"
+	return cli.Command{
+		Name:  name,
+		Usage: "Generate a genesis block, a blockchain key pair and a chain parameters file for a new coin",
+		Description: `The chain parameters file can be loaded by a node with -chain-params, instead of
+   compiling the genesis block parameters into the node.
+
+   The blockchain key and the distribution addresses are generated from a seed.
+   A deterministic wallet created with the seed has the genesis address followed by the distribution addresses.
+   If no seed is given, a new seed is generated and printed. Keep it secret, like the chain parameters file
+   which has the blockchain secret key.`,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "coin",
+				Usage: "name of the coin",
+				Value: "skycoin",
+			},
+			cli.StringFlag{
+				Name:  "ticker",
+				Usage: "price ticker of the coin. Defaults to the uppercase coin name",
+			},
+			cli.StringFlag{
+				Name:  "seed",
+				Usage: "seed of the blockchain key and the distribution addresses. A new seed is generated if empty",
+			},
+			cli.Uint64Flag{
+				Name:  "max-coin-supply",
+				Usage: "number of coins created in the genesis block",
+				Value: 100e6,
+			},
+			cli.IntFlag{
+				Name:  "distribution-addresses",
+				Usage: "number of addresses that the genesis coins are distributed to. Must divide -max-coin-supply",
+				Value: 100,
+			},
+			cli.UintFlag{
+				Name:  "decimals",
+				Usage: "maximum number of decimals of the coins of a transaction",
+				Value: 3,
+			},
+			cli.Uint64Flag{
+				Name:  "timestamp",
+				Usage: "timestamp of the genesis block. Defaults to the current time",
+			},
+			cli.IntFlag{
+				Name:  "port",
+				Usage: "port of the wire protocol",
+				Value: 6000,
+			},
+			cli.IntFlag{
+				Name:  "web-interface-port",
+				Usage: "port of the web interface",
+				Value: 6420,
+			},
+			cli.StringFlag{
+				Name:  "output, o",
+				Usage: "chain parameters file path. Defaults to <coin>.toml",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			coinName := c.String("coin")
+			if err := validateCoinName(coinName); err != nil {
+				return err
+			}
+
+			ticker := c.String("ticker")
+			if ticker == "" {
+				ticker = strings.ToUpper(coinName)
+			}
+
+			seed := c.String("seed")
+			newSeed := seed == ""
+			if newSeed {
+				var err error
+				seed, err = bip39.NewDefaultMnemonic()
+				if err != nil {
+					return err
+				}
+			}
+
+			decimals := c.Uint("decimals")
+			if decimals > 255 {
+				return fmt.Errorf("invalid decimals %d", decimals)
+			}
+
+			timestamp := c.Uint64("timestamp")
+			if timestamp == 0 {
+				timestamp = uint64(time.Now().UTC().Unix())
+			}
+
+			output := c.String("output")
+			if output == "" {
+				output = coinName + ".toml"
+			}
+
+			g, err := fiber.NewGenesis(fiber.GenesisOptions{
+				CoinName:              coinName,
+				Ticker:                ticker,
+				Seed:                  seed,
+				MaxCoinSupply:         c.Uint64("max-coin-supply"),
+				DistributionAddresses: c.Int("distribution-addresses"),
+				MaxDecimals:           uint8(decimals),
+				GenesisTimestamp:      timestamp,
+				Port:                  c.Int("port"),
+				WebInterfacePort:      c.Int("web-interface-port"),
+			})
+			if err != nil {
+				return err
+			}
+
+			// The file has the blockchain secret key
+			f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+			if err != nil {
+				log.Errorf("failed to create chain parameters file %s", output)
+				return err
+			}
+			defer f.Close()
+
+			if err := g.Config.WriteTOML(f); err != nil {
+				log.Errorf("failed to write chain parameters file %s", output)
+				return err
+			}
+
+			if newSeed {
+				fmt.Fprintf(c.App.Writer, "seed: %s\n", seed)
+			}
+			fmt.Fprintf(c.App.Writer, "blockchain public key: %s\n", g.Pubkey.Hex())
+			fmt.Fprintf(c.App.Writer, "genesis address: %s\n", g.Address)
+			fmt.Fprintf(c.App.Writer, "genesis block hash: %s\n", g.Block.HashHeader().Hex())
+			fmt.Fprintf(c.App.Writer, "chain parameters file: %s\n", output)
+
+			return nil
+		},
+	}
+}
+
 func validateCoinName(s string) error {
 	x := regexp.MustCompile(fmt.Sprintf(`^%s$`, useragent.NamePattern))
 	if !x.MatchString(s) {
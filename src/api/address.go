@@ -2,9 +2,21 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/util/droplet"
+	wh "github.com/SkycoinProject/cx-chains/src/util/http"
+)
+
+const (
+	// defaultAddressTransactionsLimit is the page size of /api/v1/address/{addr}/transactions if limit is not specified
+	defaultAddressTransactionsLimit = 100
+	// maxAddressTransactionsLimit is the maximum page size of /api/v1/address/{addr}/transactions
+	maxAddressTransactionsLimit = 1000
 )
 
 // VerifyAddressRequest is the request data for POST /api/v2/address/verify
@@ -54,3 +66,112 @@ func addressVerifyHandler(w http.ResponseWriter, r *http.Request) {
 		},
 	})
 }
+
+// AddressTransaction is a confirmed transaction that touched an address
+type AddressTransaction struct {
+	BlockSeq uint64 `json:"block_seq"`
+	Txid     string `json:"txid"`
+	// Direction is "incoming" if the transaction did not decrease the address's coins, otherwise "outgoing"
+	Direction string `json:"direction"`
+	// Amount is the net change of the address's coins
+	Amount string `json:"amount"`
+}
+
+// AddressTransactionsResponse is returned by GET /api/v1/address/{addr}/transactions
+type AddressTransactionsResponse struct {
+	Address      string               `json:"address"`
+	Page         uint64               `json:"page"`
+	Limit        uint64               `json:"limit"`
+	Total        uint64               `json:"total"`
+	Transactions []AddressTransaction `json:"transactions"`
+}
+
+// addressHandler serves the endpoints under /api/v1/address/{addr}/
+// Method: GET
+// URI: /api/v1/address/{addr}/transactions
+func addressHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/address/"), "/")
+		if len(parts) != 2 || parts[1] != "transactions" {
+			wh.Error404(w, "")
+			return
+		}
+
+		addressTransactionsHandler(gateway, parts[0])(w, r)
+	}
+}
+
+// addressTransactionsHandler returns the confirmed transactions that touched an address, oldest first
+// Method: GET
+// URI: /api/v1/address/{addr}/transactions
+// Args:
+//	page [int] page number, starting from 1. Defaults to 1
+//	limit [int] transactions per page. Defaults to 100, at most 1000
+func addressTransactionsHandler(gateway Gatewayer, addrStr string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
+		addr, err := cipher.DecodeBase58Address(addrStr)
+		if err != nil {
+			wh.Error400(w, fmt.Sprintf("Invalid address %q: %v", addrStr, err))
+			return
+		}
+
+		page := uint64(1)
+		if s := r.FormValue("page"); s != "" {
+			page, err = strconv.ParseUint(s, 10, 64)
+			if err != nil || page == 0 {
+				wh.Error400(w, fmt.Sprintf("Invalid page value %q", s))
+				return
+			}
+		}
+
+		limit := uint64(defaultAddressTransactionsLimit)
+		if s := r.FormValue("limit"); s != "" {
+			limit, err = strconv.ParseUint(s, 10, 64)
+			if err != nil || limit == 0 || limit > maxAddressTransactionsLimit {
+				wh.Error400(w, fmt.Sprintf("Invalid limit value %q, must be between 1 and %d", s, maxAddressTransactionsLimit))
+				return
+			}
+		}
+
+		if page-1 > (^uint64(0))/limit {
+			wh.Error400(w, fmt.Sprintf("Invalid page value %d", page))
+			return
+		}
+
+		entries, total, err := gateway.GetAddressHistory(addr, (page-1)*limit, limit)
+		if err != nil {
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		rsp := AddressTransactionsResponse{
+			Address:      addr.String(),
+			Page:         page,
+			Limit:        limit,
+			Total:        total,
+			Transactions: make([]AddressTransaction, len(entries)),
+		}
+
+		for i, e := range entries {
+			amount, err := droplet.ToString(e.Coins)
+			if err != nil {
+				wh.Error500(w, err.Error())
+				return
+			}
+
+			rsp.Transactions[i] = AddressTransaction{
+				BlockSeq:  e.BkSeq,
+				Txid:      e.Txid.Hex(),
+				Direction: e.Direction.String(),
+				Amount:    amount,
+			}
+		}
+
+		wh.SendJSONOr500(logger, w, rsp)
+	}
+}
@@ -2,12 +2,16 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/blockdb"
 )
 
 func toJSON(t *testing.T, r interface{}) string {
@@ -146,3 +150,169 @@ func TestVerifyAddress(t *testing.T) {
 		})
 	}
 }
+
+func TestAddressTransactions(t *testing.T) {
+	addr := testutil.MakeAddress()
+	txid := testutil.RandSHA256(t)
+
+	entries := []blockdb.HistoryEntry{
+		{
+			BkSeq:     3,
+			Txid:      txid,
+			Direction: blockdb.HistoryIncoming,
+			Coins:     1e6,
+		},
+		{
+			BkSeq:     5,
+			Txid:      txid,
+			Direction: blockdb.HistoryOutgoing,
+			Coins:     1500,
+		},
+	}
+
+	tt := []struct {
+		name          string
+		method        string
+		path          string
+		status        int
+		err           string
+		offset        uint64
+		limit         uint64
+		historyResult []blockdb.HistoryEntry
+		historyTotal  uint64
+		historyErr    error
+		httpResponse  AddressTransactionsResponse
+	}{
+		{
+			name:   "405",
+			method: http.MethodPost,
+			path:   "/api/v1/address/" + addr.String() + "/transactions",
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+		{
+			name:   "404 - no address",
+			method: http.MethodGet,
+			path:   "/api/v1/address/",
+			status: http.StatusNotFound,
+			err:    "404 Not Found",
+		},
+		{
+			name:   "404 - unknown subpath",
+			method: http.MethodGet,
+			path:   "/api/v1/address/" + addr.String() + "/outputs",
+			status: http.StatusNotFound,
+			err:    "404 Not Found",
+		},
+		{
+			name:   "400 - invalid address",
+			method: http.MethodGet,
+			path:   "/api/v1/address/abcd/transactions",
+			status: http.StatusBadRequest,
+			err:    `400 Bad Request - Invalid address "abcd": Invalid address length`,
+		},
+		{
+			name:   "400 - invalid page",
+			method: http.MethodGet,
+			path:   "/api/v1/address/" + addr.String() + "/transactions?page=0",
+			status: http.StatusBadRequest,
+			err:    `400 Bad Request - Invalid page value "0"`,
+		},
+		{
+			name:   "400 - invalid limit",
+			method: http.MethodGet,
+			path:   "/api/v1/address/" + addr.String() + "/transactions?limit=1001",
+			status: http.StatusBadRequest,
+			err:    `400 Bad Request - Invalid limit value "1001", must be between 1 and 1000`,
+		},
+		{
+			name:   "400 - page overflows",
+			method: http.MethodGet,
+			path:   "/api/v1/address/" + addr.String() + "/transactions?page=18446744073709551615&limit=1000",
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - Invalid page value 18446744073709551615",
+		},
+		{
+			name:       "500 - gateway error",
+			method:     http.MethodGet,
+			path:       "/api/v1/address/" + addr.String() + "/transactions",
+			status:     http.StatusInternalServerError,
+			err:        "500 Internal Server Error - db error",
+			offset:     0,
+			limit:      100,
+			historyErr: errors.New("db error"),
+		},
+		{
+			name:          "200 - defaults",
+			method:        http.MethodGet,
+			path:          "/api/v1/address/" + addr.String() + "/transactions",
+			status:        http.StatusOK,
+			offset:        0,
+			limit:         100,
+			historyResult: entries,
+			historyTotal:  2,
+			httpResponse: AddressTransactionsResponse{
+				Address: addr.String(),
+				Page:    1,
+				Limit:   100,
+				Total:   2,
+				Transactions: []AddressTransaction{
+					{
+						BlockSeq:  3,
+						Txid:      txid.Hex(),
+						Direction: "incoming",
+						Amount:    "1.000000",
+					},
+					{
+						BlockSeq:  5,
+						Txid:      txid.Hex(),
+						Direction: "outgoing",
+						Amount:    "0.001500",
+					},
+				},
+			},
+		},
+		{
+			name:         "200 - past the last page",
+			method:       http.MethodGet,
+			path:         "/api/v1/address/" + addr.String() + "/transactions?page=3&limit=1",
+			status:       http.StatusOK,
+			offset:       2,
+			limit:        1,
+			historyTotal: 2,
+			httpResponse: AddressTransactionsResponse{
+				Address:      addr.String(),
+				Page:         3,
+				Limit:        1,
+				Total:        2,
+				Transactions: []AddressTransaction{},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetAddressHistory", addr, tc.offset, tc.limit).Return(tc.historyResult, tc.historyTotal, tc.historyErr)
+
+			req, err := http.NewRequest(tc.method, tc.path, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code, "got `%v` want `%v`", rr.Code, tc.status)
+
+			if rr.Code != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()))
+				return
+			}
+
+			var msg AddressTransactionsResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &msg)
+			require.NoError(t, err)
+			require.Equal(t, tc.httpResponse, msg)
+		})
+	}
+}
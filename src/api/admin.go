@@ -0,0 +1,249 @@
+package api
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+
+	wh "github.com/SkycoinProject/cx-chains/src/util/http"
+	"github.com/SkycoinProject/cx-chains/src/util/logging"
+	"github.com/SkycoinProject/cx-chains/src/visor"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+// LogLevels are the minimum log levels of the logger and of each module logger
+type LogLevels struct {
+	// Level is used by the modules without a level override
+	Level   string            `json:"level"`
+	Modules map[string]string `json:"modules"`
+}
+
+func newLogLevels() LogLevels {
+	modules := logging.ModuleLevels()
+	levels := LogLevels{
+		Level:   logging.Level().String(),
+		Modules: make(map[string]string, len(modules)),
+	}
+	for m, l := range modules {
+		levels.Modules[m] = l.String()
+	}
+	return levels
+}
+
+// logLevelHandler returns or changes the log levels at runtime
+// URI: /api/v1/admin/loglevel
+// Method: GET, POST
+// Args:
+//	module: module to change the level of, e.g. visor. If not provided, the level of all modules without an override is changed [POST]
+//	level: debug, info, warn, error, fatal or panic [POST]
+func logLevelHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		levelStr := r.FormValue("level")
+		if levelStr == "" {
+			wh.Error400(w, "level is required")
+			return
+		}
+
+		level, err := logging.LevelFromString(levelStr)
+		if err != nil {
+			wh.Error400(w, fmt.Sprintf("invalid level: %v", err))
+			return
+		}
+
+		module := r.FormValue("module")
+		if module == "" {
+			logging.SetLevel(level)
+		} else {
+			if _, ok := logging.ModuleLevels()[module]; !ok {
+				wh.Error400(w, fmt.Sprintf("unknown module %q", module))
+				return
+			}
+			logging.SetModuleLevel(module, level)
+		}
+
+		logger.Infof("Log level of %q changed to %s", module, level)
+	default:
+		wh.Error405(w)
+		return
+	}
+
+	wh.SendJSONOr500(logger, w, newLogLevels())
+}
+
+// countingWriter counts the bytes written to a writer
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// backupHandler streams a consistent copy of the database while the node keeps running
+// URI: /api/v1/admin/backup
+// Method: GET
+// Args:
+//	gzip: compress the copy with gzip [optional]
+func backupHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
+		compress, err := parseBoolFlag(r.FormValue("gzip"))
+		if err != nil {
+			wh.Error400(w, "Invalid value for gzip")
+			return
+		}
+
+		filename := "data.db"
+		contentType := "application/octet-stream"
+		if compress {
+			filename += ".gz"
+			contentType = "application/gzip"
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+		cw := &countingWriter{w: w}
+		var out io.Writer = cw
+		var gz *gzip.Writer
+		if compress {
+			gz = gzip.NewWriter(cw)
+			out = gz
+		}
+
+		n, err := gateway.BackupDB(out)
+		if err == nil && gz != nil {
+			err = gz.Close()
+		}
+		if err != nil {
+			// The response status can only be set if nothing has been written yet
+			if cw.n == 0 {
+				wh.Error500(w, err.Error())
+				return
+			}
+
+			logger.WithError(err).Errorf("Database backup failed after writing %d bytes", cw.n)
+			return
+		}
+
+		logger.Infof("Database backup of %d bytes written", n)
+	}
+}
+
+// DBStats is a report of the size of the database file and of the free space in it
+type DBStats struct {
+	FileSize      int64           `json:"file_size"`
+	PageSize      int             `json:"page_size"`
+	FreePages     int             `json:"free_pages"`
+	PendingPages  int             `json:"pending_pages"`
+	FreeBytes     int             `json:"free_bytes"`
+	FreelistBytes int             `json:"freelist_bytes"`
+	Buckets       []DBBucketStats `json:"buckets"`
+}
+
+// DBBucketStats is a report of the size of a database bucket
+type DBBucketStats struct {
+	Name       string `json:"name"`
+	Keys       int    `json:"keys"`
+	Depth      int    `json:"depth"`
+	AllocBytes int    `json:"alloc_bytes"`
+	InuseBytes int    `json:"inuse_bytes"`
+}
+
+// NewDBStats creates a DBStats from dbutil.DBStats
+func NewDBStats(s dbutil.DBStats) DBStats {
+	buckets := make([]DBBucketStats, len(s.Buckets))
+	for i, b := range s.Buckets {
+		buckets[i] = DBBucketStats{
+			Name:       b.Name,
+			Keys:       b.Keys,
+			Depth:      b.Depth,
+			AllocBytes: b.AllocBytes,
+			InuseBytes: b.InuseBytes,
+		}
+	}
+
+	return DBStats{
+		FileSize:      s.FileSize,
+		PageSize:      s.PageSize,
+		FreePages:     s.FreePages,
+		PendingPages:  s.PendingPages,
+		FreeBytes:     s.FreeBytes,
+		FreelistBytes: s.FreelistBytes,
+		Buckets:       buckets,
+	}
+}
+
+// CompactDBResponse is returned by POST /api/v1/admin/compactdb
+type CompactDBResponse struct {
+	Before   DBStats     `json:"before"`
+	After    DBStats     `json:"after"`
+	Duration wh.Duration `json:"duration"`
+}
+
+// dbStatsHandler returns a report of the size of the database file, of its free pages and of its buckets
+// URI: /api/v1/admin/dbstats
+// Method: GET
+func dbStatsHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
+		stats, err := gateway.DBStats()
+		if err != nil {
+			switch err {
+			case dbutil.ErrNotBoltStorage:
+				wh.Error403(w, err.Error())
+			default:
+				wh.Error500(w, err.Error())
+			}
+			return
+		}
+
+		wh.SendJSONOr500(logger, w, NewDBStats(stats))
+	}
+}
+
+// compactDBHandler copies the database into a fresh file without its free pages and swaps it in place
+// of the database file, while the node keeps running. Reads and writes wait until it is done.
+// URI: /api/v1/admin/compactdb
+// Method: POST
+func compactDBHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			wh.Error405(w)
+			return
+		}
+
+		res, err := gateway.CompactDB()
+		if err != nil {
+			switch err {
+			case dbutil.ErrNotBoltStorage, dbutil.ErrCompactReadOnly:
+				wh.Error403(w, err.Error())
+			case visor.ErrVisorShutdown:
+				wh.Error503(w, err.Error())
+			default:
+				wh.Error500(w, err.Error())
+			}
+			return
+		}
+
+		wh.SendJSONOr500(logger, w, CompactDBResponse{
+			Before:   NewDBStats(res.Before),
+			After:    NewDBStats(res.After),
+			Duration: wh.FromDuration(res.Duration),
+		})
+	}
+}
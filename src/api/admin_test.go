@@ -0,0 +1,456 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	wh "github.com/SkycoinProject/cx-chains/src/util/http"
+	"github.com/SkycoinProject/cx-chains/src/util/logging"
+	"github.com/SkycoinProject/cx-chains/src/visor"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+func TestLogLevelHandler(t *testing.T) {
+	defaultLevel := logging.Level()
+	defer logging.SetLevel(defaultLevel)
+	defer logging.SetModuleLevel("api", logging.ModuleLevels()["api"])
+
+	tt := []struct {
+		name          string
+		method        string
+		status        int
+		err           string
+		module        string
+		level         string
+		expectLevel   string
+		expectModules map[string]string
+	}{
+		{
+			name:   "405",
+			method: http.MethodPut,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+		{
+			name:   "400 - missing level",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - level is required",
+			module: "api",
+		},
+		{
+			name:   "400 - invalid level",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - invalid level: could not convert string to log level",
+			module: "api",
+			level:  "foo",
+		},
+		{
+			name:   "400 - unknown module",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			err:    `400 Bad Request - unknown module "foo"`,
+			module: "foo",
+			level:  "debug",
+		},
+		{
+			name:        "200 - set default level",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			level:       "error",
+			expectLevel: "error",
+			expectModules: map[string]string{
+				"api": "error",
+			},
+		},
+		{
+			name:        "200 - set module level",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			module:      "api",
+			level:       "debug",
+			expectLevel: "error",
+			expectModules: map[string]string{
+				"api": "debug",
+			},
+		},
+		{
+			name:        "200 - module level is kept when the default level changes",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			level:       "warn",
+			expectLevel: "warning",
+			expectModules: map[string]string{
+				"api": "debug",
+			},
+		},
+		{
+			name:        "200 - get",
+			method:      http.MethodGet,
+			status:      http.StatusOK,
+			expectLevel: "warning",
+			expectModules: map[string]string{
+				"api": "debug",
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			v := url.Values{}
+			if tc.module != "" {
+				v.Add("module", tc.module)
+			}
+			if tc.level != "" {
+				v.Add("level", tc.level)
+			}
+
+			endpoint := "/api/v1/admin/loglevel"
+			if len(v) > 0 {
+				endpoint += "?" + v.Encode()
+			}
+
+			req, err := http.NewRequest(tc.method, endpoint, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), &MockGatewayer{})
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			if status != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()))
+				return
+			}
+
+			var msg LogLevels
+			err = json.Unmarshal(rr.Body.Bytes(), &msg)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectLevel, msg.Level)
+			for m, l := range tc.expectModules {
+				require.Equal(t, l, msg.Modules[m])
+			}
+
+			_, ok := msg.Modules["api"]
+			require.True(t, ok)
+		})
+	}
+
+	require.Equal(t, logrus.WarnLevel, logging.Level())
+}
+
+func TestBackupHandler(t *testing.T) {
+	data := []byte("boltdb database file contents")
+
+	tt := []struct {
+		name        string
+		method      string
+		gzip        string
+		status      int
+		err         string
+		backupErr   error
+		contentType string
+		filename    string
+	}{
+		{
+			name:   "405",
+			method: http.MethodPost,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+		{
+			name:   "400 - invalid gzip",
+			method: http.MethodGet,
+			gzip:   "foo",
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - Invalid value for gzip",
+		},
+		{
+			name:      "500 - backup failed",
+			method:    http.MethodGet,
+			status:    http.StatusInternalServerError,
+			err:       "500 Internal Server Error - database not open",
+			backupErr: errors.New("database not open"),
+		},
+		{
+			name:        "200",
+			method:      http.MethodGet,
+			status:      http.StatusOK,
+			contentType: "application/octet-stream",
+			filename:    "data.db",
+		},
+		{
+			name:        "200 - gzip",
+			method:      http.MethodGet,
+			gzip:        "true",
+			status:      http.StatusOK,
+			contentType: "application/gzip",
+			filename:    "data.db.gz",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			call := gateway.On("BackupDB", mock.Anything)
+			if tc.backupErr != nil {
+				call.Return(int64(0), tc.backupErr)
+			} else {
+				call.Run(func(args mock.Arguments) {
+					_, err := args.Get(0).(io.Writer).Write(data)
+					require.NoError(t, err)
+				}).Return(int64(len(data)), nil)
+			}
+
+			endpoint := "/api/v1/admin/backup"
+			if tc.gzip != "" {
+				endpoint += "?gzip=" + tc.gzip
+			}
+
+			req, err := http.NewRequest(tc.method, endpoint, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			if status != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()))
+				return
+			}
+
+			require.Equal(t, tc.contentType, rr.Header().Get("Content-Type"))
+			require.Equal(t, `attachment; filename="`+tc.filename+`"`, rr.Header().Get("Content-Disposition"))
+
+			body := rr.Body.Bytes()
+			if tc.gzip != "" {
+				r, err := gzip.NewReader(bytes.NewReader(body))
+				require.NoError(t, err)
+				body, err = ioutil.ReadAll(r)
+				require.NoError(t, err)
+			}
+			require.Equal(t, data, body)
+		})
+	}
+}
+
+func TestDBStatsHandler(t *testing.T) {
+	stats := dbutil.DBStats{
+		FileSize:      1 << 20,
+		PageSize:      4096,
+		FreePages:     100,
+		PendingPages:  2,
+		FreeBytes:     102 * 4096,
+		FreelistBytes: 824,
+		Buckets: []dbutil.BucketStats{
+			{
+				Name:       "blocks",
+				Keys:       10,
+				Depth:      2,
+				AllocBytes: 8192,
+				InuseBytes: 5000,
+			},
+		},
+	}
+
+	tt := []struct {
+		name     string
+		method   string
+		status   int
+		err      string
+		statsErr error
+		response DBStats
+	}{
+		{
+			name:   "405",
+			method: http.MethodPost,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+		{
+			name:     "403 - not a bolt database",
+			method:   http.MethodGet,
+			status:   http.StatusForbidden,
+			err:      "403 Forbidden - Database is not stored in a bolt file",
+			statsErr: dbutil.ErrNotBoltStorage,
+		},
+		{
+			name:     "500 - stats failed",
+			method:   http.MethodGet,
+			status:   http.StatusInternalServerError,
+			err:      "500 Internal Server Error - database not open",
+			statsErr: errors.New("database not open"),
+		},
+		{
+			name:   "200",
+			method: http.MethodGet,
+			status: http.StatusOK,
+			response: DBStats{
+				FileSize:      1 << 20,
+				PageSize:      4096,
+				FreePages:     100,
+				PendingPages:  2,
+				FreeBytes:     102 * 4096,
+				FreelistBytes: 824,
+				Buckets: []DBBucketStats{
+					{
+						Name:       "blocks",
+						Keys:       10,
+						Depth:      2,
+						AllocBytes: 8192,
+						InuseBytes: 5000,
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("DBStats").Return(stats, tc.statsErr)
+
+			req, err := http.NewRequest(tc.method, "/api/v1/admin/dbstats", nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			if status != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()))
+				return
+			}
+
+			var msg DBStats
+			err = json.Unmarshal(rr.Body.Bytes(), &msg)
+			require.NoError(t, err)
+			require.Equal(t, tc.response, msg)
+		})
+	}
+}
+
+func TestCompactDBHandler(t *testing.T) {
+	res := dbutil.CompactResult{
+		Before: dbutil.DBStats{
+			FileSize:  1 << 20,
+			PageSize:  4096,
+			FreePages: 200,
+			FreeBytes: 200 * 4096,
+			Buckets:   []dbutil.BucketStats{},
+		},
+		After: dbutil.DBStats{
+			FileSize: 1 << 18,
+			PageSize: 4096,
+			Buckets:  []dbutil.BucketStats{},
+		},
+		Duration: time.Second,
+	}
+
+	tt := []struct {
+		name       string
+		method     string
+		status     int
+		err        string
+		compactErr error
+		response   CompactDBResponse
+	}{
+		{
+			name:   "405",
+			method: http.MethodGet,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+		{
+			name:       "403 - read-only database",
+			method:     http.MethodPost,
+			status:     http.StatusForbidden,
+			err:        "403 Forbidden - Can't compact a database opened read-only",
+			compactErr: dbutil.ErrCompactReadOnly,
+		},
+		{
+			name:       "503 - visor shut down",
+			method:     http.MethodPost,
+			status:     http.StatusServiceUnavailable,
+			err:        "503 Service Unavailable - Visor is shut down",
+			compactErr: visor.ErrVisorShutdown,
+		},
+		{
+			name:       "500 - compaction failed",
+			method:     http.MethodPost,
+			status:     http.StatusInternalServerError,
+			err:        "500 Internal Server Error - Replace database file failed",
+			compactErr: errors.New("Replace database file failed"),
+		},
+		{
+			name:   "200",
+			method: http.MethodPost,
+			status: http.StatusOK,
+			response: CompactDBResponse{
+				Before: DBStats{
+					FileSize:  1 << 20,
+					PageSize:  4096,
+					FreePages: 200,
+					FreeBytes: 200 * 4096,
+					Buckets:   []DBBucketStats{},
+				},
+				After: DBStats{
+					FileSize: 1 << 18,
+					PageSize: 4096,
+					Buckets:  []DBBucketStats{},
+				},
+				Duration: wh.FromDuration(time.Second),
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("CompactDB").Return(res, tc.compactErr)
+
+			req, err := http.NewRequest(tc.method, "/api/v1/admin/compactdb", nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			if status != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()))
+				return
+			}
+
+			var msg CompactDBResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &msg)
+			require.NoError(t, err)
+			require.Equal(t, tc.response, msg)
+		})
+	}
+}
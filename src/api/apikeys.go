@@ -0,0 +1,146 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+)
+
+const (
+	// APIKeyHeaderName is the name of the header carrying an API key
+	APIKeyHeaderName = "X-API-Key"
+
+	// APIKeyScopeRead allows an API key to access the READ, STATUS and PROMETHEUS API sets
+	APIKeyScopeRead = "read"
+	// APIKeyScopeWallet allows an API key to access the WALLET, TXN and STORAGE API sets
+	APIKeyScopeWallet = "wallet"
+	// APIKeyScopeAdmin allows an API key to access the NET_CTRL and ADMIN API sets
+	APIKeyScopeAdmin = "admin"
+)
+
+// apiKeyScopeAPISets maps API key scopes to the API sets they can access
+var apiKeyScopeAPISets = map[string][]string{
+	APIKeyScopeRead:   []string{EndpointsRead, EndpointsStatus, EndpointsPrometheus},
+	APIKeyScopeWallet: []string{EndpointsWallet, EndpointsTransaction, EndpointsStorage},
+	APIKeyScopeAdmin:  []string{EndpointsNetCtrl, EndpointsAdmin},
+}
+
+// APIKey authenticates requests from automated services, which do not need a CSRF token.
+// The requests of an API key can only access the endpoints of the API sets of its scopes.
+type APIKey struct {
+	Key    string
+	Scopes []string
+}
+
+// ParseAPIKeys parses a comma-separated list of API keys of the form <key>:<scope>[+<scope>...],
+// e.g. "a1b2c3:read+wallet,d4e5f6:admin". Scopes are read, wallet and admin.
+func ParseAPIKeys(s string) ([]APIKey, error) {
+	var keys []APIKey
+	seen := make(map[string]struct{})
+
+	for _, k := range strings.Split(s, ",") {
+		k = strings.TrimSpace(k)
+		if k == "" {
+			continue
+		}
+
+		pts := strings.Split(k, ":")
+		if len(pts) != 2 || pts[0] == "" || pts[1] == "" {
+			return nil, fmt.Errorf("Invalid API key %q, must be of the form <key>:<scope>[+<scope>...]", k)
+		}
+
+		if _, ok := seen[pts[0]]; ok {
+			return nil, fmt.Errorf("Duplicate API key %q", pts[0])
+		}
+		seen[pts[0]] = struct{}{}
+
+		scopes := strings.Split(pts[1], "+")
+		for _, s := range scopes {
+			if _, ok := apiKeyScopeAPISets[s]; !ok {
+				return nil, fmt.Errorf("Invalid API key scope %q, must be one of %s", s, strings.Join(apiKeyScopes(), ", "))
+			}
+		}
+
+		keys = append(keys, APIKey{
+			Key:    pts[0],
+			Scopes: scopes,
+		})
+	}
+
+	return keys, nil
+}
+
+// apiKeys maps the hashes of the API keys to the API sets they can access
+type apiKeys map[cipher.SHA256]map[string]struct{}
+
+func newAPIKeys(keys []APIKey) apiKeys {
+	k := make(apiKeys, len(keys))
+	for _, key := range keys {
+		apiSets := make(map[string]struct{})
+		for _, s := range key.Scopes {
+			for _, a := range apiKeyScopeAPISets[s] {
+				apiSets[a] = struct{}{}
+			}
+		}
+
+		k[cipher.SumSHA256([]byte(key.Key))] = apiSets
+	}
+
+	return k
+}
+
+// apiSets returns the API sets an API key can access
+func (k apiKeys) apiSets(key string) (map[string]struct{}, bool) {
+	apiSets, ok := k[cipher.SumSHA256([]byte(key))]
+	return apiSets, ok
+}
+
+// apiKeyScopes returns the sorted names of the scopes
+func apiKeyScopes() []string {
+	scopes := make([]string, 0, len(apiKeyScopeAPISets))
+	for s := range apiKeyScopeAPISets {
+		scopes = append(scopes, s)
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// apiKeyCheck serves requests with an API key header by keyHandler, and the other requests by handler.
+// keyHandler does not check the CSRF token nor the other authentication of the request.
+// If methodAPISets is not nil, the API key must be allowed to access one of the API sets of the request method.
+func apiKeyCheck(apiVersion string, keys apiKeys, methodAPISets map[string][]string, keyHandler, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(APIKeyHeaderName)
+		if key == "" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		allowed, ok := keys.apiSets(key)
+		if !ok {
+			writeError(w, apiVersion, http.StatusUnauthorized, "Invalid API key")
+			return
+		}
+
+		// Let the API set check of the endpoint return 405 Method Not Allowed for unsupported methods
+		if apiSets := methodAPISets[r.Method]; methodAPISets != nil && len(apiSets) > 0 {
+			permitted := false
+			for _, a := range apiSets {
+				if _, ok := allowed[a]; ok {
+					permitted = true
+					break
+				}
+			}
+
+			if !permitted {
+				writeError(w, apiVersion, http.StatusForbidden, "API key scope does not allow this endpoint")
+				return
+			}
+		}
+
+		keyHandler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAPIKeys(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  string
+		keys   []APIKey
+		errMsg string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:  "one key",
+			input: "abc:read",
+			keys: []APIKey{
+				{Key: "abc", Scopes: []string{APIKeyScopeRead}},
+			},
+		},
+		{
+			name:  "multiple keys and scopes",
+			input: "abc:read+wallet, def:admin,",
+			keys: []APIKey{
+				{Key: "abc", Scopes: []string{APIKeyScopeRead, APIKeyScopeWallet}},
+				{Key: "def", Scopes: []string{APIKeyScopeAdmin}},
+			},
+		},
+		{
+			name:   "missing scope",
+			input:  "abc",
+			errMsg: `Invalid API key "abc", must be of the form <key>:<scope>[+<scope>...]`,
+		},
+		{
+			name:   "empty key",
+			input:  ":read",
+			errMsg: `Invalid API key ":read", must be of the form <key>:<scope>[+<scope>...]`,
+		},
+		{
+			name:   "invalid scope",
+			input:  "abc:read+root",
+			errMsg: `Invalid API key scope "root", must be one of admin, read, wallet`,
+		},
+		{
+			name:   "duplicate key",
+			input:  "abc:read,abc:wallet",
+			errMsg: `Duplicate API key "abc"`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			keys, err := ParseAPIKeys(tc.input)
+			if tc.errMsg != "" {
+				require.EqualError(t, err, tc.errMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.keys, keys)
+		})
+	}
+}
+
+func TestAPIKeyCheck(t *testing.T) {
+	keys := []APIKey{
+		{Key: "walletkey", Scopes: []string{APIKeyScopeWallet}},
+		{Key: "readkey", Scopes: []string{APIKeyScopeRead}},
+	}
+
+	cases := []struct {
+		name   string
+		apiKey string
+		auth   bool
+		status int
+		body   string
+	}{
+		{
+			name:   "no API key, CSRF token required",
+			status: http.StatusForbidden,
+			body:   "403 Forbidden - invalid CSRF token\n",
+		},
+		{
+			name:   "wallet scope, CSRF token not required",
+			apiKey: "walletkey",
+			status: http.StatusBadRequest,
+			body:   "400 Bad Request - rawtx is required\n",
+		},
+		{
+			name:   "no API key, auth required",
+			auth:   true,
+			status: http.StatusUnauthorized,
+			body:   "401 Unauthorized\n",
+		},
+		{
+			name:   "wallet scope, auth not required",
+			apiKey: "walletkey",
+			auth:   true,
+			status: http.StatusBadRequest,
+			body:   "400 Bad Request - rawtx is required\n",
+		},
+		{
+			name:   "read scope not allowed",
+			apiKey: "readkey",
+			status: http.StatusForbidden,
+			body:   "403 Forbidden - API key scope does not allow this endpoint\n",
+		},
+		{
+			name:   "invalid API key",
+			apiKey: "badkey",
+			status: http.StatusUnauthorized,
+			body:   "401 Unauthorized - Invalid API key\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/injectTransaction", bytes.NewBufferString("{}"))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+			if tc.apiKey != "" {
+				req.Header.Set(APIKeyHeaderName, tc.apiKey)
+			}
+
+			cfg := defaultMuxConfig()
+			cfg.disableCSRF = false
+			cfg.apiKeys = newAPIKeys(keys)
+			if tc.auth {
+				cfg.username = "foo"
+				cfg.password = "bar"
+			}
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(cfg, gateway)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+			require.Equal(t, tc.body, rr.Body.String())
+		})
+	}
+}
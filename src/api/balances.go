@@ -0,0 +1,193 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/readable"
+	wh "github.com/SkycoinProject/cx-chains/src/util/http"
+	"github.com/SkycoinProject/cx-chains/src/visor"
+	"github.com/SkycoinProject/cx-chains/src/visor/blockdb"
+)
+
+const (
+	// maxBalancesAddresses is the maximum number of addresses of a POST /api/v1/balances request
+	maxBalancesAddresses = 10000
+	// defaultBalancesLimit is the page size of POST /api/v1/balances if limit is not specified
+	defaultBalancesLimit = 1000
+)
+
+// BalancesRequest is the request data for POST /api/v1/balances
+type BalancesRequest struct {
+	Addresses []string `json:"addresses"`
+	// MinConfirmations is the number of confirmations of a confirmed output. Defaults to 1
+	MinConfirmations uint64 `json:"min_confirmations"`
+	// Page is the page number of the addresses, starting from 1. Defaults to 1
+	Page uint64 `json:"page"`
+	// Limit is the number of addresses per page. Defaults to 1000
+	Limit uint64 `json:"limit"`
+	// Block is the seq of the block after which the balances are read. Defaults to the head block.
+	// The balances at a block are only confirmed balances, and min_confirmations is ignored
+	Block *uint64 `json:"block,omitempty"`
+}
+
+// AddressBalance is the balance of an address returned by POST /api/v1/balances
+type AddressBalance struct {
+	Address     string           `json:"address"`
+	Confirmed   readable.Balance `json:"confirmed"`
+	Unconfirmed readable.Balance `json:"unconfirmed"`
+	Spendable   readable.Balance `json:"spendable"`
+}
+
+// BalancesResponse is returned by POST /api/v1/balances
+type BalancesResponse struct {
+	// HeadSeq is the head block seq that the balances were read at, or the requested block seq
+	HeadSeq          uint64           `json:"head_seq"`
+	MinConfirmations uint64           `json:"min_confirmations"`
+	Page             uint64           `json:"page"`
+	Limit            uint64           `json:"limit"`
+	Total            uint64           `json:"total"`
+	Addresses        []AddressBalance `json:"addresses"`
+}
+
+// balancesHandler returns the confirmed, unconfirmed and spendable balances of a page of addresses.
+// The confirmed balance is the balance of the unspent outputs with at least min_confirmations confirmations.
+// The unconfirmed balance is the balance of the other unspent outputs and of the outputs created by unconfirmed transactions.
+// The spendable balance is the confirmed balance minus the outputs spent by unconfirmed transactions.
+// If block is set, the confirmed and spendable balances are the balances after the block with that seq was executed,
+// with the coin hours at the time of the block, and the unconfirmed balance is zero.
+// Method: POST
+// URI: /api/v1/balances
+// Args: JSON body, see BalancesRequest
+func balancesHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			wh.Error405(w)
+			return
+		}
+
+		if !isContentTypeJSON(r.Header.Get("Content-Type")) {
+			wh.Error415(w)
+			return
+		}
+
+		var req BalancesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			wh.Error400(w, err.Error())
+			return
+		}
+
+		if len(req.Addresses) == 0 {
+			wh.Error400(w, "addresses is required")
+			return
+		}
+
+		if len(req.Addresses) > maxBalancesAddresses {
+			wh.Error400(w, fmt.Sprintf("too many addresses, at most %d are allowed", maxBalancesAddresses))
+			return
+		}
+
+		addrs := make([]cipher.Address, len(req.Addresses))
+		seen := make(map[cipher.Address]struct{}, len(req.Addresses))
+		for i, s := range req.Addresses {
+			addr, err := cipher.DecodeBase58Address(s)
+			if err != nil {
+				wh.Error400(w, fmt.Sprintf("Invalid address %q: %v", s, err))
+				return
+			}
+
+			if _, ok := seen[addr]; ok {
+				wh.Error400(w, fmt.Sprintf("Duplicate address %q", s))
+				return
+			}
+			seen[addr] = struct{}{}
+
+			addrs[i] = addr
+		}
+
+		if req.MinConfirmations == 0 {
+			req.MinConfirmations = 1
+		}
+
+		if req.Page == 0 {
+			req.Page = 1
+		}
+
+		if req.Limit == 0 {
+			req.Limit = defaultBalancesLimit
+		} else if req.Limit > maxBalancesAddresses {
+			wh.Error400(w, fmt.Sprintf("Invalid limit value %d, must be between 1 and %d", req.Limit, maxBalancesAddresses))
+			return
+		}
+
+		if req.Page-1 > (^uint64(0))/req.Limit {
+			wh.Error400(w, fmt.Sprintf("Invalid page value %d", req.Page))
+			return
+		}
+
+		start := (req.Page - 1) * req.Limit
+		if start > uint64(len(addrs)) {
+			start = uint64(len(addrs))
+		}
+		end := start + req.Limit
+		if end > uint64(len(addrs)) {
+			end = uint64(len(addrs))
+		}
+		page := addrs[start:end]
+
+		var bals []visor.AddressBalance
+		var headSeq uint64
+		if req.Block != nil {
+			confirmed, err := gateway.GetBalancesOfAddrsAtSeq(page, *req.Block)
+			if err != nil {
+				switch err {
+				case blockdb.ErrSeqAfterHead:
+					wh.Error404(w, err.Error())
+				default:
+					err = fmt.Errorf("gateway.GetBalancesOfAddrsAtSeq failed: %v", err)
+					wh.Error500(w, err.Error())
+				}
+				return
+			}
+
+			bals = make([]visor.AddressBalance, len(confirmed))
+			for i, b := range confirmed {
+				bals[i] = visor.AddressBalance{
+					Confirmed: b,
+					Spendable: b,
+				}
+			}
+			headSeq = *req.Block
+		} else {
+			var err error
+			bals, headSeq, err = gateway.GetBalancesOfAddrs(page, req.MinConfirmations)
+			if err != nil {
+				err = fmt.Errorf("gateway.GetBalancesOfAddrs failed: %v", err)
+				wh.Error500(w, err.Error())
+				return
+			}
+		}
+
+		rsp := BalancesResponse{
+			HeadSeq:          headSeq,
+			MinConfirmations: req.MinConfirmations,
+			Page:             req.Page,
+			Limit:            req.Limit,
+			Total:            uint64(len(addrs)),
+			Addresses:        make([]AddressBalance, len(page)),
+		}
+
+		for i, addr := range page {
+			rsp.Addresses[i] = AddressBalance{
+				Address:     addr.String(),
+				Confirmed:   readable.NewBalance(bals[i].Confirmed),
+				Unconfirmed: readable.NewBalance(bals[i].Unconfirmed),
+				Spendable:   readable.NewBalance(bals[i].Spendable),
+			}
+		}
+
+		wh.SendJSONOr500(logger, w, rsp)
+	}
+}
@@ -0,0 +1,260 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/readable"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+	"github.com/SkycoinProject/cx-chains/src/visor"
+	"github.com/SkycoinProject/cx-chains/src/visor/blockdb"
+	"github.com/SkycoinProject/cx-chains/src/wallet"
+)
+
+func TestBalances(t *testing.T) {
+	addr1 := testutil.MakeAddress()
+	addr2 := testutil.MakeAddress()
+	addr3 := testutil.MakeAddress()
+
+	bal1 := visor.AddressBalance{
+		Confirmed:   wallet.NewBalance(10e6, 100),
+		Unconfirmed: wallet.NewBalance(1e6, 2),
+		Spendable:   wallet.NewBalance(4e6, 40),
+	}
+	bal2 := visor.AddressBalance{
+		Confirmed: wallet.NewBalance(3e6, 7),
+		Spendable: wallet.NewBalance(3e6, 7),
+	}
+
+	tt := []struct {
+		name             string
+		method           string
+		contentType      string
+		body             string
+		status           int
+		err              string
+		addrs            []cipher.Address
+		minConfirmations uint64
+		gatewayResult    []visor.AddressBalance
+		gatewayErr       error
+		blockSeq         uint64
+		blockResult      []wallet.Balance
+		blockErr         error
+		httpResponse     BalancesResponse
+	}{
+		{
+			name:   "405",
+			method: http.MethodGet,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+		{
+			name:        "415",
+			method:      http.MethodPost,
+			contentType: ContentTypeForm,
+			status:      http.StatusUnsupportedMediaType,
+			err:         "415 Unsupported Media Type",
+		},
+		{
+			name:   "400 - no addresses",
+			method: http.MethodPost,
+			body:   `{}`,
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - addresses is required",
+		},
+		{
+			name:   "400 - invalid address",
+			method: http.MethodPost,
+			body:   `{"addresses":["abcd"]}`,
+			status: http.StatusBadRequest,
+			err:    `400 Bad Request - Invalid address "abcd": Invalid address length`,
+		},
+		{
+			name:   "400 - duplicate address",
+			method: http.MethodPost,
+			body:   fmt.Sprintf(`{"addresses":["%s","%s"]}`, addr1, addr1),
+			status: http.StatusBadRequest,
+			err:    fmt.Sprintf(`400 Bad Request - Duplicate address "%s"`, addr1),
+		},
+		{
+			name:   "400 - invalid limit",
+			method: http.MethodPost,
+			body:   fmt.Sprintf(`{"addresses":["%s"],"limit":10001}`, addr1),
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - Invalid limit value 10001, must be between 1 and 10000",
+		},
+		{
+			name:   "400 - page overflows",
+			method: http.MethodPost,
+			body:   fmt.Sprintf(`{"addresses":["%s"],"page":18446744073709551615,"limit":2}`, addr1),
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - Invalid page value 18446744073709551615",
+		},
+		{
+			name:             "500 - gateway error",
+			method:           http.MethodPost,
+			body:             fmt.Sprintf(`{"addresses":["%s"]}`, addr1),
+			status:           http.StatusInternalServerError,
+			err:              "500 Internal Server Error - gateway.GetBalancesOfAddrs failed: db error",
+			addrs:            []cipher.Address{addr1},
+			minConfirmations: 1,
+			gatewayErr:       errors.New("db error"),
+		},
+		{
+			name:             "200 - defaults",
+			method:           http.MethodPost,
+			body:             fmt.Sprintf(`{"addresses":["%s","%s"]}`, addr1, addr2),
+			status:           http.StatusOK,
+			addrs:            []cipher.Address{addr1, addr2},
+			minConfirmations: 1,
+			gatewayResult:    []visor.AddressBalance{bal1, bal2},
+			httpResponse: BalancesResponse{
+				HeadSeq:          12,
+				MinConfirmations: 1,
+				Page:             1,
+				Limit:            1000,
+				Total:            2,
+				Addresses: []AddressBalance{
+					{
+						Address:     addr1.String(),
+						Confirmed:   readable.Balance{Coins: 10e6, Hours: 100},
+						Unconfirmed: readable.Balance{Coins: 1e6, Hours: 2},
+						Spendable:   readable.Balance{Coins: 4e6, Hours: 40},
+					},
+					{
+						Address:   addr2.String(),
+						Confirmed: readable.Balance{Coins: 3e6, Hours: 7},
+						Spendable: readable.Balance{Coins: 3e6, Hours: 7},
+					},
+				},
+			},
+		},
+		{
+			name:             "200 - page",
+			method:           http.MethodPost,
+			body:             fmt.Sprintf(`{"addresses":["%s","%s","%s"],"min_confirmations":6,"page":2,"limit":2}`, addr1, addr2, addr3),
+			status:           http.StatusOK,
+			addrs:            []cipher.Address{addr3},
+			minConfirmations: 6,
+			gatewayResult:    []visor.AddressBalance{bal2},
+			httpResponse: BalancesResponse{
+				HeadSeq:          12,
+				MinConfirmations: 6,
+				Page:             2,
+				Limit:            2,
+				Total:            3,
+				Addresses: []AddressBalance{
+					{
+						Address:   addr3.String(),
+						Confirmed: readable.Balance{Coins: 3e6, Hours: 7},
+						Spendable: readable.Balance{Coins: 3e6, Hours: 7},
+					},
+				},
+			},
+		},
+		{
+			name:             "200 - past the last page",
+			method:           http.MethodPost,
+			body:             fmt.Sprintf(`{"addresses":["%s"],"page":3}`, addr1),
+			status:           http.StatusOK,
+			addrs:            []cipher.Address{},
+			minConfirmations: 1,
+			gatewayResult:    []visor.AddressBalance{},
+			httpResponse: BalancesResponse{
+				HeadSeq:          12,
+				MinConfirmations: 1,
+				Page:             3,
+				Limit:            1000,
+				Total:            1,
+				Addresses:        []AddressBalance{},
+			},
+		},
+		{
+			name:     "404 - block after the head block",
+			method:   http.MethodPost,
+			body:     fmt.Sprintf(`{"addresses":["%s"],"block":13}`, addr1),
+			status:   http.StatusNotFound,
+			err:      "404 Not Found - Block seq is higher than the head block seq",
+			addrs:    []cipher.Address{addr1},
+			blockSeq: 13,
+			blockErr: blockdb.ErrSeqAfterHead,
+		},
+		{
+			name:     "500 - block gateway error",
+			method:   http.MethodPost,
+			body:     fmt.Sprintf(`{"addresses":["%s"],"block":3}`, addr1),
+			status:   http.StatusInternalServerError,
+			err:      "500 Internal Server Error - gateway.GetBalancesOfAddrsAtSeq failed: db error",
+			addrs:    []cipher.Address{addr1},
+			blockSeq: 3,
+			blockErr: errors.New("db error"),
+		},
+		{
+			name:        "200 - block",
+			method:      http.MethodPost,
+			body:        fmt.Sprintf(`{"addresses":["%s","%s"],"block":0}`, addr1, addr2),
+			status:      http.StatusOK,
+			addrs:       []cipher.Address{addr1, addr2},
+			blockSeq:    0,
+			blockResult: []wallet.Balance{wallet.NewBalance(10e6, 100), wallet.NewBalance(0, 0)},
+			httpResponse: BalancesResponse{
+				HeadSeq:          0,
+				MinConfirmations: 1,
+				Page:             1,
+				Limit:            1000,
+				Total:            2,
+				Addresses: []AddressBalance{
+					{
+						Address:   addr1.String(),
+						Confirmed: readable.Balance{Coins: 10e6, Hours: 100},
+						Spendable: readable.Balance{Coins: 10e6, Hours: 100},
+					},
+					{
+						Address: addr2.String(),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetBalancesOfAddrs", tc.addrs, tc.minConfirmations).Return(tc.gatewayResult, uint64(12), tc.gatewayErr)
+			gateway.On("GetBalancesOfAddrsAtSeq", tc.addrs, tc.blockSeq).Return(tc.blockResult, tc.blockErr)
+
+			req, err := http.NewRequest(tc.method, "/api/v1/balances", strings.NewReader(tc.body))
+			require.NoError(t, err)
+
+			contentType := tc.contentType
+			if contentType == "" {
+				contentType = ContentTypeJSON
+			}
+			req.Header.Set("Content-Type", contentType)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code, "got `%v` want `%v`", rr.Code, tc.status)
+
+			if rr.Code != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()))
+				return
+			}
+
+			var msg BalancesResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &msg)
+			require.NoError(t, err)
+			require.Equal(t, tc.httpResponse, msg)
+		})
+	}
+}
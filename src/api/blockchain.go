@@ -26,16 +26,24 @@ func blockchainMetadataHandler(gateway Gatewayer) http.HandlerFunc {
 			return
 		}
 
-		visorMetadata, err := gateway.GetBlockchainMetadata()
+		s, err := gateway.Snapshot()
 		if err != nil {
-			err = fmt.Errorf("gateway.GetBlockchainMetadata failed: %v", err)
+			err = fmt.Errorf("gateway.Snapshot failed: %v", err)
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		visorMetadata, err := s.GetBlockchainMetadata()
+		releaseSnapshot(s)
+		if err != nil {
+			err = fmt.Errorf("snapshot.GetBlockchainMetadata failed: %v", err)
 			wh.Error500(w, err.Error())
 			return
 		}
 
 		// This can happen if the node is shut down at the right moment, guard against a panic
 		if visorMetadata == nil {
-			err = errors.New("gateway.GetBlockchainMetadata metadata is nil")
+			err = errors.New("snapshot.GetBlockchainMetadata metadata is nil")
 			wh.Error500(w, err.Error())
 			return
 		}
@@ -30,6 +30,7 @@ func TestGetBlockchainMetadata(t *testing.T) {
 		method                      string
 		status                      int
 		err                         string
+		snapshotErr                 error
 		getBlockchainMetadataResult *visor.BlockchainMetadata
 		getBlockchainMetadataErr    error
 		result                      readable.BlockchainMetadata
@@ -40,27 +41,35 @@ func TestGetBlockchainMetadata(t *testing.T) {
 			status: http.StatusMethodNotAllowed,
 			err:    "405 Method Not Allowed",
 		},
+		{
+			name:        "500 - Snapshot error",
+			method:      http.MethodGet,
+			status:      http.StatusInternalServerError,
+			err:         "500 Internal Server Error - gateway.Snapshot failed: Snapshot error",
+			snapshotErr: errors.New("Snapshot error"),
+		},
 		{
 			name:                     "500 - GetBlockchainMetadata error",
 			method:                   http.MethodGet,
 			status:                   http.StatusInternalServerError,
-			err:                      "500 Internal Server Error - gateway.GetBlockchainMetadata failed: GetBlockchainMetadata error",
+			err:                      "500 Internal Server Error - snapshot.GetBlockchainMetadata failed: GetBlockchainMetadata error",
 			getBlockchainMetadataErr: errors.New("GetBlockchainMetadata error"),
 		},
 		{
 			name:   "500 - nil visor.BlockchainMetadata",
 			method: http.MethodGet,
 			status: http.StatusInternalServerError,
-			err:    "500 Internal Server Error - gateway.GetBlockchainMetadata metadata is nil",
+			err:    "500 Internal Server Error - snapshot.GetBlockchainMetadata metadata is nil",
 		},
 		{
 			name:   "200",
 			method: http.MethodGet,
 			status: http.StatusOK,
 			getBlockchainMetadataResult: &visor.BlockchainMetadata{
-				HeadBlock:   coin.SignedBlock{},
-				Unspents:    12,
-				Unconfirmed: 13,
+				HeadBlock:         coin.SignedBlock{},
+				Unspents:          12,
+				Unconfirmed:       13,
+				UnspentCommitment: testutil.SHA256FromHex(t, "9eb0e817fbe5f0d4a7a5ff79166e7bba27e8cea3e1a33e6f1cfb4d74ddd47e94"),
 			},
 			result: readable.BlockchainMetadata{
 				Head: readable.BlockHeader{
@@ -69,16 +78,25 @@ func TestGetBlockchainMetadata(t *testing.T) {
 					BodyHash:     "0000000000000000000000000000000000000000000000000000000000000000",
 					UxHash:       "0000000000000000000000000000000000000000000000000000000000000000",
 				},
-				Unspents:    12,
-				Unconfirmed: 13,
+				Unspents:          12,
+				Unconfirmed:       13,
+				UnspentCommitment: "9eb0e817fbe5f0d4a7a5ff79166e7bba27e8cea3e1a33e6f1cfb4d74ddd47e94",
 			},
 		},
 	}
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
+			snapshot := &MockSnapshotter{}
+			snapshot.On("GetBlockchainMetadata").Return(tc.getBlockchainMetadataResult, tc.getBlockchainMetadataErr)
+			snapshot.On("Release").Return(nil)
+
 			gateway := &MockGatewayer{}
-			gateway.On("GetBlockchainMetadata").Return(tc.getBlockchainMetadataResult, tc.getBlockchainMetadataErr)
+			if tc.snapshotErr != nil {
+				gateway.On("Snapshot").Return(nil, tc.snapshotErr)
+			} else {
+				gateway.On("Snapshot").Return(snapshot, nil)
+			}
 
 			endpoint := "/api/v1/blockchain/metadata"
 			req, err := http.NewRequest(tc.method, endpoint, nil)
@@ -106,6 +124,11 @@ func TestGetBlockchainMetadata(t *testing.T) {
 				require.NoError(t, err)
 				require.Equal(t, tc.result, msg)
 			}
+
+			// The snapshot is released after it is read
+			if tc.method == http.MethodGet && tc.snapshotErr == nil {
+				snapshot.AssertExpectations(t)
+			}
 		})
 	}
 }
@@ -224,7 +247,7 @@ func makeBadBlock(t *testing.T) *coin.Block {
 	var genCoins uint64 = 1000e6
 	var genTime uint64 = 1000
 	now := genTime + 100
-	preBlock, err := coin.NewGenesisBlock(genAddress, genCoins, genTime)
+	preBlock, err := coin.NewGenesisBlock(genAddress, genCoins, genTime, nil)
 	require.NoError(t, err)
 	uxHash := testutil.RandSHA256(t)
 	txn := coin.Transaction{
@@ -232,7 +255,7 @@ func makeBadBlock(t *testing.T) *coin.Block {
 			testutil.RandSHA256(t),
 		},
 	}
-	err = txn.PushOutput(genAddress, math.MaxInt64+1, 255)
+	err = txn.PushOutput(genAddress, math.MaxInt64+1, 255, nil)
 	require.NoError(t, err)
 	b, err := coin.NewBlock(*preBlock, now, uxHash, coin.Transactions{txn}, func(t *coin.Transaction) (uint64, error) {
 		return 0, nil
@@ -50,6 +50,14 @@ func (e ClientError) Error() string {
 	return e.Message
 }
 
+// InjectTransactionConflictError is returned by InjectTransaction and InjectEncodedTransaction
+// if the transaction conflicts with unconfirmed transactions and its fee is too low to replace them
+type InjectTransactionConflictError struct {
+	ClientError
+	// ConflictingTxids are the unconfirmed transactions spending the same inputs as the transaction
+	ConflictingTxids []string
+}
+
 // ReceivedHTTPResponse parsed a HTTPResponse received by the Client, for the V2 API
 type ReceivedHTTPResponse struct {
 	Error *HTTPError      `json:"error,omitempty"`
@@ -62,6 +70,8 @@ type Client struct {
 	Addr       string
 	Username   string
 	Password   string
+	Token      string
+	APIKey     string
 }
 
 // NewClient creates a Client
@@ -91,8 +101,26 @@ func (c *Client) SetAuth(username, password string) {
 	c.Password = password
 }
 
+// SetToken configures the Client's request authentication with an API token.
+// The token is only used if no username and password are set.
+func (c *Client) SetToken(token string) {
+	c.Token = token
+}
+
+// SetAPIKey configures the Client to send an API key, which does not need a CSRF token
+func (c *Client) SetAPIKey(key string) {
+	c.APIKey = key
+}
+
 func (c *Client) applyAuth(req *http.Request) {
+	if c.APIKey != "" {
+		req.Header.Set(APIKeyHeaderName, c.APIKey)
+	}
+
 	if c.Username == "" && c.Password == "" {
+		if c.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.Token)
+		}
 		return
 	}
 
@@ -542,6 +570,41 @@ func (c *Client) LastBlocks(n uint64) (*readable.Blocks, error) {
 	return &b, nil
 }
 
+// NextBlock makes a request to GET /api/v1/next-block
+func (c *Client) NextBlock() (*NextBlockResponse, error) {
+	var v NextBlockResponse
+	if err := c.Get("/api/v1/next-block", &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// Headers makes a request to GET /api/v1/headers, which returns the compact headers of the blocks
+// from the since seq to the head block
+func (c *Client) Headers(since uint64) ([]readable.CompactBlockHeader, error) {
+	v := url.Values{}
+	v.Add("since", fmt.Sprint(since))
+	endpoint := "/api/v1/headers?" + v.Encode()
+
+	var h []readable.CompactBlockHeader
+	if err := c.Get(endpoint, &h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// DevnetGenerate makes a request to POST /api/v1/devnet/generate
+func (c *Client) DevnetGenerate(blocks int) (*readable.Blocks, error) {
+	v := url.Values{}
+	v.Add("blocks", fmt.Sprint(blocks))
+
+	var b readable.Blocks
+	if err := c.PostForm("/api/v1/devnet/generate", strings.NewReader(v.Encode()), &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
 // LastBlocksVerbose makes a request to GET /api/v1/last_blocks?verbose=1
 func (c *Client) LastBlocksVerbose(n uint64) (*readable.BlocksVerbose, error) {
 	v := url.Values{}
@@ -587,6 +650,29 @@ func (c *Client) Balance(addrs []string) (*BalanceResponse, error) {
 	return &b, nil
 }
 
+// BalanceAtBlock makes a request to POST /api/v1/balance?addrs=xxx&block=xxx
+func (c *Client) BalanceAtBlock(addrs []string, seq uint64) (*BalanceResponse, error) {
+	v := url.Values{}
+	v.Add("addrs", strings.Join(addrs, ","))
+	v.Add("block", fmt.Sprint(seq))
+	endpoint := "/api/v1/balance"
+
+	var b BalanceResponse
+	if err := c.PostForm(endpoint, strings.NewReader(v.Encode()), &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Balances makes a request to POST /api/v1/balances
+func (c *Client) Balances(req BalancesRequest) (*BalancesResponse, error) {
+	var b BalancesResponse
+	if err := c.PostJSON("/api/v1/balances", req, &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
 // UxOut makes a request to GET /api/v1/uxout?uxid=xxx
 func (c *Client) UxOut(uxID string) (*readable.SpentOutput, error) {
 	v := url.Values{}
@@ -613,6 +699,45 @@ func (c *Client) AddressUxOuts(addr string) ([]readable.SpentOutput, error) {
 	return b, nil
 }
 
+// UnspentStats makes a request to GET /api/v1/unspents/stats.
+// If dustThreshold is empty, the default dust threshold is used.
+func (c *Client) UnspentStats(dustThreshold string) (*UnspentStatsResponse, error) {
+	endpoint := "/api/v1/unspents/stats"
+	if dustThreshold != "" {
+		v := url.Values{}
+		v.Add("dust_threshold", dustThreshold)
+		endpoint += "?" + v.Encode()
+	}
+
+	var s UnspentStatsResponse
+	if err := c.Get(endpoint, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// AddressTransactions makes a request to GET /api/v1/address/{addr}/transactions.
+// page and limit are optional and use the server defaults if 0.
+func (c *Client) AddressTransactions(addr string, page, limit uint64) (*AddressTransactionsResponse, error) {
+	v := url.Values{}
+	if page != 0 {
+		v.Add("page", fmt.Sprint(page))
+	}
+	if limit != 0 {
+		v.Add("limit", fmt.Sprint(limit))
+	}
+	endpoint := "/api/v1/address/" + url.PathEscape(addr) + "/transactions"
+	if len(v) > 0 {
+		endpoint += "?" + v.Encode()
+	}
+
+	var r AddressTransactionsResponse
+	if err := c.Get(endpoint, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
 // Wallet makes a request to GET /api/v1/wallet
 func (c *Client) Wallet(id string) (*WalletResponse, error) {
 	v := url.Values{}
@@ -719,6 +844,7 @@ type CreateTransactionRequest struct {
 	To                []Receiver     `json:"to"`
 	UxOuts            []string       `json:"unspents,omitempty"`
 	Addresses         []string       `json:"addresses,omitempty"`
+	BurnChangeHours   bool           `json:"burn_change_hours,omitempty"`
 }
 
 // HoursSelection defines options for hours distribution
@@ -738,6 +864,7 @@ type Receiver struct {
 // WalletCreateTransactionRequest is sent to /api/v1/wallet/transaction
 type WalletCreateTransactionRequest struct {
 	Unsigned bool   `json:"unsigned"`
+	HW       bool   `json:"hw"`
 	WalletID string `json:"wallet_id"`
 	Password string `json:"password"`
 	CreateTransactionRequest
@@ -945,6 +1072,15 @@ func (c *Client) PendingTransactions() ([]readable.UnconfirmedTransactions, erro
 	return v, nil
 }
 
+// FeeEstimate makes a request to GET /api/v1/fee-estimate
+func (c *Client) FeeEstimate() (*FeeEstimateResponse, error) {
+	var v FeeEstimateResponse
+	if err := c.Get("/api/v1/fee-estimate", &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
 // PendingTransactionsVerbose makes a request to GET /api/v1/pendingTxs?verbose=1
 func (c *Client) PendingTransactionsVerbose() ([]readable.UnconfirmedTransactionVerbose, error) {
 	var v []readable.UnconfirmedTransactionVerbose
@@ -1100,6 +1236,17 @@ func (c *Client) InjectEncodedTransaction(rawTxn string) (string, error) {
 
 	var txid string
 	if err := c.PostJSON("/api/v1/injectTransaction", v, &txid); err != nil {
+		if ce, ok := err.(ClientError); ok && ce.StatusCode == http.StatusConflict {
+			var rsp ReceivedHTTPResponse
+			var conflict InjectTransactionConflict
+			if json.Unmarshal([]byte(ce.Message), &rsp) == nil && rsp.Error != nil && json.Unmarshal(rsp.Data, &conflict) == nil {
+				ce.Message = rsp.Error.Message
+				return "", InjectTransactionConflictError{
+					ClientError:      ce,
+					ConflictingTxids: conflict.ConflictingTxids,
+				}
+			}
+		}
 		return "", err
 	}
 	return txid, nil
@@ -1254,6 +1401,19 @@ func (c *Client) RecoverWallet(id, seed, password string) (*WalletResponse, erro
 	return nil, err
 }
 
+// ConfirmAddressHardware makes a request to POST /api/v2/wallet/address/confirm to show an address
+// of a hardware wallet on the device, for the user to confirm.
+func (c *Client) ConfirmAddressHardware(id, addr string) error {
+	req := WalletConfirmAddressRequest{
+		WalletID: id,
+		Address:  addr,
+	}
+
+	var rsp struct{}
+	_, err := c.PostJSONV2("/api/v2/wallet/address/confirm", req, &rsp)
+	return err
+}
+
 // Disconnect disconnect a connections by ID
 func (c *Client) Disconnect(id uint64) error {
 	v := url.Values{}
@@ -1263,6 +1423,74 @@ func (c *Client) Disconnect(id uint64) error {
 	return c.PostForm("/api/v1/network/connection/disconnect", strings.NewReader(v.Encode()), &obj)
 }
 
+// Bans makes a request to GET /api/v1/network/bans
+func (c *Client) Bans() ([]readable.Ban, error) {
+	var r Bans
+	if err := c.Get("/api/v1/network/bans", &r); err != nil {
+		return nil, err
+	}
+	return r.Bans, nil
+}
+
+// Ban makes a request to POST /api/v1/network/bans.
+// If duration is 0, the node's default ban duration is used.
+func (c *Client) Ban(ip, reason string, duration time.Duration) (*readable.Ban, error) {
+	v := url.Values{}
+	v.Add("ip", ip)
+	if reason != "" {
+		v.Add("reason", reason)
+	}
+	if duration != 0 {
+		v.Add("duration", duration.String())
+	}
+
+	var r readable.Ban
+	if err := c.PostForm("/api/v1/network/bans", strings.NewReader(v.Encode()), &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Unban makes a request to DELETE /api/v1/network/bans
+func (c *Client) Unban(ip string) error {
+	v := url.Values{}
+	v.Add("ip", ip)
+
+	csrf, err := c.CSRF()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, c.Addr+"api/v1/network/bans?"+v.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	c.applyAuth(req)
+
+	if csrf != "" {
+		req.Header.Set(CSRFHeaderName, csrf)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+
+		return NewClientError(resp.Status, resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
 // GetAllStorageValues makes a GET request to /api/v2/data to get all the values from the storage of
 // `storageType` type
 func (c *Client) GetAllStorageValues(storageType kvstorage.Type) (map[string]string, error) {
@@ -1306,3 +1534,114 @@ func (c *Client) RemoveStorageValue(storageType kvstorage.Type, key string) erro
 
 	return err
 }
+
+// Backup makes a request to GET /api/v1/admin/backup and writes the copy of the database to w.
+// If compress is true, the copy is compressed with gzip. Returns the number of bytes written to w.
+func (c *Client) Backup(w io.Writer, compress bool) (int64, error) {
+	endpoint := "/api/v1/admin/backup"
+	if compress {
+		endpoint += "?gzip=true"
+	}
+
+	resp, err := c.get(endpoint)
+	if err != nil {
+		return 0, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return 0, err
+		}
+
+		return 0, NewClientError(resp.Status, resp.StatusCode, string(body))
+	}
+
+	return io.Copy(w, resp.Body)
+}
+
+// DBStats makes a request to GET /api/v1/admin/dbstats
+func (c *Client) DBStats() (*DBStats, error) {
+	var r DBStats
+	if err := c.Get("/api/v1/admin/dbstats", &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// CompactDB makes a request to POST /api/v1/admin/compactdb
+func (c *Client) CompactDB() (*CompactDBResponse, error) {
+	var r CompactDBResponse
+	if err := c.PostForm("/api/v1/admin/compactdb", strings.NewReader(""), &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Webhooks makes a request to GET /api/v1/admin/webhooks
+func (c *Client) Webhooks() ([]Webhook, error) {
+	var r []Webhook
+	if err := c.Get("/api/v1/admin/webhooks", &r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// AddWebhook makes a request to POST /api/v1/admin/webhooks.
+// If secret is empty, the node generates one. The returned webhook includes the secret.
+func (c *Client) AddWebhook(webhookURL string, addrs []string, secret string) (*Webhook, error) {
+	v := url.Values{}
+	v.Add("url", webhookURL)
+	v.Add("addrs", strings.Join(addrs, ","))
+	if secret != "" {
+		v.Add("secret", secret)
+	}
+
+	var r Webhook
+	if err := c.PostForm("/api/v1/admin/webhooks", strings.NewReader(v.Encode()), &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// RemoveWebhook makes a request to DELETE /api/v1/admin/webhooks
+func (c *Client) RemoveWebhook(id string) error {
+	v := url.Values{}
+	v.Add("id", id)
+
+	csrf, err := c.CSRF()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, c.Addr+"api/v1/admin/webhooks?"+v.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	c.applyAuth(req)
+
+	if csrf != "" {
+		req.Header.Set(CSRFHeaderName, csrf)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+
+		return NewClientError(resp.Status, resp.StatusCode, string(body))
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/SkycoinProject/cx-chains/src/readable"
+	wh "github.com/SkycoinProject/cx-chains/src/util/http"
+	"github.com/SkycoinProject/cx-chains/src/visor"
+)
+
+// devnetGenerateHandler creates and executes blocks on demand on a devnet node.
+// Each block includes the transactions of the unconfirmed pool chosen by the block policy.
+// If the unconfirmed pool is empty, the block has a transaction from the genesis address to itself.
+// Returns the generated blocks.
+// Method: POST
+// URI: /api/v1/devnet/generate
+// Args:
+// 	blocks [int, number of blocks to generate, default 1]
+func devnetGenerateHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			wh.Error405(w)
+			return
+		}
+
+		n := 1
+		if s := r.FormValue("blocks"); s != "" {
+			var err error
+			n, err = strconv.Atoi(s)
+			if err != nil || n < 1 || n > visor.MaxDevnetGenerateBlocks {
+				wh.Error400(w, fmt.Sprintf("Invalid blocks value %q, must be > 0 and <= %d", s, visor.MaxDevnetGenerateBlocks))
+				return
+			}
+		}
+
+		blocks, err := gateway.GenerateDevnetBlocks(n)
+		if err != nil {
+			switch err {
+			case visor.ErrDevnetDisabled:
+				wh.Error403(w, err.Error())
+			default:
+				err = fmt.Errorf("gateway.GenerateDevnetBlocks failed: %v", err)
+				wh.Error500(w, err.Error())
+			}
+			return
+		}
+
+		rb, err := readable.NewBlocks(blocks)
+		if err != nil {
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		wh.SendJSONOr500(logger, w, rb)
+	}
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/readable"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+	"github.com/SkycoinProject/cx-chains/src/visor"
+)
+
+func TestDevnetGenerate(t *testing.T) {
+	b, err := coin.NewGenesisBlock(testutil.MakeAddress(), 1000e6, 1000, nil)
+	require.NoError(t, err)
+	blocks := []coin.SignedBlock{
+		{
+			Block: *b,
+			Sig:   testutil.RandSig(t),
+		},
+	}
+	rb, err := readable.NewBlocks(blocks)
+	require.NoError(t, err)
+
+	tt := []struct {
+		name          string
+		method        string
+		blocks        string
+		status        int
+		err           string
+		gatewayN      int
+		gatewayResult []coin.SignedBlock
+		gatewayErr    error
+		httpResponse  *readable.Blocks
+	}{
+		{
+			name:   "405",
+			method: http.MethodGet,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+		{
+			name:   "400 - invalid blocks",
+			method: http.MethodPost,
+			blocks: "foo",
+			status: http.StatusBadRequest,
+			err:    `400 Bad Request - Invalid blocks value "foo", must be > 0 and <= 1000`,
+		},
+		{
+			name:   "400 - too many blocks",
+			method: http.MethodPost,
+			blocks: "1001",
+			status: http.StatusBadRequest,
+			err:    `400 Bad Request - Invalid blocks value "1001", must be > 0 and <= 1000`,
+		},
+		{
+			name:       "403 - devnet disabled",
+			method:     http.MethodPost,
+			gatewayN:   1,
+			gatewayErr: visor.ErrDevnetDisabled,
+			status:     http.StatusForbidden,
+			err:        "403 Forbidden - Devnet is disabled",
+		},
+		{
+			name:       "500 - gateway error",
+			method:     http.MethodPost,
+			blocks:     "2",
+			gatewayN:   2,
+			gatewayErr: errors.New("failed"),
+			status:     http.StatusInternalServerError,
+			err:        "500 Internal Server Error - gateway.GenerateDevnetBlocks failed: failed",
+		},
+		{
+			name:          "200",
+			method:        http.MethodPost,
+			gatewayN:      1,
+			gatewayResult: blocks,
+			status:        http.StatusOK,
+			httpResponse:  rb,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GenerateDevnetBlocks", tc.gatewayN).Return(tc.gatewayResult, tc.gatewayErr)
+
+			v := url.Values{}
+			if tc.blocks != "" {
+				v.Add("blocks", tc.blocks)
+			}
+
+			req, err := http.NewRequest(tc.method, "/api/v1/devnet/generate", strings.NewReader(v.Encode()))
+			require.NoError(t, err)
+			req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code, "got `%v` want `%v`", rr.Code, tc.status)
+
+			if rr.Code != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()))
+				return
+			}
+
+			var msg readable.Blocks
+			err = json.Unmarshal(rr.Body.Bytes(), &msg)
+			require.NoError(t, err)
+			require.Equal(t, *tc.httpResponse, msg)
+		})
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/SkycoinProject/cx-chains/src/readable"
 	"github.com/SkycoinProject/cx-chains/src/util/droplet"
 	wh "github.com/SkycoinProject/cx-chains/src/util/http"
-	"github.com/SkycoinProject/cx-chains/src/util/mathutil"
 )
 
 // CoinSupply records the coin supply info
@@ -48,41 +47,22 @@ func coinSupplyHandler(gateway Gatewayer) http.HandlerFunc {
 			return
 		}
 
-		allUnspents, err := gateway.GetUnspentOutputsSummary(nil)
+		supply, err := gateway.GetCoinSupply()
 		if err != nil {
-			err = fmt.Errorf("gateway.GetUnspentOutputsSummary failed: %v", err)
+			err = fmt.Errorf("gateway.GetCoinSupply failed: %v", err)
 			wh.Error500(w, err.Error())
 			return
 		}
 
 		dist := gateway.VisorConfig().Distribution
 
-		unlockedAddrs := dist.UnlockedAddressesDecoded()
-		// Search map of unlocked addresses, used to filter unspents
-		unlockedAddrSet := newAddrSet(unlockedAddrs)
-
-		var unlockedSupply uint64
-		// check confirmed unspents only
-		for _, u := range allUnspents.Confirmed {
-			// check if address is an unlocked distribution address
-			if _, ok := unlockedAddrSet[u.Body.Address]; ok {
-				var err error
-				unlockedSupply, err = mathutil.AddUint64(unlockedSupply, u.Body.Coins)
-				if err != nil {
-					err = fmt.Errorf("uint64 overflow while adding up unlocked supply coins: %v", err)
-					wh.Error500(w, err.Error())
-					return
-				}
-			}
-		}
-
 		// "total supply" is the number of coins unlocked.
 		// Each distribution address was allocated distribution.AddressInitialBalance coins.
-		totalSupply := uint64(len(unlockedAddrs)) * dist.AddressInitialBalance()
+		totalSupply := uint64(len(dist.UnlockedAddresses())) * dist.AddressInitialBalance()
 		totalSupply *= droplet.Multiplier
 
 		// "current supply" is the number of coins distributed from the unlocked pool
-		currentSupply := totalSupply - unlockedSupply
+		currentSupply := totalSupply - supply.UnlockedCoins
 
 		currentSupplyStr, err := droplet.ToString(currentSupply)
 		if err != nil {
@@ -105,48 +85,12 @@ func coinSupplyHandler(gateway Gatewayer) http.HandlerFunc {
 			return
 		}
 
-		// locked distribution addresses
-		lockedAddrs := dist.LockedAddressesDecoded()
-		lockedAddrSet := newAddrSet(lockedAddrs)
-
-		// get total coins hours which excludes locked distribution addresses
-		var totalCoinHours uint64
-		for _, out := range allUnspents.Confirmed {
-			if _, ok := lockedAddrSet[out.Body.Address]; !ok {
-				var err error
-				totalCoinHours, err = mathutil.AddUint64(totalCoinHours, out.CalculatedHours)
-				if err != nil {
-					err = fmt.Errorf("uint64 overflow while adding up total coin hours: %v", err)
-					wh.Error500(w, err.Error())
-					return
-				}
-			}
-		}
-
-		// get current coin hours which excludes all distribution addresses
-		var currentCoinHours uint64
-		for _, out := range allUnspents.Confirmed {
-			// check if address not in locked distribution addresses
-			if _, ok := lockedAddrSet[out.Body.Address]; !ok {
-				// check if address not in unlocked distribution addresses
-				if _, ok := unlockedAddrSet[out.Body.Address]; !ok {
-					currentCoinHours += out.CalculatedHours
-				}
-			}
-		}
-
-		if err != nil {
-			err = fmt.Errorf("Failed to get total coinhours: %v", err)
-			wh.Error500(w, err.Error())
-			return
-		}
-
 		cs := CoinSupply{
 			CurrentSupply:         currentSupplyStr,
 			TotalSupply:           totalSupplyStr,
 			MaxSupply:             maxSupplyStr,
-			CurrentCoinHourSupply: strconv.FormatUint(currentCoinHours, 10),
-			TotalCoinHourSupply:   strconv.FormatUint(totalCoinHours, 10),
+			CurrentCoinHourSupply: strconv.FormatUint(supply.CurrentCoinHours, 10),
+			TotalCoinHourSupply:   strconv.FormatUint(supply.TotalCoinHours, 10),
 			UnlockedAddresses:     dist.UnlockedAddresses(),
 			LockedAddresses:       dist.LockedAddresses(),
 		}
@@ -199,16 +143,12 @@ func richlistHandler(gateway Gatewayer) http.HandlerFunc {
 			}
 		}
 
-		richlist, err := gateway.GetRichlist(includeDistribution)
+		richlist, err := gateway.GetRichlist(includeDistribution, topn)
 		if err != nil {
 			wh.Error500(w, err.Error())
 			return
 		}
 
-		if topn > 0 && topn < len(richlist) {
-			richlist = richlist[:topn]
-		}
-
 		readableRichlist, err := readable.NewRichlistBalances(richlist)
 		if err != nil {
 			wh.Error500(w, err.Error())
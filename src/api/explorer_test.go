@@ -10,40 +10,25 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/SkycoinProject/cx-chains/src/cipher"
-	"github.com/SkycoinProject/cx-chains/src/coin"
 	"github.com/SkycoinProject/cx-chains/src/params"
 	"github.com/SkycoinProject/cx-chains/src/readable"
-	"github.com/SkycoinProject/cx-chains/src/testutil"
 	"github.com/SkycoinProject/cx-chains/src/util/droplet"
 	"github.com/SkycoinProject/cx-chains/src/visor"
 )
 
-func makeSuccessCoinSupplyResult(t *testing.T, allUnspents readable.UnspentOutputsSummary) *CoinSupply {
+func makeSuccessCoinSupplyResult(t *testing.T, supply visor.CoinSupply) *CoinSupply {
 	unlockedAddrs := params.MainNetDistribution.UnlockedAddressesDecoded()
-	var unlockedSupply uint64
-	// check confirmed unspents only
-	// Search map of unlocked addresses
-	// used to filter unspents
-	unlockedAddrSet := newAddrSet(unlockedAddrs)
-	for _, u := range allUnspents.HeadOutputs {
-		// check if address is an unlocked distribution address
-		if _, ok := unlockedAddrSet[cipher.MustDecodeBase58Address(u.Address)]; ok {
-			coins, err := droplet.FromString(u.Coins)
-			require.NoError(t, err)
-			unlockedSupply += coins
-		}
-	}
+
 	// "total supply" is the number of coins unlocked.
 	// Each distribution address was allocated params.MainNetDistribution.AddressInitialBalance coins.
 	totalSupply := uint64(len(unlockedAddrs)) * params.MainNetDistribution.AddressInitialBalance()
 	totalSupply *= droplet.Multiplier
 
 	// "current supply" is the number of coins distribution from the unlocked pool
-	currentSupply := totalSupply - unlockedSupply
+	currentSupply := totalSupply - supply.UnlockedCoins
 
 	currentSupplyStr, err := droplet.ToString(currentSupply)
 	require.NoError(t, err)
@@ -54,36 +39,12 @@ func makeSuccessCoinSupplyResult(t *testing.T, allUnspents readable.UnspentOutpu
 	maxSupplyStr, err := droplet.ToString(params.MainNetDistribution.MaxCoinSupply * droplet.Multiplier)
 	require.NoError(t, err)
 
-	// locked distribution addresses
-	lockedAddrs := params.MainNetDistribution.LockedAddressesDecoded()
-	lockedAddrSet := newAddrSet(lockedAddrs)
-
-	// get total coins hours which excludes locked distribution addresses
-	var totalCoinHours uint64
-	for _, out := range allUnspents.HeadOutputs {
-		if _, ok := lockedAddrSet[cipher.MustDecodeBase58Address(out.Address)]; !ok {
-			totalCoinHours += out.Hours
-		}
-	}
-
-	// get current coin hours which excludes all distribution addresses
-	var currentCoinHours uint64
-	for _, out := range allUnspents.HeadOutputs {
-		// check if address not in locked distribution addresses
-		if _, ok := lockedAddrSet[cipher.MustDecodeBase58Address(out.Address)]; !ok {
-			// check if address not in unlocked distribution addresses
-			if _, ok := unlockedAddrSet[cipher.MustDecodeBase58Address(out.Address)]; !ok {
-				currentCoinHours += out.Hours
-			}
-		}
-	}
-
 	cs := CoinSupply{
 		CurrentSupply:         currentSupplyStr,
 		TotalSupply:           totalSupplyStr,
 		MaxSupply:             maxSupplyStr,
-		CurrentCoinHourSupply: strconv.FormatUint(currentCoinHours, 10),
-		TotalCoinHourSupply:   strconv.FormatUint(totalCoinHours, 10),
+		CurrentCoinHourSupply: strconv.FormatUint(supply.CurrentCoinHours, 10),
+		TotalCoinHourSupply:   strconv.FormatUint(supply.TotalCoinHours, 10),
 		UnlockedAddresses:     params.MainNetDistribution.UnlockedAddresses(),
 		LockedAddresses:       params.MainNetDistribution.LockedAddresses(),
 	}
@@ -91,37 +52,21 @@ func makeSuccessCoinSupplyResult(t *testing.T, allUnspents readable.UnspentOutpu
 }
 
 func TestCoinSupply(t *testing.T) {
-	addrs := []cipher.Address{
-		testutil.MakeAddress(),
-		testutil.MakeAddress(),
-	}
-
-	unlockedAddrs := params.MainNetDistribution.UnlockedAddressesDecoded()
-	successGatewayGetUnspentOutputsResult := readable.UnspentOutputsSummary{
-		HeadOutputs: readable.UnspentOutputs{
-			readable.UnspentOutput{
-				Address: addrs[0].String(),
-				Coins:   "0",
-			},
-			readable.UnspentOutput{
-				Address: addrs[1].String(),
-				Coins:   "0",
-			},
-		},
+	successGatewayGetCoinSupplyResult := visor.CoinSupply{
+		UnlockedCoins:    10e6,
+		TotalCoinHours:   300,
+		CurrentCoinHours: 200,
 	}
 
-	var filterInUnlocked []visor.OutputsFilter
-	filterInUnlocked = append(filterInUnlocked, visor.FbyAddresses(unlockedAddrs))
 	tt := []struct {
-		name                           string
-		method                         string
-		status                         int
-		err                            string
-		gatewayGetUnspentOutputsArg    []visor.OutputsFilter
-		gatewayGetUnspentOutputsResult *visor.UnspentOutputsSummary
-		gatewayGetUnspentOutputsErr    error
-		result                         *CoinSupply
-		csrfDisabled                   bool
+		name                       string
+		method                     string
+		status                     int
+		err                        string
+		gatewayGetCoinSupplyResult *visor.CoinSupply
+		gatewayGetCoinSupplyErr    error
+		result                     *CoinSupply
+		csrfDisabled               bool
 	}{
 		{
 			name:   "405",
@@ -130,75 +75,27 @@ func TestCoinSupply(t *testing.T) {
 			err:    "405 Method Not Allowed",
 		},
 		{
-			name:                        "500 - gatewayGetUnspentOutputsErr",
-			method:                      http.MethodGet,
-			status:                      http.StatusInternalServerError,
-			err:                         "500 Internal Server Error - gateway.GetUnspentOutputsSummary failed: gatewayGetUnspentOutputsErr",
-			gatewayGetUnspentOutputsArg: filterInUnlocked,
-			gatewayGetUnspentOutputsErr: errors.New("gatewayGetUnspentOutputsErr"),
+			name:                    "500 - gatewayGetCoinSupplyErr",
+			method:                  http.MethodGet,
+			status:                  http.StatusInternalServerError,
+			err:                     "500 Internal Server Error - gateway.GetCoinSupply failed: gatewayGetCoinSupplyErr",
+			gatewayGetCoinSupplyErr: errors.New("gatewayGetCoinSupplyErr"),
 		},
 		{
-			name:                        "500 - gatewayGetUnspentOutputsErr",
-			method:                      http.MethodGet,
-			status:                      http.StatusInternalServerError,
-			err:                         "500 Internal Server Error - gateway.GetUnspentOutputsSummary failed: gatewayGetUnspentOutputsErr",
-			gatewayGetUnspentOutputsArg: filterInUnlocked,
-			gatewayGetUnspentOutputsErr: errors.New("gatewayGetUnspentOutputsErr"),
-		},
-		{
-			name:                        "500 - too large HeadOutputs item",
-			method:                      http.MethodGet,
-			status:                      http.StatusInternalServerError,
-			err:                         "500 Internal Server Error - Failed to convert coins to string: Droplet string conversion failed: Value is too large",
-			gatewayGetUnspentOutputsArg: filterInUnlocked,
-			gatewayGetUnspentOutputsResult: &visor.UnspentOutputsSummary{
-				Confirmed: []visor.UnspentOutput{
-					visor.UnspentOutput{
-						UxOut: coin.UxOut{
-							Body: coin.UxBody{
-								Coins:   9223372036854775807,
-								Address: unlockedAddrs[0],
-							},
-						},
-					},
-					visor.UnspentOutput{
-						UxOut: coin.UxOut{
-							Body: coin.UxBody{
-								Coins:   1000000,
-								Address: unlockedAddrs[0],
-							},
-						},
-					},
-				},
+			name:   "500 - too large unlocked coins",
+			method: http.MethodGet,
+			status: http.StatusInternalServerError,
+			err:    "500 Internal Server Error - Failed to convert coins to string: Droplet string conversion failed: Value is too large",
+			gatewayGetCoinSupplyResult: &visor.CoinSupply{
+				UnlockedCoins: 9223372036854775807 + 1000000,
 			},
 		},
 		{
-			name:   "200",
-			method: http.MethodGet,
-			status: http.StatusOK,
-
-			gatewayGetUnspentOutputsArg: filterInUnlocked,
-			gatewayGetUnspentOutputsResult: &visor.UnspentOutputsSummary{
-				Confirmed: []visor.UnspentOutput{
-					visor.UnspentOutput{
-						UxOut: coin.UxOut{
-							Body: coin.UxBody{
-								Coins:   0,
-								Address: addrs[0],
-							},
-						},
-					},
-					visor.UnspentOutput{
-						UxOut: coin.UxOut{
-							Body: coin.UxBody{
-								Coins:   0,
-								Address: addrs[1],
-							},
-						},
-					},
-				},
-			},
-			result: makeSuccessCoinSupplyResult(t, successGatewayGetUnspentOutputsResult),
+			name:                       "200",
+			method:                     http.MethodGet,
+			status:                     http.StatusOK,
+			gatewayGetCoinSupplyResult: &successGatewayGetCoinSupplyResult,
+			result:                     makeSuccessCoinSupplyResult(t, successGatewayGetCoinSupplyResult),
 		},
 	}
 
@@ -206,7 +103,7 @@ func TestCoinSupply(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			endpoint := "/api/v1/coinSupply"
 			gateway := &MockGatewayer{}
-			gateway.On("GetUnspentOutputsSummary", mock.Anything).Return(tc.gatewayGetUnspentOutputsResult, tc.gatewayGetUnspentOutputsErr)
+			gateway.On("GetCoinSupply").Return(tc.gatewayGetCoinSupplyResult, tc.gatewayGetCoinSupplyErr)
 			gateway.On("VisorConfig").Return(visor.Config{
 				Distribution: params.MainNetDistribution,
 			})
@@ -255,6 +152,7 @@ func TestGetRichlist(t *testing.T) {
 		err                      string
 		httpParams               *httpParams
 		includeDistribution      bool
+		topn                     int
 		gatewayGetRichlistResult visor.Richlist
 		gatewayGetRichlistErr    error
 		result                   Richlist
@@ -294,6 +192,7 @@ func TestGetRichlist(t *testing.T) {
 				topn:                "1",
 				includeDistribution: "false",
 			},
+			topn:                  1,
 			gatewayGetRichlistErr: errors.New("gatewayGetRichlistErr"),
 		},
 		{
@@ -304,6 +203,7 @@ func TestGetRichlist(t *testing.T) {
 				topn:                "3",
 				includeDistribution: "false",
 			},
+			topn: 3,
 			gatewayGetRichlistResult: visor.Richlist{
 				{
 					Address: cipher.MustDecodeBase58Address("2fGC7kwAM9yZyEF1QqBqp8uo9RUsF6ENGJF"),
@@ -320,16 +220,6 @@ func TestGetRichlist(t *testing.T) {
 					Coins:   500000e6,
 					Locked:  false,
 				},
-				{
-					Address: cipher.MustDecodeBase58Address("2TmvdBWJgxMwGs84R4drS9p5fYkva4dGdfs"),
-					Coins:   244458e6,
-					Locked:  false,
-				},
-				{
-					Address: cipher.MustDecodeBase58Address("24gvUHXHtSg5drKiFsMw7iMgoN2PbLub53C"),
-					Coins:   195503e6,
-					Locked:  false,
-				},
 			},
 			result: Richlist{
 				Richlist: []readable.RichlistBalance{
@@ -422,7 +312,7 @@ func TestGetRichlist(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			endpoint := "/api/v1/richlist"
 			gateway := &MockGatewayer{}
-			gateway.On("GetRichlist", tc.includeDistribution).Return(tc.gatewayGetRichlistResult, tc.gatewayGetRichlistErr)
+			gateway.On("GetRichlist", tc.includeDistribution, tc.topn).Return(tc.gatewayGetRichlistResult, tc.gatewayGetRichlistErr)
 
 			v := url.Values{}
 			if tc.httpParams != nil {
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/SkycoinProject/cx-chains/src/params"
+	wh "github.com/SkycoinProject/cx-chains/src/util/http"
+	"github.com/SkycoinProject/cx-chains/src/visor"
+)
+
+// FeeEstimateResponse is returned by GET /api/v1/fee-estimate
+type FeeEstimateResponse struct {
+	HeadSeq uint64 `json:"head_seq"`
+	// FeePerKB is the suggested fee in coin hours per 1000 bytes for a transaction to be included in the next block
+	FeePerKB            uint64 `json:"fee_per_kb"`
+	MinFeePerKB         uint64 `json:"min_fee_per_kb"`
+	UnconfirmedFeePerKB uint64 `json:"unconfirmed_fee_per_kb"`
+	RecentFeePerKB      uint64 `json:"recent_fee_per_kb"`
+	// BurnFactor is the fraction 1/BurnFactor of the input coin hours that every transaction must burn
+	BurnFactor      uint32 `json:"burn_factor"`
+	UnconfirmedSize uint64 `json:"unconfirmed_size"`
+	MaxBlockSize    uint32 `json:"max_block_size"`
+	Blocks          uint64 `json:"blocks"`
+	FullBlocks      uint64 `json:"full_blocks"`
+	// Fullness is the average fraction of max_block_size used by the recent blocks
+	Fullness       string `json:"fullness"`
+	BurnedPerBlock uint64 `json:"burned_per_block"`
+	BurnedPerKB    uint64 `json:"burned_per_kb"`
+}
+
+// NewFeeEstimateResponse creates a FeeEstimateResponse from visor.FeeEstimate
+func NewFeeEstimateResponse(est *visor.FeeEstimate) FeeEstimateResponse {
+	return FeeEstimateResponse{
+		HeadSeq:             est.HeadSeq,
+		FeePerKB:            est.FeePerKB,
+		MinFeePerKB:         est.MinFeePerKB,
+		UnconfirmedFeePerKB: est.UnconfirmedFeePerKB,
+		RecentFeePerKB:      est.RecentFeePerKB,
+		BurnFactor:          params.UserVerifyTxn.BurnFactor,
+		UnconfirmedSize:     est.UnconfirmedSize,
+		MaxBlockSize:        est.MaxBlockSize,
+		Blocks:              est.Blocks,
+		FullBlocks:          est.FullBlocks,
+		Fullness:            strconv.FormatFloat(est.Fullness, 'f', 3, 64),
+		BurnedPerBlock:      est.BurnedPerBlock,
+		BurnedPerKB:         est.BurnedPerKB,
+	}
+}
+
+// feeEstimateHandler returns the fee in coin hours per 1000 bytes suggested for a transaction
+// to be included in the next block, in addition to the coin hours required by the burn factor.
+// The estimate is the highest of the minimum fee of the unconfirmed pool, the fee needed to be chosen
+// over the transactions of the unconfirmed pool, and the median of the lowest fees of the recent full blocks.
+// Transactions created by POST /api/v1/wallet/transaction and POST /api/v2/transaction pay it by default.
+// Method: GET
+// URI: /api/v1/fee-estimate
+func feeEstimateHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
+		est, err := gateway.GetFeeEstimate()
+		if err != nil {
+			err = fmt.Errorf("gateway.GetFeeEstimate failed: %v", err)
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		wh.SendJSONOr500(logger, w, NewFeeEstimateResponse(est))
+	}
+}
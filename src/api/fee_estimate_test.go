@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/params"
+	"github.com/SkycoinProject/cx-chains/src/visor"
+)
+
+func TestFeeEstimate(t *testing.T) {
+	est := &visor.FeeEstimate{
+		HeadSeq:             58894,
+		FeePerKB:            1201,
+		UnconfirmedFeePerKB: 1201,
+		RecentFeePerKB:      950,
+		UnconfirmedSize:     45812,
+		MaxBlockSize:        32768,
+		Blocks:              20,
+		FullBlocks:          12,
+		Fullness:            0.81428,
+		BurnedPerBlock:      2753042,
+		BurnedPerKB:         103214,
+	}
+
+	tt := []struct {
+		name          string
+		method        string
+		status        int
+		err           string
+		gatewayResult *visor.FeeEstimate
+		gatewayErr    error
+		httpResponse  FeeEstimateResponse
+	}{
+		{
+			name:   "405",
+			method: http.MethodPost,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+		{
+			name:       "500 - gateway error",
+			method:     http.MethodGet,
+			gatewayErr: errors.New("failed"),
+			status:     http.StatusInternalServerError,
+			err:        "500 Internal Server Error - gateway.GetFeeEstimate failed: failed",
+		},
+		{
+			name:          "200",
+			method:        http.MethodGet,
+			gatewayResult: est,
+			status:        http.StatusOK,
+			httpResponse: FeeEstimateResponse{
+				HeadSeq:             58894,
+				FeePerKB:            1201,
+				UnconfirmedFeePerKB: 1201,
+				RecentFeePerKB:      950,
+				BurnFactor:          params.UserVerifyTxn.BurnFactor,
+				UnconfirmedSize:     45812,
+				MaxBlockSize:        32768,
+				Blocks:              20,
+				FullBlocks:          12,
+				Fullness:            "0.814",
+				BurnedPerBlock:      2753042,
+				BurnedPerKB:         103214,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetFeeEstimate").Return(tc.gatewayResult, tc.gatewayErr)
+
+			req, err := http.NewRequest(tc.method, "/api/v1/fee-estimate", nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code, "got `%v` want `%v`", rr.Code, tc.status)
+
+			if rr.Code != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()))
+				return
+			}
+
+			var msg FeeEstimateResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &msg)
+			require.NoError(t, err)
+			require.Equal(t, tc.httpResponse, msg)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"io"
 	"time"
 
 	"github.com/SkycoinProject/cx-chains/src/cipher"
@@ -9,11 +10,14 @@ import (
 	"github.com/SkycoinProject/cx-chains/src/kvstorage"
 	"github.com/SkycoinProject/cx-chains/src/transaction"
 	"github.com/SkycoinProject/cx-chains/src/visor"
+	"github.com/SkycoinProject/cx-chains/src/visor/blockdb"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
 	"github.com/SkycoinProject/cx-chains/src/visor/historydb"
 	"github.com/SkycoinProject/cx-chains/src/wallet"
 )
 
 //go:generate mockery -name Gatewayer -case underscore -inpkg -testonly
+//go:generate mockery -name Snapshotter -case underscore -inpkg -testonly
 
 // Gateway bundles daemon.Daemon, Visor, wallet.Service and kvstorage.Manager into a single object
 type Gateway struct {
@@ -33,6 +37,16 @@ func NewGateway(d *daemon.Daemon, v *visor.Visor, w *wallet.Service, m *kvstorag
 	}
 }
 
+// Snapshot returns a read view of the blockchain at the current head block, see visor.Snapshot.
+// The snapshot must be released with Release.
+func (gw *Gateway) Snapshot() (Snapshotter, error) {
+	s, err := gw.Visor.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
 // Gatewayer interface for Gateway methods
 type Gatewayer interface {
 	Daemoner
@@ -46,11 +60,15 @@ type Daemoner interface {
 	DaemonConfig() daemon.DaemonConfig
 	GetConnection(addr string) (*daemon.Connection, error)
 	GetConnections(f func(c daemon.Connection) bool) ([]daemon.Connection, error)
+	GetConnectionSlots() daemon.ConnectionSlots
 	DisconnectByGnetID(gnetID uint64) error
 	GetDefaultConnections() []string
 	GetTrustConnections() []string
 	GetExchgConnection() []string
 	GetBlockchainProgress(headSeq uint64) *daemon.BlockchainProgress
+	GetBans() []daemon.Ban
+	BanIP(ip, reason string, duration time.Duration) (*daemon.Ban, error)
+	UnbanIP(ip string) error
 	InjectBroadcastTransaction(txn coin.Transaction) error
 }
 
@@ -58,8 +76,15 @@ type Daemoner interface {
 type Visorer interface {
 	VisorConfig() visor.Config
 	StartedAt() time.Time
+	Subscribe() (<-chan visor.Event, func())
 	HeadBkSeq() (uint64, bool, error)
 	GetBlockchainMetadata() (*visor.BlockchainMetadata, error)
+	Snapshot() (Snapshotter, error)
+	DBSize() (int64, error)
+	IsReadOnly() bool
+	BackupDB(w io.Writer) (int64, error)
+	DBStats() (dbutil.DBStats, error)
+	CompactDB() (dbutil.CompactResult, error)
 	ResendUnconfirmedTxns() ([]cipher.SHA256, error)
 	GetSignedBlockByHash(hash cipher.SHA256) (*coin.SignedBlock, error)
 	GetSignedBlockByHashVerbose(hash cipher.SHA256) (*coin.SignedBlock, [][]visor.TransactionInput, error)
@@ -70,17 +95,26 @@ type Visorer interface {
 	GetBlocksInRange(start, end uint64) ([]coin.SignedBlock, error)
 	GetBlocksInRangeVerbose(start, end uint64) ([]coin.SignedBlock, [][][]visor.TransactionInput, error)
 	GetLastBlocks(num uint64) ([]coin.SignedBlock, error)
+	GetNextBlock() (*visor.NextBlock, error)
+	GenerateDevnetBlocks(n int) ([]coin.SignedBlock, error)
 	GetLastBlocksVerbose(num uint64) ([]coin.SignedBlock, [][][]visor.TransactionInput, error)
-	GetUnspentOutputsSummary(filters []visor.OutputsFilter) (*visor.UnspentOutputsSummary, error)
 	GetBalanceOfAddrs(addrs []cipher.Address) ([]wallet.BalancePair, error)
+	GetBalancesOfAddrs(addrs []cipher.Address, minConfirmations uint64) ([]visor.AddressBalance, uint64, error)
+	GetBalancesOfAddrsAtSeq(addrs []cipher.Address, seq uint64) ([]wallet.Balance, error)
+	AddressesActivity(addrs []cipher.Address) ([]bool, error)
 	VerifyTxnVerbose(txn *coin.Transaction, signed visor.TxnSignedFlag) ([]visor.TransactionInput, bool, error)
 	AddressCount() (uint64, error)
 	GetUxOutByID(id cipher.SHA256) (*historydb.UxOut, error)
 	GetSpentOutputsForAddresses(addr []cipher.Address) ([][]historydb.UxOut, error)
 	GetVerboseTransactionsForAddress(a cipher.Address) ([]visor.Transaction, [][]visor.TransactionInput, error)
-	GetRichlist(includeDistribution bool) (visor.Richlist, error)
+	GetAddressHistory(a cipher.Address, offset, limit uint64) ([]blockdb.HistoryEntry, uint64, error)
+	GetRichlist(includeDistribution bool, n int) (visor.Richlist, error)
+	GetCoinSupply() (*visor.CoinSupply, error)
+	GetUnspentStats() (*visor.UnspentStats, error)
+	GetFeeEstimate() (*visor.FeeEstimate, error)
 	GetAllUnconfirmedTransactions() ([]visor.UnconfirmedTransaction, error)
 	GetAllUnconfirmedTransactionsVerbose() ([]visor.UnconfirmedTransaction, [][]visor.TransactionInput, error)
+	GetUnconfirmedTransactionsVerbose(filter func(visor.UnconfirmedTransaction) bool) ([]visor.UnconfirmedTransaction, [][]visor.TransactionInput, error)
 	GetTransaction(txid cipher.SHA256) (*visor.Transaction, error)
 	GetTransactionWithInputs(txid cipher.SHA256) (*visor.Transaction, []visor.TransactionInput, error)
 	GetProgramState(flts []visor.TxFilter) ([]byte, error)
@@ -93,6 +127,25 @@ type Visorer interface {
 	WalletCreateTransaction(wltID string, p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error)
 	WalletCreateTransactionSigned(wltID string, password []byte, p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error)
 	WalletSignTransaction(wltID string, password []byte, txn *coin.Transaction, signIndexes []int) (*coin.Transaction, []visor.TransactionInput, error)
+	WalletCreateTransactionHardware(wltID string, p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error)
+	WalletSignTransactionHardware(wltID string, txn *coin.Transaction) (*coin.Transaction, []visor.TransactionInput, error)
+	WalletConfirmAddressHardware(wltID string, addr cipher.Address) error
+	CreateHardwareWallet(wltName string, opts wallet.Options, n uint32) (*wallet.Wallet, error)
+}
+
+// Snapshotter interface for visor.Snapshot methods used by the API
+type Snapshotter interface {
+	GetBlockchainMetadata() (*visor.BlockchainMetadata, error)
+	GetUnspentOutputsSummary(filters []visor.OutputsFilter) (*visor.UnspentOutputsSummary, error)
+	GetBalanceOfAddrs(addrs []cipher.Address) ([]wallet.BalancePair, error)
+	Release() error
+}
+
+// releaseSnapshot releases a snapshot, logging the error if it fails
+func releaseSnapshot(s Snapshotter) {
+	if err := s.Release(); err != nil {
+		logger.WithError(err).Error("Snapshot.Release failed")
+	}
 }
 
 // Walleter interface for wallet.Service methods used by the API
@@ -101,8 +154,10 @@ type Walleter interface {
 	EncryptWallet(wltID string, password []byte) (*wallet.Wallet, error)
 	DecryptWallet(wltID string, password []byte) (*wallet.Wallet, error)
 	GetWalletSeed(wltID string, password []byte) (string, error)
-	CreateWallet(wltName string, options wallet.Options, bg wallet.BalanceGetter) (*wallet.Wallet, error)
+	CreateWallet(wltName string, options wallet.Options, bg wallet.BalanceGetter, tf wallet.TransactionsFinder) (*wallet.Wallet, error)
 	RecoverWallet(wltID, seed string, password []byte) (*wallet.Wallet, error)
+	UnlockWallet(wltID string, password []byte, timeout time.Duration) (time.Time, error)
+	LockWallet(wltID string) error
 	NewAddresses(wltID string, password []byte, n uint64) ([]cipher.Address, error)
 	GetWallet(wltID string) (*wallet.Wallet, error)
 	GetWallets() (wallet.Wallets, error)
@@ -0,0 +1,82 @@
+package grpc
+
+import (
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+)
+
+func newBlockHeader(h coin.BlockHeader) *BlockHeader {
+	return &BlockHeader{
+		Seq:          h.BkSeq,
+		Hash:         h.Hash().Hex(),
+		PreviousHash: h.PrevHash.Hex(),
+		Time:         h.Time,
+		Fee:          h.Fee,
+		Version:      h.Version,
+		BodyHash:     h.BodyHash.Hex(),
+		UxHash:       h.UxHash.Hex(),
+	}
+}
+
+func newBlock(b coin.SignedBlock) (*Block, error) {
+	txns := make([]*Transaction, len(b.Body.Transactions))
+	for i, txn := range b.Body.Transactions {
+		t, err := newTransaction(txn, b.Head.BkSeq == 0)
+		if err != nil {
+			return nil, err
+		}
+		txns[i] = t
+	}
+
+	return &Block{
+		Header:       newBlockHeader(b.Head),
+		Transactions: txns,
+		Signature:    b.Sig.Hex(),
+	}, nil
+}
+
+func newTransaction(txn coin.Transaction, isGenesis bool) (*Transaction, error) {
+	raw, err := txn.SerializeHex()
+	if err != nil {
+		return nil, err
+	}
+
+	// Genesis transaction uses empty SHA256 as the txid for its outputs
+	txid := txn.Hash()
+	outputTxID := cipher.SHA256{}
+	if !isGenesis {
+		outputTxID = txid
+	}
+
+	sigs := make([]string, len(txn.Sigs))
+	for i, s := range txn.Sigs {
+		sigs[i] = s.Hex()
+	}
+
+	in := make([]string, len(txn.In))
+	for i, h := range txn.In {
+		in[i] = h.Hex()
+	}
+
+	out := make([]*TransactionOutput, len(txn.Out))
+	for i, o := range txn.Out {
+		out[i] = &TransactionOutput{
+			Uxid:         o.UxID(outputTxID).Hex(),
+			Address:      o.Address.String(),
+			Coins:        o.Coins,
+			Hours:        o.Hours,
+			ProgramState: o.ProgramState,
+		}
+	}
+
+	return &Transaction{
+		Txid:       txid.Hex(),
+		Length:     txn.Length,
+		Type:       uint32(txn.Type),
+		InnerHash:  txn.InnerHash.Hex(),
+		Signatures: sigs,
+		Inputs:     in,
+		Outputs:    out,
+		Raw:        raw,
+	}, nil
+}
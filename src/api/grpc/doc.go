@@ -0,0 +1,19 @@
+/*
+Package grpc implements a gRPC interface to the core visor operations, alongside
+the REST API in package api.
+
+The service and its messages are defined in visor.proto: fetching blocks by
+seq, the blockchain head, address balances, the unconfirmed transaction pool,
+broadcasting a transaction and streaming new blocks.
+
+The Go client and server stubs in visor.pb.go are generated with protoc and
+protoc-gen-go v1.3.1, matching the vendored github.com/golang/protobuf:
+
+	go generate ./src/api/grpc
+
+Server implements the service with a Gatewayer. It is started and stopped by
+api.Server when api.Config.GRPCAddr is set.
+*/
+package grpc
+
+//go:generate protoc --go_out=plugins=grpc:. visor.proto
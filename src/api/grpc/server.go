@@ -0,0 +1,262 @@
+package grpc
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/daemon"
+	"github.com/SkycoinProject/cx-chains/src/util/logging"
+	"github.com/SkycoinProject/cx-chains/src/visor"
+	"github.com/SkycoinProject/cx-chains/src/wallet"
+)
+
+var (
+	logger = logging.MustGetLogger("grpc")
+)
+
+//go:generate mockery -name Gatewayer -case underscore -inpkg -testonly
+
+// Gatewayer is the subset of api.Gatewayer used by the gRPC service
+type Gatewayer interface {
+	HeadBkSeq() (uint64, bool, error)
+	GetSignedBlockBySeq(seq uint64) (*coin.SignedBlock, error)
+	GetBalanceOfAddrs(addrs []cipher.Address) ([]wallet.BalancePair, error)
+	GetAllUnconfirmedTransactions() ([]visor.UnconfirmedTransaction, error)
+	InjectBroadcastTransaction(txn coin.Transaction) error
+	Subscribe() (<-chan visor.Event, func())
+}
+
+// Server serves the Visor gRPC service
+type Server struct {
+	gateway Gatewayer
+	server  *grpc.Server
+}
+
+// NewServer creates a Server backed by gateway
+func NewServer(gateway Gatewayer) *Server {
+	s := &Server{
+		gateway: gateway,
+		server:  grpc.NewServer(),
+	}
+
+	RegisterVisorServer(s.server, s)
+
+	return s
+}
+
+// Serve accepts gRPC connections on l until Shutdown is called
+func (s *Server) Serve(l net.Listener) error {
+	logger.Infof("Starting gRPC interface on %s", l.Addr())
+	defer logger.Info("gRPC interface closed")
+
+	if err := s.server.Serve(l); err != nil && err != grpc.ErrServerStopped {
+		return err
+	}
+	return nil
+}
+
+// Shutdown closes the listeners and connections, and cancels the pending RPCs
+func (s *Server) Shutdown() {
+	if s == nil {
+		return
+	}
+
+	logger.Info("Shutting down gRPC interface")
+	defer logger.Info("gRPC interface shut down")
+	s.server.Stop()
+}
+
+// Head returns the blockchain head block header
+func (s *Server) Head(ctx context.Context, req *HeadRequest) (*BlockHeader, error) {
+	seq, ok, err := s.gateway.HeadBkSeq()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if !ok {
+		return nil, status.Error(codes.NotFound, "blockchain is empty")
+	}
+
+	b, err := s.block(seq)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Header, nil
+}
+
+// BlockBySeq returns a block by its sequence number
+func (s *Server) BlockBySeq(ctx context.Context, req *BlockBySeqRequest) (*Block, error) {
+	return s.block(req.Seq)
+}
+
+// Balances returns the confirmed and predicted balances of addresses
+func (s *Server) Balances(ctx context.Context, req *BalancesRequest) (*BalancesResponse, error) {
+	if len(req.Addresses) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "addresses is required")
+	}
+
+	addrs := make([]cipher.Address, len(req.Addresses))
+	for i, a := range req.Addresses {
+		var err error
+		addrs[i], err = cipher.DecodeBase58Address(a)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid address %q: %v", a, err)
+		}
+	}
+
+	bals, err := s.gateway.GetBalanceOfAddrs(addrs)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	rsp := &BalancesResponse{
+		Balances: make([]*AddressBalance, len(bals)),
+	}
+	for i, b := range bals {
+		rsp.Balances[i] = &AddressBalance{
+			Address: req.Addresses[i],
+			Confirmed: &Balance{
+				Coins: b.Confirmed.Coins,
+				Hours: b.Confirmed.Hours,
+			},
+			Predicted: &Balance{
+				Coins: b.Predicted.Coins,
+				Hours: b.Predicted.Hours,
+			},
+		}
+	}
+
+	return rsp, nil
+}
+
+// Mempool returns the unconfirmed transaction pool
+func (s *Server) Mempool(ctx context.Context, req *MempoolRequest) (*MempoolResponse, error) {
+	txns, err := s.gateway.GetAllUnconfirmedTransactions()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	rsp := &MempoolResponse{
+		Transactions: make([]*UnconfirmedTransaction, len(txns)),
+	}
+	for i, txn := range txns {
+		t, err := newTransaction(txn.Transaction, false)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		rsp.Transactions[i] = &UnconfirmedTransaction{
+			Transaction: t,
+			Received:    txn.Received,
+			Checked:     txn.Checked,
+			Announced:   txn.Announced,
+			IsValid:     txn.IsValid == 1,
+		}
+	}
+
+	return rsp, nil
+}
+
+// BroadcastTransaction injects a serialized transaction and broadcasts it to peers
+func (s *Server) BroadcastTransaction(ctx context.Context, req *BroadcastTransactionRequest) (*BroadcastTransactionResponse, error) {
+	if req.Raw == "" {
+		return nil, status.Error(codes.InvalidArgument, "raw is required")
+	}
+
+	txn, err := coin.DeserializeTransactionHex(req.Raw)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.gateway.InjectBroadcastTransaction(txn); err != nil {
+		switch err.(type) {
+		case visor.ErrTxnViolatesUserConstraint,
+			visor.ErrTxnViolatesHardConstraint,
+			visor.ErrTxnViolatesSoftConstraint,
+			visor.ErrTxnViolatesPoolPolicy:
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case visor.ErrTxnConflict:
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		default:
+			if daemon.IsBroadcastFailure(err) {
+				return nil, status.Error(codes.Unavailable, err.Error())
+			}
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	return &BroadcastTransactionResponse{
+		Txid: txn.Hash().Hex(),
+	}, nil
+}
+
+// StreamBlocks streams blocks starting from a sequence number, then new blocks as they are added
+func (s *Server) StreamBlocks(req *StreamBlocksRequest, stream Visor_StreamBlocksServer) error {
+	next := req.StartSeq
+
+	// Subscribe before reading the head, so that no block is missed in between
+	events, unsubscribe := s.gateway.Subscribe()
+	defer unsubscribe()
+
+	for {
+		head, ok, err := s.gateway.HeadBkSeq()
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+
+		for ; ok && next <= head; next++ {
+			b, err := s.block(next)
+			if err != nil {
+				return err
+			}
+
+			if err := stream.Send(b); err != nil {
+				return err
+			}
+		}
+
+		if err := waitForBlock(stream.Context(), events); err != nil {
+			return err
+		}
+	}
+}
+
+// waitForBlock waits for a visor.EventBlockExecuted event
+func waitForBlock(ctx context.Context, events <-chan visor.Event) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e, ok := <-events:
+			if !ok {
+				return status.Error(codes.Unavailable, "visor event subscription closed")
+			}
+			if e.Type == visor.EventBlockExecuted {
+				return nil
+			}
+		}
+	}
+}
+
+func (s *Server) block(seq uint64) (*Block, error) {
+	b, err := s.gateway.GetSignedBlockBySeq(seq)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if b == nil {
+		return nil, status.Errorf(codes.NotFound, "block %d not found", seq)
+	}
+
+	pb, err := newBlock(*b)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return pb, nil
+}
@@ -0,0 +1,308 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/daemon"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+	"github.com/SkycoinProject/cx-chains/src/visor"
+	"github.com/SkycoinProject/cx-chains/src/wallet"
+)
+
+func makeTransaction(t *testing.T) coin.Transaction {
+	p, s := cipher.GenerateKeyPair()
+	ux := coin.UxOut{
+		Body: coin.UxBody{
+			SrcTransaction: testutil.RandSHA256(t),
+			Address:        cipher.AddressFromPubKey(p),
+			Coins:          2e6,
+			Hours:          100,
+		},
+	}
+
+	txn := coin.Transaction{}
+	err := txn.PushInput(ux.Hash())
+	require.NoError(t, err)
+	err = txn.PushOutput(testutil.MakeAddress(), 2e6, 50, []byte("state"))
+	require.NoError(t, err)
+	txn.SignInputs([]cipher.SecKey{s})
+	err = txn.UpdateHeader()
+	require.NoError(t, err)
+	return txn
+}
+
+func makeBlock(t *testing.T, seq uint64) *coin.SignedBlock {
+	return &coin.SignedBlock{
+		Block: coin.Block{
+			Head: coin.BlockHeader{
+				BkSeq:    seq,
+				Version:  1,
+				Time:     1000 + seq,
+				Fee:      10,
+				PrevHash: testutil.RandSHA256(t),
+				BodyHash: testutil.RandSHA256(t),
+				UxHash:   testutil.RandSHA256(t),
+			},
+			Body: coin.BlockBody{
+				Transactions: coin.Transactions{makeTransaction(t)},
+			},
+		},
+		Sig: testutil.RandSig(t),
+	}
+}
+
+// startServer serves a Server backed by gateway on a local port, and returns a client connected to it
+func startServer(t *testing.T, gateway Gatewayer) (VisorClient, func()) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := NewServer(gateway)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		err := s.Serve(l)
+		require.NoError(t, err)
+	}()
+
+	conn, err := grpc.Dial(l.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+
+	return NewVisorClient(conn), func() {
+		require.NoError(t, conn.Close())
+		s.Shutdown()
+		<-done
+	}
+}
+
+func requireStatus(t *testing.T, code codes.Code, msg string, err error) {
+	st, ok := status.FromError(err)
+	require.True(t, ok, "not a status error: %v", err)
+	require.Equal(t, code, st.Code())
+	require.Equal(t, msg, st.Message())
+}
+
+func TestNewBlock(t *testing.T) {
+	b := makeBlock(t, 3)
+	txn := b.Body.Transactions[0]
+
+	pb, err := newBlock(*b)
+	require.NoError(t, err)
+
+	require.Equal(t, &BlockHeader{
+		Seq:          3,
+		Hash:         b.HashHeader().Hex(),
+		PreviousHash: b.Head.PrevHash.Hex(),
+		Time:         1003,
+		Fee:          10,
+		Version:      1,
+		BodyHash:     b.Head.BodyHash.Hex(),
+		UxHash:       b.Head.UxHash.Hex(),
+	}, pb.Header)
+	require.Equal(t, b.Sig.Hex(), pb.Signature)
+
+	require.Len(t, pb.Transactions, 1)
+	require.Equal(t, &Transaction{
+		Txid:       txn.Hash().Hex(),
+		Length:     txn.Length,
+		Type:       uint32(txn.Type),
+		InnerHash:  txn.InnerHash.Hex(),
+		Signatures: []string{txn.Sigs[0].Hex()},
+		Inputs:     []string{txn.In[0].Hex()},
+		Outputs: []*TransactionOutput{
+			{
+				Uxid:         txn.Out[0].UxID(txn.Hash()).Hex(),
+				Address:      txn.Out[0].Address.String(),
+				Coins:        2e6,
+				Hours:        50,
+				ProgramState: []byte("state"),
+			},
+		},
+		Raw: txn.MustSerializeHex(),
+	}, pb.Transactions[0])
+
+	// Genesis block outputs use an empty txid for their uxids
+	b.Head.BkSeq = 0
+	pb, err = newBlock(*b)
+	require.NoError(t, err)
+	require.Equal(t, txn.Out[0].UxID(cipher.SHA256{}).Hex(), pb.Transactions[0].Outputs[0].Uxid)
+}
+
+func TestServer(t *testing.T) {
+	gateway := &MockGatewayer{}
+	client, stop := startServer(t, gateway)
+	defer stop()
+
+	ctx := context.Background()
+	b := makeBlock(t, 5)
+
+	// Head
+	gateway.On("HeadBkSeq").Return(uint64(5), true, nil).Once()
+	gateway.On("GetSignedBlockBySeq", uint64(5)).Return(b, nil)
+	h, err := client.Head(ctx, &HeadRequest{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), h.Seq)
+	require.Equal(t, b.HashHeader().Hex(), h.Hash)
+
+	gateway.On("HeadBkSeq").Return(uint64(0), false, nil).Once()
+	_, err = client.Head(ctx, &HeadRequest{})
+	requireStatus(t, codes.NotFound, "blockchain is empty", err)
+
+	// BlockBySeq
+	pb, err := client.BlockBySeq(ctx, &BlockBySeqRequest{Seq: 5})
+	require.NoError(t, err)
+	require.Equal(t, b.Sig.Hex(), pb.Signature)
+	require.Len(t, pb.Transactions, 1)
+
+	gateway.On("GetSignedBlockBySeq", uint64(6)).Return(nil, nil)
+	_, err = client.BlockBySeq(ctx, &BlockBySeqRequest{Seq: 6})
+	requireStatus(t, codes.NotFound, "block 6 not found", err)
+
+	gateway.On("GetSignedBlockBySeq", uint64(7)).Return(nil, errors.New("db error"))
+	_, err = client.BlockBySeq(ctx, &BlockBySeqRequest{Seq: 7})
+	requireStatus(t, codes.Internal, "db error", err)
+
+	// Balances
+	addr := testutil.MakeAddress()
+	gateway.On("GetBalanceOfAddrs", []cipher.Address{addr}).Return([]wallet.BalancePair{
+		{
+			Confirmed: wallet.Balance{Coins: 1e6, Hours: 2},
+			Predicted: wallet.Balance{Coins: 3e6, Hours: 4},
+		},
+	}, nil)
+	bals, err := client.Balances(ctx, &BalancesRequest{
+		Addresses: []string{addr.String()},
+	})
+	require.NoError(t, err)
+	require.Len(t, bals.Balances, 1)
+	require.Equal(t, addr.String(), bals.Balances[0].Address)
+	require.Equal(t, uint64(1e6), bals.Balances[0].Confirmed.Coins)
+	require.Equal(t, uint64(2), bals.Balances[0].Confirmed.Hours)
+	require.Equal(t, uint64(3e6), bals.Balances[0].Predicted.Coins)
+	require.Equal(t, uint64(4), bals.Balances[0].Predicted.Hours)
+
+	_, err = client.Balances(ctx, &BalancesRequest{})
+	requireStatus(t, codes.InvalidArgument, "addresses is required", err)
+
+	_, err = client.Balances(ctx, &BalancesRequest{
+		Addresses: []string{"foo"},
+	})
+	requireStatus(t, codes.InvalidArgument, `invalid address "foo": Invalid address length`, err)
+
+	// Mempool
+	txn := makeTransaction(t)
+	gateway.On("GetAllUnconfirmedTransactions").Return([]visor.UnconfirmedTransaction{
+		{
+			Transaction: txn,
+			Received:    1,
+			Checked:     2,
+			Announced:   3,
+			IsValid:     1,
+		},
+	}, nil)
+	mp, err := client.Mempool(ctx, &MempoolRequest{})
+	require.NoError(t, err)
+	require.Len(t, mp.Transactions, 1)
+	require.Equal(t, txn.Hash().Hex(), mp.Transactions[0].Transaction.Txid)
+	require.Equal(t, int64(1), mp.Transactions[0].Received)
+	require.Equal(t, int64(2), mp.Transactions[0].Checked)
+	require.Equal(t, int64(3), mp.Transactions[0].Announced)
+	require.True(t, mp.Transactions[0].IsValid)
+
+	// BroadcastTransaction
+	gateway.On("InjectBroadcastTransaction", txn).Return(nil).Once()
+	rsp, err := client.BroadcastTransaction(ctx, &BroadcastTransactionRequest{
+		Raw: txn.MustSerializeHex(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, txn.Hash().Hex(), rsp.Txid)
+
+	_, err = client.BroadcastTransaction(ctx, &BroadcastTransactionRequest{})
+	requireStatus(t, codes.InvalidArgument, "raw is required", err)
+
+	for _, tc := range []struct {
+		err  error
+		code codes.Code
+	}{
+		{visor.NewErrTxnViolatesHardConstraint(errors.New("bad txn")), codes.InvalidArgument},
+		{visor.NewErrTxnConflict([]cipher.SHA256{testutil.RandSHA256(t)}), codes.AlreadyExists},
+		{daemon.ErrNetworkingDisabled, codes.Unavailable},
+		{errors.New("inject failed"), codes.Internal},
+	} {
+		gateway.On("InjectBroadcastTransaction", txn).Return(tc.err).Once()
+		_, err = client.BroadcastTransaction(ctx, &BroadcastTransactionRequest{
+			Raw: txn.MustSerializeHex(),
+		})
+		requireStatus(t, tc.code, tc.err.Error(), err)
+	}
+
+	gateway.AssertExpectations(t)
+}
+
+func TestServerStreamBlocks(t *testing.T) {
+	gateway := &MockGatewayer{}
+	client, stop := startServer(t, gateway)
+	defer stop()
+
+	blocks := []*coin.SignedBlock{makeBlock(t, 0), makeBlock(t, 1), makeBlock(t, 2)}
+	for i, b := range blocks {
+		gateway.On("GetSignedBlockBySeq", uint64(i)).Return(b, nil)
+	}
+
+	events := make(chan visor.Event, 2)
+	unsubscribed := make(chan struct{})
+	gateway.On("Subscribe").Return((<-chan visor.Event)(events), func() {
+		close(unsubscribed)
+	})
+
+	// Block 1 is sent immediately, and block 2 once it is executed
+	gateway.On("HeadBkSeq").Return(uint64(1), true, nil).Once()
+	gateway.On("HeadBkSeq").Return(uint64(2), true, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.StreamBlocks(ctx, &StreamBlocksRequest{StartSeq: 1})
+	require.NoError(t, err)
+
+	b, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), b.Header.Seq)
+	require.Equal(t, blocks[1].HashHeader().Hex(), b.Header.Hash)
+
+	// Unconfirmed pool events do not wake up the stream
+	events <- visor.Event{
+		Type: visor.EventUnconfirmedInjected,
+	}
+	events <- visor.Event{
+		Type:  visor.EventBlockExecuted,
+		Block: blocks[2],
+	}
+
+	b, err = stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), b.Header.Seq)
+	require.Equal(t, blocks[2].HashHeader().Hex(), b.Header.Hash)
+
+	cancel()
+	_, err = stream.Recv()
+	requireStatus(t, codes.Canceled, "context canceled", err)
+
+	select {
+	case <-unsubscribed:
+	case <-time.After(time.Second):
+		t.Fatal("StreamBlocks did not unsubscribe")
+	}
+
+	gateway.AssertNumberOfCalls(t, "HeadBkSeq", 2)
+}
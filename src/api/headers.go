@@ -0,0 +1,166 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SkycoinProject/cx-chains/src/readable"
+	wh "github.com/SkycoinProject/cx-chains/src/util/http"
+)
+
+// headersBatchSize is the number of blocks loaded at a time when streaming headers
+const headersBatchSize = 1000
+
+// headersHandler streams the compact headers of the blocks from a seq to the head block.
+// The response is chunked and flushed after each batch of headers.
+// The ETag of the response is the hash of the head block, and its Last-Modified time is the time of the head block,
+// so that requests with If-None-Match or If-Modified-Since are answered with 304 Not Modified until a new block is created.
+// URI: /api/v1/headers
+// Method: GET
+// Args:
+//	since: seq of the first header [optional, default 0]
+func headersHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
+		var since uint64
+		if s := r.FormValue("since"); s != "" {
+			var err error
+			since, err = strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				wh.Error400(w, "Invalid since value")
+				return
+			}
+		}
+
+		headSeq, ok, err := gateway.HeadBkSeq()
+		if err != nil {
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		if !ok {
+			wh.SendJSONOr500(logger, w, []readable.CompactBlockHeader{})
+			return
+		}
+
+		head, err := gateway.GetSignedBlockBySeq(headSeq)
+		if err != nil {
+			wh.Error500(w, err.Error())
+			return
+		}
+		if head == nil {
+			wh.Error500(w, fmt.Sprintf("Head block %d not found", headSeq))
+			return
+		}
+
+		etag := strconv.Quote(head.HashHeader().Hex())
+		lastModified := time.Unix(int64(head.Head.Time), 0).UTC()
+
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+
+		if notModified(r, etag, lastModified) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		flush := func() {}
+		if f, ok := w.(http.Flusher); ok {
+			flush = f.Flush
+		}
+
+		cw := &countingWriter{w: w}
+		if err := writeHeaders(cw, flush, gateway, since, headSeq); err != nil {
+			// The response status can only be set if nothing has been written yet
+			if cw.n == 0 {
+				wh.Error500(w, err.Error())
+				return
+			}
+
+			logger.WithError(err).Errorf("Streaming headers failed after writing %d bytes", cw.n)
+		}
+	}
+}
+
+// writeHeaders writes a JSON array of the compact headers of the blocks from since to headSeq,
+// loading the blocks in batches of headersBatchSize and calling flush after each batch
+func writeHeaders(w io.Writer, flush func(), gateway Gatewayer, since, headSeq uint64) error {
+	// Nothing is written until the first batch is loaded, so that an error loading it can still be returned as a 500 status
+	sep := "["
+	for start := since; start <= headSeq; start += headersBatchSize {
+		end := start + headersBatchSize - 1
+		if end > headSeq || end < start {
+			end = headSeq
+		}
+
+		blocks, err := gateway.GetBlocksInRange(start, end)
+		if err != nil {
+			return err
+		}
+
+		for _, b := range blocks {
+			if _, err := io.WriteString(w, sep); err != nil {
+				return err
+			}
+			sep = ","
+
+			data, err := json.Marshal(readable.NewCompactBlockHeader(b.Head))
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(data); err != nil {
+				return err
+			}
+		}
+
+		flush()
+
+		if end == headSeq {
+			break
+		}
+	}
+
+	if sep == "[" {
+		if _, err := io.WriteString(w, sep); err != nil {
+			return err
+		}
+	}
+
+	_, err := io.WriteString(w, "]\n")
+	return err
+}
+
+// notModified returns true if the conditional headers of the request match the etag or the last modified time.
+// If-None-Match takes precedence over If-Modified-Since.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, t := range strings.Split(inm, ",") {
+			t = strings.TrimSpace(t)
+			if t == "*" || strings.TrimPrefix(t, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		t, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+		return !lastModified.After(t)
+	}
+
+	return false
+}
@@ -0,0 +1,215 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/readable"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+)
+
+func TestHeaders(t *testing.T) {
+	blocks := make([]coin.SignedBlock, 3)
+	var prevHash cipher.SHA256
+	for i := range blocks {
+		blocks[i] = coin.SignedBlock{
+			Block: coin.Block{
+				Head: coin.BlockHeader{
+					BkSeq:    uint64(i),
+					Time:     uint64(1600000000 + i*10),
+					PrevHash: prevHash,
+					BodyHash: testutil.RandSHA256(t),
+				},
+			},
+		}
+		prevHash = blocks[i].HashHeader()
+	}
+
+	headers := make([]readable.CompactBlockHeader, len(blocks))
+	for i, b := range blocks {
+		headers[i] = readable.NewCompactBlockHeader(b.Head)
+	}
+
+	head := &blocks[2]
+	etag := strconv.Quote(head.HashHeader().Hex())
+	lastModified := time.Unix(int64(head.Head.Time), 0).UTC().Format(http.TimeFormat)
+
+	type rangeArgs struct {
+		start, end uint64
+	}
+
+	tt := []struct {
+		name            string
+		method          string
+		since           string
+		ifNoneMatch     string
+		ifModifiedSince string
+		headSeqErr      error
+		noHead          bool
+		blocksInRange   *rangeArgs
+		blocksErr       error
+		status          int
+		err             string
+		headers         []readable.CompactBlockHeader
+	}{
+		{
+			name:   "405",
+			method: http.MethodPost,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+		{
+			name:   "400 - invalid since",
+			method: http.MethodGet,
+			since:  "foo",
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - Invalid since value",
+		},
+		{
+			name:       "500 - HeadBkSeq failed",
+			method:     http.MethodGet,
+			headSeqErr: errors.New("HeadBkSeq failed"),
+			status:     http.StatusInternalServerError,
+			err:        "500 Internal Server Error - HeadBkSeq failed",
+		},
+		{
+			name:          "500 - GetBlocksInRange failed",
+			method:        http.MethodGet,
+			blocksInRange: &rangeArgs{0, 2},
+			blocksErr:     errors.New("GetBlocksInRange failed"),
+			status:        http.StatusInternalServerError,
+			err:           "500 Internal Server Error - GetBlocksInRange failed",
+		},
+		{
+			name:    "200 - no blocks",
+			method:  http.MethodGet,
+			noHead:  true,
+			status:  http.StatusOK,
+			headers: []readable.CompactBlockHeader{},
+		},
+		{
+			name:          "200 - all headers",
+			method:        http.MethodGet,
+			blocksInRange: &rangeArgs{0, 2},
+			status:        http.StatusOK,
+			headers:       headers,
+		},
+		{
+			name:          "200 - since",
+			method:        http.MethodGet,
+			since:         "1",
+			blocksInRange: &rangeArgs{1, 2},
+			status:        http.StatusOK,
+			headers:       headers[1:],
+		},
+		{
+			name:    "200 - since after head",
+			method:  http.MethodGet,
+			since:   "3",
+			status:  http.StatusOK,
+			headers: []readable.CompactBlockHeader{},
+		},
+		{
+			name:        "304 - If-None-Match",
+			method:      http.MethodGet,
+			since:       "1",
+			ifNoneMatch: `"foo", ` + etag,
+			status:      http.StatusNotModified,
+		},
+		{
+			name:          "200 - If-None-Match not matching",
+			method:        http.MethodGet,
+			since:         "1",
+			ifNoneMatch:   `"foo"`,
+			blocksInRange: &rangeArgs{1, 2},
+			status:        http.StatusOK,
+			headers:       headers[1:],
+		},
+		{
+			name:            "304 - If-Modified-Since",
+			method:          http.MethodGet,
+			ifModifiedSince: lastModified,
+			status:          http.StatusNotModified,
+		},
+		{
+			name:            "200 - If-Modified-Since before head block",
+			method:          http.MethodGet,
+			ifModifiedSince: time.Unix(int64(blocks[1].Head.Time), 0).UTC().Format(http.TimeFormat),
+			blocksInRange:   &rangeArgs{0, 2},
+			status:          http.StatusOK,
+			headers:         headers,
+		},
+		{
+			name:            "200 - If-None-Match takes precedence over If-Modified-Since",
+			method:          http.MethodGet,
+			ifNoneMatch:     `"foo"`,
+			ifModifiedSince: lastModified,
+			blocksInRange:   &rangeArgs{0, 2},
+			status:          http.StatusOK,
+			headers:         headers,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("HeadBkSeq").Return(uint64(2), !tc.noHead, tc.headSeqErr)
+			gateway.On("GetSignedBlockBySeq", uint64(2)).Return(head, nil)
+			if tc.blocksInRange != nil {
+				var result []coin.SignedBlock
+				if tc.blocksErr == nil {
+					result = blocks[tc.blocksInRange.start : tc.blocksInRange.end+1]
+				}
+				gateway.On("GetBlocksInRange", tc.blocksInRange.start, tc.blocksInRange.end).Return(result, tc.blocksErr)
+			}
+
+			endpoint := "/api/v1/headers"
+			if tc.since != "" {
+				endpoint += "?since=" + tc.since
+			}
+
+			req, err := http.NewRequest(tc.method, endpoint, nil)
+			require.NoError(t, err)
+			if tc.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tc.ifNoneMatch)
+			}
+			if tc.ifModifiedSince != "" {
+				req.Header.Set("If-Modified-Since", tc.ifModifiedSince)
+			}
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code, "got `%v` want `%v`", rr.Code, tc.status)
+
+			switch rr.Code {
+			case http.StatusOK:
+				var msg []readable.CompactBlockHeader
+				err = json.Unmarshal(rr.Body.Bytes(), &msg)
+				require.NoError(t, err)
+				require.Equal(t, tc.headers, msg)
+
+				if !tc.noHead {
+					require.Equal(t, etag, rr.Header().Get("ETag"))
+					require.Equal(t, lastModified, rr.Header().Get("Last-Modified"))
+				}
+			case http.StatusNotModified:
+				require.Empty(t, rr.Body.String())
+				require.Equal(t, etag, rr.Header().Get("ETag"))
+			default:
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()))
+			}
+		})
+	}
+}
@@ -242,9 +242,9 @@ func TestHealthHandler(t *testing.T) {
 			require.Equal(t, tc.cfg.enableGUI, r.GUIEnabled)
 			require.Equal(t, tc.walletAPIEnabled, r.WalletAPIEnabled)
 
-			require.Equal(t, uint32(10), r.UserVerifyTxn.BurnFactor)
-			require.Equal(t, uint32(32*1024), r.UserVerifyTxn.MaxTransactionSize)
-			require.Equal(t, uint8(3), r.UserVerifyTxn.MaxDropletPrecision)
+			require.Equal(t, params.UserVerifyTxn.BurnFactor, r.UserVerifyTxn.BurnFactor)
+			require.Equal(t, params.UserVerifyTxn.MaxTransactionSize, r.UserVerifyTxn.MaxTransactionSize)
+			require.Equal(t, params.UserVerifyTxn.MaxDropletPrecision, r.UserVerifyTxn.MaxDropletPrecision)
 
 			require.Equal(t, dc.UnconfirmedVerifyTxn.BurnFactor, r.UnconfirmedVerifyTxn.BurnFactor)
 			require.Equal(t, dc.UnconfirmedVerifyTxn.MaxTransactionSize, r.UnconfirmedVerifyTxn.MaxTransactionSize)
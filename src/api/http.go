@@ -18,6 +18,7 @@ import (
 	"github.com/NYTimes/gziphandler"
 	"github.com/rs/cors"
 
+	"github.com/SkycoinProject/cx-chains/src/api/grpc"
 	"github.com/SkycoinProject/cx-chains/src/cipher"
 	"github.com/SkycoinProject/cx-chains/src/readable"
 	"github.com/SkycoinProject/cx-chains/src/util/file"
@@ -58,13 +59,20 @@ const (
 	EndpointsNetCtrl = "NET_CTRL"
 	// EndpointsStorage endpoints implement interface for key-value storage for arbitrary data
 	EndpointsStorage = "STORAGE"
+	// EndpointsAdmin endpoints for administering the node at runtime, such as changing log levels
+	EndpointsAdmin = "ADMIN"
 )
 
 // Server exposes an HTTP API
 type Server struct {
 	server   *http.Server
 	listener net.Listener
-	done     chan struct{}
+	wsHub      *wsHub
+	webhookHub *webhookHub
+	done       chan struct{}
+
+	grpcServer   *grpc.Server
+	grpcListener net.Listener
 }
 
 // Config configures Server
@@ -82,6 +90,29 @@ type Config struct {
 	EnabledAPISets     map[string]struct{}
 	Username           string
 	Password           string
+	// APIToken is accepted as a bearer token in the Authorization header. Token auth is disabled if empty
+	APIToken string
+	// AuthMutatingOnly only requires authentication for the requests which can change the state of the node,
+	// so that GET requests can be made without authentication. The ADMIN, WALLET and INSECURE_WALLET_SEED API sets
+	// always require authentication
+	AuthMutatingOnly bool
+	// APIKeys authenticate the requests of automated services without a CSRF token, limited to the API sets of their scopes
+	APIKeys []APIKey
+	// RateLimit is the number of requests per second allowed per IP address or API key. Rate limiting is disabled if 0
+	RateLimit float64
+	// RateLimitBurst is the number of requests an IP address or API key can make at once
+	RateLimitBurst int
+	// ExpensiveRateLimit is the number of requests per second allowed per IP address or API key
+	// to the endpoints which dump large parts of the blockchain. Disabled if 0
+	ExpensiveRateLimit float64
+	// ExpensiveRateLimitBurst is the number of requests to the expensive endpoints an IP address or API key can make at once
+	ExpensiveRateLimitBurst int
+	// GRPCAddr is the address of the gRPC interface. It is disabled if empty
+	GRPCAddr string
+	// WebhooksFile persists the webhooks registered through the admin API. They are only kept in memory if empty
+	WebhooksFile string
+	// SigningKey signs the responses of the chain tip and balance endpoints. Responses are not signed if it is null
+	SigningKey cipher.SecKey
 }
 
 // HealthConfig configuration data exposed in /health
@@ -102,7 +133,17 @@ type muxConfig struct {
 	hostWhitelist      []string
 	username           string
 	password           string
+	apiToken           string
+	authMutatingOnly   bool
+	apiKeys            apiKeys
+	rateLimit          float64
+	rateLimitBurst     int
+	expensiveRateLimit float64
+	expensiveBurst     int
 	health             HealthConfig
+	wsHub              *wsHub
+	webhookHub         *webhookHub
+	signingKey         cipher.SecKey
 }
 
 // HTTPResponse represents the http response struct
@@ -175,6 +216,11 @@ func create(host string, c Config, gateway Gatewayer) (*Server, error) {
 		logger.Warning("Header check disabled")
 	}
 
+	webhooks, err := newWebhookHub(gateway, c.WebhooksFile)
+	if err != nil {
+		return nil, err
+	}
+
 	if c.ReadTimeout == 0 {
 		c.ReadTimeout = defaultReadTimeout
 	}
@@ -197,6 +243,16 @@ func create(host string, c Config, gateway Gatewayer) (*Server, error) {
 		hostWhitelist:      c.HostWhitelist,
 		username:           c.Username,
 		password:           c.Password,
+		apiToken:           c.APIToken,
+		authMutatingOnly:   c.AuthMutatingOnly,
+		apiKeys:            newAPIKeys(c.APIKeys),
+		rateLimit:          c.RateLimit,
+		rateLimitBurst:     c.RateLimitBurst,
+		expensiveRateLimit: c.ExpensiveRateLimit,
+		expensiveBurst:     c.ExpensiveRateLimitBurst,
+		wsHub:              newWSHub(gateway),
+		webhookHub:         webhooks,
+		signingKey:         c.SigningKey,
 	}
 
 	srvMux := newServerMux(mc, gateway)
@@ -208,10 +264,24 @@ func create(host string, c Config, gateway Gatewayer) (*Server, error) {
 		// MaxHeaderBytes: http.DefaultMaxHeaderBytes, // adjust this to allow longer GET queries
 	}
 
-	return &Server{
+	s := &Server{
 		server: srv,
-		done:   make(chan struct{}),
-	}, nil
+		wsHub:      mc.wsHub,
+		webhookHub: mc.webhookHub,
+		done:       make(chan struct{}),
+	}
+
+	if c.GRPCAddr != "" {
+		l, err := net.Listen("tcp", c.GRPCAddr)
+		if err != nil {
+			return nil, err
+		}
+
+		s.grpcListener = l
+		s.grpcServer = grpc.NewServer(gateway)
+	}
+
+	return s, nil
 }
 
 // Create creates a new Server instance that listens on HTTP
@@ -229,8 +299,8 @@ func Create(host string, c Config, gateway Gatewayer) (*Server, error) {
 
 	s, err := create(host, c, gateway)
 	if err != nil {
-		if closeErr := s.listener.Close(); closeErr != nil {
-			logger.WithError(err).Warning("s.listener.Close() error")
+		if closeErr := listener.Close(); closeErr != nil {
+			logger.WithError(closeErr).Warning("listener.Close() error")
 		}
 		return nil, err
 	}
@@ -263,8 +333,8 @@ func CreateHTTPS(host string, c Config, gateway Gatewayer, certFile, keyFile str
 
 	s, err := create(host, c, gateway)
 	if err != nil {
-		if closeErr := s.listener.Close(); closeErr != nil {
-			logger.WithError(err).Warning("s.listener.Close() error")
+		if closeErr := listener.Close(); closeErr != nil {
+			logger.WithError(closeErr).Warning("listener.Close() error")
 		}
 		return nil, err
 	}
@@ -282,12 +352,28 @@ func (s *Server) Addr() string {
 	return s.listener.Addr().String()
 }
 
-// Serve serves the web interface on the configured host
+// GRPCAddr returns the listening address of the gRPC interface, or an empty string if it is disabled
+func (s *Server) GRPCAddr() string {
+	if s == nil || s.grpcListener == nil {
+		return ""
+	}
+	return s.grpcListener.Addr().String()
+}
+
+// Serve serves the web interface on the configured host, and the gRPC interface if it is enabled
 func (s *Server) Serve() error {
 	logger.Infof("Starting web interface on %s", s.listener.Addr())
 	defer logger.Info("Web interface closed")
 	defer close(s.done)
 
+	if s.grpcServer != nil {
+		go func() {
+			if err := s.grpcServer.Serve(s.grpcListener); err != nil {
+				logger.WithError(err).Error("gRPC interface failed")
+			}
+		}()
+	}
+
 	if err := s.server.Serve(s.listener); err != nil {
 		if err != http.ErrServerClosed {
 			return err
@@ -307,6 +393,9 @@ func (s *Server) Shutdown() {
 	if err := s.listener.Close(); err != nil {
 		logger.WithError(err).Warning("s.listener.Close() error")
 	}
+	s.wsHub.Shutdown()
+	s.webhookHub.Shutdown()
+	s.grpcServer.Shutdown()
 	<-s.done
 }
 
@@ -328,6 +417,10 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 		OptionsPassthrough: false,
 	})
 
+	// The limiters are shared by all endpoints, so that clients are limited across the API
+	limiter := newRateLimiter(c.rateLimit, c.rateLimitBurst)
+	expensiveLimiter := newRateLimiter(c.expensiveRateLimit, c.expensiveBurst)
+
 	headerCheck := func(apiVersion, host string, hostWhitelist []string, handler http.Handler) http.Handler {
 		handler = originRefererCheck(apiVersion, host, hostWhitelist, handler)
 		handler = hostCheck(apiVersion, host, hostWhitelist, handler)
@@ -377,9 +470,19 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 		})
 	}
 
-	webHandlerWithOptionals := func(apiVersion, endpoint string, handlerFunc http.Handler, checkCSRF, checkHeaders bool) {
+	webHandlerWithOptionals := func(apiVersion, endpoint string, handlerFunc http.Handler, methodAPISets map[string][]string, checkCSRF, checkHeaders bool) {
+		if _, ok := signedEndpoints[endpoint]; ok && !c.signingKey.Null() {
+			handlerFunc = signResponse(c.signingKey, handlerFunc)
+		}
+
 		handler := wh.ElapsedHandler(logger, handlerFunc)
 
+		// Requests with an API key skip the CSRF, header and auth checks
+		keyHandler := handler
+		if apiVersion == apiVersion2 {
+			keyHandler = ContentTypeJSONRequired(keyHandler)
+		}
+
 		handler = corsHandler.Handler(handler)
 
 		if checkCSRF {
@@ -394,8 +497,22 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 			handler = ContentTypeJSONRequired(handler)
 		}
 
-		handler = basicAuth(apiVersion, c.username, c.password, "skycoin daemon", handler)
+		handler = authCheck(apiVersion, authConfig{
+			username:     c.username,
+			password:     c.password,
+			token:        c.apiToken,
+			mutatingOnly: c.authMutatingOnly,
+		}, methodAPISets, "skycoin daemon", handler)
+		handler = apiKeyCheck(apiVersion, c.apiKeys, methodAPISets, keyHandler, handler)
+
+		limiters := []*rateLimiter{limiter}
+		if _, ok := expensiveEndpoints[endpoint]; ok {
+			limiters = append(limiters, expensiveLimiter)
+		}
+		handler = rateLimit(apiVersion, c.apiKeys, limiters, handler)
+
 		handler = gziphandler.GzipHandler(handler)
+		handler = countRequests(endpoint, handler)
 		mux.Handle(endpoint, handler)
 	}
 
@@ -406,7 +523,7 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 			handler = forMethodAPISets(apiVersion, handler, methodAPISets)
 		}
 
-		webHandlerWithOptionals(apiVersion, endpoint, handler, true, !c.disableHeaderCheck)
+		webHandlerWithOptionals(apiVersion, endpoint, handler, methodAPISets, true, !c.disableHeaderCheck)
 	}
 
 	webHandlerV1 := func(endpoint string, handler http.Handler, methodAPISets map[string][]string) {
@@ -446,7 +563,7 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 
 	// get the current CSRF token
 	csrfHandlerV1 := func(endpoint string, handler http.Handler) {
-		webHandlerWithOptionals(apiVersion1, "/api/v1"+endpoint, handler, false, !c.disableHeaderCheck)
+		webHandlerWithOptionals(apiVersion1, "/api/v1"+endpoint, handler, nil, false, !c.disableHeaderCheck)
 	}
 	csrfHandlerV1("/csrf", getCSRFToken(c.disableCSRF)) // csrf is always available, regardless of the API set
 
@@ -496,6 +613,12 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 	webHandlerV2("/wallet/seed/verify", http.HandlerFunc(walletVerifySeedHandler), map[string][]string{
 		http.MethodPost: []string{EndpointsWallet},
 	})
+	webHandlerV1("/wallet/xpub", walletXPubHandler(gateway), map[string][]string{
+		http.MethodGet: []string{EndpointsWallet},
+	})
+	webHandlerV2("/wallet/address/confirm", walletConfirmAddressHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsWallet},
+	})
 
 	webHandlerV1("/wallet/unload", walletUnloadHandler(gateway), map[string][]string{
 		http.MethodPost: []string{EndpointsWallet},
@@ -509,6 +632,12 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 	webHandlerV2("/wallet/recover", walletRecoverHandler(gateway), map[string][]string{
 		http.MethodPost: []string{EndpointsWallet},
 	})
+	webHandlerV2("/wallet/unlock", walletUnlockHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsWallet},
+	})
+	webHandlerV2("/wallet/lock", walletLockHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsWallet},
+	})
 
 	// Blockchain interface
 	webHandlerV1("/blockchain/metadata", blockchainMetadataHandler(gateway), map[string][]string{
@@ -527,6 +656,24 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 	webHandlerV1("/last_blocks", lastBlocksHandler(gateway), map[string][]string{
 		http.MethodGet: []string{EndpointsRead},
 	})
+	webHandlerV1("/next-block", nextBlockHandler(gateway), map[string][]string{
+		http.MethodGet: []string{EndpointsRead},
+	})
+	webHandlerV1("/headers", headersHandler(gateway), map[string][]string{
+		http.MethodGet: []string{EndpointsRead},
+	})
+	webHandlerV1("/devnet/generate", devnetGenerateHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsTransaction},
+	})
+
+	// Websocket subscriptions for new blocks and transactions
+	wsHub := c.wsHub
+	if wsHub == nil {
+		wsHub = newWSHub(gateway)
+	}
+	webHandlerV1("/ws", wsHandler(wsHub), map[string][]string{
+		http.MethodGet: []string{EndpointsRead},
+	})
 
 	// Network stats endpoints
 	webHandlerV1("/network/connection", connectionHandler(gateway), map[string][]string{
@@ -549,11 +696,19 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 	webHandlerV1("/network/connection/disconnect", disconnectHandler(gateway), map[string][]string{
 		http.MethodPost: []string{EndpointsNetCtrl},
 	})
+	webHandlerV1("/network/bans", bansHandler(gateway), map[string][]string{
+		http.MethodGet:    []string{EndpointsRead, EndpointsStatus},
+		http.MethodPost:   []string{EndpointsNetCtrl},
+		http.MethodDelete: []string{EndpointsNetCtrl},
+	})
 
 	// Transaction related endpoints
 	webHandlerV1("/pendingTxs", pendingTxnsHandler(gateway), map[string][]string{
 		http.MethodGet: []string{EndpointsRead},
 	})
+	webHandlerV1("/fee-estimate", feeEstimateHandler(gateway), map[string][]string{
+		http.MethodGet: []string{EndpointsRead},
+	})
 	webHandlerV1("/transaction", transactionHandler(gateway), map[string][]string{
 		http.MethodGet: []string{EndpointsRead},
 	})
@@ -564,6 +719,15 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 	webHandlerV2("/transaction/verify", verifyTxnHandler(gateway), map[string][]string{
 		http.MethodPost: []string{EndpointsRead},
 	})
+	webHandlerV2("/multisig/address", multisigAddressHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsTransaction},
+	})
+	webHandlerV2("/multisig/transaction", multisigCreateTransactionHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsTransaction},
+	})
+	webHandlerV2("/multisig/transaction/combine", multisigCombineTransactionsHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsTransaction},
+	})
 	webHandlerV1("/transactions", transactionsHandler(gateway), map[string][]string{
 		http.MethodGet:  []string{EndpointsRead},
 		http.MethodPost: []string{EndpointsRead},
@@ -590,6 +754,12 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 		http.MethodGet:  []string{EndpointsRead},
 		http.MethodPost: []string{EndpointsRead},
 	})
+	webHandlerV1("/balances", balancesHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsRead},
+	})
+	webHandlerV1("/unspents/stats", unspentStatsHandler(gateway), map[string][]string{
+		http.MethodGet: []string{EndpointsRead},
+	})
 	webHandlerV1("/uxout", uxOutHandler(gateway), map[string][]string{
 		http.MethodGet: []string{EndpointsRead},
 	})
@@ -597,12 +767,44 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 		http.MethodGet: []string{EndpointsRead},
 	})
 
-	// golang process internal metrics for Prometheus
+	// Node administration endpoints
+	webHandlerV1("/admin/loglevel", http.HandlerFunc(logLevelHandler), map[string][]string{
+		http.MethodGet:  []string{EndpointsAdmin},
+		http.MethodPost: []string{EndpointsAdmin},
+	})
+	webHandlerV1("/admin/backup", backupHandler(gateway), map[string][]string{
+		http.MethodGet: []string{EndpointsAdmin},
+	})
+	webHandlerV1("/admin/dbstats", dbStatsHandler(gateway), map[string][]string{
+		http.MethodGet: []string{EndpointsAdmin},
+	})
+	webHandlerV1("/admin/compactdb", compactDBHandler(gateway), map[string][]string{
+		http.MethodPost: []string{EndpointsAdmin},
+	})
+
+	// Webhook notifications of address activity, only kept in memory if the hub is not configured
+	webhooks := c.webhookHub
+	if webhooks == nil {
+		webhooks, _ = newWebhookHub(gateway, "") // nolint: errcheck, it can only fail loading a file
+	}
+	webHandlerV1("/admin/webhooks", webhooksHandler(webhooks), map[string][]string{
+		http.MethodGet:    []string{EndpointsAdmin},
+		http.MethodPost:   []string{EndpointsAdmin},
+		http.MethodDelete: []string{EndpointsAdmin},
+	})
+
+	// golang process internal metrics and node health metrics for Prometheus
 	webHandlerV2("/metrics", metricsHandler(c, gateway), map[string][]string{
 		http.MethodGet: []string{EndpointsPrometheus},
 	})
+	webHandler(apiVersion1, "/metrics", metricsHandler(c, gateway), map[string][]string{
+		http.MethodGet: []string{EndpointsPrometheus},
+	})
 
 	// Address related endpoints
+	webHandlerV1("/address/", addressHandler(gateway), map[string][]string{
+		http.MethodGet: []string{EndpointsRead},
+	})
 	webHandlerV2("/address/verify", http.HandlerFunc(addressVerifyHandler), map[string][]string{
 		http.MethodPost: []string{EndpointsRead},
 	})
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -10,6 +11,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	ggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/SkycoinProject/cx-chains/src/api/grpc"
 )
 
 const configuredHost = "127.0.0.1:6420"
@@ -23,6 +29,7 @@ var allAPISetsEnabled = map[string]struct{}{
 	EndpointsPrometheus:         struct{}{},
 	EndpointsNetCtrl:            struct{}{},
 	EndpointsStorage:            struct{}{},
+	EndpointsAdmin:              struct{}{},
 }
 
 func defaultMuxConfig() muxConfig {
@@ -36,6 +43,27 @@ func defaultMuxConfig() muxConfig {
 }
 
 var endpointsMethods = map[string][]string{
+	"/api/v1/address/": []string{
+		http.MethodGet,
+	},
+	"/api/v1/admin/backup": []string{
+		http.MethodGet,
+	},
+	"/api/v1/admin/compactdb": []string{
+		http.MethodPost,
+	},
+	"/api/v1/admin/dbstats": []string{
+		http.MethodGet,
+	},
+	"/api/v1/admin/loglevel": []string{
+		http.MethodGet,
+		http.MethodPost,
+	},
+	"/api/v1/admin/webhooks": []string{
+		http.MethodGet,
+		http.MethodPost,
+		http.MethodDelete,
+	},
 	"/api/v1/address_uxouts": []string{
 		http.MethodGet,
 	},
@@ -46,6 +74,9 @@ var endpointsMethods = map[string][]string{
 		http.MethodGet,
 		http.MethodPost,
 	},
+	"/api/v1/balances": []string{
+		http.MethodPost,
+	},
 	"/api/v1/block": []string{
 		http.MethodGet,
 	},
@@ -62,6 +93,12 @@ var endpointsMethods = map[string][]string{
 	"/api/v1/coinSupply": []string{
 		http.MethodGet,
 	},
+	"/api/v1/devnet/generate": []string{
+		http.MethodPost,
+	},
+	"/api/v1/fee-estimate": []string{
+		http.MethodGet,
+	},
 	"/api/v1/health": []string{
 		http.MethodGet,
 	},
@@ -71,6 +108,12 @@ var endpointsMethods = map[string][]string{
 	"/api/v1/last_blocks": []string{
 		http.MethodGet,
 	},
+	"/api/v1/next-block": []string{
+		http.MethodGet,
+	},
+	"/api/v1/headers": []string{
+		http.MethodGet,
+	},
 	"/api/v1/version": []string{
 		http.MethodGet,
 	},
@@ -92,6 +135,11 @@ var endpointsMethods = map[string][]string{
 	"/api/v1/network/connection/disconnect": []string{
 		http.MethodPost,
 	},
+	"/api/v1/network/bans": []string{
+		http.MethodGet,
+		http.MethodPost,
+		http.MethodDelete,
+	},
 	"/api/v1/outputs": []string{
 		http.MethodGet,
 		http.MethodPost,
@@ -115,6 +163,9 @@ var endpointsMethods = map[string][]string{
 		http.MethodGet,
 		http.MethodPost,
 	},
+	"/api/v1/unspents/stats": []string{
+		http.MethodGet,
+	},
 	"/api/v1/uxout": []string{
 		http.MethodGet,
 	},
@@ -154,6 +205,9 @@ var endpointsMethods = map[string][]string{
 	"/api/v1/wallets/folderName": []string{
 		http.MethodGet,
 	},
+	"/api/v1/ws": []string{
+		http.MethodGet,
+	},
 
 	"/api/v2/transaction/verify": []string{
 		http.MethodPost,
@@ -161,12 +215,30 @@ var endpointsMethods = map[string][]string{
 	"/api/v2/address/verify": []string{
 		http.MethodPost,
 	},
+	"/api/v2/multisig/address": []string{
+		http.MethodPost,
+	},
+	"/api/v2/multisig/transaction": []string{
+		http.MethodPost,
+	},
+	"/api/v2/multisig/transaction/combine": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/address/confirm": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/lock": []string{
+		http.MethodPost,
+	},
 	"/api/v2/wallet/recover": []string{
 		http.MethodPost,
 	},
 	"/api/v2/wallet/seed/verify": []string{
 		http.MethodPost,
 	},
+	"/api/v2/wallet/unlock": []string{
+		http.MethodPost,
+	},
 	"/api/v2/wallet/transaction/sign": []string{
 		http.MethodPost,
 	},
@@ -179,6 +251,10 @@ var endpointsMethods = map[string][]string{
 		http.MethodPost,
 		http.MethodDelete,
 	},
+
+	"/metrics": []string{
+		http.MethodGet,
+	},
 }
 
 func allEndpoints() []string {
@@ -529,3 +605,43 @@ func TestHTTPBasicAuthInvalid(t *testing.T) {
 		}
 	}
 }
+
+func TestServerGRPC(t *testing.T) {
+	gateway := &MockGatewayer{}
+	gateway.On("HeadBkSeq").Return(uint64(0), false, nil)
+
+	s, err := Create("127.0.0.1:0", Config{
+		GRPCAddr: "127.0.0.1:0",
+	}, gateway)
+	require.NoError(t, err)
+	require.NotEmpty(t, s.GRPCAddr())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		err := s.Serve()
+		if err != nil && err.Error() != fmt.Sprintf("accept tcp %s: use of closed network connection", s.Addr()) {
+			require.NoError(t, err)
+		}
+	}()
+
+	conn, err := ggrpc.Dial(s.GRPCAddr(), ggrpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// The gRPC interface is served with the web interface
+	_, err = grpc.NewVisorClient(conn).Head(context.Background(), &grpc.HeadRequest{})
+	require.Equal(t, status.Error(codes.NotFound, "blockchain is empty"), err)
+
+	// and shut down with it
+	s.Shutdown()
+	<-done
+
+	_, err = grpc.NewVisorClient(conn).Head(context.Background(), &grpc.HeadRequest{})
+	require.Equal(t, codes.Unavailable, status.Code(err))
+
+	s, err = Create("127.0.0.1:0", Config{}, gateway)
+	require.NoError(t, err)
+	require.Empty(t, s.GRPCAddr())
+	require.NoError(t, s.listener.Close())
+}
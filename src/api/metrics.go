@@ -1,7 +1,11 @@
 package api
 
 import (
+	"bufio"
+	"errors"
+	"net"
 	"net/http"
+	"strconv"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -50,6 +54,26 @@ var (
 			Name: "last_block_seq",
 			Help: "Last block sequence number",
 		})
+	promHighestBlockSeq = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "highest_block_seq",
+			Help: "Highest block sequence number reported by peers",
+		})
+	promBlockSeqLag = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "block_seq_lag",
+			Help: "Number of blocks reported by peers that have not been verified and executed yet",
+		})
+	promDBSize = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "db_size_bytes",
+			Help: "Size of the blockchain database",
+		})
+	promAPIRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_requests_total",
+			Help: "Number of API requests, by endpoint, method and status code",
+		}, []string{"endpoint", "method", "code"})
 )
 
 func init() {
@@ -61,6 +85,10 @@ func init() {
 	prometheus.MustRegister(promIncomingConns)
 	prometheus.MustRegister(promStartedAt)
 	prometheus.MustRegister(promLastBlockSeq)
+	prometheus.MustRegister(promHighestBlockSeq)
+	prometheus.MustRegister(promBlockSeqLag)
+	prometheus.MustRegister(promDBSize)
+	prometheus.MustRegister(promAPIRequests)
 }
 
 func metricsHandler(c muxConfig, gateway Gatewayer) http.HandlerFunc {
@@ -80,6 +108,61 @@ func metricsHandler(c muxConfig, gateway Gatewayer) http.HandlerFunc {
 		promStartedAt.Set(float64(gateway.StartedAt().Unix()))
 		promLastBlockSeq.Set(float64(health.BlockchainMetadata.Head.BkSeq))
 
+		progress := gateway.GetBlockchainProgress(health.BlockchainMetadata.Head.BkSeq)
+		promHighestBlockSeq.Set(float64(progress.Highest))
+		var lag uint64
+		if progress.Highest > progress.Current {
+			lag = progress.Highest - progress.Current
+		}
+		promBlockSeqLag.Set(float64(lag))
+
+		dbSize, err := gateway.DBSize()
+		if err != nil {
+			wh.Error500(w, err.Error())
+			return
+		}
+		promDBSize.Set(float64(dbSize))
+
 		promhttp.Handler().ServeHTTP(w, r)
 	}
 }
+
+// statusCodeRecorder records the status code written by a handler
+type statusCodeRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (w *statusCodeRecorder) WriteHeader(code int) {
+	w.statusCode = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Flush implements http.Flusher, so that wrapped handlers can stream their response
+func (w *statusCodeRecorder) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker, so that wrapped handlers can take over the connection (e.g. for websockets)
+func (w *statusCodeRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("http.Hijacker interface is not supported")
+	}
+	return hj.Hijack()
+}
+
+// countRequests counts the requests to an endpoint in api_requests_total.
+// The registered endpoint pattern is used rather than the request path, to bound the number of label values.
+func countRequests(endpoint string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusCodeRecorder{
+			ResponseWriter: w,
+			statusCode:     http.StatusOK,
+		}
+		handler.ServeHTTP(rec, r)
+		promAPIRequests.WithLabelValues(endpoint, r.Method, strconv.Itoa(rec.statusCode)).Inc()
+	})
+}
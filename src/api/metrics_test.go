@@ -0,0 +1,134 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/daemon"
+	"github.com/SkycoinProject/cx-chains/src/util/useragent"
+	"github.com/SkycoinProject/cx-chains/src/visor"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	tt := []struct {
+		name      string
+		endpoint  string
+		status    int
+		err       string
+		dbSizeErr error
+		contains  []string
+	}{
+		{
+			name:     "200",
+			endpoint: "/metrics",
+			status:   http.StatusOK,
+			contains: []string{
+				"last_block_seq 21175",
+				"highest_block_seq 21180",
+				"block_seq_lag 5",
+				"unspent_outputs 100",
+				"unconfirmed_txns 3",
+				"open_connections 2",
+				"db_size_bytes 65536",
+				"# TYPE block_execute_duration_seconds histogram",
+				"# TYPE blocks_commit_duration_seconds histogram",
+				// The previous request to the endpoint is counted
+				`api_requests_total{code="200",endpoint="/metrics",method="GET"}`,
+			},
+		},
+		{
+			name:     "200 api v2",
+			endpoint: "/api/v2/metrics",
+			status:   http.StatusOK,
+			contains: []string{
+				"last_block_seq 21175",
+				"db_size_bytes 65536",
+			},
+		},
+		{
+			name:      "500 - DBSize failed",
+			endpoint:  "/metrics",
+			status:    http.StatusInternalServerError,
+			err:       "500 Internal Server Error - DBSize failed",
+			dbSizeErr: errors.New("DBSize failed"),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			metadata := visor.BlockchainMetadata{
+				HeadBlock: coin.SignedBlock{
+					Block: coin.Block{
+						Head: coin.BlockHeader{
+							BkSeq: 21175,
+							Time:  uint64(time.Now().Unix()),
+						},
+					},
+				},
+				Unspents:    100,
+				Unconfirmed: 3,
+			}
+
+			conns := []daemon.Connection{
+				{
+					ConnectionDetails: daemon.ConnectionDetails{
+						Outgoing: true,
+						State:    daemon.ConnectionStateIntroduced,
+					},
+				},
+				{
+					ConnectionDetails: daemon.ConnectionDetails{
+						Outgoing: false,
+						State:    daemon.ConnectionStateIntroduced,
+					},
+				},
+			}
+
+			gateway := &MockGatewayer{}
+			gateway.On("GetBlockchainMetadata").Return(&metadata, nil)
+			gateway.On("GetConnections", mock.Anything).Return(conns, nil)
+			gateway.On("StartedAt").Return(time.Now())
+			gateway.On("DaemonConfig").Return(daemon.DaemonConfig{})
+			gateway.On("GetBlockchainProgress", uint64(21175)).Return(&daemon.BlockchainProgress{
+				Current: 21175,
+				Highest: 21180,
+			})
+			gateway.On("DBSize").Return(int64(65536), tc.dbSizeErr)
+
+			cfg := defaultMuxConfig()
+			cfg.health.DaemonUserAgent = useragent.Data{
+				Coin:    "skycoin",
+				Version: "0.25.0",
+			}
+			handler := newServerMux(cfg, gateway)
+
+			var rr *httptest.ResponseRecorder
+			for i := 0; i < 2; i++ {
+				req, err := http.NewRequest(http.MethodGet, tc.endpoint, nil)
+				require.NoError(t, err)
+
+				rr = httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+			}
+
+			require.Equal(t, tc.status, rr.Code, "got `%v` want `%v`", rr.Code, tc.status)
+
+			if rr.Code != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()))
+				return
+			}
+
+			for _, s := range tc.contains {
+				require.Contains(t, rr.Body.String(), s)
+			}
+		})
+	}
+}
@@ -158,38 +158,110 @@ func originRefererCheck(apiVersion, host string, hostWhitelist []string, handler
 	})
 }
 
-func basicAuth(apiVersion, username, password, realm string, f http.Handler) http.HandlerFunc {
-	needsAuth := username != "" || password != ""
-	usernamePasswordHash := cipher.SumSHA256(append([]byte(username), []byte(password)...))
+// authConfig configures the authentication of API requests
+type authConfig struct {
+	username string
+	password string
+	// token is accepted as a bearer token in the Authorization header
+	token string
+	// mutatingOnly only requires authentication for requests that can change the state of the node.
+	// GET, HEAD and OPTIONS requests are served without authentication, except for the endpoints
+	// of the API sets in authAlwaysAPISets
+	mutatingOnly bool
+}
+
+// authAlwaysAPISets are the API sets whose endpoints require authentication for all methods,
+// because their GET requests expose wallets or the node's database
+var authAlwaysAPISets = map[string]struct{}{
+	EndpointsAdmin:              {},
+	EndpointsWallet:             {},
+	EndpointsInsecureWalletSeed: {},
+}
+
+// isSafeMethod returns true for the request methods which do not change the state of the node
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// authAlways returns true if the request method of the endpoint belongs to an API set in authAlwaysAPISets
+func authAlways(methodAPISets map[string][]string, method string) bool {
+	for _, k := range methodAPISets[method] {
+		if _, ok := authAlwaysAPISets[k]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// bearerToken returns the token of an "Authorization: Bearer <token>" request header
+func bearerToken(r *http.Request) (string, bool) {
+	const prefix = "bearer "
+	auth := r.Header.Get("Authorization")
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return "", false
+	}
+
+	return auth[len(prefix):], true
+}
+
+func authCheck(apiVersion string, c authConfig, methodAPISets map[string][]string, realm string, f http.Handler) http.HandlerFunc {
+	basicAuthEnabled := c.username != "" || c.password != ""
+	tokenAuthEnabled := c.token != ""
+	usernamePasswordHash := cipher.SumSHA256(append([]byte(c.username), []byte(c.password)...))
+	tokenHash := cipher.SumSHA256([]byte(c.token))
+
 	authHeader := fmt.Sprintf("Basic realm=%q", realm)
+	if !basicAuthEnabled && tokenAuthEnabled {
+		authHeader = fmt.Sprintf("Bearer realm=%q", realm)
+	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
-		user, pass, ok := r.BasicAuth()
-
-		if needsAuth {
-			if !ok {
-				w.Header().Set("WWW-Authenticate", authHeader)
-				writeError(w, apiVersion, http.StatusUnauthorized, "")
-				return
-			}
+		user, pass, basicOk := r.BasicAuth()
+		token, tokenOk := bearerToken(r)
 
-			userPassHash := cipher.SumSHA256(append([]byte(user), []byte(pass)...))
+		unauthorized := func() {
+			w.Header().Set("WWW-Authenticate", authHeader)
+			writeError(w, apiVersion, http.StatusUnauthorized, "")
+		}
 
-			if subtle.ConstantTimeCompare(userPassHash[:], usernamePasswordHash[:]) != 1 {
-				w.Header().Set("WWW-Authenticate", authHeader)
-				writeError(w, apiVersion, http.StatusUnauthorized, "")
-				return
-			}
-		} else {
+		if !basicAuthEnabled && !tokenAuthEnabled {
 			// If auth is not configured but the request provides auth, reject
 			// This will avoid a mistake where the daemon is not configured with auth,
 			// but the client is, and does not realize the daemon is not configured with auth
 			// because all requests are accepted
-			if user != "" || pass != "" {
-				w.Header().Set("WWW-Authenticate", authHeader)
-				writeError(w, apiVersion, http.StatusUnauthorized, "")
+			if user != "" || pass != "" || tokenOk {
+				unauthorized()
 				return
 			}
+
+			f.ServeHTTP(w, r)
+			return
+		}
+
+		if c.mutatingOnly && isSafeMethod(r.Method) && !authAlways(methodAPISets, r.Method) {
+			f.ServeHTTP(w, r)
+			return
+		}
+
+		authorized := false
+		if basicAuthEnabled && basicOk {
+			userPassHash := cipher.SumSHA256(append([]byte(user), []byte(pass)...))
+			authorized = subtle.ConstantTimeCompare(userPassHash[:], usernamePasswordHash[:]) == 1
+		}
+
+		if !authorized && tokenAuthEnabled && tokenOk {
+			h := cipher.SumSHA256([]byte(token))
+			authorized = subtle.ConstantTimeCompare(h[:], tokenHash[:]) == 1
+		}
+
+		if !authorized {
+			unauthorized()
+			return
 		}
 
 		f.ServeHTTP(w, r)
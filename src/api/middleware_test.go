@@ -282,3 +282,294 @@ func TestIsContentTypeJSON(t *testing.T) {
 	require.False(t, isContentTypeJSON("application/x-www-form-urlencoded"))
 	require.False(t, isContentTypeJSON(ContentTypeForm))
 }
+
+func TestAuthCheck(t *testing.T) {
+	basicCfg := authConfig{
+		username: "foo",
+		password: "bar",
+	}
+	tokenCfg := authConfig{
+		token: "secret",
+	}
+	bothCfg := authConfig{
+		username: "foo",
+		password: "bar",
+		token:    "secret",
+	}
+	mutatingOnlyCfg := authConfig{
+		token:        "secret",
+		mutatingOnly: true,
+	}
+
+	cases := []struct {
+		name       string
+		cfg        authConfig
+		method     string
+		apiSets    []string
+		user       string
+		pass       string
+		authHeader string
+		authorized bool
+		challenge  string
+	}{
+		{
+			name:       "no auth configured",
+			method:     http.MethodPost,
+			authorized: true,
+		},
+		{
+			name:       "no auth configured, token provided",
+			method:     http.MethodPost,
+			authHeader: "Bearer secret",
+			challenge:  `Basic realm="test"`,
+		},
+		{
+			name:       "basic auth",
+			cfg:        basicCfg,
+			method:     http.MethodPost,
+			user:       "foo",
+			pass:       "bar",
+			authorized: true,
+		},
+		{
+			name:      "basic auth, wrong password",
+			cfg:       basicCfg,
+			method:    http.MethodPost,
+			user:      "foo",
+			pass:      "baz",
+			challenge: `Basic realm="test"`,
+		},
+		{
+			name:       "basic auth, token provided",
+			cfg:        basicCfg,
+			method:     http.MethodPost,
+			authHeader: "Bearer secret",
+			challenge:  `Basic realm="test"`,
+		},
+		{
+			name:       "token auth",
+			cfg:        tokenCfg,
+			method:     http.MethodPost,
+			authHeader: "Bearer secret",
+			authorized: true,
+		},
+		{
+			name:       "token auth, case insensitive scheme",
+			cfg:        tokenCfg,
+			method:     http.MethodPost,
+			authHeader: "bearer secret",
+			authorized: true,
+		},
+		{
+			name:       "token auth, wrong token",
+			cfg:        tokenCfg,
+			method:     http.MethodPost,
+			authHeader: "Bearer secre",
+			challenge:  `Bearer realm="test"`,
+		},
+		{
+			name:      "token auth, no token",
+			cfg:       tokenCfg,
+			method:    http.MethodGet,
+			challenge: `Bearer realm="test"`,
+		},
+		{
+			name:      "token auth, basic auth provided",
+			cfg:       tokenCfg,
+			method:    http.MethodPost,
+			user:      "foo",
+			pass:      "bar",
+			challenge: `Bearer realm="test"`,
+		},
+		{
+			name:       "basic and token auth, basic auth provided",
+			cfg:        bothCfg,
+			method:     http.MethodPost,
+			user:       "foo",
+			pass:       "bar",
+			authorized: true,
+		},
+		{
+			name:       "basic and token auth, token provided",
+			cfg:        bothCfg,
+			method:     http.MethodPost,
+			authHeader: "Bearer secret",
+			authorized: true,
+		},
+		{
+			name:      "basic and token auth, nothing provided",
+			cfg:       bothCfg,
+			method:    http.MethodPost,
+			challenge: `Basic realm="test"`,
+		},
+		{
+			name:       "mutating only, GET",
+			cfg:        mutatingOnlyCfg,
+			method:     http.MethodGet,
+			authorized: true,
+		},
+		{
+			name:       "mutating only, HEAD",
+			cfg:        mutatingOnlyCfg,
+			method:     http.MethodHead,
+			authorized: true,
+		},
+		{
+			name:      "mutating only, POST",
+			cfg:       mutatingOnlyCfg,
+			method:    http.MethodPost,
+			challenge: `Bearer realm="test"`,
+		},
+		{
+			name:       "mutating only, POST with token",
+			cfg:        mutatingOnlyCfg,
+			method:     http.MethodPost,
+			authHeader: "Bearer secret",
+			authorized: true,
+		},
+		{
+			name:      "mutating only, DELETE",
+			cfg:       mutatingOnlyCfg,
+			method:    http.MethodDelete,
+			challenge: `Bearer realm="test"`,
+		},
+		{
+			name:       "mutating only, GET READ",
+			cfg:        mutatingOnlyCfg,
+			method:     http.MethodGet,
+			apiSets:    []string{EndpointsRead},
+			authorized: true,
+		},
+		{
+			name:      "mutating only, GET ADMIN",
+			cfg:       mutatingOnlyCfg,
+			method:    http.MethodGet,
+			apiSets:   []string{EndpointsAdmin},
+			challenge: `Bearer realm="test"`,
+		},
+		{
+			name:      "mutating only, GET WALLET",
+			cfg:       mutatingOnlyCfg,
+			method:    http.MethodGet,
+			apiSets:   []string{EndpointsWallet},
+			challenge: `Bearer realm="test"`,
+		},
+		{
+			name:      "mutating only, GET INSECURE_WALLET_SEED",
+			cfg:       mutatingOnlyCfg,
+			method:    http.MethodGet,
+			apiSets:   []string{EndpointsInsecureWalletSeed},
+			challenge: `Bearer realm="test"`,
+		},
+		{
+			name:       "mutating only, GET WALLET with token",
+			cfg:        mutatingOnlyCfg,
+			method:     http.MethodGet,
+			apiSets:    []string{EndpointsWallet},
+			authHeader: "Bearer secret",
+			authorized: true,
+		},
+	}
+
+	for _, tc := range cases {
+		for _, apiVersion := range []string{apiVersion1, apiVersion2} {
+			t.Run(fmt.Sprintf("%s %s", tc.name, apiVersion), func(t *testing.T) {
+				req, err := http.NewRequest(tc.method, "/foo", nil)
+				require.NoError(t, err)
+
+				if tc.user != "" || tc.pass != "" {
+					req.SetBasicAuth(tc.user, tc.pass)
+				}
+				if tc.authHeader != "" {
+					req.Header.Set("Authorization", tc.authHeader)
+				}
+
+				methodAPISets := map[string][]string{
+					tc.method: tc.apiSets,
+				}
+
+				handler := authCheck(apiVersion, tc.cfg, methodAPISets, "test", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusOK)
+				}))
+
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+
+				if tc.authorized {
+					require.Equal(t, http.StatusOK, rr.Code)
+					require.Empty(t, rr.Header().Get("WWW-Authenticate"))
+					return
+				}
+
+				require.Equal(t, http.StatusUnauthorized, rr.Code)
+				require.Equal(t, tc.challenge, rr.Header().Get("WWW-Authenticate"))
+			})
+		}
+	}
+}
+
+func TestAuthMutatingOnlyEndpoints(t *testing.T) {
+	cases := []struct {
+		name       string
+		endpoint   string
+		authHeader string
+		status     int
+	}{
+		{
+			name:     "GET version",
+			endpoint: "/api/v1/version",
+			status:   http.StatusOK,
+		},
+		{
+			name:     "GET admin backup",
+			endpoint: "/api/v1/admin/backup",
+			status:   http.StatusUnauthorized,
+		},
+		{
+			name:     "GET wallets",
+			endpoint: "/api/v1/wallets",
+			status:   http.StatusUnauthorized,
+		},
+		{
+			name:       "GET wallets, wrong token",
+			endpoint:   "/api/v1/wallets",
+			authHeader: "Bearer secre",
+			status:     http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+
+			req, err := http.NewRequest(http.MethodGet, tc.endpoint, nil)
+			require.NoError(t, err)
+
+			if tc.authHeader != "" {
+				req.Header.Set("Authorization", tc.authHeader)
+			}
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(muxConfig{
+				host:             configuredHost,
+				appLoc:           ".",
+				disableCSRF:      true,
+				disableCSP:       true,
+				apiToken:         "secret",
+				authMutatingOnly: true,
+				enabledAPISets: map[string]struct{}{
+					EndpointsRead:   struct{}{},
+					EndpointsAdmin:  struct{}{},
+					EndpointsWallet: struct{}{},
+				},
+			}, gateway)
+
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+			if tc.status == http.StatusUnauthorized {
+				require.Equal(t, `Bearer realm="skycoin daemon"`, rr.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
@@ -2,10 +2,13 @@
 
 package api
 
+import blockdb "github.com/SkycoinProject/cx-chains/src/visor/blockdb"
 import cipher "github.com/SkycoinProject/cx-chains/src/cipher"
 import coin "github.com/SkycoinProject/cx-chains/src/coin"
 import daemon "github.com/SkycoinProject/cx-chains/src/daemon"
+import dbutil "github.com/SkycoinProject/cx-chains/src/visor/dbutil"
 import historydb "github.com/SkycoinProject/cx-chains/src/visor/historydb"
+import io "io"
 import kvstorage "github.com/SkycoinProject/cx-chains/src/kvstorage"
 import mock "github.com/stretchr/testify/mock"
 import time "time"
@@ -53,6 +56,117 @@ func (_m *MockGatewayer) AddressCount() (uint64, error) {
 	return r0, r1
 }
 
+// AddressesActivity provides a mock function with given fields: addrs
+func (_m *MockGatewayer) AddressesActivity(addrs []cipher.Address) ([]bool, error) {
+	ret := _m.Called(addrs)
+
+	var r0 []bool
+	if rf, ok := ret.Get(0).(func([]cipher.Address) []bool); ok {
+		r0 = rf(addrs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]bool)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]cipher.Address) error); ok {
+		r1 = rf(addrs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BackupDB provides a mock function with given fields: w
+func (_m *MockGatewayer) BackupDB(w io.Writer) (int64, error) {
+	ret := _m.Called(w)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(io.Writer) int64); ok {
+		r0 = rf(w)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(io.Writer) error); ok {
+		r1 = rf(w)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BanIP provides a mock function with given fields: ip, reason, duration
+func (_m *MockGatewayer) BanIP(ip string, reason string, duration time.Duration) (*daemon.Ban, error) {
+	ret := _m.Called(ip, reason, duration)
+
+	var r0 *daemon.Ban
+	if rf, ok := ret.Get(0).(func(string, string, time.Duration) *daemon.Ban); ok {
+		r0 = rf(ip, reason, duration)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*daemon.Ban)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, time.Duration) error); ok {
+		r1 = rf(ip, reason, duration)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CompactDB provides a mock function with given fields:
+func (_m *MockGatewayer) CompactDB() (dbutil.CompactResult, error) {
+	ret := _m.Called()
+
+	var r0 dbutil.CompactResult
+	if rf, ok := ret.Get(0).(func() dbutil.CompactResult); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(dbutil.CompactResult)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateHardwareWallet provides a mock function with given fields: wltName, opts, n
+func (_m *MockGatewayer) CreateHardwareWallet(wltName string, opts wallet.Options, n uint32) (*wallet.Wallet, error) {
+	ret := _m.Called(wltName, opts, n)
+
+	var r0 *wallet.Wallet
+	if rf, ok := ret.Get(0).(func(string, wallet.Options, uint32) *wallet.Wallet); ok {
+		r0 = rf(wltName, opts, n)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*wallet.Wallet)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, wallet.Options, uint32) error); ok {
+		r1 = rf(wltName, opts, n)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateTransaction provides a mock function with given fields: p, wp
 func (_m *MockGatewayer) CreateTransaction(p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error) {
 	ret := _m.Called(p, wp)
@@ -85,13 +199,13 @@ func (_m *MockGatewayer) CreateTransaction(p transaction.Params, wp visor.Create
 	return r0, r1, r2
 }
 
-// CreateWallet provides a mock function with given fields: wltName, options, bg
-func (_m *MockGatewayer) CreateWallet(wltName string, options wallet.Options, bg wallet.BalanceGetter) (*wallet.Wallet, error) {
-	ret := _m.Called(wltName, options, bg)
+// CreateWallet provides a mock function with given fields: wltName, options, bg, tf
+func (_m *MockGatewayer) CreateWallet(wltName string, options wallet.Options, bg wallet.BalanceGetter, tf wallet.TransactionsFinder) (*wallet.Wallet, error) {
+	ret := _m.Called(wltName, options, bg, tf)
 
 	var r0 *wallet.Wallet
-	if rf, ok := ret.Get(0).(func(string, wallet.Options, wallet.BalanceGetter) *wallet.Wallet); ok {
-		r0 = rf(wltName, options, bg)
+	if rf, ok := ret.Get(0).(func(string, wallet.Options, wallet.BalanceGetter, wallet.TransactionsFinder) *wallet.Wallet); ok {
+		r0 = rf(wltName, options, bg, tf)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*wallet.Wallet)
@@ -99,8 +213,50 @@ func (_m *MockGatewayer) CreateWallet(wltName string, options wallet.Options, bg
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, wallet.Options, wallet.BalanceGetter) error); ok {
-		r1 = rf(wltName, options, bg)
+	if rf, ok := ret.Get(1).(func(string, wallet.Options, wallet.BalanceGetter, wallet.TransactionsFinder) error); ok {
+		r1 = rf(wltName, options, bg, tf)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DBSize provides a mock function with given fields:
+func (_m *MockGatewayer) DBSize() (int64, error) {
+	ret := _m.Called()
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func() int64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DBStats provides a mock function with given fields:
+func (_m *MockGatewayer) DBStats() (dbutil.DBStats, error) {
+	ret := _m.Called()
+
+	var r0 dbutil.DBStats
+	if rf, ok := ret.Get(0).(func() dbutil.DBStats); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(dbutil.DBStats)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -182,6 +338,59 @@ func (_m *MockGatewayer) EncryptWallet(wltID string, password []byte) (*wallet.W
 	return r0, r1
 }
 
+// GenerateDevnetBlocks provides a mock function with given fields: n
+func (_m *MockGatewayer) GenerateDevnetBlocks(n int) ([]coin.SignedBlock, error) {
+	ret := _m.Called(n)
+
+	var r0 []coin.SignedBlock
+	if rf, ok := ret.Get(0).(func(int) []coin.SignedBlock); ok {
+		r0 = rf(n)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]coin.SignedBlock)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(n)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAddressHistory provides a mock function with given fields: a, offset, limit
+func (_m *MockGatewayer) GetAddressHistory(a cipher.Address, offset uint64, limit uint64) ([]blockdb.HistoryEntry, uint64, error) {
+	ret := _m.Called(a, offset, limit)
+
+	var r0 []blockdb.HistoryEntry
+	if rf, ok := ret.Get(0).(func(cipher.Address, uint64, uint64) []blockdb.HistoryEntry); ok {
+		r0 = rf(a, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]blockdb.HistoryEntry)
+		}
+	}
+
+	var r1 uint64
+	if rf, ok := ret.Get(1).(func(cipher.Address, uint64, uint64) uint64); ok {
+		r1 = rf(a, offset, limit)
+	} else {
+		r1 = ret.Get(1).(uint64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(cipher.Address, uint64, uint64) error); ok {
+		r2 = rf(a, offset, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetAllStorageValues provides a mock function with given fields: storageType
 func (_m *MockGatewayer) GetAllStorageValues(storageType kvstorage.Type) (map[string]string, error) {
 	ret := _m.Called(storageType)
@@ -283,6 +492,22 @@ func (_m *MockGatewayer) GetBalanceOfAddrs(addrs []cipher.Address) ([]wallet.Bal
 	return r0, r1
 }
 
+// GetBans provides a mock function with given fields:
+func (_m *MockGatewayer) GetBans() []daemon.Ban {
+	ret := _m.Called()
+
+	var r0 []daemon.Ban
+	if rf, ok := ret.Get(0).(func() []daemon.Ban); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]daemon.Ban)
+		}
+	}
+
+	return r0
+}
+
 // GetBlockchainMetadata provides a mock function with given fields:
 func (_m *MockGatewayer) GetBlockchainMetadata() (*visor.BlockchainMetadata, error) {
 	ret := _m.Called()
@@ -322,6 +547,59 @@ func (_m *MockGatewayer) GetBlockchainProgress(headSeq uint64) *daemon.Blockchai
 	return r0
 }
 
+// GetBalancesOfAddrs provides a mock function with given fields: addrs, minConfirmations
+func (_m *MockGatewayer) GetBalancesOfAddrs(addrs []cipher.Address, minConfirmations uint64) ([]visor.AddressBalance, uint64, error) {
+	ret := _m.Called(addrs, minConfirmations)
+
+	var r0 []visor.AddressBalance
+	if rf, ok := ret.Get(0).(func([]cipher.Address, uint64) []visor.AddressBalance); ok {
+		r0 = rf(addrs, minConfirmations)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]visor.AddressBalance)
+		}
+	}
+
+	var r1 uint64
+	if rf, ok := ret.Get(1).(func([]cipher.Address, uint64) uint64); ok {
+		r1 = rf(addrs, minConfirmations)
+	} else {
+		r1 = ret.Get(1).(uint64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func([]cipher.Address, uint64) error); ok {
+		r2 = rf(addrs, minConfirmations)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetBalancesOfAddrsAtSeq provides a mock function with given fields: addrs, seq
+func (_m *MockGatewayer) GetBalancesOfAddrsAtSeq(addrs []cipher.Address, seq uint64) ([]wallet.Balance, error) {
+	ret := _m.Called(addrs, seq)
+
+	var r0 []wallet.Balance
+	if rf, ok := ret.Get(0).(func([]cipher.Address, uint64) []wallet.Balance); ok {
+		r0 = rf(addrs, seq)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]wallet.Balance)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]cipher.Address, uint64) error); ok {
+		r1 = rf(addrs, seq)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetBlocks provides a mock function with given fields: seqs
 func (_m *MockGatewayer) GetBlocks(seqs []uint64) ([]coin.SignedBlock, error) {
 	ret := _m.Called(seqs)
@@ -432,6 +710,29 @@ func (_m *MockGatewayer) GetBlocksVerbose(seqs []uint64) ([]coin.SignedBlock, []
 	return r0, r1, r2
 }
 
+// GetCoinSupply provides a mock function with given fields:
+func (_m *MockGatewayer) GetCoinSupply() (*visor.CoinSupply, error) {
+	ret := _m.Called()
+
+	var r0 *visor.CoinSupply
+	if rf, ok := ret.Get(0).(func() *visor.CoinSupply); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*visor.CoinSupply)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetConnection provides a mock function with given fields: addr
 func (_m *MockGatewayer) GetConnection(addr string) (*daemon.Connection, error) {
 	ret := _m.Called(addr)
@@ -455,6 +756,20 @@ func (_m *MockGatewayer) GetConnection(addr string) (*daemon.Connection, error)
 	return r0, r1
 }
 
+// GetConnectionSlots provides a mock function with given fields:
+func (_m *MockGatewayer) GetConnectionSlots() daemon.ConnectionSlots {
+	ret := _m.Called()
+
+	var r0 daemon.ConnectionSlots
+	if rf, ok := ret.Get(0).(func() daemon.ConnectionSlots); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(daemon.ConnectionSlots)
+	}
+
+	return r0
+}
+
 // GetConnections provides a mock function with given fields: f
 func (_m *MockGatewayer) GetConnections(f func(daemon.Connection) bool) ([]daemon.Connection, error) {
 	ret := _m.Called(f)
@@ -510,6 +825,29 @@ func (_m *MockGatewayer) GetExchgConnection() []string {
 	return r0
 }
 
+// GetFeeEstimate provides a mock function with given fields:
+func (_m *MockGatewayer) GetFeeEstimate() (*visor.FeeEstimate, error) {
+	ret := _m.Called()
+
+	var r0 *visor.FeeEstimate
+	if rf, ok := ret.Get(0).(func() *visor.FeeEstimate); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*visor.FeeEstimate)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetLastBlocks provides a mock function with given fields: num
 func (_m *MockGatewayer) GetLastBlocks(num uint64) ([]coin.SignedBlock, error) {
 	ret := _m.Called(num)
@@ -565,6 +903,29 @@ func (_m *MockGatewayer) GetLastBlocksVerbose(num uint64) ([]coin.SignedBlock, [
 	return r0, r1, r2
 }
 
+// GetNextBlock provides a mock function with given fields:
+func (_m *MockGatewayer) GetNextBlock() (*visor.NextBlock, error) {
+	ret := _m.Called()
+
+	var r0 *visor.NextBlock
+	if rf, ok := ret.Get(0).(func() *visor.NextBlock); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*visor.NextBlock)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetProgramState provides a mock function with given fields: flts
 func (_m *MockGatewayer) GetProgramState(flts []visor.TxFilter) ([]byte, error) {
 	ret := _m.Called(flts)
@@ -588,13 +949,13 @@ func (_m *MockGatewayer) GetProgramState(flts []visor.TxFilter) ([]byte, error)
 	return r0, r1
 }
 
-// GetRichlist provides a mock function with given fields: includeDistribution
-func (_m *MockGatewayer) GetRichlist(includeDistribution bool) (visor.Richlist, error) {
-	ret := _m.Called(includeDistribution)
+// GetRichlist provides a mock function with given fields: includeDistribution, n
+func (_m *MockGatewayer) GetRichlist(includeDistribution bool, n int) (visor.Richlist, error) {
+	ret := _m.Called(includeDistribution, n)
 
 	var r0 visor.Richlist
-	if rf, ok := ret.Get(0).(func(bool) visor.Richlist); ok {
-		r0 = rf(includeDistribution)
+	if rf, ok := ret.Get(0).(func(bool, int) visor.Richlist); ok {
+		r0 = rf(includeDistribution, n)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(visor.Richlist)
@@ -602,8 +963,8 @@ func (_m *MockGatewayer) GetRichlist(includeDistribution bool) (visor.Richlist,
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(bool) error); ok {
-		r1 = rf(includeDistribution)
+	if rf, ok := ret.Get(1).(func(bool, int) error); ok {
+		r1 = rf(includeDistribution, n)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -891,22 +1252,54 @@ func (_m *MockGatewayer) GetTrustConnections() []string {
 	return r0
 }
 
-// GetUnspentOutputsSummary provides a mock function with given fields: filters
-func (_m *MockGatewayer) GetUnspentOutputsSummary(filters []visor.OutputsFilter) (*visor.UnspentOutputsSummary, error) {
-	ret := _m.Called(filters)
+// GetUnconfirmedTransactionsVerbose provides a mock function with given fields: filter
+func (_m *MockGatewayer) GetUnconfirmedTransactionsVerbose(filter func(visor.UnconfirmedTransaction) bool) ([]visor.UnconfirmedTransaction, [][]visor.TransactionInput, error) {
+	ret := _m.Called(filter)
+
+	var r0 []visor.UnconfirmedTransaction
+	if rf, ok := ret.Get(0).(func(func(visor.UnconfirmedTransaction) bool) []visor.UnconfirmedTransaction); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]visor.UnconfirmedTransaction)
+		}
+	}
+
+	var r1 [][]visor.TransactionInput
+	if rf, ok := ret.Get(1).(func(func(visor.UnconfirmedTransaction) bool) [][]visor.TransactionInput); ok {
+		r1 = rf(filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([][]visor.TransactionInput)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(func(visor.UnconfirmedTransaction) bool) error); ok {
+		r2 = rf(filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetUnspentStats provides a mock function with given fields:
+func (_m *MockGatewayer) GetUnspentStats() (*visor.UnspentStats, error) {
+	ret := _m.Called()
 
-	var r0 *visor.UnspentOutputsSummary
-	if rf, ok := ret.Get(0).(func([]visor.OutputsFilter) *visor.UnspentOutputsSummary); ok {
-		r0 = rf(filters)
+	var r0 *visor.UnspentStats
+	if rf, ok := ret.Get(0).(func() *visor.UnspentStats); ok {
+		r0 = rf()
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*visor.UnspentOutputsSummary)
+			r0 = ret.Get(0).(*visor.UnspentStats)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func([]visor.OutputsFilter) error); ok {
-		r1 = rf(filters)
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1163,6 +1556,34 @@ func (_m *MockGatewayer) InjectBroadcastTransaction(txn coin.Transaction) error
 	return r0
 }
 
+// IsReadOnly provides a mock function with given fields:
+func (_m *MockGatewayer) IsReadOnly() bool {
+	ret := _m.Called()
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// LockWallet provides a mock function with given fields: wltID
+func (_m *MockGatewayer) LockWallet(wltID string) error {
+	ret := _m.Called(wltID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(wltID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewAddresses provides a mock function with given fields: wltID, password, n
 func (_m *MockGatewayer) NewAddresses(wltID string, password []byte, n uint64) ([]cipher.Address, error) {
 	ret := _m.Called(wltID, password, n)
@@ -1246,6 +1667,29 @@ func (_m *MockGatewayer) ResendUnconfirmedTxns() ([]cipher.SHA256, error) {
 	return r0, r1
 }
 
+// Snapshot provides a mock function with given fields:
+func (_m *MockGatewayer) Snapshot() (Snapshotter, error) {
+	ret := _m.Called()
+
+	var r0 Snapshotter
+	if rf, ok := ret.Get(0).(func() Snapshotter); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(Snapshotter)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // StartedAt provides a mock function with given fields:
 func (_m *MockGatewayer) StartedAt() time.Time {
 	ret := _m.Called()
@@ -1260,6 +1704,45 @@ func (_m *MockGatewayer) StartedAt() time.Time {
 	return r0
 }
 
+// Subscribe provides a mock function with given fields:
+func (_m *MockGatewayer) Subscribe() (<-chan visor.Event, func()) {
+	ret := _m.Called()
+
+	var r0 <-chan visor.Event
+	if rf, ok := ret.Get(0).(func() <-chan visor.Event); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan visor.Event)
+		}
+	}
+
+	var r1 func()
+	if rf, ok := ret.Get(1).(func() func()); ok {
+		r1 = rf()
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(func())
+		}
+	}
+
+	return r0, r1
+}
+
+// UnbanIP provides a mock function with given fields: ip
+func (_m *MockGatewayer) UnbanIP(ip string) error {
+	ret := _m.Called(ip)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(ip)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UnloadWallet provides a mock function with given fields: wltID
 func (_m *MockGatewayer) UnloadWallet(wltID string) error {
 	ret := _m.Called(wltID)
@@ -1274,6 +1757,27 @@ func (_m *MockGatewayer) UnloadWallet(wltID string) error {
 	return r0
 }
 
+// UnlockWallet provides a mock function with given fields: wltID, password, timeout
+func (_m *MockGatewayer) UnlockWallet(wltID string, password []byte, timeout time.Duration) (time.Time, error) {
+	ret := _m.Called(wltID, password, timeout)
+
+	var r0 time.Time
+	if rf, ok := ret.Get(0).(func(string, []byte, time.Duration) time.Time); ok {
+		r0 = rf(wltID, password, timeout)
+	} else {
+		r0 = ret.Get(0).(time.Time)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []byte, time.Duration) error); ok {
+		r1 = rf(wltID, password, timeout)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateWalletLabel provides a mock function with given fields: wltID, label
 func (_m *MockGatewayer) UpdateWalletLabel(wltID string, label string) error {
 	ret := _m.Called(wltID, label)
@@ -1332,6 +1836,20 @@ func (_m *MockGatewayer) VisorConfig() visor.Config {
 	return r0
 }
 
+// WalletConfirmAddressHardware provides a mock function with given fields: wltID, addr
+func (_m *MockGatewayer) WalletConfirmAddressHardware(wltID string, addr cipher.Address) error {
+	ret := _m.Called(wltID, addr)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, cipher.Address) error); ok {
+		r0 = rf(wltID, addr)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // WalletCreateTransaction provides a mock function with given fields: wltID, p, wp
 func (_m *MockGatewayer) WalletCreateTransaction(wltID string, p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error) {
 	ret := _m.Called(wltID, p, wp)
@@ -1364,6 +1882,38 @@ func (_m *MockGatewayer) WalletCreateTransaction(wltID string, p transaction.Par
 	return r0, r1, r2
 }
 
+// WalletCreateTransactionHardware provides a mock function with given fields: wltID, p, wp
+func (_m *MockGatewayer) WalletCreateTransactionHardware(wltID string, p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error) {
+	ret := _m.Called(wltID, p, wp)
+
+	var r0 *coin.Transaction
+	if rf, ok := ret.Get(0).(func(string, transaction.Params, visor.CreateTransactionParams) *coin.Transaction); ok {
+		r0 = rf(wltID, p, wp)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*coin.Transaction)
+		}
+	}
+
+	var r1 []visor.TransactionInput
+	if rf, ok := ret.Get(1).(func(string, transaction.Params, visor.CreateTransactionParams) []visor.TransactionInput); ok {
+		r1 = rf(wltID, p, wp)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]visor.TransactionInput)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string, transaction.Params, visor.CreateTransactionParams) error); ok {
+		r2 = rf(wltID, p, wp)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // WalletCreateTransactionSigned provides a mock function with given fields: wltID, password, p, wp
 func (_m *MockGatewayer) WalletCreateTransactionSigned(wltID string, password []byte, p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error) {
 	ret := _m.Called(wltID, password, p, wp)
@@ -1448,3 +1998,35 @@ func (_m *MockGatewayer) WalletSignTransaction(wltID string, password []byte, tx
 
 	return r0, r1, r2
 }
+
+// WalletSignTransactionHardware provides a mock function with given fields: wltID, txn
+func (_m *MockGatewayer) WalletSignTransactionHardware(wltID string, txn *coin.Transaction) (*coin.Transaction, []visor.TransactionInput, error) {
+	ret := _m.Called(wltID, txn)
+
+	var r0 *coin.Transaction
+	if rf, ok := ret.Get(0).(func(string, *coin.Transaction) *coin.Transaction); ok {
+		r0 = rf(wltID, txn)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*coin.Transaction)
+		}
+	}
+
+	var r1 []visor.TransactionInput
+	if rf, ok := ret.Get(1).(func(string, *coin.Transaction) []visor.TransactionInput); ok {
+		r1 = rf(wltID, txn)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]visor.TransactionInput)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string, *coin.Transaction) error); ok {
+		r2 = rf(wltID, txn)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
@@ -0,0 +1,424 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/transaction"
+	"github.com/SkycoinProject/cx-chains/src/util/fee"
+	"github.com/SkycoinProject/cx-chains/src/visor"
+	"github.com/SkycoinProject/cx-chains/src/visor/blockdb"
+	"github.com/SkycoinProject/cx-chains/src/wallet"
+)
+
+// MultisigAddressRequest is the request data for POST /api/v2/multisig/address
+type MultisigAddressRequest struct {
+	Required int      `json:"required"`
+	PubKeys  []string `json:"pubkeys"`
+	// WalletID and Address are optional, and add the public key of the wallet's address to the public keys
+	WalletID string `json:"wallet_id,omitempty"`
+	Address  string `json:"address,omitempty"`
+}
+
+// MultisigAddressResponse is the response data for POST /api/v2/multisig/address
+type MultisigAddressResponse struct {
+	Address  string   `json:"address"`
+	Required int      `json:"required"`
+	PubKeys  []string `json:"pubkeys"`
+}
+
+// multisigAddressHandler creates an m-of-n multisig address
+// Method: POST
+// URI: /api/v2/multisig/address
+// Args: JSON body
+func multisigAddressHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req MultisigAddressRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if (req.WalletID == "") != (req.Address == "") {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "wallet_id and address must be used together")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		pubKeys, err := parseMultisigPubKeys(req.PubKeys)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var addr cipher.Address
+		if req.WalletID != "" {
+			walletAddr, err := cipher.DecodeBase58Address(req.Address)
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("invalid address: %v", err))
+				writeHTTPResponse(w, resp)
+				return
+			}
+
+			wlt, err := gateway.GetWallet(req.WalletID)
+			if err != nil {
+				var resp HTTPResponse
+				switch err {
+				case wallet.ErrWalletAPIDisabled:
+					resp = NewHTTPErrorResponse(http.StatusForbidden, "")
+				case wallet.ErrWalletNotExist:
+					resp = NewHTTPErrorResponse(http.StatusNotFound, err.Error())
+				default:
+					resp = NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+				}
+				writeHTTPResponse(w, resp)
+				return
+			}
+
+			addr, pubKeys, err = wlt.MultisigAddress(walletAddr, req.Required, pubKeys)
+			if err != nil {
+				var resp HTTPResponse
+				switch err.(type) {
+				case wallet.Error:
+					resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+				default:
+					resp = NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+				}
+				writeHTTPResponse(w, resp)
+				return
+			}
+		} else {
+			addr, err = cipher.MultisigAddress(req.Required, pubKeys)
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+				writeHTTPResponse(w, resp)
+				return
+			}
+			pubKeys = cipher.SortMultisigPubKeys(pubKeys)
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: MultisigAddressResponse{
+				Address:  addr.String(),
+				Required: req.Required,
+				PubKeys:  multisigPubKeysHex(pubKeys),
+			},
+		})
+	}
+}
+
+// MultisigAddressDescriptor describes a multisig address by its public keys
+type MultisigAddressDescriptor struct {
+	Required int      `json:"required"`
+	PubKeys  []string `json:"pubkeys"`
+}
+
+// MultisigInput is the signing status of a multisig transaction input
+type MultisigInput struct {
+	Address  string   `json:"address"`
+	Required int      `json:"required"`
+	PubKeys  []string `json:"pubkeys"`
+	// Signed has the public keys which signed the input
+	Signed []string `json:"signed"`
+}
+
+// MultisigTransactionResponse is returned by the /multisig/transaction endpoints.
+// It is a CreateTransactionResponse with the signing status of each input.
+type MultisigTransactionResponse struct {
+	CreateTransactionResponse
+	MultisigInputs []MultisigInput `json:"multisig_inputs"`
+	// Complete is true if every input has the required number of signatures
+	Complete bool `json:"complete"`
+}
+
+// NewMultisigTransactionResponse creates a MultisigTransactionResponse
+func NewMultisigTransactionResponse(txn *coin.Transaction, inputs []visor.TransactionInput) (*MultisigTransactionResponse, error) {
+	txnResp, err := NewCreateTransactionResponse(txn, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	witnesses, err := txn.MultisigWitnesses()
+	if err != nil {
+		return nil, err
+	}
+
+	msInputs := make([]MultisigInput, len(witnesses))
+	for i, w := range witnesses {
+		addr, err := w.Address()
+		if err != nil {
+			return nil, err
+		}
+
+		signed := []string{}
+		for j, s := range w.Sigs {
+			if !s.Null() {
+				signed = append(signed, w.PubKeys[j].Hex())
+			}
+		}
+
+		msInputs[i] = MultisigInput{
+			Address:  addr.String(),
+			Required: w.Required,
+			PubKeys:  multisigPubKeysHex(w.PubKeys),
+			Signed:   signed,
+		}
+	}
+
+	return &MultisigTransactionResponse{
+		CreateTransactionResponse: *txnResp,
+		MultisigInputs:            msInputs,
+		Complete:                  txn.IsFullySigned(),
+	}, nil
+}
+
+// MultisigCreateTransactionRequest is the request data for POST /api/v2/multisig/transaction
+type MultisigCreateTransactionRequest struct {
+	// Multisig describes the multisig addresses whose outputs can be spent
+	Multisig []MultisigAddressDescriptor `json:"multisig"`
+	createTransactionRequest
+}
+
+// multisigCreateTransactionHandler creates an unsigned transaction spending outputs of multisig addresses
+// Method: POST
+// URI: /api/v2/multisig/transaction
+// Args: JSON body
+func multisigCreateTransactionHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req MultisigCreateTransactionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if len(req.Multisig) == 0 {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "multisig is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if err := req.Validate(); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		witnesses := make(map[cipher.Address]coin.MultisigWitness, len(req.Multisig))
+		addrs := make([]cipher.Address, 0, len(req.Multisig))
+		for i, d := range req.Multisig {
+			pubKeys, err := parseMultisigPubKeys(d.PubKeys)
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("multisig[%d]: %v", i, err))
+				writeHTTPResponse(w, resp)
+				return
+			}
+
+			wit, err := coin.NewMultisigWitness(d.Required, pubKeys)
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("multisig[%d]: %v", i, err))
+				writeHTTPResponse(w, resp)
+				return
+			}
+
+			addr := cipher.MustMultisigAddress(wit.Required, wit.PubKeys)
+			if _, ok := witnesses[addr]; ok {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("multisig[%d]: duplicate multisig address", i))
+				writeHTTPResponse(w, resp)
+				return
+			}
+			witnesses[addr] = wit
+			addrs = append(addrs, addr)
+		}
+
+		// Spend from the multisig addresses if no addresses or unspents are specified
+		visorParams := req.VisorParams()
+		if len(visorParams.Addresses) == 0 && len(visorParams.UxOuts) == 0 {
+			visorParams.Addresses = addrs
+		}
+
+		txn, inputs, err := gateway.CreateTransaction(req.TransactionParams(), visorParams)
+		if err != nil {
+			var resp HTTPResponse
+			switch err.(type) {
+			case blockdb.ErrUnspentNotExist, transaction.Error, visor.UserError, wallet.Error:
+				resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			default:
+				switch err {
+				case fee.ErrTxnNoFee, fee.ErrTxnInsufficientCoinHours:
+					resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+				default:
+					resp = NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+				}
+			}
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		txnWitnesses := make([]coin.MultisigWitness, len(inputs))
+		for i, in := range inputs {
+			wit, ok := witnesses[in.UxOut.Body.Address]
+			if !ok {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("unspent output %s is not owned by a multisig address in the request", in.UxOut.Hash().Hex()))
+				writeHTTPResponse(w, resp)
+				return
+			}
+			txnWitnesses[i] = wit
+		}
+
+		if err := txn.SetMultisigWitnesses(txnWitnesses); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		// Verify the transaction again, since the witnesses change its size
+		if _, _, err := gateway.VerifyTxnVerbose(txn, visor.TxnUnsigned); err != nil {
+			var resp HTTPResponse
+			switch err.(type) {
+			case visor.ErrTxnViolatesSoftConstraint,
+				visor.ErrTxnViolatesHardConstraint,
+				visor.ErrTxnViolatesUserConstraint:
+				resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			default:
+				resp = NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			}
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		txnResp, err := NewMultisigTransactionResponse(txn, inputs)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusInternalServerError, fmt.Sprintf("NewMultisigTransactionResponse failed: %v", err))
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: txnResp,
+		})
+	}
+}
+
+// MultisigCombineTransactionsRequest is the request data for POST /api/v2/multisig/transaction/combine
+type MultisigCombineTransactionsRequest struct {
+	EncodedTransactions []string `json:"encoded_transactions"`
+}
+
+// multisigCombineTransactionsHandler combines the signatures of copies of a multisig transaction signed by different signers
+// Method: POST
+// URI: /api/v2/multisig/transaction/combine
+// Args: JSON body
+func multisigCombineTransactionsHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req MultisigCombineTransactionsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if len(req.EncodedTransactions) == 0 {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "encoded_transactions is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		txns := make([]coin.Transaction, len(req.EncodedTransactions))
+		for i, s := range req.EncodedTransactions {
+			txn, err := decodeTxn(s)
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("Decode transaction %d failed: %v", i, err))
+				writeHTTPResponse(w, resp)
+				return
+			}
+			txns[i] = *txn
+		}
+
+		txn, err := coin.MergeMultisigSignatures(txns)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		signed := visor.TxnSigned
+		if !txn.IsFullySigned() {
+			signed = visor.TxnUnsigned
+		}
+
+		inputs, _, err := gateway.VerifyTxnVerbose(txn, signed)
+		if err != nil {
+			var resp HTTPResponse
+			switch err.(type) {
+			case visor.ErrTxnViolatesSoftConstraint,
+				visor.ErrTxnViolatesHardConstraint,
+				visor.ErrTxnViolatesUserConstraint:
+				resp = NewHTTPErrorResponse(http.StatusUnprocessableEntity, err.Error())
+			default:
+				resp = NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			}
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		txnResp, err := NewMultisigTransactionResponse(txn, inputs)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusInternalServerError, fmt.Sprintf("NewMultisigTransactionResponse failed: %v", err))
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: txnResp,
+		})
+	}
+}
+
+func parseMultisigPubKeys(pubKeys []string) ([]cipher.PubKey, error) {
+	if len(pubKeys) == 0 {
+		return nil, errors.New("pubkeys is required")
+	}
+
+	keys := make([]cipher.PubKey, len(pubKeys))
+	for i, p := range pubKeys {
+		var err error
+		keys[i], err = cipher.PubKeyFromHex(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pubkeys[%d]: %v", i, err)
+		}
+	}
+	return keys, nil
+}
+
+func multisigPubKeysHex(pubKeys []cipher.PubKey) []string {
+	keys := make([]string, len(pubKeys))
+	for i, p := range pubKeys {
+		keys[i] = p.Hex()
+	}
+	return keys
+}
@@ -0,0 +1,506 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/params"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+	"github.com/SkycoinProject/cx-chains/src/transaction"
+	"github.com/SkycoinProject/cx-chains/src/visor"
+	"github.com/SkycoinProject/cx-chains/src/wallet"
+)
+
+// enableMultisig sets params.MultisigActivationSeq and returns a function which restores it
+func enableMultisig(seq uint64) func() {
+	prev := params.MultisigActivationSeq
+	params.MultisigActivationSeq = seq
+	return func() {
+		params.MultisigActivationSeq = prev
+	}
+}
+
+func makeMultisigKeys(n int) ([]cipher.PubKey, []cipher.SecKey) {
+	pubKeys := make([]cipher.PubKey, n)
+	secKeys := make([]cipher.SecKey, n)
+	for i := range pubKeys {
+		pubKeys[i], secKeys[i] = cipher.GenerateKeyPair()
+	}
+	return pubKeys, secKeys
+}
+
+func serveMultisigRequest(t *testing.T, gateway *MockGatewayer, endpoint, body string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Add("Content-Type", ContentTypeJSON)
+	setCSRFParameters(t, tokenValid, req)
+
+	rr := httptest.NewRecorder()
+	handler := newServerMux(defaultMuxConfig(), gateway)
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestMultisigAddress(t *testing.T) {
+	pubKeys, _ := makeMultisigKeys(2)
+
+	w, err := wallet.NewWallet("foo.wlt", wallet.Options{
+		Coin: wallet.CoinTypeSkycoin,
+		Seed: "multisig",
+	})
+	require.NoError(t, err)
+	walletAddr := w.Entries[0].SkycoinAddress()
+	walletPubKey := w.Entries[0].Public
+
+	body := func(required int, pubKeys []cipher.PubKey, walletID, addr string) string {
+		b, err := json.Marshal(MultisigAddressRequest{
+			Required: required,
+			PubKeys:  multisigPubKeysHex(pubKeys),
+			WalletID: walletID,
+			Address:  addr,
+		})
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	tt := []struct {
+		name             string
+		body             string
+		walletID         string
+		getWalletErr     error
+		status           int
+		err              string
+		address          cipher.Address
+		sortedPubKeys    []cipher.PubKey
+		gatewayGetWallet bool
+	}{
+		{
+			name:          "200",
+			body:          body(2, pubKeys, "", ""),
+			status:        http.StatusOK,
+			address:       cipher.MustMultisigAddress(2, pubKeys),
+			sortedPubKeys: cipher.SortMultisigPubKeys(pubKeys),
+		},
+		{
+			name:             "200 - wallet address",
+			body:             body(2, pubKeys, "foo.wlt", walletAddr.String()),
+			walletID:         "foo.wlt",
+			gatewayGetWallet: true,
+			status:           http.StatusOK,
+			address:          cipher.MustMultisigAddress(2, append([]cipher.PubKey{walletPubKey}, pubKeys...)),
+			sortedPubKeys:    cipher.SortMultisigPubKeys(append([]cipher.PubKey{walletPubKey}, pubKeys...)),
+		},
+		{
+			name:   "400 - invalid json",
+			body:   "{ca",
+			status: http.StatusBadRequest,
+			err:    "invalid character 'c' looking for beginning of object key string",
+		},
+		{
+			name:   "400 - missing pubkeys",
+			body:   body(1, nil, "", ""),
+			status: http.StatusBadRequest,
+			err:    "pubkeys is required",
+		},
+		{
+			name:   "400 - invalid pubkey",
+			body:   `{"required":1,"pubkeys":["foo"]}`,
+			status: http.StatusBadRequest,
+			err:    "invalid pubkeys[0]: Invalid public key",
+		},
+		{
+			name:   "400 - required exceeds pubkeys",
+			body:   body(3, pubKeys, "", ""),
+			status: http.StatusBadRequest,
+			err:    cipher.ErrMultisigRequired.Error(),
+		},
+		{
+			name:   "400 - wallet_id without address",
+			body:   body(1, pubKeys, "foo.wlt", ""),
+			status: http.StatusBadRequest,
+			err:    "wallet_id and address must be used together",
+		},
+		{
+			name:             "400 - address not in wallet",
+			body:             body(1, pubKeys, "foo.wlt", testutil.MakeAddress().String()),
+			walletID:         "foo.wlt",
+			gatewayGetWallet: true,
+			status:           http.StatusBadRequest,
+			err:              wallet.ErrUnknownAddress.Error(),
+		},
+		{
+			name:             "404 - wallet not found",
+			body:             body(1, pubKeys, "foo.wlt", walletAddr.String()),
+			walletID:         "foo.wlt",
+			gatewayGetWallet: true,
+			getWalletErr:     wallet.ErrWalletNotExist,
+			status:           http.StatusNotFound,
+			err:              "wallet doesn't exist",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.gatewayGetWallet {
+				if tc.getWalletErr != nil {
+					gateway.On("GetWallet", tc.walletID).Return(nil, tc.getWalletErr)
+				} else {
+					gateway.On("GetWallet", tc.walletID).Return(w, nil)
+				}
+			}
+
+			rr := serveMultisigRequest(t, gateway, "/api/v2/multisig/address", tc.body)
+			require.Equal(t, tc.status, rr.Code, "got `%v` want `%v` (%v)", rr.Code, tc.status, rr.Body)
+
+			var rsp ReceivedHTTPResponse
+			err := json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			if tc.status != http.StatusOK {
+				require.NotNil(t, rsp.Error)
+				require.Equal(t, tc.err, rsp.Error.Message)
+				return
+			}
+
+			var msg MultisigAddressResponse
+			err = json.Unmarshal(rsp.Data, &msg)
+			require.NoError(t, err)
+			require.Equal(t, tc.address.String(), msg.Address)
+			require.Equal(t, multisigPubKeysHex(tc.sortedPubKeys), msg.PubKeys)
+		})
+	}
+}
+
+func TestMultisigCreateTransaction(t *testing.T) {
+	defer enableMultisig(0)()
+
+	pubKeys, _ := makeMultisigKeys(3)
+	msAddr := cipher.MustMultisigAddress(2, pubKeys)
+	destinationAddress := testutil.MakeAddress()
+
+	makeInput := func(addr cipher.Address) visor.TransactionInput {
+		return visor.TransactionInput{
+			UxOut: coin.UxOut{
+				Body: coin.UxBody{
+					SrcTransaction: testutil.RandSHA256(t),
+					Address:        addr,
+					Coins:          2e6,
+					Hours:          100,
+				},
+			},
+			CalculatedHours: 100,
+		}
+	}
+
+	makeTxn := func(inputs []visor.TransactionInput) *coin.Transaction {
+		txn := &coin.Transaction{}
+		for _, in := range inputs {
+			err := txn.PushInput(in.UxOut.Hash())
+			require.NoError(t, err)
+		}
+		err := txn.PushOutput(destinationAddress, 1e6, 10, nil)
+		require.NoError(t, err)
+		err = txn.PushOutput(msAddr, uint64(len(inputs))*2e6-1e6, 10, nil)
+		require.NoError(t, err)
+		txn.Sigs = make([]cipher.Sig, len(inputs))
+		err = txn.UpdateHeader()
+		require.NoError(t, err)
+		return txn
+	}
+
+	body := func(multisig []MultisigAddressDescriptor) string {
+		b, err := json.Marshal(struct {
+			Multisig []MultisigAddressDescriptor `json:"multisig"`
+			rawCreateTxnRequest
+		}{
+			Multisig: multisig,
+			rawCreateTxnRequest: rawCreateTxnRequest{
+				HoursSelection: rawHoursSelection{
+					Type: transaction.HoursSelectionTypeManual,
+				},
+				To: []rawReceiver{
+					{
+						Address: destinationAddress.String(),
+						Coins:   "1",
+						Hours:   "10",
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	descriptor := MultisigAddressDescriptor{
+		Required: 2,
+		PubKeys:  multisigPubKeysHex(pubKeys),
+	}
+
+	tt := []struct {
+		name      string
+		body      string
+		inputs    []visor.TransactionInput
+		createErr error
+		verifyErr error
+		status    int
+		err       string
+	}{
+		{
+			name:   "200",
+			body:   body([]MultisigAddressDescriptor{descriptor}),
+			inputs: []visor.TransactionInput{makeInput(msAddr), makeInput(msAddr)},
+			status: http.StatusOK,
+		},
+		{
+			name:   "400 - missing multisig",
+			body:   body(nil),
+			status: http.StatusBadRequest,
+			err:    "multisig is required",
+		},
+		{
+			name: "400 - invalid multisig",
+			body: body([]MultisigAddressDescriptor{{
+				Required: 4,
+				PubKeys:  descriptor.PubKeys,
+			}}),
+			status: http.StatusBadRequest,
+			err:    "multisig[0]: " + cipher.ErrMultisigRequired.Error(),
+		},
+		{
+			name:   "400 - duplicate multisig address",
+			body:   body([]MultisigAddressDescriptor{descriptor, descriptor}),
+			status: http.StatusBadRequest,
+			err:    "multisig[1]: duplicate multisig address",
+		},
+		{
+			name:      "400 - create transaction error",
+			body:      body([]MultisigAddressDescriptor{descriptor}),
+			createErr: transaction.ErrInsufficientBalance,
+			status:    http.StatusBadRequest,
+			err:       "balance is not sufficient",
+		},
+		{
+			name:      "400 - verify error",
+			body:      body([]MultisigAddressDescriptor{descriptor}),
+			inputs:    []visor.TransactionInput{makeInput(msAddr)},
+			verifyErr: visor.NewErrTxnViolatesSoftConstraint(errors.New("Transaction size bigger than the max block size")),
+			status:    http.StatusBadRequest,
+			err:       "Transaction violates soft constraint: Transaction size bigger than the max block size",
+		},
+		{
+			name:   "500 - create transaction error",
+			body:   body([]MultisigAddressDescriptor{descriptor}),
+			status: http.StatusInternalServerError,
+			err:    "unhandled error",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+
+			var txn *coin.Transaction
+			createErr := tc.createErr
+			if tc.inputs != nil {
+				txn = makeTxn(tc.inputs)
+			} else if createErr == nil {
+				createErr = errors.New("unhandled error")
+			}
+
+			// The multisig addresses are spent if no addresses or unspents are specified
+			gateway.On("CreateTransaction", mock.Anything, visor.CreateTransactionParams{
+				Addresses: []cipher.Address{msAddr},
+			}).Return(txn, tc.inputs, createErr)
+			gateway.On("VerifyTxnVerbose", mock.Anything, visor.TxnUnsigned).Return(tc.inputs, false, tc.verifyErr)
+
+			rr := serveMultisigRequest(t, gateway, "/api/v2/multisig/transaction", tc.body)
+			require.Equal(t, tc.status, rr.Code, "got `%v` want `%v` (%v)", rr.Code, tc.status, rr.Body)
+
+			var rsp ReceivedHTTPResponse
+			err := json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			if tc.status != http.StatusOK {
+				require.NotNil(t, rsp.Error)
+				require.Equal(t, tc.err, rsp.Error.Message)
+				return
+			}
+
+			var msg MultisigTransactionResponse
+			err = json.Unmarshal(rsp.Data, &msg)
+			require.NoError(t, err)
+
+			require.False(t, msg.Complete)
+			require.Len(t, msg.MultisigInputs, len(tc.inputs))
+			for _, in := range msg.MultisigInputs {
+				require.Equal(t, msAddr.String(), in.Address)
+				require.Equal(t, 2, in.Required)
+				require.Equal(t, multisigPubKeysHex(cipher.SortMultisigPubKeys(pubKeys)), in.PubKeys)
+				require.Empty(t, in.Signed)
+			}
+
+			createdTxn, err := coin.DeserializeTransactionHex(msg.EncodedTransaction)
+			require.NoError(t, err)
+			require.True(t, createdTxn.IsMultisig())
+			require.NoError(t, createdTxn.VerifyUnsigned())
+			require.Equal(t, txn.InnerHash, createdTxn.InnerHash)
+		})
+	}
+}
+
+func TestMultisigCombineTransactions(t *testing.T) {
+	defer enableMultisig(0)()
+
+	pubKeys, secKeys := makeMultisigKeys(3)
+	msAddr := cipher.MustMultisigAddress(2, pubKeys)
+
+	input := visor.TransactionInput{
+		UxOut: coin.UxOut{
+			Body: coin.UxBody{
+				SrcTransaction: testutil.RandSHA256(t),
+				Address:        msAddr,
+				Coins:          2e6,
+				Hours:          100,
+			},
+		},
+		CalculatedHours: 100,
+	}
+
+	txn := coin.Transaction{}
+	err := txn.PushInput(input.UxOut.Hash())
+	require.NoError(t, err)
+	err = txn.PushOutput(testutil.MakeAddress(), 2e6, 10, nil)
+	require.NoError(t, err)
+	wit, err := coin.NewMultisigWitness(2, pubKeys)
+	require.NoError(t, err)
+	err = txn.SetMultisigWitnesses([]coin.MultisigWitness{wit})
+	require.NoError(t, err)
+
+	sign := func(key cipher.SecKey) string {
+		signed := txn
+		signed.Sigs = append([]cipher.Sig{}, txn.Sigs...)
+		err := signed.SignMultisigInput(key, 0)
+		require.NoError(t, err)
+		return signed.MustSerializeHex()
+	}
+
+	stdTxn := coin.Transaction{}
+	err = stdTxn.PushInput(input.UxOut.Hash())
+	require.NoError(t, err)
+	err = stdTxn.PushOutput(testutil.MakeAddress(), 2e6, 10, nil)
+	require.NoError(t, err)
+	stdTxn.SignInputs([]cipher.SecKey{secKeys[0]})
+	err = stdTxn.UpdateHeader()
+	require.NoError(t, err)
+
+	signed0 := sign(secKeys[0])
+	signed2 := sign(secKeys[2])
+
+	body := func(encoded ...string) string {
+		b, err := json.Marshal(MultisigCombineTransactionsRequest{
+			EncodedTransactions: encoded,
+		})
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	tt := []struct {
+		name      string
+		body      string
+		signed    visor.TxnSignedFlag
+		verifyErr error
+		status    int
+		err       string
+		complete  bool
+		nSigned   int
+	}{
+		{
+			name:     "200 - complete",
+			body:     body(signed0, signed2),
+			signed:   visor.TxnSigned,
+			status:   http.StatusOK,
+			complete: true,
+			nSigned:  2,
+		},
+		{
+			name:    "200 - partially signed",
+			body:    body(txn.MustSerializeHex(), signed0),
+			signed:  visor.TxnUnsigned,
+			status:  http.StatusOK,
+			nSigned: 1,
+		},
+		{
+			name:   "400 - missing encoded_transactions",
+			body:   body(),
+			status: http.StatusBadRequest,
+			err:    "encoded_transactions is required",
+		},
+		{
+			name:   "400 - invalid encoded transaction",
+			body:   body(signed0, "abc"),
+			status: http.StatusBadRequest,
+			err:    "Decode transaction 1 failed: encoding/hex: odd length hex string",
+		},
+		{
+			name:   "400 - not a multisig transaction",
+			body:   body(stdTxn.MustSerializeHex()),
+			status: http.StatusBadRequest,
+			err:    coin.ErrNotMultisigTransaction.Error(),
+		},
+		{
+			name:      "422 - verify error",
+			body:      body(signed0, signed2),
+			signed:    visor.TxnSigned,
+			verifyErr: visor.NewErrTxnViolatesHardConstraint(errors.New("Signature not valid for output being spent")),
+			status:    http.StatusUnprocessableEntity,
+			err:       "Transaction violates hard constraint: Signature not valid for output being spent",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("VerifyTxnVerbose", mock.Anything, tc.signed).Return([]visor.TransactionInput{input}, false, tc.verifyErr)
+
+			rr := serveMultisigRequest(t, gateway, "/api/v2/multisig/transaction/combine", tc.body)
+			require.Equal(t, tc.status, rr.Code, "got `%v` want `%v` (%v)", rr.Code, tc.status, rr.Body)
+
+			var rsp ReceivedHTTPResponse
+			err := json.Unmarshal(bytes.TrimSpace(rr.Body.Bytes()), &rsp)
+			require.NoError(t, err)
+
+			if tc.status != http.StatusOK {
+				require.NotNil(t, rsp.Error)
+				require.Equal(t, tc.err, rsp.Error.Message)
+				return
+			}
+
+			var msg MultisigTransactionResponse
+			err = json.Unmarshal(rsp.Data, &msg)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.complete, msg.Complete)
+			require.Len(t, msg.MultisigInputs, 1)
+			require.Len(t, msg.MultisigInputs[0].Signed, tc.nSigned)
+
+			merged, err := coin.DeserializeTransactionHex(msg.EncodedTransaction)
+			require.NoError(t, err)
+			require.Equal(t, txn.InnerHash, merged.InnerHash)
+			if tc.complete {
+				require.NoError(t, merged.Verify())
+				require.NoError(t, merged.VerifyInputSignatures(coin.UxArray{input.UxOut}))
+			}
+		})
+	}
+}
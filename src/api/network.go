@@ -8,6 +8,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/SkycoinProject/cx-chains/src/daemon"
 	"github.com/SkycoinProject/cx-chains/src/readable"
@@ -18,6 +19,7 @@ import (
 // URI: /api/v1/network/connections
 // Method: GET
 // Args:
+//
 //	addr - An IP:Port string
 func connectionHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -47,13 +49,14 @@ func connectionHandler(gateway Gatewayer) http.HandlerFunc {
 	}
 }
 
-// Connections wraps []Connection
+// Connections wraps []Connection and the connection slots of the node
 type Connections struct {
-	Connections []readable.Connection `json:"connections"`
+	Connections []readable.Connection    `json:"connections"`
+	Slots       readable.ConnectionSlots `json:"slots"`
 }
 
-// NewConnections copies []daemon.Connection to a struct with json tags
-func NewConnections(dconns []daemon.Connection) Connections {
+// NewConnections copies []daemon.Connection and daemon.ConnectionSlots to a struct with json tags
+func NewConnections(dconns []daemon.Connection, slots daemon.ConnectionSlots) Connections {
 	conns := make([]readable.Connection, len(dconns))
 	for i, dc := range dconns {
 		conns[i] = readable.NewConnection(&dc)
@@ -61,15 +64,17 @@ func NewConnections(dconns []daemon.Connection) Connections {
 
 	return Connections{
 		Connections: conns,
+		Slots:       readable.NewConnectionSlots(slots),
 	}
 }
 
-// connectionsHandler returns all outgoing connections
+// connectionsHandler returns the connections and the connection slots of the node
 // URI: /api/v1/network/connections
 // Method: GET
 // Args:
-//	states: [optional] comma-separated list of connection states ("pending", "connected" or "introduced"). Defaults to "connected,introduced"
-//  direction: [optional] "outgoing" or "incoming". If not provided, both are included.
+//
+//		states: [optional] comma-separated list of connection states ("pending", "connected" or "introduced"). Defaults to "connected,introduced"
+//	 direction: [optional] "outgoing" or "incoming". If not provided, both are included.
 func connectionsHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
@@ -132,7 +137,7 @@ func connectionsHandler(gateway Gatewayer) http.HandlerFunc {
 			return
 		}
 
-		wh.SendJSONOr500(logger, w, NewConnections(conns))
+		wh.SendJSONOr500(logger, w, NewConnections(conns, gateway.GetConnectionSlots()))
 	}
 }
 
@@ -193,6 +198,7 @@ func exchgConnectionsHandler(gateway Gatewayer) http.HandlerFunc {
 // URI: /api/v1/network/connection/disconnect
 // Method: POST
 // Args:
+//
 //	id: ID of the connection
 func disconnectHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -226,3 +232,81 @@ func disconnectHandler(gateway Gatewayer) http.HandlerFunc {
 		wh.SendJSONOr500(logger, w, struct{}{})
 	}
 }
+
+// Bans wraps []readable.Ban
+type Bans struct {
+	Bans []readable.Ban `json:"bans"`
+}
+
+// bansHandler lists, adds and removes banned peer IP addresses
+// URI: /api/v1/network/bans
+// Method: GET, POST, DELETE
+// GET returns the banned IP addresses
+// POST bans an IP address and disconnects its connections. Args:
+//
+//	ip: IP address to ban
+//	reason: [optional] reason for the ban
+//	duration: [optional] duration of the ban, e.g. "1h30m". Defaults to the node's ban duration
+//
+// DELETE removes the ban of an IP address. Args:
+//
+//	ip: banned IP address
+func bansHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			wh.SendJSONOr500(logger, w, Bans{
+				Bans: readable.NewBans(gateway.GetBans()),
+			})
+		case http.MethodPost:
+			ip := r.FormValue("ip")
+			if ip == "" {
+				wh.Error400(w, "ip is required")
+				return
+			}
+
+			var duration time.Duration
+			if d := r.FormValue("duration"); d != "" {
+				var err error
+				duration, err = time.ParseDuration(d)
+				if err != nil || duration < 0 {
+					wh.Error400(w, "invalid duration")
+					return
+				}
+			}
+
+			reason := r.FormValue("reason")
+			if reason == "" {
+				reason = "banned by the node operator"
+			}
+
+			ban, err := gateway.BanIP(ip, reason, duration)
+			if err != nil {
+				wh.Error400(w, err.Error())
+				return
+			}
+
+			wh.SendJSONOr500(logger, w, readable.NewBan(*ban))
+		case http.MethodDelete:
+			ip := r.FormValue("ip")
+			if ip == "" {
+				wh.Error400(w, "ip is required")
+				return
+			}
+
+			if err := gateway.UnbanIP(ip); err != nil {
+				switch err {
+				case daemon.ErrBanNotFound:
+					wh.Error404(w, "")
+				default:
+					wh.Error500(w, err.Error())
+				}
+				return
+			}
+
+			wh.SendJSONOr500(logger, w, struct{}{})
+		default:
+			wh.Error405(w)
+		}
+	}
+}
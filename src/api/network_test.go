@@ -178,6 +178,8 @@ func TestConnections(t *testing.T) {
 			Height:      1234,
 			UserAgent:   useragent.MustParse("skycoin:0.25.1(foo)"),
 		},
+		Protected:        true,
+		MisbehaviorScore: 20,
 	}
 
 	readIntrOut := readable.Connection{
@@ -196,23 +198,43 @@ func TestConnections(t *testing.T) {
 	}
 
 	readIntrIn := readable.Connection{
-		Addr:          "127.0.0.2:6062",
-		GnetID:        2,
-		LastSent:      99999,
-		LastReceived:  1111111,
-		ConnectedAt:   222222,
-		Outgoing:      false,
-		State:         daemon.ConnectionStateIntroduced,
-		Mirror:        9877,
-		ListenPort:    9879,
-		Height:        1234,
-		UserAgent:     useragent.MustParse("skycoin:0.25.1(foo)"),
-		IsTrustedPeer: false,
+		Addr:             "127.0.0.2:6062",
+		GnetID:           2,
+		LastSent:         99999,
+		LastReceived:     1111111,
+		ConnectedAt:      222222,
+		Outgoing:         false,
+		State:            daemon.ConnectionStateIntroduced,
+		Mirror:           9877,
+		ListenPort:       9879,
+		Height:           1234,
+		UserAgent:        useragent.MustParse("skycoin:0.25.1(foo)"),
+		IsTrustedPeer:    false,
+		Protected:        true,
+		MisbehaviorScore: 20,
 	}
 
 	conns := []daemon.Connection{intrOut, intrIn}
 	readConns := []readable.Connection{readIntrOut, readIntrIn}
 
+	slots := daemon.ConnectionSlots{
+		MaxConnections: 128,
+		OutgoingTarget: 8,
+		Outgoing:       1,
+		MaxIncoming:    110,
+		ProtectedSlots: 8,
+		Protected:      1,
+	}
+
+	readSlots := readable.ConnectionSlots{
+		MaxConnections: 128,
+		OutgoingTarget: 8,
+		Outgoing:       1,
+		MaxIncoming:    110,
+		ProtectedSlots: 8,
+		Protected:      1,
+	}
+
 	tt := []struct {
 		name                                 string
 		method                               string
@@ -239,6 +261,7 @@ func TestConnections(t *testing.T) {
 			gatewayGetSolicitedConnectionsResult: conns,
 			result: Connections{
 				Connections: readConns,
+				Slots:       readSlots,
 			},
 		},
 
@@ -251,6 +274,7 @@ func TestConnections(t *testing.T) {
 			gatewayGetSolicitedConnectionsResult: conns,
 			result: Connections{
 				Connections: readConns,
+				Slots:       readSlots,
 			},
 		},
 
@@ -263,6 +287,7 @@ func TestConnections(t *testing.T) {
 			gatewayGetSolicitedConnectionsResult: conns,
 			result: Connections{
 				Connections: readConns,
+				Slots:       readSlots,
 			},
 		},
 
@@ -275,6 +300,7 @@ func TestConnections(t *testing.T) {
 			gatewayGetSolicitedConnectionsResult: conns,
 			result: Connections{
 				Connections: readConns,
+				Slots:       readSlots,
 			},
 		},
 
@@ -288,6 +314,7 @@ func TestConnections(t *testing.T) {
 			gatewayGetSolicitedConnectionsResult: conns,
 			result: Connections{
 				Connections: readConns,
+				Slots:       readSlots,
 			},
 		},
 
@@ -300,6 +327,7 @@ func TestConnections(t *testing.T) {
 			gatewayGetSolicitedConnectionsResult: conns,
 			result: Connections{
 				Connections: readConns,
+				Slots:       readSlots,
 			},
 		},
 
@@ -333,6 +361,7 @@ func TestConnections(t *testing.T) {
 			endpoint := "/api/v1/network/connections"
 			gateway := &MockGatewayer{}
 			gateway.On("GetConnections", mock.Anything).Return(tc.gatewayGetSolicitedConnectionsResult, tc.gatewayGetSolicitedConnectionsError)
+			gateway.On("GetConnectionSlots").Return(slots)
 
 			v := url.Values{}
 			if tc.states != "" {
@@ -628,3 +657,210 @@ func TestDisconnect(t *testing.T) {
 		})
 	}
 }
+
+func TestBans(t *testing.T) {
+	created := time.Unix(1600000000, 0).UTC()
+	ban := daemon.Ban{
+		IP:      "1.2.3.4",
+		Reason:  "malformed message",
+		Created: created,
+		Expires: created.Add(time.Hour),
+	}
+
+	tt := []struct {
+		name       string
+		method     string
+		status     int
+		err        string
+		ip         string
+		reason     string
+		duration   string
+		banArgs    []interface{}
+		banResult  *daemon.Ban
+		banErr     error
+		unbanErr   error
+		getBans    []daemon.Ban
+		expectBans *Bans
+		expectBan  *readable.Ban
+	}{
+		{
+			name:   "405",
+			method: http.MethodPut,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+
+		{
+			name:    "200 GET",
+			method:  http.MethodGet,
+			status:  http.StatusOK,
+			getBans: []daemon.Ban{ban},
+			expectBans: &Bans{
+				Bans: []readable.Ban{
+					{
+						IP:      "1.2.3.4",
+						Reason:  "malformed message",
+						Created: 1600000000,
+						Expires: 1600003600,
+					},
+				},
+			},
+		},
+
+		{
+			name:       "200 GET no bans",
+			method:     http.MethodGet,
+			status:     http.StatusOK,
+			getBans:    []daemon.Ban{},
+			expectBans: &Bans{Bans: []readable.Ban{}},
+		},
+
+		{
+			name:   "400 POST missing ip",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - ip is required",
+		},
+
+		{
+			name:     "400 POST invalid duration",
+			method:   http.MethodPost,
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - invalid duration",
+			ip:       "1.2.3.4",
+			duration: "foo",
+		},
+
+		{
+			name:     "400 POST negative duration",
+			method:   http.MethodPost,
+			status:   http.StatusBadRequest,
+			err:      "400 Bad Request - invalid duration",
+			ip:       "1.2.3.4",
+			duration: "-1h",
+		},
+
+		{
+			name:    "400 POST invalid ip",
+			method:  http.MethodPost,
+			status:  http.StatusBadRequest,
+			err:     `400 Bad Request - Invalid IP address "foo"`,
+			ip:      "foo",
+			banArgs: []interface{}{"foo", "banned by the node operator", time.Duration(0)},
+			banErr:  errors.New(`Invalid IP address "foo"`),
+		},
+
+		{
+			name:      "200 POST",
+			method:    http.MethodPost,
+			status:    http.StatusOK,
+			ip:        "1.2.3.4",
+			reason:    "malformed message",
+			duration:  "1h",
+			banArgs:   []interface{}{"1.2.3.4", "malformed message", time.Hour},
+			banResult: &ban,
+			expectBan: &readable.Ban{
+				IP:      "1.2.3.4",
+				Reason:  "malformed message",
+				Created: 1600000000,
+				Expires: 1600003600,
+			},
+		},
+
+		{
+			name:   "400 DELETE missing ip",
+			method: http.MethodDelete,
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - ip is required",
+		},
+
+		{
+			name:     "404 DELETE ban not found",
+			method:   http.MethodDelete,
+			status:   http.StatusNotFound,
+			err:      "404 Not Found",
+			ip:       "1.2.3.4",
+			unbanErr: daemon.ErrBanNotFound,
+		},
+
+		{
+			name:     "500 DELETE error",
+			method:   http.MethodDelete,
+			status:   http.StatusInternalServerError,
+			err:      "500 Internal Server Error - foo",
+			ip:       "1.2.3.4",
+			unbanErr: errors.New("foo"),
+		},
+
+		{
+			name:   "200 DELETE",
+			method: http.MethodDelete,
+			status: http.StatusOK,
+			ip:     "1.2.3.4",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetBans").Return(tc.getBans)
+			if tc.banArgs != nil {
+				gateway.On("BanIP", tc.banArgs...).Return(tc.banResult, tc.banErr)
+			}
+			gateway.On("UnbanIP", tc.ip).Return(tc.unbanErr)
+
+			endpoint := "/api/v1/network/bans"
+			v := url.Values{}
+			if tc.ip != "" {
+				v.Add("ip", tc.ip)
+			}
+			if tc.reason != "" {
+				v.Add("reason", tc.reason)
+			}
+			if tc.duration != "" {
+				v.Add("duration", tc.duration)
+			}
+
+			var body string
+			if tc.method == http.MethodPost {
+				body = v.Encode()
+			} else if len(v) > 0 {
+				endpoint += "?" + v.Encode()
+			}
+
+			req, err := http.NewRequest(tc.method, endpoint, strings.NewReader(body))
+			require.NoError(t, err)
+			req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			if status != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()), "got `%v`| %d, want `%v`",
+					strings.TrimSpace(rr.Body.String()), status, tc.err)
+				return
+			}
+
+			switch {
+			case tc.expectBans != nil:
+				var obj Bans
+				err = json.Unmarshal(rr.Body.Bytes(), &obj)
+				require.NoError(t, err)
+				require.Equal(t, *tc.expectBans, obj)
+			case tc.expectBan != nil:
+				var obj readable.Ban
+				err = json.Unmarshal(rr.Body.Bytes(), &obj)
+				require.NoError(t, err)
+				require.Equal(t, *tc.expectBan, obj)
+			default:
+				var obj struct{}
+				err = json.Unmarshal(rr.Body.Bytes(), &obj)
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/readable"
+	wh "github.com/SkycoinProject/cx-chains/src/util/http"
+	"github.com/SkycoinProject/cx-chains/src/visor"
+)
+
+// BlockPolicy is the policy used by a block publisher to choose the transactions of a block
+type BlockPolicy struct {
+	MaxTransactions int      `json:"max_transactions"`
+	Ordering        string   `json:"ordering"`
+	AllowAddresses  []string `json:"allow_addresses"`
+	DenyAddresses   []string `json:"deny_addresses"`
+}
+
+// ExcludedTransaction is a transaction of the unconfirmed pool that is not in the next block
+type ExcludedTransaction struct {
+	Hash   string `json:"txid"`
+	Reason string `json:"reason"`
+}
+
+// NextBlockResponse is returned by GET /api/v1/next-block
+type NextBlockResponse struct {
+	// Block is null if no transaction of the unconfirmed pool can be included
+	Block    *readable.Block       `json:"block"`
+	Policy   BlockPolicy           `json:"policy"`
+	MaxSize  uint32                `json:"max_size"`
+	Size     uint32                `json:"size"`
+	Pending  int                   `json:"pending"`
+	Excluded []ExcludedTransaction `json:"excluded"`
+}
+
+// NewNextBlockResponse creates a NextBlockResponse from visor.NextBlock
+func NewNextBlockResponse(nb *visor.NextBlock) (*NextBlockResponse, error) {
+	var b *readable.Block
+	if nb.Block != nil {
+		var err error
+		b, err = readable.NewBlock(*nb.Block)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ordering := nb.Policy.Ordering
+	if ordering == "" {
+		ordering = visor.TxnOrderingFee
+	}
+
+	excluded := make([]ExcludedTransaction, len(nb.Excluded))
+	for i, e := range nb.Excluded {
+		excluded[i] = ExcludedTransaction{
+			Hash:   e.Hash.Hex(),
+			Reason: e.Err.Error(),
+		}
+	}
+
+	return &NextBlockResponse{
+		Block: b,
+		Policy: BlockPolicy{
+			MaxTransactions: nb.Policy.MaxTransactions,
+			Ordering:        string(ordering),
+			AllowAddresses:  addressesToStrings(nb.Policy.AllowAddresses),
+			DenyAddresses:   addressesToStrings(nb.Policy.DenyAddresses),
+		},
+		MaxSize:  nb.MaxSize,
+		Size:     nb.Size,
+		Pending:  nb.Pending,
+		Excluded: excluded,
+	}, nil
+}
+
+func addressesToStrings(addrs []cipher.Address) []string {
+	s := make([]string, len(addrs))
+	for i, a := range addrs {
+		s[i] = a.String()
+	}
+	return s
+}
+
+// nextBlockHandler returns the block that a block publisher would create from the unconfirmed pool
+// with the configured block policy, and the pending transactions that would not be included and why.
+// The block is not signed nor executed.
+// Method: GET
+// URI: /api/v1/next-block
+func nextBlockHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
+		nb, err := gateway.GetNextBlock()
+		if err != nil {
+			err = fmt.Errorf("gateway.GetNextBlock failed: %v", err)
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		resp, err := NewNextBlockResponse(nb)
+		if err != nil {
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		wh.SendJSONOr500(logger, w, resp)
+	}
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/readable"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+	"github.com/SkycoinProject/cx-chains/src/visor"
+)
+
+func TestNextBlock(t *testing.T) {
+	b, err := coin.NewGenesisBlock(testutil.MakeAddress(), 1000e6, 1000, nil)
+	require.NoError(t, err)
+	rb, err := readable.NewBlock(*b)
+	require.NoError(t, err)
+
+	deniedAddr := testutil.MakeAddress()
+	excludedHash := testutil.RandSHA256(t)
+
+	nb := &visor.NextBlock{
+		Block: b,
+		Policy: visor.BlockPolicy{
+			MaxTransactions: 10,
+			Ordering:        visor.TxnOrderingFIFO,
+			DenyAddresses:   []cipher.Address{deniedAddr},
+		},
+		MaxSize: 32768,
+		Size:    rb.Body.Transactions[0].Length,
+		Pending: 2,
+		Excluded: []visor.ExcludedTransaction{
+			{
+				Hash: excludedHash,
+				Err:  visor.ErrTxnDeniedAddress,
+			},
+		},
+	}
+
+	tt := []struct {
+		name          string
+		method        string
+		status        int
+		err           string
+		gatewayResult *visor.NextBlock
+		gatewayErr    error
+		httpResponse  NextBlockResponse
+	}{
+		{
+			name:   "405",
+			method: http.MethodPost,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+		{
+			name:       "500 - gateway error",
+			method:     http.MethodGet,
+			gatewayErr: errors.New("failed"),
+			status:     http.StatusInternalServerError,
+			err:        "500 Internal Server Error - gateway.GetNextBlock failed: failed",
+		},
+		{
+			name:          "200 - empty pool",
+			method:        http.MethodGet,
+			gatewayResult: &visor.NextBlock{MaxSize: 32768},
+			status:        http.StatusOK,
+			httpResponse: NextBlockResponse{
+				Policy: BlockPolicy{
+					Ordering:       string(visor.TxnOrderingFee),
+					AllowAddresses: []string{},
+					DenyAddresses:  []string{},
+				},
+				MaxSize:  32768,
+				Excluded: []ExcludedTransaction{},
+			},
+		},
+		{
+			name:          "200",
+			method:        http.MethodGet,
+			gatewayResult: nb,
+			status:        http.StatusOK,
+			httpResponse: NextBlockResponse{
+				Block: rb,
+				Policy: BlockPolicy{
+					MaxTransactions: 10,
+					Ordering:        string(visor.TxnOrderingFIFO),
+					AllowAddresses:  []string{},
+					DenyAddresses:   []string{deniedAddr.String()},
+				},
+				MaxSize: 32768,
+				Size:    rb.Body.Transactions[0].Length,
+				Pending: 2,
+				Excluded: []ExcludedTransaction{
+					{
+						Hash:   excludedHash.Hex(),
+						Reason: "Transaction spends from or sends to a denied address",
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetNextBlock").Return(tc.gatewayResult, tc.gatewayErr)
+
+			req, err := http.NewRequest(tc.method, "/api/v1/next-block", nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code, "got `%v` want `%v`", rr.Code, tc.status)
+
+			if rr.Code != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()))
+				return
+			}
+
+			var msg NextBlockResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &msg)
+			require.NoError(t, err)
+			require.Equal(t, tc.httpResponse, msg)
+		})
+	}
+}
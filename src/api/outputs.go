@@ -59,9 +59,17 @@ func outputsHandler(gateway Gatewayer) http.HandlerFunc {
 			}
 		}
 
-		summary, err := gateway.GetUnspentOutputsSummary(filters)
+		s, err := gateway.Snapshot()
 		if err != nil {
-			err = fmt.Errorf("gateway.GetUnspentOutputsSummary failed: %v", err)
+			err = fmt.Errorf("gateway.Snapshot failed: %v", err)
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		summary, err := s.GetUnspentOutputsSummary(filters)
+		releaseSnapshot(s)
+		if err != nil {
+			err = fmt.Errorf("snapshot.GetUnspentOutputsSummary failed: %v", err)
 			wh.Error500(w, err.Error())
 			return
 		}
@@ -33,6 +33,7 @@ func TestGetOutputsHandler(t *testing.T) {
 		status                    int
 		err                       string
 		httpBody                  *httpBody
+		snapshotErr               error
 		getUnspentOutputsResponse *visor.UnspentOutputsSummary
 		getUnspentOutputsError    error
 		httpResponse              *readable.UnspentOutputsSummary
@@ -62,11 +63,18 @@ func TestGetOutputsHandler(t *testing.T) {
 				addrs: invalidAddr,
 			},
 		},
+		{
+			name:        "500 - snapshotErr",
+			method:      http.MethodGet,
+			status:      http.StatusInternalServerError,
+			err:         "500 Internal Server Error - gateway.Snapshot failed: snapshotErr",
+			snapshotErr: errors.New("snapshotErr"),
+		},
 		{
 			name:                      "500 - getUnspentOutputsError",
 			method:                    http.MethodGet,
 			status:                    http.StatusInternalServerError,
-			err:                       "500 Internal Server Error - gateway.GetUnspentOutputsSummary failed: getUnspentOutputsError",
+			err:                       "500 Internal Server Error - snapshot.GetUnspentOutputsSummary failed: getUnspentOutputsError",
 			getUnspentOutputsResponse: nil,
 			getUnspentOutputsError:    errors.New("getUnspentOutputsError"),
 		},
@@ -112,9 +120,17 @@ func TestGetOutputsHandler(t *testing.T) {
 
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
+			snapshot := &MockSnapshotter{}
+			snapshot.On("GetUnspentOutputsSummary", mock.Anything).Return(tc.getUnspentOutputsResponse, tc.getUnspentOutputsError)
+			snapshot.On("Release").Return(nil)
+
 			gateway := &MockGatewayer{}
 			endpoint := "/api/v1/outputs"
-			gateway.On("GetUnspentOutputsSummary", mock.Anything).Return(tc.getUnspentOutputsResponse, tc.getUnspentOutputsError)
+			if tc.snapshotErr != nil {
+				gateway.On("Snapshot").Return(nil, tc.snapshotErr)
+			} else {
+				gateway.On("Snapshot").Return(snapshot, nil)
+			}
 
 			v := url.Values{}
 			if tc.httpBody != nil {
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+)
+
+// expensiveEndpoints are the endpoints which dump large parts of the blockchain or unspent output set.
+// Their requests are limited by the expensive rate limiter, in addition to the rate limiter of all requests.
+var expensiveEndpoints = map[string]struct{}{
+	"/api/v1/blocks":          struct{}{},
+	"/api/v1/last_blocks":     struct{}{},
+	"/api/v1/next-block":      struct{}{},
+	"/api/v1/headers":         struct{}{},
+	"/api/v1/transactions":    struct{}{},
+	"/api/v1/outputs":         struct{}{},
+	"/api/v1/balances":        struct{}{},
+	"/api/v1/richlist":        struct{}{},
+	"/api/v1/addresscount":    struct{}{},
+	"/api/v1/admin/backup":    struct{}{},
+	"/api/v1/admin/compactdb": struct{}{},
+}
+
+// rateLimitPruneInterval is how often the buckets which have refilled are removed
+const rateLimitPruneInterval = time.Minute
+
+// tokenBucket holds the tokens of a client at the time it was last updated
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// rateLimiter is a token bucket rate limiter keyed by client.
+// Each client can make burst requests at once, and its bucket refills at rate tokens per second.
+type rateLimiter struct {
+	sync.Mutex
+	rate      float64
+	burst     int
+	buckets   map[string]*tokenBucket
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+// newRateLimiter creates a rateLimiter. Returns nil if rate is not positive, which disables rate limiting.
+// If burst is not positive, clients can make one request at once.
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+
+	if burst <= 0 {
+		burst = 1
+	}
+
+	return &rateLimiter{
+		rate:    rate,
+		burst:   burst,
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// refill adds the tokens accumulated since the bucket was last updated, up to burst
+func (l *rateLimiter) refill(b *tokenBucket, now time.Time) {
+	b.tokens = math.Min(float64(l.burst), b.tokens+now.Sub(b.updated).Seconds()*l.rate)
+	b.updated = now
+}
+
+// allow takes a token from the client's bucket. If the bucket is empty, returns false
+// and how long the client must wait for a token.
+func (l *rateLimiter) allow(client string) (bool, time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastPrune) >= rateLimitPruneInterval {
+		l.prune(now)
+	}
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &tokenBucket{
+			tokens:  float64(l.burst),
+			updated: now,
+		}
+		l.buckets[client] = b
+	}
+
+	l.refill(b, now)
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+
+	b.tokens--
+	return true, 0
+}
+
+// prune removes the buckets which have refilled, since they are the same as new buckets.
+// Must be called with the lock held.
+func (l *rateLimiter) prune(now time.Time) {
+	for k, b := range l.buckets {
+		l.refill(b, now)
+		if b.tokens >= float64(l.burst) {
+			delete(l.buckets, k)
+		}
+	}
+	l.lastPrune = now
+}
+
+// rateLimitClient returns the client a request is limited as.
+// Requests with a valid API key are limited per API key, other requests per IP address.
+func rateLimitClient(r *http.Request, keys apiKeys) string {
+	if key := r.Header.Get(APIKeyHeaderName); key != "" {
+		if _, ok := keys.apiSets(key); ok {
+			return "key:" + cipher.SumSHA256([]byte(key)).Hex()
+		}
+	}
+
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	return "ip:" + ip
+}
+
+// rateLimit responds with 429 Too Many Requests and a Retry-After header if any of the limiters does not allow the request.
+// nil limiters are ignored.
+func rateLimit(apiVersion string, keys apiKeys, limiters []*rateLimiter, handler http.Handler) http.Handler {
+	var active []*rateLimiter
+	for _, l := range limiters {
+		if l != nil {
+			active = append(active, l)
+		}
+	}
+
+	if len(active) == 0 {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := rateLimitClient(r, keys)
+
+		for _, l := range active {
+			if ok, wait := l.allow(client); !ok {
+				w.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(wait.Seconds())), 10))
+				writeError(w, apiVersion, http.StatusTooManyRequests, "Rate limit exceeded")
+				return
+			}
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	require.Nil(t, newRateLimiter(0, 10))
+
+	now := time.Unix(1600000000, 0)
+	l := newRateLimiter(2, 3)
+	l.now = func() time.Time {
+		return now
+	}
+
+	// The burst is allowed at once
+	for i := 0; i < 3; i++ {
+		ok, wait := l.allow("a")
+		require.True(t, ok)
+		require.Equal(t, time.Duration(0), wait)
+	}
+
+	ok, wait := l.allow("a")
+	require.False(t, ok)
+	require.Equal(t, 500*time.Millisecond, wait)
+
+	// Clients are limited independently
+	ok, _ = l.allow("b")
+	require.True(t, ok)
+
+	// The bucket refills at the rate
+	now = now.Add(500 * time.Millisecond)
+	ok, _ = l.allow("a")
+	require.True(t, ok)
+	ok, _ = l.allow("a")
+	require.False(t, ok)
+
+	// The bucket does not refill beyond the burst
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		ok, _ = l.allow("a")
+		require.True(t, ok)
+	}
+	ok, _ = l.allow("a")
+	require.False(t, ok)
+
+	// Refilled buckets are pruned
+	now = now.Add(rateLimitPruneInterval)
+	l.allow("c")
+	require.Len(t, l.buckets, 1)
+}
+
+func TestRateLimit(t *testing.T) {
+	keys := newAPIKeys([]APIKey{
+		{Key: "readkey", Scopes: []string{APIKeyScopeRead}},
+	})
+
+	limiter := newRateLimiter(0.001, 2)
+	expensiveLimiter := newRateLimiter(0.001, 1)
+
+	handler := rateLimit(apiVersion1, keys, []*rateLimiter{limiter, expensiveLimiter, nil}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	request := func(remoteAddr, apiKey string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodGet, "/api/v1/richlist", nil)
+		require.NoError(t, err)
+		req.RemoteAddr = remoteAddr
+		if apiKey != "" {
+			req.Header.Set(APIKeyHeaderName, apiKey)
+		}
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := request("1.2.3.4:5000", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	// The expensive limiter allows one request at once
+	rr = request("1.2.3.4:5001", "")
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	require.Equal(t, "429 Too Many Requests - Rate limit exceeded\n", rr.Body.String())
+	require.Equal(t, "1000", rr.Header().Get("Retry-After"))
+
+	// Requests with a valid API key are limited per API key
+	rr = request("1.2.3.4:5000", "readkey")
+	require.Equal(t, http.StatusOK, rr.Code)
+	rr = request("5.6.7.8:5000", "readkey")
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+
+	// An invalid API key does not evade the limit of the IP address
+	rr = request("1.2.3.4:5000", "badkey")
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+
+	rr = request("5.6.7.8:5000", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestRateLimitServerMux(t *testing.T) {
+	cfg := defaultMuxConfig()
+	cfg.rateLimit = 0.001
+	cfg.rateLimitBurst = 2
+
+	handler := newServerMux(cfg, &MockGatewayer{})
+
+	for i, status := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		req, err := http.NewRequest(http.MethodGet, "/api/v1/version", nil)
+		require.NoError(t, err)
+		req.RemoteAddr = "1.2.3.4:5000"
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		require.Equal(t, status, rr.Code, "request %d", i)
+	}
+
+	// The v2 API responds with a JSON error
+	req, err := http.NewRequest(http.MethodPost, "/api/v2/address/verify", nil)
+	require.NoError(t, err)
+	req.RemoteAddr = "1.2.3.4:5000"
+	req.Header.Set("Content-Type", ContentTypeJSON)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	require.Equal(t, "{\n    \"error\": {\n        \"message\": \"Rate limit exceeded\",\n        \"code\": 429\n    }\n}", rr.Body.String())
+}
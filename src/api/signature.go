@@ -0,0 +1,138 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+)
+
+const (
+	// ResponseSignatureHeader is the header of the signature of a signed response
+	ResponseSignatureHeader = "X-Node-Signature"
+	// ResponsePubKeyHeader is the header of the public key of the signature of a signed response
+	ResponsePubKeyHeader = "X-Node-Pubkey"
+)
+
+// signedEndpoints are the endpoints of the chain tip and of balances, whose responses are signed
+// if the server has a signing key
+var signedEndpoints = map[string]struct{}{
+	"/api/v1/blockchain/metadata": struct{}{},
+	"/api/v1/last_blocks":         struct{}{},
+	"/api/v1/balance":             struct{}{},
+	"/api/v1/balances":            struct{}{},
+}
+
+// ErrResponseNotSigned is returned by VerifyResponseSignature if the response has no signature
+var ErrResponseNotSigned = errors.New("Response is not signed")
+
+// CanonicalJSON returns the canonical form of a JSON document: without insignificant whitespace,
+// with the keys of the objects sorted, and with the numbers and strings as they appear in the document.
+// The canonical form is the same whatever the indentation and the order of the keys of the document.
+func CanonicalJSON(data []byte) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	e := json.NewEncoder(&b)
+	e.SetEscapeHTML(false)
+	if err := e.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
+
+// SignResponse returns the signature of the SHA256 hash of the canonical JSON of a response body
+func SignResponse(body []byte, seckey cipher.SecKey) (cipher.Sig, error) {
+	canonical, err := CanonicalJSON(body)
+	if err != nil {
+		return cipher.Sig{}, err
+	}
+
+	return cipher.SignHash(cipher.SumSHA256(canonical), seckey)
+}
+
+// VerifyResponseSignature verifies that the signature of a response was made by pubkey over the canonical JSON of its body.
+// The response body must be the uncompressed body.
+func VerifyResponseSignature(header http.Header, body []byte, pubkey cipher.PubKey) error {
+	sigHex := header.Get(ResponseSignatureHeader)
+	if sigHex == "" {
+		return ErrResponseNotSigned
+	}
+
+	sig, err := cipher.SigFromHex(sigHex)
+	if err != nil {
+		return err
+	}
+
+	canonical, err := CanonicalJSON(body)
+	if err != nil {
+		return err
+	}
+
+	return cipher.VerifyPubKeySignedHash(pubkey, sig, cipher.SumSHA256(canonical))
+}
+
+// bufferedResponseWriter holds the status and the body of a response until they are written
+type bufferedResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *bufferedResponseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(p)
+}
+
+// signResponse signs the successful JSON responses of a handler with seckey.
+// The signature is over the SHA256 hash of the canonical JSON of the response body, see CanonicalJSON,
+// so that it can be verified after the body is reformatted. It is set in the ResponseSignatureHeader header,
+// and the public key in the ResponsePubKeyHeader header.
+func signResponse(seckey cipher.SecKey, handler http.Handler) http.Handler {
+	pubkey := cipher.MustPubKeyFromSecKey(seckey).Hex()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bw := &bufferedResponseWriter{
+			ResponseWriter: w,
+		}
+
+		handler.ServeHTTP(bw, r)
+
+		if bw.status == 0 {
+			bw.status = http.StatusOK
+		}
+
+		if bw.status == http.StatusOK && strings.HasPrefix(w.Header().Get("Content-Type"), ContentTypeJSON) {
+			sig, err := SignResponse(bw.body.Bytes(), seckey)
+			if err != nil {
+				logger.WithError(err).Error("Signing the response failed")
+			} else {
+				w.Header().Set(ResponseSignatureHeader, sig.Hex())
+				w.Header().Set(ResponsePubKeyHeader, pubkey)
+			}
+		}
+
+		w.WriteHeader(bw.status)
+		if _, err := w.Write(bw.body.Bytes()); err != nil {
+			logger.WithError(err).Error("http Write failed")
+		}
+	})
+}
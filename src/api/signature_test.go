@@ -0,0 +1,134 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/visor"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	tt := []struct {
+		name      string
+		data      string
+		canonical string
+		err       bool
+	}{
+		{
+			name:      "sorted keys without whitespace",
+			data:      "{\n    \"b\": [1, 2.50, {\"d\": null, \"c\": true}],\n    \"a\": \"<x & y>\"\n}\n",
+			canonical: `{"a":"<x & y>","b":[1,2.50,{"c":true,"d":null}]}`,
+		},
+		{
+			name:      "large integers are preserved",
+			data:      `{"coins": 18446744073709551615}`,
+			canonical: `{"coins":18446744073709551615}`,
+		},
+		{
+			name: "invalid json",
+			data: `{"a":`,
+			err:  true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			canonical, err := CanonicalJSON([]byte(tc.data))
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.canonical, string(canonical))
+		})
+	}
+}
+
+func TestSignedResponses(t *testing.T) {
+	pubkey, seckey := cipher.GenerateKeyPair()
+	metadata := &visor.BlockchainMetadata{
+		HeadBlock: coin.SignedBlock{},
+		Unspents:  12,
+	}
+
+	tt := []struct {
+		name        string
+		signingKey  cipher.SecKey
+		metadataErr error
+		status      int
+		signed      bool
+	}{
+		{
+			name:   "no signing key",
+			status: http.StatusOK,
+		},
+		{
+			name:       "signed",
+			signingKey: seckey,
+			status:     http.StatusOK,
+			signed:     true,
+		},
+		{
+			name:        "errors are not signed",
+			signingKey:  seckey,
+			metadataErr: errors.New("GetBlockchainMetadata error"),
+			status:      http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			snapshot := &MockSnapshotter{}
+			if tc.metadataErr != nil {
+				snapshot.On("GetBlockchainMetadata").Return(nil, tc.metadataErr)
+			} else {
+				snapshot.On("GetBlockchainMetadata").Return(metadata, nil)
+			}
+			snapshot.On("Release").Return(nil)
+
+			gateway := &MockGatewayer{}
+			gateway.On("Snapshot").Return(snapshot, nil)
+
+			req, err := http.NewRequest(http.MethodGet, "/api/v1/blockchain/metadata", nil)
+			require.NoError(t, err)
+
+			cfg := defaultMuxConfig()
+			cfg.signingKey = tc.signingKey
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(cfg, gateway)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code)
+
+			err = VerifyResponseSignature(rr.Header(), rr.Body.Bytes(), pubkey)
+			if !tc.signed {
+				require.Equal(t, ErrResponseNotSigned, err)
+				require.Empty(t, rr.Header().Get(ResponsePubKeyHeader))
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, pubkey.Hex(), rr.Header().Get(ResponsePubKeyHeader))
+
+			// The signature is over the canonical JSON, so it is valid for the reformatted body
+			canonical, err := CanonicalJSON(rr.Body.Bytes())
+			require.NoError(t, err)
+			require.NoError(t, VerifyResponseSignature(rr.Header(), canonical, pubkey))
+
+			// A modified body does not match the signature
+			otherPubkey, _ := cipher.GenerateKeyPair()
+			require.Error(t, VerifyResponseSignature(rr.Header(), canonical, otherPubkey))
+
+			tampered, err := CanonicalJSON([]byte(`{"unspents": 13}`))
+			require.NoError(t, err)
+			require.Error(t, VerifyResponseSignature(rr.Header(), tampered, pubkey))
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/SkycoinProject/cx-chains/src/visor"
 	"github.com/SkycoinProject/cx-chains/src/visor/blockdb"
 	"github.com/SkycoinProject/cx-chains/src/wallet"
+	"github.com/SkycoinProject/cx-chains/src/wallet/hardware"
 )
 
 // CreateTransactionResponse is returned by /wallet/transaction
@@ -46,6 +47,36 @@ func NewCreateTransactionResponse(txn *coin.Transaction, inputs []visor.Transact
 	}, nil
 }
 
+// TransactionAndInputs decodes the encoded transaction and its inputs.
+// This allows a CreateTransactionResponse to be used as a portable envelope for signing a transaction offline.
+// The inputs are verified against the transaction, since the signer cannot look them up.
+func (r *CreateTransactionResponse) TransactionAndInputs() (*coin.Transaction, []visor.TransactionInput, error) {
+	txn, err := coin.DeserializeTransactionHex(r.EncodedTransaction)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid encoded transaction: %v", err)
+	}
+
+	if len(txn.In) != len(r.Transaction.In) {
+		return nil, nil, errors.New("number of inputs does not match the encoded transaction")
+	}
+
+	inputs := make([]visor.TransactionInput, len(r.Transaction.In))
+	for i, in := range r.Transaction.In {
+		ti, err := in.ToTransactionInput()
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid input %d: %v", i, err)
+		}
+
+		if ti.UxOut.Hash() != txn.In[i] {
+			return nil, nil, fmt.Errorf("input %d does not match the encoded transaction", i)
+		}
+
+		inputs[i] = *ti
+	}
+
+	return &txn, inputs, nil
+}
+
 // CreatedTransaction represents a transaction created by /wallet/transaction
 type CreatedTransaction struct {
 	Length    uint32 `json:"length"`
@@ -232,6 +263,7 @@ type CreatedTransactionInput struct {
 	Time            uint64 `json:"timestamp,omitempty"`
 	Block           uint64 `json:"block,omitempty"`
 	TxID            string `json:"txid,omitempty"`
+	ProgramState    []byte `json:"prgrmState,omitempty"`
 }
 
 // NewCreatedTransactionInput creates CreatedTransactionInput
@@ -259,6 +291,63 @@ func NewCreatedTransactionInput(out visor.TransactionInput) (*CreatedTransaction
 		Time:            out.UxOut.Head.Time,
 		Block:           out.UxOut.Head.BkSeq,
 		TxID:            txID,
+		ProgramState:    out.UxOut.Body.ProgramState,
+	}, nil
+}
+
+// ToTransactionInput converts a CreatedTransactionInput back to a visor.TransactionInput
+func (r *CreatedTransactionInput) ToTransactionInput() (*visor.TransactionInput, error) {
+	addr, err := cipher.DecodeBase58Address(r.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	coins, err := droplet.FromString(r.Coins)
+	if err != nil {
+		return nil, err
+	}
+
+	hours, err := strconv.ParseUint(r.Hours, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	calculatedHours, err := strconv.ParseUint(r.CalculatedHours, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	txID, err := cipher.SHA256FromHex(r.TxID)
+	if err != nil {
+		return nil, err
+	}
+
+	ux := coin.UxOut{
+		Head: coin.UxHead{
+			Time:  r.Time,
+			BkSeq: r.Block,
+		},
+		Body: coin.UxBody{
+			SrcTransaction: txID,
+			Address:        addr,
+			Coins:          coins,
+			Hours:          hours,
+			ProgramState:   r.ProgramState,
+		},
+	}
+
+	uxID, err := cipher.SHA256FromHex(r.UxID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ux.Hash() != uxID {
+		return nil, errors.New("uxid does not match the input")
+	}
+
+	return &visor.TransactionInput{
+		UxOut:           ux,
+		CalculatedHours: calculatedHours,
 	}, nil
 }
 
@@ -271,6 +360,12 @@ type createTransactionRequest struct {
 	MainExpressions   []byte         `json:"mainExprs"`
 	UxOuts            []wh.SHA256    `json:"unspents,omitempty"`
 	Addresses         []wh.Address   `json:"addresses,omitempty"`
+	CoinSelection     string         `json:"coin_selection,omitempty"`
+	MaxInputs         uint64         `json:"max_inputs,omitempty"`
+	Replace           *wh.SHA256     `json:"replace,omitempty"`
+	ExtraFee          wh.Hours       `json:"extra_fee,omitempty"`
+	FeePerKB          *wh.Hours      `json:"fee_per_kb,omitempty"`
+	BurnChangeHours   bool           `json:"burn_change_hours,omitempty"`
 }
 
 // hoursSelection defines options for hours distribution
@@ -344,10 +439,30 @@ func (r createTransactionRequest) Validate() error {
 		}
 	}
 
+	switch r.CoinSelection {
+	case "",
+		transaction.CoinSelectionMinimizeInputs,
+		transaction.CoinSelectionMinimizeChange,
+		transaction.CoinSelectionOldestFirst,
+		transaction.CoinSelectionRandom:
+	default:
+		return errors.New("invalid coin_selection")
+	}
+
 	if len(r.UxOuts) != 0 && len(r.Addresses) != 0 {
 		return errors.New("unspents and addresses cannot be combined")
 	}
 
+	if r.Replace != nil {
+		if r.Replace.Null() {
+			return errors.New("replace must not be the null hash")
+		}
+
+		if len(r.UxOuts) != 0 || len(r.Addresses) != 0 {
+			return errors.New("replace cannot be combined with unspents or addresses")
+		}
+	}
+
 	addressMap := make(map[cipher.Address]struct{}, len(r.Addresses))
 	for i, a := range r.Addresses {
 		if a.Null() {
@@ -394,25 +509,34 @@ func (r createTransactionRequest) Validate() error {
 	// Auto mode would distribute hours to the outputs and could hypothetically
 	// avoid assigning duplicate hours in many cases, but the complexity for doing
 	// so is very high, so also reject duplicate (address, coins) for auto mode.
-	// outputs := make(map[coin.TransactionOutput]struct{}, len(r.To))
-	// for _, to := range r.To {
-	// 	var hours uint64
-	// 	if to.Hours != nil {
-	// 		hours = to.Hours.Value()
-	// 	}
-
-	// 	txo := coin.TransactionOutput{
-	// 		Address: to.Address.Address,
-	// 		Coins:   to.Coins.Value(),
-	// 		Hours:   hours,
-	// 	}
-
-	// 	if _, ok := outputs[txo]; ok {
-	// 		return errors.New("to contains duplicate values")
-	// 	}
-
-	// 	outputs[txo] = struct{}{}
-	// }
+	// coin.TransactionOutput is not comparable because of its ProgramState,
+	// so the program state is compared as a string, as transaction.Params does.
+	type outputKey struct {
+		address      cipher.Address
+		coins        uint64
+		hours        uint64
+		programState string
+	}
+	outputs := make(map[outputKey]struct{}, len(r.To))
+	for _, to := range r.To {
+		var hours uint64
+		if to.Hours != nil {
+			hours = to.Hours.Value()
+		}
+
+		txo := outputKey{
+			address:      to.Address.Address,
+			coins:        to.Coins.Value(),
+			hours:        hours,
+			programState: string(to.ProgramState),
+		}
+
+		if _, ok := outputs[txo]; ok {
+			return errors.New("to contains duplicate values")
+		}
+
+		outputs[txo] = struct{}{}
+	}
 
 	return nil
 }
@@ -447,6 +571,10 @@ func (r createTransactionRequest) TransactionParams() transaction.Params {
 		},
 		ChangeAddress: changeAddress,
 		To:            to,
+		CoinSelection: r.CoinSelection,
+		MaxInputs:     r.MaxInputs,
+		ExtraFee:      r.ExtraFee.Value(),
+		BurnChangeHours: r.BurnChangeHours,
 		MainExpressions: r.MainExpressions,
 	}
 }
@@ -456,7 +584,24 @@ func (r createTransactionRequest) VisorParams() visor.CreateTransactionParams {
 		IgnoreUnconfirmed: r.IgnoreUnconfirmed,
 		Addresses:         r.addresses(),
 		UxOuts:            r.uxOuts(),
+		Replace:           r.replace(),
+		FeePerKB:          r.feePerKB(),
+	}
+}
+
+func (r createTransactionRequest) feePerKB() *uint64 {
+	if r.FeePerKB == nil {
+		return nil
 	}
+	v := r.FeePerKB.Value()
+	return &v
+}
+
+func (r createTransactionRequest) replace() cipher.SHA256 {
+	if r.Replace == nil {
+		return cipher.SHA256{}
+	}
+	return r.Replace.SHA256
 }
 
 func (r createTransactionRequest) addresses() []cipher.Address {
@@ -506,10 +651,10 @@ func transactionHandlerV2(gateway Gatewayer) http.HandlerFunc {
 			return
 		}
 
-		// Check that addresses or unspents are not empty
+		// Check that addresses or unspents are not empty, unless a pending transaction is replaced
 		// This is not checked in Validate() because POST /api/v1/wallet/transaction
 		// allows both to be empty
-		if len(req.Addresses) == 0 && len(req.UxOuts) == 0 {
+		if len(req.Addresses) == 0 && len(req.UxOuts) == 0 && req.Replace == nil {
 			resp := NewHTTPErrorResponse(http.StatusBadRequest, "one of addresses or unspents must not be empty")
 			writeHTTPResponse(w, resp)
 			return
@@ -549,6 +694,7 @@ func transactionHandlerV2(gateway Gatewayer) http.HandlerFunc {
 // walletCreateTransactionRequest is sent to POST /api/v1/wallet/transaction
 type walletCreateTransactionRequest struct {
 	Unsigned bool   `json:"unsigned"`
+	HW       bool   `json:"hw"`
 	WalletID string `json:"wallet_id"`
 	Password string `json:"password"`
 	createTransactionRequest
@@ -564,6 +710,14 @@ func (r walletCreateTransactionRequest) Validate() error {
 		return errors.New("password must not be used for unsigned transactions")
 	}
 
+	if r.HW && r.Unsigned {
+		return errors.New("hw and unsigned cannot be combined")
+	}
+
+	if r.HW && len(r.Password) != 0 {
+		return errors.New("password must not be used for hardware wallet transactions")
+	}
+
 	return r.createTransactionRequest.Validate()
 }
 
@@ -599,12 +753,20 @@ func walletCreateTransactionHandler(gateway Gatewayer) http.HandlerFunc {
 
 		var txn *coin.Transaction
 		var inputs []visor.TransactionInput
-		if req.Unsigned {
+		switch {
+		case req.Unsigned:
 			txn, inputs, err = gateway.WalletCreateTransaction(req.WalletID, req.TransactionParams(), req.VisorParams())
-		} else {
+		case req.HW:
+			txn, inputs, err = gateway.WalletCreateTransactionHardware(req.WalletID, req.TransactionParams(), req.VisorParams())
+		default:
 			txn, inputs, err = gateway.WalletCreateTransactionSigned(req.WalletID, []byte(req.Password), req.TransactionParams(), req.VisorParams())
 		}
 		if err != nil {
+			if err == hardware.ErrNoDevice {
+				wh.Error503(w, err.Error())
+				return
+			}
+
 			switch err.(type) {
 			case wallet.Error:
 				switch err {
@@ -648,6 +810,7 @@ type WalletSignTransactionRequest struct {
 	Password           string `json:"password"`
 	EncodedTransaction string `json:"encoded_transaction"`
 	SignIndexes        []int  `json:"sign_indexes"`
+	HW                 bool   `json:"hw"`
 }
 
 // walletSignTransactionHandler signs an unsigned transaction
@@ -681,6 +844,18 @@ func walletSignTransactionHandler(gateway Gatewayer) http.HandlerFunc {
 			return
 		}
 
+		if req.HW && req.Password != "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "password must not be used for hardware wallet transactions")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.HW && len(req.SignIndexes) != 0 {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "sign_indexes cannot be used with hw, the hardware wallet signs all inputs")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
 		txn, err := decodeTxn(req.EncodedTransaction)
 		if err != nil {
 			resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("Decode transaction failed: %v", err))
@@ -688,6 +863,20 @@ func walletSignTransactionHandler(gateway Gatewayer) http.HandlerFunc {
 			return
 		}
 
+		if txn.IsMultisig() {
+			if req.HW {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, "hardware wallets cannot sign multisig transactions")
+				writeHTTPResponse(w, resp)
+				return
+			}
+
+			if len(req.SignIndexes) != 0 {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, "sign_indexes cannot be used with multisig transactions")
+				writeHTTPResponse(w, resp)
+				return
+			}
+		}
+
 		// Check that number of sign_indexes does not exceed number of inputs
 		if len(req.SignIndexes) > len(txn.In) {
 			resp := NewHTTPErrorResponse(http.StatusBadRequest, "Too many values in sign_indexes")
@@ -715,8 +904,19 @@ func walletSignTransactionHandler(gateway Gatewayer) http.HandlerFunc {
 			signIndexesMap[i] = struct{}{}
 		}
 
-		signedTxn, inputs, err := gateway.WalletSignTransaction(req.WalletID, []byte(req.Password), txn, req.SignIndexes)
+		var signedTxn *coin.Transaction
+		var inputs []visor.TransactionInput
+		if req.HW {
+			signedTxn, inputs, err = gateway.WalletSignTransactionHardware(req.WalletID, txn)
+		} else {
+			signedTxn, inputs, err = gateway.WalletSignTransaction(req.WalletID, []byte(req.Password), txn, req.SignIndexes)
+		}
 		if err != nil {
+			if err == hardware.ErrNoDevice {
+				writeHTTPResponse(w, NewHTTPErrorResponse(http.StatusServiceUnavailable, err.Error()))
+				return
+			}
+
 			var resp HTTPResponse
 			switch err.(type) {
 			case wallet.Error:
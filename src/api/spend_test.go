@@ -21,6 +21,7 @@ import (
 	"github.com/SkycoinProject/cx-chains/src/visor"
 	"github.com/SkycoinProject/cx-chains/src/visor/blockdb"
 	"github.com/SkycoinProject/cx-chains/src/wallet"
+	"github.com/SkycoinProject/cx-chains/src/wallet/hardware"
 )
 
 type rawHoursSelection struct {
@@ -30,18 +31,23 @@ type rawHoursSelection struct {
 }
 
 type rawReceiver struct {
-	Address string `json:"address"`
-	Coins   string `json:"coins"`
-	Hours   string `json:"hours,omitempty"`
+	Address      string `json:"address"`
+	Coins        string `json:"coins"`
+	Hours        string `json:"hours,omitempty"`
+	ProgramState []byte `json:"prgrmState,omitempty"`
 }
 
 type rawCreateTxnRequest struct {
-	UxOuts         []string          `json:"unspents,omitempty"`
-	Addresses      []string          `json:"addresses,omitempty"`
-	HoursSelection rawHoursSelection `json:"hours_selection"`
-	ChangeAddress  string            `json:"change_address,omitempty"`
-	To             []rawReceiver     `json:"to"`
-	Password       string            `json:"password"`
+	UxOuts          []string          `json:"unspents,omitempty"`
+	Addresses       []string          `json:"addresses,omitempty"`
+	HoursSelection  rawHoursSelection `json:"hours_selection"`
+	ChangeAddress   string            `json:"change_address,omitempty"`
+	To              []rawReceiver     `json:"to"`
+	Password        string            `json:"password"`
+	CoinSelection   string            `json:"coin_selection,omitempty"`
+	Replace         string            `json:"replace,omitempty"`
+	ExtraFee        string            `json:"extra_fee,omitempty"`
+	BurnChangeHours bool              `json:"burn_change_hours,omitempty"`
 }
 
 func TestCreateTransaction(t *testing.T) {
@@ -534,6 +540,33 @@ func TestCreateTransaction(t *testing.T) {
 			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "to contains duplicate values"),
 		},
 
+		{
+			name:   "400 - manual duplicate outputs with the same program state",
+			method: http.MethodPost,
+			body: &rawCreateTxnRequest{
+				HoursSelection: rawHoursSelection{
+					Type: transaction.HoursSelectionTypeManual,
+				},
+				ChangeAddress: changeAddress.String(),
+				To: []rawReceiver{
+					{
+						Address:      destinationAddress.String(),
+						Coins:        "1.2",
+						Hours:        "100",
+						ProgramState: []byte{1, 2, 3},
+					},
+					{
+						Address:      destinationAddress.String(),
+						Coins:        "1.2",
+						Hours:        "100",
+						ProgramState: []byte{1, 2, 3},
+					},
+				},
+			},
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "to contains duplicate values"),
+		},
+
 		{
 			name:   "400 - auto duplicate outputs",
 			method: http.MethodPost,
@@ -582,6 +615,74 @@ func TestCreateTransaction(t *testing.T) {
 			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "unspents and addresses cannot be combined"),
 		},
 
+		{
+			name:   "400 - both replace and uxouts specified",
+			method: http.MethodPost,
+			body: &rawCreateTxnRequest{
+				HoursSelection: rawHoursSelection{
+					Type:        transaction.HoursSelectionTypeAuto,
+					Mode:        transaction.HoursSelectionModeShare,
+					ShareFactor: newStrPtr("0.5"),
+				},
+				ChangeAddress: changeAddress.String(),
+				To: []rawReceiver{
+					{
+						Address: destinationAddress.String(),
+						Coins:   "1.2",
+					},
+				},
+				UxOuts:  []string{walletInput.Hex()},
+				Replace: testutil.RandSHA256(t).Hex(),
+			},
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "replace cannot be combined with unspents or addresses"),
+		},
+
+		{
+			name:   "400 - replace null hash",
+			method: http.MethodPost,
+			body: &rawCreateTxnRequest{
+				HoursSelection: rawHoursSelection{
+					Type:        transaction.HoursSelectionTypeAuto,
+					Mode:        transaction.HoursSelectionModeShare,
+					ShareFactor: newStrPtr("0.5"),
+				},
+				ChangeAddress: changeAddress.String(),
+				To: []rawReceiver{
+					{
+						Address: destinationAddress.String(),
+						Coins:   "1.2",
+					},
+				},
+				Replace: cipher.SHA256{}.Hex(),
+			},
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "replace must not be the null hash"),
+		},
+
+		{
+			name:   "400 - invalid coin selection",
+			method: http.MethodPost,
+			body: &rawCreateTxnRequest{
+				HoursSelection: rawHoursSelection{
+					Type:        transaction.HoursSelectionTypeAuto,
+					Mode:        transaction.HoursSelectionModeShare,
+					ShareFactor: newStrPtr("0.5"),
+				},
+				ChangeAddress: changeAddress.String(),
+				To: []rawReceiver{
+					{
+						Address: destinationAddress.String(),
+						Coins:   "1.2",
+					},
+				},
+				Addresses:     []string{destinationAddress.String()},
+				CoinSelection: "largest_first",
+			},
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid coin_selection"),
+		},
+
 		{
 			name:   "400 - missing uxouts and addresses",
 			method: http.MethodPost,
@@ -723,6 +824,63 @@ func TestCreateTransaction(t *testing.T) {
 			},
 		},
 
+		{
+			name:   "200 - replace with extra fee",
+			method: http.MethodPost,
+			body: &rawCreateTxnRequest{
+				HoursSelection: rawHoursSelection{
+					Type: transaction.HoursSelectionTypeManual,
+				},
+				To: []rawReceiver{
+					{
+						Address: destinationAddress.String(),
+						Coins:   "100",
+						Hours:   "10",
+					},
+				},
+				ChangeAddress: changeAddress.String(),
+				Replace:       txn.Hash().Hex(),
+				ExtraFee:      "5",
+			},
+			status:                         http.StatusOK,
+			gatewayCreateTransactionResult: txn,
+			gatewayCreateTransactionInputs: inputs,
+			httpResponse: HTTPResponse{
+				Data: createTxnResponse,
+			},
+		},
+
+		{
+			name:   "200 - unspents with multiple outputs and burned change hours",
+			method: http.MethodPost,
+			body: &rawCreateTxnRequest{
+				HoursSelection: rawHoursSelection{
+					Type: transaction.HoursSelectionTypeManual,
+				},
+				UxOuts: []string{testutil.RandSHA256(t).Hex()},
+				To: []rawReceiver{
+					{
+						Address: destinationAddress.String(),
+						Coins:   "100",
+						Hours:   "10",
+					},
+					{
+						Address: destinationAddress.String(),
+						Coins:   "5",
+						Hours:   "0",
+					},
+				},
+				ChangeAddress:   changeAddress.String(),
+				BurnChangeHours: true,
+			},
+			status:                         http.StatusOK,
+			gatewayCreateTransactionResult: txn,
+			gatewayCreateTransactionInputs: inputs,
+			httpResponse: HTTPResponse{
+				Data: createTxnResponse,
+			},
+		},
+
 		{
 			name:                           "200 - manual type nonzero hours - csrf disabled",
 			method:                         http.MethodPost,
@@ -875,6 +1033,7 @@ func TestWalletCreateTransaction(t *testing.T) {
 		WalletID string `json:"wallet_id"`
 		Password string `json:"password"`
 		Unsigned bool   `json:"unsigned"`
+		HW       bool   `json:"hw"`
 	}
 
 	changeAddress := testutil.MakeAddress()
@@ -1516,6 +1675,31 @@ func TestWalletCreateTransaction(t *testing.T) {
 			err:    "400 Bad Request - unspents and addresses cannot be combined",
 		},
 
+		{
+			name:   "400 - invalid coin selection",
+			method: http.MethodPost,
+			body: rawWalletCreateTxnRequest{
+				rawCreateTxnRequest: rawCreateTxnRequest{
+					HoursSelection: rawHoursSelection{
+						Type:        transaction.HoursSelectionTypeAuto,
+						Mode:        transaction.HoursSelectionModeShare,
+						ShareFactor: newStrPtr("0.5"),
+					},
+					ChangeAddress: changeAddress.String(),
+					To: []rawReceiver{
+						{
+							Address: destinationAddress.String(),
+							Coins:   "1.2",
+						},
+					},
+					CoinSelection: "largest_first",
+				},
+				WalletID: "foo.wlt",
+			},
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - invalid coin_selection",
+		},
+
 		{
 			name:   "400 - duplicate uxouts",
 			method: http.MethodPost,
@@ -1758,6 +1942,53 @@ func TestWalletCreateTransaction(t *testing.T) {
 		err:    "400 Bad Request - password must not be used for unsigned transactions",
 	})
 
+	cases = append(cases, testCase{
+		name:   "400 - hw combined with unsigned",
+		method: http.MethodPost,
+		body: rawWalletCreateTxnRequest{
+			rawCreateTxnRequest: validBody.rawCreateTxnRequest,
+			WalletID:            "foo.wlt",
+			Unsigned:            true,
+			HW:                  true,
+		},
+		status: http.StatusBadRequest,
+		err:    "400 Bad Request - hw and unsigned cannot be combined",
+	}, testCase{
+		name:   "400 - password provided for hw request",
+		method: http.MethodPost,
+		body: rawWalletCreateTxnRequest{
+			rawCreateTxnRequest: validBody.rawCreateTxnRequest,
+			WalletID:            "foo.wlt",
+			Password:            "foo",
+			HW:                  true,
+		},
+		status: http.StatusBadRequest,
+		err:    "400 Bad Request - password must not be used for hardware wallet transactions",
+	}, testCase{
+		name:   "503 - hw no device",
+		method: http.MethodPost,
+		body: rawWalletCreateTxnRequest{
+			rawCreateTxnRequest: validBody.rawCreateTxnRequest,
+			WalletID:            "foo.wlt",
+			HW:                  true,
+		},
+		status:                      http.StatusServiceUnavailable,
+		gatewayCreateTransactionErr: hardware.ErrNoDevice,
+		err:                         "503 Service Unavailable - hardware wallet device is not connected",
+	}, testCase{
+		name:   "200 - hw",
+		method: http.MethodPost,
+		body: rawWalletCreateTxnRequest{
+			rawCreateTxnRequest: validBody.rawCreateTxnRequest,
+			WalletID:            "foo.wlt",
+			HW:                  true,
+		},
+		status:                         http.StatusOK,
+		gatewayCreateTransactionResult: txn,
+		gatewayCreateTransactionInputs: inputs,
+		createTransactionResponse:      createTxnResponse,
+	})
+
 	for _, tc := range cases {
 		name := fmt.Sprintf("unsigned=%v %s", tc.body.Unsigned, tc.name)
 		t.Run(name, func(t *testing.T) {
@@ -1772,6 +2003,9 @@ func TestWalletCreateTransaction(t *testing.T) {
 				if tc.body.Unsigned {
 					x := gateway.On("WalletCreateTransaction", body.WalletID, body.TransactionParams(), body.VisorParams())
 					x.Return(tc.gatewayCreateTransactionResult, tc.gatewayCreateTransactionInputs, tc.gatewayCreateTransactionErr)
+				} else if tc.body.HW {
+					x := gateway.On("WalletCreateTransactionHardware", body.WalletID, body.TransactionParams(), body.VisorParams())
+					x.Return(tc.gatewayCreateTransactionResult, tc.gatewayCreateTransactionInputs, tc.gatewayCreateTransactionErr)
 				} else {
 					x := gateway.On("WalletCreateTransactionSigned", body.WalletID, []byte(body.Password), body.TransactionParams(), body.VisorParams())
 					x.Return(tc.gatewayCreateTransactionResult, tc.gatewayCreateTransactionInputs, tc.gatewayCreateTransactionErr)
@@ -1988,6 +2222,58 @@ func TestWalletSignTransaction(t *testing.T) {
 			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "Duplicate value in sign_indexes"),
 		},
 
+		{
+			name:   "400 hw with password",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			body: &WalletSignTransactionRequest{
+				WalletID:           "foo.wlt",
+				Password:           "foo",
+				EncodedTransaction: validBody.EncodedTransaction,
+				HW:                 true,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "password must not be used for hardware wallet transactions"),
+		},
+
+		{
+			name:   "400 hw with sign indexes",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			body: &WalletSignTransactionRequest{
+				WalletID:           "foo.wlt",
+				EncodedTransaction: validBody.EncodedTransaction,
+				SignIndexes:        []int{1},
+				HW:                 true,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "sign_indexes cannot be used with hw, the hardware wallet signs all inputs"),
+		},
+
+		{
+			name:   "503 - hw no device",
+			method: http.MethodPost,
+			body: &WalletSignTransactionRequest{
+				WalletID:           "foo.wlt",
+				EncodedTransaction: validBody.EncodedTransaction,
+				HW:                 true,
+			},
+			status:                    http.StatusServiceUnavailable,
+			gatewaySignTransactionErr: hardware.ErrNoDevice,
+			httpResponse:              NewHTTPErrorResponse(http.StatusServiceUnavailable, "hardware wallet device is not connected"),
+		},
+
+		{
+			name:   "400 - hw address mismatch",
+			method: http.MethodPost,
+			body: &WalletSignTransactionRequest{
+				WalletID:           "foo.wlt",
+				EncodedTransaction: validBody.EncodedTransaction,
+				HW:                 true,
+			},
+			status:                    http.StatusBadRequest,
+			gatewaySignTransactionErr: hardware.ErrAddressMismatch,
+			httpResponse:              NewHTTPErrorResponse(http.StatusBadRequest, "hardware wallet device address does not match the wallet address"),
+		},
+
 		{
 			name:                      "500 - misc error",
 			method:                    http.MethodPost,
@@ -2115,6 +2401,22 @@ func TestWalletSignTransaction(t *testing.T) {
 				Data: *signedTxnResp,
 			},
 		},
+
+		{
+			name:   "200 - hw",
+			method: http.MethodPost,
+			body: &WalletSignTransactionRequest{
+				WalletID:           "foo.wlt",
+				EncodedTransaction: validBody.EncodedTransaction,
+				HW:                 true,
+			},
+			status:                       http.StatusOK,
+			gatewaySignTransactionResult: &signedTxn,
+			gatewaySignTransactionInputs: inputs,
+			httpResponse: HTTPResponse{
+				Data: *signedTxnResp,
+			},
+		},
 	}
 
 	for _, tc := range tt {
@@ -2133,6 +2435,7 @@ func TestWalletSignTransaction(t *testing.T) {
 
 			if tc.body != nil {
 				gateway.On("WalletSignTransaction", tc.body.WalletID, []byte(tc.body.Password), txn, tc.body.SignIndexes).Return(tc.gatewaySignTransactionResult, tc.gatewaySignTransactionInputs, tc.gatewaySignTransactionErr)
+				gateway.On("WalletSignTransactionHardware", tc.body.WalletID, txn).Return(tc.gatewaySignTransactionResult, tc.gatewaySignTransactionInputs, tc.gatewaySignTransactionErr)
 			}
 
 			endpoint := "/api/v2/wallet/transaction/sign"
@@ -370,6 +370,8 @@ func programStateHandler(gateway Gatewayer) http.HandlerFunc {
 // Response:
 //      200 - ok, returns the transaction hash in hex as string
 //      400 - bad transaction
+//      409 - the transaction spends the same inputs as unconfirmed transactions and its fee is too low to replace them,
+//            returns an HTTPResponse with an InjectTransactionConflict as data
 //		500 - other error
 //      503 - network unavailable for broadcasting transaction
 func injectTransactionHandler(gateway Gatewayer) http.HandlerFunc {
@@ -400,11 +402,20 @@ func injectTransactionHandler(gateway Gatewayer) http.HandlerFunc {
 		}
 
 		if err := gateway.InjectBroadcastTransaction(txn); err != nil {
-			switch err.(type) {
+			switch e := err.(type) {
 			case visor.ErrTxnViolatesUserConstraint,
 				visor.ErrTxnViolatesHardConstraint,
-				visor.ErrTxnViolatesSoftConstraint:
+				visor.ErrTxnViolatesSoftConstraint,
+				visor.ErrTxnViolatesPoolPolicy:
 				wh.Error400(w, err.Error())
+			case visor.ErrTxnConflict:
+				writeHTTPResponse(w, HTTPResponse{
+					Error: &HTTPError{
+						Code:    http.StatusConflict,
+						Message: err.Error(),
+					},
+					Data: NewInjectTransactionConflict(e.Txids),
+				})
 			default:
 				if daemon.IsBroadcastFailure(err) {
 					wh.Error503(w, err.Error())
@@ -419,6 +430,23 @@ func injectTransactionHandler(gateway Gatewayer) http.HandlerFunc {
 	}
 }
 
+// InjectTransactionConflict is the data of the 409 response of POST /api/v1/injectTransaction
+type InjectTransactionConflict struct {
+	// ConflictingTxids are the unconfirmed transactions spending the same inputs as the rejected transaction
+	ConflictingTxids []string `json:"conflicting_txids"`
+}
+
+// NewInjectTransactionConflict creates an InjectTransactionConflict from the IDs of the conflicting transactions
+func NewInjectTransactionConflict(hashes []cipher.SHA256) InjectTransactionConflict {
+	txids := make([]string, len(hashes))
+	for i, h := range hashes {
+		txids[i] = h.Hex()
+	}
+	return InjectTransactionConflict{
+		ConflictingTxids: txids,
+	}
+}
+
 // ResendResult the result of rebroadcasting transaction
 type ResendResult struct {
 	Txids []string `json:"txids"`
@@ -69,9 +69,9 @@ func makeTransaction(t *testing.T) coin.Transaction {
 
 	err := txn.PushInput(ux.Hash())
 	require.NoError(t, err)
-	err = txn.PushOutput(makeAddress(), 1e6, 50)
+	err = txn.PushOutput(makeAddress(), 1e6, 50, nil)
 	require.NoError(t, err)
-	err = txn.PushOutput(makeAddress(), 5e6, 50)
+	err = txn.PushOutput(makeAddress(), 5e6, 50, nil)
 	require.NoError(t, err)
 	txn.SignInputs([]cipher.SecKey{s})
 	err = txn.UpdateHeader()
@@ -428,13 +428,13 @@ func TestGetTransactionByID(t *testing.T) {
 					Height:    9,
 				},
 				Transaction: readable.Transaction{
-					Hash:      "b64525bc14edb3c838ff3ef4f01bd74712432b32c18463dbda59b431959b2e52",
+					Hash:      "9a82dbde1c327c385e471ce3bcf13d16c06bab12233a2ab7b941bec3433b3754",
 					InnerHash: "0000000000000000000000000000000000000000000000000000000000000000",
 					Sigs:      []string{validSig},
 					In:        []string{validHash},
 					Out: []readable.TransactionOutput{
 						{
-							Hash:    "87ec4d440fd64bb4c26839d58684e567e499265ca396649c03304b928378720b",
+							Hash:    "3291d35d5da5b220bbea4aeaa0cefa8a8f5624f72e0c894731291917c2087d8c",
 							Coins:   "0.009999",
 							Hours:   1111,
 							Address: validAddr,
@@ -495,12 +495,12 @@ func TestGetTransactionByID(t *testing.T) {
 				Transaction: readable.TransactionVerbose{
 					BlockTransactionVerbose: readable.BlockTransactionVerbose{
 						Fee:       2222,
-						Hash:      "b64525bc14edb3c838ff3ef4f01bd74712432b32c18463dbda59b431959b2e52",
+						Hash:      "9a82dbde1c327c385e471ce3bcf13d16c06bab12233a2ab7b941bec3433b3754",
 						InnerHash: "0000000000000000000000000000000000000000000000000000000000000000",
 						Sigs:      []string{validSig},
 						In: []readable.TransactionInput{
 							{
-								Hash:            "50e8ad459e29a051d969f221f1fb9775e26248e8b443982fef0cfaa117ee6c0c",
+								Hash:            "ceb3453e03c254fafa0c216a8d0dbb6bc233c669be97f1cb5233b6dd3f4d2bd0",
 								Coins:           "0.009999",
 								Hours:           1111,
 								CalculatedHours: 3333,
@@ -509,7 +509,7 @@ func TestGetTransactionByID(t *testing.T) {
 						},
 						Out: []readable.TransactionOutput{
 							{
-								Hash:    "87ec4d440fd64bb4c26839d58684e567e499265ca396649c03304b928378720b",
+								Hash:    "3291d35d5da5b220bbea4aeaa0cefa8a8f5624f72e0c894731291917c2087d8c",
 								Coins:   "0.009999",
 								Hours:   1111,
 								Address: validAddr,
@@ -554,7 +554,7 @@ func TestGetTransactionByID(t *testing.T) {
 					BlockSeq:  100,
 					Height:    9,
 				},
-				EncodedTransaction: "0000000000000000000000000000000000000000000000000000000000000000000000000001000000cca1595fb27375789da47bb1cf78e14febc2be6f3c3034247fea6f700b853cddbab5d16f4ffc1912fca8373f10e468b745d6a1d686cb73ade1e3c3b3653b2f9d7f0100000079216473e8f2c17095c6887cc9edca6c023afedfac2e0c5460e8b6f359684f8b0100000000a1f1da0612c870cbb2d88fb3d7f95ba7118d6efb0f270000000000005704000000000000",
+				EncodedTransaction: "0000000000000000000000000000000000000000000000000000000000000000000000000001000000cca1595fb27375789da47bb1cf78e14febc2be6f3c3034247fea6f700b853cddbab5d16f4ffc1912fca8373f10e468b745d6a1d686cb73ade1e3c3b3653b2f9d7f0100000079216473e8f2c17095c6887cc9edca6c023afedfac2e0c5460e8b6f359684f8b0100000000a1f1da0612c870cbb2d88fb3d7f95ba7118d6efb0f2700000000000057040000000000000000000000000000",
 			},
 		},
 	}
@@ -644,6 +644,18 @@ func TestInjectTransaction(t *testing.T) {
 	invalidTxnBodyJSON, err := json.Marshal(b)
 	require.NoError(t, err)
 
+	conflictErr := visor.NewErrTxnConflict([]cipher.SHA256{validTransaction.Hash()})
+	conflictBody, err := json.MarshalIndent(HTTPResponse{
+		Error: &HTTPError{
+			Code:    http.StatusConflict,
+			Message: conflictErr.Error(),
+		},
+		Data: InjectTransactionConflict{
+			ConflictingTxids: []string{validTransaction.Hash().Hex()},
+		},
+	}, "", "    ")
+	require.NoError(t, err)
+
 	tt := []struct {
 		name                   string
 		method                 string
@@ -716,6 +728,15 @@ func TestInjectTransaction(t *testing.T) {
 			injectTransactionArg:   validTransaction,
 			injectTransactionError: gnet.ErrPoolEmpty,
 		},
+		{
+			name:                   "409 - visor.ErrTxnConflict",
+			method:                 http.MethodPost,
+			status:                 http.StatusConflict,
+			err:                    string(conflictBody),
+			httpBody:               string(validTxnBodyJSON),
+			injectTransactionArg:   validTransaction,
+			injectTransactionError: conflictErr,
+		},
 		{
 			name:                   "500 - other injectTransactionError",
 			method:                 http.MethodPost,
@@ -957,7 +978,7 @@ func TestGetRawTxn(t *testing.T) {
 			},
 			getTransactionArg:      testutil.SHA256FromHex(t, validHash),
 			getTransactionResponse: &visor.Transaction{},
-			httpResponse:           "00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+			httpResponse:           "0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
 		},
 	}
 
@@ -1319,9 +1340,9 @@ func prepareTxnAndInputs(t *testing.T) transactionAndInputs {
 
 	err := txn.PushInput(ux.Hash())
 	require.NoError(t, err)
-	err = txn.PushOutput(makeAddress(), 1e6, 50)
+	err = txn.PushOutput(makeAddress(), 1e6, 50, nil)
 	require.NoError(t, err)
-	err = txn.PushOutput(makeAddress(), 5e6, 50)
+	err = txn.PushOutput(makeAddress(), 5e6, 50, nil)
 	require.NoError(t, err)
 	txn.SignInputs([]cipher.SecKey{s})
 	err = txn.UpdateHeader()
@@ -1342,9 +1363,9 @@ func makeTransactionWithEmptyAddressOutput(t *testing.T) transactionAndInputs {
 
 	err := txn.PushInput(ux.Hash())
 	require.NoError(t, err)
-	err = txn.PushOutput(makeAddress(), 1e6, 50)
+	err = txn.PushOutput(makeAddress(), 1e6, 50, nil)
 	require.NoError(t, err)
-	err = txn.PushOutput(cipher.Address{}, 5e6, 50)
+	err = txn.PushOutput(cipher.Address{}, 5e6, 50, nil)
 	require.NoError(t, err)
 	txn.SignInputs([]cipher.SecKey{s})
 	err = txn.UpdateHeader()
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/SkycoinProject/cx-chains/src/util/droplet"
+	wh "github.com/SkycoinProject/cx-chains/src/util/http"
+	"github.com/SkycoinProject/cx-chains/src/visor"
+	"github.com/SkycoinProject/cx-chains/src/visor/blockdb"
+)
+
+// defaultDustThreshold are the coins below which an unspent output is counted as dust by default, in droplets
+const defaultDustThreshold = 1e6
+
+// UnspentStatsBucket are the unspent outputs with coins in a range
+type UnspentStatsBucket struct {
+	MinCoins string `json:"min_coins"`
+	// MaxCoins is the exclusive upper bound of the range, empty for the last bucket
+	MaxCoins string `json:"max_coins,omitempty"`
+	Count    uint64 `json:"count"`
+	Coins    string `json:"coins"`
+}
+
+// UnspentStatsDust are the unspent outputs with fewer coins than the dust threshold
+type UnspentStatsDust struct {
+	Threshold string `json:"threshold"`
+	Count     uint64 `json:"count"`
+	Coins     string `json:"coins"`
+}
+
+// UnspentStatsResponse is returned by GET /api/v1/unspents/stats
+type UnspentStatsResponse struct {
+	HeadSeq   uint64               `json:"head_seq"`
+	Count     uint64               `json:"count"`
+	Coins     string               `json:"coins"`
+	CoinHours uint64               `json:"coin_hours"`
+	Histogram []UnspentStatsBucket `json:"histogram"`
+	Dust      UnspentStatsDust     `json:"dust"`
+}
+
+// dustThresholds returns the valid dust_threshold values
+func dustThresholds() ([]string, error) {
+	thresholds := make([]string, len(blockdb.UnspentStatsBounds))
+	for i, b := range blockdb.UnspentStatsBounds {
+		var err error
+		thresholds[i], err = droplet.ToString(b)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return thresholds, nil
+}
+
+// unspentStatsHandler returns the statistics of the unspent outputs: their count, coins and coin hours,
+// a histogram of their coins and the number of dust outputs. The statistics are maintained as blocks are
+// executed, so this does not scan the unspent outputs.
+// Method: GET
+// URI: /api/v1/unspents/stats
+// Args:
+//
+//	dust_threshold: Outputs with fewer coins are counted as dust [optional, default "1"]
+//	    Must be one of the bounds of the histogram buckets
+func unspentStatsHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
+		dustThreshold := uint64(defaultDustThreshold)
+		if v := r.FormValue("dust_threshold"); v != "" {
+			var err error
+			dustThreshold, err = droplet.FromString(v)
+			if err != nil {
+				wh.Error400(w, "Invalid dust_threshold value")
+				return
+			}
+		}
+
+		stats, err := gateway.GetUnspentStats()
+		if err != nil {
+			err = fmt.Errorf("gateway.GetUnspentStats failed: %v", err)
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		dust, err := stats.Dust(dustThreshold)
+		if err != nil {
+			thresholds, err := dustThresholds()
+			if err != nil {
+				wh.Error500(w, err.Error())
+				return
+			}
+
+			wh.Error400(w, fmt.Sprintf("Invalid dust_threshold value, must be one of %s", strings.Join(thresholds, ", ")))
+			return
+		}
+
+		resp, err := newUnspentStatsResponse(stats, dust, dustThreshold)
+		if err != nil {
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		wh.SendJSONOr500(logger, w, resp)
+	}
+}
+
+func newUnspentStatsResponse(stats *visor.UnspentStats, dust blockdb.UnspentStatsBucket, dustThreshold uint64) (*UnspentStatsResponse, error) {
+	coins, err := droplet.ToString(stats.Total.Coins)
+	if err != nil {
+		return nil, err
+	}
+
+	histogram := make([]UnspentStatsBucket, len(stats.Histogram))
+	var minCoins uint64
+	for i, b := range stats.Histogram {
+		bucket := UnspentStatsBucket{
+			Count: b.Count,
+		}
+
+		if bucket.Coins, err = droplet.ToString(b.Coins); err != nil {
+			return nil, err
+		}
+		if bucket.MinCoins, err = droplet.ToString(minCoins); err != nil {
+			return nil, err
+		}
+		if i < len(blockdb.UnspentStatsBounds) {
+			minCoins = blockdb.UnspentStatsBounds[i]
+			if bucket.MaxCoins, err = droplet.ToString(minCoins); err != nil {
+				return nil, err
+			}
+		}
+
+		histogram[i] = bucket
+	}
+
+	threshold, err := droplet.ToString(dustThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	dustCoins, err := droplet.ToString(dust.Coins)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UnspentStatsResponse{
+		HeadSeq:   stats.HeadSeq,
+		Count:     stats.Count,
+		Coins:     coins,
+		CoinHours: stats.CoinHours,
+		Histogram: histogram,
+		Dust: UnspentStatsDust{
+			Threshold: threshold,
+			Count:     dust.Count,
+			Coins:     dustCoins,
+		},
+	}, nil
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/visor"
+	"github.com/SkycoinProject/cx-chains/src/visor/blockdb"
+)
+
+func TestUnspentStats(t *testing.T) {
+	stats := &visor.UnspentStats{
+		UnspentStats: blockdb.UnspentStats{
+			Total: blockdb.BalanceTotal{
+				Coins: 1002600000,
+				Hours: 100,
+			},
+			Count: 7,
+		},
+		HeadSeq:   12,
+		CoinHours: 150,
+	}
+	stats.Histogram[1] = blockdb.UnspentStatsBucket{Count: 2, Coins: 2e4}
+	stats.Histogram[3] = blockdb.UnspentStatsBucket{Count: 4, Coins: 2580000}
+	stats.Histogram[6] = blockdb.UnspentStatsBucket{Count: 1, Coins: 1e9}
+
+	histogram := []UnspentStatsBucket{
+		{MinCoins: "0.000000", MaxCoins: "0.001000", Coins: "0.000000"},
+		{MinCoins: "0.001000", MaxCoins: "0.010000", Count: 2, Coins: "0.020000"},
+		{MinCoins: "0.010000", MaxCoins: "0.100000", Coins: "0.000000"},
+		{MinCoins: "0.100000", MaxCoins: "1.000000", Count: 4, Coins: "2.580000"},
+		{MinCoins: "1.000000", MaxCoins: "10.000000", Coins: "0.000000"},
+		{MinCoins: "10.000000", MaxCoins: "100.000000", Coins: "0.000000"},
+		{MinCoins: "100.000000", MaxCoins: "1000.000000", Count: 1, Coins: "1000.000000"},
+		{MinCoins: "1000.000000", MaxCoins: "10000.000000", Coins: "0.000000"},
+		{MinCoins: "10000.000000", MaxCoins: "100000.000000", Coins: "0.000000"},
+		{MinCoins: "100000.000000", MaxCoins: "1000000.000000", Coins: "0.000000"},
+		{MinCoins: "1000000.000000", Coins: "0.000000"},
+	}
+
+	tt := []struct {
+		name          string
+		method        string
+		query         string
+		status        int
+		err           string
+		gatewayResult *visor.UnspentStats
+		gatewayErr    error
+		httpResponse  UnspentStatsResponse
+	}{
+		{
+			name:   "405",
+			method: http.MethodPost,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+		{
+			name:   "400 - invalid dust_threshold",
+			method: http.MethodGet,
+			query:  "?dust_threshold=foo",
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - Invalid dust_threshold value",
+		},
+		{
+			name:          "400 - dust_threshold is not a bound",
+			method:        http.MethodGet,
+			query:         "?dust_threshold=0.5",
+			gatewayResult: stats,
+			status:        http.StatusBadRequest,
+			err:           "400 Bad Request - Invalid dust_threshold value, must be one of 0.001000, 0.010000, 0.100000, 1.000000, 10.000000, 100.000000, 1000.000000, 10000.000000, 100000.000000, 1000000.000000",
+		},
+		{
+			name:       "500 - gateway error",
+			method:     http.MethodGet,
+			gatewayErr: errors.New("failed"),
+			status:     http.StatusInternalServerError,
+			err:        "500 Internal Server Error - gateway.GetUnspentStats failed: failed",
+		},
+		{
+			name:          "200",
+			method:        http.MethodGet,
+			gatewayResult: stats,
+			status:        http.StatusOK,
+			httpResponse: UnspentStatsResponse{
+				HeadSeq:   12,
+				Count:     7,
+				Coins:     "1002.600000",
+				CoinHours: 150,
+				Histogram: histogram,
+				Dust: UnspentStatsDust{
+					Threshold: "1.000000",
+					Count:     6,
+					Coins:     "2.600000",
+				},
+			},
+		},
+		{
+			name:          "200 - dust_threshold",
+			method:        http.MethodGet,
+			query:         "?dust_threshold=0.01",
+			gatewayResult: stats,
+			status:        http.StatusOK,
+			httpResponse: UnspentStatsResponse{
+				HeadSeq:   12,
+				Count:     7,
+				Coins:     "1002.600000",
+				CoinHours: 150,
+				Histogram: histogram,
+				Dust: UnspentStatsDust{
+					Threshold: "0.010000",
+					Count:     2,
+					Coins:     "0.020000",
+				},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetUnspentStats").Return(tc.gatewayResult, tc.gatewayErr)
+
+			req, err := http.NewRequest(tc.method, "/api/v1/unspents/stats"+tc.query, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, tc.status, rr.Code, "got `%v` want `%v`", rr.Code, tc.status)
+
+			if rr.Code != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()))
+				return
+			}
+
+			var msg UnspentStatsResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &msg)
+			require.NoError(t, err)
+			require.Equal(t, tc.httpResponse, msg)
+		})
+	}
+}
@@ -5,14 +5,20 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"sort"
 	"strconv"
+	"time"
 
+	"github.com/SkycoinProject/cx-chains/src/cipher"
 	"github.com/SkycoinProject/cx-chains/src/cipher/bip39"
+	"github.com/SkycoinProject/cx-chains/src/cipher/bip44"
 	"github.com/SkycoinProject/cx-chains/src/readable"
 	wh "github.com/SkycoinProject/cx-chains/src/util/http"
+	"github.com/SkycoinProject/cx-chains/src/visor/blockdb"
 	"github.com/SkycoinProject/cx-chains/src/wallet"
+	"github.com/SkycoinProject/cx-chains/src/wallet/hardware"
 )
 
 // UnconfirmedTxnsResponse contains unconfirmed transaction data
@@ -47,6 +53,7 @@ func NewWalletResponse(w *wallet.Wallet) (*WalletResponse, error) {
 	wr.Meta.Type = w.Meta["type"]
 	wr.Meta.Version = w.Meta["version"]
 	wr.Meta.CryptoType = w.Meta["cryptoType"]
+	wr.Meta.XPub = w.Meta["xpub"]
 
 	// Converts "encrypted" string to boolean if any
 	if encryptedStr, ok := w.Meta["encrypted"]; ok {
@@ -67,9 +74,15 @@ func NewWalletResponse(w *wallet.Wallet) (*WalletResponse, error) {
 	}
 
 	for _, e := range w.Entries {
+		// Watch-only wallets created from addresses have no public keys
+		var public string
+		if !e.Public.Null() {
+			public = e.Public.Hex()
+		}
+
 		wr.Entries = append(wr.Entries, readable.WalletEntry{
 			Address: e.Address.String(),
-			Public:  e.Public.Hex(),
+			Public:  public,
 		})
 	}
 
@@ -117,10 +130,13 @@ func walletBalanceHandler(gateway Gatewayer) http.HandlerFunc {
 
 // Returns the balance of one or more addresses, both confirmed and predicted.  The predicted
 // balance is the confirmed balance minus the pending spends.
+// If block is set, returns the balance after the block with that seq was executed,
+// with the coin hours at the time of the block. The predicted balance is the confirmed balance.
 // URI: /api/v1s/balance
 // Method: GET, POST
 // Args:
 //     addrs: command separated list of addresses [required]
+//     block: block seq of the balance [optional]
 func balanceHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet && r.Method != http.MethodPost {
@@ -140,11 +156,48 @@ func balanceHandler(gateway Gatewayer) http.HandlerFunc {
 			return
 		}
 
-		bals, err := gateway.GetBalanceOfAddrs(addrs)
-		if err != nil {
-			err = fmt.Errorf("gateway.GetBalanceOfAddrs failed: %v", err)
-			wh.Error500(w, err.Error())
-			return
+		var bals []wallet.BalancePair
+		if block := r.FormValue("block"); block != "" {
+			seq, err := strconv.ParseUint(block, 10, 64)
+			if err != nil {
+				wh.Error400(w, fmt.Sprintf("Invalid block value %q", block))
+				return
+			}
+
+			confirmed, err := gateway.GetBalancesOfAddrsAtSeq(addrs, seq)
+			if err != nil {
+				switch err {
+				case blockdb.ErrSeqAfterHead:
+					wh.Error404(w, err.Error())
+				default:
+					err = fmt.Errorf("gateway.GetBalancesOfAddrsAtSeq failed: %v", err)
+					wh.Error500(w, err.Error())
+				}
+				return
+			}
+
+			bals = make([]wallet.BalancePair, len(confirmed))
+			for i, b := range confirmed {
+				bals[i] = wallet.BalancePair{
+					Confirmed: b,
+					Predicted: b,
+				}
+			}
+		} else {
+			s, err := gateway.Snapshot()
+			if err != nil {
+				err = fmt.Errorf("gateway.Snapshot failed: %v", err)
+				wh.Error500(w, err.Error())
+				return
+			}
+
+			bals, err = s.GetBalanceOfAddrs(addrs)
+			releaseSnapshot(s)
+			if err != nil {
+				err = fmt.Errorf("snapshot.GetBalanceOfAddrs failed: %v", err)
+				wh.Error500(w, err.Error())
+				return
+			}
 		}
 
 		// create map of address to balance
@@ -181,11 +234,20 @@ func balanceHandler(gateway Gatewayer) http.HandlerFunc {
 // URI: /api/v1/wallet/create
 // Method: POST
 // Args:
-//     seed: wallet seed [required]
+//     seed: wallet seed [required, except for watch-only wallets]
 //     label: wallet label [required]
 //     scan: the number of addresses to scan ahead for balances [optional, must be > 0]
+//           for bip44 and xpub watch-only wallets, the number of consecutive unused addresses to scan for used addresses [optional, default 20]
 //     encrypt: bool value, whether encrypt the wallet [optional]
 //     password: password for encrypting wallet [optional, must be provided if "encrypt" is set]
+//     type: wallet type, "deterministic", "bip44" or "watch-only" [optional, default "deterministic"]
+//     seed_passphrase: bip39 seed passphrase [optional, bip44 wallets only]
+//     bip44_coin: bip44 coin type [optional, bip44 wallets only, default 8000]
+//     bip44_account: bip44 account [optional, bip44 wallets only, default 0]
+//     xpub: bip44 account extended public key [optional, watch-only wallets only, xpub or addresses must be provided]
+//     addresses: comma separated list of addresses [optional, watch-only wallets only, xpub or addresses must be provided]
+//     hw: bool value, create a watch-only wallet of the addresses of the connected hardware wallet device [optional].
+//         scan is the number of device addresses, from address index 0. xpub and addresses must not be provided.
 func walletCreateHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
@@ -193,8 +255,19 @@ func walletCreateHandler(gateway Gatewayer) http.HandlerFunc {
 			return
 		}
 
+		walletType := r.FormValue("type")
+		if walletType == "" {
+			walletType = wallet.WalletTypeDeterministic
+		}
+		switch walletType {
+		case wallet.WalletTypeDeterministic, wallet.WalletTypeBip44, wallet.WalletTypeWatchOnly:
+		default:
+			wh.Error400(w, "invalid wallet type")
+			return
+		}
+
 		seed := r.FormValue("seed")
-		if seed == "" {
+		if seed == "" && walletType != wallet.WalletTypeWatchOnly {
 			wh.Error400(w, "missing seed")
 			return
 		}
@@ -231,8 +304,70 @@ func walletCreateHandler(gateway Gatewayer) http.HandlerFunc {
 			return
 		}
 
+		seedPassphrase := r.FormValue("seed_passphrase")
+		defer func() {
+			seedPassphrase = ""
+		}()
+
+		var bip44Coin *bip44.CoinType
+		bip44CoinStr := r.FormValue("bip44_coin")
+		if bip44CoinStr != "" {
+			c, err := strconv.ParseUint(bip44CoinStr, 10, 31)
+			if err != nil {
+				wh.Error400(w, "invalid bip44_coin value")
+				return
+			}
+			ct := bip44.CoinType(c)
+			bip44Coin = &ct
+		}
+
+		var bip44Account uint64
+		bip44AccountStr := r.FormValue("bip44_account")
+		if bip44AccountStr != "" {
+			var err error
+			bip44Account, err = strconv.ParseUint(bip44AccountStr, 10, 31)
+			if err != nil {
+				wh.Error400(w, "invalid bip44_account value")
+				return
+			}
+		}
+
+		var hw bool
+		hwStr := r.FormValue("hw")
+		if hwStr != "" {
+			var err error
+			hw, err = strconv.ParseBool(hwStr)
+			if err != nil {
+				wh.Error400(w, fmt.Sprintf("invalid hw value: %v", err))
+				return
+			}
+		}
+
+		if hw && walletType != wallet.WalletTypeWatchOnly {
+			wh.Error400(w, "hw is only supported by watch-only wallets")
+			return
+		}
+
+		xpub := r.FormValue("xpub")
+
+		var addrs []cipher.Addresser
+		addrsStr := r.FormValue("addresses")
+		if addrsStr != "" {
+			for _, s := range splitCommaString(addrsStr) {
+				a, err := cipher.DecodeBase58Address(s)
+				if err != nil {
+					wh.Error400(w, fmt.Sprintf("invalid address %q: %v", s, err))
+					return
+				}
+				addrs = append(addrs, a)
+			}
+		}
+
 		scanNStr := r.FormValue("scan")
 		var scanN uint64 = 1
+		if walletType == wallet.WalletTypeBip44 || (walletType == wallet.WalletTypeWatchOnly && xpub != "") {
+			scanN = wallet.DefaultBip44GapLimit
+		}
 		if scanNStr != "" {
 			var err error
 			scanN, err = strconv.ParseUint(scanNStr, 10, 64)
@@ -247,13 +382,61 @@ func walletCreateHandler(gateway Gatewayer) http.HandlerFunc {
 			return
 		}
 
+		if hw {
+			if xpub != "" || len(addrs) != 0 {
+				wh.Error400(w, "xpub and addresses cannot be used with hw")
+				return
+			}
+
+			if scanN > math.MaxUint32 {
+				wh.Error400(w, "invalid scan value")
+				return
+			}
+
+			wlt, err := gateway.CreateHardwareWallet("", wallet.Options{
+				Label: label,
+			}, uint32(scanN))
+			if err != nil {
+				switch err.(type) {
+				case wallet.Error:
+					switch err {
+					case wallet.ErrWalletAPIDisabled:
+						wh.Error403(w, "")
+					default:
+						wh.Error400(w, err.Error())
+					}
+				default:
+					if err == hardware.ErrNoDevice {
+						wh.Error503(w, err.Error())
+					} else {
+						wh.Error500(w, err.Error())
+					}
+				}
+				return
+			}
+
+			rlt, err := NewWalletResponse(wlt)
+			if err != nil {
+				wh.Error500(w, err.Error())
+				return
+			}
+			wh.SendJSONOr500(logger, w, rlt)
+			return
+		}
+
 		wlt, err := gateway.CreateWallet("", wallet.Options{
-			Seed:     seed,
-			Label:    label,
-			Encrypt:  encrypt,
-			Password: []byte(password),
-			ScanN:    scanN,
-		}, gateway)
+			Seed:           seed,
+			Label:          label,
+			Encrypt:        encrypt,
+			Password:       []byte(password),
+			ScanN:          scanN,
+			Type:           walletType,
+			SeedPassphrase: seedPassphrase,
+			Bip44Coin:      bip44Coin,
+			Bip44Account:   uint32(bip44Account),
+			XPub:           xpub,
+			Addresses:      addrs,
+		}, gateway, gateway)
 		if err != nil {
 			switch err.(type) {
 			case wallet.Error:
@@ -654,6 +837,7 @@ func walletSeedHandler(gateway Gatewayer) http.HandlerFunc {
 			switch err {
 			case wallet.ErrMissingPassword,
 				wallet.ErrWalletNotEncrypted,
+				wallet.ErrWalletWatchOnly,
 				wallet.ErrInvalidPassword:
 				wh.Error400(w, err.Error())
 			case wallet.ErrWalletAPIDisabled, wallet.ErrSeedAPIDisabled:
@@ -676,6 +860,58 @@ func walletSeedHandler(gateway Gatewayer) http.HandlerFunc {
 	}
 }
 
+// Returns the bip32 extended public key of a bip44 wallet's account, or of the account watched by a watch-only wallet
+// URI: /api/v1/wallet/xpub
+// Method: GET
+// Args:
+//     id: wallet id [required]
+func walletXPubHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
+		id := r.FormValue("id")
+		if id == "" {
+			wh.Error400(w, "missing wallet id")
+			return
+		}
+
+		wlt, err := gateway.GetWallet(id)
+		if err != nil {
+			switch err {
+			case wallet.ErrWalletAPIDisabled:
+				wh.Error403(w, "")
+			case wallet.ErrWalletNotExist:
+				wh.Error404(w, "")
+			default:
+				wh.Error500(w, err.Error())
+			}
+			return
+		}
+
+		xpub, err := wlt.XPub()
+		if err != nil {
+			switch err {
+			case wallet.ErrWalletNotBip44, wallet.ErrWatchOnlyNoXPub:
+				wh.Error400(w, err.Error())
+			default:
+				wh.Error500(w, err.Error())
+			}
+			return
+		}
+
+		v := struct {
+			XPub string `json:"xpub"`
+		}{
+			XPub: xpub,
+		}
+
+		wh.SendJSONOr500(logger, w, v)
+	}
+}
+
 // VerifySeedRequest is the request data for POST /api/v2/wallet/seed/verify
 type VerifySeedRequest struct {
 	Seed string `json:"seed"`
@@ -770,6 +1006,7 @@ func walletEncryptHandler(gateway Gatewayer) http.HandlerFunc {
 		if err != nil {
 			switch err {
 			case wallet.ErrWalletEncrypted,
+				wallet.ErrWalletWatchOnly,
 				wallet.ErrMissingPassword,
 				wallet.ErrInvalidPassword:
 				wh.Error400(w, err.Error())
@@ -928,3 +1165,228 @@ func walletRecoverHandler(gateway Gatewayer) http.HandlerFunc {
 		})
 	}
 }
+
+// WalletUnlockRequest is the request data for POST /api/v2/wallet/unlock
+type WalletUnlockRequest struct {
+	ID       string `json:"id"`
+	Password string `json:"password"`
+	// Timeout is the number of seconds to keep the wallet unlocked
+	Timeout uint64 `json:"timeout"`
+}
+
+// WalletUnlockResponse is the response data for POST /api/v2/wallet/unlock
+type WalletUnlockResponse struct {
+	ID            string `json:"id"`
+	UnlockedUntil int64  `json:"unlocked_until"`
+}
+
+// URI: /api/v2/wallet/unlock
+// Method: POST
+// Args:
+//	id: wallet id
+//	password: wallet password
+//	timeout: number of seconds to keep the wallet unlocked
+// Decrypts an encrypted wallet and keeps it decrypted in memory until the timeout expires.
+// While the wallet is unlocked, transactions can be created and signed with it without a password.
+// Unlocking an unlocked wallet restarts the timeout.
+func walletUnlockHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletUnlockRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.ID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.Password == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "password is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.Timeout == 0 {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "timeout is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.Timeout > uint64(wallet.MaxUnlockTimeout/time.Second) {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, wallet.ErrInvalidUnlockTimeout.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		password := []byte(req.Password)
+		defer func() {
+			req.Password = ""
+			password = nil
+		}()
+
+		until, err := gateway.UnlockWallet(req.ID, password, time.Duration(req.Timeout)*time.Second)
+		if err != nil {
+			var resp HTTPResponse
+			switch err {
+			case wallet.ErrInvalidPassword:
+				resp = NewHTTPErrorResponse(http.StatusUnauthorized, err.Error())
+			case wallet.ErrWalletNotExist:
+				resp = NewHTTPErrorResponse(http.StatusNotFound, "")
+			case wallet.ErrWalletAPIDisabled:
+				resp = NewHTTPErrorResponse(http.StatusForbidden, "")
+			default:
+				switch err.(type) {
+				case wallet.Error:
+					resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+				default:
+					resp = NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+				}
+			}
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: WalletUnlockResponse{
+				ID:            req.ID,
+				UnlockedUntil: until.Unix(),
+			},
+		})
+	}
+}
+
+// WalletLockRequest is the request data for POST /api/v2/wallet/lock
+type WalletLockRequest struct {
+	ID string `json:"id"`
+}
+
+// URI: /api/v2/wallet/lock
+// Method: POST
+// Args:
+//	id: wallet id
+// Erases the decrypted copy of a wallet unlocked with /api/v2/wallet/unlock from memory.
+// Locking a wallet that is not unlocked is not an error.
+func walletLockHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletLockRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.ID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if err := gateway.LockWallet(req.ID); err != nil {
+			var resp HTTPResponse
+			switch err {
+			case wallet.ErrWalletNotExist:
+				resp = NewHTTPErrorResponse(http.StatusNotFound, "")
+			case wallet.ErrWalletAPIDisabled:
+				resp = NewHTTPErrorResponse(http.StatusForbidden, "")
+			default:
+				resp = NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			}
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: struct{}{},
+		})
+	}
+}
+
+// WalletConfirmAddressRequest is the request data for POST /api/v2/wallet/address/confirm
+type WalletConfirmAddressRequest struct {
+	WalletID string `json:"wallet_id"`
+	Address  string `json:"address"`
+}
+
+// URI: /api/v2/wallet/address/confirm
+// Method: POST
+// Args:
+//	wallet_id: hardware wallet id
+//	address: address to confirm
+// Shows the address on the hardware wallet device, for the user to confirm that the device owns it.
+// Returns 503 if no hardware wallet device is connected.
+func walletConfirmAddressHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletConfirmAddressRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.WalletID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "wallet_id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.Address == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "address is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		addr, err := cipher.DecodeBase58Address(req.Address)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("invalid address: %v", err))
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if err := gateway.WalletConfirmAddressHardware(req.WalletID, addr); err != nil {
+			var resp HTTPResponse
+			switch err {
+			case hardware.ErrNoDevice:
+				resp = NewHTTPErrorResponse(http.StatusServiceUnavailable, err.Error())
+			case wallet.ErrWalletNotExist:
+				resp = NewHTTPErrorResponse(http.StatusNotFound, "")
+			case wallet.ErrWalletAPIDisabled:
+				resp = NewHTTPErrorResponse(http.StatusForbidden, "")
+			default:
+				switch err.(type) {
+				case wallet.Error:
+					resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+				default:
+					resp = NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+				}
+			}
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: struct{}{},
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"encoding/json"
 
@@ -17,16 +18,20 @@ import (
 
 	"github.com/SkycoinProject/cx-chains/src/cipher"
 	"github.com/SkycoinProject/cx-chains/src/cipher/bip39"
+	"github.com/SkycoinProject/cx-chains/src/cipher/bip44"
 	"github.com/SkycoinProject/cx-chains/src/coin"
 	"github.com/SkycoinProject/cx-chains/src/readable"
 	"github.com/SkycoinProject/cx-chains/src/testutil"
 	"github.com/SkycoinProject/cx-chains/src/visor"
+	"github.com/SkycoinProject/cx-chains/src/visor/blockdb"
 	"github.com/SkycoinProject/cx-chains/src/wallet"
+	"github.com/SkycoinProject/cx-chains/src/wallet/hardware"
 )
 
 func TestGetBalanceHandler(t *testing.T) {
 	type httpBody struct {
 		addrs string
+		block string
 	}
 	invalidAddr := "invalidAddr"
 	validAddr := "2eZYSbzBKJ7QCL4kd5LSqV478rJQGb4UNkf"
@@ -38,9 +43,13 @@ func TestGetBalanceHandler(t *testing.T) {
 		status                    int
 		err                       string
 		httpBody                  *httpBody
+		snapshotErr               error
 		getBalanceOfAddrsArg      []cipher.Address
 		getBalanceOfAddrsResponse []wallet.BalancePair
 		getBalanceOfAddrsError    error
+		getBalancesAtSeqArg       uint64
+		getBalancesAtSeqResponse  []wallet.Balance
+		getBalancesAtSeqError     error
 		httpResponse              readable.BalancePair
 	}{
 		{
@@ -65,11 +74,21 @@ func TestGetBalanceHandler(t *testing.T) {
 			err:      "400 Bad Request - addrs is required",
 			httpBody: &httpBody{},
 		},
+		{
+			name:   "500 - SnapshotError",
+			method: http.MethodGet,
+			status: http.StatusInternalServerError,
+			err:    "500 Internal Server Error - gateway.Snapshot failed: SnapshotError",
+			httpBody: &httpBody{
+				addrs: validAddr,
+			},
+			snapshotErr: errors.New("SnapshotError"),
+		},
 		{
 			name:   "500 - GetBalanceOfAddrsError",
 			method: http.MethodGet,
 			status: http.StatusInternalServerError,
-			err:    "500 Internal Server Error - gateway.GetBalanceOfAddrs failed: GetBalanceOfAddrsError",
+			err:    "500 Internal Server Error - snapshot.GetBalanceOfAddrs failed: GetBalanceOfAddrsError",
 			httpBody: &httpBody{
 				addrs: validAddr,
 			},
@@ -158,19 +177,85 @@ func TestGetBalanceHandler(t *testing.T) {
 			},
 			httpResponse: readable.BalancePair{},
 		},
+		{
+			name:   "400 - invalid block",
+			method: http.MethodGet,
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - Invalid block value \"-1\"",
+			httpBody: &httpBody{
+				addrs: validAddr,
+				block: "-1",
+			},
+		},
+		{
+			name:   "404 - block after the head block",
+			method: http.MethodGet,
+			status: http.StatusNotFound,
+			err:    "404 Not Found - Block seq is higher than the head block seq",
+			httpBody: &httpBody{
+				addrs: validAddr,
+				block: "100",
+			},
+			getBalanceOfAddrsArg:  []cipher.Address{address},
+			getBalancesAtSeqArg:   100,
+			getBalancesAtSeqError: blockdb.ErrSeqAfterHead,
+		},
+		{
+			name:   "500 - GetBalancesOfAddrsAtSeq error",
+			method: http.MethodGet,
+			status: http.StatusInternalServerError,
+			err:    "500 Internal Server Error - gateway.GetBalancesOfAddrsAtSeq failed: GetBalancesOfAddrsAtSeqError",
+			httpBody: &httpBody{
+				addrs: validAddr,
+				block: "3",
+			},
+			getBalanceOfAddrsArg:  []cipher.Address{address},
+			getBalancesAtSeqArg:   3,
+			getBalancesAtSeqError: errors.New("GetBalancesOfAddrsAtSeqError"),
+		},
+		{
+			name:   "200 - OK block",
+			method: http.MethodGet,
+			status: http.StatusOK,
+			httpBody: &httpBody{
+				addrs: validAddr,
+				block: "3",
+			},
+			getBalanceOfAddrsArg: []cipher.Address{address},
+			getBalancesAtSeqArg:  3,
+			getBalancesAtSeqResponse: []wallet.Balance{
+				{Coins: 2e6, Hours: 12},
+			},
+			httpResponse: readable.BalancePair{
+				Confirmed: readable.Balance{Coins: 2e6, Hours: 12},
+				Predicted: readable.Balance{Coins: 2e6, Hours: 12},
+			},
+		},
 	}
 
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
+			snapshot := &MockSnapshotter{}
+			snapshot.On("GetBalanceOfAddrs", tc.getBalanceOfAddrsArg).Return(tc.getBalanceOfAddrsResponse, tc.getBalanceOfAddrsError)
+			snapshot.On("Release").Return(nil)
+
 			gateway := &MockGatewayer{}
 			endpoint := "/api/v1/balance"
-			gateway.On("GetBalanceOfAddrs", tc.getBalanceOfAddrsArg).Return(tc.getBalanceOfAddrsResponse, tc.getBalanceOfAddrsError)
+			if tc.snapshotErr != nil {
+				gateway.On("Snapshot").Return(nil, tc.snapshotErr)
+			} else {
+				gateway.On("Snapshot").Return(snapshot, nil)
+			}
+			gateway.On("GetBalancesOfAddrsAtSeq", tc.getBalanceOfAddrsArg, tc.getBalancesAtSeqArg).Return(tc.getBalancesAtSeqResponse, tc.getBalancesAtSeqError)
 
 			v := url.Values{}
 			if tc.httpBody != nil {
 				if tc.httpBody.addrs != "" {
 					v.Add("addrs", tc.httpBody.addrs)
 				}
+				if tc.httpBody.block != "" {
+					v.Add("block", tc.httpBody.block)
+				}
 			}
 
 			var reqBody io.Reader
@@ -849,12 +934,21 @@ func TestWalletTransactionsHandler(t *testing.T) {
 func TestWalletCreateHandler(t *testing.T) {
 	entries, responseEntries := makeEntries([]byte("seed"), 5)
 	type httpBody struct {
-		Seed     string
-		Label    string
-		ScanN    string
-		Encrypt  bool
-		Password string
+		Seed           string
+		Label          string
+		ScanN          string
+		Encrypt        bool
+		Password       string
+		Type           string
+		SeedPassphrase string
+		Bip44Coin      string
+		Bip44Account   string
+		XPub           string
+		Addresses      string
+		HW             string
 	}
+	bip44Coin := bip44.CoinTypeBitcoin
+	watchAddrs := []cipher.Addresser{testutil.MakeAddress(), testutil.MakeAddress()}
 	tt := []struct {
 		name                      string
 		method                    string
@@ -865,6 +959,7 @@ func TestWalletCreateHandler(t *testing.T) {
 		options                   wallet.Options
 		gatewayCreateWalletResult wallet.Wallet
 		gatewayCreateWalletErr    error
+		hwScanN                   uint32
 		responseBody              WalletResponse
 		csrfDisabled              bool
 	}{
@@ -1077,15 +1172,283 @@ func TestWalletCreateHandler(t *testing.T) {
 			status: http.StatusBadRequest,
 			err:    "400 Bad Request - missing password",
 		},
+		{
+			name:   "400 - invalid wallet type",
+			method: http.MethodPost,
+			body: &httpBody{
+				Seed:  "foo",
+				Label: "bar",
+				Type:  "foo",
+			},
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - invalid wallet type",
+		},
+		{
+			name:   "400 - invalid bip44_coin value",
+			method: http.MethodPost,
+			body: &httpBody{
+				Seed:      "foo",
+				Label:     "bar",
+				Type:      wallet.WalletTypeBip44,
+				Bip44Coin: "-1",
+			},
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - invalid bip44_coin value",
+		},
+		{
+			name:   "400 - invalid bip44_account value",
+			method: http.MethodPost,
+			body: &httpBody{
+				Seed:         "foo",
+				Label:        "bar",
+				Type:         wallet.WalletTypeBip44,
+				Bip44Account: "2147483648",
+			},
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - invalid bip44_account value",
+		},
+		{
+			name:   "200 - OK - bip44",
+			method: http.MethodPost,
+			body: &httpBody{
+				Seed:           "foo",
+				Label:          "bar",
+				Type:           wallet.WalletTypeBip44,
+				SeedPassphrase: "baz",
+				Bip44Coin:      "0",
+				Bip44Account:   "2",
+			},
+			status:  http.StatusOK,
+			err:     "",
+			wltName: "filename",
+			options: wallet.Options{
+				Label:          "bar",
+				Seed:           "foo",
+				Password:       []byte{},
+				ScanN:          wallet.DefaultBip44GapLimit,
+				Type:           wallet.WalletTypeBip44,
+				SeedPassphrase: "baz",
+				Bip44Coin:      &bip44Coin,
+				Bip44Account:   2,
+			},
+			gatewayCreateWalletResult: wallet.Wallet{
+				Meta: map[string]string{
+					"filename": "filename",
+					"type":     wallet.WalletTypeBip44,
+					"xpub":     "xpub",
+				},
+			},
+			responseBody: WalletResponse{
+				Meta: readable.WalletMeta{
+					Filename: "filename",
+					Type:     wallet.WalletTypeBip44,
+					XPub:     "xpub",
+				},
+			},
+		},
+		{
+			name:   "400 - invalid watch-only address",
+			method: http.MethodPost,
+			body: &httpBody{
+				Label:     "bar",
+				Type:      wallet.WalletTypeWatchOnly,
+				Addresses: "foo",
+			},
+			status: http.StatusBadRequest,
+			err:    "400 Bad Request - invalid address \"foo\": Invalid address length",
+		},
+		{
+			name:   "400 - watch-only wallet with seed",
+			method: http.MethodPost,
+			body: &httpBody{
+				Seed:  "foo",
+				Label: "bar",
+				Type:  wallet.WalletTypeWatchOnly,
+				XPub:  "xpub",
+			},
+			status:  http.StatusBadRequest,
+			err:     "400 Bad Request - watch-only wallets cannot have a seed or be encrypted",
+			wltName: "foo",
+			options: wallet.Options{
+				Label:    "bar",
+				Seed:     "foo",
+				Password: []byte{},
+				ScanN:    wallet.DefaultBip44GapLimit,
+				Type:     wallet.WalletTypeWatchOnly,
+				XPub:     "xpub",
+			},
+			gatewayCreateWalletErr: wallet.ErrWatchOnlySecrets,
+		},
+		{
+			name:   "200 - OK - watch-only xpub",
+			method: http.MethodPost,
+			body: &httpBody{
+				Label: "bar",
+				Type:  wallet.WalletTypeWatchOnly,
+				XPub:  "xpub",
+			},
+			status:  http.StatusOK,
+			wltName: "filename",
+			options: wallet.Options{
+				Label:    "bar",
+				Password: []byte{},
+				ScanN:    wallet.DefaultBip44GapLimit,
+				Type:     wallet.WalletTypeWatchOnly,
+				XPub:     "xpub",
+			},
+			gatewayCreateWalletResult: wallet.Wallet{
+				Meta: map[string]string{
+					"filename": "filename",
+					"type":     wallet.WalletTypeWatchOnly,
+					"xpub":     "xpub",
+				},
+			},
+			responseBody: WalletResponse{
+				Meta: readable.WalletMeta{
+					Filename: "filename",
+					Type:     wallet.WalletTypeWatchOnly,
+					XPub:     "xpub",
+				},
+			},
+		},
+		{
+			name:   "200 - OK - watch-only addresses",
+			method: http.MethodPost,
+			body: &httpBody{
+				Label:     "bar",
+				Type:      wallet.WalletTypeWatchOnly,
+				Addresses: watchAddrs[0].String() + "," + watchAddrs[1].String(),
+			},
+			status:  http.StatusOK,
+			wltName: "filename",
+			options: wallet.Options{
+				Label:     "bar",
+				Password:  []byte{},
+				Type:      wallet.WalletTypeWatchOnly,
+				Addresses: watchAddrs,
+			},
+			gatewayCreateWalletResult: wallet.Wallet{
+				Meta: map[string]string{
+					"filename": "filename",
+					"type":     wallet.WalletTypeWatchOnly,
+				},
+				Entries: []wallet.Entry{
+					{Address: watchAddrs[0]},
+					{Address: watchAddrs[1]},
+				},
+			},
+			responseBody: WalletResponse{
+				Meta: readable.WalletMeta{
+					Filename: "filename",
+					Type:     wallet.WalletTypeWatchOnly,
+				},
+				Entries: []readable.WalletEntry{
+					{Address: watchAddrs[0].String()},
+					{Address: watchAddrs[1].String()},
+				},
+			},
+		},
+		{
+			name:   "400 - invalid hw value",
+			method: http.MethodPost,
+			body: &httpBody{
+				Label: "bar",
+				Type:  wallet.WalletTypeWatchOnly,
+				HW:    "foo",
+			},
+			status:  http.StatusBadRequest,
+			err:     "400 Bad Request - invalid hw value: strconv.ParseBool: parsing \"foo\": invalid syntax",
+			wltName: "foo",
+		},
+		{
+			name:   "400 - hw deterministic wallet",
+			method: http.MethodPost,
+			body: &httpBody{
+				Label: "bar",
+				Seed:  "foo",
+				HW:    "true",
+			},
+			status:  http.StatusBadRequest,
+			err:     "400 Bad Request - hw is only supported by watch-only wallets",
+			wltName: "foo",
+		},
+		{
+			name:   "400 - hw with addresses",
+			method: http.MethodPost,
+			body: &httpBody{
+				Label:     "bar",
+				Type:      wallet.WalletTypeWatchOnly,
+				Addresses: watchAddrs[0].String(),
+				HW:        "true",
+			},
+			status:  http.StatusBadRequest,
+			err:     "400 Bad Request - xpub and addresses cannot be used with hw",
+			wltName: "foo",
+		},
+		{
+			name:   "503 - hw no device",
+			method: http.MethodPost,
+			body: &httpBody{
+				Label: "bar",
+				Type:  wallet.WalletTypeWatchOnly,
+				HW:    "true",
+			},
+			status:                 http.StatusServiceUnavailable,
+			err:                    "503 Service Unavailable - " + hardware.ErrNoDevice.Error(),
+			wltName:                "foo",
+			options:                wallet.Options{Label: "bar"},
+			hwScanN:                1,
+			gatewayCreateWalletErr: hardware.ErrNoDevice,
+		},
+		{
+			name:   "200 - OK - hw",
+			method: http.MethodPost,
+			body: &httpBody{
+				Label: "bar",
+				Type:  wallet.WalletTypeWatchOnly,
+				ScanN: "2",
+				HW:    "true",
+			},
+			status:  http.StatusOK,
+			wltName: "filename",
+			options: wallet.Options{Label: "bar"},
+			hwScanN: 2,
+			gatewayCreateWalletResult: wallet.Wallet{
+				Meta: map[string]string{
+					"filename": "filename",
+					"type":     wallet.WalletTypeWatchOnly,
+				},
+				Entries: []wallet.Entry{
+					{Address: watchAddrs[0]},
+					{Address: watchAddrs[1], ChildNumber: 1},
+				},
+			},
+			responseBody: WalletResponse{
+				Meta: readable.WalletMeta{
+					Filename: "filename",
+					Type:     wallet.WalletTypeWatchOnly,
+				},
+				Entries: []readable.WalletEntry{
+					{Address: watchAddrs[0].String()},
+					{Address: watchAddrs[1].String()},
+				},
+			},
+		},
 	}
 
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
 			gateway := &MockGatewayer{}
+			if tc.hwScanN != 0 {
+				gateway.On("CreateHardwareWallet", "", tc.options, tc.hwScanN).Return(&tc.gatewayCreateWalletResult, tc.gatewayCreateWalletErr)
+			}
 			if tc.options.ScanN == 0 {
 				tc.options.ScanN = 1
 			}
-			gateway.On("CreateWallet", "", tc.options, gateway).Return(&tc.gatewayCreateWalletResult, tc.gatewayCreateWalletErr)
+			if tc.options.Type == "" {
+				tc.options.Type = wallet.WalletTypeDeterministic
+			}
+			gateway.On("CreateWallet", "", tc.options, gateway, gateway).Return(&tc.gatewayCreateWalletResult, tc.gatewayCreateWalletErr)
 
 			endpoint := "/api/v1/wallet/create"
 
@@ -1108,6 +1471,34 @@ func TestWalletCreateHandler(t *testing.T) {
 				if tc.body.Password != "" {
 					v.Add("password", tc.body.Password)
 				}
+
+				if tc.body.Type != "" {
+					v.Add("type", tc.body.Type)
+				}
+
+				if tc.body.SeedPassphrase != "" {
+					v.Add("seed_passphrase", tc.body.SeedPassphrase)
+				}
+
+				if tc.body.Bip44Coin != "" {
+					v.Add("bip44_coin", tc.body.Bip44Coin)
+				}
+
+				if tc.body.Bip44Account != "" {
+					v.Add("bip44_account", tc.body.Bip44Account)
+				}
+
+				if tc.body.XPub != "" {
+					v.Add("xpub", tc.body.XPub)
+				}
+
+				if tc.body.Addresses != "" {
+					v.Add("addresses", tc.body.Addresses)
+				}
+
+				if tc.body.HW != "" {
+					v.Add("hw", tc.body.HW)
+				}
 			}
 
 			req, err := http.NewRequest(tc.method, endpoint, strings.NewReader(v.Encode()))
@@ -1542,46 +1933,164 @@ func TestGetWalletSeed(t *testing.T) {
 	}
 }
 
-func TestWalletNewAddressesHandler(t *testing.T) {
-	type httpBody struct {
-		ID       string
-		Num      string
-		Password string
-	}
-	type Addresses struct {
-		Address []string `json:"addresses"`
-	}
-
-	var responseAddresses = Addresses{}
-	var responseEmptyAddresses = Addresses{}
-
-	var emptyAddrs = make([]cipher.Address, 0)
-	var addrs = make([]cipher.Address, 3)
-
-	for i := 0; i < 3; i++ {
-		pub, _ := cipher.MustGenerateDeterministicKeyPair(cipher.RandByte(32))
-		addrs[i] = cipher.AddressFromPubKey(pub)
-		responseAddresses.Address = append(responseAddresses.Address, addrs[i].String())
-	}
-
+func TestWalletXPubHandler(t *testing.T) {
 	tt := []struct {
-		name                      string
-		method                    string
-		body                      *httpBody
-		status                    int
-		err                       string
-		walletID                  string
-		n                         uint64
-		password                  string
-		gatewayNewAddressesResult []cipher.Address
-		gatewayNewAddressesErr    error
-		responseBody              Addresses
-		csrfDisabled              bool
+		name              string
+		method            string
+		wltID             string
+		gatewayReturnArgs []interface{}
+		expectStatus      int
+		expectErr         string
+		expectXPub        string
 	}{
 		{
-			name:   "405",
+			name:   "200 - OK",
 			method: http.MethodGet,
-			status: http.StatusMethodNotAllowed,
+			wltID:  "wallet.wlt",
+			gatewayReturnArgs: []interface{}{
+				&wallet.Wallet{
+					Meta: map[string]string{
+						"type": wallet.WalletTypeBip44,
+						"xpub": "xpub",
+					},
+				},
+				nil,
+			},
+			expectStatus: http.StatusOK,
+			expectXPub:   "xpub",
+		},
+		{
+			name:         "400 - missing wallet id",
+			method:       http.MethodGet,
+			expectStatus: http.StatusBadRequest,
+			expectErr:    "400 Bad Request - missing wallet id",
+		},
+		{
+			name:   "400 - wallet not bip44",
+			method: http.MethodGet,
+			wltID:  "wallet.wlt",
+			gatewayReturnArgs: []interface{}{
+				&wallet.Wallet{
+					Meta: map[string]string{
+						"type": wallet.WalletTypeDeterministic,
+					},
+				},
+				nil,
+			},
+			expectStatus: http.StatusBadRequest,
+			expectErr:    "400 Bad Request - wallet type is not bip44",
+		},
+		{
+			name:   "403 - wallet API disabled",
+			method: http.MethodGet,
+			wltID:  "wallet.wlt",
+			gatewayReturnArgs: []interface{}{
+				nil,
+				wallet.ErrWalletAPIDisabled,
+			},
+			expectStatus: http.StatusForbidden,
+			expectErr:    "403 Forbidden",
+		},
+		{
+			name:   "404 - wallet does not exist",
+			method: http.MethodGet,
+			wltID:  "wallet.wlt",
+			gatewayReturnArgs: []interface{}{
+				nil,
+				wallet.ErrWalletNotExist,
+			},
+			expectStatus: http.StatusNotFound,
+			expectErr:    "404 Not Found",
+		},
+		{
+			name:         "405 - Method Not Allowed",
+			method:       http.MethodPost,
+			expectStatus: http.StatusMethodNotAllowed,
+			expectErr:    "405 Method Not Allowed",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetWallet", tc.wltID).Return(tc.gatewayReturnArgs...)
+
+			endpoint := "/api/v1/wallet/xpub"
+
+			v := url.Values{}
+			if tc.wltID != "" {
+				v.Add("id", tc.wltID)
+			}
+			if len(v) > 0 {
+				endpoint += "?" + v.Encode()
+			}
+
+			req, err := http.NewRequest(tc.method, endpoint, nil)
+			require.NoError(t, err)
+
+			setCSRFParameters(t, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.expectStatus, status)
+
+			if status != http.StatusOK {
+				require.Equal(t, tc.expectErr, strings.TrimSpace(rr.Body.String()))
+			} else {
+				var r struct {
+					XPub string `json:"xpub"`
+				}
+				err := json.Unmarshal(rr.Body.Bytes(), &r)
+				require.NoError(t, err)
+				require.Equal(t, tc.expectXPub, r.XPub)
+			}
+		})
+	}
+}
+
+func TestWalletNewAddressesHandler(t *testing.T) {
+	type httpBody struct {
+		ID       string
+		Num      string
+		Password string
+	}
+	type Addresses struct {
+		Address []string `json:"addresses"`
+	}
+
+	var responseAddresses = Addresses{}
+	var responseEmptyAddresses = Addresses{}
+
+	var emptyAddrs = make([]cipher.Address, 0)
+	var addrs = make([]cipher.Address, 3)
+
+	for i := 0; i < 3; i++ {
+		pub, _ := cipher.MustGenerateDeterministicKeyPair(cipher.RandByte(32))
+		addrs[i] = cipher.AddressFromPubKey(pub)
+		responseAddresses.Address = append(responseAddresses.Address, addrs[i].String())
+	}
+
+	tt := []struct {
+		name                      string
+		method                    string
+		body                      *httpBody
+		status                    int
+		err                       string
+		walletID                  string
+		n                         uint64
+		password                  string
+		gatewayNewAddressesResult []cipher.Address
+		gatewayNewAddressesErr    error
+		responseBody              Addresses
+		csrfDisabled              bool
+	}{
+		{
+			name:   "405",
+			method: http.MethodGet,
+			status: http.StatusMethodNotAllowed,
 			err:    "405 Method Not Allowed",
 		},
 		{
@@ -2750,3 +3259,428 @@ func TestWalletRecover(t *testing.T) {
 		})
 	}
 }
+
+func TestWalletConfirmAddressHandler(t *testing.T) {
+	addr := testutil.MakeAddress()
+
+	cases := []struct {
+		name         string
+		method       string
+		status       int
+		req          *WalletConfirmAddressRequest
+		httpBody     string
+		gatewayErr   error
+		httpResponse HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+		{
+			name:         "400 - invalid json",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			httpBody:     "{ca",
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid character 'c' looking for beginning of object key string"),
+		},
+		{
+			name:   "400 - missing wallet_id",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &WalletConfirmAddressRequest{
+				Address: addr.String(),
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "wallet_id is required"),
+		},
+		{
+			name:   "400 - missing address",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &WalletConfirmAddressRequest{
+				WalletID: "foo.wlt",
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "address is required"),
+		},
+		{
+			name:   "400 - invalid address",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &WalletConfirmAddressRequest{
+				WalletID: "foo.wlt",
+				Address:  "foo",
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid address: Invalid address length"),
+		},
+		{
+			name:   "400 - address not in wallet",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &WalletConfirmAddressRequest{
+				WalletID: "foo.wlt",
+				Address:  addr.String(),
+			},
+			gatewayErr:   wallet.ErrUnknownAddress,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "address not found in wallet"),
+		},
+		{
+			name:   "400 - address mismatch",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &WalletConfirmAddressRequest{
+				WalletID: "foo.wlt",
+				Address:  addr.String(),
+			},
+			gatewayErr:   hardware.ErrAddressMismatch,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "hardware wallet device address does not match the wallet address"),
+		},
+		{
+			name:   "404 - wallet not found",
+			method: http.MethodPost,
+			status: http.StatusNotFound,
+			req: &WalletConfirmAddressRequest{
+				WalletID: "foo.wlt",
+				Address:  addr.String(),
+			},
+			gatewayErr:   wallet.ErrWalletNotExist,
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:   "503 - no device",
+			method: http.MethodPost,
+			status: http.StatusServiceUnavailable,
+			req: &WalletConfirmAddressRequest{
+				WalletID: "foo.wlt",
+				Address:  addr.String(),
+			},
+			gatewayErr:   hardware.ErrNoDevice,
+			httpResponse: NewHTTPErrorResponse(http.StatusServiceUnavailable, "hardware wallet device is not connected"),
+		},
+		{
+			name:   "200",
+			method: http.MethodPost,
+			status: http.StatusOK,
+			req: &WalletConfirmAddressRequest{
+				WalletID: "foo.wlt",
+				Address:  addr.String(),
+			},
+			httpResponse: HTTPResponse{
+				Data: struct{}{},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.req != nil {
+				gateway.On("WalletConfirmAddressHardware", tc.req.WalletID, addr).Return(tc.gatewayErr)
+			}
+
+			if tc.httpBody == "" && tc.req != nil {
+				tc.httpBody = toJSON(t, tc.req)
+			}
+
+			endpoint := "/api/v2/wallet/address/confirm"
+			req, err := http.NewRequest(tc.method, endpoint, strings.NewReader(tc.httpBody))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+
+			setCSRFParameters(t, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if tc.httpResponse.Data == nil {
+				require.Nil(t, rsp.Data)
+			} else {
+				require.Equal(t, "{}", string(rsp.Data))
+			}
+		})
+	}
+}
+
+func TestWalletUnlockHandler(t *testing.T) {
+	until := time.Unix(1570000000, 0)
+
+	cases := []struct {
+		name         string
+		method       string
+		status       int
+		req          *WalletUnlockRequest
+		httpBody     string
+		httpResponse HTTPResponse
+		gatewayErr   error
+	}{
+		{
+			name:         "method not allowed",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			httpBody:     toJSON(t, WalletUnlockRequest{}),
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, "Method Not Allowed"),
+		},
+		{
+			name:         "empty json body",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			httpBody:     "",
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "EOF"),
+		},
+		{
+			name:   "id missing",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &WalletUnlockRequest{
+				Password: "pwd",
+				Timeout:  60,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "id is required"),
+		},
+		{
+			name:   "password missing",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &WalletUnlockRequest{
+				ID:      "foo.wlt",
+				Timeout: 60,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "password is required"),
+		},
+		{
+			name:   "timeout missing",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &WalletUnlockRequest{
+				ID:       "foo.wlt",
+				Password: "pwd",
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "timeout is required"),
+		},
+		{
+			name:   "timeout too large",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &WalletUnlockRequest{
+				ID:       "foo.wlt",
+				Password: "pwd",
+				Timeout:  uint64(wallet.MaxUnlockTimeout/time.Second) + 1,
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, wallet.ErrInvalidUnlockTimeout.Error()),
+		},
+		{
+			name:   "invalid password",
+			method: http.MethodPost,
+			status: http.StatusUnauthorized,
+			req: &WalletUnlockRequest{
+				ID:       "foo.wlt",
+				Password: "wrong",
+				Timeout:  60,
+			},
+			gatewayErr:   wallet.ErrInvalidPassword,
+			httpResponse: NewHTTPErrorResponse(http.StatusUnauthorized, wallet.ErrInvalidPassword.Error()),
+		},
+		{
+			name:   "wallet not encrypted",
+			method: http.MethodPost,
+			status: http.StatusBadRequest,
+			req: &WalletUnlockRequest{
+				ID:       "foo.wlt",
+				Password: "pwd",
+				Timeout:  60,
+			},
+			gatewayErr:   wallet.ErrWalletNotEncrypted,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, wallet.ErrWalletNotEncrypted.Error()),
+		},
+		{
+			name:   "wallet does not exist",
+			method: http.MethodPost,
+			status: http.StatusNotFound,
+			req: &WalletUnlockRequest{
+				ID:       "foo.wlt",
+				Password: "pwd",
+				Timeout:  60,
+			},
+			gatewayErr:   wallet.ErrWalletNotExist,
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:   "wallet api disabled",
+			method: http.MethodPost,
+			status: http.StatusForbidden,
+			req: &WalletUnlockRequest{
+				ID:       "foo.wlt",
+				Password: "pwd",
+				Timeout:  60,
+			},
+			gatewayErr:   wallet.ErrWalletAPIDisabled,
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, ""),
+		},
+		{
+			name:   "ok",
+			method: http.MethodPost,
+			status: http.StatusOK,
+			req: &WalletUnlockRequest{
+				ID:       "foo.wlt",
+				Password: "pwd",
+				Timeout:  60,
+			},
+			httpResponse: HTTPResponse{
+				Data: WalletUnlockResponse{
+					ID:            "foo.wlt",
+					UnlockedUntil: until.Unix(),
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.req != nil {
+				gateway.On("UnlockWallet", tc.req.ID, []byte(tc.req.Password), time.Duration(tc.req.Timeout)*time.Second).Return(until, tc.gatewayErr)
+				tc.httpBody = toJSON(t, tc.req)
+			}
+
+			req, err := http.NewRequest(tc.method, "/api/v2/wallet/unlock", strings.NewReader(tc.httpBody))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+
+			setCSRFParameters(t, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+
+			cfg := defaultMuxConfig()
+			cfg.disableCSRF = false
+
+			handler := newServerMux(cfg, gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var unlockRsp WalletUnlockResponse
+				err := json.Unmarshal(rsp.Data, &unlockRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(WalletUnlockResponse), unlockRsp)
+			}
+		})
+	}
+}
+
+func TestWalletLockHandler(t *testing.T) {
+	cases := []struct {
+		name         string
+		method       string
+		status       int
+		req          *WalletLockRequest
+		httpBody     string
+		httpResponse HTTPResponse
+		gatewayErr   error
+	}{
+		{
+			name:         "method not allowed",
+			method:       http.MethodGet,
+			status:       http.StatusMethodNotAllowed,
+			httpBody:     toJSON(t, WalletLockRequest{}),
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, "Method Not Allowed"),
+		},
+		{
+			name:         "id missing",
+			method:       http.MethodPost,
+			status:       http.StatusBadRequest,
+			httpBody:     toJSON(t, WalletLockRequest{}),
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "id is required"),
+		},
+		{
+			name:   "wallet does not exist",
+			method: http.MethodPost,
+			status: http.StatusNotFound,
+			req: &WalletLockRequest{
+				ID: "foo.wlt",
+			},
+			gatewayErr:   wallet.ErrWalletNotExist,
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+		{
+			name:   "wallet api disabled",
+			method: http.MethodPost,
+			status: http.StatusForbidden,
+			req: &WalletLockRequest{
+				ID: "foo.wlt",
+			},
+			gatewayErr:   wallet.ErrWalletAPIDisabled,
+			httpResponse: NewHTTPErrorResponse(http.StatusForbidden, ""),
+		},
+		{
+			name:   "ok",
+			method: http.MethodPost,
+			status: http.StatusOK,
+			req: &WalletLockRequest{
+				ID: "foo.wlt",
+			},
+			httpResponse: HTTPResponse{
+				Data: struct{}{},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.req != nil {
+				gateway.On("LockWallet", tc.req.ID).Return(tc.gatewayErr)
+				tc.httpBody = toJSON(t, tc.req)
+			}
+
+			req, err := http.NewRequest(tc.method, "/api/v2/wallet/lock", strings.NewReader(tc.httpBody))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+
+			setCSRFParameters(t, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+
+			cfg := defaultMuxConfig()
+			cfg.disableCSRF = false
+
+			handler := newServerMux(cfg, gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if tc.httpResponse.Data != nil {
+				require.Equal(t, "{}", string(rsp.Data))
+			}
+		})
+	}
+}
@@ -0,0 +1,694 @@
+package api
+
+// Webhook notifications of address activity.
+// Registered URLs are POSTed signed JSON payloads for the transactions touching their addresses, see WebhookPayload.
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/readable"
+	"github.com/SkycoinProject/cx-chains/src/util/file"
+	wh "github.com/SkycoinProject/cx-chains/src/util/http"
+	"github.com/SkycoinProject/cx-chains/src/visor"
+)
+
+const (
+	// webhookQueueSize is the number of payloads queued per webhook before new payloads are dropped
+	webhookQueueSize = 1024
+	// webhookMaxAttempts is the number of times a payload is sent before it is dropped
+	webhookMaxAttempts = 10
+	// webhookInitialBackoff is the time waited before the first retry, doubled after each retry
+	webhookInitialBackoff = time.Second
+	// webhookMaxBackoff is the maximum time waited between retries
+	webhookMaxBackoff = time.Minute * 10
+	// webhookTimeout is the time allowed for a webhook request
+	webhookTimeout = time.Second * 10
+
+	// WebhookEventDeposit is sent for every new unconfirmed transaction creating outputs to a registered address
+	WebhookEventDeposit = "deposit"
+	// WebhookEventConfirmation is sent for every confirmed transaction spending from or to a registered address
+	WebhookEventConfirmation = "confirmation"
+
+	// WebhookSignatureHeader is the header of the hex encoded HMAC-SHA256 of the payload, keyed by the webhook secret
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+var (
+	// ErrWebhookNotFound is returned when removing an unknown webhook
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrWebhookReadOnly is returned when registering a webhook on a node with a read-only database,
+	// which executes no blocks and accepts no transactions, so the webhook would never be notified
+	ErrWebhookReadOnly = errors.New("webhooks can't be registered on a read-only node")
+
+	errWebhookHubShutdown = errors.New("webhook hub is shut down")
+)
+
+// Webhook is a URL notified of the activity of a set of addresses
+type Webhook struct {
+	ID        string   `json:"id"`
+	URL       string   `json:"url"`
+	Addresses []string `json:"addresses"`
+	// Secret is the key of the payload signatures. It is only returned when the webhook is registered
+	Secret string `json:"secret,omitempty"`
+}
+
+// WebhookPayload is the JSON body POSTed to a webhook
+type WebhookPayload struct {
+	// ID identifies the notification and is the same for all attempts, so that receivers can ignore duplicates
+	ID        string `json:"id"`
+	WebhookID string `json:"webhook_id"`
+	Type      string `json:"type"`
+	// Timestamp is the unix time at which the notification was created
+	Timestamp int64 `json:"timestamp"`
+	// Addresses are the registered addresses touched by the transaction
+	Addresses []string `json:"addresses"`
+	// Data is a readable.UnconfirmedTransactionVerbose for deposit notifications
+	// and a WebsocketConfirmedTransaction for confirmation notifications
+	Data interface{} `json:"data"`
+}
+
+// SignWebhookPayload returns the hex encoded HMAC-SHA256 of a payload, keyed by the webhook secret
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body) // nolint: errcheck
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newWebhook validates the URL and addresses of a webhook, and generates its ID and a secret if it has none
+func newWebhook(rawURL string, addrs []cipher.Address, secret string) (Webhook, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Webhook{}, fmt.Errorf("invalid url: %v", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Webhook{}, errors.New("invalid url: must be an absolute http or https URL")
+	}
+
+	if len(addrs) == 0 {
+		return Webhook{}, errors.New("at least one address is required")
+	}
+
+	if secret == "" {
+		secret = hex.EncodeToString(cipher.RandByte(32))
+	}
+
+	addrStrs := make([]string, len(addrs))
+	for i, a := range addrs {
+		addrStrs[i] = a.String()
+	}
+
+	return Webhook{
+		ID:        hex.EncodeToString(cipher.RandByte(16)),
+		URL:       u.String(),
+		Addresses: addrStrs,
+		Secret:    secret,
+	}, nil
+}
+
+// webhookDelivery is a payload queued for a webhook
+type webhookDelivery struct {
+	id   string
+	body []byte
+}
+
+// webhookWorker delivers the payloads of a webhook in order
+type webhookWorker struct {
+	Webhook
+	addrs map[cipher.Address]struct{}
+	queue chan webhookDelivery
+	quit  chan struct{}
+	done  chan struct{}
+}
+
+// webhookHub sends the notifications of the registered webhooks
+type webhookHub struct {
+	gateway Gatewayer
+	// file persists the registered webhooks. They are only kept in memory if empty
+	file           string
+	client         *http.Client
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+
+	sync.Mutex
+	workers      map[string]*webhookWorker
+	listenerQuit chan struct{}
+	listenerDone chan struct{}
+	shutdown     bool
+}
+
+// newWebhookHub creates a webhookHub and starts the webhooks registered in filename
+func newWebhookHub(gateway Gatewayer, filename string) (*webhookHub, error) {
+	h := &webhookHub{
+		gateway: gateway,
+		file:    filename,
+		client: &http.Client{
+			Timeout: webhookTimeout,
+		},
+		initialBackoff: webhookInitialBackoff,
+		maxBackoff:     webhookMaxBackoff,
+		workers:        make(map[string]*webhookWorker),
+	}
+
+	if filename == "" {
+		return h, nil
+	}
+
+	var webhooks []Webhook
+	if err := file.LoadJSON(filename, &webhooks); err != nil {
+		if os.IsNotExist(err) {
+			return h, nil
+		}
+		return nil, fmt.Errorf("load webhooks file failed: %v", err)
+	}
+
+	h.Lock()
+	defer h.Unlock()
+
+	for _, w := range webhooks {
+		if _, err := h.start(w); err != nil {
+			for _, worker := range h.workers {
+				h.stop(worker)
+			}
+			return nil, fmt.Errorf("invalid webhook %q in %s: %v", w.ID, filename, err)
+		}
+	}
+
+	return h, nil
+}
+
+// start starts the worker of a webhook, and the event listener if it is the first webhook. Must be called with the lock held
+func (h *webhookHub) start(w Webhook) (*webhookWorker, error) {
+	if w.ID == "" || w.Secret == "" {
+		return nil, errors.New("webhook id and secret are required")
+	}
+	if _, ok := h.workers[w.ID]; ok {
+		return nil, fmt.Errorf("duplicate webhook id %q", w.ID)
+	}
+
+	addrs := make(map[cipher.Address]struct{}, len(w.Addresses))
+	for _, a := range w.Addresses {
+		addr, err := cipher.DecodeBase58Address(a)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q: %v", a, err)
+		}
+		addrs[addr] = struct{}{}
+	}
+
+	worker := &webhookWorker{
+		Webhook: w,
+		addrs:   addrs,
+		queue:   make(chan webhookDelivery, webhookQueueSize),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	h.workers[w.ID] = worker
+	go h.deliver(worker)
+
+	if h.listenerQuit == nil {
+		h.listenerQuit = make(chan struct{})
+		h.listenerDone = make(chan struct{})
+
+		// Read the head before subscribing, so that a block executed in between is notified with the next block event
+		headSeq, _, err := h.gateway.HeadBkSeq()
+		if err != nil {
+			logger.WithError(err).Error("webhook hub: gateway.HeadBkSeq failed")
+		}
+
+		// Subscribe before returning, so that the new webhook does not miss events
+		events, unsubscribe := h.gateway.Subscribe()
+		go h.listen(headSeq, events, unsubscribe, h.listenerQuit, h.listenerDone)
+	}
+
+	return worker, nil
+}
+
+// stop stops the worker of a webhook, and the event listener if it was the last webhook. Must be called with the lock held
+func (h *webhookHub) stop(w *webhookWorker) {
+	delete(h.workers, w.ID)
+	close(w.quit)
+
+	if len(h.workers) == 0 && h.listenerQuit != nil {
+		close(h.listenerQuit)
+		h.listenerQuit = nil
+	}
+}
+
+// add registers a webhook created by newWebhook
+func (h *webhookHub) add(w Webhook) error {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.shutdown {
+		return errWebhookHubShutdown
+	}
+
+	if h.gateway.IsReadOnly() {
+		return ErrWebhookReadOnly
+	}
+
+	worker, err := h.start(w)
+	if err != nil {
+		return err
+	}
+
+	if err := h.save(h.webhooks()); err != nil {
+		h.stop(worker)
+		return err
+	}
+
+	logger.Infof("Webhook %s registered for %s", w.ID, w.URL)
+	return nil
+}
+
+// remove unregisters a webhook. Its pending payloads are dropped
+func (h *webhookHub) remove(id string) error {
+	h.Lock()
+	defer h.Unlock()
+
+	w, ok := h.workers[id]
+	if !ok {
+		return ErrWebhookNotFound
+	}
+
+	// Save the list without the webhook before stopping its worker,
+	// so that the webhook keeps running if it can't be removed from the file
+	webhooks := h.webhooks()
+	remaining := webhooks[:0]
+	for _, x := range webhooks {
+		if x.ID != id {
+			remaining = append(remaining, x)
+		}
+	}
+
+	if err := h.save(remaining); err != nil {
+		return err
+	}
+
+	h.stop(w)
+
+	logger.Infof("Webhook %s removed", id)
+	return nil
+}
+
+// list returns the registered webhooks without their secrets, ordered by ID
+func (h *webhookHub) list() []Webhook {
+	h.Lock()
+	defer h.Unlock()
+
+	webhooks := h.webhooks()
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks
+}
+
+// webhooks returns the registered webhooks ordered by ID. Must be called with the lock held
+func (h *webhookHub) webhooks() []Webhook {
+	webhooks := make([]Webhook, 0, len(h.workers))
+	for _, w := range h.workers {
+		webhooks = append(webhooks, w.Webhook)
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].ID < webhooks[j].ID
+	})
+
+	return webhooks
+}
+
+// save writes webhooks to the webhooks file. Must be called with the lock held
+func (h *webhookHub) save(webhooks []Webhook) error {
+	if h.file == "" {
+		return nil
+	}
+
+	return file.SaveJSON(h.file, webhooks, 0600)
+}
+
+// Shutdown stops the event listener and the webhook workers. Pending payloads are dropped
+func (h *webhookHub) Shutdown() {
+	if h == nil {
+		return
+	}
+
+	h.Lock()
+	h.shutdown = true
+	var done []chan struct{}
+	for _, w := range h.workers {
+		done = append(done, w.done)
+		h.stop(w)
+	}
+	if h.listenerDone != nil {
+		done = append(done, h.listenerDone)
+	}
+	h.Unlock()
+
+	for _, d := range done {
+		<-d
+	}
+}
+
+// listen queues the notifications for visor events until quit is closed.
+// headSeq is the head block seq read before subscribing to events
+func (h *webhookHub) listen(headSeq uint64, events <-chan visor.Event, unsubscribe func(), quit, done chan struct{}) {
+	defer close(done)
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-quit:
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+
+			switch e.Type {
+			case visor.EventBlockExecuted:
+				headSeq = h.notifyBlocks(headSeq)
+			case visor.EventUnconfirmedInjected:
+				h.notifyUnconfirmed(e.Txids)
+			}
+		}
+	}
+}
+
+// notifyBlocks queues the confirmation notifications for the blocks after lastSeq up to the current head,
+// and returns the new last seq. Reading up to the head catches up on any events dropped by the visor.
+func (h *webhookHub) notifyBlocks(lastSeq uint64) uint64 {
+	headSeq, ok, err := h.gateway.HeadBkSeq()
+	if err != nil {
+		logger.WithError(err).Error("webhook hub: gateway.HeadBkSeq failed")
+		return lastSeq
+	}
+	if !ok {
+		return lastSeq
+	}
+
+	for lastSeq < headSeq {
+		end := headSeq
+		if end-lastSeq > wsMaxBlocksPerRead {
+			end = lastSeq + wsMaxBlocksPerRead
+		}
+
+		blocks, inputs, err := h.gateway.GetBlocksInRangeVerbose(lastSeq+1, end)
+		if err != nil {
+			logger.WithError(err).Error("webhook hub: gateway.GetBlocksInRangeVerbose failed")
+			return lastSeq
+		}
+		if len(blocks) == 0 {
+			return lastSeq
+		}
+
+		for i, b := range blocks {
+			for j, txn := range b.Block.Body.Transactions {
+				addrs := transactionAddresses(txn, inputs[i][j])
+				if !h.watches(addrs) {
+					continue
+				}
+
+				rt, err := readable.NewBlockTransactionVerbose(txn, inputs[i][j], b.Block.Head.BkSeq == 0)
+				if err != nil {
+					logger.WithError(err).Error("webhook hub: readable.NewBlockTransactionVerbose failed")
+					continue
+				}
+
+				h.queue(WebhookEventConfirmation, txn.Hash(), WebsocketConfirmedTransaction{
+					BlockSeq:    b.Block.Head.BkSeq,
+					BlockHash:   b.Block.HashHeader().Hex(),
+					Transaction: rt,
+				}, addrs)
+			}
+
+			lastSeq = b.Block.Head.BkSeq
+		}
+	}
+
+	return lastSeq
+}
+
+// notifyUnconfirmed queues the deposit notifications for transactions injected into the unconfirmed pool.
+// Transactions which already left the pool are skipped.
+func (h *webhookHub) notifyUnconfirmed(txids []cipher.SHA256) {
+	injected := make(map[cipher.SHA256]struct{}, len(txids))
+	for _, txid := range txids {
+		injected[txid] = struct{}{}
+	}
+
+	txns, inputs, err := h.gateway.GetUnconfirmedTransactionsVerbose(func(txn visor.UnconfirmedTransaction) bool {
+		_, ok := injected[txn.Transaction.Hash()]
+		return ok
+	})
+	if err != nil {
+		logger.WithError(err).Error("webhook hub: gateway.GetUnconfirmedTransactionsVerbose failed")
+		return
+	}
+
+	for i := range txns {
+		// Only the outputs of a deposit are matched
+		addrs := make(map[cipher.Address]struct{}, len(txns[i].Transaction.Out))
+		for _, o := range txns[i].Transaction.Out {
+			addrs[o.Address] = struct{}{}
+		}
+		if !h.watches(addrs) {
+			continue
+		}
+
+		rt, err := readable.NewUnconfirmedTransactionVerbose(&txns[i], inputs[i])
+		if err != nil {
+			logger.WithError(err).Error("webhook hub: readable.NewUnconfirmedTransactionVerbose failed")
+			continue
+		}
+
+		h.queue(WebhookEventDeposit, txns[i].Transaction.Hash(), rt, addrs)
+	}
+}
+
+// watches returns true if a webhook is registered for one of the addresses
+func (h *webhookHub) watches(addrs map[cipher.Address]struct{}) bool {
+	h.Lock()
+	defer h.Unlock()
+
+	for _, w := range h.workers {
+		if len(w.matches(addrs)) != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matches returns the registered addresses of the webhook which are in addrs, sorted
+func (w *webhookWorker) matches(addrs map[cipher.Address]struct{}) []string {
+	var matched []string
+	for a := range addrs {
+		if _, ok := w.addrs[a]; ok {
+			matched = append(matched, a.String())
+		}
+	}
+
+	sort.Strings(matched)
+	return matched
+}
+
+// queue queues a notification for each webhook registered for one of the addresses
+func (h *webhookHub) queue(eventType string, txid cipher.SHA256, data interface{}, addrs map[cipher.Address]struct{}) {
+	h.Lock()
+	defer h.Unlock()
+
+	now := time.Now().Unix()
+	id := eventType + ":" + txid.Hex()
+
+	for _, w := range h.workers {
+		matched := w.matches(addrs)
+		if len(matched) == 0 {
+			continue
+		}
+
+		body, err := json.Marshal(WebhookPayload{
+			ID:        id,
+			WebhookID: w.ID,
+			Type:      eventType,
+			Timestamp: now,
+			Addresses: matched,
+			Data:      data,
+		})
+		if err != nil {
+			logger.WithError(err).Error("webhook json.Marshal payload failed")
+			continue
+		}
+
+		select {
+		case w.queue <- webhookDelivery{
+			id:   id,
+			body: body,
+		}:
+		default:
+			logger.Warningf("Webhook %s queue is full, dropping notification %s", w.ID, id)
+		}
+	}
+}
+
+// deliver sends the queued payloads of a webhook in order, retrying with exponential backoff, until the webhook is stopped
+func (h *webhookHub) deliver(w *webhookWorker) {
+	defer close(w.done)
+
+	// Abort the request in progress when the webhook is stopped
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-w.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		select {
+		case <-w.quit:
+			return
+		case d := <-w.queue:
+			backoff := h.initialBackoff
+			for attempt := 1; ; attempt++ {
+				err := h.post(ctx, w.Webhook, d.body)
+				if err == nil {
+					break
+				}
+
+				if attempt == webhookMaxAttempts {
+					logger.WithError(err).Errorf("Webhook %s notification %s failed after %d attempts, dropping it", w.ID, d.id, attempt)
+					break
+				}
+
+				logger.WithError(err).Warningf("Webhook %s notification %s failed, retrying in %s", w.ID, d.id, backoff)
+
+				select {
+				case <-w.quit:
+					return
+				case <-time.After(backoff):
+				}
+
+				backoff *= 2
+				if backoff > h.maxBackoff {
+					backoff = h.maxBackoff
+				}
+			}
+		}
+	}
+}
+
+// post sends a signed payload to a webhook. Any response status other than 2xx is an error
+func (h *webhookHub) post(ctx context.Context, w Webhook, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+
+	req.Header.Set("Content-Type", ContentTypeJSON)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(w.Secret, body))
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Read the body so that the connection can be reused
+	if _, err := io.Copy(ioutil.Discard, resp.Body); err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// webhooksHandler lists, registers and removes the webhooks notified of address activity
+// URI: /api/v1/admin/webhooks
+// Method: GET, POST, DELETE
+// Args:
+//
+//	url: URL to POST the notifications to [POST]
+//	addrs: comma-separated list of addresses to notify the activity of [POST]
+//	secret: key of the payload signatures. A random secret is generated if not provided [POST, optional]
+//	id: ID of the webhook to remove [DELETE]
+//
+// POST returns the registered webhook with its secret. GET returns the webhooks without their secrets.
+func webhooksHandler(hub *webhookHub) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			wh.SendJSONOr500(logger, w, hub.list())
+		case http.MethodPost:
+			rawURL := r.FormValue("url")
+			if rawURL == "" {
+				wh.Error400(w, "url is required")
+				return
+			}
+
+			addrs, err := parseAddressesFromStr(r.FormValue("addrs"))
+			if err != nil {
+				wh.Error400(w, fmt.Sprintf("parse parameter: 'addrs' failed: %v", err))
+				return
+			}
+
+			webhook, err := newWebhook(rawURL, addrs, r.FormValue("secret"))
+			if err != nil {
+				wh.Error400(w, err.Error())
+				return
+			}
+
+			if err := hub.add(webhook); err != nil {
+				switch err {
+				case ErrWebhookReadOnly:
+					wh.Error403(w, err.Error())
+				default:
+					wh.Error500(w, err.Error())
+				}
+				return
+			}
+
+			wh.SendJSONOr500(logger, w, webhook)
+		case http.MethodDelete:
+			id := r.FormValue("id")
+			if id == "" {
+				wh.Error400(w, "id is required")
+				return
+			}
+
+			if err := hub.remove(id); err != nil {
+				switch err {
+				case ErrWebhookNotFound:
+					wh.Error404(w, "")
+				default:
+					wh.Error500(w, err.Error())
+				}
+				return
+			}
+
+			wh.SendJSONOr500(logger, w, struct{}{})
+		default:
+			wh.Error405(w)
+		}
+	}
+}
@@ -0,0 +1,450 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/visor"
+)
+
+// webhookRequest is a request received by a webhook receiver
+type webhookRequest struct {
+	signature string
+	body      []byte
+}
+
+// newWebhookReceiver returns a server which records the webhook requests it receives,
+// and fails the first failures requests
+func newWebhookReceiver(t *testing.T, failures int) (*httptest.Server, <-chan webhookRequest) {
+	requests := make(chan webhookRequest, 16)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, ContentTypeJSON, r.Header.Get("Content-Type"))
+
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		requests <- webhookRequest{
+			signature: r.Header.Get(WebhookSignatureHeader),
+			body:      body,
+		}
+
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+
+	return s, requests
+}
+
+func readWebhookRequest(t *testing.T, requests <-chan webhookRequest, secret string) WebhookPayload {
+	select {
+	case r := <-requests:
+		require.Equal(t, SignWebhookPayload(secret, r.body), r.signature)
+
+		var p WebhookPayload
+		require.NoError(t, json.Unmarshal(r.body, &p))
+		return p
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook request not received")
+		return WebhookPayload{}
+	}
+}
+
+func TestNewWebhook(t *testing.T) {
+	addr := makeAddress()
+
+	w, err := newWebhook("https://example.com/hook", []cipher.Address{addr}, "")
+	require.NoError(t, err)
+	require.Len(t, w.ID, 32)
+	require.Len(t, w.Secret, 64)
+	require.Equal(t, "https://example.com/hook", w.URL)
+	require.Equal(t, []string{addr.String()}, w.Addresses)
+
+	w2, err := newWebhook("http://127.0.0.1:8080/hook", []cipher.Address{addr}, "secret")
+	require.NoError(t, err)
+	require.Equal(t, "secret", w2.Secret)
+	require.NotEqual(t, w.ID, w2.ID)
+
+	_, err = newWebhook("example.com/hook", []cipher.Address{addr}, "")
+	require.EqualError(t, err, "invalid url: must be an absolute http or https URL")
+
+	_, err = newWebhook("ftp://example.com/hook", []cipher.Address{addr}, "")
+	require.EqualError(t, err, "invalid url: must be an absolute http or https URL")
+
+	_, err = newWebhook("https://example.com/hook", nil, "")
+	require.EqualError(t, err, "at least one address is required")
+}
+
+func TestWebhookHubEvents(t *testing.T) {
+	gateway := &MockGatewayer{}
+	gateway.On("IsReadOnly").Return(false)
+	hub, err := newWebhookHub(gateway, "")
+	require.NoError(t, err)
+	hub.initialBackoff = time.Millisecond
+	defer hub.Shutdown()
+
+	events := make(chan visor.Event, 4)
+	unsubscribed := make(chan struct{})
+	gateway.On("Subscribe").Return((<-chan visor.Event)(events), func() {
+		close(unsubscribed)
+	}).Once()
+
+	// The head is read once when subscribing, then for every block event
+	gateway.On("HeadBkSeq").Return(uint64(0), true, nil).Once()
+	gateway.On("HeadBkSeq").Return(uint64(1), true, nil)
+
+	txn := makeTransaction(t)
+	inputAddr := makeAddress()
+	inputs := []visor.TransactionInput{
+		{
+			UxOut: coin.UxOut{
+				Body: coin.UxBody{
+					Address: inputAddr,
+					Coins:   6e6,
+					Hours:   100,
+				},
+			},
+			CalculatedHours: 100,
+		},
+	}
+	b := coin.SignedBlock{
+		Block: coin.Block{
+			Head: coin.BlockHeader{
+				BkSeq: 1,
+			},
+			Body: coin.BlockBody{
+				Transactions: coin.Transactions{txn},
+			},
+		},
+	}
+	gateway.On("GetBlocksInRangeVerbose", uint64(1), uint64(1)).Return([]coin.SignedBlock{b}, [][][]visor.TransactionInput{{inputs}}, nil).Once()
+
+	deposit := visor.UnconfirmedTransaction{
+		Transaction: makeTransaction(t),
+	}
+	// Spends from a registered address without depositing to one
+	spend := visor.UnconfirmedTransaction{
+		Transaction: makeTransaction(t),
+	}
+	for _, utxn := range []visor.UnconfirmedTransaction{spend, deposit} {
+		utxn := utxn
+		gateway.On("GetUnconfirmedTransactionsVerbose", mock.MatchedBy(func(f func(visor.UnconfirmedTransaction) bool) bool {
+			return f(utxn)
+		})).Return([]visor.UnconfirmedTransaction{utxn}, [][]visor.TransactionInput{inputs}, nil).Once()
+	}
+
+	// The receiver fails the first attempt
+	s, requests := newWebhookReceiver(t, 1)
+	defer s.Close()
+
+	depositAddr := deposit.Transaction.Out[1].Address
+	w, err := newWebhook(s.URL, []cipher.Address{inputAddr, depositAddr}, "secret")
+	require.NoError(t, err)
+	require.NoError(t, hub.add(w))
+
+	// A block event notifies the confirmed transactions touching the registered addresses
+	events <- visor.Event{
+		Type:  visor.EventBlockExecuted,
+		Block: &b,
+	}
+
+	p := readWebhookRequest(t, requests, "secret")
+	retried := readWebhookRequest(t, requests, "secret")
+	require.Equal(t, p, retried)
+
+	require.Equal(t, "confirmation:"+txn.Hash().Hex(), p.ID)
+	require.Equal(t, w.ID, p.WebhookID)
+	require.Equal(t, WebhookEventConfirmation, p.Type)
+	require.Equal(t, []string{inputAddr.String()}, p.Addresses)
+	var confirmed WebsocketConfirmedTransaction
+	data, err := json.Marshal(p.Data)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &confirmed))
+	require.Equal(t, uint64(1), confirmed.BlockSeq)
+	require.Equal(t, b.HashHeader().Hex(), confirmed.BlockHash)
+	require.Equal(t, txn.Hash().Hex(), confirmed.Transaction.Hash)
+
+	// Unconfirmed transactions are only notified if they deposit to a registered address
+	events <- visor.Event{
+		Type:  visor.EventUnconfirmedInjected,
+		Txids: []cipher.SHA256{spend.Transaction.Hash()},
+	}
+	events <- visor.Event{
+		Type:  visor.EventUnconfirmedInjected,
+		Txids: []cipher.SHA256{deposit.Transaction.Hash()},
+	}
+
+	p = readWebhookRequest(t, requests, "secret")
+	require.Equal(t, "deposit:"+deposit.Transaction.Hash().Hex(), p.ID)
+	require.Equal(t, WebhookEventDeposit, p.Type)
+	require.Equal(t, []string{depositAddr.String()}, p.Addresses)
+
+	// The hub unsubscribes from the visor events when the last webhook is removed
+	require.NoError(t, hub.remove(w.ID))
+	require.Equal(t, ErrWebhookNotFound, hub.remove(w.ID))
+	select {
+	case <-unsubscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook hub did not unsubscribe")
+	}
+
+	gateway.AssertExpectations(t)
+}
+
+func TestWebhookHubBlockExecutedWhileSubscribing(t *testing.T) {
+	gateway := &MockGatewayer{}
+	gateway.On("IsReadOnly").Return(false)
+	hub, err := newWebhookHub(gateway, "")
+	require.NoError(t, err)
+	defer hub.Shutdown()
+
+	txn := makeTransaction(t)
+	b := coin.SignedBlock{
+		Block: coin.Block{
+			Head: coin.BlockHeader{
+				BkSeq: 1,
+			},
+			Body: coin.BlockBody{
+				Transactions: coin.Transactions{txn},
+			},
+		},
+	}
+
+	// Block 1 is executed right after the hub subscribes, before the listener handles any event
+	var headSeq uint64
+	events := make(chan visor.Event, 1)
+	gateway.On("Subscribe").Run(func(args mock.Arguments) {
+		atomic.StoreUint64(&headSeq, 1)
+		events <- visor.Event{
+			Type:  visor.EventBlockExecuted,
+			Block: &b,
+		}
+	}).Return((<-chan visor.Event)(events), func() {}).Once()
+	gateway.On("HeadBkSeq").Return(func() uint64 {
+		return atomic.LoadUint64(&headSeq)
+	}, true, nil)
+	inputs := []visor.TransactionInput{
+		{
+			UxOut: coin.UxOut{
+				Body: coin.UxBody{
+					Address: makeAddress(),
+					Coins:   6e6,
+					Hours:   100,
+				},
+			},
+			CalculatedHours: 100,
+		},
+	}
+	gateway.On("GetBlocksInRangeVerbose", uint64(1), uint64(1)).Return([]coin.SignedBlock{b}, [][][]visor.TransactionInput{{inputs}}, nil).Once()
+
+	s, requests := newWebhookReceiver(t, 0)
+	defer s.Close()
+
+	addr := txn.Out[0].Address
+	w, err := newWebhook(s.URL, []cipher.Address{addr}, "")
+	require.NoError(t, err)
+	require.NoError(t, hub.add(w))
+
+	p := readWebhookRequest(t, requests, w.Secret)
+	require.Equal(t, "confirmation:"+txn.Hash().Hex(), p.ID)
+	require.Equal(t, []string{addr.String()}, p.Addresses)
+
+	gateway.AssertExpectations(t)
+}
+
+func TestWebhookDeliveryGivesUp(t *testing.T) {
+	gateway := &MockGatewayer{}
+	gateway.On("IsReadOnly").Return(false)
+	gateway.On("Subscribe").Return((<-chan visor.Event)(make(chan visor.Event)), func() {})
+	gateway.On("HeadBkSeq").Return(uint64(0), true, nil)
+
+	hub, err := newWebhookHub(gateway, "")
+	require.NoError(t, err)
+	hub.initialBackoff = time.Millisecond
+	hub.maxBackoff = time.Millisecond * 2
+	defer hub.Shutdown()
+
+	s, requests := newWebhookReceiver(t, webhookMaxAttempts)
+	defer s.Close()
+
+	addr := makeAddress()
+	w, err := newWebhook(s.URL, []cipher.Address{addr}, "")
+	require.NoError(t, err)
+	require.NoError(t, hub.add(w))
+
+	addrs := map[cipher.Address]struct{}{
+		addr: {},
+	}
+	hub.queue(WebhookEventDeposit, cipher.SHA256{1}, nil, addrs)
+	hub.queue(WebhookEventDeposit, cipher.SHA256{2}, nil, addrs)
+
+	// The first notification is dropped after the maximum number of attempts, then the next one is sent
+	for i := 0; i < webhookMaxAttempts; i++ {
+		p := readWebhookRequest(t, requests, w.Secret)
+		require.Equal(t, "deposit:"+cipher.SHA256{1}.Hex(), p.ID)
+	}
+
+	p := readWebhookRequest(t, requests, w.Secret)
+	require.Equal(t, "deposit:"+cipher.SHA256{2}.Hex(), p.ID)
+}
+
+func TestWebhookHubRemoveSaveFails(t *testing.T) {
+	gateway := &MockGatewayer{}
+	gateway.On("IsReadOnly").Return(false)
+	gateway.On("Subscribe").Return((<-chan visor.Event)(make(chan visor.Event)), func() {})
+	gateway.On("HeadBkSeq").Return(uint64(0), true, nil)
+
+	dir, err := ioutil.TempDir("", "webhooks")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	hub, err := newWebhookHub(gateway, filepath.Join(dir, "webhooks.json"))
+	require.NoError(t, err)
+	defer hub.Shutdown()
+
+	w, err := newWebhook("https://example.com/hook", []cipher.Address{makeAddress()}, "")
+	require.NoError(t, err)
+	require.NoError(t, hub.add(w))
+
+	// The webhook keeps running if the file without it can't be written
+	hub.file = filepath.Join(dir, "missing", "webhooks.json")
+	require.Error(t, hub.remove(w.ID))
+	require.Equal(t, []Webhook{w}, hub.webhooks())
+	require.Contains(t, hub.workers, w.ID)
+	require.NotNil(t, hub.listenerQuit)
+}
+
+func TestWebhooksHandler(t *testing.T) {
+	gateway := &MockGatewayer{}
+	gateway.On("IsReadOnly").Return(false)
+	gateway.On("Subscribe").Return((<-chan visor.Event)(make(chan visor.Event)), func() {})
+	gateway.On("HeadBkSeq").Return(uint64(0), true, nil)
+
+	dir, err := ioutil.TempDir("", "webhooks")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "webhooks.json")
+	hub, err := newWebhookHub(gateway, filename)
+	require.NoError(t, err)
+	defer hub.Shutdown()
+
+	mc := defaultMuxConfig()
+	mc.webhookHub = hub
+	handler := newServerMux(mc, gateway)
+
+	do := func(method string, v url.Values) *httptest.ResponseRecorder {
+		endpoint := "/api/v1/admin/webhooks"
+		var body *strings.Reader
+		if method == http.MethodPost {
+			body = strings.NewReader(v.Encode())
+		} else {
+			endpoint += "?" + v.Encode()
+			body = strings.NewReader("")
+		}
+
+		req, err := http.NewRequest(method, endpoint, body)
+		require.NoError(t, err)
+		if method == http.MethodPost {
+			req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		}
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do(http.MethodPut, nil)
+	require.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+
+	rr = do(http.MethodPost, url.Values{"addrs": {makeAddress().String()}})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Equal(t, "400 Bad Request - url is required", strings.TrimSpace(rr.Body.String()))
+
+	rr = do(http.MethodPost, url.Values{"url": {"https://example.com/hook"}, "addrs": {"foo"}})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = do(http.MethodPost, url.Values{"url": {"https://example.com/hook"}})
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Equal(t, "400 Bad Request - at least one address is required", strings.TrimSpace(rr.Body.String()))
+
+	// Registering returns the webhook with its secret
+	addr := makeAddress()
+	rr = do(http.MethodPost, url.Values{"url": {"https://example.com/hook"}, "addrs": {addr.String()}})
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var w Webhook
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &w))
+	require.NotEmpty(t, w.ID)
+	require.NotEmpty(t, w.Secret)
+	require.Equal(t, []string{addr.String()}, w.Addresses)
+
+	// Listing omits the secrets
+	rr = do(http.MethodGet, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var webhooks []Webhook
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &webhooks))
+	listed := w
+	listed.Secret = ""
+	require.Equal(t, []Webhook{listed}, webhooks)
+
+	// The webhooks are persisted
+	reloaded, err := newWebhookHub(gateway, filename)
+	require.NoError(t, err)
+	require.Equal(t, []Webhook{w}, reloaded.webhooks())
+	reloaded.Shutdown()
+
+	rr = do(http.MethodDelete, nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Equal(t, "400 Bad Request - id is required", strings.TrimSpace(rr.Body.String()))
+
+	rr = do(http.MethodDelete, url.Values{"id": {w.ID}})
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	rr = do(http.MethodDelete, url.Values{"id": {w.ID}})
+	require.Equal(t, http.StatusNotFound, rr.Code)
+
+	reloaded, err = newWebhookHub(gateway, filename)
+	require.NoError(t, err)
+	require.Empty(t, reloaded.webhooks())
+	reloaded.Shutdown()
+}
+
+func TestWebhooksHandlerReadOnly(t *testing.T) {
+	gateway := &MockGatewayer{}
+	gateway.On("IsReadOnly").Return(true)
+
+	hub, err := newWebhookHub(gateway, "")
+	require.NoError(t, err)
+	defer hub.Shutdown()
+
+	mc := defaultMuxConfig()
+	mc.webhookHub = hub
+	handler := newServerMux(mc, gateway)
+
+	v := url.Values{"url": {"https://example.com/hook"}, "addrs": {makeAddress().String()}}
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/admin/webhooks", strings.NewReader(v.Encode()))
+	require.NoError(t, err)
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusForbidden, rr.Code)
+	require.Equal(t, "403 Forbidden - webhooks can't be registered on a read-only node", strings.TrimSpace(rr.Body.String()))
+	require.Empty(t, hub.webhooks())
+}
@@ -0,0 +1,557 @@
+package api
+
+// Websocket subscription API for new blocks and transactions.
+// Events are pushed as they are published by the visor, see visor.Event.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/readable"
+	wh "github.com/SkycoinProject/cx-chains/src/util/http"
+	"github.com/SkycoinProject/cx-chains/src/visor"
+)
+
+const (
+	// wsMaxMessageSize is the maximum size of a message accepted from a client
+	wsMaxMessageSize = 64 * 1024
+	// wsMaxSubscriptions is the maximum number of addresses a single client can subscribe to
+	wsMaxSubscriptions = 1024
+	// wsSendBufferSize is the number of events buffered per client before it is considered too slow and dropped
+	wsSendBufferSize = 256
+	// wsWriteTimeout is the time allowed to write a message to a client
+	wsWriteTimeout = time.Second * 10
+	// wsPongWait is the time allowed to read the next pong from a client
+	wsPongWait = time.Second * 60
+	// wsPingInterval is how often pings are sent to a client. Must be less than wsPongWait
+	wsPingInterval = time.Second * 30
+	// wsMaxBlocksPerRead is the maximum number of new blocks to read from the gateway at once
+	wsMaxBlocksPerRead = 100
+
+	// WebsocketEventBlock is sent for every new block
+	WebsocketEventBlock = "block"
+	// WebsocketEventConfirmedTransaction is sent for every confirmed transaction touching a subscribed address
+	WebsocketEventConfirmedTransaction = "transaction_confirmed"
+	// WebsocketEventUnconfirmedTransaction is sent for every new unconfirmed transaction touching a subscribed address
+	WebsocketEventUnconfirmedTransaction = "transaction_unconfirmed"
+	// WebsocketEventSubscriptions is sent in response to a subscribe or unsubscribe request
+	WebsocketEventSubscriptions = "subscriptions"
+	// WebsocketEventError is sent when a client request is invalid
+	WebsocketEventError = "error"
+)
+
+var (
+	errWebsocketHubShutdown = errors.New("websocket hub is shut down")
+
+	wsUpgrader = websocket.Upgrader{
+		// The Origin header is already checked by originRefererCheck
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+		Error: func(w http.ResponseWriter, r *http.Request, status int, reason error) {
+			w.Header().Set("Sec-WebSocket-Version", "13")
+			wh.ErrorXXX(w, status, reason.Error())
+		},
+	}
+)
+
+// WebsocketEvent is a JSON message pushed to websocket subscribers
+type WebsocketEvent struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// WebsocketRequest is a JSON message sent by a websocket client to change its subscriptions
+type WebsocketRequest struct {
+	// Type is "subscribe" or "unsubscribe"
+	Type      string   `json:"type"`
+	Addresses []string `json:"addresses"`
+}
+
+// WebsocketConfirmedTransaction is the data of a transaction_confirmed event
+type WebsocketConfirmedTransaction struct {
+	BlockSeq    uint64                           `json:"block_seq"`
+	BlockHash   string                           `json:"block_hash"`
+	Transaction readable.BlockTransactionVerbose `json:"transaction"`
+}
+
+// WebsocketSubscriptions is the data of a subscriptions event
+type WebsocketSubscriptions struct {
+	Addresses []string `json:"addresses"`
+}
+
+// wsHandler upgrades the connection to a websocket and pushes events to it
+// URI: /api/v1/ws
+// Method: GET
+// Args:
+//
+//	addrs: [optional] comma-separated list of addresses to subscribe to
+//
+// Once connected, the client can change its subscriptions by sending
+// {"type": "subscribe", "addresses": [...]} or {"type": "unsubscribe", "addresses": [...]}.
+// Block events are sent to all clients, transaction events only for subscribed addresses.
+func wsHandler(hub *wsHub) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
+		addrs, err := parseAddressesFromStr(r.FormValue("addrs"))
+		if err != nil {
+			wh.Error400(w, err.Error())
+			return
+		}
+
+		if len(addrs) > wsMaxSubscriptions {
+			wh.Error400(w, fmt.Sprintf("too many addresses, maximum is %d", wsMaxSubscriptions))
+			return
+		}
+
+		conn, err := wsUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			logger.WithError(err).Info("websocket Upgrade failed")
+			return
+		}
+
+		client := newWSClient(conn, addrs)
+		if err := hub.register(client); err != nil {
+			client.close()
+			return
+		}
+		defer hub.unregister(client)
+
+		go client.writeLoop()
+		client.readLoop()
+	}
+}
+
+// wsClient is a websocket subscriber
+type wsClient struct {
+	conn      *websocket.Conn
+	send      chan []byte
+	quit      chan struct{}
+	closeOnce sync.Once
+
+	addrsLock sync.RWMutex
+	addrs     map[cipher.Address]struct{}
+}
+
+func newWSClient(conn *websocket.Conn, addrs []cipher.Address) *wsClient {
+	c := &wsClient{
+		conn:  conn,
+		send:  make(chan []byte, wsSendBufferSize),
+		quit:  make(chan struct{}),
+		addrs: make(map[cipher.Address]struct{}, len(addrs)),
+	}
+
+	for _, a := range addrs {
+		c.addrs[a] = struct{}{}
+	}
+
+	return c
+}
+
+// subscribedTo returns true if the client is subscribed to any of the addresses
+func (c *wsClient) subscribedTo(addrs map[cipher.Address]struct{}) bool {
+	c.addrsLock.RLock()
+	defer c.addrsLock.RUnlock()
+
+	for a := range addrs {
+		if _, ok := c.addrs[a]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// push queues an event for sending. If the client's buffer is full, the client is disconnected
+func (c *wsClient) push(msg []byte) {
+	select {
+	case c.send <- msg:
+	default:
+		logger.Info("websocket client is too slow, disconnecting")
+		c.close()
+	}
+}
+
+func (c *wsClient) pushEvent(eventType string, data interface{}) {
+	msg, err := json.Marshal(WebsocketEvent{
+		Type: eventType,
+		Data: data,
+	})
+	if err != nil {
+		logger.WithError(err).Error("websocket json.Marshal event failed")
+		return
+	}
+
+	c.push(msg)
+}
+
+func (c *wsClient) close() {
+	c.closeOnce.Do(func() {
+		if err := c.conn.Close(); err != nil {
+			logger.WithError(err).Debug("websocket conn.Close failed")
+		}
+	})
+}
+
+// writeLoop writes queued events and periodic pings to the connection.
+// It is the only writer of data messages to the connection.
+func (c *wsClient) writeLoop() {
+	defer c.close()
+
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.quit:
+			return
+		case msg := <-c.send:
+			if err := c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout)); err != nil {
+				logger.WithError(err).Debug("websocket SetWriteDeadline failed")
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				logger.WithError(err).Debug("websocket write failed")
+				return
+			}
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				logger.WithError(err).Debug("websocket ping failed")
+				return
+			}
+		}
+	}
+}
+
+// readLoop reads subscription requests until the connection is closed.
+// Control frames (ping, pong and close) are handled by the websocket.Conn while reading.
+func (c *wsClient) readLoop() {
+	defer close(c.quit)
+	defer c.close()
+
+	c.conn.SetReadLimit(wsMaxMessageSize)
+	if err := c.conn.SetReadDeadline(time.Now().Add(wsPongWait)); err != nil {
+		logger.WithError(err).Debug("websocket SetReadDeadline failed")
+		return
+	}
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		_, msg, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				logger.WithError(err).Debug("websocket read failed")
+			}
+			return
+		}
+
+		var req WebsocketRequest
+		if err := json.Unmarshal(msg, &req); err != nil {
+			c.pushEvent(WebsocketEventError, "invalid request: "+err.Error())
+			continue
+		}
+
+		if err := c.handleRequest(req); err != nil {
+			c.pushEvent(WebsocketEventError, err.Error())
+			continue
+		}
+
+		c.pushEvent(WebsocketEventSubscriptions, c.subscriptions())
+	}
+}
+
+func (c *wsClient) handleRequest(req WebsocketRequest) error {
+	addrs := make([]cipher.Address, len(req.Addresses))
+	for i, s := range req.Addresses {
+		a, err := cipher.DecodeBase58Address(s)
+		if err != nil {
+			return fmt.Errorf("address %q is invalid: %v", s, err)
+		}
+		addrs[i] = a
+	}
+
+	c.addrsLock.Lock()
+	defer c.addrsLock.Unlock()
+
+	switch req.Type {
+	case "subscribe":
+		for _, a := range addrs {
+			c.addrs[a] = struct{}{}
+		}
+		if len(c.addrs) > wsMaxSubscriptions {
+			for _, a := range addrs {
+				delete(c.addrs, a)
+			}
+			return fmt.Errorf("too many addresses, maximum is %d", wsMaxSubscriptions)
+		}
+	case "unsubscribe":
+		for _, a := range addrs {
+			delete(c.addrs, a)
+		}
+	default:
+		return fmt.Errorf("invalid request type %q", req.Type)
+	}
+
+	return nil
+}
+
+func (c *wsClient) subscriptions() WebsocketSubscriptions {
+	c.addrsLock.RLock()
+	defer c.addrsLock.RUnlock()
+
+	addrs := make([]string, 0, len(c.addrs))
+	for a := range c.addrs {
+		addrs = append(addrs, a.String())
+	}
+
+	return WebsocketSubscriptions{
+		Addresses: addrs,
+	}
+}
+
+// wsHub tracks websocket clients and, while any client is connected,
+// subscribes to the visor events for new blocks and unconfirmed transactions
+type wsHub struct {
+	gateway Gatewayer
+
+	sync.Mutex
+	clients      map[*wsClient]struct{}
+	listenerQuit chan struct{}
+	listenerDone chan struct{}
+	shutdown     bool
+}
+
+func newWSHub(gateway Gatewayer) *wsHub {
+	return &wsHub{
+		gateway: gateway,
+		clients: make(map[*wsClient]struct{}),
+	}
+}
+
+// register adds a client, starting the event listener if it is the first one
+func (h *wsHub) register(c *wsClient) error {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.shutdown {
+		return errWebsocketHubShutdown
+	}
+
+	h.clients[c] = struct{}{}
+
+	if h.listenerQuit == nil {
+		h.listenerQuit = make(chan struct{})
+		h.listenerDone = make(chan struct{})
+
+		// Read the head before subscribing, so that a block executed in between is sent with the next block event
+		headSeq, _, err := h.gateway.HeadBkSeq()
+		if err != nil {
+			logger.WithError(err).Error("websocket hub: gateway.HeadBkSeq failed")
+		}
+
+		// Subscribe before returning, so that the new client does not miss events
+		events, unsubscribe := h.gateway.Subscribe()
+		go h.listen(headSeq, events, unsubscribe, h.listenerQuit, h.listenerDone)
+	}
+
+	return nil
+}
+
+// unregister removes a client, stopping the event listener if it was the last one
+func (h *wsHub) unregister(c *wsClient) {
+	h.Lock()
+	defer h.Unlock()
+
+	delete(h.clients, c)
+
+	if len(h.clients) == 0 && h.listenerQuit != nil {
+		close(h.listenerQuit)
+		h.listenerQuit = nil
+	}
+}
+
+// Shutdown disconnects all clients and stops the event listener
+func (h *wsHub) Shutdown() {
+	if h == nil {
+		return
+	}
+
+	h.Lock()
+	h.shutdown = true
+	for c := range h.clients {
+		c.close()
+	}
+	done := h.listenerDone
+	if h.listenerQuit != nil {
+		close(h.listenerQuit)
+		h.listenerQuit = nil
+	}
+	h.Unlock()
+
+	if done != nil {
+		<-done
+	}
+}
+
+// listen sends websocket events for visor events until quit is closed.
+// headSeq is the head block seq read before subscribing to events
+func (h *wsHub) listen(headSeq uint64, events <-chan visor.Event, unsubscribe func(), quit, done chan struct{}) {
+	defer close(done)
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-quit:
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+
+			switch e.Type {
+			case visor.EventBlockExecuted:
+				headSeq = h.sendBlocks(headSeq)
+			case visor.EventUnconfirmedInjected:
+				h.sendUnconfirmed(e.Txids)
+			}
+		}
+	}
+}
+
+// sendBlocks sends events for blocks after lastSeq up to the current head, and returns the new last seq.
+// Reading up to the head instead of only the event's block catches up on any events dropped by the visor.
+func (h *wsHub) sendBlocks(lastSeq uint64) uint64 {
+	headSeq, ok, err := h.gateway.HeadBkSeq()
+	if err != nil {
+		logger.WithError(err).Error("websocket hub: gateway.HeadBkSeq failed")
+		return lastSeq
+	}
+	if !ok {
+		return lastSeq
+	}
+
+	for lastSeq < headSeq {
+		end := headSeq
+		if end-lastSeq > wsMaxBlocksPerRead {
+			end = lastSeq + wsMaxBlocksPerRead
+		}
+
+		blocks, inputs, err := h.gateway.GetBlocksInRangeVerbose(lastSeq+1, end)
+		if err != nil {
+			logger.WithError(err).Error("websocket hub: gateway.GetBlocksInRangeVerbose failed")
+			return lastSeq
+		}
+		if len(blocks) == 0 {
+			return lastSeq
+		}
+
+		for i, b := range blocks {
+			rb, err := readable.NewBlock(b.Block)
+			if err != nil {
+				logger.WithError(err).Error("websocket hub: readable.NewBlock failed")
+				return lastSeq
+			}
+
+			h.broadcast(WebsocketEventBlock, rb, nil)
+
+			for j, txn := range b.Block.Body.Transactions {
+				rt, err := readable.NewBlockTransactionVerbose(txn, inputs[i][j], b.Block.Head.BkSeq == 0)
+				if err != nil {
+					logger.WithError(err).Error("websocket hub: readable.NewBlockTransactionVerbose failed")
+					continue
+				}
+
+				h.broadcast(WebsocketEventConfirmedTransaction, WebsocketConfirmedTransaction{
+					BlockSeq:    b.Block.Head.BkSeq,
+					BlockHash:   b.Block.HashHeader().Hex(),
+					Transaction: rt,
+				}, transactionAddresses(txn, inputs[i][j]))
+			}
+
+			lastSeq = b.Block.Head.BkSeq
+		}
+	}
+
+	return lastSeq
+}
+
+// sendUnconfirmed sends events for transactions injected into the unconfirmed pool.
+// Transactions which already left the pool are skipped.
+func (h *wsHub) sendUnconfirmed(txids []cipher.SHA256) {
+	injected := make(map[cipher.SHA256]struct{}, len(txids))
+	for _, txid := range txids {
+		injected[txid] = struct{}{}
+	}
+
+	txns, inputs, err := h.gateway.GetUnconfirmedTransactionsVerbose(func(txn visor.UnconfirmedTransaction) bool {
+		_, ok := injected[txn.Transaction.Hash()]
+		return ok
+	})
+	if err != nil {
+		logger.WithError(err).Error("websocket hub: gateway.GetUnconfirmedTransactionsVerbose failed")
+		return
+	}
+
+	for i := range txns {
+		rt, err := readable.NewUnconfirmedTransactionVerbose(&txns[i], inputs[i])
+		if err != nil {
+			logger.WithError(err).Error("websocket hub: readable.NewUnconfirmedTransactionVerbose failed")
+			continue
+		}
+
+		h.broadcast(WebsocketEventUnconfirmedTransaction, rt, transactionAddresses(txns[i].Transaction, inputs[i]))
+	}
+}
+
+// broadcast sends an event to all clients. If addrs is not nil, the event is
+// only sent to clients subscribed to at least one of the addresses.
+func (h *wsHub) broadcast(eventType string, data interface{}, addrs map[cipher.Address]struct{}) {
+	h.Lock()
+	defer h.Unlock()
+
+	if len(h.clients) == 0 {
+		return
+	}
+
+	msg, err := json.Marshal(WebsocketEvent{
+		Type: eventType,
+		Data: data,
+	})
+	if err != nil {
+		logger.WithError(err).Error("websocket json.Marshal event failed")
+		return
+	}
+
+	for c := range h.clients {
+		if addrs != nil && !c.subscribedTo(addrs) {
+			continue
+		}
+		c.push(msg)
+	}
+}
+
+// transactionAddresses returns the set of input and output addresses of a transaction
+func transactionAddresses(txn coin.Transaction, inputs []visor.TransactionInput) map[cipher.Address]struct{} {
+	addrs := make(map[cipher.Address]struct{}, len(inputs)+len(txn.Out))
+	for _, in := range inputs {
+		addrs[in.UxOut.Body.Address] = struct{}{}
+	}
+	for _, o := range txn.Out {
+		addrs[o.Address] = struct{}{}
+	}
+	return addrs
+}
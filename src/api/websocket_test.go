@@ -0,0 +1,303 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/readable"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+	"github.com/SkycoinProject/cx-chains/src/visor"
+)
+
+func TestWSClientHandleRequest(t *testing.T) {
+	addr := testutil.MakeAddress()
+	c := newWSClient(nil, nil)
+
+	err := c.handleRequest(WebsocketRequest{
+		Type:      "subscribe",
+		Addresses: []string{addr.String()},
+	})
+	require.NoError(t, err)
+	require.True(t, c.subscribedTo(map[cipher.Address]struct{}{addr: {}}))
+	require.Equal(t, []string{addr.String()}, c.subscriptions().Addresses)
+
+	err = c.handleRequest(WebsocketRequest{
+		Type:      "subscribe",
+		Addresses: []string{"bad"},
+	})
+	require.Error(t, err)
+
+	err = c.handleRequest(WebsocketRequest{
+		Type: "foo",
+	})
+	require.Error(t, err)
+
+	err = c.handleRequest(WebsocketRequest{
+		Type:      "unsubscribe",
+		Addresses: []string{addr.String()},
+	})
+	require.NoError(t, err)
+	require.False(t, c.subscribedTo(map[cipher.Address]struct{}{addr: {}}))
+}
+
+func TestWebsocketHandlerInvalidRequests(t *testing.T) {
+	tt := []struct {
+		name    string
+		method  string
+		query   string
+		headers map[string]string
+		status  int
+	}{
+		{
+			name:   "405",
+			method: http.MethodPost,
+			status: http.StatusMethodNotAllowed,
+		},
+		{
+			name:   "400 invalid address",
+			method: http.MethodGet,
+			query:  "?addrs=foo",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "400 not an upgrade",
+			method: http.MethodGet,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "400 bad version",
+			method: http.MethodGet,
+			headers: map[string]string{
+				"Connection":            "Upgrade",
+				"Upgrade":               "websocket",
+				"Sec-WebSocket-Version": "8",
+				"Sec-WebSocket-Key":     "dGhlIHNhbXBsZSBub25jZQ==",
+			},
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			req, err := http.NewRequest(tc.method, "/api/v1/ws"+tc.query, nil)
+			require.NoError(t, err)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+			require.Equal(t, tc.status, rr.Code)
+		})
+	}
+}
+
+func TestWebsocketEventJSON(t *testing.T) {
+	b, err := json.Marshal(WebsocketEvent{
+		Type: WebsocketEventSubscriptions,
+		Data: WebsocketSubscriptions{
+			Addresses: []string{},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `{"type":"subscriptions","data":{"addresses":[]}}`, string(b))
+}
+
+// readWebsocketEvent reads the next event from conn, with its data left undecoded
+func readWebsocketEvent(t *testing.T, conn *websocket.Conn) (string, json.RawMessage) {
+	err := conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	require.NoError(t, err)
+
+	var e struct {
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+	err = conn.ReadJSON(&e)
+	require.NoError(t, err)
+
+	return e.Type, e.Data
+}
+
+func TestWebsocketHubEvents(t *testing.T) {
+	gateway := &MockGatewayer{}
+	hub := newWSHub(gateway)
+	s := httptest.NewServer(wsHandler(hub))
+	defer s.Close()
+	defer hub.Shutdown()
+
+	events := make(chan visor.Event, 4)
+	unsubscribed := make(chan struct{})
+	gateway.On("Subscribe").Return((<-chan visor.Event)(events), func() {
+		close(unsubscribed)
+	}).Once()
+
+	// The head is read once when subscribing, then for every block event
+	gateway.On("HeadBkSeq").Return(uint64(0), true, nil).Once()
+	gateway.On("HeadBkSeq").Return(uint64(1), true, nil)
+
+	txn := makeTransaction(t)
+	inputs := []visor.TransactionInput{
+		{
+			UxOut: coin.UxOut{
+				Body: coin.UxBody{
+					Address: makeAddress(),
+					Coins:   6e6,
+					Hours:   100,
+				},
+			},
+			CalculatedHours: 100,
+		},
+	}
+	b := coin.SignedBlock{
+		Block: coin.Block{
+			Head: coin.BlockHeader{
+				BkSeq: 1,
+			},
+			Body: coin.BlockBody{
+				Transactions: coin.Transactions{txn},
+			},
+		},
+	}
+	gateway.On("GetBlocksInRangeVerbose", uint64(1), uint64(1)).Return([]coin.SignedBlock{b}, [][][]visor.TransactionInput{{inputs}}, nil).Once()
+
+	subscribed := visor.UnconfirmedTransaction{
+		Transaction: makeTransaction(t),
+	}
+	other := visor.UnconfirmedTransaction{
+		Transaction: makeTransaction(t),
+	}
+	for _, utxn := range []visor.UnconfirmedTransaction{other, subscribed} {
+		utxn := utxn
+		gateway.On("GetUnconfirmedTransactionsVerbose", mock.MatchedBy(func(f func(visor.UnconfirmedTransaction) bool) bool {
+			return f(utxn)
+		})).Return([]visor.UnconfirmedTransaction{utxn}, [][]visor.TransactionInput{inputs}, nil).Once()
+	}
+
+	url := "ws" + strings.TrimPrefix(s.URL, "http") + "/?addrs=" + txn.Out[0].Address.String()
+	conn, rsp, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, rsp.StatusCode)
+	defer conn.Close()
+
+	// Change the subscriptions
+	addr := subscribed.Transaction.Out[1].Address
+	err = conn.WriteJSON(WebsocketRequest{
+		Type:      "subscribe",
+		Addresses: []string{addr.String()},
+	})
+	require.NoError(t, err)
+
+	eventType, data := readWebsocketEvent(t, conn)
+	require.Equal(t, WebsocketEventSubscriptions, eventType)
+	var subs WebsocketSubscriptions
+	require.NoError(t, json.Unmarshal(data, &subs))
+	require.Len(t, subs.Addresses, 2)
+
+	err = conn.WriteJSON(WebsocketRequest{
+		Type: "foo",
+	})
+	require.NoError(t, err)
+	eventType, data = readWebsocketEvent(t, conn)
+	require.Equal(t, WebsocketEventError, eventType)
+	require.Equal(t, `"invalid request type \"foo\""`, string(data))
+
+	// A block event sends the block, and the confirmed transactions touching the subscribed addresses
+	events <- visor.Event{
+		Type:  visor.EventBlockExecuted,
+		Block: &b,
+	}
+
+	eventType, _ = readWebsocketEvent(t, conn)
+	require.Equal(t, WebsocketEventBlock, eventType)
+
+	eventType, data = readWebsocketEvent(t, conn)
+	require.Equal(t, WebsocketEventConfirmedTransaction, eventType)
+	var confirmed WebsocketConfirmedTransaction
+	require.NoError(t, json.Unmarshal(data, &confirmed))
+	require.Equal(t, uint64(1), confirmed.BlockSeq)
+	require.Equal(t, b.HashHeader().Hex(), confirmed.BlockHash)
+	require.Equal(t, txn.Hash().Hex(), confirmed.Transaction.Hash)
+
+	// Unconfirmed transactions are only sent if they touch a subscribed address
+	events <- visor.Event{
+		Type:  visor.EventUnconfirmedInjected,
+		Txids: []cipher.SHA256{other.Transaction.Hash()},
+	}
+	events <- visor.Event{
+		Type:  visor.EventUnconfirmedInjected,
+		Txids: []cipher.SHA256{subscribed.Transaction.Hash()},
+	}
+
+	eventType, data = readWebsocketEvent(t, conn)
+	require.Equal(t, WebsocketEventUnconfirmedTransaction, eventType)
+	var unconfirmed readable.UnconfirmedTransactionVerbose
+	require.NoError(t, json.Unmarshal(data, &unconfirmed))
+	require.Equal(t, subscribed.Transaction.Hash().Hex(), unconfirmed.Transaction.Hash)
+
+	// The hub unsubscribes from the visor events when the last client disconnects
+	require.NoError(t, conn.Close())
+	select {
+	case <-unsubscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("websocket hub did not unsubscribe")
+	}
+
+	gateway.AssertExpectations(t)
+}
+
+func TestWebsocketHubBlockExecutedWhileSubscribing(t *testing.T) {
+	gateway := &MockGatewayer{}
+	hub := newWSHub(gateway)
+	s := httptest.NewServer(wsHandler(hub))
+	defer s.Close()
+	defer hub.Shutdown()
+
+	b := coin.SignedBlock{
+		Block: coin.Block{
+			Head: coin.BlockHeader{
+				BkSeq: 1,
+			},
+		},
+	}
+
+	// Block 1 is executed right after the hub subscribes, before the listener handles any event
+	var headSeq uint64
+	events := make(chan visor.Event, 1)
+	gateway.On("Subscribe").Run(func(args mock.Arguments) {
+		atomic.StoreUint64(&headSeq, 1)
+		events <- visor.Event{
+			Type:  visor.EventBlockExecuted,
+			Block: &b,
+		}
+	}).Return((<-chan visor.Event)(events), func() {}).Once()
+	gateway.On("HeadBkSeq").Return(func() uint64 {
+		return atomic.LoadUint64(&headSeq)
+	}, true, nil)
+	gateway.On("GetBlocksInRangeVerbose", uint64(1), uint64(1)).Return([]coin.SignedBlock{b}, [][][]visor.TransactionInput{{}}, nil).Once()
+
+	url := "ws" + strings.TrimPrefix(s.URL, "http") + "/"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	eventType, data := readWebsocketEvent(t, conn)
+	require.Equal(t, WebsocketEventBlock, eventType)
+	var rb readable.Block
+	require.NoError(t, json.Unmarshal(data, &rb))
+	require.Equal(t, uint64(1), rb.Head.BkSeq)
+
+	gateway.AssertExpectations(t)
+}
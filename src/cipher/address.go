@@ -123,7 +123,7 @@ func AddressFromBytes(b []byte) (Address, error) {
 		return Address{}, ErrAddressInvalidChecksum
 	}
 
-	if a.Version != 0 {
+	if a.Version != 0 && a.Version != AddressVersionMultisig {
 		return Address{}, ErrAddressInvalidVersion
 	}
 
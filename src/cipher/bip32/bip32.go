@@ -497,6 +497,26 @@ func (k *PublicKey) String() string {
 	return base58.Encode(k.Serialize())
 }
 
+// DeserializeEncodedPrivateKey deserializes a base58 xprv key to a PrivateKey
+func DeserializeEncodedPrivateKey(xprv string) (*PrivateKey, error) {
+	b, err := base58.Decode(xprv)
+	if err != nil {
+		return nil, err
+	}
+
+	return DeserializePrivateKey(b)
+}
+
+// DeserializeEncodedPublicKey deserializes a base58 xpub key to a PublicKey
+func DeserializeEncodedPublicKey(xpub string) (*PublicKey, error) {
+	b, err := base58.Decode(xpub)
+	if err != nil {
+		return nil, err
+	}
+
+	return DeserializePublicKey(b)
+}
+
 // DeserializePrivateKey deserializes a byte slice into a PrivateKey
 func DeserializePrivateKey(data []byte) (*PrivateKey, error) {
 	k, err := deserialize(data, true)
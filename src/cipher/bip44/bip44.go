@@ -0,0 +1,105 @@
+/*
+Package bip44 implements the bip44 spec https://github.com/bitcoin/bips/blob/master/bip-0044.mediawiki
+
+A bip44 path has the structure m / purpose' / coin_type' / account' / change / address_index
+*/
+package bip44
+
+import (
+	"errors"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher/bip32"
+)
+
+// CoinType is the coin_type part of the bip44 path
+type CoinType uint32
+
+const (
+	// CoinTypeBitcoin is the coin_type for Bitcoin
+	CoinTypeBitcoin CoinType = 0
+	// CoinTypeBitcoinTestnet is the coin_type for Bitcoin testnet
+	CoinTypeBitcoinTestnet CoinType = 1
+	// CoinTypeSkycoin is the coin_type for Skycoin
+	CoinTypeSkycoin CoinType = 8000
+
+	// Purpose is the bip44 purpose value, the first node of the path
+	Purpose = 44
+
+	// ExternalChainIndex is the index of the external chain, used for receiving addresses
+	ExternalChainIndex = uint32(0)
+	// ChangeChainIndex is the index of the internal chain, used for change addresses
+	ChangeChainIndex = uint32(1)
+)
+
+var (
+	// ErrInvalidCoinType coin_type is >= 0x80000000
+	ErrInvalidCoinType = errors.New("bip44 coin type must be less than 0x80000000")
+	// ErrInvalidAccount account is >= 0x80000000
+	ErrInvalidAccount = errors.New("bip44 account must be less than 0x80000000")
+)
+
+// Coin is a bip32 node at the coin_type level of a bip44 path
+type Coin struct {
+	*bip32.PrivateKey
+}
+
+// NewCoin creates the bip32 node at m/44'/coin_type'.
+// This method can return a bip32 ImpossibleChild error.
+func NewCoin(seed []byte, coinType CoinType) (*Coin, error) {
+	if uint32(coinType) >= bip32.FirstHardenedChild {
+		return nil, ErrInvalidCoinType
+	}
+
+	mk, err := bip32.NewMasterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	purpose, err := mk.NewPrivateChildKey(Purpose + bip32.FirstHardenedChild)
+	if err != nil {
+		return nil, err
+	}
+
+	coin, err := purpose.NewPrivateChildKey(uint32(coinType) + bip32.FirstHardenedChild)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Coin{
+		PrivateKey: coin,
+	}, nil
+}
+
+// Account returns the hardened account node m/44'/coin_type'/account'.
+// This method can return a bip32 ImpossibleChild error.
+func (c *Coin) Account(account uint32) (*Account, error) {
+	if account >= bip32.FirstHardenedChild {
+		return nil, ErrInvalidAccount
+	}
+
+	a, err := c.NewPrivateChildKey(account + bip32.FirstHardenedChild)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Account{
+		PrivateKey: a,
+	}, nil
+}
+
+// Account is a bip32 node at the account level of a bip44 path
+type Account struct {
+	*bip32.PrivateKey
+}
+
+// External returns the external chain node m/44'/coin_type'/account'/0.
+// This method can return a bip32 ImpossibleChild error.
+func (a *Account) External() (*bip32.PrivateKey, error) {
+	return a.NewPrivateChildKey(ExternalChainIndex)
+}
+
+// Change returns the change chain node m/44'/coin_type'/account'/1.
+// This method can return a bip32 ImpossibleChild error.
+func (a *Account) Change() (*bip32.PrivateKey, error) {
+	return a.NewPrivateChildKey(ChangeChainIndex)
+}
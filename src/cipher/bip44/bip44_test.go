@@ -0,0 +1,58 @@
+package bip44
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher/bip32"
+	"github.com/SkycoinProject/cx-chains/src/cipher/bip39"
+)
+
+func TestNewCoin(t *testing.T) {
+	mnemonic := "dizzy cigar grant ramp inmate uniform gold success able payment faith practice"
+	seed, err := bip39.NewSeed(mnemonic, "")
+	require.NoError(t, err)
+
+	for _, coinType := range []CoinType{CoinTypeBitcoin, CoinTypeSkycoin} {
+		t.Run(fmt.Sprint(coinType), func(t *testing.T) {
+			c, err := NewCoin(seed, coinType)
+			require.NoError(t, err)
+
+			k, err := bip32.NewPrivateKeyFromPath(seed, fmt.Sprintf("m/44'/%d'", coinType))
+			require.NoError(t, err)
+			require.Equal(t, k.String(), c.String())
+
+			for _, account := range []uint32{0, 1, 10} {
+				a, err := c.Account(account)
+				require.NoError(t, err)
+
+				k, err := bip32.NewPrivateKeyFromPath(seed, fmt.Sprintf("m/44'/%d'/%d'", coinType, account))
+				require.NoError(t, err)
+				require.Equal(t, k.String(), a.String())
+
+				external, err := a.External()
+				require.NoError(t, err)
+				k, err = bip32.NewPrivateKeyFromPath(seed, fmt.Sprintf("m/44'/%d'/%d'/0", coinType, account))
+				require.NoError(t, err)
+				require.Equal(t, k.String(), external.String())
+
+				change, err := a.Change()
+				require.NoError(t, err)
+				k, err = bip32.NewPrivateKeyFromPath(seed, fmt.Sprintf("m/44'/%d'/%d'/1", coinType, account))
+				require.NoError(t, err)
+				require.Equal(t, k.String(), change.String())
+			}
+
+			_, err = c.Account(bip32.FirstHardenedChild)
+			require.Equal(t, ErrInvalidAccount, err)
+		})
+	}
+
+	_, err = NewCoin(seed, CoinType(bip32.FirstHardenedChild))
+	require.Equal(t, ErrInvalidCoinType, err)
+
+	_, err = NewCoin([]byte("short"), CoinTypeSkycoin)
+	require.Equal(t, bip32.ErrInvalidSeedLength, err)
+}
@@ -0,0 +1,174 @@
+package encrypt
+
+import (
+	"crypto/aes"
+	gocipher "crypto/cipher"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/cipher/scrypt"
+)
+
+const (
+	scryptAES256GCMMetaLengthSize = 2  // meta data length field size in bytes
+	scryptAES256GCMSaltSize       = 32 // salt bytes number
+	aes256KeyLen                  = 32 // AES-256 key length in bytes
+)
+
+// DefaultScryptAES256GCM default ScryptAES256GCM encryptor
+var DefaultScryptAES256GCM = ScryptAES256GCM{
+	N:      ScryptN,
+	R:      ScryptR,
+	P:      ScryptP,
+	KeyLen: ScryptKeyLen,
+}
+
+// ScryptAES256GCM provides methods for encryption/decryption with scrypt and AES-256-GCM
+type ScryptAES256GCM struct {
+	N      int
+	R      int
+	P      int
+	KeyLen int
+}
+
+// Encrypt encrypts data with password,
+// 1. Scrypt derives the key from password
+// 2. AES-256 in GCM mode generates AEAD from the derived key
+// 3. Puts scrypt paramenters, salt and nonce into metadata, json serialize it and get the serialized metadata length
+// 4. AEAD.Seal encrypts the data, and use [length][metadata] as additional data
+// 5. Final format: base64([[length][metadata]][ciphertext]), length is 2 bytes.
+func (s ScryptAES256GCM) Encrypt(data, password []byte) ([]byte, error) {
+	if len(password) == 0 {
+		return nil, errors.New("missing password")
+	}
+
+	if s.KeyLen != aes256KeyLen {
+		return nil, errors.New("invalid key length, AES-256 requires a 32 byte key")
+	}
+
+	// Scyrpt derives key from password
+	salt := cipher.RandByte(scryptAES256GCMSaltSize)
+	dk, err := scrypt.Key(password, salt, s.N, s.R, s.P, s.KeyLen)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(dk)
+
+	aead, err := newAES256GCM(dk)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare metadata
+	m := meta{
+		N:      s.N,
+		R:      s.R,
+		P:      s.P,
+		KeyLen: s.KeyLen,
+		Salt:   salt,
+		Nonce:  cipher.RandByte(aead.NonceSize()),
+	}
+	// json serialize the metadata
+	ms, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ms) > math.MaxUint16 {
+		return nil, errors.New("metadata length beyond the math.MaxUint16")
+	}
+
+	length := make([]byte, scryptAES256GCMMetaLengthSize)
+	binary.LittleEndian.PutUint16(length, uint16(len(ms)))
+
+	// Additional data for AEAD
+	ad := append(length, ms...)
+	ciphertext := aead.Seal(nil, m.Nonce, data, ad)
+
+	// Base64 encode the [[length][metadata]][ciphertext]
+	rawData := append(ad, ciphertext...)
+	enc := base64.StdEncoding
+	buf := make([]byte, enc.EncodedLen(len(rawData)))
+	enc.Encode(buf, rawData)
+	return buf, nil
+}
+
+// Decrypt decrypts the data with password
+// 1. Base64 decodes the data
+// 2. Reads the first [metaLengthSize] bytes data to get the metadata length, and reads out the metadata.
+// 3. Scrypt derives key from password and paramenters in metadata
+// 4. AES-256 in GCM mode geneates AEAD
+// 5. AEAD decrypts ciphertext with nonce in metadata and [length][metadata] as additional data.
+func (s ScryptAES256GCM) Decrypt(data, password []byte) ([]byte, error) {
+	if len(password) == 0 {
+		return nil, errors.New("missing password")
+	}
+
+	enc := base64.StdEncoding
+	encData := make([]byte, enc.DecodedLen(len(data)))
+	n, err := enc.Decode(encData, data)
+	if err != nil {
+		return nil, err
+	}
+	encData = encData[:n]
+
+	if len(encData) < scryptAES256GCMMetaLengthSize {
+		return nil, errors.New("invalid metadata length")
+	}
+
+	length := binary.LittleEndian.Uint16(encData[:scryptAES256GCMMetaLengthSize])
+	// Sum as an int, a uint16 sum wraps around for lengths close to math.MaxUint16
+	metaEnd := scryptAES256GCMMetaLengthSize + int(length)
+	if metaEnd > len(encData) {
+		return nil, errors.New("invalid metadata length")
+	}
+
+	var m meta
+	if err := json.Unmarshal(encData[scryptAES256GCMMetaLengthSize:metaEnd], &m); err != nil {
+		return nil, err
+	}
+
+	if m.KeyLen != aes256KeyLen {
+		return nil, errors.New("invalid key length, AES-256 requires a 32 byte key")
+	}
+
+	ad := encData[:metaEnd]
+	// Scrypt derives key
+	dk, err := scrypt.Key(password, m.Salt, m.N, m.R, m.P, m.KeyLen)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(dk)
+
+	// Geneates AEAD
+	aead, err := newAES256GCM(dk)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(m.Nonce) != aead.NonceSize() {
+		return nil, errors.New("invalid nonce length")
+	}
+
+	return aead.Open(nil, m.Nonce, encData[metaEnd:], ad)
+}
+
+func newAES256GCM(key []byte) (gocipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return gocipher.NewGCM(block)
+}
+
+// wipe overwrites b with zeros.
+// It is used for the derived keys, which are copied by the AEADs created from them
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,129 @@
+package encrypt
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScryptAES256GCMEncrypt(t *testing.T) {
+	for i := uint(1); i < 16; i++ {
+		name := fmt.Sprintf("N=1<<%v r=%v p=%v keyLen=%v", i, 8, 1, 32)
+		t.Run(name, func(t *testing.T) {
+			crypto := ScryptAES256GCM{N: 1 << i, R: 8, P: 1, KeyLen: 32}
+			encData, err := crypto.Encrypt([]byte("plaintext"), []byte("password"))
+			require.NoError(t, err)
+
+			data, err := base64.StdEncoding.DecodeString(string(encData))
+			require.NoError(t, err)
+			// Checks the prefix
+			ml := binary.LittleEndian.Uint16(data[:scryptAES256GCMMetaLengthSize])
+			require.True(t, int(scryptAES256GCMMetaLengthSize+ml) <= len(data))
+			var m meta
+			require.NoError(t, json.Unmarshal(data[scryptAES256GCMMetaLengthSize:scryptAES256GCMMetaLengthSize+ml], &m))
+			require.Equal(t, m.N, 1<<i)
+			require.Equal(t, m.R, 8)
+			require.Equal(t, m.P, 1)
+			require.Equal(t, m.KeyLen, 32)
+
+			// Decrypts the encrypted data
+			plaintext, err := crypto.Decrypt(encData, []byte("password"))
+			require.NoError(t, err)
+			require.Equal(t, []byte("plaintext"), plaintext)
+		})
+	}
+}
+
+func TestScryptAES256GCMEncryptInvalidKeyLen(t *testing.T) {
+	crypto := ScryptAES256GCM{N: 1 << 10, R: 8, P: 1, KeyLen: 16}
+	_, err := crypto.Encrypt([]byte("plaintext"), []byte("password"))
+	require.Equal(t, errors.New("invalid key length, AES-256 requires a 32 byte key"), err)
+
+	_, err = DefaultScryptAES256GCM.Encrypt([]byte("plaintext"), nil)
+	require.Equal(t, errors.New("missing password"), err)
+}
+
+func TestScryptAES256GCMDecrypt(t *testing.T) {
+	tt := []struct {
+		name    string
+		data    []byte
+		encData []byte
+		encPwd  []byte
+		decPwd  []byte
+		err     error
+	}{
+		{
+			name:    "ok",
+			data:    []byte("plaintext"),
+			encData: []byte("cwB7Im4iOjEwMjQsInIiOjgsInAiOjEsImtleUxlbiI6MzIsInNhbHQiOiI0ZEVEdGxReGlCWTdsamkwZ1U2a0dYOUxaM1ZrMEJRWmxNeGZNVnJOdW5jPSIsIm5vbmNlIjoiOEFwRW5mMmxZbmFmNmtKbSJ90utuAwOvhN+UPe9yTmPD8cVc0kxm92AsqQ=="),
+			encPwd:  []byte("pwd"),
+			decPwd:  []byte("pwd"),
+			err:     nil,
+		},
+		{
+			name:    "invalid password",
+			data:    []byte("plaintext"),
+			encData: []byte("cwB7Im4iOjEwMjQsInIiOjgsInAiOjEsImtleUxlbiI6MzIsInNhbHQiOiI0ZEVEdGxReGlCWTdsamkwZ1U2a0dYOUxaM1ZrMEJRWmxNeGZNVnJOdW5jPSIsIm5vbmNlIjoiOEFwRW5mMmxZbmFmNmtKbSJ90utuAwOvhN+UPe9yTmPD8cVc0kxm92AsqQ=="),
+			encPwd:  []byte("pwd"),
+			decPwd:  []byte("wrong password"),
+			err:     errors.New("cipher: message authentication failed"),
+		},
+		{
+			name:    "missing password",
+			data:    []byte("plaintext"),
+			encData: []byte("cwB7Im4iOjEwMjQsInIiOjgsInAiOjEsImtleUxlbiI6MzIsInNhbHQiOiI0ZEVEdGxReGlCWTdsamkwZ1U2a0dYOUxaM1ZrMEJRWmxNeGZNVnJOdW5jPSIsIm5vbmNlIjoiOEFwRW5mMmxZbmFmNmtKbSJ90utuAwOvhN+UPe9yTmPD8cVc0kxm92AsqQ=="),
+			encPwd:  []byte("pwd"),
+			decPwd:  nil,
+			err:     errors.New("missing password"),
+		},
+		{
+			name:    "invalid metadata length",
+			data:    []byte("plaintext"),
+			encData: []byte("cw=="),
+			encPwd:  []byte("pwd"),
+			decPwd:  []byte("pwd"),
+			err:     errors.New("invalid metadata length"),
+		},
+		{
+			name:    "metadata length overflows uint16",
+			data:    []byte("plaintext"),
+			encData: []byte(base64.StdEncoding.EncodeToString([]byte{0xff, 0xff, 0x78})),
+			encPwd:  []byte("pwd"),
+			decPwd:  []byte("pwd"),
+			err:     errors.New("invalid metadata length"),
+		},
+		{
+			name:    "metadata length wraps to zero",
+			data:    []byte("plaintext"),
+			encData: []byte(base64.StdEncoding.EncodeToString([]byte{0xfe, 0xff, 0x78})),
+			encPwd:  []byte("pwd"),
+			decPwd:  []byte("pwd"),
+			err:     errors.New("invalid metadata length"),
+		},
+	}
+
+	for _, tc := range tt {
+		name := fmt.Sprintf("N=1<<10 r=8 p=1 keyLen=32 %v", tc.name)
+		t.Run(name, func(t *testing.T) {
+			crypto := ScryptAES256GCM{}
+			data, err := crypto.Decrypt(tc.encData, tc.decPwd)
+			require.Equal(t, tc.err, err)
+			if err != nil {
+				return
+			}
+
+			require.Equal(t, tc.data, data)
+		})
+	}
+}
+
+func TestWipe(t *testing.T) {
+	b := []byte("derived key")
+	wipe(b)
+	require.Equal(t, make([]byte, len("derived key")), b)
+}
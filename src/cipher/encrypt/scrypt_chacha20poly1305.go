@@ -4,6 +4,7 @@ Package encrypt provides encryption methods.
 Encryption methods provided:
 
 * chacha20-poly1305 with scrypt key derivation
+* AES-256-GCM with scrypt key derivation
 * sha256xor with sha256 key derivation
 
 The latter is insecure due to the insecure key derivation so should not be used.
@@ -85,6 +86,7 @@ func (s ScryptChacha20poly1305) Encrypt(data, password []byte) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer wipe(dk)
 
 	// Prepare metadata
 	m := meta{
@@ -160,6 +162,7 @@ func (s ScryptChacha20poly1305) Decrypt(data, password []byte) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer wipe(dk)
 
 	// Geneates AEAD
 	aead, err := chacha20poly1305.New(dk)
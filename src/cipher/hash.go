@@ -206,3 +206,47 @@ func Merkle(h0 []SHA256) SHA256 {
 	}
 	return h1[0]
 }
+
+// MerkleProof returns the sibling hashes on the path from the hash at index to the merkle root of a hash array,
+// from the bottom of the tree up. Panics if index is out of range.
+func MerkleProof(h0 []SHA256, index int) []SHA256 {
+	if index < 0 || index >= len(h0) {
+		log.Panic("MerkleProof index out of range")
+	}
+
+	lh := uint64(len(h0))
+	np := nextPowerOfTwo(lh)
+	h1 := make([]SHA256, np)
+	copy(h1, h0)
+
+	var proof []SHA256
+	for len(h1) != 1 {
+		proof = append(proof, h1[index^1])
+
+		h2 := make([]SHA256, len(h1)/2)
+		for i := 0; i < len(h2); i++ {
+			h2[i] = AddSHA256(h1[2*i], h1[2*i+1])
+		}
+		h1 = h2
+		index /= 2
+	}
+	return proof
+}
+
+// VerifyMerkleProof returns true if the proof created by MerkleProof shows that
+// the hash is at index of the hash array with the merkle root
+func VerifyMerkleProof(root, h SHA256, index uint64, proof []SHA256) bool {
+	if len(proof) < 64 && index >= uint64(1)<<uint(len(proof)) {
+		return false
+	}
+
+	for _, p := range proof {
+		if index%2 == 0 {
+			h = AddSHA256(h, p)
+		} else {
+			h = AddSHA256(p, h)
+		}
+		index /= 2
+	}
+	return h == root
+}
@@ -371,3 +371,40 @@ func TestMerkle(t *testing.T) {
 		AddSHA256(SHA256{}, SHA256{})))
 	require.Equal(t, Merkle([]SHA256{h, h2, h3, h4, h5}), out)
 }
+
+func TestMerkleProof(t *testing.T) {
+	for n := 1; n <= 9; n++ {
+		hashes := make([]SHA256, n)
+		for i := range hashes {
+			hashes[i] = SumSHA256(randBytes(t, 128))
+		}
+		root := Merkle(append([]SHA256{}, hashes...))
+
+		for i, h := range hashes {
+			proof := MerkleProof(hashes, i)
+			require.Len(t, proof, merkleDepth(nextPowerOfTwo(uint64(n))))
+			require.True(t, VerifyMerkleProof(root, h, uint64(i), proof))
+
+			// The proof does not verify another hash or index
+			require.False(t, VerifyMerkleProof(root, SumSHA256(randBytes(t, 128)), uint64(i), proof))
+			if n > 1 {
+				require.False(t, VerifyMerkleProof(root, h, uint64((i+1)%n), proof))
+			}
+			require.False(t, VerifyMerkleProof(root, h, uint64(i)+nextPowerOfTwo(uint64(n)), proof))
+		}
+	}
+
+	require.Panics(t, func() {
+		MerkleProof([]SHA256{SumSHA256(randBytes(t, 128))}, 1)
+	})
+}
+
+// merkleDepth returns the number of levels of a merkle tree with n leaves, for n a power of 2
+func merkleDepth(n uint64) int {
+	k := 0
+	for n > 1 {
+		n /= 2
+		k++
+	}
+	return k
+}
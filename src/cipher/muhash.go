@@ -0,0 +1,117 @@
+package cipher
+
+import (
+	"errors"
+	"math/big"
+)
+
+/*
+MuHash is a multiplicative multiset hash (MuHash3072).
+
+Every element is hashed to a 3072 bit number, and the set is represented by the
+product of the numbers of its elements modulo the prime 2^3072 - 1103717.
+Elements can be inserted and removed in any order and the result only depends on
+the multiset of elements, which allows the hash to be updated incrementally.
+Finding a set with a colliding hash reduces to the discrete logarithm problem,
+unlike additive or xor based set hashes which can be broken by solving a linear system.
+
+Removals are accumulated in a separate denominator, so that the costly modular inverse
+is only computed when the digest is requested.
+*/
+
+const (
+	// muHashSize is the size in bytes of a MuHash3072 number
+	muHashSize = 384
+)
+
+var (
+	// ErrInvalidLengthMuHash Invalid MuHash length
+	ErrInvalidLengthMuHash = errors.New("Invalid MuHash length")
+
+	// muHashPrime is the MuHash3072 modulus, 2^3072 - 1103717
+	muHashPrime = func() *big.Int {
+		p := new(big.Int).Lsh(big.NewInt(1), muHashSize*8)
+		return p.Sub(p, big.NewInt(1103717))
+	}()
+)
+
+// MuHash is a multiset hash which can be updated incrementally
+type MuHash struct {
+	numerator   *big.Int
+	denominator *big.Int
+}
+
+// NewMuHash returns the MuHash of the empty set
+func NewMuHash() *MuHash {
+	return &MuHash{
+		numerator:   big.NewInt(1),
+		denominator: big.NewInt(1),
+	}
+}
+
+// muHashElement hashes data to a number modulo the MuHash3072 prime.
+// The 384 bytes of the number are the SHA256 of the SHA256 of data followed by a counter byte, for counters 0 to 11.
+func muHashElement(data []byte) *big.Int {
+	h := SumSHA256(data)
+
+	b := make([]byte, 0, muHashSize)
+	for i := 0; len(b) < muHashSize; i++ {
+		c := SumSHA256(append(h[:], byte(i)))
+		b = append(b, c[:]...)
+	}
+
+	e := new(big.Int).SetBytes(b)
+	return e.Mod(e, muHashPrime)
+}
+
+// Insert adds data to the set
+func (m *MuHash) Insert(data []byte) {
+	m.numerator.Mul(m.numerator, muHashElement(data))
+	m.numerator.Mod(m.numerator, muHashPrime)
+}
+
+// Remove removes data from the set. Removing data that was not inserted is not detected,
+// the result is the hash of a set where data has a negative count.
+func (m *MuHash) Remove(data []byte) {
+	m.denominator.Mul(m.denominator, muHashElement(data))
+	m.denominator.Mod(m.denominator, muHashPrime)
+}
+
+// Digest returns the SHA256 of the set's number
+func (m *MuHash) Digest() SHA256 {
+	n := new(big.Int).ModInverse(m.denominator, muHashPrime)
+	n.Mul(n, m.numerator)
+	n.Mod(n, muHashPrime)
+
+	return SumSHA256(muHashBytes(n))
+}
+
+// muHashBytes returns n as muHashSize big-endian bytes
+func muHashBytes(n *big.Int) []byte {
+	b := n.Bytes()
+	p := make([]byte, muHashSize-len(b), muHashSize)
+	return append(p, b...)
+}
+
+// Serialize returns the numerator and the denominator of the MuHash, 384 big-endian bytes each
+func (m *MuHash) Serialize() []byte {
+	return append(muHashBytes(m.numerator), muHashBytes(m.denominator)...)
+}
+
+// MuHashFromBytes deserializes a MuHash serialized by Serialize
+func MuHashFromBytes(b []byte) (*MuHash, error) {
+	if len(b) != muHashSize*2 {
+		return nil, ErrInvalidLengthMuHash
+	}
+
+	m := &MuHash{
+		numerator:   new(big.Int).SetBytes(b[:muHashSize]),
+		denominator: new(big.Int).SetBytes(b[muHashSize:]),
+	}
+
+	if m.numerator.Cmp(muHashPrime) >= 0 || m.denominator.Cmp(muHashPrime) >= 0 || m.numerator.Sign() == 0 || m.denominator.Sign() == 0 {
+		return nil, errors.New("Invalid MuHash value")
+	}
+
+	return m, nil
+}
@@ -0,0 +1,70 @@
+package cipher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMuHash(t *testing.T) {
+	a := []byte("a")
+	b := []byte("b")
+
+	empty := NewMuHash().Digest()
+
+	// Order independent
+	m1 := NewMuHash()
+	m1.Insert(a)
+	m1.Insert(b)
+	m2 := NewMuHash()
+	m2.Insert(b)
+	m2.Insert(a)
+	require.Equal(t, m1.Digest(), m2.Digest())
+	require.NotEqual(t, empty, m1.Digest())
+
+	// Removing an element undoes inserting it
+	m1.Remove(b)
+	ma := NewMuHash()
+	ma.Insert(a)
+	require.Equal(t, ma.Digest(), m1.Digest())
+	m1.Remove(a)
+	require.Equal(t, empty, m1.Digest())
+
+	// Inserting an element twice does not cancel it out
+	aa := NewMuHash()
+	aa.Insert(a)
+	aa.Insert(a)
+	require.NotEqual(t, empty, aa.Digest())
+	require.NotEqual(t, ma.Digest(), aa.Digest())
+
+	// Removing before inserting gives the same result
+	r := NewMuHash()
+	r.Remove(a)
+	r.Insert(a)
+	require.Equal(t, empty, r.Digest())
+}
+
+func TestMuHashSerialize(t *testing.T) {
+	m := NewMuHash()
+	m.Insert([]byte("a"))
+	m.Insert([]byte("b"))
+	m.Remove([]byte("c"))
+
+	b := m.Serialize()
+	require.Len(t, b, muHashSize*2)
+
+	m2, err := MuHashFromBytes(b)
+	require.NoError(t, err)
+	require.Equal(t, m.Digest(), m2.Digest())
+
+	// Updates of the deserialized MuHash match the original
+	m.Insert([]byte("c"))
+	m2.Insert([]byte("c"))
+	require.Equal(t, m.Digest(), m2.Digest())
+
+	_, err = MuHashFromBytes(b[1:])
+	require.Equal(t, ErrInvalidLengthMuHash, err)
+
+	_, err = MuHashFromBytes(make([]byte, muHashSize*2))
+	require.Error(t, err)
+}
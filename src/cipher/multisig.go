@@ -0,0 +1,106 @@
+package cipher
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"sort"
+)
+
+/*
+Multisig addresses are owned by n public keys, and spending their outputs requires
+signatures by m of them.
+
+The address key is the Ripemd160 of the double SHA256 of m, n and the public keys sorted by their bytes,
+so a multisig address does not depend on the order of its public keys.
+Multisig addresses have the version AddressVersionMultisig.
+*/
+
+const (
+	// AddressVersionMultisig is the address version of multisig addresses
+	AddressVersionMultisig byte = 0x01
+	// MaxMultisigPubKeys is the maximum number of public keys of a multisig address
+	MaxMultisigPubKeys = 16
+)
+
+var (
+	// ErrMultisigRequired the number of required signatures is not between 1 and the number of public keys
+	ErrMultisigRequired = errors.New("multisig required signatures must be between 1 and the number of public keys")
+	// ErrMultisigPubKeysCount the number of public keys is not between 1 and MaxMultisigPubKeys
+	ErrMultisigPubKeysCount = errors.New("multisig public keys must be between 1 and 16")
+	// ErrMultisigDuplicatePubKey the public keys contain duplicates
+	ErrMultisigDuplicatePubKey = errors.New("multisig public keys contain duplicates")
+)
+
+// SortMultisigPubKeys returns a copy of the public keys sorted by their bytes,
+// which is the order of the public keys of a multisig address
+func SortMultisigPubKeys(pubKeys []PubKey) []PubKey {
+	sorted := make([]PubKey, len(pubKeys))
+	copy(sorted, pubKeys)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i][:], sorted[j][:]) < 0
+	})
+	return sorted
+}
+
+// VerifyMultisigPubKeys checks that required of the public keys can own a multisig address
+func VerifyMultisigPubKeys(required int, pubKeys []PubKey) error {
+	if len(pubKeys) == 0 || len(pubKeys) > MaxMultisigPubKeys {
+		return ErrMultisigPubKeysCount
+	}
+
+	if required < 1 || required > len(pubKeys) {
+		return ErrMultisigRequired
+	}
+
+	seen := make(map[PubKey]struct{}, len(pubKeys))
+	for _, p := range pubKeys {
+		if _, ok := seen[p]; ok {
+			return ErrMultisigDuplicatePubKey
+		}
+		seen[p] = struct{}{}
+
+		if err := p.Verify(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MultisigAddress creates the multisig address which requires required signatures of the public keys
+func MultisigAddress(required int, pubKeys []PubKey) (Address, error) {
+	if err := VerifyMultisigPubKeys(required, pubKeys); err != nil {
+		return Address{}, err
+	}
+
+	sorted := SortMultisigPubKeys(pubKeys)
+
+	b := make([]byte, 0, 2+len(sorted)*len(PubKey{}))
+	b = append(b, byte(required), byte(len(sorted)))
+	for _, p := range sorted {
+		b = append(b, p[:]...)
+	}
+
+	r1 := SumSHA256(b)
+	r2 := SumSHA256(r1[:])
+
+	return Address{
+		Version: AddressVersionMultisig,
+		Key:     HashRipemd160(r2[:]),
+	}, nil
+}
+
+// MustMultisigAddress creates a multisig address, panics on error
+func MustMultisigAddress(required int, pubKeys []PubKey) Address {
+	addr, err := MultisigAddress(required, pubKeys)
+	if err != nil {
+		log.Panic(err)
+	}
+	return addr
+}
+
+// IsMultisig returns true if the address is a multisig address
+func (addr Address) IsMultisig() bool {
+	return addr.Version == AddressVersionMultisig
+}
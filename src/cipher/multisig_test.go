@@ -0,0 +1,141 @@
+package cipher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func makeMultisigPubKeys(n int) []PubKey {
+	pubKeys := make([]PubKey, n)
+	for i := range pubKeys {
+		pubKeys[i], _ = GenerateKeyPair()
+	}
+	return pubKeys
+}
+
+func TestMultisigAddress(t *testing.T) {
+	pubKeys := makeMultisigPubKeys(3)
+
+	addr, err := MultisigAddress(2, pubKeys)
+	require.NoError(t, err)
+	require.True(t, addr.IsMultisig())
+	require.Equal(t, AddressVersionMultisig, addr.Version)
+
+	// The address does not depend on the order of the public keys
+	reversed := []PubKey{pubKeys[2], pubKeys[1], pubKeys[0]}
+	addr2, err := MultisigAddress(2, reversed)
+	require.NoError(t, err)
+	require.Equal(t, addr, addr2)
+
+	// The input is not modified
+	require.Equal(t, pubKeys[2], reversed[0])
+
+	// The address depends on the number of required signatures
+	addr3, err := MultisigAddress(3, pubKeys)
+	require.NoError(t, err)
+	require.NotEqual(t, addr, addr3)
+
+	// The address depends on the public keys
+	addr4, err := MultisigAddress(2, pubKeys[:2])
+	require.NoError(t, err)
+	require.NotEqual(t, addr, addr4)
+
+	// 1-of-1 multisig address is not the standard address of the public key
+	addr5, err := MultisigAddress(1, pubKeys[:1])
+	require.NoError(t, err)
+	require.NotEqual(t, AddressFromPubKey(pubKeys[0]), addr5)
+	require.False(t, AddressFromPubKey(pubKeys[0]).IsMultisig())
+
+	// Roundtrip through the base58 encoding
+	addr6, err := DecodeBase58Address(addr.String())
+	require.NoError(t, err)
+	require.Equal(t, addr, addr6)
+
+	// A multisig address cannot be verified against a single public key
+	require.Equal(t, ErrAddressInvalidVersion, addr.Verify(pubKeys[0]))
+
+	require.Panics(t, func() {
+		MustMultisigAddress(0, pubKeys)
+	})
+	require.Equal(t, addr, MustMultisigAddress(2, pubKeys))
+}
+
+func TestVerifyMultisigPubKeys(t *testing.T) {
+	pubKeys := makeMultisigPubKeys(MaxMultisigPubKeys + 1)
+
+	cases := []struct {
+		name     string
+		required int
+		pubKeys  []PubKey
+		err      error
+	}{
+		{
+			name:     "valid",
+			required: 2,
+			pubKeys:  pubKeys[:3],
+		},
+		{
+			name:     "max public keys",
+			required: MaxMultisigPubKeys,
+			pubKeys:  pubKeys[:MaxMultisigPubKeys],
+		},
+		{
+			name:     "no public keys",
+			required: 1,
+			err:      ErrMultisigPubKeysCount,
+		},
+		{
+			name:     "too many public keys",
+			required: 1,
+			pubKeys:  pubKeys,
+			err:      ErrMultisigPubKeysCount,
+		},
+		{
+			name:     "required zero",
+			required: 0,
+			pubKeys:  pubKeys[:3],
+			err:      ErrMultisigRequired,
+		},
+		{
+			name:     "required exceeds public keys",
+			required: 4,
+			pubKeys:  pubKeys[:3],
+			err:      ErrMultisigRequired,
+		},
+		{
+			name:     "duplicate public key",
+			required: 2,
+			pubKeys:  []PubKey{pubKeys[0], pubKeys[1], pubKeys[0]},
+			err:      ErrMultisigDuplicatePubKey,
+		},
+		{
+			name:     "invalid public key",
+			required: 1,
+			pubKeys:  []PubKey{pubKeys[0], {}},
+			err:      ErrInvalidPubKey,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := VerifyMultisigPubKeys(tc.required, tc.pubKeys)
+			require.Equal(t, tc.err, err)
+
+			_, err = MultisigAddress(tc.required, tc.pubKeys)
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func TestSortMultisigPubKeys(t *testing.T) {
+	pubKeys := makeMultisigPubKeys(5)
+	sorted := SortMultisigPubKeys(pubKeys)
+	require.Len(t, sorted, 5)
+	for i := 1; i < len(sorted); i++ {
+		require.True(t, sorted[i-1].Hex() < sorted[i].Hex())
+	}
+	for _, p := range pubKeys {
+		require.Contains(t, sorted, p)
+	}
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func backupCmd() *cobra.Command {
+	backupCmd := &cobra.Command{
+		Short: "Back up the database of a running node",
+		Use:   "backup [file]",
+		Long: `Writes a consistent copy of the database of the node to a file, while the node keeps running.
+    The file must not exist. Add .gz to the file name and use --gzip to compress the copy.
+    Requires the ADMIN API set to be enabled on the node.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE:         backup,
+	}
+
+	backupCmd.Flags().BoolP("gzip", "z", false, "Compress the copy with gzip")
+
+	return backupCmd
+}
+
+func backup(c *cobra.Command, args []string) error {
+	compress, err := c.Flags().GetBool("gzip")
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(args[0], os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	n, err := apiClient.Backup(f, compress)
+	if err != nil {
+		// Do not leave a partial copy behind
+		if cErr := f.Close(); cErr != nil {
+			return cErr
+		}
+		if rErr := os.Remove(args[0]); rErr != nil {
+			return rErr
+		}
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote %d bytes to %s\n", n, args[0])
+	return nil
+}
@@ -2,20 +2,32 @@ package cli
 
 import (
 	"fmt"
+	"os"
 
 	gcli "github.com/spf13/cobra"
 )
 
 func broadcastTxCmd() *gcli.Command {
 	return &gcli.Command{
-		Short:                 "Broadcast a raw transaction to the network",
-		Use:                   "broadcastTransaction [raw transaction]",
+		Short: "Broadcast a raw transaction to the network",
+		Use:   "broadcastTransaction [raw transaction or envelope file]",
+		Long: `Broadcast a raw transaction, or a transaction envelope file signed
+    with the signTransaction command.`,
 		Args:                  gcli.ExactArgs(1),
 		DisableFlagsInUseLine: true,
 		SilenceUsage:          true,
 		RunE: func(_ *gcli.Command, args []string) error {
 			rawtx := args[0]
 
+			// A transaction envelope may be provided instead of the raw transaction
+			if _, err := os.Stat(rawtx); err == nil {
+				envelope, err := readTxnEnvelope(rawtx)
+				if err != nil {
+					return err
+				}
+				rawtx = envelope.EncodedTransaction
+			}
+
 			txid, err := apiClient.InjectEncodedTransaction(rawtx)
 			if err != nil {
 				return err
@@ -11,6 +11,7 @@ import (
 	"github.com/SkycoinProject/cx-chains/src/cipher"
 	"github.com/SkycoinProject/cx-chains/src/util/apputil"
 	"github.com/SkycoinProject/cx-chains/src/visor"
+	"github.com/SkycoinProject/cx-chains/src/visor/blockdb"
 	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
 )
 
@@ -139,3 +140,140 @@ func checkDBDecoding(_ *cobra.Command, args []string) error {
 	return nil
 
 }
+
+func verifyDBCmd() *cobra.Command {
+	return &cobra.Command{
+		Short: "Verify the blockchain and unspent output pool consistency",
+		Use:   "verifydb [db path]",
+		Long: `Walks the blocks from the genesis block to the head block, recomputes the unspent output pool
+    and reports any divergence from the stored unspent pool, its address index and the blockchain metadata.
+    If no argument is specificed, the default data.db in $HOME/.$COIN/ will be checked.`,
+		Args:                  cobra.MaximumNArgs(1),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		RunE:                  verifyDB,
+	}
+}
+
+func verifyDB(_ *cobra.Command, args []string) error {
+	// get db path
+	dbPath := ""
+	if len(args) > 0 {
+		dbPath = args[0]
+	}
+	dbPath, err := resolveDBPath(cliConfig, dbPath)
+	if err != nil {
+		return err
+	}
+
+	// check if this file exists
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		return fmt.Errorf("db file: %v does not exist", dbPath)
+	}
+
+	db, err := bolt.Open(dbPath, 0600, &bolt.Options{
+		Timeout:  5 * time.Second,
+		ReadOnly: true,
+	})
+
+	if err != nil {
+		return fmt.Errorf("open db failed: %v", err)
+	}
+
+	go func() {
+		apputil.CatchInterrupt(quitChan)
+	}()
+
+	res, err := blockdb.Verify(wrapDB(db), visor.DefaultWalker, quitChan)
+	if err != nil {
+		if err == blockdb.ErrVerifyStopped {
+			return nil
+		}
+		return fmt.Errorf("verifydb failed: %v", err)
+	}
+
+	fmt.Printf("head seq: %d, blocks: %d, unspent outputs: %d\n", res.HeadSeq, res.Blocks, res.Unspents)
+
+	if !res.OK() {
+		for _, d := range res.Divergences {
+			fmt.Println(d)
+		}
+		if n := res.DivergenceCount - len(res.Divergences); n > 0 {
+			fmt.Printf("... %d more\n", n)
+		}
+		return fmt.Errorf("verifydb found %d divergences", res.DivergenceCount)
+	}
+
+	fmt.Println("verify db success")
+	return nil
+}
+
+func replayCmd() *cobra.Command {
+	replayCmd := &cobra.Command{
+		Short: "Replay the blockchain and report the first divergence from the stored state",
+		Use:   "replay [flags] [db path]",
+		Long: `Re-executes the blocks from the genesis block to the head block, recomputing the unspent outputs
+    and the CX program states, and compares them to the stored state at every block from --seq.
+    Stops at the first divergence.
+    If no argument is specificed, the default data.db in $HOME/.$COIN/ will be replayed.`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE:         replay,
+	}
+
+	replayCmd.Flags().Uint64("seq", 0, "Sequence of the first block to compare to the stored state")
+
+	return replayCmd
+}
+
+func replay(c *cobra.Command, args []string) error {
+	startSeq, err := c.Flags().GetUint64("seq")
+	if err != nil {
+		return err
+	}
+
+	// get db path
+	dbPath := ""
+	if len(args) > 0 {
+		dbPath = args[0]
+	}
+	dbPath, err = resolveDBPath(cliConfig, dbPath)
+	if err != nil {
+		return err
+	}
+
+	// check if this file exists
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		return fmt.Errorf("db file: %v does not exist", dbPath)
+	}
+
+	db, err := bolt.Open(dbPath, 0600, &bolt.Options{
+		Timeout:  5 * time.Second,
+		ReadOnly: true,
+	})
+
+	if err != nil {
+		return fmt.Errorf("open db failed: %v", err)
+	}
+
+	go func() {
+		apputil.CatchInterrupt(quitChan)
+	}()
+
+	res, err := blockdb.Replay(wrapDB(db), visor.DefaultWalker, startSeq, quitChan)
+	if err != nil {
+		if err == blockdb.ErrVerifyStopped {
+			return nil
+		}
+		return fmt.Errorf("replay failed: %v", err)
+	}
+
+	fmt.Printf("head seq: %d, blocks replayed from seq %d: %d, program states: %d, unspent outputs: %d\n", res.HeadSeq, res.StartSeq, res.Blocks, res.ProgramStates, res.Unspents)
+
+	if !res.OK() {
+		return fmt.Errorf("replay diverged at %s", res.Divergence)
+	}
+
+	fmt.Println("replay success")
+	return nil
+}
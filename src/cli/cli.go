@@ -45,6 +45,8 @@ var (
     RPC_ADDR: Address of RPC node. Must be in scheme://host format. Default "%s"
     RPC_USER: Username for RPC API, if enabled in the RPC.
     RPC_PASS: Password for RPC API, if enabled in the RPC.
+    RPC_TOKEN: API token for RPC API, if enabled in the RPC. Ignored if RPC_USER or RPC_PASS is set.
+    RPC_API_KEY: API key for RPC API, if configured in the RPC. Requests with an API key do not need a CSRF token.
     COIN: Name of the coin. Default "%s"
     WALLET_DIR: Directory where wallets are stored. This value is overridden by any subcommand flag specifying a wallet filename, if that filename includes a path. Default "%s"
     WALLET_NAME: Name of wallet file (without path). This value is overridden by any subcommand flag specifying a wallet filename. Default "%s"
@@ -100,6 +102,8 @@ type Config struct {
 	RPCAddress  string `json:"rpc_address"`
 	RPCUsername string `json:"-"`
 	RPCPassword string `json:"-"`
+	RPCToken    string `json:"-"`
+	RPCAPIKey   string `json:"-"`
 }
 
 // LoadConfig loads config from environment, prior to parsing CLI flags
@@ -122,6 +126,8 @@ func LoadConfig() (Config, error) {
 
 	rpcUser := os.Getenv("RPC_USER")
 	rpcPass := os.Getenv("RPC_PASS")
+	rpcToken := os.Getenv("RPC_TOKEN")
+	rpcAPIKey := os.Getenv("RPC_API_KEY")
 
 	home := file.UserHome()
 
@@ -155,6 +161,8 @@ func LoadConfig() (Config, error) {
 		RPCAddress:  rpcAddr,
 		RPCUsername: rpcUser,
 		RPCPassword: rpcPass,
+		RPCToken:    rpcToken,
+		RPCAPIKey:   rpcAPIKey,
 	}, nil
 }
 
@@ -213,6 +221,8 @@ func resolveDBPath(cfg Config, db string) (string, error) {
 func NewCLI(cfg Config) (*cobra.Command, error) {
 	apiClient = api.NewClient(cfg.RPCAddress)
 	apiClient.SetAuth(cfg.RPCUsername, cfg.RPCPassword)
+	apiClient.SetToken(cfg.RPCToken)
+	apiClient.SetAPIKey(cfg.RPCAPIKey)
 
 	cliConfig = cfg
 
@@ -239,12 +249,16 @@ func NewCLI(cfg Config) (*cobra.Command, error) {
 		listAddressesCmd(),
 		listWalletsCmd(),
 		sendCmd(),
+		signTxnCmd(),
 		showConfigCmd(),
 		showSeedCmd(),
 		statusCmd(),
 		transactionCmd(),
 		verifyTransactionCmd(),
 		verifyAddressCmd(),
+		verifyDBCmd(),
+		replayCmd(),
+		exportChainCmd(),
 		versionCmd(),
 		walletCreateCmd(),
 		walletAddAddressesCmd(),
@@ -256,6 +270,9 @@ func NewCLI(cfg Config) (*cobra.Command, error) {
 		addressTransactionsCmd(),
 		pendingTransactionsCmd(),
 		addresscountCmd(),
+		backupCmd(),
+		compactDBCmd(),
+		dbStatsCmd(),
 	}
 
 	skyCLI.Version = Version
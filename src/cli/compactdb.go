@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func compactDBCmd() *cobra.Command {
+	return &cobra.Command{
+		Short: "Compact the database of a running node",
+		Use:   "compactdb",
+		Long: `Copies the database of the node into a fresh file without its free pages, and swaps it in place
+    of the database file while the node keeps running. The database file never shrinks by itself:
+    the space freed by removed unconfirmed transactions and rewritten data is only reused by later writes.
+    Requests to the node wait until the compaction is done. Prints the size of the database before and after.
+    Requires the ADMIN API set to be enabled on the node.`,
+		Args:                  cobra.NoArgs,
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		RunE: func(c *cobra.Command, args []string) error {
+			res, err := apiClient.CompactDB()
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Compacted the database from %d to %d bytes in %s\n", res.Before.FileSize, res.After.FileSize, res.Duration)
+			return nil
+		},
+	}
+}
+
+func dbStatsCmd() *cobra.Command {
+	return &cobra.Command{
+		Short: "Show the size of the database of a running node",
+		Use:   "dbstats",
+		Long: `Shows the size of the database file of the node, the free pages in it which can be reclaimed
+    with the compactdb command, and the size of each bucket.
+    Requires the ADMIN API set to be enabled on the node.`,
+		Args:                  cobra.NoArgs,
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		RunE: func(c *cobra.Command, args []string) error {
+			stats, err := apiClient.DBStats()
+			if err != nil {
+				return err
+			}
+
+			return printJSON(stats)
+		},
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/SkycoinProject/cx-chains/src/api"
 	"github.com/SkycoinProject/cx-chains/src/params"
 	"github.com/SkycoinProject/cx-chains/src/readable"
 	"github.com/SkycoinProject/cx-chains/src/transaction"
@@ -54,7 +55,12 @@ func createRawTxnCmd() *cobra.Command {
     Use caution when using the "-p" command. If you have command history enabled
     your wallet encryption password can be recovered from the history log. If you
     do not include the "-p" option you will be prompted to enter your password
-    after you enter your command.`, cliConfig.FullWalletPath()),
+    after you enter your command.
+
+    Use the "--unsigned" option to create an unsigned transaction envelope
+    instead, which includes the transaction inputs and can be signed offline
+    with the signTransaction command. No password is needed, and the wallet
+    may be a watch-only wallet.`, cliConfig.FullWalletPath()),
 		SilenceUsage: true,
 		Args:         cobra.MinimumNArgs(0),
 		RunE: func(c *cobra.Command, args []string) error {
@@ -63,6 +69,25 @@ func createRawTxnCmd() *cobra.Command {
 				return err
 			}
 
+			unsigned, err := c.Flags().GetBool("unsigned")
+			if err != nil {
+				return err
+			}
+
+			if unsigned {
+				envelope, err := createUnsignedRawTxnCmdHandler(c, args)
+				switch err.(type) {
+				case nil:
+				case WalletLoadError:
+					printHelp(c)
+					return err
+				default:
+					return err
+				}
+
+				return printJSON(envelope)
+			}
+
 			txn, err := createRawTxnCmdHandler(c, args)
 			switch err.(type) {
 			case nil:
@@ -101,6 +126,7 @@ example: -m '[{"addr":"$addr1", "coins": "10.2"}, {"addr":"$addr2", "coins": "20
 	createRawTxnCmd.Flags().StringP("password", "p", "", "Wallet password")
 	createRawTxnCmd.Flags().BoolP("json", "j", false, "Returns the results in JSON format.")
 	createRawTxnCmd.Flags().String("csv", "", "CSV file containing addresses and amounts to send")
+	createRawTxnCmd.Flags().Bool("unsigned", false, "Create an unsigned transaction envelope in JSON format, to be signed with signTransaction")
 
 	return createRawTxnCmd
 }
@@ -373,6 +399,60 @@ func createRawTxnCmdHandler(c *cobra.Command, args []string) (*coin.Transaction,
 		parsedArgs.Password, params.MainNetDistribution)
 }
 
+func createUnsignedRawTxnCmdHandler(c *cobra.Command, args []string) (*api.CreateTransactionResponse, error) {
+	parsedArgs, err := parseCreateRawTxnArgs(c, args)
+	if err != nil {
+		return nil, err
+	}
+
+	wlt, err := wallet.Load(parsedArgs.WalletID)
+	if err != nil {
+		return nil, WalletLoadError{err}
+	}
+
+	inAddrs, err := walletSpendAddresses(wlt, parsedArgs.Address, parsedArgs.ChangeAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	return CreateUnsignedRawTxn(apiClient, wlt, inAddrs, parsedArgs.ChangeAddress,
+		parsedArgs.SendAmounts, params.MainNetDistribution)
+}
+
+// walletSpendAddresses returns the wallet addresses to spend from, which is addr if specified or else all addresses in the wallet.
+// The addresses and the change address must be in the wallet.
+func walletSpendAddresses(wlt *wallet.Wallet, addr, chgAddr string) ([]string, error) {
+	cAddr, err := cipher.DecodeBase58Address(chgAddr)
+	if err != nil {
+		return nil, ErrAddress
+	}
+
+	if _, ok := wlt.GetEntry(cAddr); !ok {
+		return nil, fmt.Errorf("change address %v is not in wallet", chgAddr)
+	}
+
+	if addr != "" {
+		srcAddr, err := cipher.DecodeBase58Address(addr)
+		if err != nil {
+			return nil, ErrAddress
+		}
+
+		if _, ok := wlt.GetEntry(srcAddr); !ok {
+			return nil, fmt.Errorf("%v address is not in wallet", addr)
+		}
+
+		return []string{addr}, nil
+	}
+
+	totalAddrs := wlt.GetAddresses()
+	addrStrArray := make([]string, len(totalAddrs))
+	for i, a := range totalAddrs {
+		addrStrArray[i] = a.String()
+	}
+
+	return addrStrArray, nil
+}
+
 func validateSendAmounts(toAddrs []SendAmount) error {
 	for _, arg := range toAddrs {
 		// validate to address
@@ -561,23 +641,95 @@ func CreateRawTxn(c GetOutputser, wlt *wallet.Wallet, inAddrs []string, chgAddr
 	return txn, nil
 }
 
-func createRawTxn(uxouts *readable.UnspentOutputsSummary, wlt *wallet.Wallet, chgAddr string, toAddrs []SendAmount, password []byte) (*coin.Transaction, error) {
+// CreateUnsignedRawTxn creates an unsigned transaction from a set of addresses contained in a loaded *wallet.Wallet.
+// The transaction is returned in an envelope with its inputs, so that it can be signed offline with SignRawTxn.
+// The wallet's secrets are not used, so the wallet may be encrypted or watch-only.
+func CreateUnsignedRawTxn(c GetOutputser, wlt *wallet.Wallet, inAddrs []string, chgAddr string, toAddrs []SendAmount, distParams params.Distribution) (*api.CreateTransactionResponse, error) {
+	if err := validateSendAmounts(toAddrs); err != nil {
+		return nil, err
+	}
+
+	// Get unspent outputs of those addresses
+	outputs, err := c.OutputsForAddresses(inAddrs)
+	if err != nil {
+		return nil, err
+	}
+
+	inUxs, err := outputs.SpendableOutputs().ToUxArray()
+	if err != nil {
+		return nil, err
+	}
+
+	spendOutputs, txOuts, err := makeRawTxnOutputs(outputs, chgAddr, toAddrs)
+	if err != nil {
+		return nil, err
+	}
+
+	txn, err := NewUnsignedTransaction(spendOutputs, txOuts)
+	if err != nil {
+		return nil, err
+	}
+
+	// filter out unspents which are not used in transaction, in the order of the transaction inputs
+	var inUxsFiltered coin.UxArray
+	for _, h := range txn.In {
+		for _, u := range inUxs {
+			if h == u.Hash() {
+				inUxsFiltered = append(inUxsFiltered, u)
+			}
+		}
+	}
+
+	head, err := outputs.Head.ToCoinBlockHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := visor.VerifySingleTxnSoftConstraints(*txn, head.Time, inUxsFiltered, distParams, params.UserVerifyTxn); err != nil {
+		return nil, err
+	}
+	if err := visor.VerifySingleTxnHardConstraints(*txn, head, inUxsFiltered, visor.TxnUnsigned); err != nil {
+		return nil, err
+	}
+	if err := visor.VerifySingleTxnUserConstraints(*txn); err != nil {
+		return nil, err
+	}
+
+	inputs, err := visor.NewTransactionInputs(inUxsFiltered, head.Time)
+	if err != nil {
+		return nil, err
+	}
+
+	return api.NewCreateTransactionResponse(txn, inputs)
+}
+
+// makeRawTxnOutputs chooses the outputs to spend and makes the transaction outputs, including change
+func makeRawTxnOutputs(uxouts *readable.UnspentOutputsSummary, chgAddr string, toAddrs []SendAmount) ([]transaction.UxBalance, []coin.TransactionOutput, error) {
 	// Calculate total required coins
 	var totalCoins uint64
 	for _, arg := range toAddrs {
 		var err error
 		totalCoins, err = mathutil.AddUint64(totalCoins, arg.Coins)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
 	spendOutputs, err := chooseSpends(uxouts, totalCoins)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	txOuts, err := makeChangeOut(spendOutputs, chgAddr, toAddrs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return spendOutputs, txOuts, nil
+}
+
+func createRawTxn(uxouts *readable.UnspentOutputsSummary, wlt *wallet.Wallet, chgAddr string, toAddrs []SendAmount, password []byte) (*coin.Transaction, error) {
+	spendOutputs, txOuts, err := makeRawTxnOutputs(uxouts, chgAddr, toAddrs)
 	if err != nil {
 		return nil, err
 	}
@@ -756,3 +908,27 @@ func NewTransaction(utxos []transaction.UxBalance, keys []cipher.SecKey, outs []
 
 	return &txn, nil
 }
+
+// NewUnsignedTransaction creates a transaction with null signatures, to be signed later
+func NewUnsignedTransaction(utxos []transaction.UxBalance, outs []coin.TransactionOutput) (*coin.Transaction, error) {
+	txn := coin.Transaction{}
+	for _, u := range utxos {
+		if err := txn.PushInput(u.Hash); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, o := range outs {
+		if err := txn.PushOutput(o.Address, o.Coins, o.Hours, o.ProgramState); err != nil {
+			return nil, err
+		}
+	}
+
+	txn.Sigs = make([]cipher.Sig, len(txn.In))
+
+	if err := txn.UpdateHeader(); err != nil {
+		return nil, err
+	}
+
+	return &txn, nil
+}
@@ -1,19 +1,32 @@
 package cli
 
 import (
+	"encoding/json"
 	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/SkycoinProject/cx-chains/src/api"
 	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/params"
 	"github.com/SkycoinProject/cx-chains/src/readable"
 	"github.com/SkycoinProject/cx-chains/src/testutil"
 	"github.com/SkycoinProject/cx-chains/src/transaction"
 	"github.com/SkycoinProject/cx-chains/src/util/fee"
+	"github.com/SkycoinProject/cx-chains/src/visor"
+	"github.com/SkycoinProject/cx-chains/src/wallet"
 )
 
 func TestMakeChangeOut(t *testing.T) {
+	// The expected hours are calculated with a burn factor of 10
+	originalBurnFactor := params.UserVerifyTxn.BurnFactor
+	params.UserVerifyTxn.BurnFactor = 10
+	defer func() {
+		params.UserVerifyTxn.BurnFactor = originalBurnFactor
+	}()
+
 	// single destination test
 	uxOuts := []transaction.UxBalance{
 		{
@@ -114,6 +127,13 @@ func TestMakeChangeOut(t *testing.T) {
 }
 
 func TestMakeChangeOutMinOneCoinHourSend(t *testing.T) {
+	// The expected hours are calculated with a burn factor of 10
+	originalBurnFactor := params.UserVerifyTxn.BurnFactor
+	params.UserVerifyTxn.BurnFactor = 10
+	defer func() {
+		params.UserVerifyTxn.BurnFactor = originalBurnFactor
+	}()
+
 	uxOuts := []transaction.UxBalance{
 		{
 			Hash:    cipher.MustSHA256FromHex("f569461182b0efe9a5c666e9a35c6602b351021c1803cc740aca548cf6db4cb2"),
@@ -161,6 +181,13 @@ func TestMakeChangeOutMinOneCoinHourSend(t *testing.T) {
 }
 
 func TestMakeChangeOutCoinHourCap(t *testing.T) {
+	// The expected hours are calculated with a burn factor of 10
+	originalBurnFactor := params.UserVerifyTxn.BurnFactor
+	params.UserVerifyTxn.BurnFactor = 10
+	defer func() {
+		params.UserVerifyTxn.BurnFactor = originalBurnFactor
+	}()
+
 	uxOuts := []transaction.UxBalance{
 		{
 			Hash:    cipher.MustSHA256FromHex("f569461182b0efe9a5c666e9a35c6602b351021c1803cc740aca548cf6db4cb2"),
@@ -598,3 +625,102 @@ func TestParseSendAmountsFromCSV(t *testing.T) {
 		})
 	}
 }
+
+type fakeGetOutputser readable.UnspentOutputsSummary
+
+func (f fakeGetOutputser) OutputsForAddresses(addrs []string) (*readable.UnspentOutputsSummary, error) {
+	s := readable.UnspentOutputsSummary(f)
+	return &s, nil
+}
+
+func TestCreateUnsignedRawTxnAndSign(t *testing.T) {
+	wlt, err := wallet.NewWallet("test.wlt", wallet.Options{
+		Seed:      "seed",
+		GenerateN: 2,
+	})
+	require.NoError(t, err)
+
+	watchOnly, err := wallet.NewWallet("watch.wlt", wallet.Options{
+		Type:      wallet.WalletTypeWatchOnly,
+		Addresses: wlt.GetAddresses(),
+	})
+	require.NoError(t, err)
+
+	head := coin.BlockHeader{
+		BkSeq: 20,
+		Time:  1e9,
+	}
+
+	var outputs readable.UnspentOutputs
+	for i, e := range wlt.Entries {
+		ux := coin.UxOut{
+			Head: coin.UxHead{
+				Time:  head.Time - 1000,
+				BkSeq: uint64(10 + i),
+			},
+			Body: coin.UxBody{
+				SrcTransaction: testutil.RandSHA256(t),
+				Address:        e.SkycoinAddress(),
+				Coins:          10e6,
+				Hours:          100,
+			},
+		}
+
+		o, err := readable.NewUnspentOutput(visor.UnspentOutput{
+			UxOut:           ux,
+			CalculatedHours: 100,
+		})
+		require.NoError(t, err)
+		outputs = append(outputs, o)
+	}
+
+	c := fakeGetOutputser{
+		Head:        readable.NewBlockHeader(head),
+		HeadOutputs: outputs,
+	}
+
+	toAddrs := []SendAmount{{
+		Addr:  testutil.MakeAddress().String(),
+		Coins: 15e6,
+	}}
+	chgAddr := wlt.Entries[0].Address.String()
+	addrs := []string{wlt.Entries[0].Address.String(), wlt.Entries[1].Address.String()}
+
+	// The unsigned transaction is created with the watch-only wallet
+	envelope, err := CreateUnsignedRawTxn(c, watchOnly, addrs, chgAddr, toAddrs, params.MainNetDistribution)
+	require.NoError(t, err)
+	require.Len(t, envelope.Transaction.In, 2)
+	for _, s := range envelope.Transaction.Sigs {
+		require.Equal(t, cipher.Sig{}.Hex(), s)
+	}
+
+	// The envelope survives serialization
+	b, err := json.Marshal(envelope)
+	require.NoError(t, err)
+	var unsigned api.CreateTransactionResponse
+	err = json.Unmarshal(b, &unsigned)
+	require.NoError(t, err)
+
+	_, err = SignRawTxn(watchOnly, &unsigned, PasswordFromBytes(nil))
+	require.Equal(t, wallet.ErrWalletWatchOnly, err)
+
+	signed, err := SignRawTxn(wlt, &unsigned, PasswordFromBytes(nil))
+	require.NoError(t, err)
+
+	txn, inputs, err := signed.TransactionAndInputs()
+	require.NoError(t, err)
+	require.True(t, txn.IsFullySigned())
+	require.Equal(t, envelope.Transaction.InnerHash, txn.InnerHash.Hex())
+
+	uxOuts := make(coin.UxArray, len(inputs))
+	for i, in := range inputs {
+		uxOuts[i] = in.UxOut
+	}
+	err = visor.VerifySingleTxnHardConstraints(*txn, head, uxOuts, visor.TxnSigned)
+	require.NoError(t, err)
+
+	// Inputs which do not match the encoded transaction are rejected
+	unsigned.Transaction.In[0].Coins = "20"
+	_, err = SignRawTxn(wlt, &unsigned, PasswordFromBytes(nil))
+	require.Equal(t, errors.New("invalid input 0: uxid does not match the input"), err)
+}
@@ -0,0 +1,389 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/spf13/cobra"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher/encoder"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/readable"
+	"github.com/SkycoinProject/cx-chains/src/util/apputil"
+	"github.com/SkycoinProject/cx-chains/src/util/droplet"
+	"github.com/SkycoinProject/cx-chains/src/visor"
+	"github.com/SkycoinProject/cx-chains/src/visor/blockdb"
+)
+
+const (
+	exportFormatJSONL  = "jsonl"
+	exportFormatCSV    = "csv"
+	exportFormatBinary = "binary"
+)
+
+func exportChainCmd() *cobra.Command {
+	exportChainCmd := &cobra.Command{
+		Short: "Export the blocks or the transactions of the blockchain",
+		Use:   "exportchain [flags] [db path]",
+		Long: `Exports the blocks from --start to --end of the blockchain database, or their transactions with --txns,
+    as JSON lines, CSV or raw binary records. The records are streamed to the output one block at a time.
+
+    jsonl writes one JSON object per line, in the format of the block and transaction API endpoints.
+    csv writes a header line and one line per block or transaction. The inputs of a transaction are
+    separated by ";", and its outputs are written as "address:coins:hours" separated by ";".
+    binary writes a 4 byte little endian length followed by the encoded signed block or transaction,
+    for each record.
+
+    The database can not be opened while the node is running, use the backup command to copy it first.
+    If no argument is specificed, the default data.db in $HOME/.$COIN/ will be exported.`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE:         exportChain,
+	}
+
+	exportChainCmd.Flags().StringP("format", "f", exportFormatJSONL, "Output format. Options are jsonl, csv and binary")
+	exportChainCmd.Flags().StringP("output", "o", "", "Output file. Defaults to stdout")
+	exportChainCmd.Flags().Uint64("start", 0, "Sequence of the first block to export")
+	exportChainCmd.Flags().Uint64("end", math.MaxUint64, "Sequence of the last block to export. Defaults to the head block")
+	exportChainCmd.Flags().Bool("txns", false, "Export one record per transaction instead of one record per block")
+
+	return exportChainCmd
+}
+
+func exportChain(c *cobra.Command, args []string) error {
+	format, err := c.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case exportFormatJSONL, exportFormatCSV, exportFormatBinary:
+	default:
+		return errInvalidExportFormat(format)
+	}
+
+	output, err := c.Flags().GetString("output")
+	if err != nil {
+		return err
+	}
+
+	start, err := c.Flags().GetUint64("start")
+	if err != nil {
+		return err
+	}
+
+	end, err := c.Flags().GetUint64("end")
+	if err != nil {
+		return err
+	}
+
+	txns, err := c.Flags().GetBool("txns")
+	if err != nil {
+		return err
+	}
+
+	// get db path
+	dbPath := ""
+	if len(args) > 0 {
+		dbPath = args[0]
+	}
+	dbPath, err = resolveDBPath(cliConfig, dbPath)
+	if err != nil {
+		return err
+	}
+
+	// check if this file exists
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		return fmt.Errorf("db file: %v does not exist", dbPath)
+	}
+
+	db, err := bolt.Open(dbPath, 0600, &bolt.Options{
+		Timeout:  5 * time.Second,
+		ReadOnly: true,
+	})
+
+	if err != nil {
+		return fmt.Errorf("open db failed: %v", err)
+	}
+
+	w := os.Stdout
+	if output != "" {
+		w, err = os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	exporter, err := newChainExporter(bw, format, txns)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		apputil.CatchInterrupt(quitChan)
+	}()
+
+	n, err := blockdb.ExportBlocks(wrapDB(db), visor.DefaultWalker, start, end, quitChan, exporter.WriteBlock)
+	if err != nil && err != blockdb.ErrExportStopped {
+		return fmt.Errorf("exportchain failed: %v", err)
+	}
+
+	if err := exporter.Flush(); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	if output != "" {
+		if err := w.Close(); err != nil {
+			return err
+		}
+	}
+
+	// Keep stdout for the records
+	fmt.Fprintf(os.Stderr, "Exported %d blocks, %d records\n", n, exporter.Records())
+	return nil
+}
+
+// chainExporter writes the blocks or the transactions of the exported blocks in an output format
+type chainExporter interface {
+	WriteBlock(b *coin.SignedBlock) error
+	Flush() error
+	Records() uint64
+}
+
+func newChainExporter(w io.Writer, format string, txns bool) (chainExporter, error) {
+	switch format {
+	case exportFormatJSONL:
+		return &jsonlExporter{
+			enc:  json.NewEncoder(w),
+			txns: txns,
+		}, nil
+	case exportFormatCSV:
+		return &csvExporter{
+			w:    csv.NewWriter(w),
+			txns: txns,
+		}, nil
+	case exportFormatBinary:
+		return &binaryExporter{
+			w:    w,
+			txns: txns,
+		}, nil
+	default:
+		return nil, errInvalidExportFormat(format)
+	}
+}
+
+func errInvalidExportFormat(format string) error {
+	return fmt.Errorf("invalid format %q, must be jsonl, csv or binary", format)
+}
+
+// exportTransaction is a transaction record of the jsonl format
+type exportTransaction struct {
+	BlockSeq  uint64 `json:"block_seq"`
+	BlockHash string `json:"block_hash"`
+	readable.Transaction
+}
+
+type jsonlExporter struct {
+	enc     *json.Encoder
+	txns    bool
+	records uint64
+}
+
+func (e *jsonlExporter) WriteBlock(b *coin.SignedBlock) error {
+	if !e.txns {
+		rb, err := readable.NewBlock(b.Block)
+		if err != nil {
+			return err
+		}
+
+		if err := e.enc.Encode(rb); err != nil {
+			return err
+		}
+		e.records++
+		return nil
+	}
+
+	blockHash := b.HashHeader().Hex()
+	for _, txn := range b.Body.Transactions {
+		rt, err := readable.NewTransactionWithTimestamp(txn, b.Seq() == 0, b.Time())
+		if err != nil {
+			return err
+		}
+
+		if err := e.enc.Encode(exportTransaction{
+			BlockSeq:    b.Seq(),
+			BlockHash:   blockHash,
+			Transaction: *rt,
+		}); err != nil {
+			return err
+		}
+		e.records++
+	}
+
+	return nil
+}
+
+func (e *jsonlExporter) Flush() error {
+	return nil
+}
+
+func (e *jsonlExporter) Records() uint64 {
+	return e.records
+}
+
+var (
+	csvBlockHeader       = []string{"seq", "block_hash", "previous_block_hash", "timestamp", "fee", "version", "tx_body_hash", "ux_hash", "txns", "size"}
+	csvTransactionHeader = []string{"block_seq", "block_hash", "timestamp", "txid", "inner_hash", "type", "length", "inputs", "outputs"}
+)
+
+type csvExporter struct {
+	w             *csv.Writer
+	txns          bool
+	headerWritten bool
+	records       uint64
+}
+
+func (e *csvExporter) WriteBlock(b *coin.SignedBlock) error {
+	if !e.headerWritten {
+		header := csvBlockHeader
+		if e.txns {
+			header = csvTransactionHeader
+		}
+		if err := e.w.Write(header); err != nil {
+			return err
+		}
+		e.headerWritten = true
+	}
+
+	if !e.txns {
+		size, err := b.Size()
+		if err != nil {
+			return err
+		}
+
+		if err := e.w.Write([]string{
+			strconv.FormatUint(b.Seq(), 10),
+			b.HashHeader().Hex(),
+			b.Head.PrevHash.Hex(),
+			strconv.FormatUint(b.Time(), 10),
+			strconv.FormatUint(b.Head.Fee, 10),
+			strconv.FormatUint(uint64(b.Head.Version), 10),
+			b.Head.BodyHash.Hex(),
+			b.Head.UxHash.Hex(),
+			strconv.Itoa(len(b.Body.Transactions)),
+			strconv.FormatUint(uint64(size), 10),
+		}); err != nil {
+			return err
+		}
+		e.records++
+		return nil
+	}
+
+	blockHash := b.HashHeader().Hex()
+	for _, txn := range b.Body.Transactions {
+		rt, err := readable.NewTransaction(txn, b.Seq() == 0)
+		if err != nil {
+			return err
+		}
+
+		outputs := make([]string, len(txn.Out))
+		for i, o := range txn.Out {
+			coins, err := droplet.ToString(o.Coins)
+			if err != nil {
+				return err
+			}
+			outputs[i] = fmt.Sprintf("%s:%s:%d", o.Address, coins, o.Hours)
+		}
+
+		if err := e.w.Write([]string{
+			strconv.FormatUint(b.Seq(), 10),
+			blockHash,
+			strconv.FormatUint(b.Time(), 10),
+			rt.Hash,
+			rt.InnerHash,
+			strconv.FormatUint(uint64(rt.Type), 10),
+			strconv.FormatUint(uint64(rt.Length), 10),
+			strings.Join(rt.In, ";"),
+			strings.Join(outputs, ";"),
+		}); err != nil {
+			return err
+		}
+		e.records++
+	}
+
+	return nil
+}
+
+func (e *csvExporter) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvExporter) Records() uint64 {
+	return e.records
+}
+
+type binaryExporter struct {
+	w       io.Writer
+	txns    bool
+	records uint64
+}
+
+func (e *binaryExporter) WriteBlock(b *coin.SignedBlock) error {
+	if !e.txns {
+		return e.write(encoder.Serialize(*b))
+	}
+
+	for i := range b.Body.Transactions {
+		buf, err := b.Body.Transactions[i].Serialize()
+		if err != nil {
+			return err
+		}
+
+		if err := e.write(buf); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// write writes a record prefixed by its length
+func (e *binaryExporter) write(buf []byte) error {
+	if uint64(len(buf)) > math.MaxUint32 {
+		return fmt.Errorf("record of %d bytes is too large", len(buf))
+	}
+
+	var prefix [4]byte
+	binary.LittleEndian.PutUint32(prefix[:], uint32(len(buf)))
+	if _, err := e.w.Write(prefix[:]); err != nil {
+		return err
+	}
+	if _, err := e.w.Write(buf); err != nil {
+		return err
+	}
+
+	e.records++
+	return nil
+}
+
+func (e *binaryExporter) Flush() error {
+	return nil
+}
+
+func (e *binaryExporter) Records() uint64 {
+	return e.records
+}
@@ -0,0 +1,201 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/cipher/encoder"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/readable"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+)
+
+// makeExportBlocks returns a genesis block and a block spending its output to two addresses
+func makeExportBlocks(t *testing.T) []coin.SignedBlock {
+	pubkey, seckey := cipher.GenerateKeyPair()
+	genAddr := cipher.AddressFromPubKey(pubkey)
+
+	gb, err := coin.NewGenesisBlock(genAddr, 100e6, 1500000000, nil)
+	require.NoError(t, err)
+
+	ux := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])[0]
+
+	var txn coin.Transaction
+	err = txn.PushInput(ux.Hash())
+	require.NoError(t, err)
+	err = txn.PushOutput(testutil.MakeAddress(), 1e6, 10, nil)
+	require.NoError(t, err)
+	err = txn.PushOutput(genAddr, 99e6, 20, nil)
+	require.NoError(t, err)
+	txn.SignInputs([]cipher.SecKey{seckey})
+	err = txn.UpdateHeader()
+	require.NoError(t, err)
+
+	b, err := coin.NewBlock(*gb, gb.Time()+10, cipher.SHA256{}, coin.Transactions{txn}, func(*coin.Transaction) (uint64, error) {
+		return 0, nil
+	})
+	require.NoError(t, err)
+
+	return []coin.SignedBlock{
+		{
+			Block: *gb,
+			Sig:   cipher.MustSignHash(gb.HashHeader(), seckey),
+		},
+		{
+			Block: *b,
+			Sig:   cipher.MustSignHash(b.HashHeader(), seckey),
+		},
+	}
+}
+
+func exportBlocks(t *testing.T, format string, txns bool, blocks []coin.SignedBlock) ([]byte, uint64) {
+	var buf bytes.Buffer
+	e, err := newChainExporter(&buf, format, txns)
+	require.NoError(t, err)
+
+	for i := range blocks {
+		err := e.WriteBlock(&blocks[i])
+		require.NoError(t, err)
+	}
+	err = e.Flush()
+	require.NoError(t, err)
+
+	return buf.Bytes(), e.Records()
+}
+
+func TestExportChainJSONL(t *testing.T) {
+	blocks := makeExportBlocks(t)
+
+	out, records := exportBlocks(t, exportFormatJSONL, false, blocks)
+	require.Equal(t, uint64(2), records)
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	require.Len(t, lines, 2)
+	for i, line := range lines {
+		var rb readable.Block
+		err := json.Unmarshal([]byte(line), &rb)
+		require.NoError(t, err)
+
+		expected, err := readable.NewBlock(blocks[i].Block)
+		require.NoError(t, err)
+		require.Equal(t, *expected, rb)
+	}
+
+	out, records = exportBlocks(t, exportFormatJSONL, true, blocks)
+	require.Equal(t, uint64(2), records)
+
+	lines = strings.Split(strings.TrimSpace(string(out)), "\n")
+	require.Len(t, lines, 2)
+
+	var rt exportTransaction
+	err := json.Unmarshal([]byte(lines[1]), &rt)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), rt.BlockSeq)
+	require.Equal(t, blocks[1].HashHeader().Hex(), rt.BlockHash)
+	require.Equal(t, blocks[1].Time(), rt.Timestamp)
+	require.Equal(t, blocks[1].Body.Transactions[0].Hash().Hex(), rt.Hash)
+	require.Len(t, rt.Out, 2)
+}
+
+func TestExportChainCSV(t *testing.T) {
+	blocks := makeExportBlocks(t)
+
+	out, records := exportBlocks(t, exportFormatCSV, false, blocks)
+	require.Equal(t, uint64(2), records)
+
+	rows, err := csv.NewReader(bytes.NewReader(out)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	require.Equal(t, csvBlockHeader, rows[0])
+
+	size, err := blocks[1].Size()
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"1",
+		blocks[1].HashHeader().Hex(),
+		blocks[0].HashHeader().Hex(),
+		fmt.Sprint(blocks[1].Time()),
+		"0",
+		"0",
+		blocks[1].Head.BodyHash.Hex(),
+		blocks[1].Head.UxHash.Hex(),
+		"1",
+		fmt.Sprint(size),
+	}, rows[2])
+
+	out, records = exportBlocks(t, exportFormatCSV, true, blocks)
+	require.Equal(t, uint64(2), records)
+
+	rows, err = csv.NewReader(bytes.NewReader(out)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	require.Equal(t, csvTransactionHeader, rows[0])
+
+	txn := blocks[1].Body.Transactions[0]
+	require.Equal(t, []string{
+		"1",
+		blocks[1].HashHeader().Hex(),
+		fmt.Sprint(blocks[1].Time()),
+		txn.Hash().Hex(),
+		txn.InnerHash.Hex(),
+		"0",
+		fmt.Sprint(txn.Length),
+		txn.In[0].Hex(),
+		fmt.Sprintf("%s:1.000000:10;%s:99.000000:20", txn.Out[0].Address, txn.Out[1].Address),
+	}, rows[2])
+}
+
+func TestExportChainBinary(t *testing.T) {
+	blocks := makeExportBlocks(t)
+
+	readRecords := func(out []byte) [][]byte {
+		var records [][]byte
+		r := bytes.NewReader(out)
+		for r.Len() > 0 {
+			var prefix [4]byte
+			_, err := io.ReadFull(r, prefix[:])
+			require.NoError(t, err)
+
+			buf := make([]byte, binary.LittleEndian.Uint32(prefix[:]))
+			_, err = io.ReadFull(r, buf)
+			require.NoError(t, err)
+			records = append(records, buf)
+		}
+		return records
+	}
+
+	out, n := exportBlocks(t, exportFormatBinary, false, blocks)
+	require.Equal(t, uint64(2), n)
+
+	records := readRecords(out)
+	require.Len(t, records, 2)
+	for i, buf := range records {
+		var b coin.SignedBlock
+		err := encoder.DeserializeRawExact(buf, &b)
+		require.NoError(t, err)
+		require.Equal(t, blocks[i], b)
+	}
+
+	out, n = exportBlocks(t, exportFormatBinary, true, blocks)
+	require.Equal(t, uint64(2), n)
+
+	records = readRecords(out)
+	require.Len(t, records, 2)
+	txn, err := coin.DeserializeTransaction(records[1])
+	require.NoError(t, err)
+	require.Equal(t, blocks[1].Body.Transactions[0], txn)
+}
+
+func TestNewChainExporterInvalidFormat(t *testing.T) {
+	_, err := newChainExporter(&bytes.Buffer{}, "xml", false)
+	require.EqualError(t, err, `invalid format "xml", must be jsonl, csv or binary`)
+}
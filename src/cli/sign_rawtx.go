@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+
+	"github.com/SkycoinProject/cx-chains/src/api"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/wallet"
+)
+
+func signTxnCmd() *cobra.Command {
+	signTxnCmd := &cobra.Command{
+		Short: "Sign an unsigned transaction envelope offline",
+		Use:   "signTransaction [flags] [envelope file]",
+		Long: fmt.Sprintf(`Sign the inputs of a transaction envelope created with "createRawTransaction --unsigned",
+    or with the transaction creation APIs of a node. The envelope includes the transaction inputs,
+    so signing does not require a connection to a node and can be done on an air-gapped machine.
+    The signed envelope can be broadcast with the broadcastTransaction command.
+
+    The default wallet (%s) will be used if no wallet was specified.
+    Inputs that are already signed are skipped. The wallet must be able to sign all other inputs.
+
+    Use caution when using the "-p" command. If you have command history enabled
+    your wallet encryption password can be recovered from the history log. If you
+    do not include the "-p" option you will be prompted to enter your password
+    after you enter your command.`, cliConfig.FullWalletPath()),
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			walletFile, err := c.Flags().GetString("wallet-file")
+			if err != nil {
+				return err
+			}
+
+			w, err := resolveWalletPath(cliConfig, walletFile)
+			if err != nil {
+				return err
+			}
+
+			password, err := c.Flags().GetString("password")
+			if err != nil {
+				return err
+			}
+
+			envelope, err := readTxnEnvelope(args[0])
+			if err != nil {
+				return err
+			}
+
+			wlt, err := wallet.Load(w)
+			if err != nil {
+				printHelp(c)
+				return WalletLoadError{err}
+			}
+
+			signed, err := SignRawTxn(wlt, envelope, NewPasswordReader([]byte(password)))
+			if err != nil {
+				return err
+			}
+
+			return printJSON(signed)
+		},
+	}
+
+	signTxnCmd.Flags().StringP("wallet-file", "f", "", "wallet file or path. If no path is specified your default wallet path will be used.")
+	signTxnCmd.Flags().StringP("password", "p", "", "Wallet password")
+
+	return signTxnCmd
+}
+
+// readTxnEnvelope reads a transaction envelope from a JSON file
+func readTxnEnvelope(filename string) (*api.CreateTransactionResponse, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var envelope api.CreateTransactionResponse
+	if err := json.Unmarshal(b, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid transaction envelope: %v", err)
+	}
+
+	return &envelope, nil
+}
+
+// SignRawTxn signs the inputs of a transaction envelope that belong to the wallet, and returns the signed envelope
+func SignRawTxn(wlt *wallet.Wallet, envelope *api.CreateTransactionResponse, pr PasswordReader) (*api.CreateTransactionResponse, error) {
+	txn, inputs, err := envelope.TransactionAndInputs()
+	if err != nil {
+		return nil, err
+	}
+
+	uxOuts := make([]coin.UxOut, len(inputs))
+	for i, in := range inputs {
+		uxOuts[i] = in.UxOut
+	}
+
+	switch pr.(type) {
+	case nil:
+		if wlt.IsEncrypted() {
+			return nil, wallet.ErrWalletEncrypted
+		}
+	case PasswordFromBytes:
+		p, err := pr.Password()
+		if err != nil {
+			return nil, err
+		}
+
+		if !wlt.IsEncrypted() && len(p) != 0 {
+			return nil, wallet.ErrWalletNotEncrypted
+		}
+	}
+
+	var signedTxn *coin.Transaction
+	if wlt.IsEncrypted() {
+		password, err := pr.Password()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := wlt.GuardView(password, func(w *wallet.Wallet) error {
+			var err error
+			signedTxn, err = w.SignTransaction(txn, nil, uxOuts)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+	} else {
+		signedTxn, err = wlt.SignTransaction(txn, nil, uxOuts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return api.NewCreateTransactionResponse(signedTxn, inputs)
+}
@@ -109,7 +109,7 @@ func TestBlockBodyHash(t *testing.T) {
 }
 
 func TestNewGenesisBlock(t *testing.T) {
-	gb, err := NewGenesisBlock(genAddress, _genCoins, _genTime)
+	gb, err := NewGenesisBlock(genAddress, _genCoins, _genTime, nil)
 	require.NoError(t, err)
 
 	require.Equal(t, cipher.SHA256{}, gb.Head.PrevHash)
@@ -132,7 +132,7 @@ func TestNewGenesisBlock(t *testing.T) {
 
 func TestCreateUnspent(t *testing.T) {
 	txn := Transaction{}
-	err := txn.PushOutput(genAddress, 11e6, 255)
+	err := txn.PushOutput(genAddress, 11e6, 255, nil)
 	require.NoError(t, err)
 	bh := BlockHeader{
 		Time:  tNow(),
@@ -170,7 +170,7 @@ func TestCreateUnspent(t *testing.T) {
 
 func TestCreateUnspents(t *testing.T) {
 	txn := Transaction{}
-	err := txn.PushOutput(genAddress, 11e6, 255)
+	err := txn.PushOutput(genAddress, 11e6, 255, nil)
 	require.NoError(t, err)
 	bh := BlockHeader{
 		Time:  tNow(),
@@ -0,0 +1,391 @@
+package coin
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+)
+
+/*
+A multisig transaction has the type TransactionTypeMultisig and spends outputs owned by multisig addresses.
+
+Each input is authorized by a MultisigWitness, which lists the public keys of the multisig address
+and a signature slot for each public key. The signed hash is the same as for a standard input,
+the SHA256 sum of the transaction inner hash and the hash of the output being spent,
+so the witnesses can be signed in any order and by different parties.
+
+The witnesses are stored in the Sigs array, so that the transaction encoding is unchanged.
+For each input in order, the Sigs array has:
+- a header: the number of required signatures in byte 0 and the number of public keys in byte 1, the other bytes are 0
+- the public keys, concatenated and split into 65 byte chunks, the last one padded with 0s
+- a signature for each public key, in the order of the public keys, which is null if the public key did not sign
+*/
+
+const (
+	// TransactionTypeMultisig is the type of a transaction which spends outputs of multisig addresses
+	TransactionTypeMultisig uint8 = 1
+)
+
+var (
+	// ErrNotMultisigTransaction is returned for multisig operations on a transaction which is not a multisig transaction
+	ErrNotMultisigTransaction = errors.New("Transaction is not a multisig transaction")
+	// ErrInvalidMultisigWitness is returned if the multisig witnesses of a transaction cannot be decoded
+	ErrInvalidMultisigWitness = errors.New("Invalid multisig witness")
+	// ErrMultisigNotEnabled is returned for a multisig transaction, or a transaction with outputs to multisig addresses,
+	// if multisig transactions are not enabled by params.MultisigActivationSeq
+	ErrMultisigNotEnabled = errors.New("Multisig transactions are not enabled")
+)
+
+// MultisigWitness authorizes spending an output owned by a multisig address
+type MultisigWitness struct {
+	Required int
+	PubKeys  []cipher.PubKey
+	// Sigs has the signature of each public key, which is null if the public key did not sign
+	Sigs []cipher.Sig
+}
+
+// NewMultisigWitness creates an unsigned MultisigWitness for the multisig address of the public keys
+func NewMultisigWitness(required int, pubKeys []cipher.PubKey) (MultisigWitness, error) {
+	if err := cipher.VerifyMultisigPubKeys(required, pubKeys); err != nil {
+		return MultisigWitness{}, err
+	}
+
+	return MultisigWitness{
+		Required: required,
+		PubKeys:  cipher.SortMultisigPubKeys(pubKeys),
+		Sigs:     make([]cipher.Sig, len(pubKeys)),
+	}, nil
+}
+
+// Address returns the multisig address of the witness
+func (w MultisigWitness) Address() (cipher.Address, error) {
+	return cipher.MultisigAddress(w.Required, w.PubKeys)
+}
+
+// SignedCount returns the number of signatures of the witness
+func (w MultisigWitness) SignedCount() int {
+	n := 0
+	for _, s := range w.Sigs {
+		if !s.Null() {
+			n++
+		}
+	}
+	return n
+}
+
+// Complete returns true if the witness has the required number of signatures
+func (w MultisigWitness) Complete() bool {
+	return w.SignedCount() >= w.Required
+}
+
+// verify checks that the signatures of the witness signed hash
+func (w MultisigWitness) verify(hash cipher.SHA256) error {
+	for i, s := range w.Sigs {
+		if s.Null() {
+			continue
+		}
+		if err := cipher.VerifyPubKeySignedHash(w.PubKeys[i], s, hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w MultisigWitness) copy() MultisigWitness {
+	c := MultisigWitness{
+		Required: w.Required,
+		PubKeys:  make([]cipher.PubKey, len(w.PubKeys)),
+		Sigs:     make([]cipher.Sig, len(w.Sigs)),
+	}
+	copy(c.PubKeys, w.PubKeys)
+	copy(c.Sigs, w.Sigs)
+	return c
+}
+
+// multisigPubKeyChunks returns the number of Sig sized chunks needed to store n public keys
+func multisigPubKeyChunks(n int) int {
+	size := n * len(cipher.PubKey{})
+	chunk := len(cipher.Sig{})
+	return (size + chunk - 1) / chunk
+}
+
+func encodeMultisigWitnesses(witnesses []MultisigWitness) []cipher.Sig {
+	var sigs []cipher.Sig
+	for _, w := range witnesses {
+		var header cipher.Sig
+		header[0] = byte(w.Required)
+		header[1] = byte(len(w.PubKeys))
+		sigs = append(sigs, header)
+
+		b := make([]byte, multisigPubKeyChunks(len(w.PubKeys))*len(cipher.Sig{}))
+		for i, p := range w.PubKeys {
+			copy(b[i*len(p):], p[:])
+		}
+		for i := 0; i < len(b); i += len(cipher.Sig{}) {
+			var chunk cipher.Sig
+			copy(chunk[:], b[i:])
+			sigs = append(sigs, chunk)
+		}
+
+		sigs = append(sigs, w.Sigs...)
+	}
+	return sigs
+}
+
+func decodeMultisigWitnesses(sigs []cipher.Sig, nInputs int) ([]MultisigWitness, error) {
+	witnesses := make([]MultisigWitness, 0, nInputs)
+	for len(witnesses) < nInputs {
+		if len(sigs) == 0 {
+			return nil, ErrInvalidMultisigWitness
+		}
+
+		header := sigs[0]
+		sigs = sigs[1:]
+		for _, b := range header[2:] {
+			if b != 0 {
+				return nil, ErrInvalidMultisigWitness
+			}
+		}
+
+		required := int(header[0])
+		n := int(header[1])
+		if n == 0 || n > cipher.MaxMultisigPubKeys || required == 0 || required > n {
+			return nil, ErrInvalidMultisigWitness
+		}
+
+		chunks := multisigPubKeyChunks(n)
+		if len(sigs) < chunks+n {
+			return nil, ErrInvalidMultisigWitness
+		}
+
+		b := make([]byte, 0, chunks*len(cipher.Sig{}))
+		for _, chunk := range sigs[:chunks] {
+			b = append(b, chunk[:]...)
+		}
+		sigs = sigs[chunks:]
+
+		pubKeySize := len(cipher.PubKey{})
+		for _, x := range b[n*pubKeySize:] {
+			if x != 0 {
+				return nil, ErrInvalidMultisigWitness
+			}
+		}
+
+		w := MultisigWitness{
+			Required: required,
+			PubKeys:  make([]cipher.PubKey, n),
+			Sigs:     make([]cipher.Sig, n),
+		}
+		for i := range w.PubKeys {
+			copy(w.PubKeys[i][:], b[i*pubKeySize:])
+		}
+		copy(w.Sigs, sigs[:n])
+		sigs = sigs[n:]
+
+		witnesses = append(witnesses, w)
+	}
+
+	if len(sigs) != 0 {
+		return nil, ErrInvalidMultisigWitness
+	}
+
+	return witnesses, nil
+}
+
+// IsMultisig returns true if the transaction is a multisig transaction
+func (txn *Transaction) IsMultisig() bool {
+	return txn.Type == TransactionTypeMultisig
+}
+
+// UsesMultisig returns true if the transaction is a multisig transaction or has outputs to multisig addresses.
+// Such a transaction can only be included in a block once multisig is active, see params.MultisigActive
+func (txn *Transaction) UsesMultisig() bool {
+	if txn.IsMultisig() {
+		return true
+	}
+
+	for _, o := range txn.Out {
+		if o.Address.Version == cipher.AddressVersionMultisig {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MultisigWitnesses returns the multisig witness of each input of a multisig transaction
+func (txn *Transaction) MultisigWitnesses() ([]MultisigWitness, error) {
+	if !txn.IsMultisig() {
+		return nil, ErrNotMultisigTransaction
+	}
+	return decodeMultisigWitnesses(txn.Sigs, len(txn.In))
+}
+
+// SetMultisigWitnesses makes the transaction a multisig transaction with a witness for each input,
+// and updates the transaction header. The inner hash is not changed by the witnesses.
+func (txn *Transaction) SetMultisigWitnesses(witnesses []MultisigWitness) error {
+	if len(witnesses) != len(txn.In) {
+		return errors.New("Number of multisig witnesses does not match number of inputs")
+	}
+
+	for _, w := range witnesses {
+		if err := cipher.VerifyMultisigPubKeys(w.Required, w.PubKeys); err != nil {
+			return err
+		}
+		if len(w.Sigs) != len(w.PubKeys) {
+			return errors.New("Number of multisig witness signatures does not match number of public keys")
+		}
+	}
+
+	txn.Type = TransactionTypeMultisig
+	txn.Sigs = encodeMultisigWitnesses(witnesses)
+	return txn.UpdateHeader()
+}
+
+// SignMultisigInput signs the input of a multisig transaction at index with the key.
+// The public key of the key must be one of the public keys of the input's witness.
+// Returns an error if the key already signed the input.
+func (txn *Transaction) SignMultisigInput(key cipher.SecKey, index int) error {
+	if index < 0 || index >= len(txn.In) {
+		return errors.New("Signature index out of range")
+	}
+
+	witnesses, err := txn.MultisigWitnesses()
+	if err != nil {
+		return err
+	}
+
+	pubKey, err := cipher.PubKeyFromSecKey(key)
+	if err != nil {
+		return err
+	}
+
+	w := witnesses[index]
+	for i, p := range w.PubKeys {
+		if p != pubKey {
+			continue
+		}
+
+		if !w.Sigs[i].Null() {
+			return errors.New("Input already signed")
+		}
+
+		h := cipher.AddSHA256(txn.InnerHash, txn.In[index])
+		w.Sigs[i] = cipher.MustSignHash(h, key)
+		return txn.SetMultisigWitnesses(witnesses)
+	}
+
+	return errors.New("Key is not a public key of the multisig input")
+}
+
+// MergeMultisigSignatures combines the signatures of copies of the same multisig transaction,
+// signed by different signers, into one transaction
+func MergeMultisigSignatures(txns []Transaction) (*Transaction, error) {
+	if len(txns) == 0 {
+		return nil, errors.New("No transactions to merge")
+	}
+
+	merged := txns[0]
+	witnesses, err := merged.MultisigWitnesses()
+	if err != nil {
+		return nil, err
+	}
+	for i := range witnesses {
+		witnesses[i] = witnesses[i].copy()
+	}
+
+	for i := range txns[1:] {
+		txn := &txns[i+1]
+		if txn.InnerHash != merged.InnerHash {
+			return nil, fmt.Errorf("Transaction %d is not the same transaction, its inner hash differs", i+1)
+		}
+
+		ws, err := txn.MultisigWitnesses()
+		if err != nil {
+			return nil, err
+		}
+
+		for j, w := range ws {
+			if w.Required != witnesses[j].Required || len(w.PubKeys) != len(witnesses[j].PubKeys) {
+				return nil, fmt.Errorf("Transaction %d has a different multisig witness for input %d", i+1, j)
+			}
+
+			for k, p := range w.PubKeys {
+				if p != witnesses[j].PubKeys[k] {
+					return nil, fmt.Errorf("Transaction %d has a different multisig witness for input %d", i+1, j)
+				}
+
+				if witnesses[j].Sigs[k].Null() {
+					witnesses[j].Sigs[k] = w.Sigs[k]
+				}
+			}
+		}
+	}
+
+	merged.Sigs = nil
+	if err := merged.SetMultisigWitnesses(witnesses); err != nil {
+		return nil, err
+	}
+
+	return &merged, nil
+}
+
+// verifyMultisig verifies the witnesses of a multisig transaction.
+// If signed is true, every witness must have the required number of signatures,
+// otherwise at least one witness must be missing signatures.
+func (txn *Transaction) verifyMultisig(witnesses []MultisigWitness, signed bool) error {
+	complete := true
+	for i, w := range witnesses {
+		if !w.Complete() {
+			// Check that signed transactions do not have any unsigned multisig inputs
+			if signed {
+				return errors.New("Unsigned input in transaction")
+			}
+			complete = false
+		}
+
+		hash := cipher.AddSHA256(txn.InnerHash, txn.In[i])
+		if err := w.verify(hash); err != nil {
+			return err
+		}
+	}
+
+	// Check that unsigned transactions have at least one input that is not fully signed
+	if !signed && complete {
+		return errors.New("Unsigned transaction must contain a null signature")
+	}
+
+	return nil
+}
+
+// verifyMultisigInputSignatures checks that the witness of each input is for the address of the output being spent,
+// and that its signatures are valid. If partial is false, each witness must have the required number of signatures.
+func (txn Transaction) verifyMultisigInputSignatures(uxIn UxArray, partial bool) error {
+	witnesses, err := txn.MultisigWitnesses()
+	if err != nil {
+		return err
+	}
+
+	for i, w := range witnesses {
+		addr, err := w.Address()
+		if err != nil {
+			return err
+		}
+
+		if addr != uxIn[i].Body.Address {
+			return errors.New("Multisig witness not valid for output being spent")
+		}
+
+		if !partial && !w.Complete() {
+			return errors.New("Unsigned input in transaction")
+		}
+
+		hash := cipher.AddSHA256(txn.InnerHash, txn.In[i]) // use inner hash, not outer hash
+		if err := w.verify(hash); err != nil {
+			return errors.New("Signature not valid for output being spent")
+		}
+	}
+
+	return nil
+}
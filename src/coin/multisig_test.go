@@ -0,0 +1,299 @@
+package coin
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/params"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+)
+
+// enableMultisig sets params.MultisigActivationSeq and returns a function which restores it
+func enableMultisig(seq uint64) func() {
+	prev := params.MultisigActivationSeq
+	params.MultisigActivationSeq = seq
+	return func() {
+		params.MultisigActivationSeq = prev
+	}
+}
+
+func makeMultisigUxOut(t *testing.T, required, n int) (UxOut, []cipher.PubKey, []cipher.SecKey) {
+	pubKeys := make([]cipher.PubKey, n)
+	secKeys := make([]cipher.SecKey, n)
+	for i := range pubKeys {
+		pubKeys[i], secKeys[i] = cipher.GenerateKeyPair()
+	}
+
+	return UxOut{
+		Head: UxHead{
+			Time:  100,
+			BkSeq: 2,
+		},
+		Body: UxBody{
+			SrcTransaction: testutil.RandSHA256(t),
+			Address:        cipher.MustMultisigAddress(required, pubKeys),
+			Coins:          1e6,
+			Hours:          100,
+		},
+	}, pubKeys, secKeys
+}
+
+func makeMultisigTransaction(t *testing.T, uxOuts []UxOut, witnesses []MultisigWitness) Transaction {
+	txn := Transaction{}
+	for _, ux := range uxOuts {
+		err := txn.PushInput(ux.Hash())
+		require.NoError(t, err)
+	}
+	err := txn.PushOutput(makeAddress(), 1e6, 50, nil)
+	require.NoError(t, err)
+
+	err = txn.SetMultisigWitnesses(witnesses)
+	require.NoError(t, err)
+	return txn
+}
+
+func TestMultisigWitnessesEncoding(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 4, cipher.MaxMultisigPubKeys} {
+		_, pubKeys, _ := makeMultisigUxOut(t, 1, n)
+		w, err := NewMultisigWitness(1, pubKeys)
+		require.NoError(t, err)
+		w.Sigs[0] = cipher.MustSignHash(testutil.RandSHA256(t), cipher.MustGenerateDeterministicKeyPairs([]byte("seed"), 1)[0])
+
+		_, pubKeys2, _ := makeMultisigUxOut(t, 2, 3)
+		w2, err := NewMultisigWitness(2, pubKeys2)
+		require.NoError(t, err)
+
+		witnesses := []MultisigWitness{w, w2}
+		sigs := encodeMultisigWitnesses(witnesses)
+		require.Len(t, sigs, 1+multisigPubKeyChunks(n)+n+1+multisigPubKeyChunks(3)+3)
+
+		decoded, err := decodeMultisigWitnesses(sigs, 2)
+		require.NoError(t, err)
+		require.Equal(t, witnesses, decoded)
+
+		// Extra words are invalid
+		_, err = decodeMultisigWitnesses(append(sigs, cipher.Sig{}), 2)
+		require.Equal(t, ErrInvalidMultisigWitness, err)
+
+		// Missing words are invalid
+		_, err = decodeMultisigWitnesses(sigs[:len(sigs)-1], 2)
+		require.Equal(t, ErrInvalidMultisigWitness, err)
+
+		// Invalid header
+		bad := make([]cipher.Sig, len(sigs))
+		copy(bad, sigs)
+		bad[0][2] = 1
+		_, err = decodeMultisigWitnesses(bad, 2)
+		require.Equal(t, ErrInvalidMultisigWitness, err)
+
+		copy(bad, sigs)
+		bad[0][0] = byte(n + 1)
+		_, err = decodeMultisigWitnesses(bad, 2)
+		require.Equal(t, ErrInvalidMultisigWitness, err)
+	}
+}
+
+func TestMultisigTransactionSignAndVerify(t *testing.T) {
+	defer enableMultisig(0)()
+
+	ux0, pubKeys0, secKeys0 := makeMultisigUxOut(t, 2, 3)
+	ux1, pubKeys1, secKeys1 := makeMultisigUxOut(t, 1, 2)
+
+	w0, err := NewMultisigWitness(2, pubKeys0)
+	require.NoError(t, err)
+	w1, err := NewMultisigWitness(1, pubKeys1)
+	require.NoError(t, err)
+
+	uxIn := UxArray{ux0, ux1}
+	txn := makeMultisigTransaction(t, uxIn, []MultisigWitness{w0, w1})
+	require.True(t, txn.IsMultisig())
+	require.Equal(t, TransactionTypeMultisig, txn.Type)
+	require.True(t, txn.IsFullyUnsigned())
+	require.False(t, txn.IsFullySigned())
+
+	// The witnesses do not change the inner hash
+	require.Equal(t, txn.HashInner(), txn.InnerHash)
+
+	require.NoError(t, txn.VerifyUnsigned())
+	require.Error(t, txn.Verify())
+	require.NoError(t, txn.VerifyPartialInputSignatures(uxIn))
+	require.Error(t, txn.VerifyInputSignatures(uxIn))
+
+	// Standard signing is not allowed
+	err = txn.SignInput(secKeys0[0], 0)
+	require.EqualError(t, err, "Multisig transaction inputs must be signed with SignMultisigInput")
+
+	// Sign the first input with one key, copy the transaction and sign it with another key
+	err = txn.SignMultisigInput(secKeys0[0], 0)
+	require.NoError(t, err)
+	err = txn.SignMultisigInput(secKeys0[0], 0)
+	require.EqualError(t, err, "Input already signed")
+	err = txn.SignMultisigInput(secKeys1[0], 0)
+	require.EqualError(t, err, "Key is not a public key of the multisig input")
+	err = txn.SignMultisigInput(secKeys0[0], 2)
+	require.EqualError(t, err, "Signature index out of range")
+
+	require.False(t, txn.IsFullyUnsigned())
+	require.False(t, txn.IsFullySigned())
+	require.NoError(t, txn.VerifyUnsigned())
+	require.NoError(t, txn.VerifyPartialInputSignatures(uxIn))
+
+	other := copyTransaction(txn)
+	err = other.SignMultisigInput(secKeys0[2], 0)
+	require.NoError(t, err)
+	err = other.SignMultisigInput(secKeys1[1], 1)
+	require.NoError(t, err)
+	require.True(t, other.IsFullySigned())
+	require.NoError(t, other.Verify())
+	require.NoError(t, other.VerifyInputSignatures(uxIn))
+	require.Error(t, other.VerifyUnsigned())
+
+	// Multisig transactions are invalid if multisig is not enabled
+	restore := enableMultisig(math.MaxUint64)
+	require.Equal(t, ErrMultisigNotEnabled, other.Verify())
+	restore()
+
+	// The signatures change the transaction hash, but not the inner hash
+	require.Equal(t, txn.InnerHash, other.InnerHash)
+	require.NotEqual(t, txn.Hash(), other.Hash())
+
+	// The witness must be for the address of the output being spent
+	_, pubKeys2, secKeys2 := makeMultisigUxOut(t, 1, 2)
+	w2, err := NewMultisigWitness(1, pubKeys2)
+	require.NoError(t, err)
+	wrong := makeMultisigTransaction(t, UxArray{ux1}, []MultisigWitness{w2})
+	err = wrong.SignMultisigInput(secKeys2[0], 0)
+	require.NoError(t, err)
+	require.NoError(t, wrong.Verify())
+	err = wrong.VerifyInputSignatures(UxArray{ux1})
+	require.EqualError(t, err, "Multisig witness not valid for output being spent")
+
+	// An invalid signature fails
+	bad := copyTransaction(other)
+	witnesses, err := bad.MultisigWitnesses()
+	require.NoError(t, err)
+	for i, s := range witnesses[0].Sigs {
+		if !s.Null() {
+			witnesses[0].Sigs[i] = cipher.MustSignHash(testutil.RandSHA256(t), secKeys0[i])
+			break
+		}
+	}
+	err = bad.SetMultisigWitnesses(witnesses)
+	require.NoError(t, err)
+	require.Error(t, bad.Verify())
+	require.EqualError(t, bad.VerifyInputSignatures(uxIn), "Signature not valid for output being spent")
+}
+
+func TestMergeMultisigSignatures(t *testing.T) {
+	defer enableMultisig(0)()
+
+	ux0, pubKeys0, secKeys0 := makeMultisigUxOut(t, 2, 3)
+	ux1, pubKeys1, secKeys1 := makeMultisigUxOut(t, 2, 2)
+
+	w0, err := NewMultisigWitness(2, pubKeys0)
+	require.NoError(t, err)
+	w1, err := NewMultisigWitness(2, pubKeys1)
+	require.NoError(t, err)
+
+	uxIn := UxArray{ux0, ux1}
+	txn := makeMultisigTransaction(t, uxIn, []MultisigWitness{w0, w1})
+
+	// Each signer signs its own copy of the unsigned transaction
+	a := copyTransaction(txn)
+	err = a.SignMultisigInput(secKeys0[0], 0)
+	require.NoError(t, err)
+	err = a.SignMultisigInput(secKeys1[0], 1)
+	require.NoError(t, err)
+
+	b := copyTransaction(txn)
+	err = b.SignMultisigInput(secKeys0[1], 0)
+	require.NoError(t, err)
+	err = b.SignMultisigInput(secKeys1[1], 1)
+	require.NoError(t, err)
+
+	merged, err := MergeMultisigSignatures([]Transaction{a, b})
+	require.NoError(t, err)
+	require.True(t, merged.IsFullySigned())
+	require.NoError(t, merged.Verify())
+	require.NoError(t, merged.VerifyInputSignatures(uxIn))
+
+	// The merged transactions are not modified
+	require.False(t, a.IsFullySigned())
+	require.False(t, b.IsFullySigned())
+
+	// Merging with the unsigned transaction makes no difference
+	merged2, err := MergeMultisigSignatures([]Transaction{txn, a, b})
+	require.NoError(t, err)
+	require.Equal(t, merged.Hash(), merged2.Hash())
+
+	_, err = MergeMultisigSignatures(nil)
+	require.EqualError(t, err, "No transactions to merge")
+
+	// A different transaction can't be merged
+	ux2, pubKeys2, _ := makeMultisigUxOut(t, 2, 3)
+	w2, err := NewMultisigWitness(2, pubKeys2)
+	require.NoError(t, err)
+	c := makeMultisigTransaction(t, UxArray{ux2, ux1}, []MultisigWitness{w2, w1})
+	_, err = MergeMultisigSignatures([]Transaction{a, c})
+	require.EqualError(t, err, "Transaction 1 is not the same transaction, its inner hash differs")
+
+	// A transaction with different witnesses can't be merged
+	d := copyTransaction(txn)
+	err = d.SetMultisigWitnesses([]MultisigWitness{w2, w1})
+	require.NoError(t, err)
+	_, err = MergeMultisigSignatures([]Transaction{a, d})
+	require.EqualError(t, err, "Transaction 1 has a different multisig witness for input 0")
+
+	// A standard transaction can't be merged
+	_, err = MergeMultisigSignatures([]Transaction{makeTransaction(t)})
+	require.Equal(t, ErrNotMultisigTransaction, err)
+}
+
+func TestSetMultisigWitnesses(t *testing.T) {
+	ux0, pubKeys0, _ := makeMultisigUxOut(t, 2, 3)
+	w0, err := NewMultisigWitness(2, pubKeys0)
+	require.NoError(t, err)
+
+	txn := Transaction{}
+	err = txn.PushInput(ux0.Hash())
+	require.NoError(t, err)
+	err = txn.PushOutput(makeAddress(), 1e6, 50, nil)
+	require.NoError(t, err)
+
+	err = txn.SetMultisigWitnesses(nil)
+	require.EqualError(t, err, "Number of multisig witnesses does not match number of inputs")
+
+	bad := w0.copy()
+	bad.Sigs = bad.Sigs[:2]
+	err = txn.SetMultisigWitnesses([]MultisigWitness{bad})
+	require.EqualError(t, err, "Number of multisig witness signatures does not match number of public keys")
+
+	bad = w0.copy()
+	bad.Required = 4
+	err = txn.SetMultisigWitnesses([]MultisigWitness{bad})
+	require.Equal(t, cipher.ErrMultisigRequired, err)
+
+	err = txn.SetMultisigWitnesses([]MultisigWitness{w0})
+	require.NoError(t, err)
+
+	// The header is updated and the type is kept
+	length := txn.Length
+	require.NotZero(t, length)
+	err = txn.UpdateHeader()
+	require.NoError(t, err)
+	require.Equal(t, TransactionTypeMultisig, txn.Type)
+	require.Equal(t, length, txn.Length)
+
+	// Serialization roundtrip
+	b, err := txn.Serialize()
+	require.NoError(t, err)
+	txn2, err := DeserializeTransaction(b)
+	require.NoError(t, err)
+	require.Equal(t, txn, txn2)
+	ws, err := txn2.MultisigWitnesses()
+	require.NoError(t, err)
+	require.Equal(t, []MultisigWitness{w0}, ws)
+}
@@ -14,7 +14,7 @@ func encodeSizeTransactionOutputs(obj *transactionOutputs) uint64 {
 
 	// obj.Out
 	i0 += 4
-	{
+	for _, x := range obj.Out {
 		i1 := uint64(0)
 
 		// x.Address.Version
@@ -30,11 +30,9 @@ func encodeSizeTransactionOutputs(obj *transactionOutputs) uint64 {
 		i1 += 8
 
 		// x.ProgramState
-		// WARNING: obj.Out[0].ProgramState manually changed from x.ProgramState
-		// WARNING: This is not considering program states in different `Out`s with different lengths
-		i1 += 4 + uint64(len(obj.Out[0].ProgramState))
+		i1 += 4 + uint64(len(x.ProgramState))
 
-		i0 += uint64(len(obj.Out)) * i1
+		i0 += i1
 	}
 
 	return i0
@@ -46,7 +46,7 @@ func encodeSizeTransaction(obj *Transaction) uint64 {
 
 	// obj.Out
 	i0 += 4
-	{
+	for _, x := range obj.Out {
 		i1 := uint64(0)
 
 		// x.Address.Version
@@ -62,11 +62,9 @@ func encodeSizeTransaction(obj *Transaction) uint64 {
 		i1 += 8
 
 		// x.ProgramState
-		// WARNING: obj.Out[0].ProgramState manually changed from x.ProgramState
-		// WARNING: This is not considering program states in different `Out`s with different lengths
-		i1 += 4 + uint64(len(obj.Out[0].ProgramState))
+		i1 += 4 + uint64(len(x.ProgramState))
 
-		i0 += uint64(len(obj.Out)) * i1
+		i0 += i1
 	}
 
 	// obj.MainExpressions
@@ -10,6 +10,7 @@ import (
 	"sort"
 
 	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/params"
 	"github.com/SkycoinProject/cx-chains/src/util/mathutil"
 )
 
@@ -100,8 +101,19 @@ func (txn *Transaction) verify(signed bool) error {
 		return errors.New("No outputs")
 	}
 
+	if txn.UsesMultisig() && !params.MultisigEnabled() {
+		return ErrMultisigNotEnabled
+	}
+
 	// Check signature index fields
-	if len(txn.Sigs) != len(txn.In) {
+	var witnesses []MultisigWitness
+	if txn.IsMultisig() {
+		var err error
+		witnesses, err = txn.MultisigWitnesses()
+		if err != nil {
+			return err
+		}
+	} else if len(txn.Sigs) != len(txn.In) {
 		return errors.New("Invalid number of signatures")
 	}
 	if len(txn.Sigs) > math.MaxUint16 {
@@ -121,7 +133,7 @@ func (txn *Transaction) verify(signed bool) error {
 		return errors.New("Duplicate spend")
 	}
 
-	if txn.Type != 0 {
+	if txn.Type != 0 && txn.Type != TransactionTypeMultisig {
 		return errors.New("transaction type invalid")
 	}
 
@@ -180,6 +192,10 @@ func (txn *Transaction) verify(signed bool) error {
 		return errors.New("InnerHash does not match computed hash")
 	}
 
+	if txn.IsMultisig() {
+		return txn.verifyMultisig(witnesses, signed)
+	}
+
 	// Validate signatures
 	for i, sig := range txn.Sigs {
 		if sig.Null() {
@@ -211,7 +227,7 @@ func (txn Transaction) verifyInputSignaturesPrelude(uxIn UxArray) error {
 	if len(txn.In) != len(uxIn) {
 		return errors.New("txn.In != uxIn")
 	}
-	if len(txn.In) != len(txn.Sigs) {
+	if !txn.IsMultisig() && len(txn.In) != len(txn.Sigs) {
 		return errors.New("txn.In != txn.Sigs")
 	}
 	if txn.InnerHash != txn.HashInner() {
@@ -234,6 +250,10 @@ func (txn Transaction) VerifyInputSignatures(uxIn UxArray) error {
 		return err
 	}
 
+	if txn.IsMultisig() {
+		return txn.verifyMultisigInputSignatures(uxIn, false)
+	}
+
 	// Check signatures against unspent address
 	for i := range txn.In {
 		if txn.Sigs[i].Null() {
@@ -259,6 +279,10 @@ func (txn Transaction) VerifyPartialInputSignatures(uxIn UxArray) error {
 		return err
 	}
 
+	if txn.IsMultisig() {
+		return txn.verifyMultisigInputSignatures(uxIn, true)
+	}
+
 	// Check signatures against unspent address for signatures that are not null
 	for i := range txn.In {
 		if txn.Sigs[i].Null() {
@@ -316,6 +340,10 @@ func (txn *Transaction) SignInput(key cipher.SecKey, index int) error {
 		return errors.New("Signature index out of range")
 	}
 
+	if txn.IsMultisig() {
+		return errors.New("Multisig transaction inputs must be signed with SignMultisigInput")
+	}
+
 	if len(txn.Sigs) == 0 {
 		txn.Sigs = make([]cipher.Sig, len(txn.In))
 	}
@@ -371,6 +399,19 @@ func (txn *Transaction) Size() (uint32, error) {
 // Unsigned transactions have a full signature array, but the signatures are null.
 // Returns true if the signatures array is empty.
 func (txn *Transaction) IsFullyUnsigned() bool {
+	if txn.IsMultisig() {
+		witnesses, err := txn.MultisigWitnesses()
+		if err != nil {
+			return false
+		}
+		for _, w := range witnesses {
+			if w.SignedCount() != 0 {
+				return false
+			}
+		}
+		return true
+	}
+
 	for _, s := range txn.Sigs {
 		if !s.Null() {
 			return false
@@ -382,11 +423,25 @@ func (txn *Transaction) IsFullyUnsigned() bool {
 
 // IsFullySigned returns true if the transaction is fully signed.
 // Returns true if the signatures array is empty.
+// A multisig transaction is fully signed if each input has the required number of signatures.
 func (txn *Transaction) IsFullySigned() bool {
 	if len(txn.Sigs) == 0 {
 		return false
 	}
 
+	if txn.IsMultisig() {
+		witnesses, err := txn.MultisigWitnesses()
+		if err != nil {
+			return false
+		}
+		for _, w := range witnesses {
+			if !w.Complete() {
+				return false
+			}
+		}
+		return true
+	}
+
 	for _, s := range txn.Sigs {
 		if s.Null() {
 			return false
@@ -447,7 +502,9 @@ func (txn *Transaction) UpdateHeader() error {
 		return err
 	}
 	txn.Length = s
-	txn.Type = byte(0x00)
+	if txn.Type != TransactionTypeMultisig {
+		txn.Type = byte(0x00)
+	}
 	txn.InnerHash = txn.HashInner()
 	return nil
 }
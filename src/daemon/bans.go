@@ -0,0 +1,163 @@
+package daemon
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Misbehavior scores added to a peer's score.
+// A peer is banned when its score reaches DaemonConfig.BanScoreThreshold.
+const (
+	// misbehaviorInvalidBlock is scored when a peer sends a block that is not signed by the blockchain pubkey
+	misbehaviorInvalidBlock = 100
+	// misbehaviorMalformedMessage is scored when a peer sends a message that can't be decoded
+	misbehaviorMalformedMessage = 50
+	// misbehaviorStalling is scored when a peer does not introduce itself in time or stops communicating
+	misbehaviorStalling = 20
+)
+
+var (
+	// ErrBanNotFound is returned when removing a ban that does not exist
+	ErrBanNotFound = errors.New("Ban not found")
+)
+
+// Ban is a banned peer IP address
+type Ban struct {
+	IP      string
+	Reason  string
+	Created time.Time
+	Expires time.Time
+}
+
+// Bans scores peer misbehavior by IP address and bans the IP addresses whose score reaches a threshold
+type Bans struct {
+	sync.Mutex
+	threshold int
+	duration  time.Duration
+	scores    map[string]int
+	bans      map[string]Ban
+	now       func() time.Time
+}
+
+// NewBans creates Bans. IP addresses are banned for duration when their score reaches threshold.
+func NewBans(threshold int, duration time.Duration) *Bans {
+	return &Bans{
+		threshold: threshold,
+		duration:  duration,
+		scores:    make(map[string]int),
+		bans:      make(map[string]Ban),
+		now: func() time.Time {
+			return time.Now().UTC()
+		},
+	}
+}
+
+// removeExpired removes the expired bans. Must be called with the lock held.
+func (b *Bans) removeExpired() {
+	now := b.now()
+	for ip, ban := range b.bans {
+		if !now.Before(ban.Expires) {
+			delete(b.bans, ip)
+		}
+	}
+}
+
+// Misbehave adds score to the IP address's score, and bans the IP address if the score reaches the threshold.
+// Returns true if the IP address was banned.
+func (b *Bans) Misbehave(ip string, score int, reason string) bool {
+	b.Lock()
+	defer b.Unlock()
+
+	b.removeExpired()
+	if _, ok := b.bans[ip]; ok {
+		return false
+	}
+
+	b.scores[ip] += score
+	if b.scores[ip] < b.threshold {
+		return false
+	}
+
+	delete(b.scores, ip)
+	b.add(ip, reason, b.duration)
+	return true
+}
+
+// Score returns the misbehavior score of an IP address
+func (b *Bans) Score(ip string) int {
+	b.Lock()
+	defer b.Unlock()
+	return b.scores[ip]
+}
+
+// add bans an IP address. Must be called with the lock held.
+func (b *Bans) add(ip, reason string, duration time.Duration) Ban {
+	now := b.now()
+	ban := Ban{
+		IP:      ip,
+		Reason:  reason,
+		Created: now,
+		Expires: now.Add(duration),
+	}
+	b.bans[ip] = ban
+	return ban
+}
+
+// Add bans an IP address for a duration, replacing any existing ban of the IP address.
+// If duration is 0, the configured ban duration is used.
+func (b *Bans) Add(ip, reason string, duration time.Duration) Ban {
+	b.Lock()
+	defer b.Unlock()
+
+	if duration == 0 {
+		duration = b.duration
+	}
+
+	delete(b.scores, ip)
+	return b.add(ip, reason, duration)
+}
+
+// Remove removes the ban of an IP address and resets its score
+func (b *Bans) Remove(ip string) error {
+	b.Lock()
+	defer b.Unlock()
+
+	b.removeExpired()
+	if _, ok := b.bans[ip]; !ok {
+		return ErrBanNotFound
+	}
+
+	delete(b.bans, ip)
+	delete(b.scores, ip)
+	return nil
+}
+
+// IsBanned returns true if an IP address is banned
+func (b *Bans) IsBanned(ip string) bool {
+	b.Lock()
+	defer b.Unlock()
+
+	b.removeExpired()
+	_, ok := b.bans[ip]
+	return ok
+}
+
+// All returns the bans, ordered by IP address
+func (b *Bans) All() []Ban {
+	b.Lock()
+	defer b.Unlock()
+
+	b.removeExpired()
+	bans := make([]Ban, 0, len(b.bans))
+	for _, ban := range b.bans {
+		bans = append(bans, ban)
+	}
+
+	sort.Slice(bans, func(i, j int) bool {
+		return bans[i].IP < bans[j].IP
+	})
+
+	return bans
+}
@@ -0,0 +1,103 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBansMisbehave(t *testing.T) {
+	now := time.Unix(1600000000, 0).UTC()
+	b := NewBans(misbehaviorInvalidBlock, time.Hour)
+	b.now = func() time.Time {
+		return now
+	}
+
+	// Scores accumulate until the threshold is reached
+	banned := b.Misbehave("1.2.3.4", misbehaviorMalformedMessage, "malformed message")
+	require.False(t, banned)
+	require.Equal(t, misbehaviorMalformedMessage, b.Score("1.2.3.4"))
+	require.False(t, b.IsBanned("1.2.3.4"))
+
+	banned = b.Misbehave("1.2.3.4", misbehaviorStalling, "stalling")
+	require.False(t, banned)
+	require.Equal(t, misbehaviorMalformedMessage+misbehaviorStalling, b.Score("1.2.3.4"))
+
+	banned = b.Misbehave("1.2.3.4", misbehaviorMalformedMessage, "malformed message")
+	require.True(t, banned)
+	require.Equal(t, 0, b.Score("1.2.3.4"))
+	require.True(t, b.IsBanned("1.2.3.4"))
+
+	// Other IP addresses are scored independently
+	require.False(t, b.IsBanned("5.6.7.8"))
+	banned = b.Misbehave("5.6.7.8", misbehaviorInvalidBlock, "invalid block")
+	require.True(t, banned)
+
+	// A banned IP address is not scored again
+	banned = b.Misbehave("1.2.3.4", misbehaviorInvalidBlock, "invalid block")
+	require.False(t, banned)
+
+	require.Equal(t, []Ban{
+		{
+			IP:      "1.2.3.4",
+			Reason:  "malformed message",
+			Created: now,
+			Expires: now.Add(time.Hour),
+		},
+		{
+			IP:      "5.6.7.8",
+			Reason:  "invalid block",
+			Created: now,
+			Expires: now.Add(time.Hour),
+		},
+	}, b.All())
+
+	// Bans expire after the ban duration
+	now = now.Add(time.Hour)
+	require.False(t, b.IsBanned("1.2.3.4"))
+	require.False(t, b.IsBanned("5.6.7.8"))
+	require.Empty(t, b.All())
+}
+
+func TestBansAddRemove(t *testing.T) {
+	now := time.Unix(1600000000, 0).UTC()
+	b := NewBans(misbehaviorInvalidBlock, time.Hour)
+	b.now = func() time.Time {
+		return now
+	}
+
+	err := b.Remove("1.2.3.4")
+	require.Equal(t, ErrBanNotFound, err)
+
+	b.Misbehave("1.2.3.4", misbehaviorStalling, "stalling")
+
+	// The configured duration is used when the duration is 0
+	ban := b.Add("1.2.3.4", "operator", 0)
+	require.Equal(t, Ban{
+		IP:      "1.2.3.4",
+		Reason:  "operator",
+		Created: now,
+		Expires: now.Add(time.Hour),
+	}, ban)
+	require.True(t, b.IsBanned("1.2.3.4"))
+	require.Equal(t, 0, b.Score("1.2.3.4"))
+
+	// Adding a ban replaces the existing ban
+	ban = b.Add("1.2.3.4", "operator", time.Minute)
+	require.Equal(t, now.Add(time.Minute), ban.Expires)
+	require.Equal(t, []Ban{ban}, b.All())
+
+	err = b.Remove("1.2.3.4")
+	require.NoError(t, err)
+	require.False(t, b.IsBanned("1.2.3.4"))
+
+	err = b.Remove("1.2.3.4")
+	require.Equal(t, ErrBanNotFound, err)
+
+	// Expired bans can't be removed
+	b.Add("1.2.3.4", "operator", time.Minute)
+	now = now.Add(time.Minute)
+	err = b.Remove("1.2.3.4")
+	require.Equal(t, ErrBanNotFound, err)
+}
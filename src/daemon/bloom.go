@@ -0,0 +1,186 @@
+package daemon
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"sync"
+
+	"github.com/SkycoinProject/cx-chains/src/coin"
+)
+
+const (
+	// MaxBloomFilterSize is the maximum size of a bloom filter in bytes
+	MaxBloomFilterSize = 36000
+	// MaxBloomFilterHashFuncs is the maximum number of hash functions of a bloom filter
+	MaxBloomFilterHashFuncs = 50
+)
+
+var (
+	// ErrBloomFilterEmpty is returned when a bloom filter has no bits or no hash functions
+	ErrBloomFilterEmpty = errors.New("Bloom filter is empty")
+	// ErrBloomFilterTooLarge is returned when a bloom filter exceeds MaxBloomFilterSize
+	ErrBloomFilterTooLarge = errors.New("Bloom filter is too large")
+	// ErrBloomFilterTooManyHashFuncs is returned when a bloom filter exceeds MaxBloomFilterHashFuncs
+	ErrBloomFilterTooManyHashFuncs = errors.New("Bloom filter has too many hash functions")
+	// ErrBloomFilterNotLoaded is returned when sending a filtered block to a peer which has not loaded a bloom filter
+	ErrBloomFilterNotLoaded = errors.New("Bloom filter is not loaded")
+)
+
+// BloomFilter is a probabilistic set of the addresses, transaction hashes and output hashes
+// that a light client is interested in. The light client loads the filter on a peer with a
+// FilterLoadMessage, and the peer sends the client only the transactions that match the filter.
+type BloomFilter struct {
+	Bits      []byte
+	HashFuncs uint32
+	// Tweak randomizes the hash functions, so that filters of different clients set different bits
+	Tweak uint32
+}
+
+// NewBloomFilter creates a BloomFilter, checking that it is within the size limits
+func NewBloomFilter(bits []byte, hashFuncs, tweak uint32) (*BloomFilter, error) {
+	if len(bits) == 0 || hashFuncs == 0 {
+		return nil, ErrBloomFilterEmpty
+	}
+	if len(bits) > MaxBloomFilterSize {
+		return nil, ErrBloomFilterTooLarge
+	}
+	if hashFuncs > MaxBloomFilterHashFuncs {
+		return nil, ErrBloomFilterTooManyHashFuncs
+	}
+
+	b := make([]byte, len(bits))
+	copy(b, bits)
+
+	return &BloomFilter{
+		Bits:      b,
+		HashFuncs: hashFuncs,
+		Tweak:     tweak,
+	}, nil
+}
+
+// bitIndex returns the index of the bit set for data by the hash function n
+func (f *BloomFilter) bitIndex(n uint32, data []byte) uint64 {
+	var seed [4]byte
+	binary.LittleEndian.PutUint32(seed[:], n*0xFBA4C795+f.Tweak)
+
+	h := fnv.New64a()
+	_, _ = h.Write(seed[:])
+	_, _ = h.Write(data)
+	return h.Sum64() % (uint64(len(f.Bits)) * 8)
+}
+
+// Add adds data to the filter
+func (f *BloomFilter) Add(data []byte) {
+	for n := uint32(0); n < f.HashFuncs; n++ {
+		i := f.bitIndex(n, data)
+		f.Bits[i/8] |= 1 << (i % 8)
+	}
+}
+
+// Contains returns true if data may have been added to the filter.
+// False positives are possible, false negatives are not.
+func (f *BloomFilter) Contains(data []byte) bool {
+	for n := uint32(0); n < f.HashFuncs; n++ {
+		i := f.bitIndex(n, data)
+		if f.Bits[i/8]&(1<<(i%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchTransaction returns true if the transaction hash, an output address or a spent output hash
+// is in the filter. The hashes of the outputs created by a matching transaction are added to the filter,
+// so that the transactions spending them match too.
+func (f *BloomFilter) MatchTransaction(head coin.BlockHeader, txn coin.Transaction) bool {
+	matched := false
+
+	txid := txn.Hash()
+	if f.Contains(txid[:]) {
+		matched = true
+	}
+
+	for _, o := range txn.Out {
+		if f.Contains(o.Address.Bytes()) {
+			matched = true
+			break
+		}
+	}
+
+	if !matched {
+		for _, h := range txn.In {
+			if f.Contains(h[:]) {
+				matched = true
+				break
+			}
+		}
+	}
+
+	if !matched {
+		return false
+	}
+
+	for _, ux := range coin.CreateUnspents(head, txn) {
+		h := ux.Hash()
+		f.Add(h[:])
+	}
+
+	return true
+}
+
+// bloomFilters holds the bloom filters loaded by light client peers, by address
+type bloomFilters struct {
+	sync.Mutex
+	filters map[string]*BloomFilter
+}
+
+// newBloomFilters creates bloomFilters
+func newBloomFilters() *bloomFilters {
+	return &bloomFilters{
+		filters: make(map[string]*BloomFilter),
+	}
+}
+
+// set sets the filter of a peer, replacing its previous filter
+func (b *bloomFilters) set(addr string, f *BloomFilter) {
+	b.Lock()
+	defer b.Unlock()
+	b.filters[addr] = f
+}
+
+// remove removes the filter of a peer
+func (b *bloomFilters) remove(addr string) {
+	b.Lock()
+	defer b.Unlock()
+	delete(b.filters, addr)
+}
+
+// has returns true if a peer has loaded a filter
+func (b *bloomFilters) has(addr string) bool {
+	b.Lock()
+	defer b.Unlock()
+	_, ok := b.filters[addr]
+	return ok
+}
+
+// matchBlock returns the indexes of the transactions of a block that match the filter of a peer.
+// Returns false if the peer has not loaded a filter.
+func (b *bloomFilters) matchBlock(addr string, sb coin.SignedBlock) ([]int, bool) {
+	b.Lock()
+	defer b.Unlock()
+
+	f, ok := b.filters[addr]
+	if !ok {
+		return nil, false
+	}
+
+	var matches []int
+	for i, txn := range sb.Body.Transactions {
+		if f.MatchTransaction(sb.Head, txn) {
+			matches = append(matches, i)
+		}
+	}
+
+	return matches, true
+}
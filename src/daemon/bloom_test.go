@@ -0,0 +1,160 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+)
+
+func TestNewBloomFilter(t *testing.T) {
+	_, err := NewBloomFilter(nil, 1, 0)
+	require.Equal(t, ErrBloomFilterEmpty, err)
+
+	_, err = NewBloomFilter([]byte{0}, 0, 0)
+	require.Equal(t, ErrBloomFilterEmpty, err)
+
+	_, err = NewBloomFilter(make([]byte, MaxBloomFilterSize+1), 1, 0)
+	require.Equal(t, ErrBloomFilterTooLarge, err)
+
+	_, err = NewBloomFilter([]byte{0}, MaxBloomFilterHashFuncs+1, 0)
+	require.Equal(t, ErrBloomFilterTooManyHashFuncs, err)
+
+	// The bits are copied
+	bits := make([]byte, 8)
+	f, err := NewBloomFilter(bits, 3, 0)
+	require.NoError(t, err)
+	f.Add([]byte("foo"))
+	require.Equal(t, make([]byte, 8), bits)
+}
+
+func TestBloomFilterContains(t *testing.T) {
+	f, err := NewBloomFilter(make([]byte, 512), 10, 12345)
+	require.NoError(t, err)
+
+	added := make([]cipher.SHA256, 50)
+	for i := range added {
+		added[i] = testutil.RandSHA256(t)
+		f.Add(added[i][:])
+	}
+
+	for _, h := range added {
+		require.True(t, f.Contains(h[:]))
+	}
+
+	// With 50 entries in 4096 bits and 10 hash functions, the false positive rate is about 1e-7
+	for i := 0; i < 100; i++ {
+		h := testutil.RandSHA256(t)
+		require.False(t, f.Contains(h[:]))
+	}
+
+	// The tweak changes the bits set
+	g, err := NewBloomFilter(make([]byte, 512), 10, 54321)
+	require.NoError(t, err)
+	g.Add(added[0][:])
+	require.NotEqual(t, f.Bits, g.Bits)
+}
+
+func TestBloomFilterMatchTransaction(t *testing.T) {
+	addr := testutil.MakeAddress()
+	head := coin.BlockHeader{
+		BkSeq: 10,
+		Time:  1600000000,
+	}
+
+	txn := coin.Transaction{
+		In: []cipher.SHA256{testutil.RandSHA256(t)},
+		Out: []coin.TransactionOutput{
+			{
+				Address: testutil.MakeAddress(),
+				Coins:   1e6,
+			},
+			{
+				Address: addr,
+				Coins:   2e6,
+			},
+		},
+	}
+	txn.InnerHash = txn.HashInner()
+
+	newFilter := func() *BloomFilter {
+		f, err := NewBloomFilter(make([]byte, 256), 5, 0)
+		require.NoError(t, err)
+		return f
+	}
+
+	// No match
+	f := newFilter()
+	require.False(t, f.MatchTransaction(head, txn))
+
+	// Output address match
+	f = newFilter()
+	f.Add(addr.Bytes())
+	require.True(t, f.MatchTransaction(head, txn))
+
+	// The created outputs are added to the filter, so that the transaction spending them matches
+	uxs := coin.CreateUnspents(head, txn)
+	spend := coin.Transaction{
+		In: []cipher.SHA256{uxs[1].Hash()},
+		Out: []coin.TransactionOutput{
+			{
+				Address: testutil.MakeAddress(),
+				Coins:   2e6,
+			},
+		},
+	}
+	require.True(t, f.MatchTransaction(head, spend))
+
+	// Transaction hash match
+	f = newFilter()
+	txid := txn.Hash()
+	f.Add(txid[:])
+	require.True(t, f.MatchTransaction(head, txn))
+
+	// Spent output match
+	f = newFilter()
+	f.Add(txn.In[0][:])
+	require.True(t, f.MatchTransaction(head, txn))
+}
+
+func TestBloomFiltersMatchBlock(t *testing.T) {
+	addr := testutil.MakeAddress()
+	b := coin.SignedBlock{
+		Block: coin.Block{
+			Body: coin.BlockBody{
+				Transactions: coin.Transactions{
+					{
+						Out: []coin.TransactionOutput{{Address: testutil.MakeAddress()}},
+					},
+					{
+						Out: []coin.TransactionOutput{{Address: addr}},
+					},
+					{
+						Out: []coin.TransactionOutput{{Address: testutil.MakeAddress()}},
+					},
+				},
+			},
+		},
+	}
+
+	filters := newBloomFilters()
+	_, ok := filters.matchBlock("1.1.1.1:6000", b)
+	require.False(t, ok)
+
+	f, err := NewBloomFilter(make([]byte, 256), 5, 0)
+	require.NoError(t, err)
+	f.Add(addr.Bytes())
+
+	filters.set("1.1.1.1:6000", f)
+	require.True(t, filters.has("1.1.1.1:6000"))
+
+	indexes, ok := filters.matchBlock("1.1.1.1:6000", b)
+	require.True(t, ok)
+	require.Equal(t, []int{1}, indexes)
+
+	filters.remove("1.1.1.1:6000")
+	require.False(t, filters.has("1.1.1.1:6000"))
+}
@@ -2,7 +2,8 @@ package daemon
 
 import (
 	"errors"
-	"fmt"
+	"net"
+	"strconv"
 	"sync"
 	"time"
 
@@ -95,7 +96,7 @@ func (c *connection) ListenAddr() string {
 		return ""
 	}
 
-	return fmt.Sprintf("%s:%d", ip, c.ListenPort)
+	return net.JoinHostPort(ip, strconv.Itoa(int(c.ListenPort)))
 }
 
 // Connections manages a collection of Connection
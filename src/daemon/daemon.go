@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"net"
 	"reflect"
 	"sort"
 	"strings"
@@ -26,6 +27,7 @@ import (
 	"github.com/SkycoinProject/cx-chains/src/util/logging"
 	"github.com/SkycoinProject/cx-chains/src/util/useragent"
 	"github.com/SkycoinProject/cx-chains/src/visor"
+	"github.com/SkycoinProject/cx-chains/src/visor/blockdb"
 	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
 )
 
@@ -93,12 +95,30 @@ func (cfg *Config) preprocess() (Config, error) {
 	}
 	config.Pool.port = config.Daemon.Port
 	config.Pool.address = config.Daemon.Address
+	config.Pool.proxy = config.Daemon.Proxy
+	config.Pex.Proxy = config.Daemon.Proxy
+
+	switch {
+	case config.Daemon.DisableIncomingIPv4 && config.Daemon.DisableIncomingIPv6:
+		config.Daemon.DisableIncomingConnections = true
+	case config.Daemon.DisableIncomingIPv4:
+		config.Pool.listenNetwork = "tcp6"
+	case config.Daemon.DisableIncomingIPv6:
+		config.Pool.listenNetwork = "tcp4"
+	default:
+		config.Pool.listenNetwork = "tcp"
+	}
 
 	if config.Daemon.DisableNetworking {
 		logger.Info("Networking is disabled")
 		config.Pex.Disabled = true
 		config.Daemon.DisableIncomingConnections = true
 		config.Daemon.DisableOutgoingConnections = true
+	} else if config.Daemon.Devnet {
+		logger.Info("Running a devnet, peer connections are disabled")
+		config.Pex.Disabled = true
+		config.Daemon.DisableIncomingConnections = true
+		config.Daemon.DisableOutgoingConnections = true
 	} else {
 		if config.Daemon.DisableIncomingConnections {
 			logger.Info("Incoming connections are disabled.")
@@ -106,18 +126,47 @@ func (cfg *Config) preprocess() (Config, error) {
 		if config.Daemon.DisableOutgoingConnections {
 			logger.Info("Outgoing connections are disabled.")
 		}
+		if !config.Daemon.DisableIncomingConnections && config.Pool.listenNetwork != "tcp" {
+			logger.Infof("Incoming connections are only allowed on %s", config.Pool.listenNetwork)
+		}
+		if config.Daemon.Proxy != "" && !config.Daemon.DisableOutgoingConnections {
+			logger.WithField("proxy", config.Daemon.Proxy).Info("Outgoing connections are made through a SOCKS5 proxy")
+		}
 	}
 
 	if config.Daemon.MaxConnections < config.Daemon.MaxOutgoingConnections {
 		return Config{}, errors.New("MaxOutgoingConnections cannot be more than MaxConnections")
 	}
 
+	if config.Daemon.MaxIncomingConnections < 0 {
+		return Config{}, errors.New("MaxIncomingConnections must be >= 0")
+	}
+
+	if config.Daemon.ProtectedConnections < 0 {
+		return Config{}, errors.New("ProtectedConnections must be >= 0")
+	}
+
+	if config.Daemon.ExecuteBlocksBatchSize < 1 {
+		return Config{}, errors.New("ExecuteBlocksBatchSize must be >= 1")
+	}
+
+	if config.Daemon.GetBlocksRequestCount < 1 {
+		return Config{}, errors.New("GetBlocksRequestCount must be >= 1")
+	}
+
+	if config.Daemon.MaxBlockRequestsPerPeer < 1 {
+		return Config{}, errors.New("MaxBlockRequestsPerPeer must be >= 1")
+	}
+
 	if config.Daemon.MaxPendingConnections > config.Daemon.MaxOutgoingConnections {
 		config.Daemon.MaxPendingConnections = config.Daemon.MaxOutgoingConnections
 	}
 
 	config.Pool.MaxConnections = config.Daemon.MaxConnections
 	config.Pool.MaxOutgoingConnections = config.Daemon.MaxOutgoingConnections
+	// gnet accepts one more incoming connection than the daemon allows, so that the daemon
+	// can choose which incoming connection to evict when the incoming connections are full
+	config.Pool.MaxIncomingConnections = config.Daemon.MaxIncomingConnections + config.Daemon.ProtectedConnections + 1
 	config.Pool.MaxIncomingMessageLength = int(config.Daemon.MaxIncomingMessageLength)
 	config.Pool.MaxOutgoingMessageLength = int(config.Daemon.MaxOutgoingMessageLength)
 
@@ -177,6 +226,11 @@ type DaemonConfig struct { // nolint: golint
 	MaxConnections int
 	// Number of outgoing connections to maintain
 	MaxOutgoingConnections int
+	// Maximum number of incoming connections, excluding the connections in protected slots.
+	// When a new incoming connection exceeds it, the incoming connection with the highest misbehavior score is evicted
+	MaxIncomingConnections int
+	// Number of incoming connection slots reserved for trusted peers. Connections in these slots are never evicted
+	ProtectedConnections int
 	// Maximum number of connections to try at once
 	MaxPendingConnections int
 	// How long to wait for a version packet
@@ -189,10 +243,18 @@ type DaemonConfig struct { // nolint: golint
 	IPCountsMax int
 	// Disable all networking activity
 	DisableNetworking bool
+	// Run a single-node development chain. Peer connections are disabled, and transactions and blocks are not broadcast
+	Devnet bool
 	// Don't make outgoing connections
 	DisableOutgoingConnections bool
 	// Don't allow incoming connections
 	DisableIncomingConnections bool
+	// Don't allow incoming connections over IPv4
+	DisableIncomingIPv4 bool
+	// Don't allow incoming connections over IPv6
+	DisableIncomingIPv6 bool
+	// Address of a SOCKS5 proxy that outgoing connections are made through, as host:port, e.g. a Tor client
+	Proxy string
 	// Run on localhost and only connect to localhost peers
 	LocalhostOnly bool
 	// Log ping and pong messages
@@ -205,6 +267,20 @@ type DaemonConfig struct { // nolint: golint
 	GetBlocksRequestCount uint64
 	// Maximum number of blocks to respond with to a GetBlocksMessage
 	MaxGetBlocksResponseCount uint64
+	// How many headers to request in a GetHeadersMessage
+	HeadersRequestCount uint64
+	// Maximum number of headers to respond with to a GetHeadersMessage
+	MaxGetHeadersResponseCount uint64
+	// Maximum number of outstanding block requests to a peer during headers-first synchronization
+	MaxBlockRequestsPerPeer int
+	// How long a peer has to respond to a block request before the blocks are requested from another peer
+	BlockRequestTimeout time.Duration
+	// Maximum number of blocks ahead of the head block to download and buffer
+	MaxBufferedBlocks uint64
+	// Maximum number of headers ahead of the head block to track
+	MaxHeadersAhead uint64
+	// Maximum number of received blocks to execute in a single database transaction
+	ExecuteBlocksBatchSize int
 	// Max announce txns hash number
 	MaxTxnAnnounceNum int
 	// How often new blocks are created by the signing node, in seconds
@@ -228,12 +304,18 @@ type DaemonConfig struct { // nolint: golint
 	MaxOutgoingMessageLength uint64
 	// Maximum total size of transactions in a block
 	MaxBlockTransactionsSize uint32
+	// Misbehavior score at which a peer's IP address is banned
+	BanScoreThreshold int
+	// How long a peer's IP address is banned for when its misbehavior score reaches BanScoreThreshold
+	BanDuration time.Duration
+	// Serve bloom filtered blocks and transaction proofs to light clients
+	EnableSPVServer bool
 }
 
 // NewDaemonConfig creates daemon config
 func NewDaemonConfig() DaemonConfig {
 	return DaemonConfig{
-		ProtocolVersion:              2,
+		ProtocolVersion:              4,
 		MinProtocolVersion:           2,
 		Address:                      "",
 		Port:                         6677,
@@ -242,6 +324,8 @@ func NewDaemonConfig() DaemonConfig {
 		PrivateRate:                  time.Second * 5,
 		MaxConnections:               128,
 		MaxOutgoingConnections:       8,
+		MaxIncomingConnections:       110,
+		ProtectedConnections:         8,
 		MaxPendingConnections:        8,
 		IntroductionWait:             time.Second * 30,
 		CullInvalidRate:              time.Second * 3,
@@ -256,6 +340,13 @@ func NewDaemonConfig() DaemonConfig {
 		BlocksAnnounceRate:           time.Second * 60,
 		GetBlocksRequestCount:        20,
 		MaxGetBlocksResponseCount:    20,
+		HeadersRequestCount:          1000,
+		MaxGetHeadersResponseCount:   1000,
+		MaxBlockRequestsPerPeer:      2,
+		BlockRequestTimeout:          time.Second * 30,
+		MaxBufferedBlocks:            1024,
+		MaxHeadersAhead:              50000,
+		ExecuteBlocksBatchSize:       20,
 		MaxTxnAnnounceNum:            16,
 		BlockCreationInterval:        10,
 		UnconfirmedRefreshRate:       time.Minute,
@@ -265,6 +356,9 @@ func NewDaemonConfig() DaemonConfig {
 		MaxOutgoingMessageLength:     256 * 1024,
 		MaxIncomingMessageLength:     1024 * 1024,
 		MaxBlockTransactionsSize:     5 * 1024 * 1024,
+		BanScoreThreshold:            100,
+		BanDuration:                  time.Hour * 24,
+		EnableSPVServer:              false,
 	}
 }
 
@@ -282,15 +376,26 @@ type daemoner interface {
 	getSignedBlocksSince(seq, count uint64) ([]coin.SignedBlock, error)
 	headBkSeq() (uint64, bool, error)
 	executeSignedBlock(b coin.SignedBlock) error
+	executeSignedBlocks(blocks []coin.SignedBlock) error
 	filterKnownUnconfirmed(txns []cipher.SHA256) ([]cipher.SHA256, error)
 	getKnownUnconfirmed(txns []cipher.SHA256) (coin.Transactions, error)
+	getAllUnconfirmed() (coin.Transactions, error)
 	requestBlocksFromAddr(addr string) error
+	requestBlocksAfter(headSeq uint64) error
+	requestBlockBodies() error
+	addHeaders(headers []SignedBlockHeader) (int, error)
+	bufferBlocks(addr string, headSeq uint64, blocks []coin.SignedBlock) []coin.SignedBlock
 	announceAllValidTxns() error
 	pexConfig() pex.Config
 	injectTransaction(txn coin.Transaction) (bool, *visor.ErrTxnViolatesSoftConstraint, error)
 	recordMessageEvent(m asyncMessage, c *gnet.MessageContext) error
 	connectionIntroduced(addr string, gnetID uint64, m *IntroductionMessage) (*connection, error)
 	sendRandomPeers(addr string) error
+	misbehave(addr string, score int, reason string)
+	setBloomFilter(addr string, f *BloomFilter)
+	clearBloomFilter(addr string)
+	sendMerkleBlock(addr string, b coin.SignedBlock) error
+	getTxnProofs(txids []cipher.SHA256) ([]blockdb.TxnProof, error)
 }
 
 // Daemon stateful properties of the daemon
@@ -308,6 +413,12 @@ type Daemon struct {
 	announcedTxns *announcedTxnsCache
 	// Cache of connection metadata
 	connections *Connections
+	// Peer misbehavior scores and banned IP addresses
+	bans *Bans
+	// Best header chain and block requests of headers-first synchronization
+	headers *headerSync
+	// Bloom filters loaded by light client peers
+	filters *bloomFilters
 	// connect, disconnect, message, error events channel
 	events chan interface{}
 	// quit channel
@@ -328,6 +439,15 @@ func New(config Config, v *visor.Visor) (*Daemon, error) {
 		return nil, err
 	}
 
+	headers := newHeaderSync(headerSyncConfig{
+		blockchainPubkey:   config.Daemon.BlockchainPubkey,
+		requestCount:       config.Daemon.GetBlocksRequestCount,
+		maxRequestsPerPeer: config.Daemon.MaxBlockRequestsPerPeer,
+		requestTimeout:     config.Daemon.BlockRequestTimeout,
+		maxBufferedBlocks:  config.Daemon.MaxBufferedBlocks,
+		maxHeadersAhead:    config.Daemon.MaxHeadersAhead,
+	})
+
 	d := &Daemon{
 		config:   config.Daemon,
 		Messages: NewMessages(config.Messages),
@@ -336,6 +456,9 @@ func New(config Config, v *visor.Visor) (*Daemon, error) {
 
 		announcedTxns: newAnnouncedTxnsCache(),
 		connections:   NewConnections(),
+		bans:          NewBans(config.Daemon.BanScoreThreshold, config.Daemon.BanDuration),
+		headers:       headers,
+		filters:       newBloomFilters(),
 		events:        make(chan interface{}, config.Pool.EventChannelSize),
 		quit:          make(chan struct{}),
 		done:          make(chan struct{}),
@@ -444,6 +567,11 @@ func (dm *Daemon) Run() error {
 	defer unconfirmedRefreshTicker.Stop()
 	unconfirmedRemoveInvalidTicker := time.NewTicker(dm.config.UnconfirmedRemoveInvalidRate)
 	defer unconfirmedRemoveInvalidTicker.Stop()
+	if dm.visor.IsReadOnly() {
+		// The unconfirmed pool cannot be modified in a read-only db
+		unconfirmedRefreshTicker.Stop()
+		unconfirmedRemoveInvalidTicker.Stop()
+	}
 	blocksRequestTicker := time.NewTicker(dm.config.BlocksRequestRate)
 	defer blocksRequestTicker.Stop()
 	blocksAnnounceTicker := time.NewTicker(dm.config.BlocksAnnounceRate)
@@ -710,6 +838,10 @@ func (dm *Daemon) connectToPeer(p pex.Peer) error {
 		return errors.New("Not localhost")
 	}
 
+	if dm.bans.IsBanned(a) {
+		return errors.New("Peer is banned")
+	}
+
 	if c := dm.connections.get(p.Addr); c != nil {
 		return errors.New("Already connected to this peer")
 	}
@@ -951,6 +1083,14 @@ func (dm *Daemon) onConnectEvent(e ConnectEvent) {
 		logger.Critical().WithFields(fields).Warning("Connection.Outgoing does not match ConnectEvent.Solicited state")
 	}
 
+	if dm.isBanned(e.Addr) {
+		logger.WithFields(fields).Info("Peer is banned, disconnecting")
+		if err := dm.Disconnect(e.Addr, ErrDisconnectIsBlacklisted); err != nil {
+			logger.WithError(err).WithFields(fields).Error("Disconnect")
+		}
+		return
+	}
+
 	if dm.ipCountMaxed(e.Addr) {
 		logger.WithFields(fields).Info("Max connections for this IP address reached, disconnecting")
 		if err := dm.Disconnect(e.Addr, ErrDisconnectIPLimitReached); err != nil {
@@ -959,6 +1099,18 @@ func (dm *Daemon) onConnectEvent(e ConnectEvent) {
 		return
 	}
 
+	if !e.Solicited {
+		if addr := dm.selectEvictedConnection(); addr != "" {
+			logger.WithFields(fields).WithField("evictedAddr", addr).Info("Max incoming connections reached, evicting a connection")
+			if err := dm.Disconnect(addr, ErrDisconnectEvicted); err != nil {
+				logger.WithError(err).WithFields(fields).WithField("evictedAddr", addr).Error("Disconnect")
+			}
+			if addr == e.Addr {
+				return
+			}
+		}
+	}
+
 	logger.WithFields(fields).Debug("Sending introduction message")
 
 	if err := dm.sendMessage(e.Addr, NewIntroductionMessage(
@@ -988,7 +1140,20 @@ func (dm *Daemon) onDisconnectEvent(e DisconnectEvent) {
 		return
 	}
 
-	// TODO -- blacklist peer for certain reasons, not just remove
+	dm.filters.remove(e.Addr)
+
+	// Score the misbehavior of the peer, which bans its IP address if its score is too high
+	switch e.Reason {
+	case gnet.ErrDisconnectMalformedMessage,
+		gnet.ErrDisconnectUnknownMessage,
+		gnet.ErrDisconnectInvalidMessageLength,
+		gnet.ErrDisconnectMessageDecodeUnderflow:
+		dm.misbehave(e.Addr, misbehaviorMalformedMessage, e.Reason.Error())
+	case ErrDisconnectIdle,
+		ErrDisconnectIntroductionTimeout:
+		dm.misbehave(e.Addr, misbehaviorStalling, e.Reason.Error())
+	}
+
 	switch e.Reason {
 	case ErrDisconnectIntroductionTimeout,
 		ErrDisconnectBlockchainPubkeyNotMatched,
@@ -1096,28 +1261,56 @@ func (dm *Daemon) handleMessageSendResult(r gnet.SendResult) {
 	}
 }
 
-// requestBlocks sends a GetBlocksMessage to all connections
+// requestBlocks sends a GetHeadersMessage to the connections which support headers-first synchronization,
+// and requests blocks from all connections
 func (dm *Daemon) requestBlocks() error {
 	if dm.config.DisableNetworking {
 		return ErrNetworkingDisabled
 	}
 
-	headSeq, ok, err := dm.visor.HeadBkSeq()
+	headSeq, err := dm.syncHead()
 	if err != nil {
 		return err
 	}
-	if !ok {
-		return errors.New("Cannot request blocks, there is no head block")
+
+	var addrs []string
+	for _, c := range dm.connections.all() {
+		if c.HasIntroduced() && c.ProtocolVersion >= headersFirstProtocolVersion {
+			addrs = append(addrs, c.Addr)
+		}
 	}
 
-	m := NewGetBlocksMessage(headSeq, dm.config.GetBlocksRequestCount)
+	if len(addrs) > 0 {
+		m := NewGetHeadersMessage(headSeq, dm.headers.best(), dm.config.HeadersRequestCount)
+		if _, err := dm.pool.Pool.BroadcastMessage(m, addrs); err != nil {
+			logger.WithError(err).Debug("Broadcast GetHeadersMessage failed")
+		}
+	}
 
-	if _, err := dm.broadcastMessage(m); err != nil {
-		logger.WithError(err).Debug("Broadcast GetBlocksMessage failed")
-		return err
+	return dm.requestBlocksAfter(headSeq)
+}
+
+// syncHead updates the head block of the header sync, and returns the head block seq
+func (dm *Daemon) syncHead() (uint64, error) {
+	headSeq, ok, err := dm.visor.HeadBkSeq()
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, errors.New("Cannot request blocks, there is no head block")
 	}
 
-	return nil
+	b, err := dm.visor.GetSignedBlockBySeq(headSeq)
+	if err != nil {
+		return 0, err
+	}
+	if b == nil {
+		return 0, fmt.Errorf("Head block %d not found", headSeq)
+	}
+
+	dm.headers.setHead(headSeq, b.HashHeader())
+
+	return headSeq, nil
 }
 
 // announceBlocks sends an AnnounceBlocksMessage to all connections
@@ -1161,6 +1354,11 @@ func (dm *Daemon) createAndPublishBlock() (*coin.SignedBlock, error) {
 		return nil, err
 	}
 
+	// A devnet has no peers to publish the block to
+	if dm.config.Devnet {
+		return &sb, nil
+	}
+
 	err = dm.broadcastBlock(sb)
 
 	return &sb, err
@@ -1214,7 +1412,12 @@ func (dm *Daemon) BroadcastTransaction(txn coin.Transaction) ([]uint64, error) {
 
 // BroadcastUserTransaction broadcasts a single transaction to all peers.
 // Returns an error if no peers that would propagate the transaction could be reached.
+// A devnet has no peers, so the transaction is not broadcast and no error is returned.
 func (dm *Daemon) BroadcastUserTransaction(txn coin.Transaction, head *coin.SignedBlock, inputs coin.UxArray) error {
+	if dm.config.Devnet {
+		return nil
+	}
+
 	ids, err := dm.BroadcastTransaction(txn)
 	if err != nil {
 		return err
@@ -1330,37 +1533,233 @@ func (dm *Daemon) Disconnect(addr string, r gnet.DisconnectReason) error {
 
 // Implements private daemoner interface methods:
 
-// requestBlocksFromAddr sends a GetBlocksMessage to one connected address
+// requestBlocksFromAddr sends a GetHeadersMessage to one connected address if it supports headers-first synchronization,
+// otherwise a GetBlocksMessage
 func (dm *Daemon) requestBlocksFromAddr(addr string) error {
 	if dm.config.DisableNetworking {
 		return ErrNetworkingDisabled
 	}
 
-	headSeq, ok, err := dm.visor.HeadBkSeq()
+	headSeq, err := dm.syncHead()
 	if err != nil {
 		return err
 	}
-	if !ok {
-		return errors.New("Cannot request blocks from addr, there is no head block")
+
+	if c := dm.connections.get(addr); c != nil && c.ProtocolVersion >= headersFirstProtocolVersion {
+		m := NewGetHeadersMessage(headSeq, dm.headers.best(), dm.config.HeadersRequestCount)
+		return dm.sendMessage(addr, m)
 	}
 
 	m := NewGetBlocksMessage(headSeq, dm.config.GetBlocksRequestCount)
 	return dm.sendMessage(addr, m)
 }
 
-// broadcastBlock sends a signed block to all connections
+// requestBlocksAfter sends a GetBlocksMessage to the connections which do not support headers-first synchronization,
+// and requests the blocks of the best header chain from the other connections
+func (dm *Daemon) requestBlocksAfter(headSeq uint64) error {
+	if dm.config.DisableNetworking {
+		return ErrNetworkingDisabled
+	}
+
+	var addrs []string
+	for _, c := range dm.connections.all() {
+		if c.HasIntroduced() && c.ProtocolVersion < headersFirstProtocolVersion {
+			addrs = append(addrs, c.Addr)
+		}
+	}
+
+	if len(addrs) > 0 {
+		m := NewGetBlocksMessage(headSeq, dm.config.GetBlocksRequestCount)
+		if _, err := dm.pool.Pool.BroadcastMessage(m, addrs); err != nil {
+			logger.WithError(err).Debug("Broadcast GetBlocksMessage failed")
+		}
+	}
+
+	return dm.requestBlockBodies()
+}
+
+// requestBlockBodies requests the blocks of the best header chain which are not downloaded yet,
+// spreading the requests over the connections which support headers-first synchronization.
+// Peers which do not respond to their requests in time are scored as stalling, and their blocks are requested again.
+func (dm *Daemon) requestBlockBodies() error {
+	if dm.config.DisableNetworking {
+		return ErrNetworkingDisabled
+	}
+
+	headSeq, err := dm.syncHead()
+	if err != nil {
+		return err
+	}
+
+	var peers []peerHeight
+	var highest peerHeight
+	for _, c := range dm.connections.all() {
+		if !c.HasIntroduced() || c.ProtocolVersion < headersFirstProtocolVersion {
+			continue
+		}
+
+		p := peerHeight{
+			addr:   c.Addr,
+			height: c.Height,
+		}
+		peers = append(peers, p)
+		if p.height > highest.height {
+			highest = p
+		}
+	}
+
+	requests, expired := dm.headers.nextRequests(peers)
+
+	for _, r := range expired {
+		dm.misbehave(r.addr, misbehaviorStalling, fmt.Sprintf("request for %d blocks after block %d timed out", r.count, r.start-1))
+	}
+
+	for _, r := range requests {
+		if err := dm.sendMessage(r.addr, NewGetBlocksMessage(r.start-1, r.count)); err != nil {
+			logger.WithError(err).WithField("addr", r.addr).Warning("Send GetBlocksMessage failed")
+			dm.headers.cancelRequest(r)
+		}
+	}
+
+	// Request the following headers once the blocks of the known headers are being downloaded
+	best := dm.headers.best()
+	if highest.height > best && best-headSeq < dm.config.MaxBufferedBlocks {
+		m := NewGetHeadersMessage(headSeq, best, dm.config.HeadersRequestCount)
+		if err := dm.sendMessage(highest.addr, m); err != nil {
+			logger.WithError(err).WithField("addr", highest.addr).Warning("Send GetHeadersMessage failed")
+		}
+	}
+
+	return nil
+}
+
+// addHeaders adds signed headers to the best header chain. Returns the number of headers added.
+func (dm *Daemon) addHeaders(headers []SignedBlockHeader) (int, error) {
+	if _, err := dm.syncHead(); err != nil {
+		return 0, err
+	}
+
+	return dm.headers.addHeaders(headers)
+}
+
+// bufferBlocks buffers the blocks of the best header chain received from a peer,
+// and returns the blocks following headSeq which can be executed in order.
+// The peer is scored as misbehaving if a block does not match its header.
+func (dm *Daemon) bufferBlocks(addr string, headSeq uint64, blocks []coin.SignedBlock) []coin.SignedBlock {
+	ready, invalid := dm.headers.addBlocks(addr, headSeq, blocks)
+	for _, b := range invalid {
+		dm.misbehave(addr, misbehaviorInvalidBlock, fmt.Sprintf("block %d does not match its header", b.Block.Head.BkSeq))
+	}
+
+	return ready
+}
+
+// broadcastBlock sends a signed block to all connections.
+// Light clients which loaded a bloom filter are sent a MerkleBlockMessage with the matching transactions.
+// Peers which support compact blocks are sent a CompactBlockMessage, the others a GiveBlocksMessage.
 func (dm *Daemon) broadcastBlock(sb coin.SignedBlock) error {
 	if dm.config.DisableNetworking {
 		return ErrNetworkingDisabled
 	}
 
-	m := NewGiveBlocksMessage([]coin.SignedBlock{sb}, dm.config.MaxOutgoingMessageLength)
-	if len(m.Blocks) != 1 {
-		logger.Critical().Error("NewGiveBlocksMessage truncated its only block")
+	var compactAddrs, fullAddrs []string
+	filtered := 0
+	for _, c := range dm.connections.all() {
+		if !c.HasIntroduced() {
+			continue
+		}
+
+		if dm.filters.has(c.Addr) {
+			if err := dm.sendMerkleBlock(c.Addr, sb); err != nil {
+				logger.WithError(err).WithField("addr", c.Addr).Warning("sendMerkleBlock failed")
+			} else {
+				filtered++
+			}
+			continue
+		}
+
+		if c.ProtocolVersion >= compactBlocksProtocolVersion {
+			compactAddrs = append(compactAddrs, c.Addr)
+		} else {
+			fullAddrs = append(fullAddrs, c.Addr)
+		}
+	}
+
+	var compactErr, fullErr error
+	if len(compactAddrs) > 0 {
+		_, compactErr = dm.pool.Pool.BroadcastMessage(NewCompactBlockMessage(sb), compactAddrs)
+	}
+
+	if len(fullAddrs) > 0 {
+		m := NewGiveBlocksMessage([]coin.SignedBlock{sb}, dm.config.MaxOutgoingMessageLength)
+		if len(m.Blocks) != 1 {
+			logger.Critical().Error("NewGiveBlocksMessage truncated its only block")
+		}
+
+		_, fullErr = dm.pool.Pool.BroadcastMessage(m, fullAddrs)
+	}
+
+	switch {
+	case len(compactAddrs) == 0 && len(fullAddrs) == 0:
+		if filtered > 0 {
+			return nil
+		}
+		return gnet.ErrNoAddresses
+	case len(compactAddrs) == 0:
+		return fullErr
+	case len(fullAddrs) == 0:
+		return compactErr
+	case compactErr != nil && fullErr != nil:
+		return compactErr
+	default:
+		// The block was broadcast to some of the peers
+		return nil
+	}
+}
+
+// setBloomFilter sets the bloom filter of a light client peer
+func (dm *Daemon) setBloomFilter(addr string, f *BloomFilter) {
+	dm.filters.set(addr, f)
+}
+
+// clearBloomFilter removes the bloom filter of a light client peer
+func (dm *Daemon) clearBloomFilter(addr string) {
+	dm.filters.remove(addr)
+}
+
+// sendMerkleBlock sends a light client peer a MerkleBlockMessage with the transactions of a block that match its bloom filter,
+// followed by a GiveTxnsMessage with the matched transactions.
+// Returns ErrBloomFilterNotLoaded if the peer has not loaded a bloom filter.
+func (dm *Daemon) sendMerkleBlock(addr string, b coin.SignedBlock) error {
+	indexes, ok := dm.filters.matchBlock(addr, b)
+	if !ok {
+		return ErrBloomFilterNotLoaded
+	}
+
+	if err := dm.sendMessage(addr, NewMerkleBlockMessage(b, indexes)); err != nil {
+		return err
+	}
+
+	if len(indexes) == 0 {
+		return nil
+	}
+
+	txns := make([]coin.Transaction, len(indexes))
+	for i, index := range indexes {
+		txns[i] = b.Body.Transactions[index]
+	}
+
+	m := NewGiveTxnsMessage(txns, dm.config.MaxOutgoingMessageLength)
+	if len(m.Transactions) != len(txns) {
+		logger.WithField("addr", addr).Warningf("NewGiveTxnsMessage truncated %d matched transactions to %d", len(txns), len(m.Transactions))
 	}
 
-	_, err := dm.broadcastMessage(m)
-	return err
+	return dm.sendMessage(addr, m)
+}
+
+// getTxnProofs returns the merkle proofs of the confirmed transactions
+func (dm *Daemon) getTxnProofs(txids []cipher.SHA256) ([]blockdb.TxnProof, error) {
+	return dm.visor.GetTxnProofs(txids)
 }
 
 // DaemonConfig returns the daemon config
@@ -1419,7 +1818,19 @@ func (dm *Daemon) sendRandomPeers(addr string) error {
 		return errors.New("No peers available")
 	}
 
+	// IPv6 peers are only sent to peers which can decode a GiveIPv6PeersMessage
+	if c := dm.connections.get(addr); c != nil && c.ProtocolVersion >= ipv6PeersProtocolVersion {
+		if m := NewGiveIPv6PeersMessage(peers, dm.config.MaxOutgoingMessageLength); len(m.Peers) > 0 {
+			if err := dm.sendMessage(addr, m); err != nil {
+				return err
+			}
+		}
+	}
+
 	m := NewGivePeersMessage(peers, dm.config.MaxOutgoingMessageLength)
+	if len(m.Peers) == 0 {
+		return nil
+	}
 
 	return dm.sendMessage(addr, m)
 }
@@ -1550,6 +1961,11 @@ func (dm *Daemon) executeSignedBlock(b coin.SignedBlock) error {
 	return dm.visor.ExecuteSignedBlock(b)
 }
 
+// executeSignedBlocks executes a batch of signed blocks in a single database transaction
+func (dm *Daemon) executeSignedBlocks(blocks []coin.SignedBlock) error {
+	return dm.visor.ProcessBlocks(blocks)
+}
+
 // filterKnownUnconfirmed returns unconfirmed txn hashes with known ones removed
 func (dm *Daemon) filterKnownUnconfirmed(txns []cipher.SHA256) ([]cipher.SHA256, error) {
 	return dm.visor.FilterKnownUnconfirmed(txns)
@@ -1560,6 +1976,21 @@ func (dm *Daemon) getKnownUnconfirmed(txns []cipher.SHA256) (coin.Transactions,
 	return dm.visor.GetKnownUnconfirmed(txns)
 }
 
+// getAllUnconfirmed returns all unconfirmed transactions
+func (dm *Daemon) getAllUnconfirmed() (coin.Transactions, error) {
+	utxns, err := dm.visor.GetAllUnconfirmedTransactions()
+	if err != nil {
+		return nil, err
+	}
+
+	txns := make(coin.Transactions, len(utxns))
+	for i, utxn := range utxns {
+		txns[i] = utxn.Transaction
+	}
+
+	return txns, nil
+}
+
 // injectTransaction records a coin.Transaction to the UnconfirmedTxnPool if the txn is not
 // already in the blockchain.
 // The bool return value is whether or not the transaction was already in the pool.
@@ -1577,6 +2008,10 @@ type Connection struct {
 	Pex  pex.Peer
 	Gnet GnetConnectionDetails
 	ConnectionDetails
+	// Protected is true if the connection is an incoming connection in a protected slot
+	Protected bool
+	// MisbehaviorScore is the misbehavior score of the connection's IP address
+	MisbehaviorScore int
 }
 
 // GnetConnectionDetails connection data from gnet
@@ -1609,8 +2044,9 @@ func newConnection(dc *connection, gc *gnet.Connection, pp *pex.Peer) Connection
 	return c
 }
 
-// newConnection creates a Connection from daemon.connection, gnet.Connection and pex.Peer
-func (dm *Daemon) newConnection(c *connection) (*Connection, error) {
+// newConnection creates a Connection from daemon.connection, gnet.Connection and pex.Peer.
+// protected are the addresses of the incoming connections in protected slots
+func (dm *Daemon) newConnection(c *connection, protected map[string]struct{}) (*Connection, error) {
 	if c == nil {
 		return nil, nil
 	}
@@ -1630,6 +2066,12 @@ func (dm *Daemon) newConnection(c *connection) (*Connection, error) {
 	}
 
 	cc := newConnection(c, gc, pp)
+
+	_, cc.Protected = protected[c.Addr]
+	if ip, _, err := iputil.SplitAddr(c.Addr); err == nil {
+		cc.MisbehaviorScore = dm.bans.Score(ip)
+	}
+
 	return &cc, nil
 }
 
@@ -1640,11 +2082,12 @@ func (dm *Daemon) GetConnections(f func(c Connection) bool) ([]Connection, error
 	}
 
 	cs := dm.connections.all()
+	protected := dm.protectedConnections(cs)
 
 	conns := make([]Connection, 0)
 
 	for _, c := range cs {
-		cc, err := dm.newConnection(&c)
+		cc, err := dm.newConnection(&c, protected)
 		if err != nil {
 			return nil, err
 		}
@@ -1680,7 +2123,7 @@ func (dm *Daemon) GetConnection(addr string) (*Connection, error) {
 		return nil, nil
 	}
 
-	return dm.newConnection(c)
+	return dm.newConnection(c, dm.protectedConnections(dm.connections.all()))
 }
 
 // DisconnectByGnetID disconnects a connection by gnet ID
@@ -1693,6 +2136,85 @@ func (dm *Daemon) DisconnectByGnetID(gnetID uint64) error {
 	return dm.Disconnect(c.Addr, ErrDisconnectRequestedByOperator)
 }
 
+// isBanned returns true if the IP address of a peer is banned
+func (dm *Daemon) isBanned(addr string) bool {
+	ip, _, err := iputil.SplitAddr(addr)
+	if err != nil {
+		return false
+	}
+	return dm.bans.IsBanned(ip)
+}
+
+// misbehave adds to the misbehavior score of a peer's IP address.
+// If the score reaches the ban threshold, the IP address is banned and its connections are disconnected.
+// Trusted peers are not scored.
+func (dm *Daemon) misbehave(addr string, score int, reason string) {
+	if dm.isTrustedPeer(addr) {
+		return
+	}
+
+	ip, _, err := iputil.SplitAddr(addr)
+	if err != nil {
+		logger.WithError(err).WithField("addr", addr).Error("misbehave: iputil.SplitAddr failed")
+		return
+	}
+
+	fields := logrus.Fields{
+		"addr":   addr,
+		"score":  score,
+		"reason": reason,
+	}
+	logger.WithFields(fields).Info("Peer misbehaved")
+
+	if dm.bans.Misbehave(ip, score, reason) {
+		logger.WithFields(fields).WithField("duration", dm.config.BanDuration).Warning("Peer banned")
+		dm.disconnectIP(ip, ErrDisconnectIsBlacklisted)
+	}
+}
+
+// disconnectIP disconnects all connections from an IP address
+func (dm *Daemon) disconnectIP(ip string, r gnet.DisconnectReason) {
+	for _, c := range dm.connections.all() {
+		a, _, err := iputil.SplitAddr(c.Addr)
+		if err != nil || a != ip {
+			continue
+		}
+
+		if err := dm.Disconnect(c.Addr, r); err != nil {
+			logger.WithError(err).WithField("addr", c.Addr).Error("Disconnect")
+		}
+	}
+}
+
+// GetBans returns the banned IP addresses
+func (dm *Daemon) GetBans() []Ban {
+	return dm.bans.All()
+}
+
+// BanIP bans an IP address and disconnects its connections.
+// If duration is 0, the IP address is banned for DaemonConfig.BanDuration.
+func (dm *Daemon) BanIP(ip, reason string, duration time.Duration) (*Ban, error) {
+	if net.ParseIP(ip) == nil {
+		return nil, fmt.Errorf("Invalid IP address %q", ip)
+	}
+
+	ban := dm.bans.Add(ip, reason, duration)
+	logger.WithFields(logrus.Fields{
+		"ip":       ip,
+		"reason":   reason,
+		"duration": ban.Expires.Sub(ban.Created),
+	}).Info("IP address banned by the node operator")
+
+	dm.disconnectIP(ip, ErrDisconnectIsBlacklisted)
+
+	return &ban, nil
+}
+
+// UnbanIP removes the ban of an IP address
+func (dm *Daemon) UnbanIP(ip string) error {
+	return dm.bans.Remove(ip)
+}
+
 // GetTrustConnections returns all trusted connections
 func (dm *Daemon) GetTrustConnections() []string {
 	return dm.pex.Trusted().ToAddrs()
@@ -47,6 +47,8 @@ var (
 	ErrDisconnectInvalidMaxTransactionSize gnet.DisconnectReason = errors.New("Invalid max transaction size in introduction message")
 	// ErrDisconnectInvalidMaxDropletPrecision invalid max droplet precision in introduction message
 	ErrDisconnectInvalidMaxDropletPrecision gnet.DisconnectReason = errors.New("Invalid max droplet precision in introduction message")
+	// ErrDisconnectEvicted the incoming connections are full and the connection was evicted for another one
+	ErrDisconnectEvicted gnet.DisconnectReason = errors.New("Evicted to make room for another incoming connection")
 
 	// ErrDisconnectUnknownReason used when mapping an unknown reason code to an error. Is not sent over the network.
 	ErrDisconnectUnknownReason gnet.DisconnectReason = errors.New("Unknown DisconnectReason")
@@ -73,6 +75,7 @@ var (
 		ErrDisconnectInvalidBurnFactor:             17,
 		ErrDisconnectInvalidMaxTransactionSize:     18,
 		ErrDisconnectInvalidMaxDropletPrecision:    19,
+		ErrDisconnectEvicted:                       20,
 
 		// gnet codes are registered here, but they are not sent in a DISC
 		// message by gnet. Only daemon sends a DISC packet.
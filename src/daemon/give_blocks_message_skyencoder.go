@@ -78,7 +78,7 @@ func encodeSizeGiveBlocksMessage(obj *GiveBlocksMessage) uint64 {
 
 			// x.Out
 			i2 += 4
-			{
+			for _, x := range x.Out {
 				i3 := uint64(0)
 
 				// x.Address.Version
@@ -94,11 +94,9 @@ func encodeSizeGiveBlocksMessage(obj *GiveBlocksMessage) uint64 {
 				i3 += 8
 
 				// x.ProgramState
-				// WARNING: x.Out[0].ProgramState manually changed from x.ProgramState
-				// WARNING: This is not considering program states in different `Out`s with different lengths
-				i3 += 4 + uint64(len(x.Out[0].ProgramState))
+				i3 += 4 + uint64(len(x.ProgramState))
 
-				i2 += uint64(len(x.Out)) * i3
+				i2 += i3
 			}
 
 			// x.MainExpressions
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"net"
 	"reflect"
+	"strconv"
 	"sync"
 	"time"
 
@@ -21,6 +22,7 @@ import (
 	"github.com/SkycoinProject/cx-chains/src/daemon/strand"
 	"github.com/SkycoinProject/cx-chains/src/util/elapse"
 	"github.com/SkycoinProject/cx-chains/src/util/logging"
+	"github.com/SkycoinProject/cx-chains/src/util/socks5"
 )
 
 // DisconnectReason is passed to ConnectionPool's DisconnectCallback
@@ -98,12 +100,21 @@ type Config struct {
 	Address string
 	// Port to listen on. Set to 0 for arbitrary assignment
 	Port uint16
+	// Network to listen on, "tcp" for IPv4 and IPv6, "tcp4" for IPv4 only or "tcp6" for IPv6 only
+	ListenNetwork string
+	// Address of a SOCKS5 proxy that outgoing connections are made through, as host:port.
+	// Leave empty to connect directly
+	Proxy string
 	// Maximum total connections. Must be >= MaxOutgoingConnections + MaxDefaultPeerOutgoingConnections.
 	MaxConnections int
 	// Maximum outgoing connections
 	MaxOutgoingConnections int
 	// Maximum allowed default outgoing connection number
 	MaxDefaultPeerOutgoingConnections int
+	// Maximum incoming connections. Incoming connections are also limited to
+	// MaxConnections - MaxOutgoingConnections - MaxDefaultPeerOutgoingConnections.
+	// Set to 0 to apply only that limit
+	MaxIncomingConnections int
 	// Messages greater than length are rejected and the sender disconnected
 	MaxIncomingMessageLength int
 	// Messages greater than length are not sent and an error is reported in a SendResult
@@ -141,6 +152,7 @@ func NewConfig() Config {
 	return Config{
 		Address:                           "",
 		Port:                              0,
+		ListenNetwork:                     "tcp",
 		MaxConnections:                    128,
 		MaxOutgoingMessageLength:          256 * 1024,
 		MaxIncomingMessageLength:          1024 * 1024,
@@ -293,10 +305,10 @@ func (pool *ConnectionPool) Run() error {
 	}()
 
 	// start the connection accept loop
-	addr := fmt.Sprintf("%s:%v", pool.Config.Address, pool.Config.Port)
-	logger.Infof("Listening for connections on %s...", addr)
+	addr := net.JoinHostPort(pool.Config.Address, strconv.Itoa(int(pool.Config.Port)))
+	logger.Infof("Listening for connections on %s (%s)...", addr, pool.Config.ListenNetwork)
 
-	ln, err := net.Listen("tcp", addr)
+	ln, err := net.Listen(pool.Config.ListenNetwork, addr)
 	if err != nil {
 		return err
 	}
@@ -763,7 +775,11 @@ func (pool *ConnectionPool) isConnExist(addr string) bool {
 }
 
 func (pool *ConnectionPool) isMaxIncomingConnectionsReached() bool {
-	return len(pool.pool) >= (pool.Config.MaxConnections - pool.Config.MaxOutgoingConnections - pool.Config.MaxDefaultPeerOutgoingConnections)
+	incoming := len(pool.pool) - len(pool.outgoingConnections)
+	if pool.Config.MaxIncomingConnections > 0 && incoming >= pool.Config.MaxIncomingConnections {
+		return true
+	}
+	return incoming >= (pool.Config.MaxConnections - pool.Config.MaxOutgoingConnections - pool.Config.MaxDefaultPeerOutgoingConnections)
 }
 
 func (pool *ConnectionPool) isMaxOutgoingConnectionsReached() bool {
@@ -818,7 +834,7 @@ func (pool *ConnectionPool) Connect(address string) error {
 	}
 
 	logger.WithField("addr", address).Debugf("Making TCP connection")
-	conn, err := net.DialTimeout("tcp", address, pool.Config.DialTimeout)
+	conn, err := pool.dial(address)
 	if err != nil {
 		return err
 	}
@@ -836,6 +852,19 @@ func (pool *ConnectionPool) Connect(address string) error {
 	return nil
 }
 
+// dial makes a TCP connection to address, through the SOCKS5 proxy if configured
+func (pool *ConnectionPool) dial(address string) (net.Conn, error) {
+	if pool.Config.Proxy == "" {
+		return net.DialTimeout("tcp", address, pool.Config.DialTimeout)
+	}
+
+	d := socks5.Dialer{
+		ProxyAddr: pool.Config.Proxy,
+		Timeout:   pool.Config.DialTimeout,
+	}
+	return d.Dial("tcp", address)
+}
+
 // Disconnect removes a connection from the pool by address and invokes DisconnectCallback
 func (pool *ConnectionPool) Disconnect(addr string, r DisconnectReason) error {
 	return pool.strand("Disconnect", func() error {
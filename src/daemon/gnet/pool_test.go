@@ -129,6 +129,70 @@ func TestNewConnectionAlreadyConnected(t *testing.T) {
 	<-q
 }
 
+func TestCanConnectMaxIncomingConnections(t *testing.T) {
+	tt := []struct {
+		name                   string
+		maxIncomingConnections int
+		incoming               int
+		outgoing               int
+		err                    error
+	}{
+		{
+			name:     "below limit without max incoming connections",
+			incoming: 7,
+			outgoing: 8,
+		},
+		{
+			name:     "outgoing connections don't count toward the limit",
+			incoming: 7,
+			outgoing: 16,
+		},
+		{
+			name:     "MaxConnections - MaxOutgoingConnections - MaxDefaultPeerOutgoingConnections reached",
+			incoming: 8,
+			err:      ErrMaxIncomingConnectionsReached,
+		},
+		{
+			name:                   "below max incoming connections",
+			maxIncomingConnections: 4,
+			incoming:               3,
+			outgoing:               8,
+		},
+		{
+			name:                   "max incoming connections reached",
+			maxIncomingConnections: 4,
+			incoming:               4,
+			err:                    ErrMaxIncomingConnectionsReached,
+		},
+		{
+			name:                   "max incoming connections above the limit of MaxConnections",
+			maxIncomingConnections: 12,
+			incoming:               8,
+			err:                    ErrMaxIncomingConnectionsReached,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := newTestConfig()
+			cfg.MaxIncomingConnections = tc.maxIncomingConnections
+			p, err := NewConnectionPool(cfg, nil)
+			require.NoError(t, err)
+
+			for i := 0; i < tc.incoming+tc.outgoing; i++ {
+				a := fmt.Sprintf("127.0.0.1:%d", 7000+i)
+				p.pool[uint64(i+1)] = &Connection{}
+				if i >= tc.incoming {
+					p.outgoingConnections[a] = struct{}{}
+				}
+			}
+
+			err = p.canConnect("127.0.0.2:7000", false)
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
 func TestAcceptConnections(t *testing.T) {
 	cfg := newTestConfig()
 	p, err := NewConnectionPool(cfg, nil)
@@ -0,0 +1,373 @@
+package daemon
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+)
+
+var (
+	// ErrHeaderInvalidSignature is returned when a header is not signed by the blockchain pubkey
+	ErrHeaderInvalidSignature = errors.New("Header signature is invalid")
+	// ErrHeaderNotConnected is returned when a header does not follow the best header
+	ErrHeaderNotConnected = errors.New("Header does not follow the best header")
+)
+
+// SignedBlockHeader is a block header with the signature of the block.
+// The signature is of the header hash, so it can be verified without the block body.
+type SignedBlockHeader struct {
+	Header coin.BlockHeader
+	Sig    cipher.Sig
+}
+
+// NewSignedBlockHeader returns the SignedBlockHeader of a block
+func NewSignedBlockHeader(b coin.SignedBlock) SignedBlockHeader {
+	return SignedBlockHeader{
+		Header: b.Head,
+		Sig:    b.Sig,
+	}
+}
+
+// Hash returns the hash of the header, which is the hash of its block
+func (h SignedBlockHeader) Hash() cipher.SHA256 {
+	return h.Header.Hash()
+}
+
+// bodyRequest is a request for the blocks of the headers [start, start+count) made to a peer
+type bodyRequest struct {
+	addr      string
+	start     uint64
+	count     uint64
+	requested time.Time
+}
+
+// peerHeight is the blockchain height of a peer
+type peerHeight struct {
+	addr   string
+	height uint64
+}
+
+// headerSyncConfig configures headerSync
+type headerSyncConfig struct {
+	// Pubkey of the block publisher, which signs the headers
+	blockchainPubkey cipher.PubKey
+	// Number of blocks to request from a peer at once
+	requestCount uint64
+	// Maximum number of outstanding block requests to a peer
+	maxRequestsPerPeer int
+	// How long a peer has to respond to a block request before the blocks are requested from another peer
+	requestTimeout time.Duration
+	// Maximum number of blocks ahead of the blockchain head to download
+	maxBufferedBlocks uint64
+	// Maximum number of headers ahead of the blockchain head to track
+	maxHeadersAhead uint64
+}
+
+// headerSync tracks the best chain of signed headers ahead of the blockchain head,
+// and schedules the download of the blocks of the headers from multiple peers in parallel.
+// The downloaded blocks are buffered until all of the blocks before them are downloaded,
+// so that they can be executed in order.
+type headerSync struct {
+	sync.Mutex
+	config headerSyncConfig
+
+	headSeq  uint64
+	bestSeq  uint64
+	bestHash cipher.SHA256
+	headers  map[uint64]SignedBlockHeader
+	blocks   map[uint64]coin.SignedBlock
+	// Outstanding block requests, by the seq of their first block
+	requests map[uint64]bodyRequest
+	now      func() time.Time
+}
+
+// newHeaderSync creates a headerSync
+func newHeaderSync(c headerSyncConfig) *headerSync {
+	return &headerSync{
+		config:   c,
+		headers:  make(map[uint64]SignedBlockHeader),
+		blocks:   make(map[uint64]coin.SignedBlock),
+		requests: make(map[uint64]bodyRequest),
+		now:      time.Now,
+	}
+}
+
+// reset forgets the headers, blocks and requests ahead of the blockchain head. Must be called with the lock held.
+func (s *headerSync) reset(headSeq uint64, headHash cipher.SHA256) {
+	s.headers = make(map[uint64]SignedBlockHeader)
+	s.blocks = make(map[uint64]coin.SignedBlock)
+	s.requests = make(map[uint64]bodyRequest)
+	s.bestSeq = headSeq
+	s.bestHash = headHash
+}
+
+// setHead sets the blockchain head, removing the headers, blocks and requests which are no longer ahead of it
+func (s *headerSync) setHead(headSeq uint64, headHash cipher.SHA256) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.headSeq = headSeq
+
+	// The header chain does not lead to the blockchain head, it can't be used anymore
+	if h, ok := s.headers[headSeq]; ok && h.Hash() != headHash {
+		s.reset(headSeq, headHash)
+		return
+	}
+
+	if s.bestSeq <= headSeq {
+		s.reset(headSeq, headHash)
+		return
+	}
+
+	for seq := range s.headers {
+		if seq <= headSeq {
+			delete(s.headers, seq)
+		}
+	}
+
+	for seq := range s.blocks {
+		if seq <= headSeq {
+			delete(s.blocks, seq)
+		}
+	}
+
+	for start, r := range s.requests {
+		if start+r.count-1 <= headSeq {
+			delete(s.requests, start)
+		}
+	}
+}
+
+// best returns the seq of the best header
+func (s *headerSync) best() uint64 {
+	s.Lock()
+	defer s.Unlock()
+	return s.bestSeq
+}
+
+// addHeaders adds the headers which extend the best header chain, verifying their signatures.
+// Headers which are already known are skipped. Returns the number of headers added.
+func (s *headerSync) addHeaders(headers []SignedBlockHeader) (int, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	added := 0
+	for _, h := range headers {
+		if h.Header.BkSeq <= s.bestSeq {
+			continue
+		}
+
+		if h.Header.BkSeq != s.bestSeq+1 || h.Header.PrevHash != s.bestHash {
+			return added, ErrHeaderNotConnected
+		}
+
+		if s.bestSeq-s.headSeq >= s.config.maxHeadersAhead {
+			return added, nil
+		}
+
+		hash := h.Hash()
+		if err := cipher.VerifyPubKeySignedHash(s.config.blockchainPubkey, h.Sig, hash); err != nil {
+			return added, ErrHeaderInvalidSignature
+		}
+
+		s.headers[h.Header.BkSeq] = h
+		s.bestSeq = h.Header.BkSeq
+		s.bestHash = hash
+		added++
+	}
+
+	return added, nil
+}
+
+// addBlocks buffers the blocks received from a peer, and returns the blocks following headSeq which can be executed in order.
+// Blocks of known headers must match their header, otherwise they are returned as invalid.
+// Blocks of unknown headers are not buffered, but are returned if they follow headSeq,
+// so that peers which do not serve headers can still be synchronized from.
+func (s *headerSync) addBlocks(addr string, headSeq uint64, blocks []coin.SignedBlock) ([]coin.SignedBlock, []coin.SignedBlock) {
+	s.Lock()
+	defer s.Unlock()
+
+	var invalid []coin.SignedBlock
+	unknown := make(map[uint64]coin.SignedBlock)
+	received := make(map[uint64]struct{}, len(blocks))
+
+	for _, b := range blocks {
+		seq := b.Block.Head.BkSeq
+		if seq <= headSeq {
+			continue
+		}
+
+		h, ok := s.headers[seq]
+		if !ok {
+			unknown[seq] = b
+			continue
+		}
+
+		if b.HashHeader() != h.Hash() || b.Body.Hash() != h.Header.BodyHash {
+			invalid = append(invalid, b)
+			continue
+		}
+
+		received[seq] = struct{}{}
+
+		if seq-headSeq > s.config.maxBufferedBlocks {
+			continue
+		}
+
+		s.blocks[seq] = b
+	}
+
+	// A request is answered once the peer sends any of its blocks.
+	// The blocks the response did not include are requested again.
+	for start, r := range s.requests {
+		if r.addr != addr {
+			continue
+		}
+
+		for seq := start; seq < start+r.count; seq++ {
+			if _, ok := received[seq]; ok {
+				delete(s.requests, start)
+				break
+			}
+		}
+	}
+
+	var ready []coin.SignedBlock
+	for seq := headSeq + 1; ; seq++ {
+		if b, ok := s.blocks[seq]; ok {
+			ready = append(ready, b)
+			delete(s.blocks, seq)
+		} else if b, ok := unknown[seq]; ok {
+			ready = append(ready, b)
+		} else {
+			break
+		}
+	}
+
+	return ready, invalid
+}
+
+// nextRequests schedules requests for the blocks of the best header chain which are not downloaded yet.
+// Requests are made to the peers whose height includes all of the blocks of the request,
+// spreading the requests over the peers with the fewest outstanding requests.
+// Returns the new requests, and the requests which timed out. The blocks of the timed out requests
+// are requested from another peer if possible.
+func (s *headerSync) nextRequests(peers []peerHeight) ([]bodyRequest, []bodyRequest) {
+	s.Lock()
+	defer s.Unlock()
+
+	now := s.now()
+
+	// expiredAddr is the peer which failed to respond to the expired request of a block, by block seq
+	expiredAddr := make(map[uint64]string)
+	var expired []bodyRequest
+	for start, r := range s.requests {
+		if now.Sub(r.requested) >= s.config.requestTimeout {
+			expired = append(expired, r)
+			for seq := start; seq < start+r.count; seq++ {
+				expiredAddr[seq] = r.addr
+			}
+			delete(s.requests, start)
+		}
+	}
+
+	sort.Slice(expired, func(i, j int) bool {
+		return expired[i].start < expired[j].start
+	})
+
+	// requested are the blocks of the outstanding requests. The outstanding requests don't follow the
+	// requestCount grid from the current head if the head advanced by part of a request since they were made
+	outstanding := make(map[string]int, len(peers))
+	requested := make(map[uint64]struct{})
+	for start, r := range s.requests {
+		outstanding[r.addr]++
+		for seq := start; seq < start+r.count; seq++ {
+			requested[seq] = struct{}{}
+		}
+	}
+
+	// missing returns true if the block at seq is neither buffered nor requested
+	missing := func(seq uint64) bool {
+		if _, ok := s.blocks[seq]; ok {
+			return false
+		}
+		_, ok := requested[seq]
+		return !ok
+	}
+
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].addr < peers[j].addr
+	})
+
+	limit := s.bestSeq
+	if limit > s.headSeq+s.config.maxBufferedBlocks {
+		limit = s.headSeq + s.config.maxBufferedBlocks
+	}
+
+	// Requests are made for runs of up to requestCount missing blocks
+	var requests []bodyRequest
+	for start := s.headSeq + 1; start <= limit; {
+		if !missing(start) {
+			start++
+			continue
+		}
+
+		end := start
+		for end < limit && end-start+1 < s.config.requestCount && missing(end+1) {
+			end++
+		}
+
+		addr, ok := s.choosePeer(peers, outstanding, end, expiredAddr[start])
+		if !ok {
+			start = end + 1
+			continue
+		}
+
+		r := bodyRequest{
+			addr:      addr,
+			start:     start,
+			count:     end - start + 1,
+			requested: now,
+		}
+		s.requests[start] = r
+		outstanding[addr]++
+		requests = append(requests, r)
+		start = end + 1
+	}
+
+	return requests, expired
+}
+
+// choosePeer returns the peer with the fewest outstanding requests which has the block at height,
+// avoiding the peer which failed to respond to the previous request if another peer is available.
+// Must be called with the lock held.
+func (s *headerSync) choosePeer(peers []peerHeight, outstanding map[string]int, height uint64, avoid string) (string, bool) {
+	var best string
+	found := false
+	for _, p := range peers {
+		if p.height < height || outstanding[p.addr] >= s.config.maxRequestsPerPeer {
+			continue
+		}
+
+		if !found || (best == avoid && p.addr != avoid) || (p.addr != avoid && outstanding[p.addr] < outstanding[best]) {
+			best = p.addr
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+// cancelRequest removes a request, so that its blocks are requested again
+func (s *headerSync) cancelRequest(r bodyRequest) {
+	s.Lock()
+	defer s.Unlock()
+
+	if cur, ok := s.requests[r.start]; ok && cur.addr == r.addr {
+		delete(s.requests, r.start)
+	}
+}
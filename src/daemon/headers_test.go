@@ -0,0 +1,296 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+)
+
+// makeHeaderChain creates a chain of n signed blocks following the block seq and hash
+func makeHeaderChain(t *testing.T, seckey cipher.SecKey, seq uint64, hash cipher.SHA256, n int) []coin.SignedBlock {
+	blocks := make([]coin.SignedBlock, n)
+	for i := range blocks {
+		b := coin.Block{
+			Head: coin.BlockHeader{
+				BkSeq:    seq + uint64(i) + 1,
+				PrevHash: hash,
+			},
+			Body: coin.BlockBody{
+				Transactions: coin.Transactions{
+					{
+						In: []cipher.SHA256{testutil.RandSHA256(t)},
+					},
+				},
+			},
+		}
+		b.Head.BodyHash = b.Body.Hash()
+		hash = b.HashHeader()
+
+		blocks[i] = coin.SignedBlock{
+			Block: b,
+			Sig:   cipher.MustSignHash(hash, seckey),
+		}
+	}
+
+	return blocks
+}
+
+func makeSignedBlockHeaders(blocks []coin.SignedBlock) []SignedBlockHeader {
+	headers := make([]SignedBlockHeader, len(blocks))
+	for i, b := range blocks {
+		headers[i] = NewSignedBlockHeader(b)
+	}
+	return headers
+}
+
+func newTestHeaderSync(pubkey cipher.PubKey) *headerSync {
+	return newHeaderSync(headerSyncConfig{
+		blockchainPubkey:   pubkey,
+		requestCount:       2,
+		maxRequestsPerPeer: 1,
+		requestTimeout:     time.Second * 30,
+		maxBufferedBlocks:  6,
+		maxHeadersAhead:    8,
+	})
+}
+
+func TestHeaderSyncAddHeaders(t *testing.T) {
+	pubkey, seckey := cipher.GenerateKeyPair()
+	headHash := testutil.RandSHA256(t)
+	blocks := makeHeaderChain(t, seckey, 5, headHash, 10)
+	headers := makeSignedBlockHeaders(blocks)
+
+	s := newTestHeaderSync(pubkey)
+	s.setHead(5, headHash)
+	require.Equal(t, uint64(5), s.best())
+
+	added, err := s.addHeaders(headers[:3])
+	require.NoError(t, err)
+	require.Equal(t, 3, added)
+	require.Equal(t, uint64(8), s.best())
+
+	// Known headers are skipped
+	added, err = s.addHeaders(headers[1:5])
+	require.NoError(t, err)
+	require.Equal(t, 2, added)
+	require.Equal(t, uint64(10), s.best())
+
+	// Headers must follow the best header
+	added, err = s.addHeaders(headers[6:7])
+	require.Equal(t, ErrHeaderNotConnected, err)
+	require.Equal(t, 0, added)
+
+	// Headers must be signed by the blockchain pubkey
+	_, badSeckey := cipher.GenerateKeyPair()
+	badHeader := headers[5]
+	badHeader.Sig = cipher.MustSignHash(badHeader.Hash(), badSeckey)
+	added, err = s.addHeaders([]SignedBlockHeader{badHeader})
+	require.Equal(t, ErrHeaderInvalidSignature, err)
+	require.Equal(t, 0, added)
+	require.Equal(t, uint64(10), s.best())
+
+	// No more than maxHeadersAhead headers are tracked
+	added, err = s.addHeaders(headers[5:])
+	require.NoError(t, err)
+	require.Equal(t, 3, added)
+	require.Equal(t, uint64(13), s.best())
+
+	// The headers up to the head block are removed
+	s.setHead(8, blocks[2].HashHeader())
+	require.Equal(t, uint64(13), s.best())
+	require.Len(t, s.headers, 5)
+
+	// The header chain is reset if it does not lead to the head block
+	s.setHead(9, testutil.RandSHA256(t))
+	require.Equal(t, uint64(9), s.best())
+	require.Empty(t, s.headers)
+}
+
+func TestHeaderSyncAddBlocks(t *testing.T) {
+	pubkey, seckey := cipher.GenerateKeyPair()
+	headHash := testutil.RandSHA256(t)
+	blocks := makeHeaderChain(t, seckey, 5, headHash, 8)
+
+	s := newTestHeaderSync(pubkey)
+	s.setHead(5, headHash)
+	_, err := s.addHeaders(makeSignedBlockHeaders(blocks[:6]))
+	require.NoError(t, err)
+
+	// Blocks after a missing block are buffered
+	ready, invalid := s.addBlocks("1.1.1.1:6000", 5, blocks[2:4])
+	require.Empty(t, ready)
+	require.Empty(t, invalid)
+
+	// Blocks which do not match their header are invalid
+	badBlock := blocks[1]
+	badBlock.Body.Transactions = nil
+	ready, invalid = s.addBlocks("2.2.2.2:6000", 5, []coin.SignedBlock{blocks[0], badBlock})
+	require.Equal(t, blocks[:1], ready)
+	require.Equal(t, []coin.SignedBlock{badBlock}, invalid)
+
+	// The buffered blocks are returned once the blocks before them are received.
+	// Blocks of unknown headers are returned if they follow the other blocks.
+	ready, invalid = s.addBlocks("2.2.2.2:6000", 6, []coin.SignedBlock{blocks[1], blocks[4], blocks[5], blocks[6]})
+	require.Equal(t, blocks[1:7], ready)
+	require.Empty(t, invalid)
+	require.Empty(t, s.blocks)
+
+	// Blocks of unknown headers are not returned if they do not follow the head block
+	ready, invalid = s.addBlocks("2.2.2.2:6000", 11, blocks[7:])
+	require.Empty(t, ready)
+	require.Empty(t, invalid)
+}
+
+func TestHeaderSyncNextRequests(t *testing.T) {
+	pubkey, seckey := cipher.GenerateKeyPair()
+	headHash := testutil.RandSHA256(t)
+	blocks := makeHeaderChain(t, seckey, 5, headHash, 8)
+
+	now := time.Unix(1600000000, 0).UTC()
+	s := newTestHeaderSync(pubkey)
+	s.now = func() time.Time {
+		return now
+	}
+	s.setHead(5, headHash)
+
+	// No requests are made without headers
+	requests, expired := s.nextRequests([]peerHeight{{addr: "1.1.1.1:6000", height: 100}})
+	require.Empty(t, requests)
+	require.Empty(t, expired)
+
+	_, err := s.addHeaders(makeSignedBlockHeaders(blocks))
+	require.NoError(t, err)
+
+	peers := []peerHeight{
+		{addr: "1.1.1.1:6000", height: 100},
+		{addr: "2.2.2.2:6000", height: 100},
+		// Does not have the blocks
+		{addr: "3.3.3.3:6000", height: 6},
+	}
+
+	// Requests are spread over the peers, no more than maxRequestsPerPeer at once
+	requests, expired = s.nextRequests(peers)
+	require.Empty(t, expired)
+	require.Equal(t, []bodyRequest{
+		{addr: "1.1.1.1:6000", start: 6, count: 2, requested: now},
+		{addr: "2.2.2.2:6000", start: 8, count: 2, requested: now},
+	}, requests)
+
+	requests, expired = s.nextRequests(peers)
+	require.Empty(t, requests)
+	require.Empty(t, expired)
+
+	// Answering a request allows another request to the peer
+	_, _ = s.addBlocks("1.1.1.1:6000", 5, blocks[:2])
+	s.setHead(7, blocks[1].HashHeader())
+
+	requests, expired = s.nextRequests(peers)
+	require.Empty(t, expired)
+	require.Equal(t, []bodyRequest{
+		{addr: "1.1.1.1:6000", start: 10, count: 2, requested: now},
+	}, requests)
+
+	// Timed out requests are made to another peer
+	now = now.Add(time.Second * 30)
+	requests, expired = s.nextRequests(peers)
+	require.Equal(t, []bodyRequest{
+		{addr: "2.2.2.2:6000", start: 8, count: 2, requested: now.Add(-time.Second * 30)},
+		{addr: "1.1.1.1:6000", start: 10, count: 2, requested: now.Add(-time.Second * 30)},
+	}, expired)
+	require.Equal(t, []bodyRequest{
+		{addr: "1.1.1.1:6000", start: 8, count: 2, requested: now},
+		{addr: "2.2.2.2:6000", start: 10, count: 2, requested: now},
+	}, requests)
+
+	// A cancelled request is made again
+	s.cancelRequest(requests[1])
+	requests, expired = s.nextRequests(peers)
+	require.Empty(t, expired)
+	require.Equal(t, []bodyRequest{
+		{addr: "2.2.2.2:6000", start: 10, count: 2, requested: now},
+	}, requests)
+
+	// No more than maxBufferedBlocks ahead of the head block are requested
+	s = newTestHeaderSync(pubkey)
+	s.config.maxRequestsPerPeer = 10
+	s.now = func() time.Time {
+		return now
+	}
+	s.setHead(5, headHash)
+	_, err = s.addHeaders(makeSignedBlockHeaders(blocks))
+	require.NoError(t, err)
+
+	requests, expired = s.nextRequests(peers[:1])
+	require.Empty(t, expired)
+	require.Equal(t, []bodyRequest{
+		{addr: "1.1.1.1:6000", start: 6, count: 2, requested: now},
+		{addr: "1.1.1.1:6000", start: 8, count: 2, requested: now},
+		{addr: "1.1.1.1:6000", start: 10, count: 2, requested: now},
+	}, requests)
+}
+
+func TestHeaderSyncNextRequestsPartialResponse(t *testing.T) {
+	pubkey, seckey := cipher.GenerateKeyPair()
+	headHash := testutil.RandSHA256(t)
+	blocks := makeHeaderChain(t, seckey, 5, headHash, 8)
+
+	now := time.Unix(1600000000, 0).UTC()
+	s := newTestHeaderSync(pubkey)
+	s.now = func() time.Time {
+		return now
+	}
+	s.setHead(5, headHash)
+
+	_, err := s.addHeaders(makeSignedBlockHeaders(blocks))
+	require.NoError(t, err)
+
+	peers := []peerHeight{
+		{addr: "1.1.1.1:6000", height: 100},
+		{addr: "2.2.2.2:6000", height: 100},
+	}
+
+	requests, expired := s.nextRequests(peers)
+	require.Empty(t, expired)
+	require.Equal(t, []bodyRequest{
+		{addr: "1.1.1.1:6000", start: 6, count: 2, requested: now},
+		{addr: "2.2.2.2:6000", start: 8, count: 2, requested: now},
+	}, requests)
+
+	// The peer sends only the first block of its request, so the head advances by fewer than requestCount blocks.
+	// Only the block it didn't send is requested again, not the blocks requested from the other peer
+	ready, invalid := s.addBlocks("1.1.1.1:6000", 5, blocks[:1])
+	require.Empty(t, invalid)
+	require.Equal(t, blocks[:1], ready)
+	s.setHead(6, blocks[0].HashHeader())
+
+	requests, expired = s.nextRequests(peers)
+	require.Empty(t, expired)
+	require.Equal(t, []bodyRequest{
+		{addr: "1.1.1.1:6000", start: 7, count: 1, requested: now},
+	}, requests)
+
+	// The blocks after the outstanding requests are requested in runs of requestCount from the end of the requests
+	s.config.maxRequestsPerPeer = 2
+	requests, expired = s.nextRequests(peers)
+	require.Empty(t, expired)
+	require.Equal(t, []bodyRequest{
+		{addr: "1.1.1.1:6000", start: 10, count: 2, requested: now},
+		{addr: "2.2.2.2:6000", start: 12, count: 1, requested: now},
+	}, requests)
+
+	// The buffered blocks are not requested again
+	ready, invalid = s.addBlocks("2.2.2.2:6000", 6, blocks[2:4])
+	require.Empty(t, invalid)
+	require.Empty(t, ready)
+	s.cancelRequest(bodyRequest{addr: "1.1.1.1:6000", start: 10})
+	requests, expired = s.nextRequests(peers)
+	require.Empty(t, expired)
+	require.Equal(t, []bodyRequest{
+		{addr: "1.1.1.1:6000", start: 10, count: 2, requested: now},
+	}, requests)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
 
 	"github.com/sirupsen/logrus"
@@ -17,6 +18,7 @@ import (
 	"github.com/SkycoinProject/cx-chains/src/params"
 	"github.com/SkycoinProject/cx-chains/src/util/iputil"
 	"github.com/SkycoinProject/cx-chains/src/util/useragent"
+	"github.com/SkycoinProject/cx-chains/src/visor/blockdb"
 )
 
 // Message represent a packet to be serialized over the network by
@@ -46,14 +48,23 @@ func NewMessageConfig(prefix string, m interface{}) MessageConfig {
 
 //go:generate skyencoder -unexported -struct IntroductionMessage
 //go:generate skyencoder -unexported -struct GivePeersMessage
+//go:generate skyencoder -unexported -struct GiveIPv6PeersMessage
 //go:generate skyencoder -unexported -struct GetBlocksMessage
 //go:generate skyencoder -unexported -struct GiveBlocksMessage
 //go:generate skyencoder -unexported -struct AnnounceBlocksMessage
+//go:generate skyencoder -unexported -struct CompactBlockMessage
+//go:generate skyencoder -unexported -struct GetHeadersMessage
+//go:generate skyencoder -unexported -struct GiveHeadersMessage
+//go:generate skyencoder -unexported -struct FilterLoadMessage
+//go:generate skyencoder -unexported -struct GetMerkleBlocksMessage
+//go:generate skyencoder -unexported -struct MerkleBlockMessage
+//go:generate skyencoder -unexported -struct GetTxnProofsMessage
 //go:generate skyencoder -unexported -struct GetTxnsMessage
 //go:generate skyencoder -unexported -struct GiveTxnsMessage
 //go:generate skyencoder -unexported -struct AnnounceTxnsMessage
 //go:generate skyencoder -unexported -struct DisconnectMessage
 //go:generate skyencoder -unexported -struct IPAddr
+//go:generate skyencoder -unexported -struct IPv6Addr
 //go:generate skyencoder -unexported -output-path . -package daemon -struct SignedBlock github.com/SkycoinProject/cx-chains/src/coin
 //go:generate skyencoder -unexported -output-path . -package daemon -struct Transaction github.com/SkycoinProject/cx-chains/src/coin
 
@@ -72,6 +83,15 @@ func getMessageConfigs() []MessageConfig {
 		NewMessageConfig("GIVT", GiveTxnsMessage{}),
 		NewMessageConfig("ANNT", AnnounceTxnsMessage{}),
 		NewMessageConfig("DISC", DisconnectMessage{}),
+		NewMessageConfig("CMPB", CompactBlockMessage{}),
+		NewMessageConfig("GETH", GetHeadersMessage{}),
+		NewMessageConfig("GIVH", GiveHeadersMessage{}),
+		NewMessageConfig("FLTL", FilterLoadMessage{}),
+		NewMessageConfig("FLTC", FilterClearMessage{}),
+		NewMessageConfig("GETM", GetMerkleBlocksMessage{}),
+		NewMessageConfig("MRKB", MerkleBlockMessage{}),
+		NewMessageConfig("GETX", GetTxnProofsMessage{}),
+		NewMessageConfig("GIP6", GiveIPv6PeersMessage{}),
 	}
 }
 
@@ -121,7 +141,7 @@ func NewIPAddr(addr string) (ipaddr IPAddr, err error) {
 		return
 	}
 
-	// TODO -- support ipv6
+	// IPv6 addresses are sent in an IPv6Addr
 	ipb := net.ParseIP(ips).To4()
 	if ipb == nil {
 		err = errors.New("Ignoring IPv6 address")
@@ -141,6 +161,41 @@ func (ipa IPAddr) String() string {
 	return fmt.Sprintf("%s:%d", net.IP(ipb).String(), ipa.Port)
 }
 
+// IPv6Addr compact representation of [IP]:Port for IPv6 addresses
+type IPv6Addr struct {
+	IP   [16]byte
+	Port uint16
+}
+
+// NewIPv6Addr returns an IPv6Addr from an [ip]:port string.
+// IPv4 addresses are rejected, they are sent in an IPAddr.
+func NewIPv6Addr(addr string) (ipaddr IPv6Addr, err error) {
+	ips, port, err := iputil.SplitAddr(addr)
+	if err != nil {
+		return
+	}
+
+	ip := net.ParseIP(ips)
+	if ip == nil {
+		err = errors.New("Invalid IP address")
+		return
+	}
+
+	if ip.To4() != nil {
+		err = errors.New("Ignoring IPv4 address")
+		return
+	}
+
+	copy(ipaddr.IP[:], ip.To16())
+	ipaddr.Port = port
+	return
+}
+
+// String returns IPv6Addr as "[ip]:port"
+func (ipa IPv6Addr) String() string {
+	return net.JoinHostPort(net.IP(ipa.IP[:]).String(), strconv.Itoa(int(ipa.Port)))
+}
+
 // asyncMessage messages that perform an action when received must implement this interface.
 // process() is called after the message is pulled off of messageEvent channel.
 // Messages should place themselves on the messageEvent channel in their
@@ -206,6 +261,10 @@ func NewGivePeersMessage(peers []pex.Peer, maxMsgLength uint64) *GivePeersMessag
 
 	ipaddrs := make([]IPAddr, 0, len(peers))
 	for _, ps := range peers {
+		if iputil.IsIPv6(ps.Addr) {
+			continue
+		}
+
 		ipaddr, err := NewIPAddr(ps.Addr)
 		if err != nil {
 			logger.WithError(err).WithField("addr", ps.Addr).Warning("GivePeersMessage skipping invalid address")
@@ -293,12 +352,15 @@ func (gpm *GivePeersMessage) Handle(mc *gnet.MessageContext, daemon interface{})
 
 // process Notifies the Pex instance that peers were received
 func (gpm *GivePeersMessage) process(d daemoner) {
+	addExchangedPeers(d, gpm.c, gpm.GetPeers())
+}
+
+// addExchangedPeers adds the peers received via PEX from the peer of the message context
+func addExchangedPeers(d daemoner, mc *gnet.MessageContext, peers []string) {
 	if d.pexConfig().Disabled {
 		return
 	}
 
-	peers := gpm.GetPeers()
-
 	if len(peers) == 0 {
 		return
 	}
@@ -314,8 +376,8 @@ func (gpm *GivePeersMessage) process(d daemoner) {
 	}
 
 	logger.WithFields(logrus.Fields{
-		"addr":   gpm.c.Addr,
-		"gnetID": gpm.c.ConnID,
+		"addr":   mc.Addr,
+		"gnetID": mc.ConnID,
 		"peers":  peersStr,
 		"count":  len(peers),
 	}).Debug("Received peers via PEX")
@@ -323,6 +385,117 @@ func (gpm *GivePeersMessage) process(d daemoner) {
 	d.addPeers(peers)
 }
 
+// ipv6PeersProtocolVersion is the minimum protocol version of the peers which can receive a GiveIPv6PeersMessage
+const ipv6PeersProtocolVersion = 4
+
+// GiveIPv6PeersMessage sent in response to GetPeersMessage with the IPv6 peers, in addition to a GivePeersMessage
+// with the IPv4 peers. It is only sent to peers with a protocol version >= ipv6PeersProtocolVersion.
+type GiveIPv6PeersMessage struct {
+	Peers []IPv6Addr           `enc:",maxlen=512"`
+	c     *gnet.MessageContext `enc:"-"`
+}
+
+// NewGiveIPv6PeersMessage converts the IPv6 peers of []pex.Peer to []IPv6Addr for binary transmission.
+// IPv4 peers are skipped. If the size of the message would exceed maxMsgLength, the IPv6Addr slice is truncated.
+func NewGiveIPv6PeersMessage(peers []pex.Peer, maxMsgLength uint64) *GiveIPv6PeersMessage {
+	ipaddrs := make([]IPv6Addr, 0, len(peers))
+	for _, ps := range peers {
+		if !iputil.IsIPv6(ps.Addr) {
+			continue
+		}
+
+		ipaddr, err := NewIPv6Addr(ps.Addr)
+		if err != nil {
+			logger.WithError(err).WithField("addr", ps.Addr).Warning("GiveIPv6PeersMessage skipping invalid address")
+			continue
+		}
+		ipaddrs = append(ipaddrs, ipaddr)
+
+		if len(ipaddrs) == 512 {
+			break
+		}
+	}
+
+	m := &GiveIPv6PeersMessage{
+		Peers: ipaddrs,
+	}
+	truncateGiveIPv6PeersMessage(m, maxMsgLength)
+	return m
+}
+
+// truncateGiveIPv6PeersMessage truncates the peers in GiveIPv6PeersMessage to fit inside of MaxOutgoingMessageLength
+func truncateGiveIPv6PeersMessage(m *GiveIPv6PeersMessage, maxMsgLength uint64) {
+	// The message length will include a 4 byte message type prefix.
+	// Panic if the prefix can't fit, otherwise we can't adjust the uint64 safely
+	if maxMsgLength < 4 {
+		logger.Panic("maxMsgLength must be >= 4")
+	}
+
+	maxMsgLength -= 4
+
+	// Measure the current message size, if it fits, return
+	n := m.EncodeSize()
+	if n <= maxMsgLength {
+		return
+	}
+
+	// Measure the size of an empty message
+	var mm GiveIPv6PeersMessage
+	size := mm.EncodeSize()
+
+	// Measure the size of the peers, advancing the slice index until it reaches capacity
+	index := -1
+	for i, ip := range m.Peers {
+		x := encodeSizeIPv6Addr(&ip)
+		if size+x > maxMsgLength {
+			break
+		}
+		size += x
+		index = i
+	}
+
+	m.Peers = m.Peers[:index+1]
+
+	if len(m.Peers) == 0 {
+		logger.Critical().Error("truncateGiveIPv6PeersMessage truncated peers to an empty slice")
+	}
+}
+
+// EncodeSize implements gnet.Serializer
+func (gpm *GiveIPv6PeersMessage) EncodeSize() uint64 {
+	return encodeSizeGiveIPv6PeersMessage(gpm)
+}
+
+// Encode implements gnet.Serializer
+func (gpm *GiveIPv6PeersMessage) Encode(buf []byte) error {
+	return encodeGiveIPv6PeersMessageToBuffer(buf, gpm)
+}
+
+// Decode implements gnet.Serializer
+func (gpm *GiveIPv6PeersMessage) Decode(buf []byte) (uint64, error) {
+	return decodeGiveIPv6PeersMessage(buf, gpm)
+}
+
+// GetPeers returns the peers contained in the message as an array of "[ip]:port" strings
+func (gpm *GiveIPv6PeersMessage) GetPeers() []string {
+	peers := make([]string, len(gpm.Peers))
+	for i, ipaddr := range gpm.Peers {
+		peers[i] = ipaddr.String()
+	}
+	return peers
+}
+
+// Handle handle message
+func (gpm *GiveIPv6PeersMessage) Handle(mc *gnet.MessageContext, daemon interface{}) error {
+	gpm.c = mc
+	return daemon.(daemoner).recordMessageEvent(gpm, mc)
+}
+
+// process Notifies the Pex instance that peers were received
+func (gpm *GiveIPv6PeersMessage) process(d daemoner) {
+	addExchangedPeers(d, gpm.c, gpm.GetPeers())
+}
+
 // IntroductionMessage is sent on first connect by both parties
 type IntroductionMessage struct {
 	c                    *gnet.MessageContext `enc:"-"`
@@ -881,8 +1054,9 @@ func (m *GiveBlocksMessage) process(d daemoner) {
 		return
 	}
 
-	// These DB queries are not performed in a transaction for performance reasons.
-	// It is not necessary that the blocks be executed together in a single transaction.
+	// The head block query is not performed in the same transaction as block execution.
+	// It is not necessary that all of the blocks be executed together in a single transaction,
+	// but batches of blocks are executed in one transaction to avoid a database commit per block.
 
 	processed := 0
 	maxSeq, ok, err := d.headBkSeq()
@@ -895,6 +1069,7 @@ func (m *GiveBlocksMessage) process(d daemoner) {
 		return
 	}
 
+	blocks := make([]coin.SignedBlock, 0, len(m.Blocks))
 	for _, b := range m.Blocks {
 		// To minimize waste when receiving multiple responses from peers
 		// we only break out of the loop if the block itself is invalid.
@@ -905,19 +1080,68 @@ func (m *GiveBlocksMessage) process(d daemoner) {
 		if b.Seq() <= maxSeq {
 			continue
 		}
+		blocks = append(blocks, b)
+	}
+
+	// Blocks of the best header chain are buffered until the blocks before them are received,
+	// and the blocks which can be executed in order are returned
+	blocks = d.bufferBlocks(m.c.Addr, maxSeq, blocks)
+
+	batchSize := d.DaemonConfig().ExecuteBlocksBatchSize
+	for len(blocks) > 0 {
+		n := batchSize
+		if n > len(blocks) {
+			n = len(blocks)
+		}
+		batch := blocks[:n]
+		blocks = blocks[n:]
+
+		if len(batch) > 1 {
+			err := d.executeSignedBlocks(batch)
+			if err == nil {
+				logger.Critical().WithFields(logrus.Fields{
+					"startSeq": batch[0].Block.Head.BkSeq,
+					"endSeq":   batch[len(batch)-1].Block.Head.BkSeq,
+				}).Info("Added new blocks")
+				processed += len(batch)
+				continue
+			}
+
+			// Execute the blocks of the failed batch one at a time below,
+			// so that the valid blocks before the invalid block are still added
+			logger.WithError(err).WithField("startSeq", batch[0].Block.Head.BkSeq).Warning("Failed to execute received blocks batch, executing blocks individually")
+		}
+
+		failed := false
+		for _, b := range batch {
+			if err := d.executeSignedBlock(b); err != nil {
+				logger.Critical().WithError(err).WithField("seq", b.Block.Head.BkSeq).Error("Failed to execute received block")
+
+				// Only the blocks signed by the block publisher can be executed,
+				// so a peer sending a block with an invalid signature is misbehaving
+				if err := b.VerifySignature(d.DaemonConfig().BlockchainPubkey); err != nil {
+					d.misbehave(m.c.Addr, misbehaviorInvalidBlock, fmt.Sprintf("invalid block %d: %v", b.Block.Head.BkSeq, err))
+				}
+
+				failed = true
+				break
+			}
 
-		err := d.executeSignedBlock(b)
-		if err == nil {
 			logger.Critical().WithField("seq", b.Block.Head.BkSeq).Info("Added new block")
 			processed++
-		} else {
-			logger.Critical().WithError(err).WithField("seq", b.Block.Head.BkSeq).Error("Failed to execute received block")
-			// Blocks must be received in order, so if one fails its assumed
-			// the rest are failing
+		}
+
+		// Blocks must be received in order, so if one fails its assumed
+		// the rest are failing
+		if failed {
 			break
 		}
 	}
 	if processed == 0 {
+		// The blocks may have been buffered, answering requests which are replaced by the next requests
+		if err := d.requestBlockBodies(); err != nil {
+			logger.WithError(err).Warning("requestBlockBodies")
+		}
 		return
 	}
 
@@ -944,9 +1168,8 @@ func (m *GiveBlocksMessage) process(d daemoner) {
 	}
 
 	// Request more blocks
-	gbm := NewGetBlocksMessage(headBkSeq, d.DaemonConfig().GetBlocksRequestCount)
-	if _, err := d.broadcastMessage(gbm); err != nil {
-		logger.WithError(err).Warning("Broadcast GetBlocksMessage failed")
+	if err := d.requestBlocksAfter(headBkSeq); err != nil {
+		logger.WithError(err).Warning("requestBlocksAfter")
 	}
 }
 
@@ -1018,6 +1241,699 @@ func (abm *AnnounceBlocksMessage) process(d daemoner) {
 	}
 }
 
+// compactBlocksProtocolVersion is the minimum protocol version of the peers which can receive a CompactBlockMessage
+const compactBlocksProtocolVersion = 3
+
+// CompactBlockMessage relays a new block as its header, signature and the short IDs of its transactions.
+// The receiving peer reconstructs the block from the transactions in its unconfirmed transaction pool,
+// and requests the full block with a GetBlocksMessage if any transaction is missing.
+type CompactBlockMessage struct {
+	Header   coin.BlockHeader
+	Sig      cipher.Sig
+	ShortIDs []uint64             `enc:",maxlen=65535"`
+	c        *gnet.MessageContext `enc:"-"`
+}
+
+// NewCompactBlockMessage creates CompactBlockMessage
+func NewCompactBlockMessage(sb coin.SignedBlock) *CompactBlockMessage {
+	hash := sb.HashHeader()
+	shortIDs := make([]uint64, len(sb.Body.Transactions))
+	for i, txn := range sb.Body.Transactions {
+		shortIDs[i] = shortTxnID(hash, txn.Hash())
+	}
+
+	return &CompactBlockMessage{
+		Header:   sb.Head,
+		Sig:      sb.Sig,
+		ShortIDs: shortIDs,
+	}
+}
+
+// shortTxnID returns the short ID of a transaction in a block.
+// The short ID is salted with the block hash, so that a collision in one block does not repeat in other blocks.
+func shortTxnID(blockHash, txnHash cipher.SHA256) uint64 {
+	h := cipher.AddSHA256(blockHash, txnHash)
+	return binary.LittleEndian.Uint64(h[:8])
+}
+
+// reconstruct rebuilds the signed block from txns. Returns false if a transaction is missing from txns,
+// or if the reconstructed transactions do not match the block body hash because of a short ID collision.
+func (m *CompactBlockMessage) reconstruct(txns coin.Transactions) (coin.SignedBlock, bool) {
+	hash := coin.Block{Head: m.Header}.HashHeader()
+
+	// Short IDs which match more than one transaction are ambiguous, and treated as missing
+	byShortID := make(map[uint64]*coin.Transaction, len(txns))
+	for i := range txns {
+		id := shortTxnID(hash, txns[i].Hash())
+		if _, ok := byShortID[id]; ok {
+			byShortID[id] = nil
+			continue
+		}
+		byShortID[id] = &txns[i]
+	}
+
+	blockTxns := make(coin.Transactions, len(m.ShortIDs))
+	for i, id := range m.ShortIDs {
+		txn := byShortID[id]
+		if txn == nil {
+			return coin.SignedBlock{}, false
+		}
+		blockTxns[i] = *txn
+	}
+
+	b := coin.SignedBlock{
+		Block: coin.Block{
+			Head: m.Header,
+			Body: coin.BlockBody{
+				Transactions: blockTxns,
+			},
+		},
+		Sig: m.Sig,
+	}
+
+	if b.Body.Hash() != m.Header.BodyHash {
+		return coin.SignedBlock{}, false
+	}
+
+	return b, true
+}
+
+// EncodeSize implements gnet.Serializer
+func (m *CompactBlockMessage) EncodeSize() uint64 {
+	return encodeSizeCompactBlockMessage(m)
+}
+
+// Encode implements gnet.Serializer
+func (m *CompactBlockMessage) Encode(buf []byte) error {
+	return encodeCompactBlockMessageToBuffer(buf, m)
+}
+
+// Decode implements gnet.Serializer
+func (m *CompactBlockMessage) Decode(buf []byte) (uint64, error) {
+	return decodeCompactBlockMessage(buf, m)
+}
+
+// Handle handles message
+func (m *CompactBlockMessage) Handle(mc *gnet.MessageContext, daemon interface{}) error {
+	m.c = mc
+	return daemon.(daemoner).recordMessageEvent(m, mc)
+}
+
+// process reconstructs and executes the block, falling back to requesting the full block
+func (m *CompactBlockMessage) process(d daemoner) {
+	if d.DaemonConfig().DisableNetworking {
+		return
+	}
+
+	fields := logrus.Fields{
+		"addr":   m.c.Addr,
+		"gnetID": m.c.ConnID,
+		"seq":    m.Header.BkSeq,
+	}
+
+	headBkSeq, ok, err := d.headBkSeq()
+	if err != nil {
+		logger.WithError(err).Error("CompactBlockMessage d.headBkSeq failed")
+		return
+	}
+	if !ok {
+		logger.Error("CompactBlockMessage no head block, cannot process CompactBlockMessage")
+		return
+	}
+
+	if m.Header.BkSeq <= headBkSeq {
+		return
+	}
+
+	requestBlocks := func() {
+		gbm := NewGetBlocksMessage(headBkSeq, d.DaemonConfig().GetBlocksRequestCount)
+		if err := d.sendMessage(m.c.Addr, gbm); err != nil {
+			logger.WithError(err).WithFields(fields).Error("Send GetBlocksMessage")
+		}
+	}
+
+	// Only the block following the head block can be executed, request the blocks in between
+	if m.Header.BkSeq != headBkSeq+1 {
+		requestBlocks()
+		return
+	}
+
+	txns, err := d.getAllUnconfirmed()
+	if err != nil {
+		logger.WithError(err).WithFields(fields).Error("CompactBlockMessage d.getAllUnconfirmed failed")
+		return
+	}
+
+	b, ok := m.reconstruct(txns)
+	if !ok {
+		logger.WithFields(fields).Debug("CompactBlockMessage could not be reconstructed, requesting the full block")
+		requestBlocks()
+		return
+	}
+
+	if err := d.executeSignedBlock(b); err != nil {
+		logger.Critical().WithError(err).WithFields(fields).Error("Failed to execute compact block")
+
+		// Only the blocks signed by the block publisher can be executed,
+		// so a peer sending a block with an invalid signature is misbehaving
+		if err := b.VerifySignature(d.DaemonConfig().BlockchainPubkey); err != nil {
+			d.misbehave(m.c.Addr, misbehaviorInvalidBlock, fmt.Sprintf("invalid block %d: %v", b.Block.Head.BkSeq, err))
+		}
+		return
+	}
+
+	logger.Critical().WithField("seq", b.Block.Head.BkSeq).Info("Added new compact block")
+
+	// Announce our new block to peers
+	abm := NewAnnounceBlocksMessage(b.Block.Head.BkSeq)
+	if _, err := d.broadcastMessage(abm); err != nil {
+		logger.WithError(err).Warning("Broadcast AnnounceBlocksMessage failed")
+	}
+}
+
+// headersFirstProtocolVersion is the minimum protocol version of the peers which can serve a GetHeadersMessage
+const headersFirstProtocolVersion = 3
+
+// GetHeadersMessage requests the signed headers of the blocks after LastHeader.
+// The headers are downloaded ahead of the blocks, so that the blocks can be requested from multiple peers in parallel.
+type GetHeadersMessage struct {
+	// Head block of the sender
+	LastBlock        uint64
+	LastHeader       uint64
+	RequestedHeaders uint64
+	c                *gnet.MessageContext `enc:"-"`
+}
+
+// NewGetHeadersMessage creates GetHeadersMessage
+func NewGetHeadersMessage(lastBlock, lastHeader, requestedHeaders uint64) *GetHeadersMessage {
+	return &GetHeadersMessage{
+		LastBlock:        lastBlock,
+		LastHeader:       lastHeader,
+		RequestedHeaders: requestedHeaders,
+	}
+}
+
+// EncodeSize implements gnet.Serializer
+func (m *GetHeadersMessage) EncodeSize() uint64 {
+	return encodeSizeGetHeadersMessage(m)
+}
+
+// Encode implements gnet.Serializer
+func (m *GetHeadersMessage) Encode(buf []byte) error {
+	return encodeGetHeadersMessageToBuffer(buf, m)
+}
+
+// Decode implements gnet.Serializer
+func (m *GetHeadersMessage) Decode(buf []byte) (uint64, error) {
+	return decodeGetHeadersMessage(buf, m)
+}
+
+// Handle handles message
+func (m *GetHeadersMessage) Handle(mc *gnet.MessageContext, daemon interface{}) error {
+	m.c = mc
+	return daemon.(daemoner).recordMessageEvent(m, mc)
+}
+
+// process replies with the signed headers of the blocks after LastHeader
+func (m *GetHeadersMessage) process(d daemoner) {
+	dc := d.DaemonConfig()
+	if dc.DisableNetworking {
+		return
+	}
+
+	fields := logrus.Fields{
+		"addr":   m.c.Addr,
+		"gnetID": m.c.ConnID,
+	}
+
+	// Record this as this peer's highest block
+	d.recordPeerHeight(m.c.Addr, m.c.ConnID, m.LastBlock)
+
+	requestedHeaders := m.RequestedHeaders
+	if requestedHeaders > dc.MaxGetHeadersResponseCount {
+		logger.WithFields(logrus.Fields{
+			"requestedHeaders":    requestedHeaders,
+			"maxRequestedHeaders": dc.MaxGetHeadersResponseCount,
+		}).WithFields(fields).Debug("GetHeadersMessage.RequestedHeaders value exceeds configured limit, reducing")
+		requestedHeaders = dc.MaxGetHeadersResponseCount
+	}
+
+	blocks, err := d.getSignedBlocksSince(m.LastHeader, requestedHeaders)
+	if err != nil {
+		logger.WithFields(fields).WithError(err).Error("getSignedBlocksSince failed")
+		return
+	}
+
+	if len(blocks) == 0 {
+		return
+	}
+
+	headers := make([]SignedBlockHeader, len(blocks))
+	for i, b := range blocks {
+		headers[i] = NewSignedBlockHeader(b)
+	}
+
+	logger.WithFields(fields).Debugf("GetHeadersMessage: replying with %d headers after block %d", len(headers), m.LastHeader)
+
+	if err := d.sendMessage(m.c.Addr, NewGiveHeadersMessage(headers, dc.MaxOutgoingMessageLength)); err != nil {
+		logger.WithFields(fields).WithError(err).Error("Send GiveHeadersMessage failed")
+	}
+}
+
+// GiveHeadersMessage sent in response to GetHeadersMessage
+type GiveHeadersMessage struct {
+	Headers []SignedBlockHeader  `enc:",maxlen=2048"`
+	c       *gnet.MessageContext `enc:"-"`
+}
+
+// NewGiveHeadersMessage creates GiveHeadersMessage.
+// If the size of message would exceed maxMsgLength, the header slice is truncated.
+func NewGiveHeadersMessage(headers []SignedBlockHeader, maxMsgLength uint64) *GiveHeadersMessage {
+	if len(headers) > 2048 {
+		headers = headers[:2048]
+	}
+
+	// The message length will include a 4 byte message type prefix.
+	// Panic if the prefix can't fit, otherwise we can't adjust the uint64 safely
+	if maxMsgLength < 4 {
+		logger.Panic("maxMsgLength must be >= 4")
+	}
+
+	// The headers are of a fixed size
+	var mm GiveHeadersMessage
+	size := mm.EncodeSize()
+	headerSize := encodeSizeGiveHeadersMessage(&GiveHeadersMessage{
+		Headers: make([]SignedBlockHeader, 1),
+	}) - size
+
+	if n := (maxMsgLength - 4 - size) / headerSize; uint64(len(headers)) > n {
+		headers = headers[:n]
+	}
+
+	return &GiveHeadersMessage{
+		Headers: headers,
+	}
+}
+
+// EncodeSize implements gnet.Serializer
+func (m *GiveHeadersMessage) EncodeSize() uint64 {
+	return encodeSizeGiveHeadersMessage(m)
+}
+
+// Encode implements gnet.Serializer
+func (m *GiveHeadersMessage) Encode(buf []byte) error {
+	return encodeGiveHeadersMessageToBuffer(buf, m)
+}
+
+// Decode implements gnet.Serializer
+func (m *GiveHeadersMessage) Decode(buf []byte) (uint64, error) {
+	return decodeGiveHeadersMessage(buf, m)
+}
+
+// Handle handles message
+func (m *GiveHeadersMessage) Handle(mc *gnet.MessageContext, daemon interface{}) error {
+	m.c = mc
+	return daemon.(daemoner).recordMessageEvent(m, mc)
+}
+
+// process adds the headers to the best header chain, then requests the following headers
+// and the blocks of the headers
+func (m *GiveHeadersMessage) process(d daemoner) {
+	dc := d.DaemonConfig()
+	if dc.DisableNetworking {
+		return
+	}
+
+	fields := logrus.Fields{
+		"addr":   m.c.Addr,
+		"gnetID": m.c.ConnID,
+	}
+
+	added, err := d.addHeaders(m.Headers)
+	switch err {
+	case nil:
+	case ErrHeaderInvalidSignature:
+		// Only the headers signed by the block publisher can be added,
+		// so a peer sending a header with an invalid signature is misbehaving
+		d.misbehave(m.c.Addr, misbehaviorInvalidBlock, err.Error())
+		return
+	default:
+		logger.WithError(err).WithFields(fields).Debug("GiveHeadersMessage d.addHeaders failed")
+	}
+
+	if added > 0 {
+		logger.WithFields(fields).Debugf("Added %d headers", added)
+
+		// The peer may have more headers
+		last := m.Headers[len(m.Headers)-1].Header.BkSeq
+		headBkSeq, ok, err := d.headBkSeq()
+		if err != nil {
+			logger.WithError(err).Error("GiveHeadersMessage d.headBkSeq failed")
+			return
+		}
+		if !ok {
+			logger.Error("GiveHeadersMessage no head block, cannot request headers")
+			return
+		}
+
+		gh := NewGetHeadersMessage(headBkSeq, last, dc.HeadersRequestCount)
+		if err := d.sendMessage(m.c.Addr, gh); err != nil {
+			logger.WithError(err).WithFields(fields).Error("Send GetHeadersMessage")
+		}
+	}
+
+	if err := d.requestBlockBodies(); err != nil {
+		logger.WithError(err).WithFields(fields).Warning("requestBlockBodies")
+	}
+}
+
+// FilterLoadMessage loads a bloom filter on the peer. The peer sends the light client
+// MerkleBlockMessages with only the transactions of a block that match the filter.
+// A previously loaded filter is replaced.
+type FilterLoadMessage struct {
+	Filter    []byte `enc:",maxlen=36000"`
+	HashFuncs uint32
+	Tweak     uint32
+	c         *gnet.MessageContext `enc:"-"`
+}
+
+// NewFilterLoadMessage creates FilterLoadMessage
+func NewFilterLoadMessage(f BloomFilter) *FilterLoadMessage {
+	return &FilterLoadMessage{
+		Filter:    f.Bits,
+		HashFuncs: f.HashFuncs,
+		Tweak:     f.Tweak,
+	}
+}
+
+// EncodeSize implements gnet.Serializer
+func (m *FilterLoadMessage) EncodeSize() uint64 {
+	return encodeSizeFilterLoadMessage(m)
+}
+
+// Encode implements gnet.Serializer
+func (m *FilterLoadMessage) Encode(buf []byte) error {
+	return encodeFilterLoadMessageToBuffer(buf, m)
+}
+
+// Decode implements gnet.Serializer
+func (m *FilterLoadMessage) Decode(buf []byte) (uint64, error) {
+	return decodeFilterLoadMessage(buf, m)
+}
+
+// Handle handles message
+func (m *FilterLoadMessage) Handle(mc *gnet.MessageContext, daemon interface{}) error {
+	m.c = mc
+	return daemon.(daemoner).recordMessageEvent(m, mc)
+}
+
+// process sets the bloom filter of the peer
+func (m *FilterLoadMessage) process(d daemoner) {
+	dc := d.DaemonConfig()
+	if dc.DisableNetworking || !dc.EnableSPVServer {
+		return
+	}
+
+	f, err := NewBloomFilter(m.Filter, m.HashFuncs, m.Tweak)
+	if err != nil {
+		d.misbehave(m.c.Addr, misbehaviorMalformedMessage, err.Error())
+		return
+	}
+
+	d.setBloomFilter(m.c.Addr, f)
+}
+
+// FilterClearMessage removes the bloom filter loaded on the peer. The peer sends full blocks again.
+type FilterClearMessage struct {
+	c *gnet.MessageContext `enc:"-"`
+}
+
+// EncodeSize implements gnet.Serializer
+func (m *FilterClearMessage) EncodeSize() uint64 {
+	return 0
+}
+
+// Encode implements gnet.Serializer
+func (m *FilterClearMessage) Encode(buf []byte) error {
+	return nil
+}
+
+// Decode implements gnet.Serializer
+func (m *FilterClearMessage) Decode(buf []byte) (uint64, error) {
+	return 0, nil
+}
+
+// Handle handles message
+func (m *FilterClearMessage) Handle(mc *gnet.MessageContext, daemon interface{}) error {
+	m.c = mc
+	return daemon.(daemoner).recordMessageEvent(m, mc)
+}
+
+// process removes the bloom filter of the peer
+func (m *FilterClearMessage) process(d daemoner) {
+	dc := d.DaemonConfig()
+	if dc.DisableNetworking || !dc.EnableSPVServer {
+		return
+	}
+
+	d.clearBloomFilter(m.c.Addr)
+}
+
+// GetMerkleBlocksMessage requests the blocks after LastBlock filtered by the bloom filter loaded with a FilterLoadMessage.
+// Each block is sent as a MerkleBlockMessage followed by a GiveTxnsMessage with the matched transactions.
+type GetMerkleBlocksMessage struct {
+	LastBlock       uint64
+	RequestedBlocks uint64
+	c               *gnet.MessageContext `enc:"-"`
+}
+
+// NewGetMerkleBlocksMessage creates GetMerkleBlocksMessage
+func NewGetMerkleBlocksMessage(lastBlock, requestedBlocks uint64) *GetMerkleBlocksMessage {
+	return &GetMerkleBlocksMessage{
+		LastBlock:       lastBlock,
+		RequestedBlocks: requestedBlocks,
+	}
+}
+
+// EncodeSize implements gnet.Serializer
+func (m *GetMerkleBlocksMessage) EncodeSize() uint64 {
+	return encodeSizeGetMerkleBlocksMessage(m)
+}
+
+// Encode implements gnet.Serializer
+func (m *GetMerkleBlocksMessage) Encode(buf []byte) error {
+	return encodeGetMerkleBlocksMessageToBuffer(buf, m)
+}
+
+// Decode implements gnet.Serializer
+func (m *GetMerkleBlocksMessage) Decode(buf []byte) (uint64, error) {
+	return decodeGetMerkleBlocksMessage(buf, m)
+}
+
+// Handle handles message
+func (m *GetMerkleBlocksMessage) Handle(mc *gnet.MessageContext, daemon interface{}) error {
+	m.c = mc
+	return daemon.(daemoner).recordMessageEvent(m, mc)
+}
+
+// process replies with the filtered blocks after LastBlock
+func (m *GetMerkleBlocksMessage) process(d daemoner) {
+	dc := d.DaemonConfig()
+	if dc.DisableNetworking || !dc.EnableSPVServer {
+		return
+	}
+
+	fields := logrus.Fields{
+		"addr":   m.c.Addr,
+		"gnetID": m.c.ConnID,
+	}
+
+	requestedBlocks := m.RequestedBlocks
+	if requestedBlocks > dc.MaxGetBlocksResponseCount {
+		logger.WithFields(logrus.Fields{
+			"requestedBlocks":    requestedBlocks,
+			"maxRequestedBlocks": dc.MaxGetBlocksResponseCount,
+		}).WithFields(fields).Debug("GetMerkleBlocksMessage.RequestedBlocks value exceeds configured limit, reducing")
+		requestedBlocks = dc.MaxGetBlocksResponseCount
+	}
+
+	blocks, err := d.getSignedBlocksSince(m.LastBlock, requestedBlocks)
+	if err != nil {
+		logger.WithFields(fields).WithError(err).Error("getSignedBlocksSince failed")
+		return
+	}
+
+	logger.WithFields(fields).Debugf("GetMerkleBlocksMessage: replying with %d blocks after block %d", len(blocks), m.LastBlock)
+
+	for _, b := range blocks {
+		if err := d.sendMerkleBlock(m.c.Addr, b); err != nil {
+			logger.WithFields(fields).WithError(err).Warning("sendMerkleBlock failed")
+			return
+		}
+	}
+}
+
+// MerkleMatch is a transaction of a block with the merkle branch from the transaction hash to the block body hash
+type MerkleMatch struct {
+	// Index of the transaction in the block
+	Index uint32
+	Txid  cipher.SHA256
+	// Sibling hashes of the merkle branch, from the transaction hash up
+	Proof []cipher.SHA256 `enc:",maxlen=32"`
+}
+
+// MerkleBlockMessage sends a light client the signed header of a block and the transactions of the block
+// that match its bloom filter, each with its merkle proof. The matched transactions follow in a GiveTxnsMessage.
+type MerkleBlockMessage struct {
+	Header coin.BlockHeader
+	Sig    cipher.Sig
+	// Number of transactions in the block
+	TxnCount uint32
+	Matches  []MerkleMatch        `enc:",maxlen=65535"`
+	c        *gnet.MessageContext `enc:"-"`
+}
+
+// NewMerkleBlockMessage creates MerkleBlockMessage with the transactions at indexes of the block
+func NewMerkleBlockMessage(sb coin.SignedBlock, indexes []int) *MerkleBlockMessage {
+	hashes := make([]cipher.SHA256, len(sb.Body.Transactions))
+	for i := range sb.Body.Transactions {
+		hashes[i] = sb.Body.Transactions[i].Hash()
+	}
+
+	matches := make([]MerkleMatch, len(indexes))
+	for i, index := range indexes {
+		matches[i] = MerkleMatch{
+			Index: uint32(index),
+			Txid:  hashes[index],
+			Proof: cipher.MerkleProof(hashes, index),
+		}
+	}
+
+	return &MerkleBlockMessage{
+		Header:   sb.Head,
+		Sig:      sb.Sig,
+		TxnCount: uint32(len(hashes)),
+		Matches:  matches,
+	}
+}
+
+// newMerkleBlockMessageFromProof creates MerkleBlockMessage with the transaction of a TxnProof
+func newMerkleBlockMessageFromProof(p blockdb.TxnProof) *MerkleBlockMessage {
+	return &MerkleBlockMessage{
+		Header: p.Header,
+		Sig:    p.Sig,
+		Matches: []MerkleMatch{
+			{
+				Index: p.Index,
+				Txid:  p.Txid,
+				Proof: p.Hashes,
+			},
+		},
+	}
+}
+
+// Verify checks that the header is signed by pubkey, and that the matched transactions are in the block
+func (m *MerkleBlockMessage) Verify(pubkey cipher.PubKey) error {
+	if err := cipher.VerifyPubKeySignedHash(pubkey, m.Sig, m.Header.Hash()); err != nil {
+		return err
+	}
+
+	for _, x := range m.Matches {
+		if !cipher.VerifyMerkleProof(m.Header.BodyHash, x.Txid, uint64(x.Index), x.Proof) {
+			return fmt.Errorf("merkle proof of transaction %s does not match the block body hash", x.Txid.Hex())
+		}
+	}
+
+	return nil
+}
+
+// EncodeSize implements gnet.Serializer
+func (m *MerkleBlockMessage) EncodeSize() uint64 {
+	return encodeSizeMerkleBlockMessage(m)
+}
+
+// Encode implements gnet.Serializer
+func (m *MerkleBlockMessage) Encode(buf []byte) error {
+	return encodeMerkleBlockMessageToBuffer(buf, m)
+}
+
+// Decode implements gnet.Serializer
+func (m *MerkleBlockMessage) Decode(buf []byte) (uint64, error) {
+	return decodeMerkleBlockMessage(buf, m)
+}
+
+// Handle handles message. MerkleBlockMessages are for light clients,
+// there is nothing for a full node to do when this is received.
+func (m *MerkleBlockMessage) Handle(mc *gnet.MessageContext, daemon interface{}) error {
+	return nil
+}
+
+// GetTxnProofsMessage requests the merkle proofs of confirmed transactions.
+// The proof of each confirmed transaction is sent in a MerkleBlockMessage with the transaction as its only match.
+// Unknown and unconfirmed transactions are ignored.
+type GetTxnProofsMessage struct {
+	Txids []cipher.SHA256      `enc:",maxlen=256"`
+	c     *gnet.MessageContext `enc:"-"`
+}
+
+// NewGetTxnProofsMessage creates GetTxnProofsMessage
+func NewGetTxnProofsMessage(txids []cipher.SHA256) *GetTxnProofsMessage {
+	if len(txids) > 256 {
+		txids = txids[:256]
+	}
+
+	return &GetTxnProofsMessage{
+		Txids: txids,
+	}
+}
+
+// EncodeSize implements gnet.Serializer
+func (m *GetTxnProofsMessage) EncodeSize() uint64 {
+	return encodeSizeGetTxnProofsMessage(m)
+}
+
+// Encode implements gnet.Serializer
+func (m *GetTxnProofsMessage) Encode(buf []byte) error {
+	return encodeGetTxnProofsMessageToBuffer(buf, m)
+}
+
+// Decode implements gnet.Serializer
+func (m *GetTxnProofsMessage) Decode(buf []byte) (uint64, error) {
+	return decodeGetTxnProofsMessage(buf, m)
+}
+
+// Handle handles message
+func (m *GetTxnProofsMessage) Handle(mc *gnet.MessageContext, daemon interface{}) error {
+	m.c = mc
+	return daemon.(daemoner).recordMessageEvent(m, mc)
+}
+
+// process replies with the merkle proofs of the confirmed transactions
+func (m *GetTxnProofsMessage) process(d daemoner) {
+	dc := d.DaemonConfig()
+	if dc.DisableNetworking || !dc.EnableSPVServer {
+		return
+	}
+
+	fields := logrus.Fields{
+		"addr":   m.c.Addr,
+		"gnetID": m.c.ConnID,
+	}
+
+	proofs, err := d.getTxnProofs(m.Txids)
+	if err != nil {
+		logger.WithFields(fields).WithError(err).Error("getTxnProofs failed")
+		return
+	}
+
+	for _, p := range proofs {
+		if err := d.sendMessage(m.c.Addr, newMerkleBlockMessageFromProof(p)); err != nil {
+			logger.WithFields(fields).WithError(err).Error("Send MerkleBlockMessage failed")
+			return
+		}
+	}
+}
+
 // SendingTxnsMessage send transaction message interface
 type SendingTxnsMessage interface {
 	GetFiltered() []cipher.SHA256
@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"errors"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -17,6 +18,7 @@ import (
 	"github.com/SkycoinProject/cx-chains/src/params"
 	"github.com/SkycoinProject/cx-chains/src/testutil"
 	"github.com/SkycoinProject/cx-chains/src/util/useragent"
+	"github.com/SkycoinProject/cx-chains/src/visor/blockdb"
 )
 
 func TestIntroductionMessage(t *testing.T) {
@@ -592,6 +594,22 @@ func TestMessageEncodeDecode(t *testing.T) {
 				},
 			},
 		},
+		{
+			goldenFile: "give-ipv6-peers-msg.golden",
+			obj:        &GiveIPv6PeersMessage{},
+			msg: &GiveIPv6PeersMessage{
+				Peers: []IPv6Addr{
+					{
+						IP:   [16]byte{0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x01},
+						Port: 1234,
+					},
+					{
+						IP:   [16]byte{0x20, 0x01, 0x0d, 0xb8, 0x85, 0xa3, 0, 0, 0, 0, 0x8a, 0x2e, 0x03, 0x70, 0x73, 0x34},
+						Port: 4321,
+					},
+				},
+			},
+		},
 		{
 			goldenFile: "ping-msg.golden",
 			obj:        &PingMessage{},
@@ -761,6 +779,128 @@ func TestMessageEncodeDecode(t *testing.T) {
 				MaxBkSeq: 50000,
 			},
 		},
+		{
+			goldenFile: "compact-block-msg.golden",
+			obj:        &CompactBlockMessage{},
+			msg: &CompactBlockMessage{
+				Header: coin.BlockHeader{
+					Version:  1,
+					Time:     1538036613,
+					BkSeq:    9999999999,
+					Fee:      1234123412341234,
+					PrevHash: cipher.MustSHA256FromHex("59cb7d0e2ce8a03d1054afcc28a22fe864a8813460d241db38c59d10e7c29132"),
+					BodyHash: cipher.MustSHA256FromHex("6d421469409591f0c3112884c8cf10f8bca5d8ab87c9c30dea2ea73b6751bbf9"),
+					UxHash:   cipher.MustSHA256FromHex("6ea6a972cf06d25908b29953aeddb68c3b6f3a9903e8f964dc89b0abc0645dea"),
+				},
+				Sig:      cipher.MustSigFromHex("8cf145e9ef4a4a5254bc57798a7a61dfed238768f94edc5635175c6b91bccd8ec1555da603c5e31b018e135b82b1525be8a92973c468a74b5b40b8da189cb465eb"),
+				ShortIDs: []uint64{1234567890123456789, 9876543210},
+			},
+		},
+		{
+			goldenFile: "get-headers-msg.golden",
+			obj:        &GetHeadersMessage{},
+			msg: &GetHeadersMessage{
+				LastBlock:        50000,
+				LastHeader:       51000,
+				RequestedHeaders: 1000,
+			},
+		},
+		{
+			goldenFile: "give-headers-msg.golden",
+			obj:        &GiveHeadersMessage{},
+			msg: &GiveHeadersMessage{
+				Headers: []SignedBlockHeader{
+					{
+						Header: coin.BlockHeader{
+							Version:  1,
+							Time:     1538036613,
+							BkSeq:    9999999999,
+							Fee:      1234123412341234,
+							PrevHash: cipher.MustSHA256FromHex("59cb7d0e2ce8a03d1054afcc28a22fe864a8813460d241db38c59d10e7c29132"),
+							BodyHash: cipher.MustSHA256FromHex("6d421469409591f0c3112884c8cf10f8bca5d8ab87c9c30dea2ea73b6751bbf9"),
+							UxHash:   cipher.MustSHA256FromHex("6ea6a972cf06d25908b29953aeddb68c3b6f3a9903e8f964dc89b0abc0645dea"),
+						},
+						Sig: cipher.MustSigFromHex("8cf145e9ef4a4a5254bc57798a7a61dfed238768f94edc5635175c6b91bccd8ec1555da603c5e31b018e135b82b1525be8a92973c468a74b5b40b8da189cb465eb"),
+					},
+					{
+						Header: coin.BlockHeader{
+							Version:  1,
+							Time:     1538036623,
+							BkSeq:    10000000000,
+							Fee:      0,
+							PrevHash: cipher.MustSHA256FromHex("23dc4b68c0fc790989bb82f04b9d5174baab6f0f6808ed35be9b93cb73c69108"),
+							BodyHash: cipher.MustSHA256FromHex("2be4b0155c1ab9613007fe522e3b12bac4be79800a19bc8cd8ca343868caa583"),
+							UxHash:   cipher.MustSHA256FromHex("335b63b0f335c6aee5e7e1b3c62dd09bb6074e38b48e2469e294a019d5ae5aa1"),
+						},
+						Sig: cipher.MustSigFromHex("8cf145e9ef4a4a5254bc57798a7a61dfed238768f94edc5635175c6b91bccd8ec1555da603c5e31b018e135b82b1525be8a92973c468a74b5b40b8da189cb465eb"),
+					},
+				},
+			},
+		},
+		{
+			goldenFile: "filter-load-msg.golden",
+			obj:        &FilterLoadMessage{},
+			msg: &FilterLoadMessage{
+				Filter:    []byte{0x01, 0x00, 0x80, 0x20, 0xff, 0x04, 0x00, 0x10},
+				HashFuncs: 11,
+				Tweak:     3551190516,
+			},
+		},
+		{
+			goldenFile: "get-merkle-blocks-msg.golden",
+			obj:        &GetMerkleBlocksMessage{},
+			msg: &GetMerkleBlocksMessage{
+				LastBlock:       50000,
+				RequestedBlocks: 20,
+			},
+		},
+		{
+			goldenFile: "merkle-block-msg.golden",
+			obj:        &MerkleBlockMessage{},
+			msg: &MerkleBlockMessage{
+				Header: coin.BlockHeader{
+					Version:  1,
+					Time:     1538036613,
+					BkSeq:    9999999999,
+					Fee:      1234123412341234,
+					PrevHash: cipher.MustSHA256FromHex("59cb7d0e2ce8a03d1054afcc28a22fe864a8813460d241db38c59d10e7c29132"),
+					BodyHash: cipher.MustSHA256FromHex("6d421469409591f0c3112884c8cf10f8bca5d8ab87c9c30dea2ea73b6751bbf9"),
+					UxHash:   cipher.MustSHA256FromHex("6ea6a972cf06d25908b29953aeddb68c3b6f3a9903e8f964dc89b0abc0645dea"),
+				},
+				Sig:      cipher.MustSigFromHex("8cf145e9ef4a4a5254bc57798a7a61dfed238768f94edc5635175c6b91bccd8ec1555da603c5e31b018e135b82b1525be8a92973c468a74b5b40b8da189cb465eb"),
+				TxnCount: 5,
+				Matches: []MerkleMatch{
+					{
+						Index: 1,
+						Txid:  cipher.MustSHA256FromHex("23dc4b68c0fc790989bb82f04b9d5174baab6f0f6808ed35be9b93cb73c69108"),
+						Proof: []cipher.SHA256{
+							cipher.MustSHA256FromHex("2be4b0155c1ab9613007fe522e3b12bac4be79800a19bc8cd8ca343868caa583"),
+							cipher.MustSHA256FromHex("335b63b0f335c6aee5e7e1b3c62dd09bb6074e38b48e2469e294a019d5ae5aa1"),
+							cipher.MustSHA256FromHex("619a367f4e5dee741348366899237ddc920335fc847ccafdf2d32ed57bb7b385"),
+						},
+					},
+					{
+						Index: 4,
+						Txid:  cipher.MustSHA256FromHex("1773d8901df96bba4c6d65499e11e6ec73a9978c611d1463898ffbc2b49773fc"),
+						Proof: []cipher.SHA256{
+							cipher.MustSHA256FromHex("703f84ee0702b44fc89ce573a239d5fbf185bf5d4e7fc8f4930262bcda1e8fb0"),
+							cipher.MustSHA256FromHex("c9e904862da01f2d7676c12c4342dde36d9a9a9d25be5351e2b57fae6f426bb9"),
+							cipher.MustSHA256FromHex("766d6f6ed56599a91759c75466e3f09b9d6d5995b58dd5bbfba5af10b1a8cdea"),
+						},
+					},
+				},
+			},
+		},
+		{
+			goldenFile: "get-txn-proofs-msg.golden",
+			obj:        &GetTxnProofsMessage{},
+			msg: &GetTxnProofsMessage{
+				Txids: []cipher.SHA256{
+					cipher.MustSHA256FromHex("a9da3e4acb1892a000c1b658a64d4e420d0c381862928ab820fb3f3a534a9674"),
+					cipher.MustSHA256FromHex("2c7989f47524721bb2c7a7f967208c9b1c01829c9a55addf22d066e5c55ab3ac"),
+				},
+			},
+		},
 		{
 			goldenFile: "announce-txns-msg.golden",
 			obj:        &AnnounceTxnsMessage{},
@@ -912,6 +1052,62 @@ func TestTruncateGivePeersMessage(t *testing.T) {
 	require.True(t, n <= maxLen)
 }
 
+func TestTruncateGiveIPv6PeersMessage(t *testing.T) {
+	maxLen := uint64(1024)
+	m := &GiveIPv6PeersMessage{}
+
+	// Empty message, no truncation
+	prevLen := len(m.Peers)
+	truncateGiveIPv6PeersMessage(m, maxLen)
+	require.Equal(t, prevLen, len(m.Peers))
+
+	n := encodeSizeGiveIPv6PeersMessage(m)
+	require.True(t, n <= maxLen)
+
+	// One peer, no truncation
+	m.Peers = append(m.Peers, IPv6Addr{})
+	prevLen = len(m.Peers)
+	truncateGiveIPv6PeersMessage(m, maxLen)
+	require.Equal(t, prevLen, len(m.Peers))
+
+	n = encodeSizeGiveIPv6PeersMessage(m)
+	require.True(t, n <= maxLen)
+
+	// Too many peers, truncated
+	n = encodeSizeIPv6Addr(&IPv6Addr{})
+	m.Peers = make([]IPv6Addr, (maxLen/n)*2)
+	prevLen = len(m.Peers)
+	truncateGiveIPv6PeersMessage(m, maxLen)
+	require.True(t, len(m.Peers) < prevLen)
+	require.NotEmpty(t, m.Peers)
+
+	n = encodeSizeGiveIPv6PeersMessage(m)
+	require.True(t, n <= maxLen)
+}
+
+func TestNewGivePeersMessageIPv6(t *testing.T) {
+	peers := []pex.Peer{
+		{Addr: "11.22.33.44:6000"},
+		{Addr: "[2001:db8::1]:6000"},
+		{Addr: "55.66.77.88:7000"},
+		{Addr: "[2001:db8:85a3::8a2e:370:7334]:7000"},
+	}
+
+	// IPv4 peers are sent in a GivePeersMessage
+	m := NewGivePeersMessage(peers, 1024)
+	require.Equal(t, []string{"11.22.33.44:6000", "55.66.77.88:7000"}, m.GetPeers())
+
+	// IPv6 peers are sent in a GiveIPv6PeersMessage
+	m6 := NewGiveIPv6PeersMessage(peers, 1024)
+	require.Equal(t, []string{"[2001:db8::1]:6000", "[2001:db8:85a3::8a2e:370:7334]:7000"}, m6.GetPeers())
+
+	_, err := NewIPv6Addr("11.22.33.44:6000")
+	require.EqualError(t, err, "Ignoring IPv4 address")
+
+	_, err = NewIPAddr("[2001:db8::1]:6000")
+	require.EqualError(t, err, "Ignoring IPv6 address")
+}
+
 func TestTruncateGiveBlocksMessage(t *testing.T) {
 	maxLen := uint64(1024)
 	m := &GiveBlocksMessage{}
@@ -1086,6 +1282,553 @@ func TestGetBlocksMessageProcess(t *testing.T) {
 	d.AssertExpectations(t)
 }
 
+func TestGiveBlocksMessageProcess(t *testing.T) {
+	makeBlocks := func(seqs ...uint64) []coin.SignedBlock {
+		blocks := make([]coin.SignedBlock, len(seqs))
+		for i, seq := range seqs {
+			blocks[i] = coin.SignedBlock{
+				Block: coin.Block{
+					Head: coin.BlockHeader{
+						BkSeq: seq,
+					},
+				},
+			}
+		}
+		return blocks
+	}
+
+	pubkey, seckey := cipher.GenerateKeyPair()
+	signedBlocks := makeBlocks(7, 8)
+	for i := range signedBlocks {
+		signedBlocks[i].Sig = cipher.MustSignHash(signedBlocks[i].HashHeader(), seckey)
+	}
+
+	tt := []struct {
+		name      string
+		blocks    []coin.SignedBlock
+		batchSize int
+		setup     func(d *mockDaemoner)
+		headSeq   uint64
+	}{
+		{
+			name:      "known blocks skipped, executed in batches",
+			blocks:    makeBlocks(5, 6, 7, 8, 9),
+			batchSize: 2,
+			setup: func(d *mockDaemoner) {
+				d.On("executeSignedBlocks", makeBlocks(7, 8)).Return(nil)
+				d.On("executeSignedBlock", makeBlocks(9)[0]).Return(nil)
+			},
+			headSeq: 9,
+		},
+		{
+			name:      "failed batch executed individually",
+			blocks:    makeBlocks(7, 8, 9),
+			batchSize: 2,
+			setup: func(d *mockDaemoner) {
+				d.On("executeSignedBlocks", makeBlocks(7, 8)).Return(errors.New("invalid block 8"))
+				d.On("executeSignedBlock", makeBlocks(7)[0]).Return(nil)
+				d.On("executeSignedBlock", makeBlocks(8)[0]).Return(errors.New("invalid block 8"))
+				d.On("misbehave", "127.0.0.1:1234", misbehaviorInvalidBlock, mock.AnythingOfType("string"))
+			},
+			headSeq: 7,
+		},
+		{
+			name:      "failed block with valid signature not scored",
+			blocks:    signedBlocks,
+			batchSize: 1,
+			setup: func(d *mockDaemoner) {
+				d.On("executeSignedBlock", signedBlocks[0]).Return(nil)
+				d.On("executeSignedBlock", signedBlocks[1]).Return(errors.New("block 8 rejected"))
+			},
+			headSeq: 7,
+		},
+		{
+			name:      "batch size 1",
+			blocks:    makeBlocks(7, 8),
+			batchSize: 1,
+			setup: func(d *mockDaemoner) {
+				d.On("executeSignedBlock", makeBlocks(7)[0]).Return(nil)
+				d.On("executeSignedBlock", makeBlocks(8)[0]).Return(nil)
+			},
+			headSeq: 8,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &mockDaemoner{}
+
+			m := &GiveBlocksMessage{
+				Blocks: tc.blocks,
+				c: &gnet.MessageContext{
+					ConnID: 10,
+					Addr:   "127.0.0.1:1234",
+				},
+			}
+
+			d.On("DaemonConfig").Return(DaemonConfig{
+				ExecuteBlocksBatchSize: tc.batchSize,
+				GetBlocksRequestCount:  20,
+				BlockchainPubkey:       pubkey,
+			})
+			d.On("headBkSeq").Return(uint64(6), true, nil).Once()
+			d.On("headBkSeq").Return(tc.headSeq, true, nil).Once()
+			d.On("bufferBlocks", "127.0.0.1:1234", uint64(6), mock.Anything).Return(func(addr string, headSeq uint64, blocks []coin.SignedBlock) []coin.SignedBlock {
+				return blocks
+			})
+			tc.setup(d)
+			d.On("broadcastMessage", NewAnnounceBlocksMessage(tc.headSeq)).Return(nil, nil)
+			d.On("requestBlocksAfter", tc.headSeq).Return(nil)
+
+			m.process(d)
+
+			d.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCompactBlockMessageProcess(t *testing.T) {
+	txns := make(coin.Transactions, 3)
+	for i := range txns {
+		txns[i] = coin.Transaction{
+			In: []cipher.SHA256{testutil.RandSHA256(t)},
+		}
+	}
+
+	pubkey, seckey := cipher.GenerateKeyPair()
+	sb := coin.SignedBlock{
+		Block: coin.Block{
+			Head: coin.BlockHeader{
+				BkSeq: 7,
+			},
+			Body: coin.BlockBody{
+				Transactions: txns[:2],
+			},
+		},
+	}
+	sb.Head.BodyHash = sb.Body.Hash()
+	sb.Sig = cipher.MustSignHash(sb.HashHeader(), seckey)
+
+	tt := []struct {
+		name        string
+		block       coin.SignedBlock
+		unconfirmed coin.Transactions
+		setup       func(d *mockDaemoner)
+	}{
+		{
+			name:        "reconstructed from unconfirmed txns",
+			block:       sb,
+			unconfirmed: coin.Transactions{txns[2], txns[1], txns[0]},
+			setup: func(d *mockDaemoner) {
+				d.On("executeSignedBlock", sb).Return(nil)
+				d.On("broadcastMessage", NewAnnounceBlocksMessage(7)).Return(nil, nil)
+			},
+		},
+		{
+			name:        "missing txn, full block requested",
+			block:       sb,
+			unconfirmed: coin.Transactions{txns[0]},
+			setup: func(d *mockDaemoner) {
+				d.On("sendMessage", "127.0.0.1:1234", NewGetBlocksMessage(6, 20)).Return(nil)
+			},
+		},
+		{
+			name:  "block after the next block, blocks requested",
+			block: coin.SignedBlock{Block: coin.Block{Head: coin.BlockHeader{BkSeq: 8}}},
+			setup: func(d *mockDaemoner) {
+				d.On("sendMessage", "127.0.0.1:1234", NewGetBlocksMessage(6, 20)).Return(nil)
+			},
+		},
+		{
+			name:  "known block ignored",
+			block: coin.SignedBlock{Block: coin.Block{Head: coin.BlockHeader{BkSeq: 6}}},
+			setup: func(d *mockDaemoner) {},
+		},
+		{
+			name:        "block with an invalid signature",
+			block:       coin.SignedBlock{Block: sb.Block},
+			unconfirmed: txns,
+			setup: func(d *mockDaemoner) {
+				d.On("executeSignedBlock", coin.SignedBlock{Block: sb.Block}).Return(errors.New("invalid signature"))
+				d.On("misbehave", "127.0.0.1:1234", misbehaviorInvalidBlock, mock.AnythingOfType("string"))
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &mockDaemoner{}
+
+			m := NewCompactBlockMessage(tc.block)
+			m.c = &gnet.MessageContext{
+				ConnID: 10,
+				Addr:   "127.0.0.1:1234",
+			}
+
+			d.On("DaemonConfig").Return(DaemonConfig{
+				GetBlocksRequestCount: 20,
+				BlockchainPubkey:      pubkey,
+			})
+			d.On("headBkSeq").Return(uint64(6), true, nil)
+			if tc.unconfirmed != nil {
+				d.On("getAllUnconfirmed").Return(tc.unconfirmed, nil)
+			}
+			tc.setup(d)
+
+			m.process(d)
+
+			d.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCompactBlockMessageReconstruct(t *testing.T) {
+	txns := make(coin.Transactions, 2)
+	for i := range txns {
+		txns[i] = coin.Transaction{
+			In: []cipher.SHA256{testutil.RandSHA256(t)},
+		}
+	}
+
+	sb := coin.SignedBlock{
+		Block: coin.Block{
+			Body: coin.BlockBody{
+				Transactions: txns,
+			},
+		},
+	}
+	sb.Head.BodyHash = sb.Body.Hash()
+
+	m := NewCompactBlockMessage(sb)
+	require.Len(t, m.ShortIDs, 2)
+
+	b, ok := m.reconstruct(coin.Transactions{txns[1], txns[0]})
+	require.True(t, ok)
+	require.Equal(t, sb, b)
+
+	// A short ID collision in the unconfirmed txns is treated as a missing txn
+	_, ok = m.reconstruct(coin.Transactions{txns[1], txns[0], txns[0]})
+	require.False(t, ok)
+
+	// Transactions which do not match the body hash are rejected
+	m.Header.BodyHash = testutil.RandSHA256(t)
+	_, ok = m.reconstruct(txns)
+	require.False(t, ok)
+}
+
+func TestGetHeadersMessageProcess(t *testing.T) {
+	d := &mockDaemoner{}
+
+	m := &GetHeadersMessage{
+		LastBlock:  7,
+		LastHeader: 9,
+		// request more headers than MaxGetHeadersResponseCount to verify capping
+		RequestedHeaders: 5000,
+		c: &gnet.MessageContext{
+			ConnID: 10,
+			Addr:   "127.0.0.1:1234",
+		},
+	}
+
+	config := DaemonConfig{
+		DisableNetworking:          false,
+		MaxGetHeadersResponseCount: 1000,
+		MaxOutgoingMessageLength:   1024,
+	}
+
+	// Have getSignedBlocksSince return a lot of blocks to verify truncation
+	blocks := make([]coin.SignedBlock, 1000)
+	for i := range blocks {
+		blocks[i].Head.BkSeq = uint64(i) + 10
+	}
+
+	ghm := NewGiveHeadersMessage(makeSignedBlockHeaders(blocks), config.MaxOutgoingMessageLength)
+	require.True(t, len(ghm.Headers) < len(blocks), "headers should be truncated")
+	require.NotEmpty(t, ghm.Headers)
+	require.True(t, ghm.EncodeSize()+4 <= config.MaxOutgoingMessageLength)
+
+	d.On("DaemonConfig").Return(config)
+	d.On("recordPeerHeight", "127.0.0.1:1234", uint64(10), uint64(7)).Return()
+	d.On("getSignedBlocksSince", uint64(9), uint64(1000)).Return(blocks, nil)
+	d.On("sendMessage", "127.0.0.1:1234", ghm).Return(nil)
+
+	m.process(d)
+
+	d.AssertExpectations(t)
+}
+
+func TestGiveHeadersMessageProcess(t *testing.T) {
+	headers := []SignedBlockHeader{
+		{Header: coin.BlockHeader{BkSeq: 7}},
+		{Header: coin.BlockHeader{BkSeq: 8}},
+	}
+
+	tt := []struct {
+		name  string
+		setup func(d *mockDaemoner)
+	}{
+		{
+			name: "headers added, more headers requested",
+			setup: func(d *mockDaemoner) {
+				d.On("addHeaders", headers).Return(2, nil)
+				d.On("headBkSeq").Return(uint64(6), true, nil)
+				d.On("sendMessage", "127.0.0.1:1234", NewGetHeadersMessage(6, 8, 1000)).Return(nil)
+				d.On("requestBlockBodies").Return(nil)
+			},
+		},
+		{
+			name: "known headers",
+			setup: func(d *mockDaemoner) {
+				d.On("addHeaders", headers).Return(0, nil)
+				d.On("requestBlockBodies").Return(nil)
+			},
+		},
+		{
+			name: "headers not connected",
+			setup: func(d *mockDaemoner) {
+				d.On("addHeaders", headers).Return(0, ErrHeaderNotConnected)
+				d.On("requestBlockBodies").Return(nil)
+			},
+		},
+		{
+			name: "header with an invalid signature",
+			setup: func(d *mockDaemoner) {
+				d.On("addHeaders", headers).Return(1, ErrHeaderInvalidSignature)
+				d.On("misbehave", "127.0.0.1:1234", misbehaviorInvalidBlock, ErrHeaderInvalidSignature.Error())
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &mockDaemoner{}
+
+			m := &GiveHeadersMessage{
+				Headers: headers,
+				c: &gnet.MessageContext{
+					ConnID: 10,
+					Addr:   "127.0.0.1:1234",
+				},
+			}
+
+			d.On("DaemonConfig").Return(DaemonConfig{
+				HeadersRequestCount: 1000,
+			})
+			tc.setup(d)
+
+			m.process(d)
+
+			d.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFilterLoadMessageProcess(t *testing.T) {
+	tt := []struct {
+		name   string
+		config DaemonConfig
+		msg    FilterLoadMessage
+		setup  func(d *mockDaemoner)
+	}{
+		{
+			name: "filter loaded",
+			config: DaemonConfig{
+				EnableSPVServer: true,
+			},
+			msg: FilterLoadMessage{
+				Filter:    []byte{0x01, 0x02},
+				HashFuncs: 3,
+				Tweak:     4,
+			},
+			setup: func(d *mockDaemoner) {
+				d.On("setBloomFilter", "127.0.0.1:1234", &BloomFilter{
+					Bits:      []byte{0x01, 0x02},
+					HashFuncs: 3,
+					Tweak:     4,
+				})
+			},
+		},
+		{
+			name: "too many hash functions",
+			config: DaemonConfig{
+				EnableSPVServer: true,
+			},
+			msg: FilterLoadMessage{
+				Filter:    []byte{0x01, 0x02},
+				HashFuncs: MaxBloomFilterHashFuncs + 1,
+			},
+			setup: func(d *mockDaemoner) {
+				d.On("misbehave", "127.0.0.1:1234", misbehaviorMalformedMessage, ErrBloomFilterTooManyHashFuncs.Error())
+			},
+		},
+		{
+			name: "empty filter",
+			config: DaemonConfig{
+				EnableSPVServer: true,
+			},
+			msg: FilterLoadMessage{
+				HashFuncs: 3,
+			},
+			setup: func(d *mockDaemoner) {
+				d.On("misbehave", "127.0.0.1:1234", misbehaviorMalformedMessage, ErrBloomFilterEmpty.Error())
+			},
+		},
+		{
+			name: "spv server disabled",
+			msg: FilterLoadMessage{
+				Filter:    []byte{0x01, 0x02},
+				HashFuncs: 3,
+			},
+			setup: func(d *mockDaemoner) {},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &mockDaemoner{}
+
+			m := tc.msg
+			m.c = &gnet.MessageContext{
+				ConnID: 10,
+				Addr:   "127.0.0.1:1234",
+			}
+
+			d.On("DaemonConfig").Return(tc.config)
+			tc.setup(d)
+
+			m.process(d)
+
+			d.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetMerkleBlocksMessageProcess(t *testing.T) {
+	d := &mockDaemoner{}
+
+	m := &GetMerkleBlocksMessage{
+		LastBlock: 7,
+		// request more blocks than MaxGetBlocksResponseCount to verify capping
+		RequestedBlocks: 500,
+		c: &gnet.MessageContext{
+			ConnID: 10,
+			Addr:   "127.0.0.1:1234",
+		},
+	}
+
+	blocks := make([]coin.SignedBlock, 3)
+	for i := range blocks {
+		blocks[i].Head.BkSeq = uint64(i) + 8
+	}
+
+	d.On("DaemonConfig").Return(DaemonConfig{
+		EnableSPVServer:           true,
+		MaxGetBlocksResponseCount: 20,
+	})
+	d.On("getSignedBlocksSince", uint64(7), uint64(20)).Return(blocks, nil)
+	for _, b := range blocks {
+		d.On("sendMerkleBlock", "127.0.0.1:1234", b).Return(nil)
+	}
+
+	m.process(d)
+
+	d.AssertExpectations(t)
+
+	// No more blocks are sent when the peer has not loaded a bloom filter
+	d = &mockDaemoner{}
+	d.On("DaemonConfig").Return(DaemonConfig{
+		EnableSPVServer:           true,
+		MaxGetBlocksResponseCount: 20,
+	})
+	d.On("getSignedBlocksSince", uint64(7), uint64(20)).Return(blocks, nil)
+	d.On("sendMerkleBlock", "127.0.0.1:1234", blocks[0]).Return(ErrBloomFilterNotLoaded)
+
+	m.process(d)
+
+	d.AssertExpectations(t)
+	d.AssertNumberOfCalls(t, "sendMerkleBlock", 1)
+}
+
+func TestGetTxnProofsMessageProcess(t *testing.T) {
+	d := &mockDaemoner{}
+
+	txids := []cipher.SHA256{
+		testutil.RandSHA256(t),
+		testutil.RandSHA256(t),
+	}
+
+	m := &GetTxnProofsMessage{
+		Txids: txids,
+		c: &gnet.MessageContext{
+			ConnID: 10,
+			Addr:   "127.0.0.1:1234",
+		},
+	}
+
+	proof := blockdb.TxnProof{
+		Header: coin.BlockHeader{
+			BkSeq:    3,
+			BodyHash: testutil.RandSHA256(t),
+		},
+		Txid:  txids[1],
+		Index: 2,
+		Hashes: []cipher.SHA256{
+			testutil.RandSHA256(t),
+			testutil.RandSHA256(t),
+		},
+	}
+
+	d.On("DaemonConfig").Return(DaemonConfig{
+		EnableSPVServer: true,
+	})
+	d.On("getTxnProofs", txids).Return([]blockdb.TxnProof{proof}, nil)
+	d.On("sendMessage", "127.0.0.1:1234", &MerkleBlockMessage{
+		Header: proof.Header,
+		Matches: []MerkleMatch{
+			{
+				Index: 2,
+				Txid:  txids[1],
+				Proof: proof.Hashes,
+			},
+		},
+	}).Return(nil)
+
+	m.process(d)
+
+	d.AssertExpectations(t)
+}
+
+func TestMerkleBlockMessageVerify(t *testing.T) {
+	pubkey, seckey := cipher.GenerateKeyPair()
+	blocks := makeHeaderChain(t, seckey, 0, testutil.RandSHA256(t), 1)
+	b := blocks[0]
+
+	for i := 0; i < 4; i++ {
+		b.Body.Transactions = append(b.Body.Transactions, coin.Transaction{
+			In: []cipher.SHA256{testutil.RandSHA256(t)},
+		})
+	}
+	b.Head.BodyHash = b.Body.Hash()
+	b.Sig = cipher.MustSignHash(b.HashHeader(), seckey)
+
+	m := NewMerkleBlockMessage(b, []int{1, 4})
+	require.Equal(t, uint32(5), m.TxnCount)
+	require.Len(t, m.Matches, 2)
+	require.Equal(t, b.Body.Transactions[4].Hash(), m.Matches[1].Txid)
+	require.NoError(t, m.Verify(pubkey))
+
+	// A block without matches only proves the header
+	require.NoError(t, NewMerkleBlockMessage(b, nil).Verify(pubkey))
+
+	// The proofs must match the body hash
+	m.Matches[0].Txid = testutil.RandSHA256(t)
+	require.Error(t, m.Verify(pubkey))
+
+	// The header must be signed by the blockchain pubkey
+	otherPubkey, _ := cipher.GenerateKeyPair()
+	require.Error(t, NewMerkleBlockMessage(b, []int{1}).Verify(otherPubkey))
+}
+
 func setupMsgEncoding() {
 	gnet.EraseMessages()
 	var messagesConfig = NewMessagesConfig()
@@ -2,6 +2,7 @@
 
 package daemon
 
+import blockdb "github.com/SkycoinProject/cx-chains/src/visor/blockdb"
 import cipher "github.com/SkycoinProject/cx-chains/src/cipher"
 import coin "github.com/SkycoinProject/cx-chains/src/coin"
 import gnet "github.com/SkycoinProject/cx-chains/src/daemon/gnet"
@@ -42,6 +43,27 @@ func (_m *mockDaemoner) Disconnect(addr string, r gnet.DisconnectReason) error {
 	return r0
 }
 
+// addHeaders provides a mock function with given fields: headers
+func (_m *mockDaemoner) addHeaders(headers []SignedBlockHeader) (int, error) {
+	ret := _m.Called(headers)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func([]SignedBlockHeader) int); ok {
+		r0 = rf(headers)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]SignedBlockHeader) error); ok {
+		r1 = rf(headers)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // addPeers provides a mock function with given fields: addrs
 func (_m *mockDaemoner) addPeers(addrs []string) int {
 	ret := _m.Called(addrs)
@@ -93,6 +115,27 @@ func (_m *mockDaemoner) broadcastMessage(msg gnet.Message) ([]uint64, error) {
 	return r0, r1
 }
 
+// bufferBlocks provides a mock function with given fields: addr, headSeq, blocks
+func (_m *mockDaemoner) bufferBlocks(addr string, headSeq uint64, blocks []coin.SignedBlock) []coin.SignedBlock {
+	ret := _m.Called(addr, headSeq, blocks)
+
+	var r0 []coin.SignedBlock
+	if rf, ok := ret.Get(0).(func(string, uint64, []coin.SignedBlock) []coin.SignedBlock); ok {
+		r0 = rf(addr, headSeq, blocks)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]coin.SignedBlock)
+		}
+	}
+
+	return r0
+}
+
+// clearBloomFilter provides a mock function with given fields: addr
+func (_m *mockDaemoner) clearBloomFilter(addr string) {
+	_m.Called(addr)
+}
+
 // connectionIntroduced provides a mock function with given fields: addr, gnetID, m
 func (_m *mockDaemoner) connectionIntroduced(addr string, gnetID uint64, m *IntroductionMessage) (*connection, error) {
 	ret := _m.Called(addr, gnetID, m)
@@ -144,6 +187,20 @@ func (_m *mockDaemoner) executeSignedBlock(b coin.SignedBlock) error {
 	return r0
 }
 
+// executeSignedBlocks provides a mock function with given fields: blocks
+func (_m *mockDaemoner) executeSignedBlocks(blocks []coin.SignedBlock) error {
+	ret := _m.Called(blocks)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]coin.SignedBlock) error); ok {
+		r0 = rf(blocks)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // filterKnownUnconfirmed provides a mock function with given fields: txns
 func (_m *mockDaemoner) filterKnownUnconfirmed(txns []cipher.SHA256) ([]cipher.SHA256, error) {
 	ret := _m.Called(txns)
@@ -167,6 +224,29 @@ func (_m *mockDaemoner) filterKnownUnconfirmed(txns []cipher.SHA256) ([]cipher.S
 	return r0, r1
 }
 
+// getAllUnconfirmed provides a mock function with given fields:
+func (_m *mockDaemoner) getAllUnconfirmed() (coin.Transactions, error) {
+	ret := _m.Called()
+
+	var r0 coin.Transactions
+	if rf, ok := ret.Get(0).(func() coin.Transactions); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(coin.Transactions)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // getKnownUnconfirmed provides a mock function with given fields: txns
 func (_m *mockDaemoner) getKnownUnconfirmed(txns []cipher.SHA256) (coin.Transactions, error) {
 	ret := _m.Called(txns)
@@ -213,6 +293,29 @@ func (_m *mockDaemoner) getSignedBlocksSince(seq uint64, count uint64) ([]coin.S
 	return r0, r1
 }
 
+// getTxnProofs provides a mock function with given fields: txids
+func (_m *mockDaemoner) getTxnProofs(txids []cipher.SHA256) ([]blockdb.TxnProof, error) {
+	ret := _m.Called(txids)
+
+	var r0 []blockdb.TxnProof
+	if rf, ok := ret.Get(0).(func([]cipher.SHA256) []blockdb.TxnProof); ok {
+		r0 = rf(txids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]blockdb.TxnProof)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]cipher.SHA256) error); ok {
+		r1 = rf(txids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // headBkSeq provides a mock function with given fields:
 func (_m *mockDaemoner) headBkSeq() (uint64, bool, error) {
 	ret := _m.Called()
@@ -271,6 +374,11 @@ func (_m *mockDaemoner) injectTransaction(txn coin.Transaction) (bool, *visor.Er
 	return r0, r1, r2
 }
 
+// misbehave provides a mock function with given fields: addr, score, reason
+func (_m *mockDaemoner) misbehave(addr string, score int, reason string) {
+	_m.Called(addr, score, reason)
+}
+
 // pexConfig provides a mock function with given fields:
 func (_m *mockDaemoner) pexConfig() pex.Config {
 	ret := _m.Called()
@@ -304,6 +412,34 @@ func (_m *mockDaemoner) recordPeerHeight(addr string, gnetID uint64, height uint
 	_m.Called(addr, gnetID, height)
 }
 
+// requestBlockBodies provides a mock function with given fields:
+func (_m *mockDaemoner) requestBlockBodies() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// requestBlocksAfter provides a mock function with given fields: headSeq
+func (_m *mockDaemoner) requestBlocksAfter(headSeq uint64) error {
+	ret := _m.Called(headSeq)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint64) error); ok {
+		r0 = rf(headSeq)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // requestBlocksFromAddr provides a mock function with given fields: addr
 func (_m *mockDaemoner) requestBlocksFromAddr(addr string) error {
 	ret := _m.Called(addr)
@@ -318,6 +454,20 @@ func (_m *mockDaemoner) requestBlocksFromAddr(addr string) error {
 	return r0
 }
 
+// sendMerkleBlock provides a mock function with given fields: addr, b
+func (_m *mockDaemoner) sendMerkleBlock(addr string, b coin.SignedBlock) error {
+	ret := _m.Called(addr, b)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, coin.SignedBlock) error); ok {
+		r0 = rf(addr, b)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // sendMessage provides a mock function with given fields: addr, msg
 func (_m *mockDaemoner) sendMessage(addr string, msg gnet.Message) error {
 	ret := _m.Called(addr, msg)
@@ -345,3 +495,8 @@ func (_m *mockDaemoner) sendRandomPeers(addr string) error {
 
 	return r0
 }
+
+// setBloomFilter provides a mock function with given fields: addr, f
+func (_m *mockDaemoner) setBloomFilter(addr string, f *BloomFilter) {
+	_m.Called(addr, f)
+}
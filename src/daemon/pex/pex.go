@@ -21,6 +21,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/SkycoinProject/cx-chains/src/util/logging"
+	"github.com/SkycoinProject/cx-chains/src/util/socks5"
 	"github.com/SkycoinProject/cx-chains/src/util/useragent"
 )
 
@@ -40,6 +41,8 @@ const (
 	oldPeerCacheFilename = "peers.txt"
 	// MaxPeerRetryTimes is the maximum number of times to retry a peer
 	MaxPeerRetryTimes = 10
+	// DefaultDNSSeedPort is the default port of the peers resolved from a DNS seed without a port
+	DefaultDNSSeedPort = 6000
 )
 
 var (
@@ -61,19 +64,23 @@ var (
 	logger = logging.MustGetLogger("pex")
 	// Default rng
 	rnum = rand.New(rand.NewSource(time.Now().Unix()))
+	// Resolves DNS seed hostnames, replaced in tests
+	lookupHost = net.LookupHost
 	// For removing inadvertent whitespace from addresses
 	whitespaceFilter = regexp.MustCompile(`\s`)
 )
 
-// validateAddress returns a sanitized address if valid, otherwise an error
+// validateAddress returns a sanitized address if valid, otherwise an error.
+// IPv6 addresses are of the form [ip]:port. The returned address has the canonical form of the IP address,
+// which is the form of the remote address of a connection to the peer.
 func validateAddress(ipPort string, allowLocalhost bool) (string, error) {
 	ipPort = whitespaceFilter.ReplaceAllString(ipPort, "")
-	pts := strings.Split(ipPort, ":")
-	if len(pts) != 2 {
+	host, portStr, err := net.SplitHostPort(ipPort)
+	if err != nil {
 		return "", ErrInvalidAddress
 	}
 
-	ip := net.ParseIP(pts[0])
+	ip := net.ParseIP(host)
 	if ip == nil {
 		return "", ErrInvalidAddress
 	} else if ip.IsLoopback() {
@@ -84,7 +91,7 @@ func validateAddress(ipPort string, allowLocalhost bool) (string, error) {
 		return "", ErrNotExternalIP
 	}
 
-	port, err := strconv.ParseUint(pts[1], 10, 16)
+	port, err := strconv.ParseUint(portStr, 10, 16)
 	if err != nil {
 		return "", ErrInvalidAddress
 	}
@@ -93,12 +100,12 @@ func validateAddress(ipPort string, allowLocalhost bool) (string, error) {
 		return "", ErrPortTooLow
 	}
 
-	return ipPort, nil
+	return net.JoinHostPort(ip.String(), strconv.FormatUint(port, 10)), nil
 }
 
 // Peer represents a known peer
 type Peer struct {
-	Addr            string         // An address of the form ip:port
+	Addr            string         // An address of the form ip:port, or [ip]:port for IPv6
 	LastSeen        int64          // Unix timestamp when this peer was last seen
 	Private         bool           // Whether it should omitted from public requests
 	Trusted         bool           // Whether this peer is trusted
@@ -184,6 +191,13 @@ type Config struct {
 	DownloadPeerList bool
 	// Download peers list from this URL
 	PeerListURL string
+	// Hostnames resolved to the IP addresses of initial peers, as host or host:port
+	DNSSeeds []string
+	// Port of the peers resolved from a DNS seed without a port
+	DNSSeedPort int
+	// Address of a SOCKS5 proxy that the peers list is downloaded through, as host:port.
+	// DNS seeds are not resolved when a proxy is used, since the DNS requests would not go through the proxy
+	Proxy string
 	// Set all peers as untrusted (even if loaded from DefaultConnections)
 	DisableTrustedPeers bool
 	// Load peers from this file on disk. NOTE: this is different from the peers file cache in the data directory
@@ -208,6 +222,7 @@ func NewConfig() Config {
 		NetworkDisabled:     false,
 		DownloadPeerList:    false,
 		PeerListURL:         DefaultPeerListURL,
+		DNSSeedPort:         DefaultDNSSeedPort,
 		DisableTrustedPeers: false,
 		CustomPeersFile:     "",
 	}
@@ -269,13 +284,9 @@ func New(cfg Config) (*Pex, error) {
 		return nil, err
 	}
 
-	// Download peers from remote peers list if networking is enabled
-	if pex.Config.DownloadPeerList && !pex.Config.NetworkDisabled {
-		go func() {
-			if err := pex.downloadPeers(); err != nil {
-				logger.WithError(err).Error("Failed to download peers list")
-			}
-		}()
+	// Resolve peers from the DNS seeds, or download peers from remote peers list, if networking is enabled
+	if (len(pex.Config.DNSSeeds) > 0 || pex.Config.DownloadPeerList) && !pex.Config.NetworkDisabled {
+		go pex.bootstrap()
 	}
 
 	return pex, nil
@@ -322,8 +333,94 @@ func (px *Pex) Shutdown() {
 	<-px.done
 }
 
+// bootstrap adds the peers resolved from the DNS seeds.
+// If no peers could be resolved from the DNS seeds, it falls back to downloading the remote peers list, if enabled.
+// The hardcoded default peers are always loaded by New.
+func (px *Pex) bootstrap() {
+	if len(px.Config.DNSSeeds) > 0 && px.Config.Proxy != "" {
+		logger.Info("Not resolving DNS seeds through a proxy")
+	} else if len(px.Config.DNSSeeds) > 0 {
+		err := px.seedFromDNS()
+		if err == nil {
+			return
+		}
+
+		logger.WithError(err).Error("Failed to resolve peers from DNS seeds")
+		if px.Config.DownloadPeerList {
+			logger.Info("Falling back to downloading the peers list")
+		}
+	}
+
+	if px.Config.DownloadPeerList {
+		if err := px.downloadPeers(); err != nil {
+			logger.WithError(err).Error("Failed to download peers list")
+		}
+	}
+}
+
+// seedFromDNS resolves the DNS seeds and adds the resolved peers.
+// A seed that fails to resolve is skipped. Returns an error if no peers could be resolved from any seed.
+func (px *Pex) seedFromDNS() error {
+	var peers []string
+	for _, seed := range px.Config.DNSSeeds {
+		addrs, err := resolveDNSSeed(seed, px.Config.DNSSeedPort)
+		if err != nil {
+			logger.WithError(err).WithField("seed", seed).Error("Failed to resolve DNS seed")
+			continue
+		}
+
+		logger.WithField("seed", seed).Infof("Resolved %d peers from DNS seed", len(addrs))
+		peers = append(peers, addrs...)
+	}
+
+	if len(peers) == 0 {
+		return errors.New("No peers resolved from DNS seeds")
+	}
+
+	n := px.AddPeers(peers)
+	logger.Infof("Added %d/%d peers from DNS seeds", n, len(peers))
+
+	return nil
+}
+
+// resolveDNSSeed resolves a DNS seed of the form host or host:port to a list of ip:port addresses.
+// If the seed has no port, defaultPort is used. IPv6 addresses are returned in the [ip]:port form.
+func resolveDNSSeed(seed string, defaultPort int) ([]string, error) {
+	seed = whitespaceFilter.ReplaceAllString(seed, "")
+
+	host := seed
+	port := strconv.Itoa(defaultPort)
+	if strings.Contains(seed, ":") {
+		var err error
+		host, port, err = net.SplitHostPort(seed)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if host == "" {
+		return nil, ErrInvalidAddress
+	}
+
+	ips, err := lookupHost(host)
+	if err != nil {
+		return nil, err
+	}
+
+	var addrs []string
+	for _, ip := range ips {
+		a := net.ParseIP(ip)
+		if a == nil {
+			continue
+		}
+		addrs = append(addrs, net.JoinHostPort(a.String(), port))
+	}
+
+	return addrs, nil
+}
+
 func (px *Pex) downloadPeers() error {
-	body, err := backoffDownloadText(px.Config.PeerListURL)
+	body, err := backoffDownloadText(px.httpClient(), px.Config.PeerListURL)
 	if err != nil {
 		logger.WithError(err).WithField("url", px.Config.PeerListURL).Error("Failed to download peers")
 		return err
@@ -651,11 +748,28 @@ func (px *Pex) isFull() bool {
 	return px.Config.Max > 0 && px.peerlist.len() >= px.Config.Max
 }
 
+// httpClient returns the HTTP client of the peers list download, which connects through the proxy if configured
+func (px *Pex) httpClient() *http.Client {
+	if px.Config.Proxy == "" {
+		return http.DefaultClient
+	}
+
+	d := socks5.Dialer{
+		ProxyAddr: px.Config.Proxy,
+		Timeout:   time.Second * 30,
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: d.DialContext,
+		},
+	}
+}
+
 // downloadText downloads a text format file from url.
 // Returns the raw response body as a string.
 // TODO -- move to util, add backoff options
-func downloadText(url string) (string, error) {
-	resp, err := http.Get(url)
+func downloadText(c *http.Client, url string) (string, error) {
+	resp, err := c.Get(url)
 	if err != nil {
 		return "", err
 	}
@@ -669,7 +783,7 @@ func downloadText(url string) (string, error) {
 	return string(body), nil
 }
 
-func backoffDownloadText(url string) (string, error) {
+func backoffDownloadText(c *http.Client, url string) (string, error) {
 	var body string
 
 	b := backoff.NewExponentialBackOff()
@@ -681,7 +795,7 @@ func backoffDownloadText(url string) (string, error) {
 	operation := func() error {
 		logger.WithField("url", url).Info("Trying to download peers list")
 		var err error
-		body, err = downloadText(url)
+		body, err = downloadText(c, url)
 		return err
 	}
 
@@ -1,8 +1,12 @@
 package pex
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
@@ -140,6 +144,44 @@ func TestValidateAddress(t *testing.T) {
 			allowLocalhost: false,
 			cleanAddr:      "11.22.33.44:8080",
 		},
+		{
+			addr:           "[2001:db8::1]:6000",
+			allowLocalhost: false,
+		},
+		{
+			addr:           "[2001:0db8:0000:0000:0000:0000:0000:0001]:6000",
+			allowLocalhost: false,
+			cleanAddr:      "[2001:db8::1]:6000",
+		},
+		{
+			addr:           "2001:db8::1:6000",
+			allowLocalhost: false,
+			err:            ErrInvalidAddress,
+		},
+		{
+			addr:           "[2001:db8::1]:1000",
+			allowLocalhost: false,
+			err:            ErrPortTooLow,
+		},
+		{
+			addr:           "[::1]:6000",
+			allowLocalhost: true,
+		},
+		{
+			addr:           "[::1]:6000",
+			allowLocalhost: false,
+			err:            ErrNoLocalhost,
+		},
+		{
+			addr:           "[fe80::1]:6000",
+			allowLocalhost: false,
+			err:            ErrNotExternalIP,
+		},
+		{
+			addr:           "[::ffff:11.22.33.44]:6000",
+			allowLocalhost: false,
+			cleanAddr:      "11.22.33.44:6000",
+		},
 	}
 
 	for _, tc := range cases {
@@ -1578,3 +1620,139 @@ func TestParseLocalPeerList(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveDNSSeed(t *testing.T) {
+	defer func() {
+		lookupHost = net.LookupHost
+	}()
+
+	lookupHost = func(host string) ([]string, error) {
+		switch host {
+		case "seed.example.com":
+			return []string{"11.22.33.44", "2001:db8::1", "55.66.77.88"}, nil
+		default:
+			return nil, errors.New("no such host")
+		}
+	}
+
+	cases := []struct {
+		name  string
+		seed  string
+		addrs []string
+		err   string
+	}{
+		{
+			name:  "default port",
+			seed:  "seed.example.com",
+			addrs: []string{"11.22.33.44:6000", "[2001:db8::1]:6000", "55.66.77.88:6000"},
+		},
+		{
+			name:  "custom port",
+			seed:  " seed.example.com:7000 ",
+			addrs: []string{"11.22.33.44:7000", "[2001:db8::1]:7000", "55.66.77.88:7000"},
+		},
+		{
+			name: "missing host",
+			seed: ":7000",
+			err:  ErrInvalidAddress.Error(),
+		},
+		{
+			name: "invalid seed",
+			seed: "seed.example.com:7000:8000",
+			err:  "address seed.example.com:7000:8000: too many colons in address",
+		},
+		{
+			name: "lookup fails",
+			seed: "unknown.example.com",
+			err:  "no such host",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			addrs, err := resolveDNSSeed(tc.seed, DefaultDNSSeedPort)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.addrs, addrs)
+		})
+	}
+}
+
+func TestPexBootstrap(t *testing.T) {
+	defer func() {
+		lookupHost = net.LookupHost
+	}()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "99.88.77.66:6000")
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		name             string
+		dnsSeeds         []string
+		downloadPeerList bool
+		proxy            string
+		peers            []string
+	}{
+		{
+			name:             "dns seeds resolved",
+			dnsSeeds:         []string{"seed.example.com", "unknown.example.com"},
+			downloadPeerList: true,
+			peers:            []string{"11.22.33.44:6000"},
+		},
+		{
+			name:             "dns seeds fail, falls back to peers list",
+			dnsSeeds:         []string{"unknown.example.com"},
+			downloadPeerList: true,
+			peers:            []string{"99.88.77.66:6000"},
+		},
+		{
+			name:     "dns seeds fail, peers list disabled",
+			dnsSeeds: []string{"unknown.example.com"},
+		},
+		{
+			name:             "no dns seeds",
+			downloadPeerList: true,
+			peers:            []string{"99.88.77.66:6000"},
+		},
+		{
+			name:     "dns seeds not resolved through a proxy",
+			dnsSeeds: []string{"seed.example.com"},
+			proxy:    "127.0.0.1:9050",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			lookupHost = func(host string) ([]string, error) {
+				if host == "seed.example.com" {
+					return []string{"11.22.33.44"}, nil
+				}
+				return nil, errors.New("no such host")
+			}
+
+			config := NewConfig()
+			config.DNSSeeds = tc.dnsSeeds
+			config.DownloadPeerList = tc.downloadPeerList
+			config.PeerListURL = server.URL
+			config.Proxy = tc.proxy
+
+			pex := &Pex{
+				Config:   config,
+				peerlist: newPeerlist(),
+			}
+
+			pex.bootstrap()
+
+			require.Equal(t, len(tc.peers), pex.peerlist.len())
+			for _, p := range tc.peers {
+				require.True(t, pex.peerlist.hasPeer(p))
+			}
+		})
+	}
+}
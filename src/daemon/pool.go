@@ -28,6 +28,8 @@ type PoolConfig struct {
 	MaxOutgoingConnections int
 	// Maximum number of outgoing connections to peers in the DefaultConnections list to maintain
 	MaxDefaultPeerOutgoingConnections int
+	// Maximum number of incoming connections accepted before the daemon applies its own limits
+	MaxIncomingConnections int
 	// Default "trusted" peers
 	DefaultConnections []string
 	// Maximum length of incoming messages in bytes
@@ -35,8 +37,10 @@ type PoolConfig struct {
 	// Maximum length of outgoing messages in bytes
 	MaxOutgoingMessageLength int
 	// These should be assigned by the controlling daemon
-	address string
-	port    int
+	address       string
+	port          int
+	listenNetwork string
+	proxy         string
 }
 
 // NewPoolConfig creates pool config
@@ -44,6 +48,7 @@ func NewPoolConfig() PoolConfig {
 	return PoolConfig{
 		port:                              6677,
 		address:                           "",
+		listenNetwork:                     "tcp",
 		DialTimeout:                       time.Second * 30,
 		MessageHandlingRate:               time.Millisecond * 50,
 		PingRate:                          5 * time.Second,
@@ -54,6 +59,7 @@ func NewPoolConfig() PoolConfig {
 		MaxConnections:                    128,
 		MaxOutgoingConnections:            8,
 		MaxDefaultPeerOutgoingConnections: 1,
+		MaxIncomingConnections:            119,
 		MaxOutgoingMessageLength:          256 * 1024,
 		MaxIncomingMessageLength:          1024 * 1024,
 	}
@@ -71,12 +77,15 @@ func NewPool(cfg PoolConfig, d *Daemon) (*Pool, error) {
 	gnetCfg.DialTimeout = cfg.DialTimeout
 	gnetCfg.Port = uint16(cfg.port)
 	gnetCfg.Address = cfg.address
+	gnetCfg.ListenNetwork = cfg.listenNetwork
+	gnetCfg.Proxy = cfg.proxy
 	gnetCfg.ConnectCallback = d.onGnetConnect
 	gnetCfg.DisconnectCallback = d.onGnetDisconnect
 	gnetCfg.ConnectFailureCallback = d.onGnetConnectFailure
 	gnetCfg.MaxConnections = cfg.MaxConnections
 	gnetCfg.MaxOutgoingConnections = cfg.MaxOutgoingConnections
 	gnetCfg.MaxDefaultPeerOutgoingConnections = cfg.MaxDefaultPeerOutgoingConnections
+	gnetCfg.MaxIncomingConnections = cfg.MaxIncomingConnections
 	gnetCfg.DefaultConnections = cfg.DefaultConnections
 	gnetCfg.MaxIncomingMessageLength = cfg.MaxIncomingMessageLength
 	gnetCfg.MaxOutgoingMessageLength = cfg.MaxOutgoingMessageLength
@@ -73,7 +73,7 @@ func encodeSizeSignedBlock(obj *coin.SignedBlock) uint64 {
 
 		// x.Out
 		i1 += 4
-		{
+		for _, x := range x.Out {
 			i2 := uint64(0)
 
 			// x.Address.Version
@@ -89,11 +89,9 @@ func encodeSizeSignedBlock(obj *coin.SignedBlock) uint64 {
 			i2 += 8
 
 			// x.ProgramState
-			// WARNING: x.Out[0].ProgramState manually changed from x.ProgramState
-			// WARNING: This is not considering program states in different `Out`s with different lengths
-			i2 += 4 + uint64(len(x.Out[0].ProgramState))
+			i2 += 4 + uint64(len(x.ProgramState))
 
-			i1 += uint64(len(x.Out)) * i2
+			i1 += i2
 		}
 
 		// x.MainExpressions
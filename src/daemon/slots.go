@@ -0,0 +1,165 @@
+package daemon
+
+import (
+	"sort"
+
+	"github.com/SkycoinProject/cx-chains/src/daemon/pex"
+	"github.com/SkycoinProject/cx-chains/src/util/iputil"
+)
+
+// ConnectionSlots are the connection limits of the daemon and the number of connections using them
+type ConnectionSlots struct {
+	// Maximum number of connections
+	MaxConnections int
+	// Number of outgoing connections to maintain
+	OutgoingTarget int
+	// Number of outgoing connections, including pending connections
+	Outgoing int
+	// Number of pending outgoing connections
+	Pending int
+	// Maximum number of incoming connections, excluding the connections in protected slots
+	MaxIncoming int
+	// Number of incoming connections, excluding the connections in protected slots
+	Incoming int
+	// Number of incoming connection slots reserved for trusted peers
+	ProtectedSlots int
+	// Number of incoming connections in protected slots
+	Protected int
+}
+
+// protectedConnections returns the addresses of the incoming connections in protected slots.
+// Incoming connections from the IP address of a trusted peer take the protected slots, in the order they connected.
+// The incoming connections of trusted peers that don't fit in the protected slots are regular incoming connections.
+func protectedConnections(conns []connection, trusted pex.Peers, slots int) map[string]struct{} {
+	protected := make(map[string]struct{})
+	if slots == 0 || len(trusted) == 0 {
+		return protected
+	}
+
+	trustedIPs := make(map[string]struct{}, len(trusted))
+	for _, p := range trusted {
+		ip, _, err := iputil.SplitAddr(p.Addr)
+		if err != nil {
+			continue
+		}
+		trustedIPs[ip] = struct{}{}
+	}
+
+	var candidates []connection
+	for _, c := range conns {
+		if c.Outgoing {
+			continue
+		}
+		ip, _, err := iputil.SplitAddr(c.Addr)
+		if err != nil {
+			continue
+		}
+		if _, ok := trustedIPs[ip]; ok {
+			candidates = append(candidates, c)
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].ConnectedAt.Equal(candidates[j].ConnectedAt) {
+			return candidates[i].Addr < candidates[j].Addr
+		}
+		return candidates[i].ConnectedAt.Before(candidates[j].ConnectedAt)
+	})
+
+	for i := 0; i < len(candidates) && i < slots; i++ {
+		protected[candidates[i].Addr] = struct{}{}
+	}
+
+	return protected
+}
+
+// selectEvictedConnection returns the address of the incoming connection to evict for the incoming connections
+// to fit in maxIncoming, or an empty string if they fit. Connections in protected slots are never evicted.
+// The connection with the highest misbehavior score is evicted, and the most recent of the connections
+// with the same score, so that a new connection is only kept by evicting a peer that misbehaved.
+func selectEvictedConnection(conns []connection, protected map[string]struct{}, maxIncoming int, score func(ip string) int) string {
+	var incoming []connection
+	for _, c := range conns {
+		if c.Outgoing {
+			continue
+		}
+		if _, ok := protected[c.Addr]; ok {
+			continue
+		}
+		incoming = append(incoming, c)
+	}
+
+	if len(incoming) <= maxIncoming {
+		return ""
+	}
+
+	scores := make(map[string]int, len(incoming))
+	for _, c := range incoming {
+		ip, _, err := iputil.SplitAddr(c.Addr)
+		if err != nil {
+			continue
+		}
+		scores[c.Addr] = score(ip)
+	}
+
+	sort.Slice(incoming, func(i, j int) bool {
+		a, b := incoming[i], incoming[j]
+		if scores[a.Addr] != scores[b.Addr] {
+			return scores[a.Addr] > scores[b.Addr]
+		}
+		if !a.ConnectedAt.Equal(b.ConnectedAt) {
+			return a.ConnectedAt.After(b.ConnectedAt)
+		}
+		return a.Addr > b.Addr
+	})
+
+	return incoming[0].Addr
+}
+
+// protectedConnections returns the addresses of the incoming connections in protected slots
+func (dm *Daemon) protectedConnections(conns []connection) map[string]struct{} {
+	return protectedConnections(conns, dm.pex.Trusted(), dm.config.ProtectedConnections)
+}
+
+// selectEvictedConnection returns the address of the incoming connection to evict when the incoming connections are full,
+// or an empty string if they are not full
+func (dm *Daemon) selectEvictedConnection() string {
+	conns := dm.connections.all()
+	return selectEvictedConnection(conns, dm.protectedConnections(conns), dm.config.MaxIncomingConnections, dm.bans.Score)
+}
+
+// GetConnectionSlots returns the connection limits of the daemon and the number of connections using them
+func (dm *Daemon) GetConnectionSlots() ConnectionSlots {
+	conns := dm.connections.all()
+	protected := dm.protectedConnections(conns)
+
+	slots := ConnectionSlots{
+		MaxConnections: dm.config.MaxConnections,
+		Protected:      len(protected),
+	}
+
+	if !dm.config.DisableOutgoingConnections {
+		slots.OutgoingTarget = dm.config.MaxOutgoingConnections
+	}
+
+	if !dm.config.DisableIncomingConnections {
+		slots.MaxIncoming = dm.config.MaxIncomingConnections
+		slots.ProtectedSlots = dm.config.ProtectedConnections
+	}
+
+	for _, c := range conns {
+		switch {
+		case c.Outgoing:
+			slots.Outgoing++
+			if c.State == ConnectionStatePending {
+				slots.Pending++
+			}
+		default:
+			if _, ok := protected[c.Addr]; !ok {
+				slots.Incoming++
+			}
+		}
+	}
+
+	return slots
+}
@@ -0,0 +1,154 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/daemon/pex"
+)
+
+func newSlotsTestConnection(addr string, outgoing bool, connectedAt int64) connection {
+	return connection{
+		Addr: addr,
+		ConnectionDetails: ConnectionDetails{
+			State:       ConnectionStateIntroduced,
+			Outgoing:    outgoing,
+			ConnectedAt: time.Unix(connectedAt, 0),
+		},
+	}
+}
+
+func TestProtectedConnections(t *testing.T) {
+	conns := []connection{
+		newSlotsTestConnection("1.1.1.1:40001", false, 3),
+		newSlotsTestConnection("2.2.2.2:40002", false, 1),
+		newSlotsTestConnection("3.3.3.3:40003", false, 2),
+		newSlotsTestConnection("4.4.4.4:6000", true, 1),
+		newSlotsTestConnection("[2001:db8::1]:40004", false, 4),
+	}
+
+	trusted := pex.Peers{
+		{Addr: "1.1.1.1:6000", Trusted: true},
+		{Addr: "3.3.3.3:6000", Trusted: true},
+		{Addr: "4.4.4.4:6000", Trusted: true},
+		{Addr: "[2001:db8::1]:6000", Trusted: true},
+	}
+
+	tt := []struct {
+		name      string
+		trusted   pex.Peers
+		slots     int
+		protected map[string]struct{}
+	}{
+		{
+			name:      "no slots",
+			trusted:   trusted,
+			slots:     0,
+			protected: map[string]struct{}{},
+		},
+		{
+			name:      "no trusted peers",
+			slots:     8,
+			protected: map[string]struct{}{},
+		},
+		{
+			name:    "trusted incoming connections fit",
+			trusted: trusted,
+			slots:   8,
+			protected: map[string]struct{}{
+				"1.1.1.1:40001":       {},
+				"3.3.3.3:40003":       {},
+				"[2001:db8::1]:40004": {},
+			},
+		},
+		{
+			name:    "earliest trusted incoming connections take the slots",
+			trusted: trusted,
+			slots:   2,
+			protected: map[string]struct{}{
+				"1.1.1.1:40001": {},
+				"3.3.3.3:40003": {},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			protected := protectedConnections(conns, tc.trusted, tc.slots)
+			require.Equal(t, tc.protected, protected)
+		})
+	}
+}
+
+func TestSelectEvictedConnection(t *testing.T) {
+	conns := []connection{
+		newSlotsTestConnection("1.1.1.1:40001", false, 1),
+		newSlotsTestConnection("2.2.2.2:40002", false, 2),
+		newSlotsTestConnection("3.3.3.3:40003", false, 3),
+		newSlotsTestConnection("4.4.4.4:6000", true, 4),
+	}
+
+	tt := []struct {
+		name        string
+		protected   map[string]struct{}
+		maxIncoming int
+		scores      map[string]int
+		evicted     string
+	}{
+		{
+			name:        "incoming connections fit",
+			maxIncoming: 3,
+		},
+		{
+			name:        "most recent connection is evicted when scores are equal",
+			maxIncoming: 2,
+			evicted:     "3.3.3.3:40003",
+		},
+		{
+			name:        "highest score is evicted",
+			maxIncoming: 2,
+			scores: map[string]int{
+				"1.1.1.1": 20,
+				"2.2.2.2": 50,
+			},
+			evicted: "2.2.2.2:40002",
+		},
+		{
+			name: "protected connections are not evicted or counted",
+			protected: map[string]struct{}{
+				"2.2.2.2:40002": {},
+			},
+			maxIncoming: 1,
+			scores: map[string]int{
+				"2.2.2.2": 50,
+			},
+			evicted: "3.3.3.3:40003",
+		},
+		{
+			name: "protected connections don't use incoming slots",
+			protected: map[string]struct{}{
+				"3.3.3.3:40003": {},
+			},
+			maxIncoming: 2,
+		},
+		{
+			name:        "no incoming connections allowed",
+			maxIncoming: 0,
+			scores: map[string]int{
+				"1.1.1.1": 20,
+			},
+			evicted: "1.1.1.1:40001",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			evicted := selectEvictedConnection(conns, tc.protected, tc.maxIncoming, func(ip string) int {
+				return tc.scores[ip]
+			})
+			require.Equal(t, tc.evicted, evicted)
+		})
+	}
+}
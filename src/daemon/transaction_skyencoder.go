@@ -47,7 +47,7 @@ func encodeSizeTransaction(obj *coin.Transaction) uint64 {
 
 	// obj.Out
 	i0 += 4
-	{
+	for _, x := range obj.Out {
 		i1 := uint64(0)
 
 		// x.Address.Version
@@ -62,9 +62,15 @@ func encodeSizeTransaction(obj *coin.Transaction) uint64 {
 		// x.Hours
 		i1 += 8
 
-		i0 += uint64(len(obj.Out)) * i1
+		// x.ProgramState
+		i1 += 4 + uint64(len(x.ProgramState))
+
+		i0 += i1
 	}
 
+	// obj.MainExpressions
+	i0 += 4 + uint64(len(obj.MainExpressions))
+
 	return i0
 }
 
@@ -5,6 +5,7 @@ package fiber
 
 import (
 	"fmt"
+	"math"
 	"strings"
 
 	"github.com/spf13/viper"
@@ -67,8 +68,11 @@ type NodeConfig struct {
 	// ExplorerURL is the URL of the public explorer
 	ExplorerURL string `mapstructure:"explorer_url"`
 
-	// These fields are set by cmd/newcoin and are not configured in the fiber.toml file
-	CoinName      string
+	// CoinName is the name of the coin. It is set by cmd/newcoin createcoin, and by the chain parameters
+	// file generated by cmd/newcoin genesis
+	CoinName string `mapstructure:"coin_name"`
+
+	// This field is set by cmd/newcoin and is not configured in the fiber.toml file
 	DataDirectory string
 }
 
@@ -94,6 +98,9 @@ type ParamsConfig struct {
 	DistributionAddresses []string `mapstructure:"distribution_addresses"`
 	// UserBurnFactor inverse fraction of coinhours that must be burned, this value is used when creating transactions
 	UserBurnFactor uint64 `mapstructure:"user_burn_factor"`
+	// MultisigActivationSeq is the seq of the first block that may contain multisig transactions
+	// or outputs to multisig addresses. Changing it is a hard fork. The default never activates multisig.
+	MultisigActivationSeq uint64 `mapstructure:"multisig_activation_seq"`
 }
 
 // NewConfig loads blockchain config parameters from a config file
@@ -163,4 +170,5 @@ func setDefaults() {
 	viper.SetDefault("params.user_max_decimals", 3)
 	viper.SetDefault("params.user_burn_factor", 10)
 	viper.SetDefault("params.user_max_transaction_size", 128 * 1024 * 1024)
+	viper.SetDefault("params.multisig_activation_seq", uint64(math.MaxUint64))
 }
@@ -50,6 +50,7 @@ func TestNewConfig(t *testing.T) {
 			UserBurnFactor:          3,
 			UserMaxTransactionSize:  999,
 			UserMaxDropletPrecision: 2,
+			MultisigActivationSeq:   1000,
 		},
 	}, coinConfig)
 }
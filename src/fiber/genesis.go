@@ -0,0 +1,189 @@
+package fiber
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"text/template"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/util/droplet"
+)
+
+// GenesisOptions are the parameters of a new chain
+type GenesisOptions struct {
+	// CoinName is the name of the coin, used for the default data directory of the node
+	CoinName string
+	// Ticker is the price ticker of the coin
+	Ticker string
+	// Seed generates the blockchain key and the distribution addresses.
+	// The blockchain key is the first key of a deterministic wallet created with Seed,
+	// and the distribution addresses are the following addresses.
+	Seed string
+	// MaxCoinSupply is the number of coins created in the genesis block
+	MaxCoinSupply uint64
+	// DistributionAddresses is the number of addresses that the genesis coins are distributed to
+	DistributionAddresses int
+	// MaxDecimals is the maximum number of decimals of the coins of a transaction
+	MaxDecimals uint8
+	// GenesisTimestamp is the timestamp of the genesis block
+	GenesisTimestamp uint64
+	// Port is the port of the wire protocol
+	Port int
+	// WebInterfacePort is the port of the web interface
+	WebInterfacePort int
+}
+
+// Genesis is a generated genesis block and the parameters of its chain
+type Genesis struct {
+	Config    Config
+	Pubkey    cipher.PubKey
+	Seckey    cipher.SecKey
+	Address   cipher.Address
+	Block     coin.Block
+	Signature cipher.Sig
+}
+
+// NewGenesis generates the blockchain key, the distribution addresses and the genesis block of a new chain.
+// The other parameters of the chain have the default values of a fiber config file.
+func NewGenesis(opts GenesisOptions) (*Genesis, error) {
+	if opts.CoinName == "" {
+		return nil, errors.New("coin name must not be empty")
+	}
+
+	if opts.Seed == "" {
+		return nil, errors.New("seed must not be empty")
+	}
+
+	if opts.DistributionAddresses < 1 {
+		return nil, errors.New("distribution addresses must be >= 1")
+	}
+
+	if opts.MaxCoinSupply == 0 || opts.MaxCoinSupply%uint64(opts.DistributionAddresses) != 0 {
+		return nil, errors.New("max coin supply must be > 0 and divisible by the number of distribution addresses")
+	}
+
+	if opts.MaxDecimals > droplet.Exponent {
+		return nil, fmt.Errorf("max decimals must be <= %d", droplet.Exponent)
+	}
+
+	// TOML integers are int64
+	if opts.MaxCoinSupply > uint64(math.MaxInt64)/droplet.Multiplier {
+		return nil, errors.New("max coin supply is too large")
+	}
+	coinVolume := opts.MaxCoinSupply * droplet.Multiplier
+
+	keys := cipher.MustGenerateDeterministicKeyPairs([]byte(opts.Seed), opts.DistributionAddresses+1)
+	seckey := keys[0]
+	pubkey := cipher.MustPubKeyFromSecKey(seckey)
+	addr := cipher.AddressFromPubKey(pubkey)
+
+	distAddrs := make([]string, opts.DistributionAddresses)
+	for i, k := range keys[1:] {
+		distAddrs[i] = cipher.MustAddressFromSecKey(k).String()
+	}
+
+	b, err := coin.NewGenesisBlock(addr, coinVolume, opts.GenesisTimestamp, []byte{})
+	if err != nil {
+		return nil, err
+	}
+	sig := cipher.MustSignHash(b.HashHeader(), seckey)
+
+	return &Genesis{
+		Config: Config{
+			Node: NodeConfig{
+				Port:                           opts.Port,
+				WebInterfacePort:               opts.WebInterfacePort,
+				GenesisSignatureStr:            sig.Hex(),
+				GenesisAddressStr:              addr.String(),
+				BlockchainPubkeyStr:            pubkey.Hex(),
+				BlockchainSeckeyStr:            seckey.Hex(),
+				GenesisTimestamp:               opts.GenesisTimestamp,
+				GenesisCoinVolume:              coinVolume,
+				UnconfirmedBurnFactor:          10,
+				UnconfirmedMaxTransactionSize:  5 * 1024 * 1024,
+				UnconfirmedMaxDropletPrecision: opts.MaxDecimals,
+				CreateBlockBurnFactor:          10,
+				CreateBlockMaxTransactionSize:  5 * 1024 * 1024,
+				CreateBlockMaxDropletPrecision: opts.MaxDecimals,
+				MaxBlockTransactionsSize:       5 * 1024 * 1024,
+				DisplayName:                    opts.CoinName,
+				Ticker:                         opts.Ticker,
+				CoinHoursName:                  "Coin Hours",
+				CoinHoursTicker:                "",
+				CoinName:                       opts.CoinName,
+			},
+			Params: ParamsConfig{
+				MaxCoinSupply:           opts.MaxCoinSupply,
+				InitialUnlockedCount:    uint64(opts.DistributionAddresses),
+				UnlockAddressRate:       5,
+				UnlockTimeInterval:      60 * 60 * 24 * 365,
+				UserMaxDropletPrecision: uint64(opts.MaxDecimals),
+				UserMaxTransactionSize:  5 * 1024 * 1024,
+				DistributionAddresses:   distAddrs,
+				UserBurnFactor:          10,
+				MultisigActivationSeq:   math.MaxUint64,
+			},
+		},
+		Pubkey:    pubkey,
+		Seckey:    seckey,
+		Address:   addr,
+		Block:     *b,
+		Signature: sig,
+	}, nil
+}
+
+var configTemplate = template.Must(template.New("fiber.toml").Funcs(template.FuncMap{
+	"multisigDisabled": func(seq uint64) bool {
+		return seq == math.MaxUint64
+	},
+}).Parse(`# fiber configuration
+[node]
+coin_name = "{{.Node.CoinName}}"
+genesis_signature_str = "{{.Node.GenesisSignatureStr}}"
+genesis_address_str = "{{.Node.GenesisAddressStr}}"
+blockchain_pubkey_str = "{{.Node.BlockchainPubkeyStr}}"
+# The secret key is required to publish blocks. Remove it from the copies of this file given to other nodes
+blockchain_seckey_str = "{{.Node.BlockchainSeckeyStr}}"
+genesis_timestamp = {{.Node.GenesisTimestamp}}
+genesis_coin_volume = {{.Node.GenesisCoinVolume}}
+default_connections = [{{range $i, $c := .Node.DefaultConnections}}{{if $i}}, {{end}}"{{$c}}"{{end}}]
+peer_list_url = "{{.Node.PeerListURL}}"
+port = {{.Node.Port}}
+web_interface_port = {{.Node.WebInterfacePort}}
+unconfirmed_burn_factor = {{.Node.UnconfirmedBurnFactor}}
+unconfirmed_max_transaction_size = {{.Node.UnconfirmedMaxTransactionSize}}
+unconfirmed_max_decimals = {{.Node.UnconfirmedMaxDropletPrecision}}
+create_block_burn_factor = {{.Node.CreateBlockBurnFactor}}
+create_block_max_transaction_size = {{.Node.CreateBlockMaxTransactionSize}}
+create_block_max_decimals = {{.Node.CreateBlockMaxDropletPrecision}}
+max_block_transactions_size = {{.Node.MaxBlockTransactionsSize}}
+display_name = "{{.Node.DisplayName}}"
+ticker = "{{.Node.Ticker}}"
+coin_hours_display_name = "{{.Node.CoinHoursName}}"
+coin_hours_ticker = "{{.Node.CoinHoursTicker}}"
+explorer_url = "{{.Node.ExplorerURL}}"
+
+[params]
+max_coin_supply = {{.Params.MaxCoinSupply}}
+initial_unlocked_count = {{.Params.InitialUnlockedCount}}
+unlock_address_rate = {{.Params.UnlockAddressRate}}
+unlock_time_interval = {{.Params.UnlockTimeInterval}}
+user_max_decimals = {{.Params.UserMaxDropletPrecision}}
+user_max_transaction_size = {{.Params.UserMaxTransactionSize}}
+user_burn_factor = {{.Params.UserBurnFactor}}
+{{if multisigDisabled .Params.MultisigActivationSeq}}# multisig_activation_seq is not set, multisig is never activated{{else}}multisig_activation_seq = {{.Params.MultisigActivationSeq}}{{end}}
+distribution_addresses = [
+{{- range .Params.DistributionAddresses}}
+    "{{.}}",
+{{- end}}
+]
+`))
+
+// WriteTOML writes the config in the format of a fiber.toml file, which can be loaded with NewConfig.
+// Params.MultisigActivationSeq is not written if multisig is never activated, since it does not fit in a TOML integer.
+func (c Config) WriteTOML(w io.Writer) error {
+	return configTemplate.Execute(w, c)
+}
@@ -0,0 +1,119 @@
+package fiber
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+)
+
+func TestNewGenesis(t *testing.T) {
+	opts := GenesisOptions{
+		CoinName:              "testcoin",
+		Ticker:                "TST",
+		Seed:                  "genesis seed",
+		MaxCoinSupply:         100e6,
+		DistributionAddresses: 4,
+		MaxDecimals:           3,
+		GenesisTimestamp:      1600000000,
+		Port:                  7000,
+		WebInterfacePort:      7420,
+	}
+
+	g, err := NewGenesis(opts)
+	require.NoError(t, err)
+
+	// The blockchain key and the distribution addresses are the addresses of a deterministic wallet with the seed
+	keys := cipher.MustGenerateDeterministicKeyPairs([]byte(opts.Seed), 5)
+	require.Equal(t, keys[0], g.Seckey)
+	require.Equal(t, cipher.MustPubKeyFromSecKey(keys[0]), g.Pubkey)
+	require.Equal(t, cipher.AddressFromPubKey(g.Pubkey), g.Address)
+	require.Len(t, g.Config.Params.DistributionAddresses, 4)
+	for i, a := range g.Config.Params.DistributionAddresses {
+		require.Equal(t, cipher.MustAddressFromSecKey(keys[i+1]).String(), a)
+	}
+
+	b, err := coin.NewGenesisBlock(g.Address, 100e12, opts.GenesisTimestamp, []byte{})
+	require.NoError(t, err)
+	require.Equal(t, *b, g.Block)
+	require.NoError(t, cipher.VerifyPubKeySignedHash(g.Pubkey, g.Signature, g.Block.HashHeader()))
+
+	// The written file loads as the same config
+	dir, err := ioutil.TempDir("", "genesis")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	f, err := os.Create(filepath.Join(dir, "testcoin.toml"))
+	require.NoError(t, err)
+	require.NoError(t, g.Config.WriteTOML(f))
+	require.NoError(t, f.Close())
+
+	cfg, err := NewConfig("testcoin.toml", dir)
+	require.NoError(t, err)
+	require.Equal(t, g.Config, cfg)
+
+	for _, tc := range []struct {
+		name string
+		opts func(GenesisOptions) GenesisOptions
+		err  string
+	}{
+		{
+			name: "no coin name",
+			opts: func(o GenesisOptions) GenesisOptions {
+				o.CoinName = ""
+				return o
+			},
+			err: "coin name must not be empty",
+		},
+		{
+			name: "no seed",
+			opts: func(o GenesisOptions) GenesisOptions {
+				o.Seed = ""
+				return o
+			},
+			err: "seed must not be empty",
+		},
+		{
+			name: "no distribution addresses",
+			opts: func(o GenesisOptions) GenesisOptions {
+				o.DistributionAddresses = 0
+				return o
+			},
+			err: "distribution addresses must be >= 1",
+		},
+		{
+			name: "supply not divisible",
+			opts: func(o GenesisOptions) GenesisOptions {
+				o.DistributionAddresses = 3
+				return o
+			},
+			err: "max coin supply must be > 0 and divisible by the number of distribution addresses",
+		},
+		{
+			name: "too many decimals",
+			opts: func(o GenesisOptions) GenesisOptions {
+				o.MaxDecimals = 7
+				return o
+			},
+			err: "max decimals must be <= 6",
+		},
+		{
+			name: "supply too large",
+			opts: func(o GenesisOptions) GenesisOptions {
+				o.MaxCoinSupply = 1e16
+				return o
+			},
+			err: "max coin supply is too large",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewGenesis(tc.opts(opts))
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package params
+
+// Checkpoint is the hash of a known-good block
+type Checkpoint struct {
+	Seq uint64
+	// Hex encoded block header hash
+	Hash string
+}
+
+// MainNetCheckpoints are the known-good blocks of the mainnet blockchain, by ascending seq.
+// A node will not execute a block at the seq of a checkpoint unless it has the hash of the checkpoint,
+// so a fork of the blockchain below the latest checkpoint is rejected.
+var MainNetCheckpoints = []Checkpoint{}
@@ -12,6 +12,7 @@ func init() {
 	loadUserBurnFactor()
 	loadUserMaxTransactionSize()
 	loadUserMaxDecimals()
+	loadMultisigActivationSeq()
 	sanityCheck()
 }
 
@@ -76,3 +77,17 @@ func loadUserMaxDecimals() {
 
 	UserVerifyTxn.MaxDropletPrecision = uint8(x)
 }
+
+func loadMultisigActivationSeq() {
+	xs := os.Getenv("MULTISIG_ACTIVATION_SEQ")
+	if xs == "" {
+		return
+	}
+
+	x, err := strconv.ParseUint(xs, 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("Invalid MULTISIG_ACTIVATION_SEQ %q: %v", xs, err))
+	}
+
+	MultisigActivationSeq = x
+}
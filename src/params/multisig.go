@@ -0,0 +1,14 @@
+package params
+
+import "math"
+
+// MultisigEnabled returns true if multisig transactions activate at some block seq
+func MultisigEnabled() bool {
+	return MultisigActivationSeq != math.MaxUint64
+}
+
+// MultisigActive returns true if a block with the given seq may contain multisig transactions
+// and outputs to multisig addresses
+func MultisigActive(seq uint64) bool {
+	return MultisigEnabled() && seq >= MultisigActivationSeq
+}
@@ -0,0 +1,29 @@
+package params
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultisigActive(t *testing.T) {
+	seq := MultisigActivationSeq
+	defer func() {
+		MultisigActivationSeq = seq
+	}()
+
+	MultisigActivationSeq = math.MaxUint64
+	require.False(t, MultisigEnabled())
+	require.False(t, MultisigActive(0))
+	require.False(t, MultisigActive(math.MaxUint64))
+
+	MultisigActivationSeq = 10
+	require.True(t, MultisigEnabled())
+	require.False(t, MultisigActive(9))
+	require.True(t, MultisigActive(10))
+	require.True(t, MultisigActive(11))
+
+	MultisigActivationSeq = 0
+	require.True(t, MultisigActive(0))
+}
@@ -125,4 +125,9 @@ var (
 		// MaxDropletPrecision can be overriden with `USER_MAX_DECIMALS` env var
 		MaxDropletPrecision: 3,
 	}
+
+	// MultisigActivationSeq is the seq of the first block that may contain multisig transactions
+	// or outputs to multisig addresses. Changing it is a hard fork.
+	// It can be overriden with `MULTISIG_ACTIVATION_SEQ` env var
+	MultisigActivationSeq uint64 = 18446744073709551615
 )
@@ -37,6 +37,27 @@ func NewBlockHeader(b coin.BlockHeader) BlockHeader {
 	}
 }
 
+// CompactBlockHeader is a block header without the fee, version and unspent outputs hash,
+// for clients that only follow the chain
+type CompactBlockHeader struct {
+	BkSeq        uint64 `json:"seq"`
+	Hash         string `json:"block_hash"`
+	PreviousHash string `json:"previous_block_hash"`
+	Time         uint64 `json:"timestamp"`
+	BodyHash     string `json:"tx_body_hash"`
+}
+
+// NewCompactBlockHeader creates a readable compact block header
+func NewCompactBlockHeader(b coin.BlockHeader) CompactBlockHeader {
+	return CompactBlockHeader{
+		BkSeq:        b.BkSeq,
+		Hash:         b.Hash().Hex(),
+		PreviousHash: b.PrevHash.Hex(),
+		Time:         b.Time,
+		BodyHash:     b.BodyHash.Hex(),
+	}
+}
+
 // ToCoinBlockHeader converts BlockHeader back to coin.BlockHeader
 func (bh BlockHeader) ToCoinBlockHeader() (coin.BlockHeader, error) {
 	prevHash, err := cipher.SHA256FromHex(bh.PreviousHash)
@@ -13,14 +13,17 @@ type BlockchainMetadata struct {
 	Unspents uint64 `json:"unspents"`
 	// Number of known unconfirmed txns
 	Unconfirmed uint64 `json:"unconfirmed"`
+	// Commitment hash of the unspent outputs
+	UnspentCommitment string `json:"unspent_commitment"`
 }
 
 // NewBlockchainMetadata creates blockchain metadata
 func NewBlockchainMetadata(bm visor.BlockchainMetadata) BlockchainMetadata {
 	return BlockchainMetadata{
-		Head:        NewBlockHeader(bm.HeadBlock.Head),
-		Unspents:    bm.Unspents,
-		Unconfirmed: bm.Unconfirmed,
+		Head:              NewBlockHeader(bm.HeadBlock.Head),
+		Unspents:          bm.Unspents,
+		Unconfirmed:       bm.Unconfirmed,
+		UnspentCommitment: bm.UnspentCommitment.Hex(),
 	}
 }
 
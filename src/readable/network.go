@@ -20,6 +20,8 @@ type Connection struct {
 	Height               uint64                 `json:"height"`
 	UserAgent            useragent.Data         `json:"user_agent"`
 	IsTrustedPeer        bool                   `json:"is_trusted_peer"`
+	Protected            bool                   `json:"protected"`
+	MisbehaviorScore     int                    `json:"misbehavior_score"`
 	UnconfirmedVerifyTxn VerifyTxn              `json:"unconfirmed_verify_transaction"`
 }
 
@@ -52,10 +54,38 @@ func NewConnection(c *daemon.Connection) Connection {
 		Height:               c.Height,
 		UserAgent:            c.UserAgent,
 		IsTrustedPeer:        c.Pex.Trusted,
+		Protected:            c.Protected,
+		MisbehaviorScore:     c.MisbehaviorScore,
 		UnconfirmedVerifyTxn: NewVerifyTxn(c.UnconfirmedVerifyTxn),
 	}
 }
 
+// ConnectionSlots are the connection limits of the node and the number of connections using them
+type ConnectionSlots struct {
+	MaxConnections int `json:"max_connections"`
+	OutgoingTarget int `json:"outgoing_target"`
+	Outgoing       int `json:"outgoing"`
+	Pending        int `json:"pending"`
+	MaxIncoming    int `json:"max_incoming"`
+	Incoming       int `json:"incoming"`
+	ProtectedSlots int `json:"protected_slots"`
+	Protected      int `json:"protected"`
+}
+
+// NewConnectionSlots copies daemon.ConnectionSlots to a struct with json tags
+func NewConnectionSlots(s daemon.ConnectionSlots) ConnectionSlots {
+	return ConnectionSlots{
+		MaxConnections: s.MaxConnections,
+		OutgoingTarget: s.OutgoingTarget,
+		Outgoing:       s.Outgoing,
+		Pending:        s.Pending,
+		MaxIncoming:    s.MaxIncoming,
+		Incoming:       s.Incoming,
+		ProtectedSlots: s.ProtectedSlots,
+		Protected:      s.Protected,
+	}
+}
+
 // VerifyTxn transaction verification parameters
 type VerifyTxn struct {
 	BurnFactor          uint32 `json:"burn_factor"`
@@ -71,3 +101,30 @@ func NewVerifyTxn(p params.VerifyTxn) VerifyTxn {
 		MaxDropletPrecision: p.MaxDropletPrecision,
 	}
 }
+
+// Ban a banned peer IP address
+type Ban struct {
+	IP      string `json:"ip"`
+	Reason  string `json:"reason"`
+	Created int64  `json:"created"`
+	Expires int64  `json:"expires"`
+}
+
+// NewBan copies daemon.Ban to a struct with json tags
+func NewBan(b daemon.Ban) Ban {
+	return Ban{
+		IP:      b.IP,
+		Reason:  b.Reason,
+		Created: b.Created.Unix(),
+		Expires: b.Expires.Unix(),
+	}
+}
+
+// NewBans copies []daemon.Ban to []Ban
+func NewBans(bans []daemon.Ban) []Ban {
+	rb := make([]Ban, len(bans))
+	for i, b := range bans {
+		rb[i] = NewBan(b)
+	}
+	return rb
+}
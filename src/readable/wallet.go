@@ -58,4 +58,5 @@ type WalletMeta struct {
 	CryptoType string `json:"crypto_type"`
 	Timestamp  int64  `json:"timestamp"`
 	Encrypted  bool   `json:"encrypted"`
+	XPub       string `json:"xpub,omitempty"`
 }
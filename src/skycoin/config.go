@@ -5,8 +5,10 @@ import (
 	"flag"
 	"fmt"
 	"math"
+	"net"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 	"time"
@@ -23,12 +25,17 @@ import (
 	"github.com/SkycoinProject/cx-chains/src/readable"
 	"github.com/SkycoinProject/cx-chains/src/util/droplet"
 	"github.com/SkycoinProject/cx-chains/src/util/file"
+	"github.com/SkycoinProject/cx-chains/src/util/logging"
 	"github.com/SkycoinProject/cx-chains/src/util/useragent"
+	"github.com/SkycoinProject/cx-chains/src/visor"
 	"github.com/SkycoinProject/cx-chains/src/wallet"
 )
 
 var (
 	help = false
+
+	// chainNameRe matches the names of chains, which are used as directory names
+	chainNameRe = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
 )
 
 // Config records skycoin node and build config
@@ -48,10 +55,19 @@ type NodeConfig struct {
 	DownloadPeerList bool
 	// Download the peers list from this URL
 	PeerListURL string
+	// Comma separated list of DNS seed hostnames, resolved to the IP addresses of initial peers
+	DNSSeeds string
+	dnsSeeds []string
 	// Don't make any outgoing connections
 	DisableOutgoingConnections bool
 	// Don't allowing incoming connections
 	DisableIncomingConnections bool
+	// Don't allow incoming connections over IPv4
+	DisableIncomingIPv4 bool
+	// Don't allow incoming connections over IPv6
+	DisableIncomingIPv6 bool
+	// Address of a SOCKS5 proxy that outgoing connections are made through, as host:port
+	Proxy string
 	// Disables networking altogether
 	DisableNetworking bool
 	// Enable GUI
@@ -87,14 +103,26 @@ type NodeConfig struct {
 	MaxOutgoingConnections int
 	// Maximum default outgoing connections
 	MaxDefaultPeerOutgoingConnections int
+	// Maximum incoming connections, excluding the connections of trusted peers in protected slots
+	MaxIncomingConnections int
+	// Number of incoming connection slots reserved for trusted peers
+	ProtectedConnections int
 	// How often to make outgoing connections
 	OutgoingConnectionsRate time.Duration
 	// MaxOutgoingMessageLength maximum size of outgoing messages
 	MaxOutgoingMessageLength int
 	// MaxIncomingMessageLength maximum size of incoming messages
 	MaxIncomingMessageLength int
+	// ExecuteBlocksBatchSize maximum number of received blocks to execute in a single database transaction
+	ExecuteBlocksBatchSize int
 	// PeerlistSize represents the maximum number of peers that the pex would maintain
 	PeerlistSize int
+	// BanScoreThreshold is the misbehavior score at which a peer's IP address is banned
+	BanScoreThreshold int
+	// BanDuration is how long a misbehaving peer's IP address is banned for
+	BanDuration time.Duration
+	// EnableSPVServer serves bloom filtered blocks and transaction proofs to light clients
+	EnableSPVServer bool
 	// Wallet Address Version
 	// AddressVersion string
 	// Remote web interface
@@ -109,17 +137,52 @@ type NodeConfig struct {
 	WebInterfaceKey string
 	// Remote web interface HTTPS support
 	WebInterfaceHTTPS bool
+	// Generate a self-signed certificate and key if WebInterfaceCert and WebInterfaceKey do not exist
+	WebInterfaceAutogenCert bool
 	// Remote web interface username and password
 	WebInterfaceUsername string
 	WebInterfacePassword string
+	// Remote web interface API token, accepted in an "Authorization: Bearer <token>" header
+	WebInterfaceAPIToken string
+	// Only require web interface auth for requests which can change the state of the node, not for GET requests.
+	// The ADMIN, WALLET and INSECURE_WALLET_SEED API sets always require auth
+	WebInterfaceAuthMutatingOnly bool
+	// Remote web interface API keys of the form <key>:<scope>[+<scope>...], separated by comma.
+	// Requests with an API key do not need a CSRF token
+	WebInterfaceAPIKeys string
+	apiKeys             []api.APIKey
 	// Allow web interface auth without HTTPS
 	WebInterfacePlaintextAuth bool
+	// Requests per second allowed per IP address or API key on the web interface. Disabled if 0
+	WebInterfaceRateLimit float64
+	// Requests an IP address or API key can make at once on the web interface
+	WebInterfaceRateLimitBurst int
+	// Requests per second allowed per IP address or API key to the web interface endpoints
+	// which dump large parts of the blockchain. Disabled if 0
+	WebInterfaceExpensiveRateLimit float64
+	// Requests to the expensive endpoints an IP address or API key can make at once
+	WebInterfaceExpensiveRateLimitBurst int
+	// gRPC interface address, served with the web interface. Disabled if empty
+	GRPCAddr string
+	// Sign the responses of the chain tip and balance endpoints of the web interface
+	WebInterfaceSignResponses bool
+	// Secret key file of the web interface response signatures, generated if it does not exist
+	// Default to ${DataDirectory}/api_signing.key
+	WebInterfaceSigningKeyFile string
 
 	// Launch System Default Browser after client startup
 	LaunchBrowser bool
 
 	// Data directory holds app data -- defaults to ~/.skycoin
 	DataDirectory string
+	// Chain parameters file generated by newcoin genesis. Its values replace the compiled-in
+	// defaults of the node, but not the values of the options set on the command line
+	ChainParamsFile string
+	// Distribution parameters loaded from ChainParamsFile
+	distribution *params.Distribution
+	// Chain is the name of the chain to run. The data of a named chain is kept in
+	// the chains/<name> subdirectory of the data directory, so that several chains can share a data directory.
+	Chain string
 	// GUI directory contains assets for the HTML interface
 	GUIDirectory string
 
@@ -136,6 +199,10 @@ type NodeConfig struct {
 	ColorLog bool
 	// This is the value registered with flag, it is converted to LogLevel after parsing
 	LogLevel string
+	// Comma separated module=level log level overrides, e.g. visor=debug,daemon=warn
+	LogModuleLevels string
+	// Log output format, one of text, json or logfmt
+	LogFormat string
 	// Disable "Reply to ping", "Received pong" log messages
 	DisablePingPong bool
 
@@ -150,6 +217,27 @@ type NodeConfig struct {
 	CreateBlockVerifyTxn params.VerifyTxn
 	// Maximum total size of transactions in a block
 	MaxBlockTransactionsSize uint32
+	// Maximum total size of transactions in the unconfirmed pool, 0 for no limit
+	MaxUnconfirmedPoolSize uint64
+	// Minimum fee in coin hours per 1000 bytes of an unconfirmed transaction
+	UnconfirmedMinFeePerKB uint64
+	// Comma separated list of known-good block hashes of the form <seq>:<hash>, in addition to the compiled-in checkpoints
+	Checkpoints string
+	checkpoints []visor.Checkpoint
+	// Verify the transaction signatures of blocks at or below the latest checkpoint
+	VerifyCheckpointedSignatures bool
+	// Number of decoded blocks cached in memory, 0 to disable the cache
+	BlockCacheSize int
+	// Maximum number of transactions in a block created by the block publisher
+	MaxBlockTransactions int
+	// Order in which the block publisher chooses transactions for a block, fee or fifo
+	BlockTxnOrdering string
+	// Comma separated list of addresses that transactions in created blocks may only spend from. Any address if empty
+	BlockAllowAddresses string
+	blockAllowAddresses []cipher.Address
+	// Comma separated list of addresses that transactions in created blocks may not spend from or send to
+	BlockDenyAddresses string
+	blockDenyAddresses []cipher.Address
 
 	unconfirmedBurnFactor          uint64
 	maxUnconfirmedTransactionSize  uint64
@@ -164,12 +252,18 @@ type NodeConfig struct {
 	WalletDirectory string
 	// Wallet crypto type
 	WalletCryptoType string
+	// Address of the Skycoin hardware wallet daemon used to sign hardware wallet transactions, disabled if empty
+	HardwareWalletDaemonAddr string
 
 	// Key-value storage
 	// Default to ${DataDirectory}/data
 	KVStorageDirectory  string
 	EnabledStorageTypes []kvstorage.Type
 
+	// Webhooks registered through the admin API
+	// Default to ${DataDirectory}/webhooks.json
+	WebhooksFile string
+
 	// Disable the hardcoded default peers
 	DisableDefaultPeers bool
 	// Load custom peers from disk
@@ -177,6 +271,17 @@ type NodeConfig struct {
 
 	RunBlockPublisher bool
 
+	// Run a single-node development chain with a genesis block generated from DevnetSeed
+	Devnet bool
+	// Seed of the blockchain key and of the funded addresses of the devnet.
+	// A deterministic wallet created with this seed has the genesis address and the funded addresses.
+	DevnetSeed string
+	// Number of addresses funded in the first block of the devnet
+	DevnetAddresses int
+	// Number of droplets sent to each funded address of the devnet
+	DevnetCoins     uint64
+	devnetAddresses []cipher.Address
+
 	/* Developer options */
 
 	// Enable cpu profiling
@@ -190,9 +295,15 @@ type NodeConfig struct {
 
 	DBPath     string
 	DBReadOnly bool
+	// Keep the blockchain database in memory instead of DBPath. The chain is lost when the node stops
+	InMemoryDB bool
 	LogToFile  bool
 	Version    bool // show node version
 
+	// Run as a read-only archival node: open the db read-only, disable networking
+	// and only expose the query APIs
+	ReadOnly bool
+
 	GenesisSignatureStr string
 	GenesisAddressStr   string
 	BlockchainPubkeyStr string
@@ -228,6 +339,12 @@ func NewNodeConfig(mode string, node fiber.NodeConfig) NodeConfig {
 		DisableOutgoingConnections: false,
 		// Don't allowing incoming connections
 		DisableIncomingConnections: false,
+		// Don't allow incoming connections over IPv4
+		DisableIncomingIPv4: false,
+		// Don't allow incoming connections over IPv6
+		DisableIncomingIPv6: false,
+		// Address of a SOCKS5 proxy that outgoing connections are made through
+		Proxy: "",
 		// Disables networking altogether
 		DisableNetworking: false,
 		// Enable GUI
@@ -251,13 +368,20 @@ func NewNodeConfig(mode string, node fiber.NodeConfig) NodeConfig {
 		MaxOutgoingConnections: 8,
 		// MaxDefaultOutgoingConnections is the maximum default outgoing connections allowed
 		MaxDefaultPeerOutgoingConnections: 1,
-		DownloadPeerList:                  true,
-		PeerListURL:                       node.PeerListURL,
+		// MaxIncomingConnections is the maximum incoming connections allowed, excluding protected connections
+		MaxIncomingConnections: 110,
+		// ProtectedConnections is the number of incoming connection slots reserved for trusted peers
+		ProtectedConnections: 8,
+		DownloadPeerList:     true,
+		PeerListURL:          node.PeerListURL,
 		// How often to make outgoing connections, in seconds
 		OutgoingConnectionsRate:  time.Second * 5,
 		MaxOutgoingMessageLength: 256 * 1024,
 		MaxIncomingMessageLength: 1024 * 1024,
+		ExecuteBlocksBatchSize:   20,
 		PeerlistSize:             65535,
+		BanScoreThreshold:        100,
+		BanDuration:              time.Hour * 24,
 		// Wallet Address Version
 		// AddressVersion: "test",
 		// Remote web interface
@@ -267,6 +391,8 @@ func NewNodeConfig(mode string, node fiber.NodeConfig) NodeConfig {
 		WebInterfaceCert:  "",
 		WebInterfaceKey:   "",
 		WebInterfaceHTTPS: false,
+		// Generate a self-signed certificate if the cert and key files do not exist
+		WebInterfaceAutogenCert: true,
 		EnabledAPISets: strings.Join([]string{
 			api.EndpointsRead,
 			api.EndpointsTransaction,
@@ -282,6 +408,7 @@ func NewNodeConfig(mode string, node fiber.NodeConfig) NodeConfig {
 		// Logging
 		ColorLog:        true,
 		LogLevel:        "INFO",
+		LogFormat:       logging.FormatText,
 		LogToFile:       false,
 		DisablePingPong: false,
 
@@ -300,6 +427,9 @@ func NewNodeConfig(mode string, node fiber.NodeConfig) NodeConfig {
 			MaxDropletPrecision: node.CreateBlockMaxDropletPrecision,
 		},
 		MaxBlockTransactionsSize: node.MaxBlockTransactionsSize,
+		BlockCacheSize:           visor.DefaultBlockCacheSize,
+		MaxBlockTransactions:     coin.MaxBlockTransactions,
+		BlockTxnOrdering:         string(visor.TxnOrderingFee),
 
 		// Wallets
 		WalletDirectory:  "",
@@ -318,8 +448,19 @@ func NewNodeConfig(mode string, node fiber.NodeConfig) NodeConfig {
 		HTTPWriteTimeout: time.Second * 60,
 		HTTPIdleTimeout:  time.Second * 120,
 
+		// Rate limiting of the web interface, disabled by default
+		WebInterfaceRateLimit:               0,
+		WebInterfaceRateLimitBurst:          20,
+		WebInterfaceExpensiveRateLimit:      0,
+		WebInterfaceExpensiveRateLimitBurst: 2,
+
 		RunBlockPublisher: false,
 
+		Devnet:          false,
+		DevnetSeed:      visor.DefaultDevnetSeed,
+		DevnetAddresses: visor.DefaultDevnetAddresses,
+		DevnetCoins:     visor.DefaultDevnetCoins,
+
 		// Enable cpu profiling
 		ProfileCPU: false,
 		// Where the file is written to
@@ -353,6 +494,18 @@ func (c *Config) postProcess() error {
 		os.Exit(0)
 	}
 
+	if c.Node.ChainParamsFile != "" {
+		if err := c.Node.loadChainParams(setFlags()); err != nil {
+			return fmt.Errorf("-chain-params: %v", err)
+		}
+	}
+
+	if c.Node.Devnet {
+		if err := c.Node.setDevnetGenesis(); err != nil {
+			return err
+		}
+	}
+
 	var err error
 	if c.Node.GenesisSignatureStr != "" {
 		c.Node.genesisSignature, err = cipher.SigFromHex(c.Node.GenesisSignatureStr)
@@ -388,6 +541,20 @@ func (c *Config) postProcess() error {
 	c.Node.DataDirectory, err = file.InitDataDir(replaceHome(c.Node.DataDirectory, home))
 	panicIfError(err, "Invalid DataDirectory")
 
+	if c.Node.Chain != "" {
+		if !chainNameRe.MatchString(c.Node.Chain) {
+			return fmt.Errorf("Invalid -chain %q, must contain only letters, digits, - and _", c.Node.Chain)
+		}
+
+		// A named chain must not run with the genesis of the compiled-in chain
+		if _, ok := setFlags()["genesis-address"]; !ok && c.Node.ChainParamsFile == "" && !c.Node.Devnet {
+			return fmt.Errorf("-chain %q requires -chain-params or the genesis options of the chain", c.Node.Chain)
+		}
+
+		c.Node.DataDirectory, err = file.InitDataDir(filepath.Join(c.Node.DataDirectory, "chains", c.Node.Chain))
+		panicIfError(err, "Invalid chain DataDirectory")
+	}
+
 	if c.Node.WebInterfaceCert == "" {
 		c.Node.WebInterfaceCert = filepath.Join(c.Node.DataDirectory, "skycoind.cert")
 	} else {
@@ -418,6 +585,18 @@ func (c *Config) postProcess() error {
 		}
 	}
 
+	if c.Node.WebInterfaceSigningKeyFile == "" {
+		c.Node.WebInterfaceSigningKeyFile = filepath.Join(c.Node.DataDirectory, "api_signing.key")
+	} else {
+		c.Node.WebInterfaceSigningKeyFile = replaceHome(c.Node.WebInterfaceSigningKeyFile, home)
+	}
+
+	if c.Node.WebhooksFile == "" {
+		c.Node.WebhooksFile = filepath.Join(c.Node.DataDirectory, "webhooks.json")
+	} else {
+		c.Node.WebhooksFile = replaceHome(c.Node.WebhooksFile, home)
+	}
+
 	if c.Node.DBPath == "" {
 		c.Node.DBPath = filepath.Join(c.Node.DataDirectory, "data.db")
 	} else {
@@ -441,6 +620,26 @@ func (c *Config) postProcess() error {
 		return err
 	}
 
+	if c.Node.BlockCacheSize < 0 {
+		return errors.New("-block-cache-size must be >= 0")
+	}
+
+	if c.Node.InMemoryDB && (c.Node.DBReadOnly || c.Node.ReadOnly) {
+		return errors.New("-in-memory-db cannot be used with -db-read-only or -read-only")
+	}
+
+	if c.Node.ReadOnly {
+		if c.Node.RunBlockPublisher {
+			return errors.New("-read-only cannot be used with -block-publisher")
+		}
+		if c.Node.ResetCorruptDB {
+			return errors.New("-read-only cannot be used with -reset-corrupt-db")
+		}
+
+		c.Node.DBReadOnly = true
+		c.Node.DisableNetworking = true
+	}
+
 	// Don't open browser to load wallets if wallet apis are disabled.
 	c.Node.enabledAPISets = apiSets
 	if _, ok := c.Node.enabledAPISets[api.EndpointsWallet]; !ok {
@@ -456,6 +655,16 @@ func (c *Config) postProcess() error {
 		c.Node.DefaultConnections = nil
 	}
 
+	if c.Node.DNSSeeds != "" {
+		c.Node.dnsSeeds = strings.Split(c.Node.DNSSeeds, ",")
+	}
+
+	if c.Node.Proxy != "" {
+		if _, _, err := net.SplitHostPort(c.Node.Proxy); err != nil {
+			return fmt.Errorf("-proxy: %v", err)
+		}
+	}
+
 	if c.Node.HostWhitelist != "" {
 		if c.Node.DisableHeaderCheck {
 			return errors.New("host whitelist should be empty when header check is disabled")
@@ -463,11 +672,62 @@ func (c *Config) postProcess() error {
 		c.Node.hostWhitelist = strings.Split(c.Node.HostWhitelist, ",")
 	}
 
-	httpAuthEnabled := c.Node.WebInterfaceUsername != "" || c.Node.WebInterfacePassword != ""
-	if httpAuthEnabled && !c.Node.WebInterfaceHTTPS && !c.Node.WebInterfacePlaintextAuth {
+	if c.Node.WebInterfaceAPIKeys != "" {
+		apiKeys, err := api.ParseAPIKeys(c.Node.WebInterfaceAPIKeys)
+		if err != nil {
+			return err
+		}
+		c.Node.apiKeys = apiKeys
+	}
+
+	checkpoints, err := visor.NewCheckpointsFromParams(params.MainNetCheckpoints)
+	if err != nil {
+		return err
+	}
+	if c.Node.Checkpoints != "" {
+		userCheckpoints, err := visor.ParseCheckpoints(c.Node.Checkpoints)
+		if err != nil {
+			return err
+		}
+		checkpoints = append(checkpoints, userCheckpoints...)
+	}
+	c.Node.checkpoints = checkpoints
+
+	if _, err := visor.ParseTxnOrdering(c.Node.BlockTxnOrdering); err != nil {
+		return fmt.Errorf("-block-txn-ordering: %v", err)
+	}
+
+	if c.Node.MaxBlockTransactions <= 0 || c.Node.MaxBlockTransactions > coin.MaxBlockTransactions {
+		return fmt.Errorf("-max-block-txns must be > 0 and <= %d", coin.MaxBlockTransactions)
+	}
+
+	c.Node.blockAllowAddresses, err = parseAddressList(c.Node.BlockAllowAddresses)
+	if err != nil {
+		return fmt.Errorf("-block-allow-addresses: %v", err)
+	}
+
+	c.Node.blockDenyAddresses, err = parseAddressList(c.Node.BlockDenyAddresses)
+	if err != nil {
+		return fmt.Errorf("-block-deny-addresses: %v", err)
+	}
+
+	httpAuthEnabled := c.Node.WebInterfaceUsername != "" || c.Node.WebInterfacePassword != "" || c.Node.WebInterfaceAPIToken != ""
+	if (httpAuthEnabled || len(c.Node.apiKeys) != 0) && !c.Node.WebInterfaceHTTPS && !c.Node.WebInterfacePlaintextAuth {
 		return errors.New("Web interface auth enabled but HTTPS is not enabled. Use -web-interface-plaintext-auth=true if this is desired")
 	}
 
+	if c.Node.WebInterfaceAuthMutatingOnly && !httpAuthEnabled {
+		return errors.New("-web-interface-auth-mutating-only requires -web-interface-username, -web-interface-password or -web-interface-api-token")
+	}
+
+	if c.Node.WebInterfaceRateLimit < 0 || c.Node.WebInterfaceExpensiveRateLimit < 0 {
+		return errors.New("-web-interface-rate-limit and -web-interface-expensive-rate-limit must be >= 0")
+	}
+
+	if c.Node.WebInterfaceRateLimitBurst < 1 || c.Node.WebInterfaceExpensiveRateLimitBurst < 1 {
+		return errors.New("-web-interface-rate-limit-burst and -web-interface-expensive-rate-limit-burst must be > 0")
+	}
+
 	if c.Node.MaxConnections < c.Node.MaxOutgoingConnections+c.Node.MaxDefaultPeerOutgoingConnections {
 		return errors.New("-max-connections must be >= -max-outgoing-connections + -max-default-peer-outgoing-connections")
 	}
@@ -476,6 +736,18 @@ func (c *Config) postProcess() error {
 		return errors.New("-max-outgoing-connections cannot be higher than -max-connections")
 	}
 
+	if c.Node.MaxIncomingConnections < 0 {
+		return errors.New("-max-incoming-connections must be >= 0")
+	}
+
+	if c.Node.ProtectedConnections < 0 {
+		return errors.New("-protected-connections must be >= 0")
+	}
+
+	if c.Node.BanScoreThreshold <= 0 {
+		return errors.New("-ban-score-threshold must be > 0")
+	}
+
 	if c.Node.maxBlockSize > math.MaxUint32 {
 		return errors.New("-max-block-size exceeds MaxUint32")
 	}
@@ -565,6 +837,7 @@ func (c *Config) postProcess() error {
 // * If EnableAll, all API sets are added
 // * For each api set in EnabledAPISets, add
 // * For each api set in DisabledAPISets, remove
+// * If ReadOnly, remove the api sets which can change the state of the node
 func buildAPISets(c NodeConfig) (map[string]struct{}, error) {
 	enabledAPISets := strings.Split(c.EnabledAPISets, ",")
 	if err := validateAPISets("-enable-api-sets", enabledAPISets); err != nil {
@@ -586,6 +859,7 @@ func buildAPISets(c NodeConfig) (map[string]struct{}, error) {
 		api.EndpointsPrometheus,
 		api.EndpointsNetCtrl,
 		api.EndpointsStorage,
+		api.EndpointsAdmin,
 		// Do not include insecure or deprecated API sets, they must always
 		// be explicitly enabled through -enable-api-sets
 	}
@@ -606,6 +880,21 @@ func buildAPISets(c NodeConfig) (map[string]struct{}, error) {
 		delete(apiSets, k)
 	}
 
+	// Only the query APIs are available to a read-only node. Anything which writes to the db,
+	// the wallets or the data directory, or which changes the node at runtime, is disabled
+	if c.ReadOnly {
+		for _, k := range []string{
+			api.EndpointsWallet,
+			api.EndpointsInsecureWalletSeed,
+			api.EndpointsTransaction,
+			api.EndpointsNetCtrl,
+			api.EndpointsStorage,
+			api.EndpointsAdmin,
+		} {
+			delete(apiSets, k)
+		}
+	}
+
 	return apiSets, nil
 }
 
@@ -620,7 +909,8 @@ func validateAPISets(opt string, apiSets []string) error {
 			api.EndpointsInsecureWalletSeed,
 			api.EndpointsPrometheus,
 			api.EndpointsNetCtrl,
-			api.EndpointsStorage:
+			api.EndpointsStorage,
+			api.EndpointsAdmin:
 		case "":
 			continue
 		default:
@@ -630,14 +920,191 @@ func validateAPISets(opt string, apiSets []string) error {
 	return nil
 }
 
+// parseAddressList parses a comma separated list of addresses
+func parseAddressList(s string) ([]cipher.Address, error) {
+	var addrs []cipher.Address
+	for _, a := range strings.Split(s, ",") {
+		a = strings.TrimSpace(a)
+		if a == "" {
+			continue
+		}
+
+		addr, err := cipher.DecodeBase58Address(a)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q: %v", a, err)
+		}
+
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
+// setFlags returns the names of the command line flags that have been set
+func setFlags() map[string]struct{} {
+	set := make(map[string]struct{})
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = struct{}{}
+	})
+	return set
+}
+
+// loadChainParams loads the chain parameters file and applies its values to the config,
+// except for the values of the flags in set
+func (c *NodeConfig) loadChainParams(set map[string]struct{}) error {
+	path := replaceHome(c.ChainParamsFile, file.UserHome())
+	cfg, err := fiber.NewConfig(filepath.Base(path), filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+
+	isSet := func(name string) bool {
+		_, ok := set[name]
+		return ok
+	}
+
+	node := cfg.Node
+	if node.CoinName != "" {
+		c.CoinName = node.CoinName
+		c.Fiber.Name = node.CoinName
+		if !isSet("data-dir") {
+			c.DataDirectory = "$HOME/." + node.CoinName
+		}
+	}
+
+	if !isSet("genesis-signature") {
+		c.GenesisSignatureStr = node.GenesisSignatureStr
+	}
+	if !isSet("genesis-address") {
+		c.GenesisAddressStr = node.GenesisAddressStr
+	}
+	if !isSet("genesis-timestamp") {
+		c.GenesisTimestamp = node.GenesisTimestamp
+	}
+	if !isSet("blockchain-public-key") {
+		c.BlockchainPubkeyStr = node.BlockchainPubkeyStr
+	}
+	if !isSet("blockchain-secret-key") {
+		c.BlockchainSeckeyStr = node.BlockchainSeckeyStr
+	}
+	c.GenesisCoinVolume = node.GenesisCoinVolume
+	c.DefaultConnections = node.DefaultConnections
+
+	if !isSet("peerlist-url") {
+		c.PeerListURL = node.PeerListURL
+	}
+	if !isSet("port") {
+		c.Port = node.Port
+	}
+	if !isSet("web-interface-port") {
+		c.WebInterfacePort = node.WebInterfacePort
+	}
+
+	if !isSet("burn-factor-unconfirmed") {
+		c.unconfirmedBurnFactor = uint64(node.UnconfirmedBurnFactor)
+	}
+	if !isSet("max-txn-size-unconfirmed") {
+		c.maxUnconfirmedTransactionSize = uint64(node.UnconfirmedMaxTransactionSize)
+	}
+	if !isSet("max-decimals-unconfirmed") {
+		c.unconfirmedMaxDropletPrecision = uint64(node.UnconfirmedMaxDropletPrecision)
+	}
+	if !isSet("burn-factor-create-block") {
+		c.createBlockBurnFactor = uint64(node.CreateBlockBurnFactor)
+	}
+	if !isSet("max-txn-size-create-block") {
+		c.createBlockMaxTransactionSize = uint64(node.CreateBlockMaxTransactionSize)
+	}
+	if !isSet("max-decimals-create-block") {
+		c.createBlockMaxDropletPrecision = uint64(node.CreateBlockMaxDropletPrecision)
+	}
+	if !isSet("max-block-size") {
+		c.maxBlockSize = uint64(node.MaxBlockTransactionsSize)
+	}
+
+	c.Fiber.DisplayName = node.DisplayName
+	c.Fiber.Ticker = node.Ticker
+	c.Fiber.CoinHoursName = node.CoinHoursName
+	c.Fiber.CoinHoursTicker = node.CoinHoursTicker
+	c.Fiber.ExplorerURL = node.ExplorerURL
+
+	if len(cfg.Params.DistributionAddresses) != 0 {
+		dist := params.Distribution{
+			MaxCoinSupply:        cfg.Params.MaxCoinSupply,
+			InitialUnlockedCount: cfg.Params.InitialUnlockedCount,
+			UnlockAddressRate:    cfg.Params.UnlockAddressRate,
+			UnlockTimeInterval:   cfg.Params.UnlockTimeInterval,
+			Addresses:            cfg.Params.DistributionAddresses,
+		}
+		if err := dist.Validate(); err != nil {
+			return err
+		}
+		c.distribution = &dist
+	}
+
+	return nil
+}
+
+// setDevnetGenesis configures a single-node chain with a blockchain key, genesis address and
+// genesis signature generated from DevnetSeed
+func (c *NodeConfig) setDevnetGenesis() error {
+	if c.ReadOnly || c.DBReadOnly {
+		return errors.New("-devnet cannot be used with -read-only or -db-read-only")
+	}
+
+	if c.DisableNetworking {
+		return errors.New("-devnet cannot be used with -disable-networking")
+	}
+
+	if c.DevnetSeed == "" {
+		return errors.New("-devnet-seed must not be empty")
+	}
+
+	if c.DevnetAddresses < 0 {
+		return errors.New("-devnet-addresses must be >= 0")
+	}
+
+	pubkey, seckey := visor.DevnetKeys(c.DevnetSeed)
+	genesisAddr := cipher.AddressFromPubKey(pubkey)
+
+	gb, err := coin.NewGenesisBlock(genesisAddr, c.GenesisCoinVolume, c.GenesisTimestamp, []byte{})
+	if err != nil {
+		return err
+	}
+
+	c.BlockchainPubkeyStr = pubkey.Hex()
+	c.BlockchainSeckeyStr = seckey.Hex()
+	c.GenesisAddressStr = genesisAddr.String()
+	c.GenesisSignatureStr = cipher.MustSignHash(gb.HashHeader(), seckey).Hex()
+	c.devnetAddresses = visor.DevnetAddresses(c.DevnetSeed, c.DevnetAddresses)
+
+	c.RunBlockPublisher = true
+	c.DisablePEX = true
+	c.DisableDefaultPeers = true
+	c.DisableIncomingConnections = true
+	c.DisableOutgoingConnections = true
+	c.DownloadPeerList = false
+	c.DNSSeeds = ""
+
+	// Keep the devnet data apart from the data of the configured chain
+	if c.Chain == "" {
+		c.Chain = "devnet"
+	}
+
+	return nil
+}
+
 // RegisterFlags binds CLI flags to config values
 func (c *NodeConfig) RegisterFlags() {
 	flag.BoolVar(&help, "help", false, "Show help")
 	flag.BoolVar(&c.DisablePEX, "disable-pex", c.DisablePEX, "disable PEX peer discovery")
 	flag.BoolVar(&c.DownloadPeerList, "download-peerlist", c.DownloadPeerList, "download a peers.txt from -peerlist-url")
 	flag.StringVar(&c.PeerListURL, "peerlist-url", c.PeerListURL, "with -download-peerlist=true, download a peers.txt file from this url")
+	flag.StringVar(&c.DNSSeeds, "dns-seeds", c.DNSSeeds, "Comma separated list of DNS seed hostnames to resolve initial peers from, as host or host:port. If no peers are resolved, falls back to -download-peerlist")
 	flag.BoolVar(&c.DisableOutgoingConnections, "disable-outgoing", c.DisableOutgoingConnections, "Don't make outgoing connections")
 	flag.BoolVar(&c.DisableIncomingConnections, "disable-incoming", c.DisableIncomingConnections, "Don't allow incoming connections")
+	flag.BoolVar(&c.DisableIncomingIPv4, "disable-incoming-ipv4", c.DisableIncomingIPv4, "Don't allow incoming connections over IPv4")
+	flag.BoolVar(&c.DisableIncomingIPv6, "disable-incoming-ipv6", c.DisableIncomingIPv6, "Don't allow incoming connections over IPv6")
+	flag.StringVar(&c.Proxy, "proxy", c.Proxy, "Make outgoing connections and download the peers list through this SOCKS5 proxy, as host:port, e.g. 127.0.0.1:9050 for Tor. DNS seeds are not resolved when a proxy is used")
 	flag.BoolVar(&c.DisableNetworking, "disable-networking", c.DisableNetworking, "Disable all network activity")
 	flag.BoolVar(&c.EnableGUI, "enable-gui", c.EnableGUI, "Enable GUI")
 	flag.BoolVar(&c.DisableCSRF, "disable-csrf", c.DisableCSRF, "disable CSRF check")
@@ -652,6 +1119,7 @@ func (c *NodeConfig) RegisterFlags() {
 	flag.StringVar(&c.WebInterfaceCert, "web-interface-cert", c.WebInterfaceCert, "skycoind.cert file for web interface HTTPS. If not provided, will autogenerate or use skycoind.cert in --data-dir")
 	flag.StringVar(&c.WebInterfaceKey, "web-interface-key", c.WebInterfaceKey, "skycoind.key file for web interface HTTPS. If not provided, will autogenerate or use skycoind.key in --data-dir")
 	flag.BoolVar(&c.WebInterfaceHTTPS, "web-interface-https", c.WebInterfaceHTTPS, "enable HTTPS for web interface")
+	flag.BoolVar(&c.WebInterfaceAutogenCert, "web-interface-autogen-cert", c.WebInterfaceAutogenCert, "with -web-interface-https=true, generate a self-signed certificate and key if -web-interface-cert and -web-interface-key do not exist")
 	flag.StringVar(&c.HostWhitelist, "host-whitelist", c.HostWhitelist, "Hostnames to whitelist in the Host header check. Only applies when the web interface is bound to localhost.")
 
 	allAPISets := []string{
@@ -663,6 +1131,7 @@ func (c *NodeConfig) RegisterFlags() {
 		api.EndpointsNetCtrl,
 		api.EndpointsInsecureWalletSeed,
 		api.EndpointsStorage,
+		api.EndpointsAdmin,
 	}
 	flag.StringVar(&c.EnabledAPISets, "enable-api-sets", c.EnabledAPISets, fmt.Sprintf("enable API set. Options are %s. Multiple values should be separated by comma", strings.Join(allAPISets, ", ")))
 	flag.StringVar(&c.DisabledAPISets, "disable-api-sets", c.DisabledAPISets, fmt.Sprintf("disable API set. Options are %s. Multiple values should be separated by comma", strings.Join(allAPISets, ", ")))
@@ -670,17 +1139,33 @@ func (c *NodeConfig) RegisterFlags() {
 
 	flag.StringVar(&c.WebInterfaceUsername, "web-interface-username", c.WebInterfaceUsername, "username for the web interface")
 	flag.StringVar(&c.WebInterfacePassword, "web-interface-password", c.WebInterfacePassword, "password for the web interface")
+	flag.StringVar(&c.WebInterfaceAPIToken, "web-interface-api-token", c.WebInterfaceAPIToken, "API token for the web interface, sent in an \"Authorization: Bearer <token>\" header")
+	flag.BoolVar(&c.WebInterfaceAuthMutatingOnly, "web-interface-auth-mutating-only", c.WebInterfaceAuthMutatingOnly, "only require web interface auth for requests which can change the state of the node, not for GET requests. The ADMIN, WALLET and INSECURE_WALLET_SEED API sets always require auth")
+	flag.StringVar(&c.WebInterfaceAPIKeys, "web-interface-api-keys", c.WebInterfaceAPIKeys, "API keys for automated services, sent in an \"X-API-Key\" header without a CSRF token. Keys are of the form <key>:<scope>[+<scope>...] with scopes read, wallet and admin. Multiple values should be separated by comma")
 	flag.BoolVar(&c.WebInterfacePlaintextAuth, "web-interface-plaintext-auth", c.WebInterfacePlaintextAuth, "allow web interface auth without https")
+	flag.Float64Var(&c.WebInterfaceRateLimit, "web-interface-rate-limit", c.WebInterfaceRateLimit, "requests per second allowed per IP address or API key on the web interface. Disabled if 0")
+	flag.IntVar(&c.WebInterfaceRateLimitBurst, "web-interface-rate-limit-burst", c.WebInterfaceRateLimitBurst, "requests an IP address or API key can make at once on the web interface")
+	flag.Float64Var(&c.WebInterfaceExpensiveRateLimit, "web-interface-expensive-rate-limit", c.WebInterfaceExpensiveRateLimit, "requests per second allowed per IP address or API key to the web interface endpoints which dump large parts of the blockchain. Disabled if 0")
+	flag.IntVar(&c.WebInterfaceExpensiveRateLimitBurst, "web-interface-expensive-rate-limit-burst", c.WebInterfaceExpensiveRateLimitBurst, "requests to the expensive web interface endpoints an IP address or API key can make at once")
+	flag.StringVar(&c.GRPCAddr, "grpc-addr", c.GRPCAddr, "address to serve the gRPC interface on, with the web interface. Disabled if empty")
+	flag.BoolVar(&c.WebInterfaceSignResponses, "web-interface-sign-responses", c.WebInterfaceSignResponses, "sign the responses of the chain tip and balance endpoints of the web interface with the key of -web-interface-signing-key-file")
+	flag.StringVar(&c.WebInterfaceSigningKeyFile, "web-interface-signing-key-file", c.WebInterfaceSigningKeyFile, "secret key file of the web interface response signatures, generated if it does not exist. Defaults to ~/.skycoin/api_signing.key")
 
 	flag.BoolVar(&c.LaunchBrowser, "launch-browser", c.LaunchBrowser, "launch system default webbrowser at client startup")
 	flag.StringVar(&c.DataDirectory, "data-dir", c.DataDirectory, "directory to store app data (defaults to ~/.skycoin)")
+	flag.StringVar(&c.ChainParamsFile, "chain-params", c.ChainParamsFile, "chain parameters file generated by newcoin genesis. Its values replace the compiled-in defaults, but not the options set on the command line")
 	flag.StringVar(&c.DBPath, "db-path", c.DBPath, "path of database file (defaults to ~/.skycoin/data.db)")
+	flag.StringVar(&c.Chain, "chain", c.Chain, "name of the chain to run. The data of the chain is kept in the chains/<name> subdirectory of the data directory")
 	flag.BoolVar(&c.DBReadOnly, "db-read-only", c.DBReadOnly, "open bolt db read-only")
+	flag.BoolVar(&c.InMemoryDB, "in-memory-db", c.InMemoryDB, "keep the database in memory instead of -db-path, for tests and ephemeral chains. The chain is lost when the node stops")
+	flag.BoolVar(&c.ReadOnly, "read-only", c.ReadOnly, "run as a read-only archival node. Opens the db read-only, disables networking, wallets and transaction injection, and only exposes query APIs")
 	flag.BoolVar(&c.ProfileCPU, "profile-cpu", c.ProfileCPU, "enable cpu profiling")
 	flag.StringVar(&c.ProfileCPUFile, "profile-cpu-file", c.ProfileCPUFile, "where to write the cpu profile file")
 	flag.BoolVar(&c.HTTPProf, "http-prof", c.HTTPProf, "run the HTTP profiling interface")
 	flag.StringVar(&c.HTTPProfHost, "http-prof-host", c.HTTPProfHost, "hostname to bind the HTTP profiling interface to")
 	flag.StringVar(&c.LogLevel, "log-level", c.LogLevel, "Choices are: debug, info, warn, error, fatal, panic")
+	flag.StringVar(&c.LogModuleLevels, "log-module-levels", c.LogModuleLevels, "Override the log level of modules. Multiple values should be separated by comma, e.g. visor=debug,daemon=warn")
+	flag.StringVar(&c.LogFormat, "log-format", c.LogFormat, "Choices are: text, json, logfmt")
 	flag.BoolVar(&c.ColorLog, "color-log", c.ColorLog, "Add terminal colors to log output")
 	flag.BoolVar(&c.DisablePingPong, "no-ping-log", c.DisablePingPong, `disable "reply to ping" and "received pong" debug log messages`)
 	flag.BoolVar(&c.LogToFile, "logtofile", c.LogToFile, "log to file")
@@ -697,30 +1182,52 @@ func (c *NodeConfig) RegisterFlags() {
 	flag.Uint64Var(&c.maxUnconfirmedTransactionSize, "max-txn-size-unconfirmed", uint64(c.UnconfirmedVerifyTxn.MaxTransactionSize), "maximum size of an unconfirmed transaction")
 	flag.Uint64Var(&c.unconfirmedBurnFactor, "burn-factor-unconfirmed", uint64(c.UnconfirmedVerifyTxn.BurnFactor), "coinhour burn factor applied to unconfirmed transactions")
 	flag.Uint64Var(&c.unconfirmedMaxDropletPrecision, "max-decimals-unconfirmed", uint64(c.UnconfirmedVerifyTxn.MaxDropletPrecision), "max number of decimal places applied to unconfirmed transactions")
+	flag.Uint64Var(&c.MaxUnconfirmedPoolSize, "max-pool-size-unconfirmed", c.MaxUnconfirmedPoolSize, "maximum total size in bytes of the unconfirmed transaction pool. When full, the lowest fee per kilobyte transactions are evicted. 0 for no limit")
+	flag.Uint64Var(&c.UnconfirmedMinFeePerKB, "min-fee-per-kb-unconfirmed", c.UnconfirmedMinFeePerKB, "minimum fee in coin hours per 1000 bytes of an unconfirmed transaction")
 	flag.Uint64Var(&c.createBlockBurnFactor, "burn-factor-create-block", uint64(c.CreateBlockVerifyTxn.BurnFactor), "coinhour burn factor applied when creating blocks")
 	flag.Uint64Var(&c.createBlockMaxTransactionSize, "max-txn-size-create-block", uint64(c.CreateBlockVerifyTxn.MaxTransactionSize), "maximum size of a transaction applied when creating blocks")
 	flag.Uint64Var(&c.createBlockMaxDropletPrecision, "max-decimals-create-block", uint64(c.CreateBlockVerifyTxn.MaxDropletPrecision), "max number of decimal places applied when creating blocks")
 	flag.Uint64Var(&c.maxBlockSize, "max-block-size", uint64(c.MaxBlockTransactionsSize), "maximum total size of transactions in a block")
+	flag.StringVar(&c.Checkpoints, "checkpoints", c.Checkpoints, "known-good block hashes of the form <seq>:<hash>, in addition to the compiled-in checkpoints. Multiple values should be separated by comma")
+	flag.BoolVar(&c.VerifyCheckpointedSignatures, "verify-checkpointed-signatures", c.VerifyCheckpointedSignatures, "verify the transaction signatures of blocks at or below the latest checkpoint")
+	flag.IntVar(&c.BlockCacheSize, "block-cache-size", c.BlockCacheSize, "number of decoded blocks cached in memory, 0 to disable the cache")
+	flag.IntVar(&c.MaxBlockTransactions, "max-block-txns", c.MaxBlockTransactions, "maximum number of transactions in a block when creating blocks")
+	flag.StringVar(&c.BlockTxnOrdering, "block-txn-ordering", c.BlockTxnOrdering, "order in which transactions are chosen when creating blocks. Options are fee (highest fee per kilobyte first) and fifo (earliest received first)")
+	flag.StringVar(&c.BlockAllowAddresses, "block-allow-addresses", c.BlockAllowAddresses, "addresses that transactions may only spend from when creating blocks. Multiple values should be separated by comma")
+	flag.StringVar(&c.BlockDenyAddresses, "block-deny-addresses", c.BlockDenyAddresses, "addresses that transactions may not spend from or send to when creating blocks. Multiple values should be separated by comma")
 
 	flag.BoolVar(&c.RunBlockPublisher, "block-publisher", c.RunBlockPublisher, "run the daemon as a block publisher")
 	flag.StringVar(&c.BlockchainPubkeyStr, "blockchain-public-key", c.BlockchainPubkeyStr, "public key of the blockchain")
 	flag.StringVar(&c.BlockchainSeckeyStr, "blockchain-secret-key", c.BlockchainSeckeyStr, "secret key of the blockchain")
 
+	flag.BoolVar(&c.Devnet, "devnet", c.Devnet, "run a single-node development chain with a generated genesis block, funded addresses and blocks generated on demand by POST /api/v1/devnet/generate. Peer connections are disabled")
+	flag.StringVar(&c.DevnetSeed, "devnet-seed", c.DevnetSeed, "seed of the blockchain key and the funded addresses of the devnet")
+	flag.IntVar(&c.DevnetAddresses, "devnet-addresses", c.DevnetAddresses, "number of addresses funded in the first block of the devnet")
+	flag.Uint64Var(&c.DevnetCoins, "devnet-coins", c.DevnetCoins, "number of droplets sent to each funded address of the devnet")
+
 	flag.StringVar(&c.GenesisAddressStr, "genesis-address", c.GenesisAddressStr, "genesis address")
 	flag.StringVar(&c.GenesisSignatureStr, "genesis-signature", c.GenesisSignatureStr, "genesis block signature")
 	flag.Uint64Var(&c.GenesisTimestamp, "genesis-timestamp", c.GenesisTimestamp, "genesis block timestamp")
 
 	flag.StringVar(&c.WalletDirectory, "wallet-dir", c.WalletDirectory, "location of the wallet files. Defaults to ~/.skycoin/wallet/")
 	flag.StringVar(&c.KVStorageDirectory, "storage-dir", c.KVStorageDirectory, "location of the storage data files. Defaults to ~/.skycoin/data/")
+	flag.StringVar(&c.WebhooksFile, "webhooks-file", c.WebhooksFile, "location of the file storing the registered webhooks. Defaults to ~/.skycoin/webhooks.json")
 	flag.IntVar(&c.MaxConnections, "max-connections", c.MaxConnections, "Maximum number of total connections allowed")
 	flag.IntVar(&c.MaxOutgoingConnections, "max-outgoing-connections", c.MaxOutgoingConnections, "Maximum number of outgoing connections allowed")
 	flag.IntVar(&c.MaxDefaultPeerOutgoingConnections, "max-default-peer-outgoing-connections", c.MaxDefaultPeerOutgoingConnections, "The maximum default peer outgoing connections allowed")
+	flag.IntVar(&c.MaxIncomingConnections, "max-incoming-connections", c.MaxIncomingConnections, "Maximum number of incoming connections allowed, excluding the connections of trusted peers in protected slots. When full, the incoming peer with the highest misbehavior score is evicted")
+	flag.IntVar(&c.ProtectedConnections, "protected-connections", c.ProtectedConnections, "Number of incoming connection slots reserved for trusted peers, which are never evicted")
 	flag.IntVar(&c.PeerlistSize, "peerlist-size", c.PeerlistSize, "Max number of peers to track in peerlist")
 	flag.DurationVar(&c.OutgoingConnectionsRate, "connection-rate", c.OutgoingConnectionsRate, "How often to make an outgoing connection")
 	flag.IntVar(&c.MaxOutgoingMessageLength, "max-out-msg-len", c.MaxOutgoingMessageLength, "Maximum length of outgoing wire messages")
 	flag.IntVar(&c.MaxIncomingMessageLength, "max-in-msg-len", c.MaxIncomingMessageLength, "Maximum length of incoming wire messages")
+	flag.IntVar(&c.BanScoreThreshold, "ban-score-threshold", c.BanScoreThreshold, "Misbehavior score at which a peer is banned")
+	flag.DurationVar(&c.BanDuration, "ban-duration", c.BanDuration, "How long a misbehaving peer is banned for")
+	flag.BoolVar(&c.EnableSPVServer, "enable-spv-server", c.EnableSPVServer, "Serve bloom filtered blocks and transaction proofs to light clients")
+	flag.IntVar(&c.ExecuteBlocksBatchSize, "execute-blocks-batch-size", c.ExecuteBlocksBatchSize, "Maximum number of received blocks to execute in a single database transaction")
 	flag.BoolVar(&c.LocalhostOnly, "localhost-only", c.LocalhostOnly, "Run on localhost and only connect to localhost peers")
-	flag.StringVar(&c.WalletCryptoType, "wallet-crypto-type", c.WalletCryptoType, "wallet crypto type. Can be sha256-xor or scrypt-chacha20poly1305")
+	flag.StringVar(&c.WalletCryptoType, "wallet-crypto-type", c.WalletCryptoType, "wallet crypto type. Can be sha256-xor, scrypt-chacha20poly1305 or scrypt-aes256gcm")
+	flag.StringVar(&c.HardwareWalletDaemonAddr, "hardware-wallet-daemon-addr", c.HardwareWalletDaemonAddr, "address of the Skycoin hardware wallet daemon, to sign hardware wallet transactions with the connected device. Disabled if empty")
 	flag.BoolVar(&c.Version, "version", false, "show node version")
 }
 
@@ -0,0 +1,84 @@
+package skycoin
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/api"
+)
+
+func TestBuildAPISets(t *testing.T) {
+	tt := []struct {
+		name    string
+		config  NodeConfig
+		apiSets []string
+		err     string
+	}{
+		{
+			name: "enabled and disabled api sets",
+			config: NodeConfig{
+				EnabledAPISets:  "READ,STATUS,WALLET",
+				DisabledAPISets: "WALLET",
+			},
+			apiSets: []string{api.EndpointsRead, api.EndpointsStatus},
+		},
+		{
+			name: "all api sets",
+			config: NodeConfig{
+				EnableAllAPISets: true,
+			},
+			apiSets: []string{
+				api.EndpointsAdmin,
+				api.EndpointsNetCtrl,
+				api.EndpointsPrometheus,
+				api.EndpointsRead,
+				api.EndpointsStatus,
+				api.EndpointsStorage,
+				api.EndpointsTransaction,
+				api.EndpointsWallet,
+			},
+		},
+		{
+			name: "read-only keeps only the query api sets",
+			config: NodeConfig{
+				EnableAllAPISets: true,
+				EnabledAPISets:   "INSECURE_WALLET_SEED",
+				ReadOnly:         true,
+			},
+			apiSets: []string{
+				api.EndpointsPrometheus,
+				api.EndpointsRead,
+				api.EndpointsStatus,
+			},
+		},
+		{
+			name: "invalid api set",
+			config: NodeConfig{
+				EnabledAPISets: "FOO",
+			},
+			err: `Invalid value in -enable-api-sets: "FOO"`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			apiSets, err := buildAPISets(tc.config)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+
+			var got []string
+			for k := range apiSets {
+				if k != "" {
+					got = append(got, k)
+				}
+			}
+			sort.Strings(got)
+			require.Equal(t, tc.apiSets, got)
+		})
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"runtime/pprof"
+	"strings"
 	"sync"
 	"time"
 
@@ -33,6 +34,7 @@ import (
 	"github.com/SkycoinProject/cx-chains/src/visor"
 	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
 	"github.com/SkycoinProject/cx-chains/src/wallet"
+	"github.com/SkycoinProject/cx-chains/src/wallet/hardware"
 )
 
 var (
@@ -59,6 +61,8 @@ func (c *Coin) Run() error {
 	var gw *api.Gateway
 	var webInterface *api.Server
 	var retErr error
+	var uncleanShutdown bool
+	var dbFlagged bool
 	errC := make(chan error, 10)
 
 	if c.config.Node.Version {
@@ -75,6 +79,32 @@ func (c *Coin) Run() error {
 
 	logging.SetLevel(logLevel)
 
+	if c.config.Node.LogModuleLevels != "" {
+		for _, ml := range strings.Split(c.config.Node.LogModuleLevels, ",") {
+			pts := strings.Split(strings.TrimSpace(ml), "=")
+			if len(pts) != 2 || pts[0] == "" {
+				err := fmt.Errorf("Invalid -log-module-levels: %q is not module=level", ml)
+				c.logger.Error(err)
+				return err
+			}
+
+			level, err := logging.LevelFromString(pts[1])
+			if err != nil {
+				err = fmt.Errorf("Invalid -log-module-levels: %v", err)
+				c.logger.Error(err)
+				return err
+			}
+
+			logging.SetModuleLevel(pts[0], level)
+		}
+	}
+
+	if err := logging.SetFormat(c.config.Node.LogFormat); err != nil {
+		err = fmt.Errorf("Invalid -log-format: %v", err)
+		c.logger.Error(err)
+		return err
+	}
+
 	if c.config.Node.ColorLog {
 		logging.EnableColors()
 	} else {
@@ -147,11 +177,16 @@ func (c *Coin) Run() error {
 	sconf := c.ConfigureStorage()
 
 	// Open the database
-	c.logger.Infof("Opening database %s", c.config.Node.DBPath)
-	db, err = visor.OpenDB(c.config.Node.DBPath, c.config.Node.DBReadOnly)
-	if err != nil {
-		c.logger.Errorf("Database failed to open: %v. Is another skycoin instance running?", err)
-		return err
+	if c.config.Node.InMemoryDB {
+		c.logger.Info("Using an in-memory database")
+		db = dbutil.NewMemoryDB()
+	} else {
+		c.logger.Infof("Opening database %s", c.config.Node.DBPath)
+		db, err = visor.OpenDB(c.config.Node.DBPath, c.config.Node.DBReadOnly)
+		if err != nil {
+			c.logger.Errorf("Database failed to open: %v. Is another skycoin instance running?", err)
+			return err
+		}
 	}
 
 	// Look for saved app version
@@ -179,8 +214,20 @@ func (c *Coin) Run() error {
 		goto earlyShutdown
 	}
 
-	// Verify the DB if the version detection says to, or if it was requested on the command line
-	if shouldVerifyDB(appVersion, dbVersion) || c.config.Node.VerifyDB {
+	// Verify the DB if it was not closed cleanly, e.g. after a crash or power loss
+	uncleanShutdown, err = visor.GetUncleanShutdown(db)
+	if err != nil {
+		c.logger.WithError(err).Error("visor.GetUncleanShutdown failed")
+		retErr = err
+		goto earlyShutdown
+	}
+
+	if uncleanShutdown {
+		c.logger.Warning("Database was not closed cleanly by the last shutdown")
+	}
+
+	// Verify the DB if the version detection says to, if it was not closed cleanly, or if it was requested on the command line
+	if shouldVerifyDB(appVersion, dbVersion) || uncleanShutdown || c.config.Node.VerifyDB {
 		if c.config.Node.ResetCorruptDB {
 			// Check the database integrity and recreate it if necessary
 			c.logger.Info("Checking database and resetting if corrupted")
@@ -212,6 +259,14 @@ func (c *Coin) Run() error {
 			retErr = err
 			goto earlyShutdown
 		}
+
+		// Flag the DB as open until the clean shutdown, so that a crash is detected on the next startup
+		if err := visor.SetUncleanShutdown(db, true); err != nil {
+			c.logger.WithError(err).Error("visor.SetUncleanShutdown failed")
+			retErr = err
+			goto earlyShutdown
+		}
+		dbFlagged = true
 	}
 
 	c.logger.Infof("Coinhour burn factor for user transactions is %d", params.UserVerifyTxn.BurnFactor)
@@ -232,6 +287,11 @@ func (c *Coin) Run() error {
 		goto earlyShutdown
 	}
 
+	if c.config.Node.HardwareWalletDaemonAddr != "" {
+		c.logger.Infof("Signing hardware wallet transactions with the hardware wallet daemon at %s", c.config.Node.HardwareWalletDaemonAddr)
+		v.SetHardwareDevice(hardware.NewDaemonDevice(c.config.Node.HardwareWalletDaemonAddr, hardware.DefaultDaemonTimeout))
+	}
+
 	d, err = daemon.New(dconf, v)
 	if err != nil {
 		c.logger.Error(err)
@@ -327,6 +387,21 @@ func (c *Coin) Run() error {
 	wg.Wait()
 
 earlyShutdown:
+	// Stop accepting blocks and transactions and flush the database before closing it
+	if v != nil {
+		c.logger.Info("Closing visor")
+		if err := v.Shutdown(); err != nil {
+			c.logger.WithError(err).Error("Failed to shut down visor")
+			dbFlagged = false
+		}
+	}
+
+	if dbFlagged {
+		if err := visor.SetUncleanShutdown(db, false); err != nil {
+			c.logger.WithError(err).Error("visor.SetUncleanShutdown failed")
+		}
+	}
+
 	if db != nil {
 		c.logger.Info("Closing database")
 		if err := db.Close(); err != nil {
@@ -371,6 +446,13 @@ func (c *Coin) initLogFile() (*os.File, error) {
 	}
 
 	hook := logging.NewWriteHook(f)
+	if c.config.Node.LogFormat != logging.FormatText {
+		formatter, err := logging.NewFormatter(c.config.Node.LogFormat)
+		if err != nil {
+			return nil, err
+		}
+		hook = logging.NewWriteHookWithFormatter(f, formatter)
+	}
 	logging.AddHook(hook)
 
 	return f, nil
@@ -381,6 +463,12 @@ func (c *Coin) ConfigureVisor() visor.Config {
 	vc := visor.NewConfig()
 
 	vc.Distribution = params.MainNetDistribution
+	if c.config.Node.distribution != nil {
+		vc.Distribution = *c.config.Node.distribution
+	}
+
+	vc.Chain = c.config.Node.Chain
+	vc.GenesisHash = c.config.Node.genesisHash
 
 	vc.IsBlockPublisher = c.config.Node.RunBlockPublisher
 	vc.Arbitrating = c.config.Node.RunBlockPublisher
@@ -389,8 +477,27 @@ func (c *Coin) ConfigureVisor() visor.Config {
 	vc.BlockchainSeckey = c.config.Node.blockchainSeckey
 
 	vc.UnconfirmedVerifyTxn = c.config.Node.UnconfirmedVerifyTxn
+	vc.UnconfirmedPolicy = visor.UnconfirmedPoolPolicy{
+		MaxSize:     c.config.Node.MaxUnconfirmedPoolSize,
+		MinFeePerKB: c.config.Node.UnconfirmedMinFeePerKB,
+	}
 	vc.CreateBlockVerifyTxn = c.config.Node.CreateBlockVerifyTxn
 	vc.MaxBlockTransactionsSize = c.config.Node.MaxBlockTransactionsSize
+	vc.BlockPolicy = visor.BlockPolicy{
+		MaxTransactions: c.config.Node.MaxBlockTransactions,
+		Ordering:        visor.TxnOrdering(c.config.Node.BlockTxnOrdering),
+		AllowAddresses:  c.config.Node.blockAllowAddresses,
+		DenyAddresses:   c.config.Node.blockDenyAddresses,
+	}
+	vc.Devnet = visor.DevnetConfig{
+		Enabled:   c.config.Node.Devnet,
+		Addresses: c.config.Node.devnetAddresses,
+		Coins:     c.config.Node.DevnetCoins,
+	}
+
+	vc.Checkpoints = c.config.Node.checkpoints
+	vc.VerifyCheckpointedSignatures = c.config.Node.VerifyCheckpointedSignatures
+	vc.BlockCacheSize = c.config.Node.BlockCacheSize
 
 	vc.GenesisAddress = c.config.Node.genesisAddress
 	vc.GenesisSignature = c.config.Node.genesisSignature
@@ -445,6 +552,8 @@ func (c *Coin) ConfigureDaemon() daemon.Config {
 	dc.Pex.Max = c.config.Node.PeerlistSize
 	dc.Pex.DownloadPeerList = c.config.Node.DownloadPeerList
 	dc.Pex.PeerListURL = c.config.Node.PeerListURL
+	dc.Pex.DNSSeeds = c.config.Node.dnsSeeds
+	dc.Pex.DNSSeedPort = c.config.Node.Port
 	dc.Pex.DisableTrustedPeers = c.config.Node.DisableDefaultPeers
 	dc.Pex.CustomPeersFile = c.config.Node.CustomPeersFile
 	dc.Pex.DefaultConnections = c.config.Node.DefaultConnections
@@ -452,15 +561,25 @@ func (c *Coin) ConfigureDaemon() daemon.Config {
 	dc.Daemon.MaxOutgoingMessageLength = uint64(c.config.Node.MaxOutgoingMessageLength)
 	dc.Daemon.MaxIncomingMessageLength = uint64(c.config.Node.MaxIncomingMessageLength)
 	dc.Daemon.MaxBlockTransactionsSize = c.config.Node.MaxBlockTransactionsSize
+	dc.Daemon.ExecuteBlocksBatchSize = c.config.Node.ExecuteBlocksBatchSize
 	dc.Daemon.DefaultConnections = c.config.Node.DefaultConnections
 	dc.Daemon.DisableOutgoingConnections = c.config.Node.DisableOutgoingConnections
 	dc.Daemon.DisableIncomingConnections = c.config.Node.DisableIncomingConnections
+	dc.Daemon.DisableIncomingIPv4 = c.config.Node.DisableIncomingIPv4
+	dc.Daemon.DisableIncomingIPv6 = c.config.Node.DisableIncomingIPv6
+	dc.Daemon.Proxy = c.config.Node.Proxy
 	dc.Daemon.DisableNetworking = c.config.Node.DisableNetworking
+	dc.Daemon.Devnet = c.config.Node.Devnet
 	dc.Daemon.Port = c.config.Node.Port
 	dc.Daemon.Address = c.config.Node.Address
 	dc.Daemon.LocalhostOnly = c.config.Node.LocalhostOnly
 	dc.Daemon.MaxConnections = c.config.Node.MaxConnections
 	dc.Daemon.MaxOutgoingConnections = c.config.Node.MaxOutgoingConnections
+	dc.Daemon.MaxIncomingConnections = c.config.Node.MaxIncomingConnections
+	dc.Daemon.ProtectedConnections = c.config.Node.ProtectedConnections
+	dc.Daemon.BanScoreThreshold = c.config.Node.BanScoreThreshold
+	dc.Daemon.BanDuration = c.config.Node.BanDuration
+	dc.Daemon.EnableSPVServer = c.config.Node.EnableSPVServer
 	dc.Daemon.DataDirectory = c.config.Node.DataDirectory
 	dc.Daemon.LogPings = !c.config.Node.DisablePingPong
 	dc.Daemon.BlockchainPubkey = c.config.Node.blockchainPubkey
@@ -497,8 +616,29 @@ func (c *Coin) createGUI(gw *api.Gateway, host string) (*api.Server, error) {
 			Fiber:           c.config.Node.Fiber,
 			DaemonUserAgent: c.config.Node.userAgent,
 		},
-		Username: c.config.Node.WebInterfaceUsername,
-		Password: c.config.Node.WebInterfacePassword,
+		Username:         c.config.Node.WebInterfaceUsername,
+		Password:         c.config.Node.WebInterfacePassword,
+		APIToken:         c.config.Node.WebInterfaceAPIToken,
+		AuthMutatingOnly: c.config.Node.WebInterfaceAuthMutatingOnly,
+		APIKeys:          c.config.Node.apiKeys,
+		GRPCAddr:         c.config.Node.GRPCAddr,
+		WebhooksFile:     c.config.Node.WebhooksFile,
+
+		RateLimit:               c.config.Node.WebInterfaceRateLimit,
+		RateLimitBurst:          c.config.Node.WebInterfaceRateLimitBurst,
+		ExpensiveRateLimit:      c.config.Node.WebInterfaceExpensiveRateLimit,
+		ExpensiveRateLimitBurst: c.config.Node.WebInterfaceExpensiveRateLimitBurst,
+	}
+
+	if c.config.Node.WebInterfaceSignResponses {
+		seckey, err := loadSigningKey(c.config.Node.WebInterfaceSigningKeyFile)
+		if err != nil {
+			c.logger.Errorf("loadSigningKey failed: %v", err)
+			return nil, err
+		}
+
+		config.SigningKey = seckey
+		c.logger.Infof("Signing web interface responses with the key of public key %s", cipher.MustPubKeyFromSecKey(seckey).Hex())
 	}
 
 	var s *api.Server
@@ -510,6 +650,12 @@ func (c *Coin) createGUI(gw *api.Gateway, host string) (*api.Server, error) {
 			return nil, err
 		}
 
+		if !exists && !c.config.Node.WebInterfaceAutogenCert {
+			err := fmt.Errorf("cert file %s and key file %s do not exist. Use -web-interface-autogen-cert=true to generate a self-signed certificate", c.config.Node.WebInterfaceCert, c.config.Node.WebInterfaceKey)
+			c.logger.Error(err)
+			return nil, err
+		}
+
 		if !exists {
 			c.logger.Infof("Autogenerating HTTP certificate and key files %s, %s", c.config.Node.WebInterfaceCert, c.config.Node.WebInterfaceKey)
 			if err := createCertFiles(c.config.Node.WebInterfaceCert, c.config.Node.WebInterfaceKey); err != nil {
@@ -595,6 +741,25 @@ func createCertFiles(certFile, keyFile string) error {
 	return nil
 }
 
+// loadSigningKey reads the hex encoded secret key of the web interface response signatures from a file.
+// A new key is generated and written to the file if it does not exist.
+func loadSigningKey(keyFile string) (cipher.SecKey, error) {
+	data, err := ioutil.ReadFile(keyFile)
+	if err == nil {
+		return cipher.SecKeyFromHex(strings.TrimSpace(string(data)))
+	}
+	if !os.IsNotExist(err) {
+		return cipher.SecKey{}, err
+	}
+
+	_, seckey := cipher.GenerateKeyPair()
+	if err := ioutil.WriteFile(keyFile, []byte(seckey.Hex()+"\n"), 0600); err != nil {
+		return cipher.SecKey{}, err
+	}
+
+	return seckey, nil
+}
+
 // ParseConfig prepare the config
 func (c *Coin) ParseConfig() error {
 	return c.config.postProcess()
@@ -16,8 +16,19 @@ import (
 	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
 )
 
-// PrepareDB creates and opens a temporary test DB and returns it with a cleanup callback
+// PrepareDB creates an in-memory test DB and returns it with a cleanup callback
 func PrepareDB(t *testing.T) (*dbutil.DB, func()) {
+	db := dbutil.NewMemoryDB()
+
+	return db, func() {
+		if err := db.Close(); err != nil {
+			t.Logf("Failed to close database: %v", err)
+		}
+	}
+}
+
+// PrepareFileDB creates and opens a temporary bolt test DB file and returns it with a cleanup callback
+func PrepareFileDB(t *testing.T) (*dbutil.DB, func()) {
 	f, err := ioutil.TempFile("", "testdb")
 	require.NoError(t, err)
 
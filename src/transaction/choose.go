@@ -2,7 +2,9 @@ package transaction
 
 import (
 	"bytes"
+	"encoding/binary"
 	"errors"
+	"math/rand"
 	"sort"
 
 	"github.com/SkycoinProject/cx-chains/src/cipher"
@@ -20,6 +22,8 @@ var (
 	ErrZeroSpend = NewError(errors.New("zero spend amount"))
 	// ErrNoUnspents is returned if a Create is called with no unspent outputs
 	ErrNoUnspents = NewError(errors.New("no unspents to spend"))
+	// ErrTooManyInputs is returned if more than Params.MaxInputs uxouts are needed for a spend
+	ErrTooManyInputs = NewError(errors.New("spend requires more inputs than max inputs"))
 )
 
 // UxBalance is an intermediate representation of a UxOut for sorting and spend choosing
@@ -180,6 +184,204 @@ func cmpUxBalanceByUxID(a, b UxBalance) bool {
 	return cmp < 0
 }
 
+// ChooseSpendsWithStrategy chooses uxout spends to satisfy an amount with a coin selection strategy.
+// An empty strategy is CoinSelectionMinimizeInputs.
+// If maxInputs is not 0 and the strategy chooses more than maxInputs uxouts, the uxouts with the most coins
+// are chosen instead, up to maxInputs of them. ErrTooManyInputs is returned if they cannot satisfy the amount.
+func ChooseSpendsWithStrategy(strategy string, uxa []UxBalance, coins, hours, maxInputs uint64) ([]UxBalance, error) {
+	var spends []UxBalance
+	var err error
+	switch strategy {
+	case CoinSelectionMinimizeInputs, "":
+		spends, err = ChooseSpendsMinimizeUxOuts(uxa, coins, hours)
+	case CoinSelectionMinimizeChange:
+		spends, err = ChooseSpendsMinimizeChange(uxa, coins, hours)
+	case CoinSelectionOldestFirst:
+		spends, err = ChooseSpendsOldestFirst(uxa, coins, hours)
+	case CoinSelectionRandom:
+		spends, err = ChooseSpendsRandom(uxa, coins, hours)
+	default:
+		return nil, ErrInvalidCoinSelection
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if maxInputs == 0 || uint64(len(spends)) <= maxInputs {
+		return spends, nil
+	}
+
+	// The strategy needs too many uxouts, fall back to choosing the uxouts with the most coins first
+	return chooseSpendsInOrder(uxa, coins, hours, maxInputs, sortSpendsCoinsHighToLow)
+}
+
+// ChooseSpendsMinimizeChange chooses uxout spends to satisfy an amount, leaving the least change
+//     -- PRO: Avoids creating many small change outputs, which have to be spent later.
+//     -- CON: May use more uxouts than ChooseSpendsMinimizeUxOuts.
+// If a single uxout can satisfy the amount, the one with the least coins is chosen.
+// Otherwise, uxouts are chosen with the highest balance first, and the last uxout chosen is
+// replaced by the uxout with the least coins which still satisfies the amount.
+func ChooseSpendsMinimizeChange(uxa []UxBalance, coins, hours uint64) ([]UxBalance, error) {
+	if err := checkSpends(uxa, coins); err != nil {
+		return nil, err
+	}
+
+	sorted := make([]UxBalance, len(uxa))
+	copy(sorted, uxa)
+	sortSpendsCoinsHighToLow(sorted)
+
+	// Find the uxout with the least coins which satisfies the amount on its own
+	for i := len(sorted) - 1; i >= 0; i-- {
+		if spendsSatisfy(sorted[i:i+1], coins, hours) {
+			return []UxBalance{sorted[i]}, nil
+		}
+	}
+
+	// Choose uxouts with the highest balance first, until the amount would be satisfied by the next one
+	var spending []UxBalance
+	for i := range sorted {
+		spending = sorted[:i+1]
+		if !spendsSatisfy(spending, coins, hours) {
+			continue
+		}
+
+		// Replace the last uxout with the smallest remaining uxout which still satisfies the amount
+		prefix := sorted[:i]
+		for j := len(sorted) - 1; j > i; j-- {
+			candidate := append(append([]UxBalance{}, prefix...), sorted[j])
+			if spendsSatisfy(candidate, coins, hours) {
+				return candidate, nil
+			}
+		}
+
+		return append([]UxBalance{}, spending...), nil
+	}
+
+	return nil, insufficientSpendsError(spending, coins)
+}
+
+// ChooseSpendsOldestFirst chooses uxout spends to satisfy an amount, using the oldest uxouts first
+//     -- PRO: Old uxouts have accumulated the most coin hours, so this maximizes the coin hours collected by the spend.
+//     -- CON: Ignores the number of uxouts and the change left over.
+func ChooseSpendsOldestFirst(uxa []UxBalance, coins, hours uint64) ([]UxBalance, error) {
+	return chooseSpendsInOrder(uxa, coins, hours, 0, sortSpendsOldestFirst)
+}
+
+// sortSpendsOldestFirst sorts uxout spends with the oldest first
+func sortSpendsOldestFirst(uxa []UxBalance) {
+	sort.Slice(uxa, func(i, j int) bool {
+		a := uxa[i]
+		b := uxa[j]
+
+		if a.BkSeq == b.BkSeq {
+			if a.Coins == b.Coins {
+				return cmpUxBalanceByUxID(a, b)
+			}
+			return a.Coins > b.Coins
+		}
+		return a.BkSeq < b.BkSeq
+	})
+}
+
+// ChooseSpendsRandom chooses uxout spends to satisfy an amount, in a random order
+//     -- PRO: The uxouts spent together do not follow a pattern that links the addresses of a wallet.
+//     -- CON: The number of uxouts and the change left over are unpredictable.
+func ChooseSpendsRandom(uxa []UxBalance, coins, hours uint64) ([]UxBalance, error) {
+	return chooseSpendsInOrder(uxa, coins, hours, 0, shuffleSpends)
+}
+
+// shuffleSpends shuffles uxout spends, with a generator seeded from a secure random source
+func shuffleSpends(uxa []UxBalance) {
+	seed := int64(binary.LittleEndian.Uint64(cipher.RandByte(8)))
+	r := rand.New(rand.NewSource(seed)) // nolint: gosec
+	r.Shuffle(len(uxa), func(i, j int) {
+		uxa[i], uxa[j] = uxa[j], uxa[i]
+	})
+}
+
+// chooseSpendsInOrder chooses uxouts in the order given by sortStrategy, until the amount is satisfied.
+// If maxInputs is not 0, ErrTooManyInputs is returned if the amount is not satisfied by the first maxInputs uxouts
+func chooseSpendsInOrder(uxa []UxBalance, coins, hours, maxInputs uint64, sortStrategy func([]UxBalance)) ([]UxBalance, error) {
+	if err := checkSpends(uxa, coins); err != nil {
+		return nil, err
+	}
+
+	sorted := make([]UxBalance, len(uxa))
+	copy(sorted, uxa)
+	sortStrategy(sorted)
+
+	for i := range sorted {
+		if maxInputs != 0 && uint64(i) >= maxInputs {
+			if spendsSatisfy(sorted, coins, hours) {
+				return nil, ErrTooManyInputs
+			}
+			break
+		}
+
+		if spendsSatisfy(sorted[:i+1], coins, hours) {
+			return sorted[:i+1], nil
+		}
+	}
+
+	return nil, insufficientSpendsError(sorted, coins)
+}
+
+// checkSpends checks the arguments common to the coin selection strategies
+func checkSpends(uxa []UxBalance, coins uint64) error {
+	if coins == 0 {
+		return ErrZeroSpend
+	}
+
+	if len(uxa) == 0 {
+		return ErrNoUnspents
+	}
+
+	hasHours := false
+	for _, ux := range uxa {
+		if ux.Coins == 0 {
+			logger.Panic("UxOut coins are 0, can't spend")
+			return errors.New("UxOut coins are 0, can't spend")
+		}
+		if ux.Hours != 0 {
+			hasHours = true
+		}
+	}
+
+	// Abort if there are no uxouts with non-zero coinhours, they can't be spent yet
+	if !hasHours {
+		return fee.ErrTxnNoFee
+	}
+
+	return nil
+}
+
+// spendsSatisfy returns true if the uxouts have enough coins, and enough hours to pay the fee and the requested hours
+func spendsSatisfy(uxa []UxBalance, coins, hours uint64) bool {
+	var haveCoins uint64
+	var haveHours uint64
+	for _, ux := range uxa {
+		haveCoins += ux.Coins
+		haveHours += ux.Hours
+	}
+
+	return haveCoins >= coins && haveHours > 0 && fee.RemainingHours(haveHours, params.UserVerifyTxn.BurnFactor) >= hours
+}
+
+// insufficientSpendsError returns the error for uxouts which cannot satisfy an amount
+func insufficientSpendsError(uxa []UxBalance, coins uint64) error {
+	var haveCoins uint64
+	for _, ux := range uxa {
+		haveCoins += ux.Coins
+	}
+
+	if haveCoins < coins {
+		return ErrInsufficientBalance
+	}
+
+	return ErrInsufficientHours
+}
+
 // ChooseSpends chooses uxouts from a list of uxouts.
 // It first chooses the uxout with the most number of coins that has nonzero coinhours.
 // It then chooses uxouts with zero coinhours, ordered by sortStrategy
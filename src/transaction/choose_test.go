@@ -388,11 +388,11 @@ func verifyChosenCoins(t *testing.T, uxb []UxBalance, coins uint64, chooseSpends
 	require.NotEqual(t, 0, len(chosen))
 
 	// Check that there are no duplicated spends chosen
-	uxMap := make(map[UxBalance]struct{}, len(chosen))
+	uxMap := make(map[cipher.SHA256]struct{}, len(chosen))
 	for _, ux := range chosen {
-		_, ok := uxMap[ux]
+		_, ok := uxMap[ux.Hash]
 		require.False(t, ok)
-		uxMap[ux] = struct{}{}
+		uxMap[ux.Hash] = struct{}{}
 	}
 
 	// The first chosen spend should have non-zero coin hours
@@ -532,3 +532,264 @@ func verifySortedHoursLowToHigh(t *testing.T, uxb []UxBalance) {
 		return a.Hours <= b.Hours
 	})
 }
+
+func TestChooseSpendsMinimizeChange(t *testing.T) {
+	h := func() cipher.SHA256 {
+		return testutil.RandSHA256(t)
+	}
+
+	uxb := []UxBalance{
+		{Hash: h(), BkSeq: 1, Coins: 50, Hours: 10},
+		{Hash: h(), BkSeq: 2, Coins: 30, Hours: 10},
+		{Hash: h(), BkSeq: 3, Coins: 12, Hours: 10},
+		{Hash: h(), BkSeq: 4, Coins: 7, Hours: 10},
+		{Hash: h(), BkSeq: 5, Coins: 3, Hours: 0},
+	}
+
+	cases := []struct {
+		name   string
+		coins  uint64
+		chosen []UxBalance
+		err    error
+	}{
+		{
+			name:   "exact single uxout",
+			coins:  12,
+			chosen: []UxBalance{uxb[2]},
+		},
+		{
+			name:   "smallest single uxout",
+			coins:  25,
+			chosen: []UxBalance{uxb[1]},
+		},
+		{
+			name:   "zero hours uxout alone is not chosen",
+			coins:  3,
+			chosen: []UxBalance{uxb[3]},
+		},
+		{
+			name:   "replace last uxout with the smallest satisfying uxout",
+			coins:  60,
+			chosen: []UxBalance{uxb[0], uxb[2]},
+		},
+		{
+			name:   "all uxouts",
+			coins:  102,
+			chosen: []UxBalance{uxb[0], uxb[1], uxb[2], uxb[3], uxb[4]},
+		},
+		{
+			name:  "insufficient balance",
+			coins: 103,
+			err:   ErrInsufficientBalance,
+		},
+		{
+			name:  "zero spend",
+			coins: 0,
+			err:   ErrZeroSpend,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			chosen, err := ChooseSpendsMinimizeChange(uxb, tc.coins, 0)
+			require.Equal(t, tc.err, err)
+			if err != nil {
+				return
+			}
+
+			require.Equal(t, tc.chosen, chosen)
+		})
+	}
+
+	_, err := ChooseSpendsMinimizeChange(nil, 10, 0)
+	require.Equal(t, ErrNoUnspents, err)
+
+	_, err = ChooseSpendsMinimizeChange([]UxBalance{{Hash: h(), Coins: 10}}, 10, 0)
+	require.Equal(t, fee.ErrTxnNoFee, err)
+
+	// Requested hours which cannot be met
+	_, err = ChooseSpendsMinimizeChange(uxb, 10, 1000)
+	require.Equal(t, ErrInsufficientHours, err)
+}
+
+func TestChooseSpendsOldestFirst(t *testing.T) {
+	nRand := 1000
+	for i := 0; i < nRand; i++ {
+		coins := uint64((rand.Intn(3)+1)*10 + rand.Intn(3)) // 10,20,30 + 0,1,2
+		uxb := makeRandomUxBalances(t)
+
+		chosen, err := ChooseSpendsOldestFirst(uxb, coins, 0)
+		if err != nil {
+			require.True(t, err == ErrNoUnspents || err == fee.ErrTxnNoFee || err == ErrInsufficientBalance || err == ErrInsufficientHours, err)
+			continue
+		}
+
+		requireChosenSpends(t, uxb, chosen, coins)
+
+		// The chosen spends are the oldest
+		for j := 1; j < len(chosen); j++ {
+			require.True(t, chosen[j-1].BkSeq <= chosen[j].BkSeq)
+		}
+		maxBkSeq := chosen[len(chosen)-1].BkSeq
+		chosenMap := make(map[cipher.SHA256]struct{}, len(chosen))
+		for _, ux := range chosen {
+			chosenMap[ux.Hash] = struct{}{}
+		}
+		for _, ux := range uxb {
+			if _, ok := chosenMap[ux.Hash]; !ok {
+				require.True(t, ux.BkSeq >= maxBkSeq)
+			}
+		}
+	}
+}
+
+func TestChooseSpendsRandom(t *testing.T) {
+	uxb := make([]UxBalance, 10)
+	for i := range uxb {
+		uxb[i] = UxBalance{
+			Hash:  testutil.RandSHA256(t),
+			BkSeq: uint64(i),
+			Coins: 10,
+			Hours: 10,
+		}
+	}
+	orig := make([]UxBalance, len(uxb))
+	copy(orig, uxb)
+
+	seen := make(map[cipher.SHA256]struct{})
+	for i := 0; i < 100; i++ {
+		chosen, err := ChooseSpendsRandom(uxb, 25, 0)
+		require.NoError(t, err)
+		require.Len(t, chosen, 3)
+		requireChosenSpends(t, uxb, chosen, 25)
+
+		for _, ux := range chosen {
+			seen[ux.Hash] = struct{}{}
+		}
+	}
+
+	// The uxouts are chosen in a random order
+	require.True(t, len(seen) > 3)
+	// The caller's uxouts are not reordered
+	require.Equal(t, orig, uxb)
+
+	_, err := ChooseSpendsRandom(uxb, 101, 0)
+	require.Equal(t, ErrInsufficientBalance, err)
+}
+
+func TestChooseSpendsWithStrategy(t *testing.T) {
+	uxb := []UxBalance{
+		{Hash: testutil.RandSHA256(t), BkSeq: 3, Coins: 50, Hours: 10},
+		{Hash: testutil.RandSHA256(t), BkSeq: 1, Coins: 30, Hours: 10},
+		{Hash: testutil.RandSHA256(t), BkSeq: 2, Coins: 12, Hours: 10},
+	}
+
+	cases := []struct {
+		strategy string
+		chosen   []UxBalance
+		err      error
+	}{
+		{
+			strategy: "",
+			chosen:   []UxBalance{uxb[0]},
+		},
+		{
+			strategy: CoinSelectionMinimizeInputs,
+			chosen:   []UxBalance{uxb[0]},
+		},
+		{
+			strategy: CoinSelectionMinimizeChange,
+			chosen:   []UxBalance{uxb[1]},
+		},
+		{
+			strategy: CoinSelectionOldestFirst,
+			chosen:   []UxBalance{uxb[1]},
+		},
+		{
+			strategy: "largest_first",
+			err:      ErrInvalidCoinSelection,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.strategy, func(t *testing.T) {
+			chosen, err := ChooseSpendsWithStrategy(tc.strategy, uxb, 20, 0, 0)
+			require.Equal(t, tc.err, err)
+			if err != nil {
+				return
+			}
+
+			require.Equal(t, tc.chosen, chosen)
+		})
+	}
+
+	chosen, err := ChooseSpendsWithStrategy(CoinSelectionRandom, uxb, 20, 0, 0)
+	require.NoError(t, err)
+	requireChosenSpends(t, uxb, chosen, 20)
+}
+
+func TestChooseSpendsWithStrategyMaxInputs(t *testing.T) {
+	uxb := []UxBalance{
+		{Hash: testutil.RandSHA256(t), BkSeq: 1, Coins: 10, Hours: 10},
+		{Hash: testutil.RandSHA256(t), BkSeq: 2, Coins: 10, Hours: 10},
+		{Hash: testutil.RandSHA256(t), BkSeq: 3, Coins: 10, Hours: 10},
+		{Hash: testutil.RandSHA256(t), BkSeq: 4, Coins: 60, Hours: 10},
+	}
+
+	// The oldest first strategy needs all 4 uxouts, but the uxout with the most coins satisfies the amount on its own
+	chosen, err := ChooseSpendsWithStrategy(CoinSelectionOldestFirst, uxb, 55, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, chosen, 4)
+
+	for _, maxInputs := range []uint64{1, 2, 3} {
+		chosen, err = ChooseSpendsWithStrategy(CoinSelectionOldestFirst, uxb, 55, 0, maxInputs)
+		require.NoError(t, err)
+		require.Equal(t, []UxBalance{uxb[3]}, chosen)
+	}
+
+	// The uxouts with the most coins need 2 inputs
+	chosen, err = ChooseSpendsWithStrategy(CoinSelectionOldestFirst, uxb, 65, 0, 2)
+	require.NoError(t, err)
+	require.Len(t, chosen, 2)
+	require.Equal(t, uxb[3], chosen[0])
+
+	_, err = ChooseSpendsWithStrategy(CoinSelectionOldestFirst, uxb, 65, 0, 1)
+	require.Equal(t, ErrTooManyInputs, err)
+
+	// The limit does not change an insufficient balance error
+	_, err = ChooseSpendsWithStrategy(CoinSelectionOldestFirst, uxb, 100, 0, 1)
+	require.Equal(t, ErrInsufficientBalance, err)
+
+	// A strategy which chooses no more than maxInputs uxouts is unchanged
+	chosen, err = ChooseSpendsWithStrategy(CoinSelectionOldestFirst, uxb, 15, 0, 2)
+	require.NoError(t, err)
+	require.Equal(t, []UxBalance{uxb[0], uxb[1]}, chosen)
+}
+
+// requireChosenSpends checks that the chosen spends are unique uxouts of uxb which satisfy coins,
+// and that no chosen spend is unnecessary
+func requireChosenSpends(t *testing.T, uxb, chosen []UxBalance, coins uint64) {
+	uxbMap := make(map[cipher.SHA256]struct{}, len(uxb))
+	for _, ux := range uxb {
+		uxbMap[ux.Hash] = struct{}{}
+	}
+
+	chosenMap := make(map[cipher.SHA256]struct{}, len(chosen))
+	var haveCoins, haveHours uint64
+	for _, ux := range chosen {
+		_, ok := uxbMap[ux.Hash]
+		require.True(t, ok)
+		_, ok = chosenMap[ux.Hash]
+		require.False(t, ok)
+		chosenMap[ux.Hash] = struct{}{}
+
+		haveCoins += ux.Coins
+		haveHours += ux.Hours
+	}
+
+	require.True(t, haveCoins >= coins)
+	require.NotEqual(t, uint64(0), haveHours)
+
+	// The spends before the last do not satisfy the amount
+	require.False(t, spendsSatisfy(chosen[:len(chosen)-1], coins, 0))
+}
@@ -31,16 +31,99 @@ var (
 // NOTE: Caller must ensure that auxs correspond to params.UxOuts options
 // Outputs to spend are chosen from the pool of outputs provided.
 // The outputs are chosen by the following procedure:
-//   - All outputs are merged into one list and are sorted coins highest, hours lowest, with the hash as a tiebreaker
+//   - All outputs are merged into one list and are chosen by the coin selection strategy of Params.CoinSelection.
+//     The default strategy sorts them coins highest, hours lowest, with the hash as a tiebreaker
 //   - Outputs are chosen from the beginning of this list, until the requested amount of coins is met.
 //     If hours are also specified, selection continues until the requested amount of hours are met.
+//   - If the strategy chooses more outputs than Params.MaxInputs, the outputs with the most coins are chosen instead,
+//     up to Params.MaxInputs of them. If these cannot satisfy the requested amount, ErrTooManyInputs is returned
 //   - If the total amount of coins in the chosen outputs is exactly equal to the requested amount of coins,
 //     such that there would be no change output but hours remain as change, another output will be chosen to create change,
 //     if the coinhour cost of adding that output is less than the coinhours that would be lost as change
 // If receiving hours are not explicitly specified, hours are allocated amongst the receiving outputs proportional to the number of coins being sent to them.
 // If the change address is not specified, the address whose bytes are lexically sorted first is chosen from the owners of the outputs being spent.
+// If Params.BurnChangeHours is set, the change output has no hours and the hours left after the fee and the outputs are burned.
+// If Params.FeePerKB is set and the fee of the transaction is below it, the transaction is created again
+// with the missing hours added to Params.ExtraFee, until its fee meets Params.FeePerKB.
 func Create(p Params, auxs coin.AddressUxOuts, headTime uint64, mainExprs []byte) (*coin.Transaction, []UxBalance, error) {
-	return create(p, auxs, headTime, 0, mainExprs)
+	txn, spends, err := create(p, auxs, headTime, 0, mainExprs)
+	if err != nil || p.FeePerKB == 0 {
+		return txn, spends, err
+	}
+
+	// Raising the extra fee may choose more inputs, which makes the transaction larger,
+	// so the fee is checked again after each attempt
+	for i := 0; i < maxFeePerKBAttempts; i++ {
+		missing, err := missingFeePerKBHours(txn, spends, p.FeePerKB)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if missing == 0 {
+			return txn, spends, nil
+		}
+
+		p.ExtraFee, err = mathutil.AddUint64(p.ExtraFee, missing)
+		if err != nil {
+			return nil, nil, NewError(fmt.Errorf("extra fee error: %v", err))
+		}
+
+		txn, spends, err = create(p, auxs, headTime, 0, mainExprs)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return nil, nil, ErrFeePerKBNotMet
+}
+
+// maxFeePerKBAttempts is the number of times Create raises the extra fee to meet Params.FeePerKB
+const maxFeePerKBAttempts = 5
+
+// missingFeePerKBHours returns the number of coin hours that txn must burn in addition to its fee
+// for its fee per kilobyte to be at least feePerKB
+func missingFeePerKBHours(txn *coin.Transaction, spends []UxBalance, feePerKB uint64) (uint64, error) {
+	size, err := txn.Size()
+	if err != nil {
+		return 0, err
+	}
+
+	var inputHours uint64
+	for _, s := range spends {
+		inputHours, err = mathutil.AddUint64(inputHours, s.Hours)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	outputHours, err := txn.OutputHours()
+	if err != nil {
+		return 0, err
+	}
+
+	if outputHours > inputHours {
+		return 0, fee.ErrTxnInsufficientCoinHours
+	}
+
+	feeHours := inputHours - outputHours
+
+	required, err := mathutil.MultUint64(feePerKB, uint64(size))
+	if err != nil {
+		return 0, NewError(fmt.Errorf("fee per kilobyte error: %v", err))
+	}
+
+	// Round up, since the fee per kilobyte of a transaction is rounded down
+	if required%1000 == 0 {
+		required /= 1000
+	} else {
+		required = required/1000 + 1
+	}
+
+	if feeHours >= required {
+		return 0, nil
+	}
+
+	return required - feeHours, nil
 }
 
 func create(p Params, auxs coin.AddressUxOuts, headTime uint64, callCount int, mainExprs []byte) (*coin.Transaction, []UxBalance, error) {
@@ -84,10 +167,16 @@ func create(p Params, auxs coin.AddressUxOuts, headTime uint64, callCount int, m
 		}
 	}
 
-	// Use the MinimizeUxOuts strategy, to use least possible uxouts
-	// this will allow more frequent spending
+	// Use the requested coin selection strategy, by default the MinimizeUxOuts strategy,
+	// to use least possible uxouts; this will allow more frequent spending
 	// we don't need to check whether we have sufficient balance beforehand as ChooseSpends already checks that
-	spends, err := ChooseSpendsMinimizeUxOuts(uxb, totalOutCoins, requestedHours)
+	// The extra fee is burned, so the spends must cover it in addition to the requested hours
+	requestedHours, err = mathutil.AddUint64(requestedHours, p.ExtraFee)
+	if err != nil {
+		return nil, nil, NewError(fmt.Errorf("total output hours error: %v", err))
+	}
+
+	spends, err := ChooseSpendsWithStrategy(p.CoinSelection, uxb, totalOutCoins, requestedHours, p.MaxInputs)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -120,6 +209,15 @@ func create(p Params, auxs coin.AddressUxOuts, headTime uint64, callCount int, m
 		logger.Critical().WithError(err).WithField("totalInputHours", totalInputHours).Error()
 		return nil, nil, err
 	}
+
+	feeHours, err = mathutil.AddUint64(feeHours, p.ExtraFee)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if feeHours > totalInputHours {
+		return nil, nil, fee.ErrTxnInsufficientCoinHours
+	}
 	remainingHours := totalInputHours - feeHours
 
 	switch p.HoursSelection.Type {
@@ -190,6 +288,11 @@ func create(p Params, auxs coin.AddressUxOuts, headTime uint64, callCount int, m
 	changeCoins := totalInputCoins - totalOutCoins
 	changeHours := remainingHours - totalOutHours
 
+	// Burned change hours are added to the fee, so there are no change hours to recover below
+	if p.BurnChangeHours {
+		changeHours = 0
+	}
+
 	// If there are no change coins but there are change hours, try to add another
 	// input to save the change hours.
 	// This chooses an available input with the least number of coin hours;
@@ -202,7 +305,9 @@ func create(p Params, auxs coin.AddressUxOuts, headTime uint64, callCount int, m
 		// Update changeCoins and changeHours
 		z := uxBalancesSub(uxb, spends)
 		sortSpendsHoursLowToHigh(z)
-		if len(z) > 0 {
+		if p.MaxInputs != 0 && uint64(len(spends)) >= p.MaxInputs {
+			logger.Debug("Unable to recover change hours, the spend already has the maximum number of inputs")
+		} else if len(z) > 0 {
 			logger.Debug("Extra input found, evaluating if it can recover change hours")
 			extra := z[0]
 
@@ -213,7 +318,7 @@ func create(p Params, auxs coin.AddressUxOuts, headTime uint64, callCount int, m
 			}
 
 			// Calculate the new fee for this new amount of hours
-			newFee := fee.RequiredFee(newTotalHours, params.UserVerifyTxn.BurnFactor)
+			newFee := fee.RequiredFee(newTotalHours, params.UserVerifyTxn.BurnFactor) + p.ExtraFee
 			if newFee < feeHours {
 				err := errors.New("updated fee after adding extra input for change is unexpectedly less than it was initially")
 				logger.WithError(err).Error()
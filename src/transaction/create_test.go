@@ -14,11 +14,19 @@ import (
 
 	"github.com/SkycoinProject/cx-chains/src/cipher"
 	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/params"
 	"github.com/SkycoinProject/cx-chains/src/testutil"
 	"github.com/SkycoinProject/cx-chains/src/util/fee"
 )
 
 func TestCreate(t *testing.T) {
+	// The expected hours of the cases are calculated with a burn factor of 10
+	originalBurnFactor := params.UserVerifyTxn.BurnFactor
+	params.UserVerifyTxn.BurnFactor = 10
+	defer func() {
+		params.UserVerifyTxn.BurnFactor = originalBurnFactor
+	}()
+
 	headTime := uint64(time.Now().UTC().Unix())
 	seed := []byte("seed")
 
@@ -304,6 +312,76 @@ func TestCreate(t *testing.T) {
 			},
 		},
 
+		{
+			name: "manual, 1 output, change, extra fee",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				To: []coin.TransactionOutput{
+					{
+						Address: addrs[0],
+						Hours:   130,
+						Coins:   2e6 + 1,
+					},
+				},
+				ExtraFee: 20,
+			},
+			unspents:       uxouts,
+			chosenUnspents: []coin.UxOut{originalUxouts[0], originalUxouts[1]},
+			changeOutput: &coin.TransactionOutput{
+				Address: changeAddress,
+				Hours:   30,
+				Coins:   2e6 - 1,
+			},
+		},
+
+		{
+			name: "manual, 1 output, change, burn change hours",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				To: []coin.TransactionOutput{
+					{
+						Address: addrs[0],
+						Hours:   130,
+						Coins:   2e6 + 1,
+					},
+				},
+				BurnChangeHours: true,
+			},
+			unspents:       uxouts,
+			chosenUnspents: []coin.UxOut{originalUxouts[0], originalUxouts[1]},
+			changeOutput: &coin.TransactionOutput{
+				Address: changeAddress,
+				Hours:   0,
+				Coins:   2e6 - 1,
+			},
+		},
+
+		{
+			name: "insufficient hours for extra fee",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				To: []coin.TransactionOutput{
+					{
+						Address: addrs[0],
+						Hours:   10,
+						Coins:   1e6,
+					},
+				},
+				ExtraFee: 100e6,
+			},
+			unspents: uxouts[:1],
+			err:      ErrInsufficientHours,
+		},
+
 		{
 			name: "manual, 1 output, change, unspecified change address",
 			params: Params{
@@ -353,6 +431,29 @@ func TestCreate(t *testing.T) {
 			},
 		},
 
+		{
+			// there are leftover coin hours and no coins change,
+			// but they are burned so no additional input is added to save them
+			name: "manual, 1 output, burn change hours, no forced change",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				To: []coin.TransactionOutput{
+					{
+						Address: addrs[0],
+						Hours:   0,
+						Coins:   2e6 * 2,
+					},
+				},
+				BurnChangeHours: true,
+			},
+			unspents:       uxouts,
+			chosenUnspents: []coin.UxOut{originalUxouts[0], originalUxouts[1]},
+			changeOutput:   nil,
+		},
+
 		{
 			// there are leftover coin hours and no coins change,
 			// but there are no more unspents to use to force a change output
@@ -669,7 +770,7 @@ func TestCreate(t *testing.T) {
 			}
 
 			t.Log("len of addrUxOuts:", len(addrUxOuts.Flatten()))
-			txn, inputs, err := Create(tc.params, addrUxOuts, tc.headTime)
+			txn, inputs, err := Create(tc.params, addrUxOuts, tc.headTime, nil)
 			require.Equal(t, tc.err, err, "%v != %v", tc.err, err)
 			if tc.err != nil {
 				return
@@ -763,6 +864,88 @@ func TestCreate(t *testing.T) {
 	}
 }
 
+func TestCreateFeePerKB(t *testing.T) {
+	originalBurnFactor := params.UserVerifyTxn.BurnFactor
+	params.UserVerifyTxn.BurnFactor = 10
+	defer func() {
+		params.UserVerifyTxn.BurnFactor = originalBurnFactor
+	}()
+
+	headTime := uint64(time.Now().UTC().Unix())
+	_, secKeys := cipher.MustGenerateDeterministicKeyPairsSeed([]byte("seed"), 1)
+
+	var uxouts []coin.UxOut
+	for i := 0; i < 3; i++ {
+		uxout := makeUxOut(t, secKeys[0], 2e6, 1e6)
+		uxout.Head.Time = headTime
+		uxouts = append(uxouts, uxout)
+	}
+
+	auxs := coin.AddressUxOuts{
+		uxouts[0].Body.Address: uxouts,
+	}
+
+	changeAddress := testutil.MakeAddress()
+	newParams := func(feePerKB uint64) Params {
+		return Params{
+			HoursSelection: HoursSelection{
+				Type: HoursSelectionTypeManual,
+			},
+			ChangeAddress: &changeAddress,
+			To: []coin.TransactionOutput{
+				{
+					Address: testutil.MakeAddress(),
+					Hours:   10,
+					Coins:   1e6,
+				},
+			},
+			FeePerKB: feePerKB,
+		}
+	}
+
+	txnFee := func(txn *coin.Transaction, spends []UxBalance) (uint64, uint32) {
+		var inputHours uint64
+		for _, s := range spends {
+			inputHours += s.Hours
+		}
+
+		outputHours, err := txn.OutputHours()
+		require.NoError(t, err)
+
+		size, err := txn.Size()
+		require.NoError(t, err)
+
+		return inputHours - outputHours, size
+	}
+
+	txn, spends, err := Create(newParams(0), auxs, headTime, nil)
+	require.NoError(t, err)
+	requiredFee, _ := txnFee(txn, spends)
+	require.Equal(t, uint64(1e5), requiredFee)
+
+	// The required fee already meets a low fee per kilobyte
+	txn, spends, err = Create(newParams(100), auxs, headTime, nil)
+	require.NoError(t, err)
+	f, _ := txnFee(txn, spends)
+	require.Equal(t, requiredFee, f)
+
+	// The fee is raised to the lowest fee that meets a high fee per kilobyte
+	feePerKB := uint64(1e6)
+	txn, spends, err = Create(newParams(feePerKB), auxs, headTime, nil)
+	require.NoError(t, err)
+	f, size := txnFee(txn, spends)
+	require.True(t, f > requiredFee)
+	require.True(t, f*1000/uint64(size) >= feePerKB)
+	require.True(t, (f-1)*1000/uint64(size) < feePerKB)
+	require.Len(t, txn.Out, 2)
+	require.Equal(t, uint64(10), txn.Out[0].Hours)
+
+	// The inputs do not have enough hours for the fee per kilobyte
+	_, _, err = Create(newParams(1e8), auxs, headTime, nil)
+	require.Error(t, err)
+	require.IsType(t, Error{}, err)
+}
+
 func makeUxOut(t *testing.T, s cipher.SecKey, coins, hours uint64) coin.UxOut { // nolint: unparam
 	body := makeUxBody(t, s, coins, hours)
 	tm := rand.Int31n(1000)
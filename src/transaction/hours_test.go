@@ -193,6 +193,7 @@ func TestDistributeSpendHours(t *testing.T) {
 		{2, burnFactor2TestCases},
 		{3, burnFactor3TestCases},
 		{10, burnFactor10TestCases},
+		{100, burnFactor100TestCases},
 	}
 
 	tested := false
@@ -663,3 +664,57 @@ var burnFactor10TestCases = []distributeSpendHoursTestCase{
 		expectAddrHours:   []uint64{5, 5, 4},
 	},
 }
+
+var burnFactor100TestCases = []distributeSpendHoursTestCase{
+	{
+		name:            "no input hours, one addr, no change",
+		inputHours:      0,
+		nAddrs:          1,
+		haveChange:      false,
+		expectAddrHours: []uint64{0},
+	},
+	{
+		name:            "one input hour, one addr, no change",
+		inputHours:      1,
+		nAddrs:          1,
+		haveChange:      false,
+		expectAddrHours: []uint64{0},
+	},
+	{
+		name:            "100 input hours, one addr, no change",
+		inputHours:      100,
+		nAddrs:          1,
+		haveChange:      false,
+		expectAddrHours: []uint64{99},
+	},
+	{
+		name:            "101 input hours, one addr, no change",
+		inputHours:      101,
+		nAddrs:          1,
+		haveChange:      false,
+		expectAddrHours: []uint64{99},
+	},
+	{
+		name:              "100 input hours, one addr, change",
+		inputHours:        100,
+		nAddrs:            1,
+		haveChange:        true,
+		expectChangeHours: 50,
+		expectAddrHours:   []uint64{49},
+	},
+	{
+		name:            "200 input hours, two addrs, no change",
+		inputHours:      200,
+		nAddrs:          2,
+		haveChange:      false,
+		expectAddrHours: []uint64{99, 99},
+	},
+	{
+		name:              "201 input hours, two addrs, change",
+		inputHours:        201,
+		nAddrs:            2,
+		haveChange:        true,
+		expectChangeHours: 99,
+		expectAddrHours:   []uint64{50, 49},
+	},
+}
@@ -31,6 +31,15 @@ const (
 
 	// HoursSelectionModeShare will distribute coin hours equally amongst destinations
 	HoursSelectionModeShare = "share"
+
+	// CoinSelectionMinimizeInputs chooses the least number of uxouts to spend. This is the default
+	CoinSelectionMinimizeInputs = "minimize_inputs"
+	// CoinSelectionMinimizeChange chooses the uxouts to spend which leave the least change
+	CoinSelectionMinimizeChange = "minimize_change"
+	// CoinSelectionOldestFirst chooses the oldest uxouts to spend first, to collect the most coin hours
+	CoinSelectionOldestFirst = "oldest_first"
+	// CoinSelectionRandom chooses the uxouts to spend in a random order
+	CoinSelectionRandom = "random"
 )
 
 var (
@@ -60,6 +69,10 @@ var (
 	ErrInvalidShareFactor = NewError(errors.New("HoursSelection.ShareFactor can only be used for share mode"))
 	// ErrShareFactorOutOfRange HoursSelection.ShareFactor must be >= 0 and <= 1
 	ErrShareFactorOutOfRange = NewError(errors.New("HoursSelection.ShareFactor must be >= 0 and <= 1"))
+	// ErrInvalidCoinSelection Invalid CoinSelection
+	ErrInvalidCoinSelection = NewError(errors.New("Invalid CoinSelection"))
+	// ErrFeePerKBNotMet the fee of the transaction could not be raised to FeePerKB
+	ErrFeePerKBNotMet = NewError(errors.New("Transaction fee could not be raised to FeePerKB"))
 )
 
 // HoursSelection defines options for hours distribution
@@ -74,6 +87,19 @@ type Params struct {
 	HoursSelection HoursSelection
 	To             []coin.TransactionOutput
 	ChangeAddress  *cipher.Address
+	// CoinSelection is the strategy for choosing the uxouts to spend, CoinSelectionMinimizeInputs if empty
+	CoinSelection string
+	// MaxInputs is the maximum number of uxouts to spend, unlimited if 0
+	MaxInputs uint64
+	// ExtraFee is the number of coin hours burned in addition to the required fee,
+	// to raise the fee of a transaction replacing an unconfirmed transaction
+	ExtraFee uint64
+	// FeePerKB is the minimum fee of the transaction in coin hours per 1000 bytes.
+	// If the required fee is lower, the extra fee is raised to meet it
+	FeePerKB uint64
+	// BurnChangeHours burns the coin hours left after the fee and the outputs' hours
+	// instead of sending them to the change output
+	BurnChangeHours bool
 
 	MainExpressions []byte //serialized expressions to run using the program state
 }
@@ -103,14 +129,27 @@ func (c Params) Validate() error {
 	// Auto mode would distribute hours to the outputs and could hypothetically
 	// avoid assigning duplicate hours in many cases, but the complexity for doing
 	// so is very high, so also reject duplicate (address, coins) for auto mode.
-	// outputs := make(map[coin.TransactionOutput]struct{}, len(c.To))
-	// for _, to := range c.To {
-	// 	outputs[to] = struct{}{}
-	// }
+	// TransactionOutput is not comparable because of its ProgramState, so the
+	// program state is compared as a string.
+	type outputKey struct {
+		address      cipher.Address
+		coins        uint64
+		hours        uint64
+		programState string
+	}
+	outputs := make(map[outputKey]struct{}, len(c.To))
+	for _, to := range c.To {
+		outputs[outputKey{
+			address:      to.Address,
+			coins:        to.Coins,
+			hours:        to.Hours,
+			programState: string(to.ProgramState),
+		}] = struct{}{}
+	}
 
-	// if len(outputs) != len(c.To) {
-	// 	return ErrDuplicateReceiver
-	// }
+	if len(outputs) != len(c.To) {
+		return ErrDuplicateReceiver
+	}
 
 	switch c.HoursSelection.Type {
 	case HoursSelectionTypeAuto:
@@ -137,6 +176,16 @@ func (c Params) Validate() error {
 		return ErrInvalidHoursSelectionType
 	}
 
+	switch c.CoinSelection {
+	case "",
+		CoinSelectionMinimizeInputs,
+		CoinSelectionMinimizeChange,
+		CoinSelectionOldestFirst,
+		CoinSelectionRandom:
+	default:
+		return ErrInvalidCoinSelection
+	}
+
 	if c.HoursSelection.ShareFactor == nil {
 		if c.HoursSelection.Mode == HoursSelectionModeShare {
 			return ErrMissingShareFactor
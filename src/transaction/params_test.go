@@ -28,6 +28,9 @@ func TestCreateWalletParamsVerify(t *testing.T) {
 		},
 	}
 
+	toManualProgramState := toManual[0]
+	toManualProgramState.ProgramState = []byte{1, 2, 3}
+
 	toAuto := []coin.TransactionOutput{
 		{
 			Address: testutil.MakeAddress(),
@@ -221,6 +224,32 @@ func TestCreateWalletParamsVerify(t *testing.T) {
 			err: "HoursSelection.ShareFactor must be >= 0 and <= 1",
 		},
 
+		{
+			name: "invalid coin selection",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				To:            toManual,
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				CoinSelection: "largest_first",
+			},
+			err: "Invalid CoinSelection",
+		},
+
+		{
+			name: "valid coin selection",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				To:            toManual,
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+				CoinSelection: CoinSelectionOldestFirst,
+				MaxInputs:     10,
+			},
+		},
+
 		{
 			name: "duplicate output when manual",
 			params: Params{
@@ -247,6 +276,29 @@ func TestCreateWalletParamsVerify(t *testing.T) {
 			err: "To contains duplicate values",
 		},
 
+		{
+			name: "duplicate output with the same program state",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				To:            []coin.TransactionOutput{toManualProgramState, toManualProgramState},
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+			},
+			err: "To contains duplicate values",
+		},
+
+		{
+			name: "same output with different program states",
+			params: Params{
+				ChangeAddress: &changeAddress,
+				To:            []coin.TransactionOutput{toManual[0], toManualProgramState},
+				HoursSelection: HoursSelection{
+					Type: HoursSelectionTypeManual,
+				},
+			},
+		},
+
 		{
 			name: "valid auto split even share factor",
 			params: Params{
@@ -99,6 +99,25 @@ var burnFactor10VerifyTxnFeeTestCases = []verifyTxnFeeTestCase{
 	{20, 20, ErrTxnNoFee},
 }
 
+var burnFactor100VerifyTxnFeeTestCases = []verifyTxnFeeTestCase{
+	{0, 0, ErrTxnNoFee},
+	{1, 0, nil},
+	{1, 1, ErrTxnNoFee},
+	{2, 1, nil},
+	{2, 2, ErrTxnNoFee},
+	{99, 98, nil},
+	{99, 99, ErrTxnNoFee},
+	{100, 99, nil},
+	{100, 100, ErrTxnNoFee},
+	{101, 99, nil},
+	{101, 100, ErrTxnInsufficientFee},
+	{101, 101, ErrTxnNoFee},
+	{200, 198, nil},
+	{200, 199, ErrTxnInsufficientFee},
+	{201, 198, nil},
+	{201, 199, ErrTxnInsufficientFee},
+}
+
 func TestVerifyTransactionFee(t *testing.T) {
 	emptyTxn := &coin.Transaction{}
 	hours, err := emptyTxn.OutputHours()
@@ -160,6 +179,7 @@ func TestVerifyTransactionFee(t *testing.T) {
 		{2, burnFactor2VerifyTxnFeeTestCases},
 		{3, burnFactor3VerifyTxnFeeTestCases},
 		{10, burnFactor10VerifyTxnFeeTestCases},
+		{100, burnFactor100VerifyTxnFeeTestCases},
 	}
 
 	tested := false
@@ -245,6 +265,21 @@ var burnFactor10RequiredFeeTestCases = []requiredFeeTestCase{
 	{1003, 101},
 }
 
+var burnFactor100RequiredFeeTestCases = []requiredFeeTestCase{
+	{0, 0},
+	{1, 1},
+	{2, 1},
+	{99, 1},
+	{100, 1},
+	{101, 2},
+	{199, 2},
+	{200, 2},
+	{201, 3},
+	{999, 10},
+	{1000, 10},
+	{1001, 11},
+}
+
 func TestRequiredFee(t *testing.T) {
 	cases := []struct {
 		burnFactor uint32
@@ -253,6 +288,7 @@ func TestRequiredFee(t *testing.T) {
 		{2, burnFactor2RequiredFeeTestCases},
 		{3, burnFactor3RequiredFeeTestCases},
 		{10, burnFactor10RequiredFeeTestCases},
+		{100, burnFactor100RequiredFeeTestCases},
 	}
 
 	tested := false
@@ -42,6 +42,11 @@ func Error405(w http.ResponseWriter) {
 	ErrorXXX(w, http.StatusMethodNotAllowed, "")
 }
 
+// Error409 respond with a 409 error and include a message
+func Error409(w http.ResponseWriter, msg string) {
+	ErrorXXX(w, http.StatusConflict, msg)
+}
+
 // Error415 respond with a 415 error
 func Error415(w http.ResponseWriter) {
 	ErrorXXX(w, http.StatusUnsupportedMediaType, "")
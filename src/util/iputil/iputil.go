@@ -64,3 +64,15 @@ func SplitAddr(addr string) (string, uint16, error) {
 
 	return ip, uint16(port64), nil
 }
+
+// IsIPv6 returns true if addr is an [ip]:port address with an IPv6 address.
+// IPv4-mapped IPv6 addresses are considered IPv4.
+func IsIPv6(addr string) bool {
+	ip, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+
+	a := net.ParseIP(ip)
+	return a != nil && a.To4() == nil
+}
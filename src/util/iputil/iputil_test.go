@@ -121,3 +121,41 @@ func TestSplitAddr(t *testing.T) {
 		})
 	}
 }
+
+func TestIsIPv6(t *testing.T) {
+	testData := []struct {
+		addr     string
+		expected bool
+	}{
+		{
+			addr:     "[2001:db8::1]:6000",
+			expected: true,
+		},
+		{
+			addr:     "[::1]:6000",
+			expected: true,
+		},
+		{
+			addr:     "[::ffff:1.2.3.4]:6000",
+			expected: false,
+		},
+		{
+			addr:     "1.2.3.4:6000",
+			expected: false,
+		},
+		{
+			addr:     "2001:db8::1",
+			expected: false,
+		},
+		{
+			addr:     "localhost:6000",
+			expected: false,
+		},
+	}
+
+	for _, tc := range testData {
+		t.Run(tc.addr, func(t *testing.T) {
+			require.Equal(t, tc.expected, IsIPv6(tc.addr))
+		})
+	}
+}
@@ -14,15 +14,20 @@ type WriteHook struct {
 
 // NewWriteHook returns a new WriteHook
 func NewWriteHook(w io.Writer) *WriteHook {
+	return NewWriteHookWithFormatter(w, &TextFormatter{
+		DisableColors:      true,
+		FullTimestamp:      true,
+		AlwaysQuoteStrings: true,
+		QuoteEmptyFields:   true,
+		ForceFormatting:    true,
+	})
+}
+
+// NewWriteHookWithFormatter returns a new WriteHook that formats entries with a logrus.Formatter
+func NewWriteHookWithFormatter(w io.Writer, formatter logrus.Formatter) *WriteHook {
 	return &WriteHook{
-		w: w,
-		formatter: &TextFormatter{
-			DisableColors:      true,
-			FullTimestamp:      true,
-			AlwaysQuoteStrings: true,
-			QuoteEmptyFields:   true,
-			ForceFormatting:    true,
-		},
+		w:         w,
+		formatter: formatter,
 	}
 }
 
@@ -1,7 +1,9 @@
 package logging
 
 import (
+	"io"
 	"os"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 )
@@ -17,9 +19,15 @@ func (logger *Logger) Critical() logrus.FieldLogger {
 	return logger.WithField(logPriorityKey, logPriorityCritical)
 }
 
-// MasterLogger wraps logrus.Logger and is able to create new package-aware loggers
+// MasterLogger wraps logrus.Logger and is able to create new package-aware loggers.
+// Each module has its own logrus.Logger sharing the output, formatter and hooks of the master logger,
+// so that its level can be overridden.
 type MasterLogger struct {
 	*logrus.Logger
+
+	mu           sync.Mutex
+	modules      map[string]*logrus.Logger
+	moduleLevels map[string]logrus.Level
 }
 
 // NewMasterLogger creates a new package-aware logger with formatting string
@@ -28,44 +36,132 @@ func NewMasterLogger() *MasterLogger {
 
 	return &MasterLogger{
 		Logger: &logrus.Logger{
-			Out: os.Stdout,
-			Formatter: &TextFormatter{
-				FullTimestamp:      true,
-				AlwaysQuoteStrings: true,
-				QuoteEmptyFields:   true,
-				ForceFormatting:    true,
-				DisableColors:      false,
-				ForceColors:        false,
-			},
-			Hooks: hooks,
-			Level: logrus.DebugLevel,
+			Out:       os.Stdout,
+			Formatter: newTextFormatter(),
+			Hooks:     hooks,
+			Level:     logrus.DebugLevel,
 		},
+		modules:      make(map[string]*logrus.Logger),
+		moduleLevels: make(map[string]logrus.Level),
+	}
+}
+
+func newTextFormatter() *TextFormatter {
+	return &TextFormatter{
+		FullTimestamp:      true,
+		AlwaysQuoteStrings: true,
+		QuoteEmptyFields:   true,
+		ForceFormatting:    true,
+		DisableColors:      false,
+		ForceColors:        false,
 	}
 }
 
 // PackageLogger instantiates a package-aware logger
 func (logger *MasterLogger) PackageLogger(moduleName string) *Logger {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	l, ok := logger.modules[moduleName]
+	if !ok {
+		level, ok := logger.moduleLevels[moduleName]
+		if !ok {
+			level = logger.GetLevel()
+		}
+
+		l = &logrus.Logger{
+			Out:       logger.Out,
+			Formatter: logger.Formatter,
+			Hooks:     logger.Hooks,
+			Level:     level,
+			ExitFunc:  logger.ExitFunc,
+		}
+		logger.modules[moduleName] = l
+	}
+
 	return &Logger{
-		FieldLogger: logger.WithField(logModuleKey, moduleName),
+		FieldLogger: l.WithField(logModuleKey, moduleName),
 	}
 }
 
 // AddHook adds a logrus.Hook to the logger and its module loggers
 func (logger *MasterLogger) AddHook(hook logrus.Hook) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	// The module loggers share the master logger's hooks
 	logger.Hooks.Add(hook)
 }
 
-// SetLevel sets the log level for the logger and its module loggers
+// SetLevel sets the log level for the logger and the module loggers without a level override
 func (logger *MasterLogger) SetLevel(level logrus.Level) {
-	logger.Level = level
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	logger.Logger.SetLevel(level)
+	for name, l := range logger.modules {
+		if _, ok := logger.moduleLevels[name]; !ok {
+			l.SetLevel(level)
+		}
+	}
+}
+
+// SetModuleLevel overrides the log level of a module logger.
+// The override applies to module loggers created afterwards too.
+func (logger *MasterLogger) SetModuleLevel(moduleName string, level logrus.Level) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	logger.moduleLevels[moduleName] = level
+	if l, ok := logger.modules[moduleName]; ok {
+		l.SetLevel(level)
+	}
 }
 
-// EnableColors enables colored logging
+// ModuleLevels returns the log level of each module logger
+func (logger *MasterLogger) ModuleLevels() map[string]logrus.Level {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	levels := make(map[string]logrus.Level, len(logger.modules))
+	for name, l := range logger.modules {
+		levels[name] = l.GetLevel()
+	}
+	return levels
+}
+
+// SetOutput sets the output of the logger and its module loggers
+func (logger *MasterLogger) SetOutput(w io.Writer) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	logger.Logger.SetOutput(w)
+	for _, l := range logger.modules {
+		l.SetOutput(w)
+	}
+}
+
+// SetFormatter sets the formatter of the logger and its module loggers
+func (logger *MasterLogger) SetFormatter(f logrus.Formatter) {
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	logger.Logger.SetFormatter(f)
+	for _, l := range logger.modules {
+		l.SetFormatter(f)
+	}
+}
+
+// EnableColors enables colored logging, if the text format is used
 func (logger *MasterLogger) EnableColors() {
-	logger.Formatter.(*TextFormatter).DisableColors = false
+	if f, ok := logger.Formatter.(*TextFormatter); ok {
+		f.DisableColors = false
+	}
 }
 
-// DisableColors disables colored logging
+// DisableColors disables colored logging, if the text format is used
 func (logger *MasterLogger) DisableColors() {
-	logger.Formatter.(*TextFormatter).DisableColors = true
+	if f, ok := logger.Formatter.(*TextFormatter); ok {
+		f.DisableColors = true
+	}
 }
@@ -21,6 +21,13 @@ const (
 	logPriorityKey = "_priority"
 	// logPriorityCritical is the log entry value for priority log statements
 	logPriorityCritical = "CRITICAL"
+
+	// FormatText is the default human readable log format
+	FormatText = "text"
+	// FormatJSON logs each entry as a JSON object
+	FormatJSON = "json"
+	// FormatLogfmt logs each entry as logfmt key=value pairs
+	FormatLogfmt = "logfmt"
 )
 
 // LevelFromString returns a logrus.Level from a string identifier
@@ -43,6 +50,27 @@ func LevelFromString(s string) (logrus.Level, error) {
 	}
 }
 
+// NewFormatter returns a logrus.Formatter for a log format.
+// The text formatter has colors disabled.
+func NewFormatter(format string) (logrus.Formatter, error) {
+	switch strings.ToLower(format) {
+	case FormatText:
+		f := newTextFormatter()
+		f.DisableColors = true
+		return f, nil
+	case FormatJSON:
+		return &logrus.JSONFormatter{}, nil
+	case FormatLogfmt:
+		return &logrus.TextFormatter{
+			DisableColors:    true,
+			FullTimestamp:    true,
+			QuoteEmptyFields: true,
+		}, nil
+	default:
+		return nil, errors.New("invalid log format")
+	}
+}
+
 // MustGetLogger returns a package-aware logger from the master logger
 func MustGetLogger(module string) *Logger {
 	return log.PackageLogger(module)
@@ -68,12 +96,43 @@ func SetLevel(level logrus.Level) {
 	log.SetLevel(level)
 }
 
+// SetModuleLevel overrides a module logger's minimum log level
+func SetModuleLevel(module string, level logrus.Level) {
+	log.SetModuleLevel(module, level)
+}
+
+// Level returns the logger's minimum log level, used by the module loggers without an override
+func Level() logrus.Level {
+	return log.GetLevel()
+}
+
+// ModuleLevels returns the minimum log level of each module logger
+func ModuleLevels() map[string]logrus.Level {
+	return log.ModuleLevels()
+}
+
+// SetFormat sets the logger's output format to FormatText, FormatJSON or FormatLogfmt
+func SetFormat(format string) error {
+	if strings.ToLower(format) == FormatText {
+		log.SetFormatter(newTextFormatter())
+		return nil
+	}
+
+	f, err := NewFormatter(format)
+	if err != nil {
+		return err
+	}
+
+	log.SetFormatter(f)
+	return nil
+}
+
 // SetOutputTo sets the logger's output to an io.Writer
 func SetOutputTo(w io.Writer) {
-	log.Out = w
+	log.SetOutput(w)
 }
 
 // Disable disables the logger completely
 func Disable() {
-	log.Out = ioutil.Discard
+	log.SetOutput(ioutil.Discard)
 }
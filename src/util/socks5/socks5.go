@@ -0,0 +1,242 @@
+/*
+Package socks5 implements a SOCKS5 client (RFC 1928) for TCP connections through a proxy, such as Tor
+*/
+package socks5
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	version = 5
+
+	authNone         = 0
+	authNoAcceptable = 0xff
+
+	cmdConnect = 1
+
+	atypIPv4   = 1
+	atypDomain = 3
+	atypIPv6   = 4
+)
+
+var (
+	// ErrAuthNotAccepted is returned if the proxy requires an authentication method
+	ErrAuthNotAccepted = errors.New("SOCKS5 proxy requires authentication")
+	// ErrInvalidVersion is returned if the proxy replies with a version other than 5
+	ErrInvalidVersion = errors.New("SOCKS5 proxy replied with an invalid version")
+	// ErrHostTooLong is returned if the host of the destination address is longer than 255 bytes
+	ErrHostTooLong = errors.New("SOCKS5 destination host is too long")
+
+	replyErrors = map[byte]string{
+		1: "general SOCKS server failure",
+		2: "connection not allowed by ruleset",
+		3: "network unreachable",
+		4: "host unreachable",
+		5: "connection refused",
+		6: "TTL expired",
+		7: "command not supported",
+		8: "address type not supported",
+	}
+)
+
+// Dialer makes TCP connections through a SOCKS5 proxy.
+// Hostnames of the destination addresses are resolved by the proxy.
+type Dialer struct {
+	// Address of the proxy, as host:port
+	ProxyAddr string
+	// Timeout of the connection to the proxy and of the SOCKS5 handshake. Use a timeout of 0 to ignore timeout.
+	Timeout time.Duration
+}
+
+// Addr is the address of a connection made through a proxy, as requested to the proxy
+type Addr struct {
+	Host string
+	Port int
+}
+
+// Network implements net.Addr
+func (a Addr) Network() string {
+	return "tcp"
+}
+
+// String implements net.Addr
+func (a Addr) String() string {
+	return net.JoinHostPort(a.Host, strconv.Itoa(a.Port))
+}
+
+type conn struct {
+	net.Conn
+	remoteAddr net.Addr
+}
+
+// RemoteAddr returns the destination address instead of the address of the proxy
+func (c *conn) RemoteAddr() net.Addr {
+	return c.remoteAddr
+}
+
+// Dial connects to addr through the proxy.
+// The RemoteAddr of the returned connection is addr, not the address of the proxy.
+func (d Dialer) Dial(network, addr string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, addr)
+}
+
+// DialContext connects to addr through the proxy using the provided context.
+// The RemoteAddr of the returned connection is addr, not the address of the proxy.
+func (d Dialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return nil, fmt.Errorf("SOCKS5 network %q is not supported", network)
+	}
+
+	host, port, err := splitAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.Timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.Timeout)
+		defer cancel()
+	}
+
+	var nd net.Dialer
+	c, err := nd.DialContext(ctx, "tcp", d.ProxyAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := c.SetDeadline(deadline); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+
+	if err := connect(c, host, port); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	if err := c.SetDeadline(time.Time{}); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	var remoteAddr net.Addr = Addr{
+		Host: host,
+		Port: port,
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		remoteAddr = &net.TCPAddr{
+			IP:   ip,
+			Port: port,
+		}
+	}
+
+	return &conn{
+		Conn:       c,
+		remoteAddr: remoteAddr,
+	}, nil
+}
+
+func splitAddr(addr string) (string, int, error) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0, err
+	}
+
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("Invalid port in address %q", addr)
+	}
+
+	if len(host) > 255 {
+		return "", 0, ErrHostTooLong
+	}
+
+	return host, int(port), nil
+}
+
+// connect performs the SOCKS5 handshake and the CONNECT request to host:port
+func connect(rw io.ReadWriter, host string, port int) error {
+	// Greeting, offering no authentication
+	if _, err := rw.Write([]byte{version, 1, authNone}); err != nil {
+		return err
+	}
+
+	var resp [2]byte
+	if _, err := io.ReadFull(rw, resp[:]); err != nil {
+		return err
+	}
+	if resp[0] != version {
+		return ErrInvalidVersion
+	}
+	switch resp[1] {
+	case authNone:
+	case authNoAcceptable:
+		return ErrAuthNotAccepted
+	default:
+		return fmt.Errorf("SOCKS5 proxy chose an unsupported authentication method %d", resp[1])
+	}
+
+	// Connect request
+	req := []byte{version, cmdConnect, 0}
+	if ip := net.ParseIP(host); ip == nil {
+		req = append(req, atypDomain, byte(len(host)))
+		req = append(req, host...)
+	} else if ip4 := ip.To4(); ip4 != nil {
+		req = append(req, atypIPv4)
+		req = append(req, ip4...)
+	} else {
+		req = append(req, atypIPv6)
+		req = append(req, ip.To16()...)
+	}
+	req = append(req, byte(port>>8), byte(port))
+
+	if _, err := rw.Write(req); err != nil {
+		return err
+	}
+
+	// Reply: version, status, reserved, then the bound address which is discarded
+	var reply [4]byte
+	if _, err := io.ReadFull(rw, reply[:]); err != nil {
+		return err
+	}
+	if reply[0] != version {
+		return ErrInvalidVersion
+	}
+	if reply[1] != 0 {
+		if msg, ok := replyErrors[reply[1]]; ok {
+			return fmt.Errorf("SOCKS5 proxy failed to connect to %s: %s", net.JoinHostPort(host, strconv.Itoa(port)), msg)
+		}
+		return fmt.Errorf("SOCKS5 proxy failed to connect to %s: unknown error %d", net.JoinHostPort(host, strconv.Itoa(port)), reply[1])
+	}
+
+	var n int
+	switch reply[3] {
+	case atypIPv4:
+		n = net.IPv4len
+	case atypIPv6:
+		n = net.IPv6len
+	case atypDomain:
+		var l [1]byte
+		if _, err := io.ReadFull(rw, l[:]); err != nil {
+			return err
+		}
+		n = int(l[0])
+	default:
+		return fmt.Errorf("SOCKS5 proxy replied with an unknown address type %d", reply[3])
+	}
+
+	// Bound address and port
+	_, err := io.ReadFull(rw, make([]byte, n+2))
+	return err
+}
@@ -0,0 +1,169 @@
+package socks5
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// serveProxy accepts one connection on ln, performs the server side of the SOCKS5 handshake
+// with the auth method and reply status given, sends the requested destination on reqC,
+// then echoes the data received on the connection
+func serveProxy(t *testing.T, ln net.Listener, auth, status byte, reqC chan<- []byte) {
+	c, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer c.Close()
+
+	greeting := make([]byte, 3)
+	if _, err := io.ReadFull(c, greeting); err != nil {
+		t.Error(err)
+		return
+	}
+	if _, err := c.Write([]byte{version, auth}); err != nil {
+		t.Error(err)
+		return
+	}
+	if auth != authNone {
+		return
+	}
+
+	// version, command, reserved, address type
+	head := make([]byte, 4)
+	if _, err := io.ReadFull(c, head); err != nil {
+		t.Error(err)
+		return
+	}
+
+	var n int
+	switch head[3] {
+	case atypIPv4:
+		n = net.IPv4len
+	case atypIPv6:
+		n = net.IPv6len
+	case atypDomain:
+		l := make([]byte, 1)
+		if _, err := io.ReadFull(c, l); err != nil {
+			t.Error(err)
+			return
+		}
+		head = append(head, l[0])
+		n = int(l[0])
+	}
+
+	rest := make([]byte, n+2)
+	if _, err := io.ReadFull(c, rest); err != nil {
+		t.Error(err)
+		return
+	}
+	reqC <- append(head, rest...)
+
+	if _, err := c.Write([]byte{version, status, 0, atypIPv4, 127, 0, 0, 1, 0x17, 0x70}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if _, err := io.Copy(c, c); err != nil {
+		return
+	}
+}
+
+func TestDialer(t *testing.T) {
+	cases := []struct {
+		name       string
+		addr       string
+		auth       byte
+		status     byte
+		request    []byte
+		remoteAddr string
+		err        string
+	}{
+		{
+			name:       "ipv4",
+			addr:       "1.2.3.4:6000",
+			request:    []byte{version, cmdConnect, 0, atypIPv4, 1, 2, 3, 4, 0x17, 0x70},
+			remoteAddr: "1.2.3.4:6000",
+		},
+		{
+			name:       "ipv6",
+			addr:       "[2001:db8::1]:6000",
+			request:    []byte{version, cmdConnect, 0, atypIPv6, 0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0x17, 0x70},
+			remoteAddr: "[2001:db8::1]:6000",
+		},
+		{
+			name:       "hostname",
+			addr:       "example.onion:6000",
+			request:    append(append([]byte{version, cmdConnect, 0, atypDomain, 13}, "example.onion"...), 0x17, 0x70),
+			remoteAddr: "example.onion:6000",
+		},
+		{
+			name:    "connection refused",
+			addr:    "1.2.3.4:6000",
+			status:  5,
+			request: []byte{version, cmdConnect, 0, atypIPv4, 1, 2, 3, 4, 0x17, 0x70},
+			err:     "SOCKS5 proxy failed to connect to 1.2.3.4:6000: connection refused",
+		},
+		{
+			name: "authentication required",
+			addr: "1.2.3.4:6000",
+			auth: authNoAcceptable,
+			err:  ErrAuthNotAccepted.Error(),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			defer ln.Close()
+
+			reqC := make(chan []byte, 1)
+			go serveProxy(t, ln, tc.auth, tc.status, reqC)
+
+			d := Dialer{
+				ProxyAddr: ln.Addr().String(),
+				Timeout:   time.Second * 5,
+			}
+
+			c, err := d.Dial("tcp", tc.addr)
+			if tc.request != nil {
+				require.Equal(t, tc.request, <-reqC)
+			}
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			defer c.Close()
+
+			require.Equal(t, tc.remoteAddr, c.RemoteAddr().String())
+
+			// The connection is relayed by the proxy after the handshake
+			_, err = c.Write([]byte("ping"))
+			require.NoError(t, err)
+			b := make([]byte, 4)
+			_, err = io.ReadFull(c, b)
+			require.NoError(t, err)
+			require.Equal(t, "ping", string(b))
+		})
+	}
+}
+
+func TestDialerInvalidAddress(t *testing.T) {
+	d := Dialer{
+		ProxyAddr: "127.0.0.1:9050",
+	}
+
+	_, err := d.Dial("udp", "1.2.3.4:6000")
+	require.EqualError(t, err, `SOCKS5 network "udp" is not supported`)
+
+	_, err = d.Dial("tcp", "1.2.3.4")
+	require.Error(t, err)
+
+	_, err = d.Dial("tcp", "1.2.3.4:foo")
+	require.EqualError(t, err, `Invalid port in address "1.2.3.4:foo"`)
+}
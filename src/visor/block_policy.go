@@ -0,0 +1,315 @@
+package visor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+var (
+	// ErrTxnDeniedAddress is returned if a transaction spends from or sends to an address of BlockPolicy.DenyAddresses
+	ErrTxnDeniedAddress = errors.New("Transaction spends from or sends to a denied address")
+	// ErrTxnNotAllowedAddress is returned if a transaction spends from an address that is not in BlockPolicy.AllowAddresses
+	ErrTxnNotAllowedAddress = errors.New("Transaction spends from an address that is not allowed")
+	// ErrTxnExceedsBlockSize is returned if a transaction does not fit in the remaining size of the block
+	ErrTxnExceedsBlockSize = errors.New("Transaction does not fit in the max block size")
+	// ErrTxnExceedsBlockTransactions is returned if the block already has BlockPolicy.MaxTransactions transactions
+	ErrTxnExceedsBlockTransactions = errors.New("Block already has the max number of transactions")
+)
+
+// TxnOrdering is the order in which a block publisher chooses the transactions of a block
+type TxnOrdering string
+
+const (
+	// TxnOrderingFee chooses the transactions with the highest fee per kilobyte first
+	TxnOrderingFee TxnOrdering = "fee"
+	// TxnOrderingFIFO chooses the transactions received first into the unconfirmed pool first
+	TxnOrderingFIFO TxnOrdering = "fifo"
+)
+
+// ParseTxnOrdering parses a TxnOrdering from a string
+func ParseTxnOrdering(s string) (TxnOrdering, error) {
+	switch o := TxnOrdering(s); o {
+	case TxnOrderingFee, TxnOrderingFIFO:
+		return o, nil
+	default:
+		return "", fmt.Errorf("Invalid transaction ordering %q, must be one of %s, %s", s, TxnOrderingFee, TxnOrderingFIFO)
+	}
+}
+
+// BlockPolicy chooses the transactions of the blocks created by a block publisher.
+// The total size of the transactions of a block is limited by Config.MaxBlockTransactionsSize.
+type BlockPolicy struct {
+	// MaxTransactions is the maximum number of transactions in a block, at most coin.MaxBlockTransactions.
+	// 0 means coin.MaxBlockTransactions.
+	MaxTransactions int
+	// Ordering is the order in which the transactions of the unconfirmed pool are chosen.
+	// Empty means TxnOrderingFee.
+	Ordering TxnOrdering
+	// AllowAddresses, if not empty, are the only addresses that transactions may spend from
+	AllowAddresses []cipher.Address
+	// DenyAddresses are addresses that transactions may not spend from or send to
+	DenyAddresses []cipher.Address
+}
+
+// NewBlockPolicy creates a BlockPolicy with the default values
+func NewBlockPolicy() BlockPolicy {
+	return BlockPolicy{
+		MaxTransactions: coin.MaxBlockTransactions,
+		Ordering:        TxnOrderingFee,
+	}
+}
+
+// Validate validates the policy
+func (p BlockPolicy) Validate() error {
+	if p.MaxTransactions < 0 || p.MaxTransactions > coin.MaxBlockTransactions {
+		return fmt.Errorf("BlockPolicy.MaxTransactions must be >= 0 and <= %d", coin.MaxBlockTransactions)
+	}
+
+	if p.Ordering != "" {
+		if _, err := ParseTxnOrdering(string(p.Ordering)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p BlockPolicy) maxTransactions() int {
+	if p.MaxTransactions == 0 {
+		return coin.MaxBlockTransactions
+	}
+	return p.MaxTransactions
+}
+
+func (p BlockPolicy) ordering() TxnOrdering {
+	if p.Ordering == "" {
+		return TxnOrderingFee
+	}
+	return p.Ordering
+}
+
+// addressFilter checks the addresses of transactions against the allow and deny lists of a BlockPolicy
+type addressFilter struct {
+	allow map[cipher.Address]struct{}
+	deny  map[cipher.Address]struct{}
+}
+
+func newAddressFilter(p BlockPolicy) addressFilter {
+	f := addressFilter{
+		allow: make(map[cipher.Address]struct{}, len(p.AllowAddresses)),
+		deny:  make(map[cipher.Address]struct{}, len(p.DenyAddresses)),
+	}
+	for _, a := range p.AllowAddresses {
+		f.allow[a] = struct{}{}
+	}
+	for _, a := range p.DenyAddresses {
+		f.deny[a] = struct{}{}
+	}
+	return f
+}
+
+func (f addressFilter) empty() bool {
+	return len(f.allow) == 0 && len(f.deny) == 0
+}
+
+// check returns an error if the inputs or the outputs of txn violate the allow and deny lists
+func (f addressFilter) check(txn coin.Transaction, inputs coin.UxArray) error {
+	for _, ux := range inputs {
+		if _, ok := f.deny[ux.Body.Address]; ok {
+			return ErrTxnDeniedAddress
+		}
+
+		if len(f.allow) != 0 {
+			if _, ok := f.allow[ux.Body.Address]; !ok {
+				return ErrTxnNotAllowedAddress
+			}
+		}
+	}
+
+	for _, o := range txn.Out {
+		if _, ok := f.deny[o.Address]; ok {
+			return ErrTxnDeniedAddress
+		}
+	}
+
+	return nil
+}
+
+// ExcludedTransaction is a transaction of the unconfirmed pool that is not chosen for the next block
+type ExcludedTransaction struct {
+	Hash cipher.SHA256
+	Err  error
+}
+
+// NextBlock is the block that a block publisher would create from the unconfirmed pool
+type NextBlock struct {
+	// Block is nil if no transaction of the unconfirmed pool can be included
+	Block  *coin.Block
+	Policy BlockPolicy
+	// MaxSize is the maximum total size in bytes of the transactions of the block
+	MaxSize uint32
+	// Size is the total size in bytes of the transactions of the block
+	Size uint32
+	// Pending is the number of transactions in the unconfirmed pool
+	Pending int
+	// Excluded are the transactions of the unconfirmed pool that are not in the block, and why
+	Excluded []ExcludedTransaction
+}
+
+// GetNextBlock returns the block that would be created from the unconfirmed pool by a block publisher
+// with the configured BlockPolicy. The block is not signed nor executed.
+func (vs *Visor) GetNextBlock() (*NextBlock, error) {
+	var nb *NextBlock
+	if err := vs.db.View("GetNextBlock", func(tx *dbutil.Tx) error {
+		head, err := vs.blockchain.Head(tx)
+		if err != nil {
+			return err
+		}
+
+		nb, err = vs.nextBlock(tx, nextBlockTime(head))
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return nb, nil
+}
+
+// nextBlock chooses the transactions of the unconfirmed pool for a block with the configured BlockPolicy,
+// and creates the block at time when
+func (vs *Visor) nextBlock(tx *dbutil.Tx, when uint64) (*NextBlock, error) {
+	policy := vs.Config.BlockPolicy
+
+	nb := &NextBlock{
+		Policy:  policy,
+		MaxSize: vs.Config.MaxBlockTransactionsSize,
+	}
+
+	uts, err := vs.unconfirmed.GetFiltered(tx, All)
+	if err != nil {
+		return nil, err
+	}
+
+	nb.Pending = len(uts)
+	if len(uts) == 0 {
+		return nb, nil
+	}
+
+	logger.Infof("unconfirmed pool has %d transactions pending", len(uts))
+
+	exclude := func(txn coin.Transaction, err error) {
+		nb.Excluded = append(nb.Excluded, ExcludedTransaction{
+			Hash: txn.Hash(),
+			Err:  err,
+		})
+	}
+
+	// Filter transactions that violate all constraints, or the address lists of the policy
+	filter := newAddressFilter(policy)
+	received := make(map[cipher.SHA256]int64, len(uts))
+	var txns coin.Transactions
+	for _, ut := range uts {
+		txn := ut.Transaction
+		if _, _, err := vs.blockchain.VerifySingleTxnSoftHardConstraints(tx, txn, vs.Config.Distribution, vs.Config.CreateBlockVerifyTxn, TxnSigned); err != nil {
+			switch err.(type) {
+			case ErrTxnViolatesHardConstraint, ErrTxnViolatesSoftConstraint:
+				logger.Warningf("Transaction %s violates constraints: %v", txn.Hash().Hex(), err)
+				exclude(txn, err)
+				continue
+			default:
+				return nil, err
+			}
+		}
+
+		if !filter.empty() {
+			inputs, err := vs.blockchain.Unspent().GetArray(tx, txn.In)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := filter.check(txn, inputs); err != nil {
+				exclude(txn, err)
+				continue
+			}
+		}
+
+		received[txn.Hash()] = ut.Received
+		txns = append(txns, txn)
+	}
+
+	if nRemoved := len(uts) - len(txns); nRemoved > 0 {
+		logger.Infof("CreateBlock ignored %d transactions violating constraints or the block policy", nRemoved)
+	}
+
+	if len(txns) == 0 {
+		return nb, nil
+	}
+
+	head, err := vs.blockchain.Head(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch policy.ordering() {
+	case TxnOrderingFIFO:
+		// Sort them by earliest received, then by hash
+		sort.SliceStable(txns, func(i, j int) bool {
+			ri := received[txns[i].Hash()]
+			rj := received[txns[j].Hash()]
+			if ri != rj {
+				return ri < rj
+			}
+			hi := txns[i].Hash()
+			hj := txns[j].Hash()
+			return bytes.Compare(hi[:], hj[:]) < 0
+		})
+	default:
+		// Sort them by highest fee per kilobyte
+		txns, err = coin.SortTransactions(txns, vs.blockchain.TransactionFee(tx, head.Time()))
+		if err != nil {
+			logger.Critical().WithError(err).Error("SortTransactions failed, no block can be made until the offending transaction is removed")
+			return nil, err
+		}
+	}
+
+	// Apply block size transaction limit
+	chosen, err := txns.TruncateBytesTo(nb.MaxSize)
+	if err != nil {
+		logger.Critical().WithError(err).Error("TruncateBytesTo failed, no block can be made until the offending transaction is removed")
+		return nil, err
+	}
+
+	for _, txn := range txns[len(chosen):] {
+		exclude(txn, ErrTxnExceedsBlockSize)
+	}
+
+	if maxTxns := policy.maxTransactions(); len(chosen) > maxTxns {
+		for _, txn := range chosen[maxTxns:] {
+			exclude(txn, ErrTxnExceedsBlockTransactions)
+		}
+		chosen = chosen[:maxTxns]
+	}
+
+	if len(chosen) == 0 {
+		logger.Panic("TruncateBytesTo removed all transactions")
+	}
+
+	nb.Size, err = chosen.Size()
+	if err != nil {
+		return nil, err
+	}
+
+	nb.Block, err = vs.blockchain.NewBlock(tx, chosen, when)
+	if err != nil {
+		logger.Warningf("blockchain.NewBlock failed: %v", err)
+		return nil, err
+	}
+
+	return nb, nil
+}
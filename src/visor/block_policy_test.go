@@ -0,0 +1,228 @@
+package visor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/params"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/historydb"
+)
+
+func TestParseTxnOrdering(t *testing.T) {
+	o, err := ParseTxnOrdering("fee")
+	require.NoError(t, err)
+	require.Equal(t, TxnOrderingFee, o)
+
+	o, err = ParseTxnOrdering("fifo")
+	require.NoError(t, err)
+	require.Equal(t, TxnOrderingFIFO, o)
+
+	_, err = ParseTxnOrdering("lifo")
+	testutil.RequireError(t, err, `Invalid transaction ordering "lifo", must be one of fee, fifo`)
+}
+
+func TestBlockPolicyValidate(t *testing.T) {
+	require.NoError(t, NewBlockPolicy().Validate())
+	require.NoError(t, BlockPolicy{}.Validate())
+
+	err := BlockPolicy{MaxTransactions: -1}.Validate()
+	testutil.RequireError(t, err, "BlockPolicy.MaxTransactions must be >= 0 and <= 65535")
+
+	err = BlockPolicy{MaxTransactions: coin.MaxBlockTransactions + 1}.Validate()
+	testutil.RequireError(t, err, "BlockPolicy.MaxTransactions must be >= 0 and <= 65535")
+
+	err = BlockPolicy{Ordering: "lifo"}.Validate()
+	testutil.RequireError(t, err, `Invalid transaction ordering "lifo", must be one of fee, fifo`)
+}
+
+func TestVisorNextBlockPolicy(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolPolicy{})
+	require.NoError(t, err)
+
+	cfg := NewConfig()
+	cfg.IsBlockPublisher = true
+	cfg.BlockchainPubkey = genPublic
+	cfg.BlockchainSeckey = genSecret
+	cfg.GenesisAddress = genAddress
+
+	v := &Visor{
+		Config:      cfg,
+		unconfirmed: unconfirmed,
+		blockchain:  bc,
+		db:          db,
+		history:     historydb.New(),
+	}
+
+	gb := addGenesisBlockToVisor(t, v)
+
+	inject := func(txn coin.Transaction) {
+		err := db.Update("", func(tx *dbutil.Tx) error {
+			known, softErr, err := unconfirmed.InjectTransaction(tx, bc, txn, params.MainNetDistribution, v.Config.UnconfirmedVerifyTxn)
+			require.False(t, known)
+			require.Nil(t, softErr)
+			return err
+		})
+		require.NoError(t, err)
+	}
+
+	// Split the genesis output to spend it in several transactions
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	inject(makeUnspentsTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, 10, params.UserVerifyTxn.MaxDropletPrecision))
+	sb, err := v.CreateAndExecuteBlock()
+	require.NoError(t, err)
+	uxs = coin.CreateUnspents(sb.Head, sb.Body.Transactions[0])
+
+	// The empty pool has no next block
+	nb, err := v.GetNextBlock()
+	require.NoError(t, err)
+	require.Nil(t, nb.Block)
+	require.Equal(t, 0, nb.Pending)
+
+	toAddr := testutil.MakeAddress()
+	deniedAddr := testutil.MakeAddress()
+
+	// Inject transactions with fees in a different order than they are received
+	var coins uint64 = 9e6
+	var f uint64 = 10
+	txns := coin.Transactions{
+		makeSpendTxWithFee(t, coin.UxArray{uxs[0]}, []cipher.SecKey{genSecret}, toAddr, coins, f),
+		makeSpendTxWithFee(t, coin.UxArray{uxs[1]}, []cipher.SecKey{genSecret}, toAddr, coins, f*4),
+		makeSpendTxWithFee(t, coin.UxArray{uxs[2]}, []cipher.SecKey{genSecret}, toAddr, coins, f*2),
+		makeSpendTxWithFee(t, coin.UxArray{uxs[3]}, []cipher.SecKey{genSecret}, deniedAddr, coins, f*3),
+	}
+	for _, txn := range txns {
+		inject(txn)
+		// Received times must differ for the FIFO ordering
+		time.Sleep(time.Millisecond)
+	}
+
+	nextBlock := func(p BlockPolicy) *NextBlock {
+		v.Config.BlockPolicy = p
+		nb, err := v.GetNextBlock()
+		require.NoError(t, err)
+		require.Equal(t, len(txns), nb.Pending)
+		return nb
+	}
+
+	blockTxns := func(nb *NextBlock) coin.Transactions {
+		require.NotNil(t, nb.Block)
+		return nb.Block.Body.Transactions
+	}
+
+	// Highest fee first
+	nb = nextBlock(NewBlockPolicy())
+	require.Equal(t, coin.Transactions{txns[1], txns[3], txns[2], txns[0]}, blockTxns(nb))
+	require.Empty(t, nb.Excluded)
+	size, err := txns.Size()
+	require.NoError(t, err)
+	require.Equal(t, size, nb.Size)
+
+	// Earliest received first
+	nb = nextBlock(BlockPolicy{
+		Ordering: TxnOrderingFIFO,
+	})
+	require.Equal(t, txns, blockTxns(nb))
+
+	// Max transactions
+	nb = nextBlock(BlockPolicy{
+		Ordering:        TxnOrderingFIFO,
+		MaxTransactions: 2,
+	})
+	require.Equal(t, txns[:2], blockTxns(nb))
+	require.Equal(t, []ExcludedTransaction{
+		{Hash: txns[2].Hash(), Err: ErrTxnExceedsBlockTransactions},
+		{Hash: txns[3].Hash(), Err: ErrTxnExceedsBlockTransactions},
+	}, nb.Excluded)
+
+	// Max block size
+	v.Config.MaxBlockTransactionsSize, err = txns[:3].Size()
+	require.NoError(t, err)
+	nb = nextBlock(BlockPolicy{
+		Ordering: TxnOrderingFIFO,
+	})
+	require.Equal(t, txns[:3], blockTxns(nb))
+	require.Equal(t, []ExcludedTransaction{
+		{Hash: txns[3].Hash(), Err: ErrTxnExceedsBlockSize},
+	}, nb.Excluded)
+	v.Config.MaxBlockTransactionsSize = NewConfig().MaxBlockTransactionsSize
+
+	// Denied output address
+	nb = nextBlock(BlockPolicy{
+		DenyAddresses: []cipher.Address{deniedAddr},
+	})
+	require.Equal(t, coin.Transactions{txns[1], txns[2], txns[0]}, blockTxns(nb))
+	require.Equal(t, []ExcludedTransaction{
+		{Hash: txns[3].Hash(), Err: ErrTxnDeniedAddress},
+	}, nb.Excluded)
+
+	// Denied input address
+	nb = nextBlock(BlockPolicy{
+		DenyAddresses: []cipher.Address{genAddress},
+	})
+	require.Nil(t, nb.Block)
+	require.Len(t, nb.Excluded, len(txns))
+	for _, e := range nb.Excluded {
+		require.Equal(t, ErrTxnDeniedAddress, e.Err)
+	}
+
+	// Allowed input address
+	nb = nextBlock(BlockPolicy{
+		AllowAddresses: []cipher.Address{genAddress},
+	})
+	require.Len(t, blockTxns(nb), len(txns))
+	require.Empty(t, nb.Excluded)
+
+	// Not allowed input address
+	nb = nextBlock(BlockPolicy{
+		AllowAddresses: []cipher.Address{toAddr},
+	})
+	require.Nil(t, nb.Block)
+	require.Len(t, nb.Excluded, len(txns))
+	for _, e := range nb.Excluded {
+		require.Equal(t, ErrTxnNotAllowedAddress, e.Err)
+	}
+
+	// No block is created if the policy excludes all transactions
+	when := sb.Time() + 100
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		_, err := v.createBlock(tx, when)
+		testutil.RequireError(t, err, "No transactions after filtering for constraint violations and the block policy")
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The created block matches the next block
+	v.Config.BlockPolicy = BlockPolicy{
+		Ordering:        TxnOrderingFIFO,
+		MaxTransactions: 3,
+	}
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		var err error
+		sb, err = v.createBlock(tx, when)
+		if err != nil {
+			return err
+		}
+		return v.executeSignedBlock(tx, sb)
+	})
+	require.NoError(t, err)
+	require.Equal(t, txns[:3], sb.Body.Transactions)
+
+	nb, err = v.GetNextBlock()
+	require.NoError(t, err)
+	require.Equal(t, 1, nb.Pending)
+	require.Equal(t, txns[3:], blockTxns(nb))
+}
@@ -74,6 +74,21 @@ type chainStore interface {
 	GetGenesisBlock(*dbutil.Tx) (*coin.SignedBlock, error)
 	GetBlockSignature(*dbutil.Tx, *coin.Block) (cipher.Sig, bool, error)
 	ForEachBlock(*dbutil.Tx, func(*coin.Block) error) error
+	UnspentCommitment(*dbutil.Tx) (cipher.SHA256, error)
+	MaybeBuildHistoryIndex(*dbutil.Tx) error
+	AddressHistory(*dbutil.Tx, cipher.Address, uint64, uint64) ([]blockdb.HistoryEntry, uint64, error)
+	MaybeBuildTxnIndex(*dbutil.Tx) error
+	TxnProof(*dbutil.Tx, cipher.SHA256) (*blockdb.TxnProof, error)
+	MaybeBuildBalanceIndex(*dbutil.Tx) error
+	Supply(*dbutil.Tx) (blockdb.BalanceTotal, error)
+	AddressBalanceTotals(*dbutil.Tx, []cipher.Address) ([]blockdb.BalanceTotal, error)
+	MaybeBuildUxDeltaIndex(*dbutil.Tx) error
+	AddressBalanceTotalsAtSeq(*dbutil.Tx, []cipher.Address, uint64) ([]blockdb.BalanceTotal, error)
+	ForEachAddressByCoins(*dbutil.Tx, func(cipher.Address, uint64) (bool, error)) error
+	MaybeBuildProgramStateIndex(*dbutil.Tx) error
+	ProgramState(*dbutil.Tx, cipher.SHA256, uint64) ([]byte, bool, error)
+	LatestProgramState(*dbutil.Tx, cipher.SHA256, uint64) (blockdb.ProgramStateEntry, bool, error)
+	CheckIndexes(*dbutil.Tx) error
 }
 
 // DefaultWalker default blockchain walker
@@ -98,6 +113,9 @@ func CreateBuckets(db *dbutil.DB) error {
 		return dbutil.CreateBuckets(tx, [][]byte{
 			UnconfirmedTxnsBkt,
 			UnconfirmedUnspentsBkt,
+			UnconfirmedFeeIndexBkt,
+			UnconfirmedFeeIndexTxnsBkt,
+			UnconfirmedMetaBkt,
 		})
 	})
 }
@@ -109,13 +127,27 @@ type BlockchainConfig struct {
 	// node will throw the error and return.
 	Arbitrating bool
 	Pubkey      cipher.PubKey
+	// Chain is the name of the chain, recorded in the database so that the databases of different chains are not mixed up.
+	// Defaults to DefaultChain.
+	Chain string
+	// GenesisHash is the hash of the genesis block of the chain, recorded in the database with the chain name
+	// so that a chain name can not be reused with other genesis parameters. Not checked if empty.
+	GenesisHash cipher.SHA256
+	// Known-good block hashes. Blocks at the seq of a checkpoint must have its hash.
+	Checkpoints []Checkpoint
+	// Verify the transaction input signatures of the blocks up to the latest checkpoint.
+	// Otherwise only the block signatures are verified for these blocks, which speeds up the initial sync.
+	VerifyCheckpointedSignatures bool
+	// Number of decoded blocks cached in memory, 0 to disable the cache
+	BlockCacheSize int
 }
 
 // Blockchain maintains blockchain and provides apis for accessing the chain.
 type Blockchain struct {
-	db    *dbutil.DB
-	cfg   BlockchainConfig
-	store chainStore
+	db          *dbutil.DB
+	cfg         BlockchainConfig
+	store       chainStore
+	checkpoints checkpoints
 }
 
 // NewBlockchain creates a Blockchain
@@ -124,11 +156,18 @@ func NewBlockchain(db *dbutil.DB, cfg BlockchainConfig) (*Blockchain, error) {
 	if err != nil {
 		return nil, err
 	}
+	chainstore.SetBlockCacheSize(cfg.BlockCacheSize)
+
+	cps, err := newCheckpoints(cfg.Checkpoints)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Blockchain{
-		cfg:   cfg,
-		db:    db,
-		store: chainstore,
+		cfg:         cfg,
+		db:          db,
+		store:       chainstore,
+		checkpoints: cps,
 	}, nil
 }
 
@@ -157,6 +196,84 @@ func (bc *Blockchain) Unspent() blockdb.UnspentPooler {
 	return bc.store.UnspentPool()
 }
 
+// UnspentCommitment returns the commitment hash of the unspent outputs
+func (bc *Blockchain) UnspentCommitment(tx *dbutil.Tx) (cipher.SHA256, error) {
+	return bc.store.UnspentCommitment(tx)
+}
+
+// MaybeBuildHistoryIndex builds the address history index if it is behind the head block
+func (bc *Blockchain) MaybeBuildHistoryIndex(tx *dbutil.Tx) error {
+	return bc.store.MaybeBuildHistoryIndex(tx)
+}
+
+// AddressHistory returns up to limit confirmed transactions of an address after skipping the first offset,
+// oldest first, and the total number of transactions of the address
+func (bc *Blockchain) AddressHistory(tx *dbutil.Tx, addr cipher.Address, offset, limit uint64) ([]blockdb.HistoryEntry, uint64, error) {
+	return bc.store.AddressHistory(tx, addr, offset, limit)
+}
+
+// MaybeBuildTxnIndex builds the transaction index if it is behind the head block
+func (bc *Blockchain) MaybeBuildTxnIndex(tx *dbutil.Tx) error {
+	return bc.store.MaybeBuildTxnIndex(tx)
+}
+
+// TxnProof returns the proof that a confirmed transaction is in its block, or nil if the transaction is not confirmed
+func (bc *Blockchain) TxnProof(tx *dbutil.Tx, txid cipher.SHA256) (*blockdb.TxnProof, error) {
+	return bc.store.TxnProof(tx, txid)
+}
+
+// MaybeBuildBalanceIndex builds the address balance index if it is behind the head block
+func (bc *Blockchain) MaybeBuildBalanceIndex(tx *dbutil.Tx) error {
+	return bc.store.MaybeBuildBalanceIndex(tx)
+}
+
+// Supply returns the coins and coin hours of all unspent outputs
+func (bc *Blockchain) Supply(tx *dbutil.Tx) (blockdb.BalanceTotal, error) {
+	return bc.store.Supply(tx)
+}
+
+// AddressBalanceTotals returns the coins and coin hours of each address's unspent outputs
+func (bc *Blockchain) AddressBalanceTotals(tx *dbutil.Tx, addrs []cipher.Address) ([]blockdb.BalanceTotal, error) {
+	return bc.store.AddressBalanceTotals(tx, addrs)
+}
+
+// MaybeBuildUxDeltaIndex builds the index of the outputs spent and created by each block if it is behind the head block
+func (bc *Blockchain) MaybeBuildUxDeltaIndex(tx *dbutil.Tx) error {
+	return bc.store.MaybeBuildUxDeltaIndex(tx)
+}
+
+// AddressBalanceTotalsAtSeq returns the coins and coin hours of each address's unspent outputs
+// after the block at seq was executed
+func (bc *Blockchain) AddressBalanceTotalsAtSeq(tx *dbutil.Tx, addrs []cipher.Address, seq uint64) ([]blockdb.BalanceTotal, error) {
+	return bc.store.AddressBalanceTotalsAtSeq(tx, addrs, seq)
+}
+
+// ForEachAddressByCoins calls f for each address with coins, from the most coins to the least,
+// until f returns false
+func (bc *Blockchain) ForEachAddressByCoins(tx *dbutil.Tx, f func(cipher.Address, uint64) (bool, error)) error {
+	return bc.store.ForEachAddressByCoins(tx, f)
+}
+
+// MaybeBuildProgramStateIndex builds the program state index if it is behind the head block
+func (bc *Blockchain) MaybeBuildProgramStateIndex(tx *dbutil.Tx) error {
+	return bc.store.MaybeBuildProgramStateIndex(tx)
+}
+
+// CheckIndexes returns blockdb.ErrIndexNotBuilt if an index is not at the height of the head block
+func (bc *Blockchain) CheckIndexes(tx *dbutil.Tx) error {
+	return bc.store.CheckIndexes(tx)
+}
+
+// ProgramState returns the state of a CX program produced by the block at seq
+func (bc *Blockchain) ProgramState(tx *dbutil.Tx, hash cipher.SHA256, seq uint64) ([]byte, bool, error) {
+	return bc.store.ProgramState(tx, hash, seq)
+}
+
+// LatestProgramState returns the most recent state of a CX program produced by a block at or before seq
+func (bc *Blockchain) LatestProgramState(tx *dbutil.Tx, hash cipher.SHA256, seq uint64) (blockdb.ProgramStateEntry, bool, error) {
+	return bc.store.LatestProgramState(tx, hash, seq)
+}
+
 // Len returns the length of current blockchain.
 func (bc Blockchain) Len(tx *dbutil.Tx) (uint64, error) {
 	return bc.store.Len(tx)
@@ -200,7 +317,7 @@ func (bc Blockchain) NewBlock(tx *dbutil.Tx, txns coin.Transactions, currentTime
 		return nil, errors.New("Time can only move forward")
 	}
 
-	txns, err = bc.processTransactions(tx, txns)
+	txns, err = bc.processTransactions(tx, txns, TxnSigned)
 	if err != nil {
 		return nil, err
 	}
@@ -222,7 +339,7 @@ func (bc Blockchain) NewBlock(tx *dbutil.Tx, txns coin.Transactions, currentTime
 		if err := bc.verifyBlockHeader(tx, *b); err != nil {
 			return nil, err
 		}
-		txns, err := bc.processTransactions(tx, b.Body.Transactions)
+		txns, err := bc.processTransactions(tx, b.Body.Transactions, TxnSigned)
 		if err != nil {
 			logger.Panicf("bc.processTransactions second verification call failed: %v", err)
 		}
@@ -232,6 +349,12 @@ func (bc Blockchain) NewBlock(tx *dbutil.Tx, txns coin.Transactions, currentTime
 }
 
 func (bc *Blockchain) processBlock(tx *dbutil.Tx, b coin.SignedBlock) (coin.SignedBlock, error) {
+	// A block which does not match the checkpoint at its seq is on a fork of the known-good blockchain
+	if err := bc.checkpoints.verify(b.Block); err != nil {
+		logger.WithError(err).Warning("Block does not match checkpoint")
+		return coin.SignedBlock{}, err
+	}
+
 	length, err := bc.Len(tx)
 	if err != nil {
 		return coin.SignedBlock{}, err
@@ -249,7 +372,14 @@ func (bc *Blockchain) processBlock(tx *dbutil.Tx, b coin.SignedBlock) (coin.Sign
 				return coin.SignedBlock{}, err
 			}
 
-			txns, err := bc.processTransactions(tx, b.Body.Transactions)
+			// The input signatures of the transactions of known-good blocks don't need to be verified.
+			// The block signature is still verified, which is enough to prove the block is from the block publisher.
+			signed := TxnSigned
+			if !bc.cfg.VerifyCheckpointedSignatures && bc.checkpoints.covers(b.Head.BkSeq) {
+				signed = txnSignedCheckpointed
+			}
+
+			txns, err := bc.processTransactions(tx, b.Body.Transactions, signed)
 			if err != nil {
 				return coin.SignedBlock{}, err
 			}
@@ -326,6 +456,10 @@ func (bc Blockchain) verifyUxHash(tx *dbutil.Tx, b coin.Block) error {
 // VerifyBlockTxnConstraints checks that the transaction does not violate hard constraints,
 // for transactions that are already included in a block.
 func (bc Blockchain) VerifyBlockTxnConstraints(tx *dbutil.Tx, txn coin.Transaction) error {
+	return bc.verifyBlockTxnConstraints(tx, txn, TxnSigned)
+}
+
+func (bc Blockchain) verifyBlockTxnConstraints(tx *dbutil.Tx, txn coin.Transaction, signed TxnSignedFlag) error {
 	// NOTE: Unspent().GetArray() returns an error if not all txn.In can be found
 	// This prevents double spends
 	uxIn, err := bc.Unspent().GetArray(tx, txn.In)
@@ -343,11 +477,11 @@ func (bc Blockchain) VerifyBlockTxnConstraints(tx *dbutil.Tx, txn coin.Transacti
 		return err
 	}
 
-	return bc.verifyBlockTxnHardConstraints(tx, txn, head, uxIn)
+	return bc.verifyBlockTxnHardConstraints(tx, txn, head, uxIn, signed)
 }
 
-func (bc Blockchain) verifyBlockTxnHardConstraints(tx *dbutil.Tx, txn coin.Transaction, head *coin.SignedBlock, uxIn coin.UxArray) error {
-	if err := VerifyBlockTxnConstraints(txn, head.Head, uxIn); err != nil {
+func (bc Blockchain) verifyBlockTxnHardConstraints(tx *dbutil.Tx, txn coin.Transaction, head *coin.SignedBlock, uxIn coin.UxArray, signed TxnSignedFlag) error {
+	if err := verifyBlockTxnConstraints(txn, head.Head, uxIn, signed); err != nil {
 		return err
 	}
 
@@ -527,7 +661,7 @@ func (bc Blockchain) GetLastBlocks(tx *dbutil.Tx, num uint64) ([]coin.SignedBloc
 // TODO:
 //  - move arbitration to visor
 //  - blockchain should have strict checking
-func (bc Blockchain) processTransactions(tx *dbutil.Tx, txs coin.Transactions) (coin.Transactions, error) {
+func (bc Blockchain) processTransactions(tx *dbutil.Tx, txs coin.Transactions, signed TxnSignedFlag) (coin.Transactions, error) {
 	// copy txs so that the following code won't modify the original txns
 	txns := make(coin.Transactions, len(txs))
 	copy(txns, txs)
@@ -561,7 +695,7 @@ func (bc Blockchain) processTransactions(tx *dbutil.Tx, txs coin.Transactions) (
 	for i, txn := range txns {
 		// Check the transaction against itself.  This covers the hash,
 		// signature indices and duplicate spends within itself
-		if err := bc.VerifyBlockTxnConstraints(tx, txn); err != nil {
+		if err := bc.verifyBlockTxnConstraints(tx, txn, signed); err != nil {
 			switch err.(type) {
 			case ErrTxnViolatesSoftConstraint:
 				logger.Critical().WithError(err).Panic("bc.VerifyBlockTxnConstraints should not return a ErrTxnViolatesSoftConstraint error")
@@ -27,7 +27,7 @@ func feeCalc(t *coin.Transaction) (uint64, error) {
 
 func addGenesisBlockToBlockchain(t *testing.T, bc *Blockchain) *coin.SignedBlock {
 	// create genesis block
-	gb, err := coin.NewGenesisBlock(genAddress, genCoins, genTime)
+	gb, err := coin.NewGenesisBlock(genAddress, genCoins, genTime, nil)
 	require.NoError(t, err)
 	gbSig := cipher.MustSignHash(gb.HashHeader(), genSecret)
 
@@ -61,10 +61,10 @@ func makeSpendTxn(t *testing.T, uxs coin.UxArray, keys []cipher.SecKey, toAddr c
 
 	hours := totalHours / 4
 
-	err := spendTxn.PushOutput(toAddr, coins, hours)
+	err := spendTxn.PushOutput(toAddr, coins, hours, nil)
 	require.NoError(t, err)
 	if totalCoins-coins != 0 {
-		err := spendTxn.PushOutput(uxs[0].Body.Address, totalCoins-coins, totalHours/4)
+		err := spendTxn.PushOutput(uxs[0].Body.Address, totalCoins-coins, totalHours/4, nil)
 		require.NoError(t, err)
 	}
 	spendTxn.SignInputs(keys)
@@ -142,6 +142,66 @@ func (fcs *fakeChainStore) ForEachBlock(tx *dbutil.Tx, f func(*coin.Block) error
 	return nil
 }
 
+func (fcs *fakeChainStore) UnspentCommitment(tx *dbutil.Tx) (cipher.SHA256, error) {
+	return cipher.SHA256{}, nil
+}
+
+func (fcs *fakeChainStore) MaybeBuildHistoryIndex(tx *dbutil.Tx) error {
+	return nil
+}
+
+func (fcs *fakeChainStore) AddressHistory(tx *dbutil.Tx, addr cipher.Address, offset, limit uint64) ([]blockdb.HistoryEntry, uint64, error) {
+	return nil, 0, nil
+}
+
+func (fcs *fakeChainStore) MaybeBuildTxnIndex(tx *dbutil.Tx) error {
+	return nil
+}
+
+func (fcs *fakeChainStore) TxnProof(tx *dbutil.Tx, txid cipher.SHA256) (*blockdb.TxnProof, error) {
+	return nil, nil
+}
+
+func (fcs *fakeChainStore) MaybeBuildBalanceIndex(tx *dbutil.Tx) error {
+	return nil
+}
+
+func (fcs *fakeChainStore) Supply(tx *dbutil.Tx) (blockdb.BalanceTotal, error) {
+	return blockdb.BalanceTotal{}, nil
+}
+
+func (fcs *fakeChainStore) AddressBalanceTotals(tx *dbutil.Tx, addrs []cipher.Address) ([]blockdb.BalanceTotal, error) {
+	return make([]blockdb.BalanceTotal, len(addrs)), nil
+}
+
+func (fcs *fakeChainStore) MaybeBuildUxDeltaIndex(tx *dbutil.Tx) error {
+	return nil
+}
+
+func (fcs *fakeChainStore) AddressBalanceTotalsAtSeq(tx *dbutil.Tx, addrs []cipher.Address, seq uint64) ([]blockdb.BalanceTotal, error) {
+	return make([]blockdb.BalanceTotal, len(addrs)), nil
+}
+
+func (fcs *fakeChainStore) ForEachAddressByCoins(tx *dbutil.Tx, f func(cipher.Address, uint64) (bool, error)) error {
+	return nil
+}
+
+func (fcs *fakeChainStore) MaybeBuildProgramStateIndex(tx *dbutil.Tx) error {
+	return nil
+}
+
+func (fcs *fakeChainStore) CheckIndexes(tx *dbutil.Tx) error {
+	return nil
+}
+
+func (fcs *fakeChainStore) ProgramState(tx *dbutil.Tx, hash cipher.SHA256, seq uint64) ([]byte, bool, error) {
+	return nil, false, nil
+}
+
+func (fcs *fakeChainStore) LatestProgramState(tx *dbutil.Tx, hash cipher.SHA256, seq uint64) (blockdb.ProgramStateEntry, bool, error) {
+	return blockdb.ProgramStateEntry{}, false, nil
+}
+
 func makeBlock(t *testing.T, preBlock coin.Block, tm uint64) *coin.Block {
 	uxHash := testutil.RandSHA256(t)
 	tx := coin.Transaction{}
@@ -152,7 +212,7 @@ func makeBlock(t *testing.T, preBlock coin.Block, tm uint64) *coin.Block {
 
 func makeBlocks(t *testing.T, n int) []coin.SignedBlock {
 	var bs []coin.SignedBlock
-	preBlock, err := coin.NewGenesisBlock(genAddress, genCoins, genTime)
+	preBlock, err := coin.NewGenesisBlock(genAddress, genCoins, genTime, nil)
 	require.NoError(t, err)
 	bs = append(bs, coin.SignedBlock{Block: *preBlock})
 
@@ -696,7 +756,7 @@ func TestProcessTransactions(t *testing.T) {
 			}
 
 			err = db.View("", func(tx *dbutil.Tx) error {
-				_, err := bc.processTransactions(tx, txns)
+				_, err := bc.processTransactions(tx, txns, TxnSigned)
 				require.EqualValues(t, tc.err, err)
 				return nil
 			})
@@ -771,7 +831,7 @@ func TestProcessBlock(t *testing.T) {
 		store: store,
 	}
 
-	gb, err := coin.NewGenesisBlock(genAddress, genCoins, genTime)
+	gb, err := coin.NewGenesisBlock(genAddress, genCoins, genTime, nil)
 	require.NoError(t, err)
 
 	sb := coin.SignedBlock{
@@ -829,7 +889,7 @@ func TestExecuteBlock(t *testing.T) {
 		store: store,
 	}
 
-	gb, err := coin.NewGenesisBlock(genAddress, genCoins, genTime)
+	gb, err := coin.NewGenesisBlock(genAddress, genCoins, genTime, nil)
 	require.NoError(t, err)
 
 	sb := coin.SignedBlock{
@@ -49,7 +49,7 @@ func MakeBlockchain(t *testing.T, db *dbutil.DB, seckey cipher.SecKey) *Blockcha
 		Pubkey: pubkey,
 	})
 	require.NoError(t, err)
-	gb, err := coin.NewGenesisBlock(GenesisAddress, GenesisCoins, GenesisTime)
+	gb, err := coin.NewGenesisBlock(GenesisAddress, GenesisCoins, GenesisTime, nil)
 	if err != nil {
 		panic(fmt.Errorf("create genesis block failed: %v", err))
 	}
@@ -140,13 +140,13 @@ func makeTransactionForChain(t *testing.T, tx *dbutil.Tx, bc *Blockchain, ux coi
 	err = txn.PushInput(ux.Hash())
 	require.NoError(t, err)
 
-	err = txn.PushOutput(toAddr, amt, hours)
+	err = txn.PushOutput(toAddr, amt, hours, nil)
 	require.NoError(t, err)
 
 	// Change output
 	coinsOut := ux.Body.Coins - amt
 	if coinsOut > 0 {
-		err := txn.PushOutput(GenesisAddress, coinsOut, chrs-hours-fee)
+		err := txn.PushOutput(GenesisAddress, coinsOut, chrs-hours-fee, nil)
 		require.NoError(t, err)
 	}
 
@@ -182,11 +182,11 @@ func makeLostCoinTxn(t *testing.T, uxs coin.UxArray, keys []cipher.SecKey, toAdd
 		totalHours += ux.Body.Hours
 	}
 
-	err := txn.PushOutput(toAddr, coins, totalHours/4)
+	err := txn.PushOutput(toAddr, coins, totalHours/4, nil)
 	require.NoError(t, err)
 	changeCoins := totalCoins - coins
 	if changeCoins > 0 {
-		err := txn.PushOutput(uxs[0].Body.Address, changeCoins-1, totalHours/4)
+		err := txn.PushOutput(uxs[0].Body.Address, changeCoins-1, totalHours/4, nil)
 		require.NoError(t, err)
 	}
 
@@ -208,13 +208,13 @@ func makeDuplicateUxOutTxn(t *testing.T, uxs coin.UxArray, keys []cipher.SecKey,
 		totalHours += ux.Body.Hours
 	}
 
-	err := txn.PushOutput(toAddr, coins, totalHours/8)
+	err := txn.PushOutput(toAddr, coins, totalHours/8, nil)
 	require.NoError(t, err)
-	err = txn.PushOutput(toAddr, coins, totalHours/8)
+	err = txn.PushOutput(toAddr, coins, totalHours/8, nil)
 	require.NoError(t, err)
 	changeCoins := totalCoins - coins
 	if changeCoins > 0 {
-		err := txn.PushOutput(uxs[0].Body.Address, changeCoins, totalHours/4)
+		err := txn.PushOutput(uxs[0].Body.Address, changeCoins, totalHours/4, nil)
 		require.NoError(t, err)
 	}
 
@@ -263,13 +263,13 @@ func makeUnspentsTxn(t *testing.T, uxs coin.UxArray, keys []cipher.SecKey, toAdd
 		// otherwise the output hashes will be duplicated and the transaction
 		// will be invalid
 		spendHours := hours - uint64(i)
-		err := spendTxn.PushOutput(toAddr, coins, spendHours)
+		err := spendTxn.PushOutput(toAddr, coins, spendHours, nil)
 		require.NoError(t, err)
 	}
 
 	// Add change output, if necessary
 	if changeCoins != 0 {
-		err := spendTxn.PushOutput(uxs[0].Body.Address, changeCoins, changeHours)
+		err := spendTxn.PushOutput(uxs[0].Body.Address, changeCoins, changeHours, nil)
 		require.NoError(t, err)
 	}
 
@@ -298,10 +298,10 @@ func makeSpendTxWithFee(t *testing.T, uxs coin.UxArray, keys []cipher.SecKey, to
 
 	spendHours := totalHours/2 - fee
 
-	err := spendTxn.PushOutput(toAddr, coins, spendHours)
+	err := spendTxn.PushOutput(toAddr, coins, spendHours, nil)
 	require.NoError(t, err)
 	if totalCoins != coins {
-		err := spendTxn.PushOutput(uxs[0].Body.Address, totalCoins-coins, 0)
+		err := spendTxn.PushOutput(uxs[0].Body.Address, totalCoins-coins, 0, nil)
 		require.NoError(t, err)
 	}
 	spendTxn.SignInputs(keys)
@@ -327,10 +327,10 @@ func makeSpendTxWithHoursBurned(t *testing.T, uxs coin.UxArray, keys []cipher.Se
 
 	spendHours := totalHours - hoursBurned
 
-	err := spendTxn.PushOutput(toAddr, coins, spendHours)
+	err := spendTxn.PushOutput(toAddr, coins, spendHours, nil)
 	require.NoError(t, err)
 	if totalCoins != coins {
-		err := spendTxn.PushOutput(uxs[0].Body.Address, totalCoins-coins, 0)
+		err := spendTxn.PushOutput(uxs[0].Body.Address, totalCoins-coins, 0, nil)
 		require.NoError(t, err)
 	}
 	spendTxn.SignInputs(keys)
@@ -663,3 +663,77 @@ func testVerifyTransactionAddressLocking(t *testing.T, toAddr string, expectedEr
 		requireSoftViolation(t, expectedErr.Error(), err)
 	}
 }
+
+func TestVerifyTransactionMultisigActivation(t *testing.T) {
+	seq := params.MultisigActivationSeq
+	defer func() {
+		params.MultisigActivationSeq = seq
+	}()
+
+	db, close := prepareDB(t)
+	defer close()
+
+	bc := MakeBlockchain(t, db, GenesisSecret)
+
+	pubKeys := make([]cipher.PubKey, 2)
+	for i := range pubKeys {
+		pubKeys[i], _ = cipher.GenerateKeyPair()
+	}
+	msAddr := cipher.MustMultisigAddress(1, pubKeys)
+
+	// The transaction sends coins to a multisig address, so it can only be included in a block
+	// with seq >= params.MultisigActivationSeq. The next block has seq 1
+	params.MultisigActivationSeq = 0
+	txn := CreateGenesisSpendTransaction(t, db, bc, msAddr, GenesisCoins/2, 1e3, 1e3)
+	require.True(t, txn.UsesMultisig())
+
+	cases := []struct {
+		name          string
+		activationSeq uint64
+		err           error
+	}{
+		{
+			name:          "multisig disabled",
+			activationSeq: math.MaxUint64,
+			err:           NewErrTxnViolatesHardConstraint(coin.ErrMultisigNotEnabled),
+		},
+		{
+			name:          "multisig not active yet",
+			activationSeq: 2,
+			err:           NewErrTxnViolatesHardConstraint(ErrMultisigNotActive),
+		},
+		{
+			name:          "multisig active",
+			activationSeq: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			params.MultisigActivationSeq = tc.activationSeq
+
+			err := db.View("", func(tx *dbutil.Tx) error {
+				head, err := bc.Head(tx)
+				require.NoError(t, err)
+
+				uxIn, err := bc.Unspent().GetArray(tx, txn.In)
+				require.NoError(t, err)
+
+				err = VerifySingleTxnHardConstraints(txn, head.Head, uxIn, TxnSigned)
+				require.Equal(t, tc.err, err)
+
+				err = VerifyBlockTxnConstraints(txn, head.Head, uxIn)
+				require.Equal(t, tc.err, err)
+
+				_, err = bc.NewBlock(tx, coin.Transactions{txn}, GenesisTime+TimeIncrement)
+				if tc.err == nil {
+					require.NoError(t, err)
+				} else {
+					require.Error(t, err)
+				}
+				return nil
+			})
+			require.NoError(t, err)
+		})
+	}
+}
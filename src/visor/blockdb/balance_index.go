@@ -0,0 +1,383 @@
+package blockdb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/util/mathutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+var (
+	balanceIndexHeightKey = []byte("balance_index_height")
+	supplyKey             = []byte("supply")
+
+	// AddressBalancesBkt maps addresses to the BalanceTotal of their unspent outputs
+	AddressBalancesBkt = []byte("address_balances")
+	// RichlistIndexBkt indexes the addresses with coins by their coins, highest first.
+	// Keys are the inverted coins followed by the address, values are empty
+	RichlistIndexBkt = []byte("richlist_index")
+	// BalanceMetaBkt holds the BalanceTotal of all unspent outputs and the balance index metadata
+	BalanceMetaBkt = []byte("balance_meta")
+
+	errStopIteration = errors.New("stop iteration")
+)
+
+// BalanceTotal aggregates the coins and coin hours of a set of unspent outputs,
+// so that their coin hours at a given time can be computed without visiting each output
+type BalanceTotal struct {
+	Coins uint64
+	// Hours is the sum of the outputs' initial coin hours
+	Hours uint64
+	// CoinTime is the sum of the outputs' coins multiplied by their creation time, in whole coin seconds
+	CoinTime uint64
+}
+
+func uxCoinTime(coins, t uint64) (uint64, error) {
+	whole, err := mathutil.MultUint64(coins/1e6, t)
+	if err != nil {
+		return 0, err
+	}
+
+	droplets, err := mathutil.MultUint64(coins%1e6, t)
+	if err != nil {
+		return 0, err
+	}
+
+	return mathutil.AddUint64(whole, droplets/1e6)
+}
+
+// Add returns the total of b and c
+func (b BalanceTotal) Add(c BalanceTotal) (BalanceTotal, error) {
+	var err error
+	if b.Coins, err = mathutil.AddUint64(b.Coins, c.Coins); err != nil {
+		return BalanceTotal{}, err
+	}
+	if b.Hours, err = mathutil.AddUint64(b.Hours, c.Hours); err != nil {
+		return BalanceTotal{}, err
+	}
+	if b.CoinTime, err = mathutil.AddUint64(b.CoinTime, c.CoinTime); err != nil {
+		return BalanceTotal{}, err
+	}
+	return b, nil
+}
+
+// Sub returns the total of b without c
+func (b BalanceTotal) Sub(c BalanceTotal) (BalanceTotal, error) {
+	if c.Coins > b.Coins || c.Hours > b.Hours || c.CoinTime > b.CoinTime {
+		return BalanceTotal{}, errors.New("BalanceTotal.Sub underflow")
+	}
+
+	return BalanceTotal{
+		Coins:    b.Coins - c.Coins,
+		Hours:    b.Hours - c.Hours,
+		CoinTime: b.CoinTime - c.CoinTime,
+	}, nil
+}
+
+func newUxBalanceTotal(ux coin.UxOut) (BalanceTotal, error) {
+	ct, err := uxCoinTime(ux.Body.Coins, ux.Head.Time)
+	if err != nil {
+		return BalanceTotal{}, err
+	}
+
+	return BalanceTotal{
+		Coins:    ux.Body.Coins,
+		Hours:    ux.Body.Hours,
+		CoinTime: ct,
+	}, nil
+}
+
+// CoinHours returns the coin hours of the outputs at time t.
+// The earned coin hours are computed from the aggregate coin seconds of the outputs,
+// so they can be slightly higher than the sum of coin.UxOut.CoinHours, which rounds down each output.
+func (b BalanceTotal) CoinHours(t uint64) (uint64, error) {
+	ct, err := uxCoinTime(b.Coins, t)
+	if err != nil {
+		return 0, err
+	}
+
+	if ct < b.CoinTime {
+		return b.Hours, nil
+	}
+
+	return mathutil.AddUint64(b.Hours, (ct-b.CoinTime)/3600)
+}
+
+// BalanceIndex maintains the BalanceTotal of each address's unspent outputs, the BalanceTotal of all
+// unspent outputs, and an index of the addresses ordered by coins, as blocks are processed
+type BalanceIndex struct{}
+
+// NewBalanceIndex creates a BalanceIndex
+func NewBalanceIndex() *BalanceIndex {
+	return &BalanceIndex{}
+}
+
+func richlistKey(addr cipher.Address, coins uint64) []byte {
+	return append(dbutil.Itob(^coins), addr.Bytes()...)
+}
+
+func (bi *BalanceIndex) getHeight(tx *dbutil.Tx) (uint64, bool, error) {
+	v, err := dbutil.GetBucketValue(tx, BalanceMetaBkt, balanceIndexHeightKey)
+	if err != nil {
+		return 0, false, err
+	} else if v == nil {
+		return 0, false, nil
+	}
+
+	return dbutil.Btoi(v), true, nil
+}
+
+func (bi *BalanceIndex) setHeight(tx *dbutil.Tx, height uint64) error {
+	return dbutil.PutBucketValue(tx, BalanceMetaBkt, balanceIndexHeightKey, dbutil.Itob(height))
+}
+
+func getBalanceTotal(tx *dbutil.Tx, bkt, key []byte) (BalanceTotal, error) {
+	var b BalanceTotal
+
+	v, err := dbutil.GetBucketValueNoCopy(tx, bkt, key)
+	if err != nil {
+		return BalanceTotal{}, err
+	} else if v == nil {
+		return BalanceTotal{}, nil
+	}
+
+	if err := decodeBalanceTotalExact(v, &b); err != nil {
+		return BalanceTotal{}, err
+	}
+
+	return b, nil
+}
+
+func putBalanceTotal(tx *dbutil.Tx, bkt, key []byte, b BalanceTotal) error {
+	buf, err := encodeBalanceTotal(&b)
+	if err != nil {
+		return err
+	}
+
+	return dbutil.PutBucketValue(tx, bkt, key, buf)
+}
+
+// Supply returns the BalanceTotal of all unspent outputs
+func (bi *BalanceIndex) Supply(tx *dbutil.Tx) (BalanceTotal, error) {
+	return getBalanceTotal(tx, BalanceMetaBkt, supplyKey)
+}
+
+// Get returns the BalanceTotal of each address's unspent outputs
+func (bi *BalanceIndex) Get(tx *dbutil.Tx, addrs []cipher.Address) ([]BalanceTotal, error) {
+	totals := make([]BalanceTotal, len(addrs))
+	for i, a := range addrs {
+		var err error
+		totals[i], err = getBalanceTotal(tx, AddressBalancesBkt, a.Bytes())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return totals, nil
+}
+
+// ForEachByCoins calls f for each address with coins, from the most coins to the least.
+// Addresses with the same coins are visited in the order of their bytes.
+// Iteration stops if f returns false.
+func (bi *BalanceIndex) ForEachByCoins(tx *dbutil.Tx, f func(cipher.Address, uint64) (bool, error)) error {
+	err := dbutil.ForEach(tx, RichlistIndexBkt, func(k, v []byte) error {
+		if len(k) != 8+20+1+4 {
+			return errors.New("invalid richlist index key length")
+		}
+
+		addr, err := cipher.AddressFromBytes(k[8:])
+		if err != nil {
+			return err
+		}
+
+		ok, err := f(addr, ^dbutil.Btoi(k[:8]))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errStopIteration
+		}
+		return nil
+	})
+
+	if err == errStopIteration {
+		return nil
+	}
+	return err
+}
+
+// adjust applies the changes to the addresses' BalanceTotals and the supply
+func (bi *BalanceIndex) adjust(tx *dbutil.Tx, addrs []cipher.Address, add, sub map[cipher.Address]BalanceTotal) error {
+	supply, err := bi.Supply(tx)
+	if err != nil {
+		return err
+	}
+
+	for _, addr := range addrs {
+		prev, err := getBalanceTotal(tx, AddressBalancesBkt, addr.Bytes())
+		if err != nil {
+			return err
+		}
+
+		b, err := prev.Add(add[addr])
+		if err != nil {
+			return err
+		}
+		if b, err = b.Sub(sub[addr]); err != nil {
+			return fmt.Errorf("balance index of %s: %v", addr, err)
+		}
+
+		if supply, err = supply.Add(add[addr]); err != nil {
+			return err
+		}
+		if supply, err = supply.Sub(sub[addr]); err != nil {
+			return fmt.Errorf("balance index supply: %v", err)
+		}
+
+		if prev.Coins != 0 {
+			if err := dbutil.Delete(tx, RichlistIndexBkt, richlistKey(addr, prev.Coins)); err != nil {
+				return err
+			}
+		}
+		if b.Coins != 0 {
+			if err := dbutil.PutBucketValue(tx, RichlistIndexBkt, richlistKey(addr, b.Coins), []byte{}); err != nil {
+				return err
+			}
+		}
+
+		if b == (BalanceTotal{}) {
+			if err := dbutil.Delete(tx, AddressBalancesBkt, addr.Bytes()); err != nil {
+				return err
+			}
+		} else if err := putBalanceTotal(tx, AddressBalancesBkt, addr.Bytes(), b); err != nil {
+			return err
+		}
+	}
+
+	return putBalanceTotal(tx, BalanceMetaBkt, supplyKey, supply)
+}
+
+// ProcessBlock removes the outputs spent by a block and adds the outputs it creates to the index.
+// spent are the outputs spent by the block.
+func (bi *BalanceIndex) ProcessBlock(tx *dbutil.Tx, b *coin.SignedBlock, spent coin.UxArray) error {
+	var addrs []cipher.Address
+	add := make(map[cipher.Address]BalanceTotal)
+	sub := make(map[cipher.Address]BalanceTotal)
+
+	apply := func(m map[cipher.Address]BalanceTotal, ux coin.UxOut) error {
+		a := ux.Body.Address
+		if _, ok := add[a]; !ok {
+			if _, ok := sub[a]; !ok {
+				addrs = append(addrs, a)
+			}
+		}
+
+		t, err := newUxBalanceTotal(ux)
+		if err != nil {
+			return err
+		}
+
+		m[a], err = m[a].Add(t)
+		return err
+	}
+
+	for _, ux := range spent {
+		if err := apply(sub, ux); err != nil {
+			return err
+		}
+	}
+
+	for _, txn := range b.Body.Transactions {
+		for _, ux := range coin.CreateUnspents(b.Head, txn) {
+			if err := apply(add, ux); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := bi.adjust(tx, addrs, add, sub); err != nil {
+		return err
+	}
+
+	// Check that the index height is incremental
+	height, ok, err := bi.getHeight(tx)
+	if err != nil {
+		return err
+	}
+
+	if b.Head.BkSeq == 0 {
+		if ok {
+			err := errors.New("balance index height is set but no block has been indexed yet")
+			logger.Critical().Error(err.Error())
+			return err
+		}
+	} else if b.Head.BkSeq != height+1 {
+		err := errors.New("balance index processing blocks out of order")
+		logger.Critical().Error(err.Error())
+		return err
+	}
+
+	return bi.setHeight(tx, b.Head.BkSeq)
+}
+
+// MaybeBuild rebuilds the index from the unspent pool if it is not at the height of the head block
+func (bi *BalanceIndex) MaybeBuild(tx *dbutil.Tx, headSeq uint64) error {
+	logger.Info("BalanceIndex.MaybeBuild")
+
+	height, ok, err := bi.getHeight(tx)
+	if err != nil {
+		return err
+	}
+
+	if ok && height == headSeq {
+		return nil
+	}
+
+	if height > headSeq {
+		logger.Critical().Warningf("balance index height > headSeq (%d > %d)", height, headSeq)
+	}
+
+	logger.Infof("Rebuilding address_balances (heightExists=%v, height=%d, headSeq=%d)", ok, height, headSeq)
+
+	for _, bkt := range [][]byte{AddressBalancesBkt, RichlistIndexBkt} {
+		if err := dbutil.Reset(tx, bkt); err != nil {
+			return err
+		}
+	}
+	if err := dbutil.Delete(tx, BalanceMetaBkt, supplyKey); err != nil {
+		return err
+	}
+
+	var addrs []cipher.Address
+	add := make(map[cipher.Address]BalanceTotal)
+	if err := dbutil.ForEach(tx, UnspentPoolBkt, func(k, v []byte) error {
+		var ux coin.UxOut
+		if err := decodeUxOutExact(v, &ux); err != nil {
+			return err
+		}
+
+		t, err := newUxBalanceTotal(ux)
+		if err != nil {
+			return err
+		}
+
+		a := ux.Body.Address
+		if _, ok := add[a]; !ok {
+			addrs = append(addrs, a)
+		}
+
+		add[a], err = add[a].Add(t)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if err := bi.adjust(tx, addrs, add, nil); err != nil {
+		return err
+	}
+
+	return bi.setHeight(tx, headSeq)
+}
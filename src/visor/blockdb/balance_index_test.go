@@ -0,0 +1,230 @@
+package blockdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+func TestBalanceTotalCoinHours(t *testing.T) {
+	uxs := []coin.UxOut{
+		{
+			Head: coin.UxHead{Time: 1000},
+			Body: coin.UxBody{Coins: 10e6, Hours: 5},
+		},
+		{
+			Head: coin.UxHead{Time: 4600},
+			Body: coin.UxBody{Coins: 3e6, Hours: 0},
+		},
+	}
+
+	var total BalanceTotal
+	for _, ux := range uxs {
+		b, err := newUxBalanceTotal(ux)
+		require.NoError(t, err)
+		total, err = total.Add(b)
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, BalanceTotal{
+		Coins:    13e6,
+		Hours:    5,
+		CoinTime: 10*1000 + 3*4600,
+	}, total)
+
+	// Whole coins and whole hours match the sum of the outputs' coin hours exactly
+	tm := uint64(1000 + 3600*3)
+	var expect uint64
+	for _, ux := range uxs {
+		h, err := ux.CoinHours(tm)
+		require.NoError(t, err)
+		expect += h
+	}
+
+	hours, err := total.CoinHours(tm)
+	require.NoError(t, err)
+	require.Equal(t, expect, hours)
+
+	// Before the outputs were created, only the initial coin hours are counted
+	hours, err = total.CoinHours(0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), hours)
+
+	b, err := total.Sub(BalanceTotal{Coins: 3e6, CoinTime: 3 * 4600})
+	require.NoError(t, err)
+	require.Equal(t, BalanceTotal{Coins: 10e6, Hours: 5, CoinTime: 10 * 1000}, b)
+
+	_, err = total.Sub(BalanceTotal{Coins: 14e6})
+	require.EqualError(t, err, "BalanceTotal.Sub underflow")
+}
+
+func TestBalanceIndex(t *testing.T) {
+	db, closeDB := prepareDB(t)
+	defer closeDB()
+
+	bc, err := NewBlockchain(db, DefaultWalker)
+	require.NoError(t, err)
+
+	gb := makeGenesisBlock(t)
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return bc.AddBlock(tx, &gb)
+	})
+	require.NoError(t, err)
+
+	pubA, secA := cipher.GenerateKeyPair()
+	addrA := cipher.AddressFromPubKey(pubA)
+	addrB := testutil.MakeAddress()
+
+	genUx := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])[0]
+	b1 := addSpendBlock(t, db, bc, gb, genUx, genSecret, addrA, 400e3)
+
+	uxA := coin.CreateUnspents(b1.Head, b1.Body.Transactions[0])[0]
+	b2 := addSpendBlock(t, db, bc, b1, uxA, secA, addrB, 100e3)
+
+	// The index matches the unspent pool
+	requireBalances := func() {
+		err := db.View("", func(tx *dbutil.Tx) error {
+			uxs, err := bc.UnspentPool().GetAll(tx)
+			require.NoError(t, err)
+
+			expect := make(map[cipher.Address]BalanceTotal)
+			var supply BalanceTotal
+			var hours uint64
+			for _, ux := range uxs {
+				b, err := newUxBalanceTotal(ux)
+				require.NoError(t, err)
+
+				expect[ux.Body.Address], err = expect[ux.Body.Address].Add(b)
+				require.NoError(t, err)
+				supply, err = supply.Add(b)
+				require.NoError(t, err)
+
+				h, err := ux.CoinHours(b2.Head.Time)
+				require.NoError(t, err)
+				hours += h
+			}
+
+			got, err := bc.Supply(tx)
+			require.NoError(t, err)
+			require.Equal(t, supply, got)
+			require.Equal(t, genCoinHours, got.Coins)
+
+			h, err := got.CoinHours(b2.Head.Time)
+			require.NoError(t, err)
+			require.True(t, h >= hours && h <= hours+uint64(len(uxs)), "%d not within %d outputs of %d", h, len(uxs), hours)
+
+			addrs := []cipher.Address{genAddress, addrA, addrB, testutil.MakeAddress()}
+			totals, err := bc.AddressBalanceTotals(tx, addrs)
+			require.NoError(t, err)
+			for i, a := range addrs {
+				require.Equal(t, expect[a], totals[i])
+			}
+
+			var order []cipher.Address
+			var coins []uint64
+			err = bc.ForEachAddressByCoins(tx, func(a cipher.Address, c uint64) (bool, error) {
+				order = append(order, a)
+				coins = append(coins, c)
+				return true, nil
+			})
+			require.NoError(t, err)
+			require.Equal(t, []cipher.Address{genAddress, addrA, addrB}, order)
+			require.Equal(t, []uint64{600e3, 300e3, 100e3}, coins)
+
+			// Iteration stops when f returns false
+			order = nil
+			err = bc.ForEachAddressByCoins(tx, func(a cipher.Address, c uint64) (bool, error) {
+				order = append(order, a)
+				return len(order) < 2, nil
+			})
+			require.NoError(t, err)
+			require.Equal(t, []cipher.Address{genAddress, addrA}, order)
+
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	requireBalances()
+
+	// A db created before the index existed is indexed from the unspent pool
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		for _, bkt := range [][]byte{AddressBalancesBkt, RichlistIndexBkt, BalanceMetaBkt} {
+			if err := dbutil.Reset(tx, bkt); err != nil {
+				return err
+			}
+		}
+		return bc.MaybeBuildBalanceIndex(tx)
+	})
+	require.NoError(t, err)
+	requireBalances()
+
+	// An address whose outputs are all spent is removed from the index
+	uxB := coin.CreateUnspents(b2.Head, b2.Body.Transactions[0])[0]
+	require.Equal(t, addrB, uxB.Body.Address)
+	txn := coin.Transaction{}
+	err = txn.PushInput(uxB.Hash())
+	require.NoError(t, err)
+	err = txn.PushOutput(addrA, uxB.Body.Coins, 0, nil)
+	require.NoError(t, err)
+
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		uxHash, err := bc.UnspentPool().GetUxHash(tx)
+		require.NoError(t, err)
+
+		b, err := coin.NewBlock(b2.Block, b2.Head.Time+100, uxHash, coin.Transactions{txn}, feeCalc)
+		require.NoError(t, err)
+
+		return bc.AddBlock(tx, &coin.SignedBlock{Block: *b})
+	})
+	require.NoError(t, err)
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		totals, err := bc.AddressBalanceTotals(tx, []cipher.Address{addrB})
+		require.NoError(t, err)
+		require.Equal(t, BalanceTotal{}, totals[0])
+
+		var order []cipher.Address
+		err = bc.ForEachAddressByCoins(tx, func(a cipher.Address, c uint64) (bool, error) {
+			order = append(order, a)
+			return true, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []cipher.Address{genAddress, addrA}, order)
+
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestBalanceIndexProcessBlockOutOfOrder(t *testing.T) {
+	db, closeDB := prepareDB(t)
+	defer closeDB()
+
+	bi := NewBalanceIndex()
+	gb := makeGenesisBlock(t)
+
+	err := db.Update("", func(tx *dbutil.Tx) error {
+		require.NoError(t, bi.ProcessBlock(tx, &gb, nil))
+
+		gb.Head.BkSeq = 2
+		err := bi.ProcessBlock(tx, &gb, nil)
+		require.EqualError(t, err, "balance index processing blocks out of order")
+
+		// Spending more than the address holds
+		err = bi.ProcessBlock(tx, &coin.SignedBlock{}, coin.UxArray{
+			{
+				Body: coin.UxBody{Address: genAddress, Coins: 3 * genCoinHours},
+			},
+		})
+		require.EqualError(t, err, "balance index of "+genAddress.String()+": BalanceTotal.Sub underflow")
+
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,148 @@
+package blockdb
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+)
+
+// blockCache is an LRU cache of the decoded blocks of the blocks bucket, keyed by block hash,
+// with an index of the cached block hashes by seq.
+//
+// Blocks are immutable once stored, so a cached block is valid as long as its hash is in the blocks bucket.
+// The block tree checks the bucket before using a cached block, so that blocks added by transactions
+// that were rolled back are never returned. The seq index is invalidated when a block is removed from the tree.
+type blockCache struct {
+	sync.Mutex
+	size   int
+	lru    *list.List
+	blocks map[cipher.SHA256]*list.Element
+	seqs   map[uint64]cipher.SHA256
+}
+
+type blockCacheEntry struct {
+	hash  cipher.SHA256
+	block *coin.Block
+}
+
+func newBlockCache(size int) *blockCache {
+	return &blockCache{
+		size:   size,
+		lru:    list.New(),
+		blocks: make(map[cipher.SHA256]*list.Element, size),
+		seqs:   make(map[uint64]cipher.SHA256, size),
+	}
+}
+
+// get returns a copy of the cached block of a hash
+func (c *blockCache) get(hash cipher.SHA256) (*coin.Block, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	e, ok := c.blocks[hash]
+	if !ok {
+		return nil, false
+	}
+
+	c.lru.MoveToFront(e)
+	return copyBlock(e.Value.(blockCacheEntry).block), true
+}
+
+// getHash returns the hash of the cached block of a seq
+func (c *blockCache) getHash(seq uint64) (cipher.SHA256, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	hash, ok := c.seqs[seq]
+	return hash, ok
+}
+
+// add adds a copy of a block to the cache, evicting the least recently used block if the cache is full.
+// If indexSeq is true, the block is also returned for its seq by getHash.
+func (c *blockCache) add(hash cipher.SHA256, b *coin.Block, indexSeq bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	if indexSeq {
+		c.seqs[b.Seq()] = hash
+	}
+
+	if e, ok := c.blocks[hash]; ok {
+		c.lru.MoveToFront(e)
+		return
+	}
+
+	c.blocks[hash] = c.lru.PushFront(blockCacheEntry{
+		hash:  hash,
+		block: copyBlock(b),
+	})
+
+	for c.lru.Len() > c.size {
+		c.evict(c.lru.Back())
+	}
+}
+
+// evict removes an element of the lru list
+func (c *blockCache) evict(e *list.Element) {
+	entry := c.lru.Remove(e).(blockCacheEntry)
+	delete(c.blocks, entry.hash)
+
+	seq := entry.block.Seq()
+	if hash, ok := c.seqs[seq]; ok && hash == entry.hash {
+		delete(c.seqs, seq)
+	}
+}
+
+// remove removes a block from the cache, and the blocks cached for its seq or a higher seq
+func (c *blockCache) remove(hash cipher.SHA256, b *coin.Block) {
+	c.Lock()
+	defer c.Unlock()
+
+	if e, ok := c.blocks[hash]; ok {
+		c.evict(e)
+	}
+
+	for seq := range c.seqs {
+		if seq >= b.Seq() {
+			delete(c.seqs, seq)
+		}
+	}
+}
+
+// copyBlock deep copies a block, so that the callers can not modify the cached block
+func copyBlock(b *coin.Block) *coin.Block {
+	cb := *b
+	if b.Body.Transactions != nil {
+		cb.Body.Transactions = make(coin.Transactions, len(b.Body.Transactions))
+		for i := range b.Body.Transactions {
+			cb.Body.Transactions[i] = copyTransaction(b.Body.Transactions[i])
+		}
+	}
+	return &cb
+}
+
+// copyTransaction deep copies a transaction
+func copyTransaction(txn coin.Transaction) coin.Transaction {
+	if txn.Sigs != nil {
+		txn.Sigs = append([]cipher.Sig{}, txn.Sigs...)
+	}
+	if txn.In != nil {
+		txn.In = append([]cipher.SHA256{}, txn.In...)
+	}
+	if txn.Out != nil {
+		out := make([]coin.TransactionOutput, len(txn.Out))
+		for i, o := range txn.Out {
+			if o.ProgramState != nil {
+				o.ProgramState = append([]byte{}, o.ProgramState...)
+			}
+			out[i] = o
+		}
+		txn.Out = out
+	}
+	if txn.MainExpressions != nil {
+		txn.MainExpressions = append([]byte{}, txn.MainExpressions...)
+	}
+	return txn
+}
@@ -0,0 +1,194 @@
+package blockdb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+// makeCacheChain returns a chain of n blocks, each with one transaction
+func makeCacheChain(n int) []coin.Block {
+	blocks := make([]coin.Block, n)
+	for i := range blocks {
+		blocks[i] = coin.Block{
+			Head: coin.BlockHeader{
+				BkSeq: uint64(i),
+				Time:  uint64(i),
+			},
+			Body: coin.BlockBody{
+				Transactions: coin.Transactions{
+					{
+						Length:    uint32(i),
+						InnerHash: cipher.SumSHA256([]byte{byte(i)}),
+						Sigs:      []cipher.Sig{{byte(i)}},
+						In:        []cipher.SHA256{{byte(i)}},
+						Out: []coin.TransactionOutput{
+							{
+								Coins:        uint64(i),
+								ProgramState: []byte{byte(i)},
+							},
+						},
+						MainExpressions: []byte{byte(i)},
+					},
+				},
+			},
+		}
+		if i > 0 {
+			blocks[i].Head.PrevHash = blocks[i-1].HashHeader()
+		}
+	}
+	return blocks
+}
+
+func TestBlockCache(t *testing.T) {
+	blocks := makeCacheChain(4)
+	hashes := make([]cipher.SHA256, len(blocks))
+	for i := range blocks {
+		hashes[i] = blocks[i].HashHeader()
+	}
+
+	c := newBlockCache(2)
+
+	c.add(hashes[0], &blocks[0], true)
+	c.add(hashes[1], &blocks[1], true)
+
+	// Reading block 0 makes block 1 the least recently used
+	b, ok := c.get(hashes[0])
+	require.True(t, ok)
+	require.Equal(t, blocks[0], *b)
+
+	c.add(hashes[2], &blocks[2], false)
+
+	_, ok = c.get(hashes[1])
+	require.False(t, ok)
+	_, ok = c.getHash(1)
+	require.False(t, ok)
+
+	hash, ok := c.getHash(0)
+	require.True(t, ok)
+	require.Equal(t, hashes[0], hash)
+
+	// Block 2 was not added for its seq
+	_, ok = c.getHash(2)
+	require.False(t, ok)
+
+	// The returned blocks are copies
+	b.Head.Fee = 10
+	b.Body.Transactions[0].Sigs[0] = cipher.Sig{10}
+	b.Body.Transactions[0].In[0] = cipher.SHA256{10}
+	b.Body.Transactions[0].Out[0].Coins = 10
+	b.Body.Transactions[0].Out[0].ProgramState[0] = 10
+	b.Body.Transactions[0].MainExpressions[0] = 10
+	b.Body.Transactions[0] = coin.Transaction{}
+	b, ok = c.get(hashes[0])
+	require.True(t, ok)
+	require.Equal(t, blocks[0], *b)
+
+	// Removing a block removes the seq index of the block and of the blocks above it
+	c.add(hashes[3], &blocks[3], true)
+	c.add(hashes[3], &blocks[3], true)
+	require.Equal(t, 2, c.lru.Len())
+
+	c.remove(hashes[0], &blocks[0])
+	_, ok = c.get(hashes[0])
+	require.False(t, ok)
+	_, ok = c.getHash(3)
+	require.False(t, ok)
+
+	_, ok = c.get(hashes[3])
+	require.True(t, ok)
+}
+
+func TestBlockTreeCache(t *testing.T) {
+	db, teardown := prepareDB(t)
+	defer teardown()
+
+	bt := &blockTree{
+		cache: newBlockCache(10),
+	}
+	blocks := makeCacheChain(4)
+
+	err := db.Update("", func(tx *dbutil.Tx) error {
+		for i := range blocks[:3] {
+			if err := bt.AddBlock(tx, &blocks[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		for i := range blocks[:3] {
+			b, err := bt.GetBlockInDepth(tx, uint64(i), DefaultWalker)
+			require.NoError(t, err)
+			require.Equal(t, blocks[i], *b)
+
+			// The block is read from the cache
+			_, ok := bt.cache.get(blocks[i].HashHeader())
+			require.True(t, ok)
+
+			b, err = bt.GetBlockInDepth(tx, uint64(i), DefaultWalker)
+			require.NoError(t, err)
+			require.Equal(t, blocks[i], *b)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	// A block cached by a transaction that is rolled back is not returned
+	errRollback := errors.New("rollback")
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		if err := bt.AddBlock(tx, &blocks[3]); err != nil {
+			return err
+		}
+
+		b, err := bt.GetBlockInDepth(tx, 3, DefaultWalker)
+		require.NoError(t, err)
+		require.Equal(t, blocks[3], *b)
+
+		return errRollback
+	})
+	require.Equal(t, errRollback, err)
+
+	_, ok := bt.cache.getHash(3)
+	require.True(t, ok)
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		b, err := bt.GetBlock(tx, blocks[3].HashHeader())
+		require.NoError(t, err)
+		require.Nil(t, b)
+
+		b, err = bt.GetBlockInDepth(tx, 3, DefaultWalker)
+		require.NoError(t, err)
+		require.Nil(t, b)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Removing a block invalidates the cache
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return bt.RemoveBlock(tx, &blocks[2])
+	})
+	require.NoError(t, err)
+
+	_, ok = bt.cache.get(blocks[2].HashHeader())
+	require.False(t, ok)
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		b, err := bt.GetBlockInDepth(tx, 2, DefaultWalker)
+		require.NoError(t, err)
+		require.Nil(t, b)
+
+		b, err = bt.GetBlockInDepth(tx, 1, DefaultWalker)
+		require.NoError(t, err)
+		require.Equal(t, blocks[1], *b)
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -25,7 +25,10 @@ var (
 type Walker func(*dbutil.Tx, []coin.HashPair) (cipher.SHA256, bool)
 
 // blockTree use the blockdb store all blocks and maintains the block tree struct.
-type blockTree struct{}
+type blockTree struct {
+	// cache of decoded blocks, nil if disabled
+	cache *blockCache
+}
 
 // AddBlock adds block with *dbutil.Tx
 func (bt *blockTree) AddBlock(tx *dbutil.Tx, b *coin.Block) error {
@@ -96,6 +99,16 @@ func (bt *blockTree) AddBlock(tx *dbutil.Tx, b *coin.Block) error {
 func (bt *blockTree) RemoveBlock(tx *dbutil.Tx, b *coin.Block) error {
 	// delete block in blocks bucket.
 	hash := b.HashHeader()
+
+	// The cache is also invalidated after the commit, in case a concurrent read of the
+	// previous database state has cached the block again in the meantime
+	if bt.cache != nil {
+		bt.cache.remove(hash, b)
+		tx.OnCommit(func() {
+			bt.cache.remove(hash, b)
+		})
+	}
+
 	if err := dbutil.Delete(tx, BlocksBkt, hash[:]); err != nil {
 		return err
 	}
@@ -138,6 +151,13 @@ func (bt *blockTree) GetBlock(tx *dbutil.Tx, hash cipher.SHA256) (*coin.Block, e
 		return nil, nil
 	}
 
+	// The block is read from the cache only once it is known to be in the bucket
+	if bt.cache != nil {
+		if cb, ok := bt.cache.get(hash); ok {
+			return cb, nil
+		}
+	}
+
 	if err := decodeBlockExact(v, &b); err != nil {
 		return nil, err
 	}
@@ -146,12 +166,29 @@ func (bt *blockTree) GetBlock(tx *dbutil.Tx, hash cipher.SHA256) (*coin.Block, e
 		return nil, fmt.Errorf("DB key %s does not match block hash header %s", hash, b.HashHeader())
 	}
 
+	if bt.cache != nil {
+		bt.cache.add(hash, &b, false)
+	}
+
 	return &b, nil
 }
 
 // GetBlockInDepth get block in depth, return nil on not found,
 // the filter is used to choose the appropriate block.
 func (bt *blockTree) GetBlockInDepth(tx *dbutil.Tx, depth uint64, filter Walker) (*coin.Block, error) {
+	if bt.cache != nil {
+		if hash, ok := bt.cache.getHash(depth); ok {
+			b, err := bt.GetBlock(tx, hash)
+			if err != nil {
+				return nil, err
+			}
+			if b != nil {
+				return b, nil
+			}
+			// The cached block is not in the database seen by this transaction, look up the seq in the tree
+		}
+	}
+
 	hash, ok, err := bt.getHashInDepth(tx, depth, filter)
 	if err != nil {
 		return nil, fmt.Errorf("BlockTree.getHashInDepth failed: %v", err)
@@ -159,7 +196,16 @@ func (bt *blockTree) GetBlockInDepth(tx *dbutil.Tx, depth uint64, filter Walker)
 		return nil, nil
 	}
 
-	return bt.GetBlock(tx, hash)
+	b, err := bt.GetBlock(tx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if b != nil && bt.cache != nil {
+		bt.cache.add(hash, b, true)
+	}
+
+	return b, nil
 }
 
 // ForEachBlock iterates all blocks and calls f on them
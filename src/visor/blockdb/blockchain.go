@@ -25,6 +25,8 @@ var (
 //go:generate skyencoder -unexported -struct hashPairsWrapper
 //go:generate skyencoder -unexported -struct hashesWrapper
 //go:generate skyencoder -unexported -struct sigWrapper
+//go:generate skyencoder -unexported -struct HistoryEntry
+//go:generate skyencoder -unexported -struct BalanceTotal
 
 // hashesWrapper wraps []cipher.SHA256 so it can be used by skyencoder
 type hashesWrapper struct {
@@ -67,6 +69,17 @@ func CreateBuckets(tx *dbutil.Tx) error {
 		UnspentPoolBkt,
 		UnspentPoolAddrIndexBkt,
 		UnspentMetaBkt,
+		AddressHistoryBkt,
+		AddressHistoryMetaBkt,
+		TxnIndexBkt,
+		TxnIndexMetaBkt,
+		UxDeltasBkt,
+		UxDeltasMetaBkt,
+		AddressBalancesBkt,
+		RichlistIndexBkt,
+		BalanceMetaBkt,
+		ProgramStateBkt,
+		ProgramStateMetaBkt,
 	})
 }
 
@@ -100,22 +113,30 @@ type UnspentPooler interface {
 	GetUnspentHashesOfAddrs(*dbutil.Tx, []cipher.Address) (AddressHashes, error)
 	ProcessBlock(*dbutil.Tx, *coin.SignedBlock) error
 	AddressCount(*dbutil.Tx) (uint64, error)
+	Stats(*dbutil.Tx) (UnspentStats, error)
 }
 
 // ChainMeta blockchain metadata
 type ChainMeta interface {
 	GetHeadSeq(*dbutil.Tx) (uint64, bool, error)
 	SetHeadSeq(*dbutil.Tx, uint64) error
+	GetUnspentCommitment(*dbutil.Tx) (*cipher.MuHash, error)
+	SetUnspentCommitment(*dbutil.Tx, *cipher.MuHash) error
 }
 
 // Blockchain maintain the buckets for blockchain
 type Blockchain struct {
-	db      *dbutil.DB
-	meta    ChainMeta
-	unspent UnspentPooler
-	tree    BlockTree
-	sigs    BlockSigs
-	walker  Walker
+	db            *dbutil.DB
+	meta          ChainMeta
+	unspent       UnspentPooler
+	tree          BlockTree
+	sigs          BlockSigs
+	history       *HistoryIndex
+	uxDeltas      *UxDeltaIndex
+	txns          *TxnIndex
+	balances      *BalanceIndex
+	programStates *ProgramStates
+	walker        Walker
 }
 
 // NewBlockchain creates a new blockchain instance
@@ -129,15 +150,33 @@ func NewBlockchain(db *dbutil.DB, walker Walker) (*Blockchain, error) {
 	}
 
 	return &Blockchain{
-		db:      db,
-		unspent: NewUnspentPool(),
-		meta:    &chainMeta{},
-		tree:    &blockTree{},
-		sigs:    &blockSigs{},
-		walker:  walker,
+		db:            db,
+		unspent:       NewUnspentPool(),
+		meta:          &chainMeta{},
+		tree:          &blockTree{},
+		sigs:          &blockSigs{},
+		history:       NewHistoryIndex(),
+		uxDeltas:      NewUxDeltaIndex(),
+		txns:          NewTxnIndex(),
+		balances:      NewBalanceIndex(),
+		programStates: NewProgramStates(),
+		walker:        walker,
 	}, nil
 }
 
+// SetBlockCacheSize sets the number of decoded blocks kept in memory by the block reads.
+// A size of 0 disables the cache. It must be called before the blockchain is used.
+func (bc *Blockchain) SetBlockCacheSize(size int) {
+	var cache *blockCache
+	if size > 0 {
+		cache = newBlockCache(size)
+	}
+
+	bc.tree = &blockTree{
+		cache: cache,
+	}
+}
+
 // UnspentPool returns the unspent pool
 func (bc *Blockchain) UnspentPool() UnspentPooler {
 	return bc.unspent
@@ -163,13 +202,312 @@ func (bc *Blockchain) AddBlock(tx *dbutil.Tx, sb *coin.SignedBlock) error {
 
 // processBlock processes a block and updates the db
 func (bc *Blockchain) processBlock(tx *dbutil.Tx, b *coin.SignedBlock) error {
+	commitment, err := bc.meta.GetUnspentCommitment(tx)
+	if err != nil {
+		return err
+	}
+
+	var inputs []cipher.SHA256
+	for _, txn := range b.Body.Transactions {
+		inputs = append(inputs, txn.In...)
+	}
+
+	spent, err := bc.unspent.GetArray(tx, inputs)
+	if err != nil {
+		return err
+	}
+
 	if err := bc.unspent.ProcessBlock(tx, b); err != nil {
 		return err
 	}
 
+	if err := bc.history.ProcessBlock(tx, b, spent); err != nil {
+		return err
+	}
+
+	if err := bc.uxDeltas.ProcessBlock(tx, b, spent); err != nil {
+		return err
+	}
+
+	if err := bc.txns.ProcessBlock(tx, b); err != nil {
+		return err
+	}
+
+	if err := bc.balances.ProcessBlock(tx, b, spent); err != nil {
+		return err
+	}
+
+	if err := bc.programStates.ProcessBlock(tx, b); err != nil {
+		return err
+	}
+
+	for _, ux := range spent {
+		subUnspentCommitment(commitment, ux)
+	}
+
+	for _, txn := range b.Body.Transactions {
+		for _, ux := range coin.CreateUnspents(b.Head, txn) {
+			addUnspentCommitment(commitment, ux)
+		}
+	}
+
+	if err := bc.meta.SetUnspentCommitment(tx, commitment); err != nil {
+		return err
+	}
+
 	return bc.meta.SetHeadSeq(tx, b.Seq())
 }
 
+// MaybeBuildHistoryIndex builds the address history index if it is behind the head block
+func (bc *Blockchain) MaybeBuildHistoryIndex(tx *dbutil.Tx) error {
+	headSeq, ok, err := bc.HeadSeq(tx)
+	if err != nil || !ok {
+		return err
+	}
+
+	return bc.history.MaybeBuild(tx, headSeq, bc.GetSignedBlockBySeq)
+}
+
+// MaybeBuildUxDeltaIndex builds the ux delta index if it is behind the head block
+func (bc *Blockchain) MaybeBuildUxDeltaIndex(tx *dbutil.Tx) error {
+	headSeq, ok, err := bc.HeadSeq(tx)
+	if err != nil || !ok {
+		return err
+	}
+
+	return bc.uxDeltas.MaybeBuild(tx, headSeq, bc.GetSignedBlockBySeq)
+}
+
+// AddressBalanceTotalsAtSeq returns the BalanceTotal of each address's unspent outputs after the block at seq was executed.
+// The blocks after seq which touched the addresses, found with the address history index,
+// are undone on the current balances of the addresses: the outputs they created are removed and the outputs they spent are added back.
+func (bc *Blockchain) AddressBalanceTotalsAtSeq(tx *dbutil.Tx, addrs []cipher.Address, seq uint64) ([]BalanceTotal, error) {
+	headSeq, ok, err := bc.HeadSeq(tx)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNoHeadBlock
+	}
+	if seq > headSeq {
+		return nil, ErrSeqAfterHead
+	}
+
+	totals, err := bc.balances.Get(tx, addrs)
+	if err != nil {
+		return nil, err
+	}
+
+	seqs, err := bc.history.SeqsAfter(tx, addrs, seq)
+	if err != nil {
+		return nil, err
+	}
+
+	// An address can be requested more than once
+	index := make(map[cipher.Address][]int, len(addrs))
+	for i, addr := range addrs {
+		index[addr] = append(index[addr], i)
+	}
+
+	// Undo the blocks from the newest, so that an output created and spent after seq is added back before it is removed
+	for i := len(seqs) - 1; i >= 0; i-- {
+		d, err := bc.uxDeltas.Get(tx, seqs[i])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, o := range d.Spent {
+			js, ok := index[o.Address]
+			if !ok {
+				continue
+			}
+
+			b, err := o.BalanceTotal()
+			if err != nil {
+				return nil, err
+			}
+
+			for _, j := range js {
+				if totals[j], err = totals[j].Add(b); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		for _, o := range d.Created {
+			js, ok := index[o.Address]
+			if !ok {
+				continue
+			}
+
+			b, err := o.BalanceTotal()
+			if err != nil {
+				return nil, err
+			}
+
+			for _, j := range js {
+				if totals[j], err = totals[j].Sub(b); err != nil {
+					return nil, fmt.Errorf("undo block %d of address %s failed: %v", seqs[i], o.Address, err)
+				}
+			}
+		}
+	}
+
+	return totals, nil
+}
+
+// MaybeBuildTxnIndex builds the transaction index if it is behind the head block
+func (bc *Blockchain) MaybeBuildTxnIndex(tx *dbutil.Tx) error {
+	headSeq, ok, err := bc.HeadSeq(tx)
+	if err != nil || !ok {
+		return err
+	}
+
+	return bc.txns.MaybeBuild(tx, headSeq, bc.GetSignedBlockBySeq)
+}
+
+// TxnProof returns the proof that a confirmed transaction is in its block, or nil if the transaction is not confirmed
+func (bc *Blockchain) TxnProof(tx *dbutil.Tx, txid cipher.SHA256) (*TxnProof, error) {
+	l, ok, err := bc.txns.Get(tx, txid)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	b, err := bc.GetSignedBlockBySeq(tx, l.BkSeq)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil || int(l.Index) >= len(b.Body.Transactions) {
+		return nil, fmt.Errorf("txn index refers to a missing transaction %d of block %d", l.Index, l.BkSeq)
+	}
+
+	p := NewTxnProof(b, int(l.Index))
+	return &p, nil
+}
+
+// AddressHistory returns up to limit confirmed transactions of an address after skipping the first offset,
+// oldest first, and the total number of transactions of the address
+func (bc *Blockchain) AddressHistory(tx *dbutil.Tx, addr cipher.Address, offset, limit uint64) ([]HistoryEntry, uint64, error) {
+	return bc.history.Get(tx, addr, offset, limit)
+}
+
+// MaybeBuildBalanceIndex builds the address balance index if it is behind the head block
+func (bc *Blockchain) MaybeBuildBalanceIndex(tx *dbutil.Tx) error {
+	headSeq, ok, err := bc.HeadSeq(tx)
+	if err != nil || !ok {
+		return err
+	}
+
+	return bc.balances.MaybeBuild(tx, headSeq)
+}
+
+// MaybeBuildProgramStateIndex builds the program state index if it is behind the head block
+func (bc *Blockchain) MaybeBuildProgramStateIndex(tx *dbutil.Tx) error {
+	headSeq, ok, err := bc.HeadSeq(tx)
+	if err != nil || !ok {
+		return err
+	}
+
+	return bc.programStates.MaybeBuild(tx, headSeq, bc.GetSignedBlockBySeq)
+}
+
+// ErrIndexNotBuilt is returned by CheckIndexes if an index is not built up to the head block
+type ErrIndexNotBuilt struct {
+	Index   string
+	Built   bool
+	Height  uint64
+	HeadSeq uint64
+}
+
+func (e ErrIndexNotBuilt) Error() string {
+	if !e.Built {
+		return fmt.Sprintf("The %s is not built, the head block seq is %d", e.Index, e.HeadSeq)
+	}
+	return fmt.Sprintf("The %s height is %d but the head block seq is %d", e.Index, e.Height, e.HeadSeq)
+}
+
+// CheckIndexes returns ErrIndexNotBuilt if an index derived from the blocks is not at the height of the head block.
+// The indexes are brought up to date by the MaybeBuild methods, which can't be used if the db is opened read-only.
+func (bc *Blockchain) CheckIndexes(tx *dbutil.Tx) error {
+	headSeq, ok, err := bc.HeadSeq(tx)
+	if err != nil || !ok {
+		return err
+	}
+
+	um := &unspentMeta{}
+	indexes := []struct {
+		name      string
+		getHeight func(*dbutil.Tx) (uint64, bool, error)
+	}{
+		{"unspent address index", um.getAddrIndexHeight},
+		{"unspent stats", um.getStatsHeight},
+		{"address history index", bc.history.getHeight},
+		{"transaction index", bc.txns.getHeight},
+		{"balance index", bc.balances.getHeight},
+		{"ux delta index", bc.uxDeltas.getHeight},
+		{"program state index", bc.programStates.getHeight},
+	}
+
+	for _, x := range indexes {
+		height, ok, err := x.getHeight(tx)
+		if err != nil {
+			// The bucket of the index does not exist if the db was created by a version without the index
+			if _, isBktErr := err.(dbutil.ErrBucketNotExist); !isBktErr {
+				return err
+			}
+		}
+
+		if !ok || height != headSeq {
+			return ErrIndexNotBuilt{
+				Index:   x.name,
+				Built:   ok,
+				Height:  height,
+				HeadSeq: headSeq,
+			}
+		}
+	}
+
+	return nil
+}
+
+// ProgramState returns the state of a CX program produced by the block at seq
+func (bc *Blockchain) ProgramState(tx *dbutil.Tx, hash cipher.SHA256, seq uint64) ([]byte, bool, error) {
+	return bc.programStates.Get(tx, hash, seq)
+}
+
+// LatestProgramState returns the most recent state of a CX program produced by a block at or before seq
+func (bc *Blockchain) LatestProgramState(tx *dbutil.Tx, hash cipher.SHA256, seq uint64) (ProgramStateEntry, bool, error) {
+	return bc.programStates.Latest(tx, hash, seq)
+}
+
+// Supply returns the BalanceTotal of all unspent outputs
+func (bc *Blockchain) Supply(tx *dbutil.Tx) (BalanceTotal, error) {
+	return bc.balances.Supply(tx)
+}
+
+// AddressBalanceTotals returns the BalanceTotal of each address's unspent outputs
+func (bc *Blockchain) AddressBalanceTotals(tx *dbutil.Tx, addrs []cipher.Address) ([]BalanceTotal, error) {
+	return bc.balances.Get(tx, addrs)
+}
+
+// ForEachAddressByCoins calls f for each address with coins, from the most coins to the least,
+// until f returns false
+func (bc *Blockchain) ForEachAddressByCoins(tx *dbutil.Tx, f func(cipher.Address, uint64) (bool, error)) error {
+	return bc.balances.ForEachByCoins(tx, f)
+}
+
+// UnspentCommitment returns the commitment hash of the unspent pool.
+// It is the digest of the MuHash of the SnapshotHash of every unspent output,
+// so it can be compared against other nodes or recomputed from a snapshot of the unspent pool.
+func (bc *Blockchain) UnspentCommitment(tx *dbutil.Tx) (cipher.SHA256, error) {
+	c, err := bc.meta.GetUnspentCommitment(tx)
+	if err != nil {
+		return cipher.SHA256{}, err
+	}
+
+	return c.Digest(), nil
+}
+
 // Head returns head block, returns error if no head block exists
 func (bc *Blockchain) Head(tx *dbutil.Tx) (*coin.SignedBlock, error) {
 	seq, ok, err := bc.HeadSeq(tx)
@@ -267,9 +267,21 @@ func (fup *fakeUnspentPool) AddressCount(tx *dbutil.Tx) (uint64, error) {
 	return uint64(len(addrs)), nil
 }
 
+func (fup *fakeUnspentPool) Stats(tx *dbutil.Tx) (UnspentStats, error) {
+	var s UnspentStats
+	for _, out := range fup.outs {
+		if err := s.add(out); err != nil {
+			return UnspentStats{}, err
+		}
+	}
+
+	return s, nil
+}
+
 type fakeChainMeta struct {
-	headSeq   uint64
-	didSetSeq bool
+	headSeq           uint64
+	didSetSeq         bool
+	unspentCommitment *cipher.MuHash
 }
 
 func newFakeChainMeta() *fakeChainMeta {
@@ -290,12 +302,25 @@ func (fcm *fakeChainMeta) SetHeadSeq(tx *dbutil.Tx, seq uint64) error {
 	return nil
 }
 
+func (fcm *fakeChainMeta) GetUnspentCommitment(tx *dbutil.Tx) (*cipher.MuHash, error) {
+	if fcm.unspentCommitment == nil {
+		return cipher.NewMuHash(), nil
+	}
+
+	return fcm.unspentCommitment, nil
+}
+
+func (fcm *fakeChainMeta) SetUnspentCommitment(tx *dbutil.Tx, c *cipher.MuHash) error {
+	fcm.unspentCommitment = c
+	return nil
+}
+
 func DefaultWalker(tx *dbutil.Tx, hps []coin.HashPair) (cipher.SHA256, bool) {
 	return hps[0].Hash, true
 }
 
 func makeGenesisBlock(t *testing.T) coin.SignedBlock {
-	gb, err := coin.NewGenesisBlock(genAddress, genCoinHours, genTime)
+	gb, err := coin.NewGenesisBlock(genAddress, genCoinHours, genTime, nil)
 	require.NoError(t, err)
 
 	sig := cipher.MustSignHash(gb.HashHeader(), genSecret)
@@ -387,12 +412,15 @@ func TestBlockchainAddBlock(t *testing.T) {
 			tc.fakeStorage.unspent.saveFailed = tc.failedSaves.unspent
 
 			bc := &Blockchain{
-				db:      db,
-				unspent: tc.fakeStorage.unspent,
-				meta:    tc.fakeStorage.chainMeta,
-				tree:    tc.fakeStorage.tree,
-				sigs:    tc.fakeStorage.sigs,
-				walker:  DefaultWalker,
+				db:       db,
+				unspent:  tc.fakeStorage.unspent,
+				meta:     tc.fakeStorage.chainMeta,
+				tree:     tc.fakeStorage.tree,
+				sigs:     tc.fakeStorage.sigs,
+				history:  NewHistoryIndex(),
+				uxDeltas: NewUxDeltaIndex(),
+				balances: NewBalanceIndex(),
+				walker:   DefaultWalker,
 			}
 
 			gb := makeGenesisBlock(t)
@@ -512,6 +540,60 @@ func TestBlockchainLen(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestBlockchainCheckIndexes(t *testing.T) {
+	db, closeDB := prepareDB(t)
+	defer closeDB()
+
+	bc, err := NewBlockchain(db, DefaultWalker)
+	require.NoError(t, err)
+
+	// An empty chain has no indexes to check
+	err = db.View("", func(tx *dbutil.Tx) error {
+		return bc.CheckIndexes(tx)
+	})
+	require.NoError(t, err)
+
+	gb := makeGenesisBlock(t)
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return bc.AddBlock(tx, &gb)
+	})
+	require.NoError(t, err)
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		return bc.CheckIndexes(tx)
+	})
+	require.NoError(t, err)
+
+	// An index behind the head block is reported
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return dbutil.Delete(tx, TxnIndexMetaBkt, txnIndexHeightKey)
+	})
+	require.NoError(t, err)
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		return bc.CheckIndexes(tx)
+	})
+	require.Equal(t, ErrIndexNotBuilt{
+		Index: "transaction index",
+	}, err)
+
+	// An index missing from a db created by an older version is reported
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		if err := bc.txns.setHeight(tx, 0); err != nil {
+			return err
+		}
+		return tx.DeleteBucket(BalanceMetaBkt)
+	})
+	require.NoError(t, err)
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		return bc.CheckIndexes(tx)
+	})
+	require.Equal(t, ErrIndexNotBuilt{
+		Index: "balance index",
+	}, err)
+}
+
 func TestBlockchainGetBlockByHash(t *testing.T) {
 	gb := makeGenesisBlock(t)
 
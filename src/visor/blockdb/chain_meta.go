@@ -1,6 +1,7 @@
 package blockdb
 
 import (
+	"github.com/SkycoinProject/cx-chains/src/cipher"
 	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
 )
 
@@ -9,6 +10,10 @@ var (
 	BlockchainMetaBkt = []byte("blockchain_meta")
 	// blockchain head sequence number
 	headSeqKey = []byte("head_seq")
+	// blockdb schema version
+	schemaVersionKey = []byte("schema_version")
+	// commitment hash of the unspent pool
+	unspentCommitmentKey = []byte("unspent_commitment")
 )
 
 type chainMeta struct{}
@@ -27,3 +32,34 @@ func (m chainMeta) GetHeadSeq(tx *dbutil.Tx) (uint64, bool, error) {
 
 	return dbutil.Btoi(v), true, nil
 }
+
+func (m chainMeta) SetSchemaVersion(tx *dbutil.Tx, version uint64) error {
+	return dbutil.PutBucketValue(tx, BlockchainMetaBkt, schemaVersionKey, dbutil.Itob(version))
+}
+
+func (m chainMeta) GetSchemaVersion(tx *dbutil.Tx) (uint64, bool, error) {
+	v, err := dbutil.GetBucketValue(tx, BlockchainMetaBkt, schemaVersionKey)
+	if err != nil {
+		return 0, false, err
+	} else if v == nil {
+		return 0, false, nil
+	}
+
+	return dbutil.Btoi(v), true, nil
+}
+
+func (m chainMeta) SetUnspentCommitment(tx *dbutil.Tx, c *cipher.MuHash) error {
+	return dbutil.PutBucketValue(tx, BlockchainMetaBkt, unspentCommitmentKey, c.Serialize())
+}
+
+// GetUnspentCommitment returns the unspent pool commitment, the commitment of the empty pool if none is stored
+func (m chainMeta) GetUnspentCommitment(tx *dbutil.Tx) (*cipher.MuHash, error) {
+	v, err := dbutil.GetBucketValue(tx, BlockchainMetaBkt, unspentCommitmentKey)
+	if err != nil {
+		return nil, err
+	} else if v == nil {
+		return cipher.NewMuHash(), nil
+	}
+
+	return cipher.MuHashFromBytes(v)
+}
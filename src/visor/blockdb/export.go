@@ -0,0 +1,85 @@
+package blockdb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+// ErrExportStopped is returned when a block export is interrupted
+var ErrExportStopped = errors.New("block export stopped")
+
+// ExportBlocks calls f with the signed blocks of the main chain from startSeq to endSeq inclusive, in order.
+// endSeq is lowered to the head block seq. The blocks are read in a single read transaction,
+// one block at a time, so f sees a consistent chain and the blocks do not need to fit in memory.
+// Returns the number of blocks passed to f.
+func ExportBlocks(db *dbutil.DB, walker Walker, startSeq, endSeq uint64, quit <-chan struct{}, f func(*coin.SignedBlock) error) (uint64, error) {
+	if startSeq > endSeq {
+		return 0, fmt.Errorf("start seq %d is above the end seq %d", startSeq, endSeq)
+	}
+
+	if quit == nil {
+		quit = make(chan struct{})
+	}
+
+	var n uint64
+	if err := db.View("ExportBlocks", func(tx *dbutil.Tx) error {
+		headSeq, ok, err := chainMeta{}.GetHeadSeq(tx)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("the blockchain is empty")
+		}
+
+		if startSeq > headSeq {
+			return fmt.Errorf("start seq %d is above the head seq %d", startSeq, headSeq)
+		}
+		if endSeq > headSeq {
+			endSeq = headSeq
+		}
+
+		bt := &blockTree{}
+		sigs := &blockSigs{}
+
+		for seq := startSeq; seq <= endSeq; seq++ {
+			select {
+			case <-quit:
+				return ErrExportStopped
+			default:
+			}
+
+			b, err := bt.GetBlockInDepth(tx, seq, walker)
+			if err != nil {
+				return err
+			}
+			if b == nil {
+				return fmt.Errorf("block seq %d is missing", seq)
+			}
+
+			sig, ok, err := sigs.Get(tx, b.HashHeader())
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return NewErrMissingSignature(b)
+			}
+
+			if err := f(&coin.SignedBlock{
+				Block: *b,
+				Sig:   sig,
+			}); err != nil {
+				return err
+			}
+			n++
+		}
+
+		return nil
+	}); err != nil {
+		return n, err
+	}
+
+	return n, nil
+}
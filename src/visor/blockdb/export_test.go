@@ -0,0 +1,110 @@
+package blockdb
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+func TestExportBlocks(t *testing.T) {
+	db, teardown := prepareDB(t)
+	defer teardown()
+
+	_, err := ExportBlocks(db, DefaultWalker, 0, 0, nil, func(*coin.SignedBlock) error {
+		return nil
+	})
+	require.EqualError(t, err, "the blockchain is empty")
+
+	blocks := makeReplayChain(t, db)
+
+	tt := []struct {
+		name     string
+		startSeq uint64
+		endSeq   uint64
+		blocks   []coin.SignedBlock
+		err      string
+	}{
+		{
+			name:     "all blocks",
+			startSeq: 0,
+			endSeq:   math.MaxUint64,
+			blocks:   blocks,
+		},
+		{
+			name:     "range",
+			startSeq: 1,
+			endSeq:   1,
+			blocks:   blocks[1:2],
+		},
+		{
+			name:     "end above the head",
+			startSeq: 2,
+			endSeq:   10,
+			blocks:   blocks[2:],
+		},
+		{
+			name:     "start above the head",
+			startSeq: 3,
+			endSeq:   10,
+			err:      "start seq 3 is above the head seq 2",
+		},
+		{
+			name:     "start above the end",
+			startSeq: 2,
+			endSeq:   1,
+			err:      "start seq 2 is above the end seq 1",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var exported []coin.SignedBlock
+			n, err := ExportBlocks(db, DefaultWalker, tc.startSeq, tc.endSeq, nil, func(b *coin.SignedBlock) error {
+				exported = append(exported, *b)
+				return nil
+			})
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, uint64(len(tc.blocks)), n)
+			require.Equal(t, tc.blocks, exported)
+		})
+	}
+
+	// An error returned by f stops the export
+	n, err := ExportBlocks(db, DefaultWalker, 0, math.MaxUint64, nil, func(b *coin.SignedBlock) error {
+		if b.Seq() == 1 {
+			return errors.New("write failed")
+		}
+		return nil
+	})
+	require.EqualError(t, err, "write failed")
+	require.Equal(t, uint64(1), n)
+
+	quit := make(chan struct{})
+	close(quit)
+	_, err = ExportBlocks(db, DefaultWalker, 0, math.MaxUint64, quit, func(*coin.SignedBlock) error {
+		return nil
+	})
+	require.Equal(t, ErrExportStopped, err)
+
+	// A missing signature is an error
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		h := blocks[1].HashHeader()
+		return dbutil.Delete(tx, BlockSigsBkt, h[:])
+	})
+	require.NoError(t, err)
+
+	_, err = ExportBlocks(db, DefaultWalker, 0, math.MaxUint64, nil, func(*coin.SignedBlock) error {
+		return nil
+	})
+	require.Equal(t, NewErrMissingSignature(&blocks[1].Block), err)
+}
@@ -0,0 +1,299 @@
+package blockdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+var (
+	historyIndexHeightKey = []byte("history_index_height")
+
+	// AddressHistoryBkt maps addresses to the confirmed transactions that touched them.
+	// Keys are the address, block seq and transaction index, so an address's entries sort in blockchain order
+	AddressHistoryBkt = []byte("address_history")
+	// AddressHistoryMetaBkt holds address history index metadata
+	AddressHistoryMetaBkt = []byte("address_history_meta")
+)
+
+// HistoryDirection is the direction coins moved relative to an address in a transaction
+type HistoryDirection uint8
+
+const (
+	// HistoryIncoming is a transaction that sent coins to the address, or did not change its coins
+	HistoryIncoming HistoryDirection = iota
+	// HistoryOutgoing is a transaction that sent coins from the address
+	HistoryOutgoing
+)
+
+// String returns the name of the direction
+func (d HistoryDirection) String() string {
+	switch d {
+	case HistoryIncoming:
+		return "incoming"
+	case HistoryOutgoing:
+		return "outgoing"
+	default:
+		return "unknown"
+	}
+}
+
+// HistoryEntry is a confirmed transaction that spent an output owned by an address, or created one
+type HistoryEntry struct {
+	BkSeq     uint64
+	Txid      cipher.SHA256
+	Direction HistoryDirection
+	// Coins is the net change of the address's coins, in droplets
+	Coins uint64
+}
+
+// HistoryIndex maps addresses to the confirmed transactions that touched them, in blockchain order
+type HistoryIndex struct{}
+
+// NewHistoryIndex creates a HistoryIndex
+func NewHistoryIndex() *HistoryIndex {
+	return &HistoryIndex{}
+}
+
+func historyKey(addr cipher.Address, seq uint64, txnIndex int) []byte {
+	k := make([]byte, 0, 20+1+4+8+4)
+	k = append(k, addr.Bytes()...)
+	k = append(k, dbutil.Itob(seq)...)
+
+	var i [4]byte
+	binary.BigEndian.PutUint32(i[:], uint32(txnIndex))
+	return append(k, i[:]...)
+}
+
+func (h *HistoryIndex) getHeight(tx *dbutil.Tx) (uint64, bool, error) {
+	v, err := dbutil.GetBucketValue(tx, AddressHistoryMetaBkt, historyIndexHeightKey)
+	if err != nil {
+		return 0, false, err
+	} else if v == nil {
+		return 0, false, nil
+	}
+
+	return dbutil.Btoi(v), true, nil
+}
+
+func (h *HistoryIndex) setHeight(tx *dbutil.Tx, height uint64) error {
+	return dbutil.PutBucketValue(tx, AddressHistoryMetaBkt, historyIndexHeightKey, dbutil.Itob(height))
+}
+
+// ProcessBlock adds the transactions of a block to the index.
+// spent are the outputs spent by the block, in the order of the transactions' inputs.
+func (h *HistoryIndex) ProcessBlock(tx *dbutil.Tx, b *coin.SignedBlock, spent coin.UxArray) error {
+	if err := h.addBlock(tx, b, spent); err != nil {
+		return err
+	}
+
+	// Check that the index height is incremental
+	height, ok, err := h.getHeight(tx)
+	if err != nil {
+		return err
+	}
+
+	if b.Head.BkSeq == 0 {
+		if ok {
+			err := errors.New("history index height is set but no block has been indexed yet")
+			logger.Critical().Error(err.Error())
+			return err
+		}
+	} else if b.Head.BkSeq != height+1 {
+		err := errors.New("history index processing blocks out of order")
+		logger.Critical().Error(err.Error())
+		return err
+	}
+
+	return h.setHeight(tx, b.Head.BkSeq)
+}
+
+func (h *HistoryIndex) addBlock(tx *dbutil.Tx, b *coin.SignedBlock, spent coin.UxArray) error {
+	type flow struct {
+		in  uint64
+		out uint64
+	}
+
+	for i, txn := range b.Body.Transactions {
+		if len(spent) < len(txn.In) {
+			return errors.New("history index is missing spent outputs of the block")
+		}
+
+		var addrs []cipher.Address
+		flows := make(map[cipher.Address]*flow)
+		get := func(addr cipher.Address) *flow {
+			f, ok := flows[addr]
+			if !ok {
+				f = &flow{}
+				flows[addr] = f
+				addrs = append(addrs, addr)
+			}
+			return f
+		}
+
+		for _, ux := range spent[:len(txn.In)] {
+			get(ux.Body.Address).in += ux.Body.Coins
+		}
+		spent = spent[len(txn.In):]
+
+		for _, o := range txn.Out {
+			get(o.Address).out += o.Coins
+		}
+
+		txid := txn.Hash()
+		for _, addr := range addrs {
+			f := flows[addr]
+
+			e := HistoryEntry{
+				BkSeq:     b.Head.BkSeq,
+				Txid:      txid,
+				Direction: HistoryIncoming,
+				Coins:     f.out - f.in,
+			}
+			if f.in > f.out {
+				e.Direction = HistoryOutgoing
+				e.Coins = f.in - f.out
+			}
+
+			buf, err := encodeHistoryEntry(&e)
+			if err != nil {
+				return err
+			}
+
+			if err := dbutil.PutBucketValue(tx, AddressHistoryBkt, historyKey(addr, b.Head.BkSeq, i), buf); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(spent) != 0 {
+		return errors.New("history index was given more spent outputs than the block's inputs")
+	}
+
+	return nil
+}
+
+// Get returns up to limit entries of an address's history after skipping the first offset entries,
+// and the total number of entries of the address
+func (h *HistoryIndex) Get(tx *dbutil.Tx, addr cipher.Address, offset, limit uint64) ([]HistoryEntry, uint64, error) {
+	var entries []HistoryEntry
+	var n uint64
+	if err := dbutil.ForEachPrefix(tx, AddressHistoryBkt, addr.Bytes(), func(k, v []byte) error {
+		defer func() {
+			n++
+		}()
+
+		if n < offset || n-offset >= limit {
+			return nil
+		}
+
+		var e HistoryEntry
+		if err := decodeHistoryEntryExact(v, &e); err != nil {
+			return err
+		}
+
+		entries = append(entries, e)
+		return nil
+	}); err != nil {
+		return nil, 0, err
+	}
+
+	return entries, n, nil
+}
+
+// SeqsAfter returns the seqs of the blocks after seq with transactions that touched any of the addresses,
+// in ascending order
+func (h *HistoryIndex) SeqsAfter(tx *dbutil.Tx, addrs []cipher.Address, seq uint64) ([]uint64, error) {
+	seqs := make(map[uint64]struct{})
+	for _, addr := range addrs {
+		prefix := addr.Bytes()
+		if err := dbutil.ForEachPrefix(tx, AddressHistoryBkt, prefix, func(k, v []byte) error {
+			if len(k) != len(prefix)+8+4 {
+				return errors.New("invalid address history key length")
+			}
+
+			if s := dbutil.Btoi(k[len(prefix) : len(prefix)+8]); s > seq {
+				seqs[s] = struct{}{}
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	sorted := make([]uint64, 0, len(seqs))
+	for s := range seqs {
+		sorted = append(sorted, s)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+
+	return sorted, nil
+}
+
+// MaybeBuild rebuilds the index if it is not at the height of the head block.
+// getBlock returns the block with a given seq from the blockchain.
+func (h *HistoryIndex) MaybeBuild(tx *dbutil.Tx, headSeq uint64, getBlock func(*dbutil.Tx, uint64) (*coin.SignedBlock, error)) error {
+	logger.Info("HistoryIndex.MaybeBuild")
+
+	height, ok, err := h.getHeight(tx)
+	if err != nil {
+		return err
+	}
+
+	if ok && height == headSeq {
+		return nil
+	}
+
+	if height > headSeq {
+		logger.Critical().Warningf("history index height > headSeq (%d > %d)", height, headSeq)
+	}
+
+	logger.Infof("Rebuilding address_history (heightExists=%v, height=%d, headSeq=%d)", ok, height, headSeq)
+
+	if err := dbutil.Reset(tx, AddressHistoryBkt); err != nil {
+		return err
+	}
+
+	// Replay the blockchain, tracking the outputs which have not been spent yet
+	unspent := make(map[cipher.SHA256]coin.UxOut)
+	for seq := uint64(0); seq <= headSeq; seq++ {
+		b, err := getBlock(tx, seq)
+		if err != nil {
+			return err
+		}
+		if b == nil {
+			return fmt.Errorf("history index rebuild failed: block %d not found", seq)
+		}
+
+		var spent coin.UxArray
+		for _, txn := range b.Body.Transactions {
+			for _, in := range txn.In {
+				ux, ok := unspent[in]
+				if !ok {
+					return NewErrUnspentNotExist(in.Hex())
+				}
+				spent = append(spent, ux)
+				delete(unspent, in)
+			}
+		}
+
+		if err := h.addBlock(tx, b, spent); err != nil {
+			return err
+		}
+
+		for _, txn := range b.Body.Transactions {
+			for _, ux := range coin.CreateUnspents(b.Head, txn) {
+				unspent[ux.Hash()] = ux
+			}
+		}
+	}
+
+	return h.setHeight(tx, headSeq)
+}
@@ -0,0 +1,198 @@
+package blockdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+func addSpendBlock(t *testing.T, db *dbutil.DB, bc *Blockchain, prev coin.SignedBlock, ux coin.UxOut, key cipher.SecKey, to cipher.Address, coins uint64) coin.SignedBlock {
+	txn := coin.Transaction{}
+	err := txn.PushInput(ux.Hash())
+	require.NoError(t, err)
+	err = txn.PushOutput(to, coins, ux.Body.Hours/4, nil)
+	require.NoError(t, err)
+	err = txn.PushOutput(ux.Body.Address, ux.Body.Coins-coins, ux.Body.Hours/4, nil)
+	require.NoError(t, err)
+	txn.SignInputs([]cipher.SecKey{key})
+	err = txn.UpdateHeader()
+	require.NoError(t, err)
+
+	var sb coin.SignedBlock
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		uxHash, err := bc.UnspentPool().GetUxHash(tx)
+		require.NoError(t, err)
+
+		b, err := coin.NewBlock(prev.Block, prev.Head.Time+100, uxHash, coin.Transactions{txn}, feeCalc)
+		require.NoError(t, err)
+
+		sb = coin.SignedBlock{
+			Block: *b,
+			Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+		}
+
+		return bc.AddBlock(tx, &sb)
+	})
+	require.NoError(t, err)
+
+	return sb
+}
+
+func TestHistoryIndex(t *testing.T) {
+	db, closeDB := prepareDB(t)
+	defer closeDB()
+
+	bc, err := NewBlockchain(db, DefaultWalker)
+	require.NoError(t, err)
+
+	// An empty blockchain has nothing to index
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return bc.MaybeBuildHistoryIndex(tx)
+	})
+	require.NoError(t, err)
+
+	gb := makeGenesisBlock(t)
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return bc.AddBlock(tx, &gb)
+	})
+	require.NoError(t, err)
+
+	pubA, secA := cipher.GenerateKeyPair()
+	addrA := cipher.AddressFromPubKey(pubA)
+	addrB := testutil.MakeAddress()
+
+	genUx := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])[0]
+	b1 := addSpendBlock(t, db, bc, gb, genUx, genSecret, addrA, 400e3)
+
+	uxA := coin.CreateUnspents(b1.Head, b1.Body.Transactions[0])[0]
+	b2 := addSpendBlock(t, db, bc, b1, uxA, secA, addrB, 100e3)
+
+	gbTxid := gb.Body.Transactions[0].Hash()
+	b1Txid := b1.Body.Transactions[0].Hash()
+	b2Txid := b2.Body.Transactions[0].Hash()
+
+	expect := map[cipher.Address][]HistoryEntry{
+		genAddress: {
+			{BkSeq: 0, Txid: gbTxid, Direction: HistoryIncoming, Coins: genCoinHours},
+			{BkSeq: 1, Txid: b1Txid, Direction: HistoryOutgoing, Coins: 400e3},
+		},
+		addrA: {
+			{BkSeq: 1, Txid: b1Txid, Direction: HistoryIncoming, Coins: 400e3},
+			{BkSeq: 2, Txid: b2Txid, Direction: HistoryOutgoing, Coins: 100e3},
+		},
+		addrB: {
+			{BkSeq: 2, Txid: b2Txid, Direction: HistoryIncoming, Coins: 100e3},
+		},
+	}
+
+	requireHistory := func() {
+		err := db.View("", func(tx *dbutil.Tx) error {
+			for addr, entries := range expect {
+				got, total, err := bc.AddressHistory(tx, addr, 0, 10)
+				require.NoError(t, err)
+				require.Equal(t, uint64(len(entries)), total)
+				require.Equal(t, entries, got)
+			}
+
+			// Pagination
+			got, total, err := bc.AddressHistory(tx, addrA, 1, 10)
+			require.NoError(t, err)
+			require.Equal(t, uint64(2), total)
+			require.Equal(t, expect[addrA][1:], got)
+
+			got, total, err = bc.AddressHistory(tx, addrA, 0, 1)
+			require.NoError(t, err)
+			require.Equal(t, uint64(2), total)
+			require.Equal(t, expect[addrA][:1], got)
+
+			got, total, err = bc.AddressHistory(tx, addrA, 2, 10)
+			require.NoError(t, err)
+			require.Equal(t, uint64(2), total)
+			require.Empty(t, got)
+
+			// Unknown address
+			got, total, err = bc.AddressHistory(tx, testutil.MakeAddress(), 0, 10)
+			require.NoError(t, err)
+			require.Equal(t, uint64(0), total)
+			require.Empty(t, got)
+
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	requireHistory()
+
+	// The index is not rebuilt when it is up to date
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return bc.MaybeBuildHistoryIndex(tx)
+	})
+	require.NoError(t, err)
+	requireHistory()
+
+	// A db created before the index existed is indexed from the blockchain
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		if err := dbutil.Reset(tx, AddressHistoryBkt); err != nil {
+			return err
+		}
+		if err := dbutil.Reset(tx, AddressHistoryMetaBkt); err != nil {
+			return err
+		}
+		return bc.MaybeBuildHistoryIndex(tx)
+	})
+	require.NoError(t, err)
+	requireHistory()
+
+	// Blocks added after the rebuild continue the index
+	// Sending coins to yourself is an incoming transaction that does not change the address's coins
+	uxA = coin.CreateUnspents(b2.Head, b2.Body.Transactions[0])[1]
+	b3 := addSpendBlock(t, db, bc, b2, uxA, secA, addrA, 50e3)
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		got, total, err := bc.AddressHistory(tx, addrA, 2, 10)
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), total)
+		require.Equal(t, []HistoryEntry{
+			{BkSeq: 3, Txid: b3.Body.Transactions[0].Hash(), Direction: HistoryIncoming, Coins: 0},
+		}, got)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestHistoryIndexProcessBlockOutOfOrder(t *testing.T) {
+	db, closeDB := prepareDB(t)
+	defer closeDB()
+
+	h := NewHistoryIndex()
+	gb := makeGenesisBlock(t)
+
+	err := db.Update("", func(tx *dbutil.Tx) error {
+		require.NoError(t, h.ProcessBlock(tx, &gb, nil))
+
+		err := h.ProcessBlock(tx, &gb, nil)
+		require.EqualError(t, err, "history index height is set but no block has been indexed yet")
+
+		gb.Head.BkSeq = 2
+		err = h.ProcessBlock(tx, &gb, nil)
+		require.EqualError(t, err, "history index processing blocks out of order")
+
+		err = h.ProcessBlock(tx, &coin.SignedBlock{
+			Block: coin.Block{
+				Head: coin.BlockHeader{BkSeq: 1},
+				Body: coin.BlockBody{
+					Transactions: coin.Transactions{{In: []cipher.SHA256{{}}}},
+				},
+			},
+		}, nil)
+		require.EqualError(t, err, "history index is missing spent outputs of the block")
+
+		return nil
+	})
+	require.NoError(t, err)
+}
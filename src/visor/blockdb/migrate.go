@@ -0,0 +1,121 @@
+package blockdb
+
+import (
+	"fmt"
+
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+// Migration upgrades the blockdb schema from the previous version to Version
+type Migration struct {
+	Version uint64
+	Name    string
+	Migrate func(*dbutil.Tx) error
+}
+
+// Migrations are the blockdb schema migrations, ordered by version.
+// A database without a schema version is at version 0.
+// Append new migrations to the end of this list when changing the on-disk layout.
+// Rebuildable indexes such as the unspent pool address index are handled by
+// Unspents.MaybeBuildIndexes and do not need a migration.
+var Migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "compute unspent pool commitment",
+		Migrate: func(tx *dbutil.Tx) error {
+			uxa, err := NewUnspentPool().GetAll(tx)
+			if err != nil {
+				return err
+			}
+
+			return chainMeta{}.SetUnspentCommitment(tx, computeUnspentCommitment(uxa))
+		},
+	},
+}
+
+// ErrSchemaVersionUnsupported is returned by Migrate if the database schema is newer than the migrations know about
+type ErrSchemaVersionUnsupported struct {
+	Version       uint64
+	LatestVersion uint64
+}
+
+func (e ErrSchemaVersionUnsupported) Error() string {
+	return fmt.Sprintf("blockdb schema version %d is newer than the latest supported version %d", e.Version, e.LatestVersion)
+}
+
+// LatestSchemaVersion returns the schema version reached after applying all of migrations
+func LatestSchemaVersion(migrations []Migration) uint64 {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// SchemaVersion returns the schema version stored in the database, 0 if none is stored
+func SchemaVersion(tx *dbutil.Tx) (uint64, error) {
+	version, _, err := chainMeta{}.GetSchemaVersion(tx)
+	return version, err
+}
+
+// Migrate runs the migrations newer than the database's schema version, in order,
+// and records the new schema version after each one.
+// A database with no blocks has nothing to migrate and is set to the latest version directly.
+func Migrate(tx *dbutil.Tx, migrations []Migration) error {
+	var prev uint64
+	for _, m := range migrations {
+		if m.Version <= prev {
+			return fmt.Errorf("blockdb migration %q version %d is not greater than the previous version %d", m.Name, m.Version, prev)
+		}
+		if m.Migrate == nil {
+			return fmt.Errorf("blockdb migration %q has no Migrate function", m.Name)
+		}
+		prev = m.Version
+	}
+
+	latest := LatestSchemaVersion(migrations)
+
+	meta := chainMeta{}
+	version, _, err := meta.GetSchemaVersion(tx)
+	if err != nil {
+		return err
+	}
+
+	if version > latest {
+		return ErrSchemaVersionUnsupported{
+			Version:       version,
+			LatestVersion: latest,
+		}
+	}
+
+	if version == latest {
+		return nil
+	}
+
+	_, hasHead, err := meta.GetHeadSeq(tx)
+	if err != nil {
+		return err
+	}
+
+	if !hasHead {
+		logger.Infof("Setting blockdb schema version to %d", latest)
+		return meta.SetSchemaVersion(tx, latest)
+	}
+
+	for _, m := range migrations {
+		if m.Version <= version {
+			continue
+		}
+
+		logger.Infof("Migrating blockdb schema to version %d: %s", m.Version, m.Name)
+
+		if err := m.Migrate(tx); err != nil {
+			return fmt.Errorf("blockdb migration %q failed: %v", m.Name, err)
+		}
+
+		if err := meta.SetSchemaVersion(tx, m.Version); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package blockdb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+func TestMigrate(t *testing.T) {
+	var ran []uint64
+	makeMigrations := func(failVersion uint64) []Migration {
+		var ms []Migration
+		for _, v := range []uint64{1, 2, 3} {
+			v := v
+			ms = append(ms, Migration{
+				Version: v,
+				Name:    "test",
+				Migrate: func(*dbutil.Tx) error {
+					if v == failVersion {
+						return errors.New("failed")
+					}
+					ran = append(ran, v)
+					return nil
+				},
+			})
+		}
+		return ms
+	}
+
+	tt := []struct {
+		name            string
+		hasHead         bool
+		storedVersion   *uint64
+		migrations      []Migration
+		err             error
+		ran             []uint64
+		expectedVersion uint64
+	}{
+		{
+			name:            "empty db is set to latest version",
+			migrations:      makeMigrations(0),
+			expectedVersion: 3,
+		},
+		{
+			name:            "unversioned db runs all migrations",
+			hasHead:         true,
+			migrations:      makeMigrations(0),
+			ran:             []uint64{1, 2, 3},
+			expectedVersion: 3,
+		},
+		{
+			name:            "runs newer migrations only",
+			hasHead:         true,
+			storedVersion:   func() *uint64 { v := uint64(1); return &v }(),
+			migrations:      makeMigrations(0),
+			ran:             []uint64{2, 3},
+			expectedVersion: 3,
+		},
+		{
+			name:            "up to date",
+			hasHead:         true,
+			storedVersion:   func() *uint64 { v := uint64(3); return &v }(),
+			migrations:      makeMigrations(0),
+			expectedVersion: 3,
+		},
+		{
+			name:          "newer than latest",
+			hasHead:       true,
+			storedVersion: func() *uint64 { v := uint64(4); return &v }(),
+			migrations:    makeMigrations(0),
+			err: ErrSchemaVersionUnsupported{
+				Version:       4,
+				LatestVersion: 3,
+			},
+		},
+		{
+			name:       "migration fails",
+			hasHead:    true,
+			migrations: makeMigrations(2),
+			err:        errors.New(`blockdb migration "test" failed: failed`),
+			ran:        []uint64{1},
+		},
+		{
+			name:    "migrations out of order",
+			hasHead: true,
+			migrations: []Migration{
+				{Version: 2, Name: "b", Migrate: func(*dbutil.Tx) error { return nil }},
+				{Version: 1, Name: "a", Migrate: func(*dbutil.Tx) error { return nil }},
+			},
+			err: errors.New(`blockdb migration "a" version 1 is not greater than the previous version 2`),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			db, teardown := prepareDB(t)
+			defer teardown()
+
+			ran = nil
+
+			err := db.Update("", func(tx *dbutil.Tx) error {
+				if tc.hasHead {
+					if err := (chainMeta{}).SetHeadSeq(tx, 0); err != nil {
+						return err
+					}
+				}
+				if tc.storedVersion != nil {
+					if err := (chainMeta{}).SetSchemaVersion(tx, *tc.storedVersion); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			require.NoError(t, err)
+
+			err = db.Update("", func(tx *dbutil.Tx) error {
+				return Migrate(tx, tc.migrations)
+			})
+			require.Equal(t, tc.err, err)
+			require.Equal(t, tc.ran, ran)
+
+			if err != nil {
+				return
+			}
+
+			err = db.View("", func(tx *dbutil.Tx) error {
+				version, err := SchemaVersion(tx)
+				require.NoError(t, err)
+				require.Equal(t, tc.expectedVersion, version)
+				return nil
+			})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestMigrationsOrdered(t *testing.T) {
+	var prev uint64
+	for _, m := range Migrations {
+		require.True(t, m.Version > prev)
+		require.NotNil(t, m.Migrate)
+		prev = m.Version
+	}
+}
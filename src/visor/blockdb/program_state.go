@@ -0,0 +1,207 @@
+package blockdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+var (
+	programStateHeightKey = []byte("program_state_height")
+
+	// ProgramStateBkt maps program hash and block seq to the serialized CX program state produced by that block
+	ProgramStateBkt = []byte("program_state")
+	// ProgramStateMetaBkt holds program state index metadata
+	ProgramStateMetaBkt = []byte("program_state_meta")
+)
+
+// ProgramHash returns the hash identifying the CX program whose state is carried by outputs to addr
+func ProgramHash(addr cipher.Address) cipher.SHA256 {
+	return cipher.SumSHA256(addr.Bytes())
+}
+
+// ProgramStateEntry is a CX program state and the seq of the block that produced it
+type ProgramStateEntry struct {
+	BkSeq uint64
+	State []byte
+}
+
+func programStateKey(hash cipher.SHA256, seq uint64) []byte {
+	k := make([]byte, 0, len(hash)+8)
+	k = append(k, hash[:]...)
+	return append(k, dbutil.Itob(seq)...)
+}
+
+// ProgramStates stores the serialized CX program states of the confirmed transaction outputs,
+// keyed by program hash and block seq
+type ProgramStates struct{}
+
+// NewProgramStates creates a ProgramStates
+func NewProgramStates() *ProgramStates {
+	return &ProgramStates{}
+}
+
+func (x *ProgramStates) getHeight(tx *dbutil.Tx) (uint64, bool, error) {
+	v, err := dbutil.GetBucketValue(tx, ProgramStateMetaBkt, programStateHeightKey)
+	if err != nil {
+		return 0, false, err
+	} else if v == nil {
+		return 0, false, nil
+	}
+
+	return dbutil.Btoi(v), true, nil
+}
+
+func (x *ProgramStates) setHeight(tx *dbutil.Tx, height uint64) error {
+	return dbutil.PutBucketValue(tx, ProgramStateMetaBkt, programStateHeightKey, dbutil.Itob(height))
+}
+
+// Put stores the state of a program at a block seq
+func (x *ProgramStates) Put(tx *dbutil.Tx, hash cipher.SHA256, seq uint64, state []byte) error {
+	return dbutil.PutBucketValue(tx, ProgramStateBkt, programStateKey(hash, seq), state)
+}
+
+// Get returns the state of a program produced by the block at seq
+func (x *ProgramStates) Get(tx *dbutil.Tx, hash cipher.SHA256, seq uint64) ([]byte, bool, error) {
+	v, err := dbutil.GetBucketValue(tx, ProgramStateBkt, programStateKey(hash, seq))
+	if err != nil {
+		return nil, false, err
+	} else if v == nil {
+		return nil, false, nil
+	}
+
+	return v, true, nil
+}
+
+// Latest returns the most recent state of a program produced by a block at or before seq
+func (x *ProgramStates) Latest(tx *dbutil.Tx, hash cipher.SHA256, seq uint64) (ProgramStateEntry, bool, error) {
+	bkt := tx.Bucket(ProgramStateBkt)
+	if bkt == nil {
+		return ProgramStateEntry{}, false, dbutil.NewErrBucketNotExist(ProgramStateBkt)
+	}
+
+	c := bkt.Cursor()
+
+	// Seek to the first key at or after hash||seq, stepping back if it is not an exact match
+	key := programStateKey(hash, seq)
+	k, v := c.Seek(key)
+	if k == nil {
+		k, v = c.Last()
+	} else if !bytes.Equal(k, key) {
+		k, v = c.Prev()
+	}
+
+	if k == nil || !bytes.HasPrefix(k, hash[:]) {
+		return ProgramStateEntry{}, false, nil
+	}
+
+	if len(k) != len(hash)+8 {
+		return ProgramStateEntry{}, false, errors.New("invalid program state key length")
+	}
+
+	// Bytes returned from boltdb are not valid outside of the transaction
+	state := make([]byte, len(v))
+	copy(state, v)
+
+	return ProgramStateEntry{
+		BkSeq: dbutil.Btoi(k[len(hash):]),
+		State: state,
+	}, true, nil
+}
+
+// ProcessBlock stores the program states of the outputs created by a block
+func (x *ProgramStates) ProcessBlock(tx *dbutil.Tx, b *coin.SignedBlock) error {
+	if err := x.addBlock(tx, b); err != nil {
+		return err
+	}
+
+	// Check that the index height is incremental
+	height, ok, err := x.getHeight(tx)
+	if err != nil {
+		return err
+	}
+
+	if b.Head.BkSeq == 0 {
+		if ok {
+			err := errors.New("program state index height is set but no block has been indexed yet")
+			logger.Critical().Error(err.Error())
+			return err
+		}
+	} else if b.Head.BkSeq != height+1 {
+		err := errors.New("program state index processing blocks out of order")
+		logger.Critical().Error(err.Error())
+		return err
+	}
+
+	return x.setHeight(tx, b.Head.BkSeq)
+}
+
+func (x *ProgramStates) addBlock(tx *dbutil.Tx, b *coin.SignedBlock) error {
+	for hash, state := range blockProgramStates(&b.Block) {
+		if err := x.Put(tx, hash, b.Head.BkSeq, state); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// blockProgramStates returns the program states produced by a block, by program hash.
+// If a block has several outputs with state for the same program, the last one is kept.
+func blockProgramStates(b *coin.Block) map[cipher.SHA256][]byte {
+	states := make(map[cipher.SHA256][]byte)
+	for _, txn := range b.Body.Transactions {
+		for _, o := range txn.Out {
+			if len(o.ProgramState) != 0 {
+				states[ProgramHash(o.Address)] = o.ProgramState
+			}
+		}
+	}
+
+	return states
+}
+
+// MaybeBuild rebuilds the index if it is not at the height of the head block.
+// getBlock returns the block with a given seq from the blockchain.
+func (x *ProgramStates) MaybeBuild(tx *dbutil.Tx, headSeq uint64, getBlock func(*dbutil.Tx, uint64) (*coin.SignedBlock, error)) error {
+	logger.Info("ProgramStates.MaybeBuild")
+
+	height, ok, err := x.getHeight(tx)
+	if err != nil {
+		return err
+	}
+
+	if ok && height == headSeq {
+		return nil
+	}
+
+	if height > headSeq {
+		logger.Critical().Warningf("program state index height > headSeq (%d > %d)", height, headSeq)
+	}
+
+	logger.Infof("Rebuilding program_state (heightExists=%v, height=%d, headSeq=%d)", ok, height, headSeq)
+
+	if err := dbutil.Reset(tx, ProgramStateBkt); err != nil {
+		return err
+	}
+
+	for seq := uint64(0); seq <= headSeq; seq++ {
+		b, err := getBlock(tx, seq)
+		if err != nil {
+			return err
+		}
+		if b == nil {
+			return fmt.Errorf("program state index rebuild failed: block %d not found", seq)
+		}
+
+		if err := x.addBlock(tx, b); err != nil {
+			return err
+		}
+	}
+
+	return x.setHeight(tx, headSeq)
+}
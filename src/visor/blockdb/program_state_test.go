@@ -0,0 +1,128 @@
+package blockdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+// addProgramStateBlock adds a block spending ux back to its owner with program state
+func addProgramStateBlock(t *testing.T, db *dbutil.DB, bc *Blockchain, prev coin.SignedBlock, ux coin.UxOut, key cipher.SecKey, state []byte) coin.SignedBlock {
+	txn := coin.Transaction{}
+	err := txn.PushInput(ux.Hash())
+	require.NoError(t, err)
+	err = txn.PushOutput(ux.Body.Address, ux.Body.Coins, ux.Body.Hours/4, state)
+	require.NoError(t, err)
+	txn.SignInputs([]cipher.SecKey{key})
+	err = txn.UpdateHeader()
+	require.NoError(t, err)
+
+	var sb coin.SignedBlock
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		uxHash, err := bc.UnspentPool().GetUxHash(tx)
+		require.NoError(t, err)
+
+		b, err := coin.NewBlock(prev.Block, prev.Head.Time+100, uxHash, coin.Transactions{txn}, feeCalc)
+		require.NoError(t, err)
+
+		sb = coin.SignedBlock{
+			Block: *b,
+			Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+		}
+
+		return bc.AddBlock(tx, &sb)
+	})
+	require.NoError(t, err)
+
+	return sb
+}
+
+func TestProgramStates(t *testing.T) {
+	db, closeDB := prepareDB(t)
+	defer closeDB()
+
+	bc, err := NewBlockchain(db, DefaultWalker)
+	require.NoError(t, err)
+
+	// An empty blockchain has nothing to index
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return bc.MaybeBuildProgramStateIndex(tx)
+	})
+	require.NoError(t, err)
+
+	gb := makeGenesisBlock(t)
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return bc.AddBlock(tx, &gb)
+	})
+	require.NoError(t, err)
+
+	hash := ProgramHash(genAddress)
+
+	genUx := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])[0]
+	b1 := addProgramStateBlock(t, db, bc, gb, genUx, genSecret, []byte("state1"))
+
+	// A block without program state does not change the program state
+	ux1 := coin.CreateUnspents(b1.Head, b1.Body.Transactions[0])[0]
+	b2 := addSpendBlock(t, db, bc, b1, ux1, genSecret, testutil.MakeAddress(), 100e3)
+
+	ux2 := coin.CreateUnspents(b2.Head, b2.Body.Transactions[0])[1]
+	addProgramStateBlock(t, db, bc, b2, ux2, genSecret, []byte("state3"))
+
+	requireStates := func() {
+		err := db.View("", func(tx *dbutil.Tx) error {
+			state, ok, err := bc.ProgramState(tx, hash, 1)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, []byte("state1"), state)
+
+			_, ok, err = bc.ProgramState(tx, hash, 2)
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			for _, tc := range []struct {
+				seq   uint64
+				entry ProgramStateEntry
+				ok    bool
+			}{
+				{seq: 0},
+				{seq: 1, entry: ProgramStateEntry{BkSeq: 1, State: []byte("state1")}, ok: true},
+				{seq: 2, entry: ProgramStateEntry{BkSeq: 1, State: []byte("state1")}, ok: true},
+				{seq: 3, entry: ProgramStateEntry{BkSeq: 3, State: []byte("state3")}, ok: true},
+				{seq: ^uint64(0), entry: ProgramStateEntry{BkSeq: 3, State: []byte("state3")}, ok: true},
+			} {
+				entry, ok, err := bc.LatestProgramState(tx, hash, tc.seq)
+				require.NoError(t, err)
+				require.Equal(t, tc.ok, ok, "seq=%d", tc.seq)
+				require.Equal(t, tc.entry, entry, "seq=%d", tc.seq)
+			}
+
+			// Unknown program
+			_, ok, err = bc.LatestProgramState(tx, testutil.RandSHA256(t), 3)
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	requireStates()
+
+	// A db created before the index existed is indexed from the blockchain
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		if err := dbutil.Reset(tx, ProgramStateBkt); err != nil {
+			return err
+		}
+		if err := dbutil.Reset(tx, ProgramStateMetaBkt); err != nil {
+			return err
+		}
+		return bc.MaybeBuildProgramStateIndex(tx)
+	})
+	require.NoError(t, err)
+	requireStates()
+}
@@ -0,0 +1,308 @@
+package blockdb
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+// ReplayDivergence is the first difference found between the replayed chain and the stored state
+type ReplayDivergence struct {
+	Seq    uint64
+	Reason string
+}
+
+func (d ReplayDivergence) String() string {
+	return fmt.Sprintf("block seq %d: %s", d.Seq, d.Reason)
+}
+
+// ReplayResult is the report produced by Replay
+type ReplayResult struct {
+	// StartSeq is the sequence of the first block compared to the stored state
+	StartSeq uint64
+	// HeadSeq is the head block sequence recorded in the blockchain metadata
+	HeadSeq uint64
+	// HasHead is false if no head block sequence is recorded
+	HasHead bool
+	// Blocks is the number of blocks replayed and compared, from StartSeq
+	Blocks uint64
+	// ProgramStates is the number of program states compared
+	ProgramStates uint64
+	// Unspents is the number of unspent outputs recomputed from the main chain
+	Unspents uint64
+	// Divergence is the first divergence found, or nil
+	Divergence *ReplayDivergence
+}
+
+// OK returns true if no divergence was found
+func (r ReplayResult) OK() bool {
+	return r.Divergence == nil
+}
+
+func (r *ReplayResult) divergef(seq uint64, format string, args ...interface{}) {
+	r.Divergence = &ReplayDivergence{
+		Seq:    seq,
+		Reason: fmt.Sprintf(format, args...),
+	}
+}
+
+// Replay re-executes the main chain from the genesis block, recomputing the unspent outputs and
+// the CX program states. Blocks from startSeq to the head block are verified and compared to the
+// stored state as they are applied; the blocks below startSeq are only applied.
+// Replay stops at the first divergence, which is recorded in the returned ReplayResult;
+// an error is only returned if the database could not be read or the replay was interrupted.
+func Replay(db *dbutil.DB, walker Walker, startSeq uint64, quit <-chan struct{}) (*ReplayResult, error) {
+	var res *ReplayResult
+	if err := db.View("Replay", func(tx *dbutil.Tx) error {
+		var err error
+		res, err = replay(tx, walker, startSeq, quit)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func replay(tx *dbutil.Tx, walker Walker, startSeq uint64, quit <-chan struct{}) (*ReplayResult, error) {
+	if quit == nil {
+		quit = make(chan struct{})
+	}
+
+	res := &ReplayResult{
+		StartSeq: startSeq,
+	}
+
+	headSeq, hasHead, err := chainMeta{}.GetHeadSeq(tx)
+	if err != nil {
+		return nil, err
+	}
+	res.HeadSeq = headSeq
+	res.HasHead = hasHead
+
+	if !hasHead {
+		return res, nil
+	}
+
+	if startSeq > headSeq {
+		return nil, fmt.Errorf("start seq %d is above the head seq %d", startSeq, headSeq)
+	}
+
+	// The program states are compared up to the height of the program state index
+	programStates := NewProgramStates()
+	programStateHeight, programStatesIndexed, err := programStates.getHeight(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	storedStates := make(map[uint64]int)
+	if programStatesIndexed {
+		if err := dbutil.ForEach(tx, ProgramStateBkt, func(k, _ []byte) error {
+			if len(k) != len(cipher.SHA256{})+8 {
+				return fmt.Errorf("invalid program state key length %d", len(k))
+			}
+			storedStates[dbutil.Btoi(k[len(k)-8:])]++
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	bt := &blockTree{}
+	sigs := &blockSigs{}
+	unspents := make(map[cipher.SHA256]coin.UxOut)
+	var xorHash cipher.SHA256
+	var prevHash cipher.SHA256
+
+	for seq := uint64(0); seq <= headSeq; seq++ {
+		select {
+		case <-quit:
+			return nil, ErrVerifyStopped
+		default:
+		}
+
+		b, err := bt.GetBlockInDepth(tx, seq, walker)
+		if err != nil {
+			return nil, err
+		}
+		if b == nil {
+			res.divergef(seq, "block is missing")
+			return res, nil
+		}
+
+		compare := seq >= startSeq
+
+		if compare {
+			hash := b.HashHeader()
+
+			if b.Seq() != seq {
+				res.divergef(seq, "block %s has seq %d", hash.Hex(), b.Seq())
+				return res, nil
+			}
+
+			if seq > 0 && b.Head.PrevHash != prevHash {
+				res.divergef(seq, "prev hash %s does not match the hash %s of the previous block", b.Head.PrevHash.Hex(), prevHash.Hex())
+				return res, nil
+			}
+
+			if b.Head.BodyHash != b.Body.Hash() {
+				res.divergef(seq, "body hash %s does not match the recomputed body hash %s", b.Head.BodyHash.Hex(), b.Body.Hash().Hex())
+				return res, nil
+			}
+
+			if _, ok, err := sigs.Get(tx, hash); err != nil {
+				return nil, err
+			} else if !ok {
+				res.divergef(seq, "block has no signature")
+				return res, nil
+			}
+
+			if b.Head.UxHash != xorHash {
+				res.divergef(seq, "uxhash %s does not match the recomputed uxhash %s", b.Head.UxHash.Hex(), xorHash.Hex())
+				return res, nil
+			}
+		}
+
+		for _, txn := range b.Body.Transactions {
+			uxIn := make(coin.UxArray, 0, len(txn.In))
+			for _, in := range txn.In {
+				ux, ok := unspents[in]
+				if !ok {
+					res.divergef(seq, "transaction %s spends unknown output %s", txn.Hash().Hex(), in.Hex())
+					return res, nil
+				}
+
+				uxIn = append(uxIn, ux)
+			}
+
+			uxOut := coin.CreateUnspents(b.Head, txn)
+
+			// The genesis transaction creates the coins and is not verified
+			if compare && seq > 0 {
+				if err := replayTransaction(b.Head.Time, txn, uxIn, uxOut); err != nil {
+					res.divergef(seq, "transaction %s is invalid: %v", txn.Hash().Hex(), err)
+					return res, nil
+				}
+			}
+
+			for _, ux := range uxIn {
+				xorHash = xorHash.Xor(ux.SnapshotHash())
+				delete(unspents, ux.Hash())
+			}
+
+			for _, ux := range uxOut {
+				h := ux.Hash()
+				if _, ok := unspents[h]; ok {
+					res.divergef(seq, "transaction %s creates duplicate output %s", txn.Hash().Hex(), h.Hex())
+					return res, nil
+				}
+
+				xorHash = xorHash.Xor(ux.SnapshotHash())
+				unspents[h] = ux
+			}
+		}
+
+		if compare && programStatesIndexed && seq <= programStateHeight {
+			states := blockProgramStates(b)
+
+			if n := storedStates[seq]; n != len(states) {
+				res.divergef(seq, "%d program states are stored but the replay produced %d", n, len(states))
+				return res, nil
+			}
+
+			for hash, state := range states {
+				stored, ok, err := programStates.Get(tx, hash, seq)
+				if err != nil {
+					return nil, err
+				}
+
+				if !ok {
+					res.divergef(seq, "program %s state is not stored", hash.Hex())
+					return res, nil
+				} else if !bytes.Equal(stored, state) {
+					res.divergef(seq, "program %s state does not match the replayed state", hash.Hex())
+					return res, nil
+				}
+
+				res.ProgramStates++
+			}
+		}
+
+		prevHash = b.HashHeader()
+		if compare {
+			res.Blocks++
+		}
+	}
+
+	res.Unspents = uint64(len(unspents))
+
+	// Compare the recomputed unspent outputs to the unspent pool at the head block
+	var stored uint64
+	if err := dbutil.ForEach(tx, UnspentPoolBkt, func(k, v []byte) error {
+		if res.Divergence != nil {
+			return nil
+		}
+
+		h, err := cipher.SHA256FromBytes(k)
+		if err != nil {
+			return err
+		}
+
+		var ux coin.UxOut
+		if err := decodeUxOutExact(v, &ux); err != nil {
+			return err
+		}
+
+		expected, ok := unspents[h]
+		switch {
+		case !ok:
+			res.divergef(headSeq, "unspent pool has unexpected output %s", h.Hex())
+		case expected.SnapshotHash() != ux.SnapshotHash():
+			res.divergef(headSeq, "unspent pool output %s does not match the replayed output", h.Hex())
+		}
+
+		stored++
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if res.Divergence != nil {
+		return res, nil
+	}
+
+	if stored != res.Unspents {
+		res.divergef(headSeq, "unspent pool has %d outputs but the replay produced %d", stored, res.Unspents)
+		return res, nil
+	}
+
+	storedXorHash, err := unspentMeta{}.getXorHash(tx)
+	if err != nil {
+		return nil, err
+	}
+	if storedXorHash != xorHash {
+		res.divergef(headSeq, "unspent pool uxhash %s does not match the replayed uxhash %s", storedXorHash.Hex(), xorHash.Hex())
+	}
+
+	return res, nil
+}
+
+// replayTransaction verifies a confirmed transaction against the outputs it spends and creates
+func replayTransaction(headTime uint64, txn coin.Transaction, uxIn, uxOut coin.UxArray) error {
+	if err := txn.Verify(); err != nil {
+		return err
+	}
+
+	if err := txn.VerifyInputSignatures(uxIn); err != nil {
+		return err
+	}
+
+	if err := coin.VerifyTransactionCoinsSpending(uxIn, uxOut); err != nil {
+		return err
+	}
+
+	return coin.VerifyTransactionHoursSpending(headTime, uxIn, uxOut)
+}
@@ -0,0 +1,185 @@
+package blockdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+// makeReplayChain writes a genesis block, a block with program state and a spend block to db
+func makeReplayChain(t *testing.T, db *dbutil.DB) []coin.SignedBlock {
+	bc, err := NewBlockchain(db, DefaultWalker)
+	require.NoError(t, err)
+
+	gb := makeGenesisBlock(t)
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return bc.AddBlock(tx, &gb)
+	})
+	require.NoError(t, err)
+
+	genUx := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])[0]
+	b1 := addProgramStateBlock(t, db, bc, gb, genUx, genSecret, []byte("state1"))
+
+	ux1 := coin.CreateUnspents(b1.Head, b1.Body.Transactions[0])[0]
+	b2 := addSpendBlock(t, db, bc, b1, ux1, genSecret, testutil.MakeAddress(), 100e3)
+
+	return []coin.SignedBlock{gb, b1, b2}
+}
+
+func TestReplay(t *testing.T) {
+	tt := []struct {
+		name          string
+		startSeq      uint64
+		corrupt       func(*testing.T, *dbutil.Tx, []coin.SignedBlock)
+		divergence    *ReplayDivergence
+		blocks        uint64
+		programStates uint64
+	}{
+		{
+			name:          "consistent",
+			corrupt:       func(*testing.T, *dbutil.Tx, []coin.SignedBlock) {},
+			blocks:        3,
+			programStates: 1,
+		},
+		{
+			name:     "from seq",
+			startSeq: 2,
+			corrupt:  func(*testing.T, *dbutil.Tx, []coin.SignedBlock) {},
+			blocks:   1,
+		},
+		{
+			name: "program state mismatch",
+			corrupt: func(t *testing.T, tx *dbutil.Tx, _ []coin.SignedBlock) {
+				err := NewProgramStates().Put(tx, ProgramHash(genAddress), 1, []byte("state2"))
+				require.NoError(t, err)
+			},
+			divergence: &ReplayDivergence{
+				Seq:    1,
+				Reason: "program " + ProgramHash(genAddress).Hex() + " state does not match the replayed state",
+			},
+			blocks: 1,
+		},
+		{
+			name:     "program state mismatch below start seq",
+			startSeq: 2,
+			corrupt: func(t *testing.T, tx *dbutil.Tx, _ []coin.SignedBlock) {
+				err := NewProgramStates().Put(tx, ProgramHash(genAddress), 1, []byte("state2"))
+				require.NoError(t, err)
+			},
+			blocks: 1,
+		},
+		{
+			name: "unexpected program state",
+			corrupt: func(t *testing.T, tx *dbutil.Tx, _ []coin.SignedBlock) {
+				err := NewProgramStates().Put(tx, ProgramHash(genAddress), 2, []byte("state2"))
+				require.NoError(t, err)
+			},
+			divergence: &ReplayDivergence{
+				Seq:    2,
+				Reason: "1 program states are stored but the replay produced 0",
+			},
+			blocks:        2,
+			programStates: 1,
+		},
+		{
+			name: "missing unspent",
+			corrupt: func(t *testing.T, tx *dbutil.Tx, blocks []coin.SignedBlock) {
+				ux := coin.CreateUnspents(blocks[2].Head, blocks[2].Body.Transactions[0])[0]
+				h := ux.Hash()
+				err := dbutil.Delete(tx, UnspentPoolBkt, h[:])
+				require.NoError(t, err)
+			},
+			divergence: &ReplayDivergence{
+				Seq:    2,
+				Reason: "unspent pool has 1 outputs but the replay produced 2",
+			},
+			blocks:        3,
+			programStates: 1,
+		},
+		{
+			name: "missing signature",
+			corrupt: func(t *testing.T, tx *dbutil.Tx, blocks []coin.SignedBlock) {
+				h := blocks[1].HashHeader()
+				err := dbutil.Delete(tx, BlockSigsBkt, h[:])
+				require.NoError(t, err)
+			},
+			divergence: &ReplayDivergence{
+				Seq:    1,
+				Reason: "block has no signature",
+			},
+			blocks: 1,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			db, teardown := prepareDB(t)
+			defer teardown()
+
+			blocks := makeReplayChain(t, db)
+
+			err := db.Update("", func(tx *dbutil.Tx) error {
+				tc.corrupt(t, tx, blocks)
+				return nil
+			})
+			require.NoError(t, err)
+
+			res, err := Replay(db, DefaultWalker, tc.startSeq, nil)
+			require.NoError(t, err)
+			require.Equal(t, tc.divergence, res.Divergence)
+			require.Equal(t, tc.divergence == nil, res.OK())
+			require.True(t, res.HasHead)
+			require.Equal(t, uint64(2), res.HeadSeq)
+			require.Equal(t, tc.startSeq, res.StartSeq)
+			require.Equal(t, tc.blocks, res.Blocks)
+			require.Equal(t, tc.programStates, res.ProgramStates)
+		})
+	}
+}
+
+func TestReplayInvalidTransaction(t *testing.T) {
+	db, teardown := prepareDB(t)
+	defer teardown()
+
+	bc, err := NewBlockchain(db, DefaultWalker)
+	require.NoError(t, err)
+
+	gb := makeGenesisBlock(t)
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return bc.AddBlock(tx, &gb)
+	})
+	require.NoError(t, err)
+
+	// blockdb does not verify transactions; spend the genesis output with the wrong key
+	_, otherSecret := cipher.GenerateKeyPair()
+	genUx := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])[0]
+	b1 := addSpendBlock(t, db, bc, gb, genUx, otherSecret, testutil.MakeAddress(), 100e3)
+
+	res, err := Replay(db, DefaultWalker, 0, nil)
+	require.NoError(t, err)
+	require.False(t, res.OK())
+	require.Equal(t, uint64(1), res.Divergence.Seq)
+	require.Contains(t, res.Divergence.Reason, "transaction "+b1.Body.Transactions[0].Hash().Hex()+" is invalid")
+	require.Equal(t, uint64(1), res.Blocks)
+}
+
+func TestReplayEmpty(t *testing.T) {
+	db, teardown := prepareDB(t)
+	defer teardown()
+
+	res, err := Replay(db, DefaultWalker, 0, nil)
+	require.NoError(t, err)
+	require.True(t, res.OK())
+	require.False(t, res.HasHead)
+	require.Equal(t, uint64(0), res.Blocks)
+
+	makeReplayChain(t, db)
+
+	_, err = Replay(db, DefaultWalker, 3, nil)
+	require.EqualError(t, err, "start seq 3 is above the head seq 2")
+}
@@ -0,0 +1,211 @@
+package blockdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+var (
+	txnIndexHeightKey = []byte("txn_index_height")
+
+	// TxnIndexBkt maps confirmed transaction hashes to the block seq and the index of the transaction in the block
+	TxnIndexBkt = []byte("txn_index")
+	// TxnIndexMetaBkt holds transaction index metadata
+	TxnIndexMetaBkt = []byte("txn_index_meta")
+)
+
+// TxnLocation is the position of a confirmed transaction in the blockchain
+type TxnLocation struct {
+	BkSeq uint64
+	Index uint32
+}
+
+func encodeTxnLocation(l TxnLocation) []byte {
+	v := make([]byte, 0, 8+4)
+	v = append(v, dbutil.Itob(l.BkSeq)...)
+
+	var i [4]byte
+	binary.BigEndian.PutUint32(i[:], l.Index)
+	return append(v, i[:]...)
+}
+
+func decodeTxnLocation(v []byte) (TxnLocation, error) {
+	if len(v) != 8+4 {
+		return TxnLocation{}, errors.New("invalid transaction index value length")
+	}
+
+	return TxnLocation{
+		BkSeq: dbutil.Btoi(v[:8]),
+		Index: binary.BigEndian.Uint32(v[8:]),
+	}, nil
+}
+
+// TxnIndex maps confirmed transaction hashes to their position in the blockchain
+type TxnIndex struct{}
+
+// NewTxnIndex creates a TxnIndex
+func NewTxnIndex() *TxnIndex {
+	return &TxnIndex{}
+}
+
+func (x *TxnIndex) getHeight(tx *dbutil.Tx) (uint64, bool, error) {
+	v, err := dbutil.GetBucketValue(tx, TxnIndexMetaBkt, txnIndexHeightKey)
+	if err != nil {
+		return 0, false, err
+	} else if v == nil {
+		return 0, false, nil
+	}
+
+	return dbutil.Btoi(v), true, nil
+}
+
+func (x *TxnIndex) setHeight(tx *dbutil.Tx, height uint64) error {
+	return dbutil.PutBucketValue(tx, TxnIndexMetaBkt, txnIndexHeightKey, dbutil.Itob(height))
+}
+
+// ProcessBlock adds the transactions of a block to the index
+func (x *TxnIndex) ProcessBlock(tx *dbutil.Tx, b *coin.SignedBlock) error {
+	if err := x.addBlock(tx, b); err != nil {
+		return err
+	}
+
+	// Check that the index height is incremental
+	height, ok, err := x.getHeight(tx)
+	if err != nil {
+		return err
+	}
+
+	if b.Head.BkSeq == 0 {
+		if ok {
+			err := errors.New("txn index height is set but no block has been indexed yet")
+			logger.Critical().Error(err.Error())
+			return err
+		}
+	} else if b.Head.BkSeq != height+1 {
+		err := errors.New("txn index processing blocks out of order")
+		logger.Critical().Error(err.Error())
+		return err
+	}
+
+	return x.setHeight(tx, b.Head.BkSeq)
+}
+
+func (x *TxnIndex) addBlock(tx *dbutil.Tx, b *coin.SignedBlock) error {
+	for i, txn := range b.Body.Transactions {
+		txid := txn.Hash()
+		v := encodeTxnLocation(TxnLocation{
+			BkSeq: b.Head.BkSeq,
+			Index: uint32(i),
+		})
+
+		if err := dbutil.PutBucketValue(tx, TxnIndexBkt, txid[:], v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get returns the position of a confirmed transaction
+func (x *TxnIndex) Get(tx *dbutil.Tx, txid cipher.SHA256) (TxnLocation, bool, error) {
+	v, err := dbutil.GetBucketValue(tx, TxnIndexBkt, txid[:])
+	if err != nil {
+		return TxnLocation{}, false, err
+	} else if v == nil {
+		return TxnLocation{}, false, nil
+	}
+
+	l, err := decodeTxnLocation(v)
+	if err != nil {
+		return TxnLocation{}, false, err
+	}
+
+	return l, true, nil
+}
+
+// MaybeBuild rebuilds the index if it is not at the height of the head block.
+// getBlock returns the block with a given seq from the blockchain.
+func (x *TxnIndex) MaybeBuild(tx *dbutil.Tx, headSeq uint64, getBlock func(*dbutil.Tx, uint64) (*coin.SignedBlock, error)) error {
+	logger.Info("TxnIndex.MaybeBuild")
+
+	height, ok, err := x.getHeight(tx)
+	if err != nil {
+		return err
+	}
+
+	if ok && height == headSeq {
+		return nil
+	}
+
+	if height > headSeq {
+		logger.Critical().Warningf("txn index height > headSeq (%d > %d)", height, headSeq)
+	}
+
+	logger.Infof("Rebuilding txn_index (heightExists=%v, height=%d, headSeq=%d)", ok, height, headSeq)
+
+	if err := dbutil.Reset(tx, TxnIndexBkt); err != nil {
+		return err
+	}
+
+	for seq := uint64(0); seq <= headSeq; seq++ {
+		b, err := getBlock(tx, seq)
+		if err != nil {
+			return err
+		}
+		if b == nil {
+			return fmt.Errorf("txn index rebuild failed: block %d not found", seq)
+		}
+
+		if err := x.addBlock(tx, b); err != nil {
+			return err
+		}
+	}
+
+	return x.setHeight(tx, headSeq)
+}
+
+// TxnProof proves that a transaction is in a signed block, by the merkle branch
+// from the transaction hash to the body hash of the block header
+type TxnProof struct {
+	Header coin.BlockHeader
+	Sig    cipher.Sig
+	Txid   cipher.SHA256
+	// Index of the transaction in the block
+	Index uint32
+	// Sibling hashes of the merkle branch, from the transaction hash up
+	Hashes []cipher.SHA256
+}
+
+// NewTxnProof creates the proof of the transaction at index of a block
+func NewTxnProof(b *coin.SignedBlock, index int) TxnProof {
+	hashes := make([]cipher.SHA256, len(b.Body.Transactions))
+	for i := range b.Body.Transactions {
+		hashes[i] = b.Body.Transactions[i].Hash()
+	}
+
+	return TxnProof{
+		Header: b.Head,
+		Sig:    b.Sig,
+		Txid:   hashes[index],
+		Index:  uint32(index),
+		Hashes: cipher.MerkleProof(hashes, index),
+	}
+}
+
+// Verify checks that the block header is signed by pubkey, and that the transaction is in the block
+func (p TxnProof) Verify(pubkey cipher.PubKey) error {
+	if err := cipher.VerifyPubKeySignedHash(pubkey, p.Sig, p.Header.Hash()); err != nil {
+		return err
+	}
+
+	if !cipher.VerifyMerkleProof(p.Header.BodyHash, p.Txid, uint64(p.Index), p.Hashes) {
+		return errors.New("Transaction merkle proof does not match the block body hash")
+	}
+
+	return nil
+}
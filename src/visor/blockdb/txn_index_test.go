@@ -0,0 +1,148 @@
+package blockdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+func TestTxnIndex(t *testing.T) {
+	db, closeDB := prepareDB(t)
+	defer closeDB()
+
+	bc, err := NewBlockchain(db, DefaultWalker)
+	require.NoError(t, err)
+
+	// An empty blockchain has nothing to index
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return bc.MaybeBuildTxnIndex(tx)
+	})
+	require.NoError(t, err)
+
+	gb := makeGenesisBlock(t)
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return bc.AddBlock(tx, &gb)
+	})
+	require.NoError(t, err)
+
+	_, secA := cipher.GenerateKeyPair()
+	addrA := cipher.AddressFromPubKey(cipher.MustPubKeyFromSecKey(secA))
+
+	genUx := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])[0]
+	b1 := addSpendBlock(t, db, bc, gb, genUx, genSecret, addrA, 400e3)
+
+	blocks := []coin.SignedBlock{gb, b1}
+
+	requireProofs := func() {
+		err := db.View("", func(tx *dbutil.Tx) error {
+			for _, b := range blocks {
+				txid := b.Body.Transactions[0].Hash()
+				p, err := bc.TxnProof(tx, txid)
+				require.NoError(t, err)
+				require.NotNil(t, p)
+				require.Equal(t, b.Head, p.Header)
+				require.Equal(t, b.Sig, p.Sig)
+				require.Equal(t, txid, p.Txid)
+				require.Equal(t, uint32(0), p.Index)
+				require.NoError(t, p.Verify(genPublic))
+			}
+
+			// Unknown transaction
+			p, err := bc.TxnProof(tx, testutil.RandSHA256(t))
+			require.NoError(t, err)
+			require.Nil(t, p)
+
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	requireProofs()
+
+	// A db created before the index existed is indexed from the blockchain
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		if err := dbutil.Reset(tx, TxnIndexBkt); err != nil {
+			return err
+		}
+		if err := dbutil.Reset(tx, TxnIndexMetaBkt); err != nil {
+			return err
+		}
+		return bc.MaybeBuildTxnIndex(tx)
+	})
+	require.NoError(t, err)
+	requireProofs()
+
+	// Blocks added after the rebuild continue the index
+	uxA := coin.CreateUnspents(b1.Head, b1.Body.Transactions[0])[0]
+	b2 := addSpendBlock(t, db, bc, b1, uxA, secA, testutil.MakeAddress(), 100e3)
+	blocks = append(blocks, b2)
+	requireProofs()
+}
+
+func TestTxnProof(t *testing.T) {
+	pubkey, seckey := cipher.GenerateKeyPair()
+
+	txns := make(coin.Transactions, 3)
+	for i := range txns {
+		txns[i] = coin.Transaction{
+			In: []cipher.SHA256{testutil.RandSHA256(t)},
+		}
+	}
+
+	b := coin.SignedBlock{
+		Block: coin.Block{
+			Head: coin.BlockHeader{
+				BkSeq: 5,
+			},
+			Body: coin.BlockBody{
+				Transactions: txns,
+			},
+		},
+	}
+	b.Head.BodyHash = b.Body.Hash()
+	b.Sig = cipher.MustSignHash(b.HashHeader(), seckey)
+
+	for i := range txns {
+		p := NewTxnProof(&b, i)
+		require.Equal(t, txns[i].Hash(), p.Txid)
+		require.Len(t, p.Hashes, 2)
+		require.NoError(t, p.Verify(pubkey))
+	}
+
+	// The proof must match the body hash
+	p := NewTxnProof(&b, 1)
+	p.Txid = txns[0].Hash()
+	require.EqualError(t, p.Verify(pubkey), "Transaction merkle proof does not match the block body hash")
+
+	// The header must be signed by the blockchain pubkey
+	otherPubkey, _ := cipher.GenerateKeyPair()
+	p = NewTxnProof(&b, 1)
+	require.Error(t, p.Verify(otherPubkey))
+}
+
+func TestTxnIndexProcessBlockOutOfOrder(t *testing.T) {
+	db, closeDB := prepareDB(t)
+	defer closeDB()
+
+	x := NewTxnIndex()
+	gb := makeGenesisBlock(t)
+
+	err := db.Update("", func(tx *dbutil.Tx) error {
+		require.NoError(t, x.ProcessBlock(tx, &gb))
+
+		err := x.ProcessBlock(tx, &gb)
+		require.EqualError(t, err, "txn index height is set but no block has been indexed yet")
+
+		gb.Head.BkSeq = 2
+		err = x.ProcessBlock(tx, &gb)
+		require.EqualError(t, err, "txn index processing blocks out of order")
+
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -270,6 +270,14 @@ func NewUnspentPool() *Unspents {
 func (up *Unspents) MaybeBuildIndexes(tx *dbutil.Tx, headSeq uint64) error {
 	logger.Info("Unspents.MaybeBuildIndexes")
 
+	if err := up.maybeBuildAddrIndex(tx, headSeq); err != nil {
+		return err
+	}
+
+	return up.maybeBuildStats(tx, headSeq)
+}
+
+func (up *Unspents) maybeBuildAddrIndex(tx *dbutil.Tx, headSeq uint64) error {
 	// Compare the addrIndexHeight to the head block,
 	// if not equal, rebuild the address index
 	addrIndexHeight, ok, err := up.meta.getAddrIndexHeight(tx)
@@ -290,6 +298,25 @@ func (up *Unspents) MaybeBuildIndexes(tx *dbutil.Tx, headSeq uint64) error {
 	return up.buildAddrIndex(tx)
 }
 
+func (up *Unspents) maybeBuildStats(tx *dbutil.Tx, headSeq uint64) error {
+	height, ok, err := up.meta.getStatsHeight(tx)
+	if err != nil {
+		return err
+	}
+
+	if ok && height == headSeq {
+		return nil
+	}
+
+	if height > headSeq {
+		logger.Critical().Warningf("unspent stats height > headSeq (%d > %d)", height, headSeq)
+	}
+
+	logger.Infof("Rebuilding unspent stats (heightExists=%v, height=%d, headSeq=%d)", ok, height, headSeq)
+
+	return up.buildStats(tx, headSeq)
+}
+
 func (up *Unspents) buildAddrIndex(tx *dbutil.Tx) error {
 	logger.Info("Building unspent address index")
 
@@ -410,6 +437,10 @@ func (up *Unspents) ProcessBlock(tx *dbutil.Tx, b *coin.SignedBlock) error {
 		return err
 	}
 
+	if err := up.processBlockStats(tx, b, uxs, txnUxs); err != nil {
+		return err
+	}
+
 	// Update indexes
 	for addr, rmHashes := range rmAddrHashes {
 		addHashes := addAddrHashes[addr]
@@ -0,0 +1,31 @@
+package blockdb
+
+import (
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+)
+
+// The unspent commitment is a MuHash of the SnapshotHash of every unspent output.
+// Unlike the xor of the unspent hashes, adding an output twice does not cancel it out,
+// and the commitment can be updated incrementally as outputs are created and spent.
+
+// addUnspentCommitment adds ux to the commitment c
+func addUnspentCommitment(c *cipher.MuHash, ux coin.UxOut) {
+	h := ux.SnapshotHash()
+	c.Insert(h[:])
+}
+
+// subUnspentCommitment removes ux from the commitment c
+func subUnspentCommitment(c *cipher.MuHash, ux coin.UxOut) {
+	h := ux.SnapshotHash()
+	c.Remove(h[:])
+}
+
+// computeUnspentCommitment computes the commitment of uxa
+func computeUnspentCommitment(uxa coin.UxArray) *cipher.MuHash {
+	c := cipher.NewMuHash()
+	for _, ux := range uxa {
+		addUnspentCommitment(c, ux)
+	}
+	return c
+}
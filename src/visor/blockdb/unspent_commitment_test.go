@@ -0,0 +1,59 @@
+package blockdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+func TestUnspentCommitment(t *testing.T) {
+	a := makeUxOut(t)
+	b := makeUxOut(t)
+
+	empty := cipher.NewMuHash().Digest()
+
+	// Order independent
+	c1 := cipher.NewMuHash()
+	addUnspentCommitment(c1, a)
+	addUnspentCommitment(c1, b)
+	c2 := cipher.NewMuHash()
+	addUnspentCommitment(c2, b)
+	addUnspentCommitment(c2, a)
+	require.Equal(t, c1.Digest(), c2.Digest())
+	require.Equal(t, c1.Digest(), computeUnspentCommitment(coin.UxArray{a, b}).Digest())
+
+	// Removing an output undoes adding it
+	subUnspentCommitment(c1, b)
+	require.Equal(t, computeUnspentCommitment(coin.UxArray{a}).Digest(), c1.Digest())
+	subUnspentCommitment(c1, a)
+	require.Equal(t, empty, c1.Digest())
+
+	// Adding an output twice does not cancel it out
+	require.NotEqual(t, empty, computeUnspentCommitment(coin.UxArray{a, a}).Digest())
+}
+
+func TestBlockchainUnspentCommitment(t *testing.T) {
+	db, teardown := prepareDB(t)
+	defer teardown()
+
+	makeVerifyChain(t, db)
+
+	bc, err := NewBlockchain(db, DefaultWalker)
+	require.NoError(t, err)
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		uxa, err := bc.UnspentPool().GetAll(tx)
+		require.NoError(t, err)
+		require.Len(t, uxa, 1)
+
+		c, err := bc.UnspentCommitment(tx)
+		require.NoError(t, err)
+		require.Equal(t, computeUnspentCommitment(uxa).Digest(), c)
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,222 @@
+package blockdb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher/encoder"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/util/mathutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+var (
+	statsKey       = []byte("stats")
+	statsHeightKey = []byte("stats_height")
+
+	// UnspentStatsBounds are the bounds of the buckets of UnspentStats.Histogram, in droplets.
+	// Bucket i holds the outputs with coins below UnspentStatsBounds[i] and at least UnspentStatsBounds[i-1],
+	// and the last bucket holds the outputs with at least the last bound.
+	UnspentStatsBounds = [UnspentStatsBuckets - 1]uint64{1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9, 1e10, 1e11, 1e12}
+)
+
+// UnspentStatsBuckets is the number of buckets of UnspentStats.Histogram
+const UnspentStatsBuckets = 11
+
+// UnspentStatsBucket aggregates the unspent outputs of a range of coins
+type UnspentStatsBucket struct {
+	Count uint64
+	Coins uint64
+}
+
+// UnspentStats are the statistics of the unspent output pool, maintained as blocks are processed
+type UnspentStats struct {
+	// Total is the BalanceTotal of all unspent outputs
+	Total BalanceTotal
+	// Count is the number of unspent outputs
+	Count uint64
+	// Histogram aggregates the unspent outputs by coins, in the buckets bounded by UnspentStatsBounds
+	Histogram [UnspentStatsBuckets]UnspentStatsBucket
+}
+
+func unspentStatsBucket(coins uint64) int {
+	for i, b := range UnspentStatsBounds {
+		if coins < b {
+			return i
+		}
+	}
+	return len(UnspentStatsBounds)
+}
+
+func (s *UnspentStats) add(ux coin.UxOut) error {
+	t, err := newUxBalanceTotal(ux)
+	if err != nil {
+		return err
+	}
+
+	if s.Total, err = s.Total.Add(t); err != nil {
+		return err
+	}
+
+	h := &s.Histogram[unspentStatsBucket(ux.Body.Coins)]
+	if h.Coins, err = mathutil.AddUint64(h.Coins, ux.Body.Coins); err != nil {
+		return err
+	}
+
+	h.Count++
+	s.Count++
+
+	return nil
+}
+
+func (s *UnspentStats) sub(ux coin.UxOut) error {
+	t, err := newUxBalanceTotal(ux)
+	if err != nil {
+		return err
+	}
+
+	h := &s.Histogram[unspentStatsBucket(ux.Body.Coins)]
+	if s.Count == 0 || h.Count == 0 || h.Coins < ux.Body.Coins {
+		return errors.New("UnspentStats.sub underflow")
+	}
+
+	if s.Total, err = s.Total.Sub(t); err != nil {
+		return err
+	}
+
+	h.Coins -= ux.Body.Coins
+	h.Count--
+	s.Count--
+
+	return nil
+}
+
+// Dust returns the number of unspent outputs with fewer coins than threshold, and their coins.
+// The threshold must be one of UnspentStatsBounds.
+func (s UnspentStats) Dust(threshold uint64) (UnspentStatsBucket, error) {
+	var dust UnspentStatsBucket
+	for i, b := range UnspentStatsBounds {
+		if b > threshold {
+			break
+		}
+
+		var err error
+		if dust.Coins, err = mathutil.AddUint64(dust.Coins, s.Histogram[i].Coins); err != nil {
+			return UnspentStatsBucket{}, err
+		}
+		dust.Count += s.Histogram[i].Count
+
+		if b == threshold {
+			return dust, nil
+		}
+	}
+
+	return UnspentStatsBucket{}, fmt.Errorf("dust threshold %d is not a bound of the unspent stats histogram", threshold)
+}
+
+func (m unspentMeta) getStats(tx *dbutil.Tx) (UnspentStats, error) {
+	var s UnspentStats
+	if _, err := dbutil.GetBucketObjectDecoded(tx, UnspentMetaBkt, statsKey, &s); err != nil {
+		return UnspentStats{}, err
+	}
+	return s, nil
+}
+
+func (m *unspentMeta) setStats(tx *dbutil.Tx, s UnspentStats) error {
+	return dbutil.PutBucketValue(tx, UnspentMetaBkt, statsKey, encoder.Serialize(s))
+}
+
+func (m *unspentMeta) getStatsHeight(tx *dbutil.Tx) (uint64, bool, error) {
+	v, err := dbutil.GetBucketValue(tx, UnspentMetaBkt, statsHeightKey)
+	if err != nil {
+		return 0, false, err
+	} else if v == nil {
+		return 0, false, nil
+	}
+
+	return dbutil.Btoi(v), true, nil
+}
+
+func (m *unspentMeta) setStatsHeight(tx *dbutil.Tx, height uint64) error {
+	return dbutil.PutBucketValue(tx, UnspentMetaBkt, statsHeightKey, dbutil.Itob(height))
+}
+
+// processBlockStats updates the stats with the outputs spent and created by a block
+func (up *Unspents) processBlockStats(tx *dbutil.Tx, b *coin.SignedBlock, spent, created coin.UxArray) error {
+	s, err := up.meta.getStats(tx)
+	if err != nil {
+		return err
+	}
+
+	for _, ux := range spent {
+		if err := s.sub(ux); err != nil {
+			return err
+		}
+	}
+
+	for _, ux := range created {
+		if err := s.add(ux); err != nil {
+			return err
+		}
+	}
+
+	if err := up.meta.setStats(tx, s); err != nil {
+		return err
+	}
+
+	// Check that the stats height is incremental
+	height, ok, err := up.meta.getStatsHeight(tx)
+	if err != nil {
+		return err
+	}
+
+	if b.Head.BkSeq == 0 {
+		if ok {
+			err := errors.New("unspent stats height is set but no block has been processed yet")
+			logger.Critical().Error(err.Error())
+			return err
+		}
+	} else if b.Head.BkSeq != height+1 {
+		err := errors.New("unspent stats processing blocks out of order")
+		logger.Critical().Error(err.Error())
+		return err
+	}
+
+	return up.meta.setStatsHeight(tx, b.Head.BkSeq)
+}
+
+// buildStats computes the stats from all the outputs of the pool
+func (up *Unspents) buildStats(tx *dbutil.Tx, headSeq uint64) error {
+	logger.Info("Building unspent stats")
+
+	var s UnspentStats
+	if err := dbutil.ForEach(tx, UnspentPoolBkt, func(_, v []byte) error {
+		var ux coin.UxOut
+		if err := decodeUxOutExact(v, &ux); err != nil {
+			return err
+		}
+
+		return s.add(ux)
+	}); err != nil {
+		return err
+	}
+
+	// The stats of an empty pool are computed when the genesis block is processed
+	if s.Count == 0 {
+		logger.Infof("No unspents to compute the stats of")
+		return dbutil.Delete(tx, UnspentMetaBkt, statsKey)
+	}
+
+	if err := up.meta.setStats(tx, s); err != nil {
+		return err
+	}
+
+	logger.Infof("Computed the stats of %d unspents", s.Count)
+
+	return up.meta.setStatsHeight(tx, headSeq)
+}
+
+// Stats returns the statistics of the unspent outputs
+func (up *Unspents) Stats(tx *dbutil.Tx) (UnspentStats, error) {
+	return up.meta.getStats(tx)
+}
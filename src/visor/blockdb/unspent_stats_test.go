@@ -0,0 +1,174 @@
+package blockdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher/encoder"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+func TestUnspentStatsDust(t *testing.T) {
+	var s UnspentStats
+	s.Histogram[0] = UnspentStatsBucket{Count: 1, Coins: 1}
+	s.Histogram[1] = UnspentStatsBucket{Count: 2, Coins: 2e3}
+	s.Histogram[2] = UnspentStatsBucket{Count: 3, Coins: 3e4}
+	s.Histogram[10] = UnspentStatsBucket{Count: 4, Coins: 4e12}
+
+	dust, err := s.Dust(1e3)
+	require.NoError(t, err)
+	require.Equal(t, UnspentStatsBucket{Count: 1, Coins: 1}, dust)
+
+	dust, err = s.Dust(1e5)
+	require.NoError(t, err)
+	require.Equal(t, UnspentStatsBucket{Count: 6, Coins: 32001}, dust)
+
+	dust, err = s.Dust(1e12)
+	require.NoError(t, err)
+	require.Equal(t, UnspentStatsBucket{Count: 6, Coins: 32001}, dust)
+
+	_, err = s.Dust(5e3)
+	require.Error(t, err)
+
+	_, err = s.Dust(1e13)
+	require.Error(t, err)
+}
+
+func TestUnspentStatsProcessBlock(t *testing.T) {
+	db, closedb := prepareDB(t)
+	defer closedb()
+
+	up := NewUnspentPool()
+
+	genesis, err := coin.NewGenesisBlock(testutil.MakeAddress(), 100e6, 100, nil)
+	require.NoError(t, err)
+
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return up.ProcessBlock(tx, &coin.SignedBlock{
+			Block: *genesis,
+		})
+	})
+	require.NoError(t, err)
+
+	genesisUx := coin.CreateUnspents(genesis.Head, genesis.Body.Transactions[0])[0]
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		s, err := up.Stats(tx)
+		require.NoError(t, err)
+
+		var expected UnspentStats
+		expected.Total, err = newUxBalanceTotal(genesisUx)
+		require.NoError(t, err)
+		expected.Count = 1
+		expected.Histogram[6] = UnspentStatsBucket{Count: 1, Coins: 100e6}
+		require.Equal(t, expected, s)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Spend the genesis output to a dust output and a change output
+	txn := coin.Transaction{}
+	err = txn.PushInput(genesisUx.Hash())
+	require.NoError(t, err)
+	err = txn.PushOutput(testutil.MakeAddress(), 5e3, 10, nil)
+	require.NoError(t, err)
+	err = txn.PushOutput(genesisUx.Body.Address, 100e6-5e3, 10, nil)
+	require.NoError(t, err)
+
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		uxHash, err := up.GetUxHash(tx)
+		require.NoError(t, err)
+
+		b, err := coin.NewBlock(*genesis, genesis.Head.Time+100, uxHash, coin.Transactions{txn}, feeCalc)
+		require.NoError(t, err)
+
+		return up.ProcessBlock(tx, &coin.SignedBlock{
+			Block: *b,
+		})
+	})
+	require.NoError(t, err)
+
+	// Processing a block out of order is rejected
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		b, err := coin.NewGenesisBlock(testutil.MakeAddress(), 100e6, 300, nil)
+		require.NoError(t, err)
+		b.Head.BkSeq = 3
+		return up.ProcessBlock(tx, &coin.SignedBlock{
+			Block: *b,
+		})
+	})
+	require.EqualError(t, err, "unspent stats processing blocks out of order")
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		s, err := up.Stats(tx)
+		require.NoError(t, err)
+
+		require.Equal(t, uint64(2), s.Count)
+		require.Equal(t, uint64(100e6), s.Total.Coins)
+		require.Equal(t, uint64(20), s.Total.Hours)
+		require.Equal(t, UnspentStatsBucket{Count: 1, Coins: 5e3}, s.Histogram[1])
+		require.Equal(t, UnspentStatsBucket{Count: 1, Coins: 100e6 - 5e3}, s.Histogram[5])
+
+		height, ok, err := up.meta.getStatsHeight(tx)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, uint64(1), height)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestUnspentMaybeBuildIndexesStats(t *testing.T) {
+	db, shutdown := setupNoUnspentAddrIndexDB(t)
+	defer shutdown()
+
+	u := NewUnspentPool()
+
+	err := db.Update("", func(tx *dbutil.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(UnspentPoolAddrIndexBkt); err != nil {
+			return err
+		}
+
+		return u.MaybeBuildIndexes(tx, 180)
+	})
+	require.NoError(t, err)
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		// Compute the stats the slow way, to confirm they match the built stats
+		var expected UnspentStats
+		err := dbutil.ForEach(tx, UnspentPoolBkt, func(k, v []byte) error {
+			var ux coin.UxOut
+			err := encoder.DeserializeRawExact(v, &ux)
+			require.NoError(t, err)
+
+			expected.Count++
+			expected.Total.Coins += ux.Body.Coins
+			expected.Total.Hours += ux.Body.Hours
+			ct, err := uxCoinTime(ux.Body.Coins, ux.Head.Time)
+			require.NoError(t, err)
+			expected.Total.CoinTime += ct
+
+			h := &expected.Histogram[unspentStatsBucket(ux.Body.Coins)]
+			h.Count++
+			h.Coins += ux.Body.Coins
+
+			return nil
+		})
+		require.NoError(t, err)
+		require.NotZero(t, expected.Count)
+
+		s, err := u.Stats(tx)
+		require.NoError(t, err)
+		require.Equal(t, expected, s)
+
+		height, ok, err := u.meta.getStatsHeight(tx)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, uint64(180), height)
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -68,7 +68,20 @@ func addUxOut(db *dbutil.DB, up *Unspents, ux coin.UxOut) error {
 			return err
 		}
 
-		return up.poolAddrIndex.adjust(tx, ux.Body.Address, []cipher.SHA256{ux.Hash()}, nil)
+		if err := up.poolAddrIndex.adjust(tx, ux.Body.Address, []cipher.SHA256{ux.Hash()}, nil); err != nil {
+			return err
+		}
+
+		s, err := up.meta.getStats(tx)
+		if err != nil {
+			return err
+		}
+
+		if err := s.add(ux); err != nil {
+			return err
+		}
+
+		return up.meta.setStats(tx, s)
 	})
 }
 
@@ -513,7 +526,7 @@ func TestUnspentProcessBlock(t *testing.T) {
 			}
 
 			for _, o := range tc.outputs {
-				err := txn.PushOutput(o.addr, o.coins, o.hours)
+				err := txn.PushOutput(o.addr, o.coins, o.hours, nil)
 				require.NoError(t, err)
 			}
 
@@ -1090,7 +1103,7 @@ func TestUnspentMaybeBuildIndexesNoRebuild(t *testing.T) {
 func setupNoUnspentAddrIndexDB(t *testing.T) (*dbutil.DB, func()) {
 	// Open a test database file that lacks UnspentPoolAddrIndexBkt,
 	// copy it to a temp file and open a database around the temp file
-	dbFilename := "./testdata/blockchain-180.no-unspent-addr-index.db"
+	dbFilename := "./testdata/cx/blockchain-180.no-unspent-addr-index.db"
 	dbFile, err := os.Open(dbFilename)
 	require.NoError(t, err)
 
@@ -0,0 +1,265 @@
+package blockdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+var (
+	uxDeltaIndexHeightKey = []byte("ux_delta_index_height")
+
+	// UxDeltasBkt maps block seqs to the outputs spent and created by the block
+	UxDeltasBkt = []byte("ux_deltas")
+	// UxDeltasMetaBkt holds ux delta index metadata
+	UxDeltasMetaBkt = []byte("ux_deltas_meta")
+
+	// ErrSeqAfterHead is returned when reading the state of the blockchain at a block after the head block
+	ErrSeqAfterHead = errors.New("Block seq is higher than the head block seq")
+)
+
+// uxDeltaOutputSize is the size of an encoded UxDeltaOutput
+const uxDeltaOutputSize = len(cipher.SHA256{}) + 25 + 8*3
+
+// UxDeltaOutput is an output spent or created by a block, without its program state
+type UxDeltaOutput struct {
+	Hash    cipher.SHA256
+	Address cipher.Address
+	Coins   uint64
+	Hours   uint64
+	// Time is the time of the block which created the output
+	Time uint64
+}
+
+func newUxDeltaOutput(ux coin.UxOut) UxDeltaOutput {
+	return UxDeltaOutput{
+		Hash:    ux.Hash(),
+		Address: ux.Body.Address,
+		Coins:   ux.Body.Coins,
+		Hours:   ux.Body.Hours,
+		Time:    ux.Head.Time,
+	}
+}
+
+// BalanceTotal returns the BalanceTotal of the output
+func (o UxDeltaOutput) BalanceTotal() (BalanceTotal, error) {
+	ct, err := uxCoinTime(o.Coins, o.Time)
+	if err != nil {
+		return BalanceTotal{}, err
+	}
+
+	return BalanceTotal{
+		Coins:    o.Coins,
+		Hours:    o.Hours,
+		CoinTime: ct,
+	}, nil
+}
+
+// UxDelta is the change of the unspent output set made by a block.
+// The outputs are recorded whole rather than by hash, because the spent outputs are removed from the unspent pool.
+type UxDelta struct {
+	Spent   []UxDeltaOutput
+	Created []UxDeltaOutput
+}
+
+func encodeUxDelta(d UxDelta) []byte {
+	v := make([]byte, 4, 4+(len(d.Spent)+len(d.Created))*uxDeltaOutputSize)
+	binary.BigEndian.PutUint32(v, uint32(len(d.Spent)))
+
+	for _, outs := range [][]UxDeltaOutput{d.Spent, d.Created} {
+		for _, o := range outs {
+			v = append(v, o.Hash[:]...)
+			v = append(v, o.Address.Bytes()...)
+			v = append(v, dbutil.Itob(o.Coins)...)
+			v = append(v, dbutil.Itob(o.Hours)...)
+			v = append(v, dbutil.Itob(o.Time)...)
+		}
+	}
+
+	return v
+}
+
+func decodeUxDelta(v []byte) (UxDelta, error) {
+	if len(v) < 4 || (len(v)-4)%uxDeltaOutputSize != 0 {
+		return UxDelta{}, errors.New("invalid ux delta value length")
+	}
+
+	nSpent := int(binary.BigEndian.Uint32(v))
+	v = v[4:]
+	if nSpent > len(v)/uxDeltaOutputSize {
+		return UxDelta{}, errors.New("invalid ux delta spent outputs count")
+	}
+
+	outs := make([]UxDeltaOutput, len(v)/uxDeltaOutputSize)
+	for i := range outs {
+		b := v[i*uxDeltaOutputSize : (i+1)*uxDeltaOutputSize]
+
+		addr, err := cipher.AddressFromBytes(b[32:57])
+		if err != nil {
+			return UxDelta{}, err
+		}
+
+		copy(outs[i].Hash[:], b[:32])
+		outs[i].Address = addr
+		outs[i].Coins = dbutil.Btoi(b[57:65])
+		outs[i].Hours = dbutil.Btoi(b[65:73])
+		outs[i].Time = dbutil.Btoi(b[73:81])
+	}
+
+	return UxDelta{
+		Spent:   outs[:nSpent],
+		Created: outs[nSpent:],
+	}, nil
+}
+
+// UxDeltaIndex records the outputs spent and created by each block, so that the unspent outputs
+// of addresses at a past block can be recovered by undoing the blocks after it
+type UxDeltaIndex struct{}
+
+// NewUxDeltaIndex creates a UxDeltaIndex
+func NewUxDeltaIndex() *UxDeltaIndex {
+	return &UxDeltaIndex{}
+}
+
+func (x *UxDeltaIndex) getHeight(tx *dbutil.Tx) (uint64, bool, error) {
+	v, err := dbutil.GetBucketValue(tx, UxDeltasMetaBkt, uxDeltaIndexHeightKey)
+	if err != nil {
+		return 0, false, err
+	} else if v == nil {
+		return 0, false, nil
+	}
+
+	return dbutil.Btoi(v), true, nil
+}
+
+func (x *UxDeltaIndex) setHeight(tx *dbutil.Tx, height uint64) error {
+	return dbutil.PutBucketValue(tx, UxDeltasMetaBkt, uxDeltaIndexHeightKey, dbutil.Itob(height))
+}
+
+// ProcessBlock records the outputs spent and created by a block.
+// spent are the outputs spent by the block.
+func (x *UxDeltaIndex) ProcessBlock(tx *dbutil.Tx, b *coin.SignedBlock, spent coin.UxArray) error {
+	if err := x.addBlock(tx, b, spent); err != nil {
+		return err
+	}
+
+	// Check that the index height is incremental
+	height, ok, err := x.getHeight(tx)
+	if err != nil {
+		return err
+	}
+
+	if b.Head.BkSeq == 0 {
+		if ok {
+			err := errors.New("ux delta index height is set but no block has been indexed yet")
+			logger.Critical().Error(err.Error())
+			return err
+		}
+	} else if b.Head.BkSeq != height+1 {
+		err := errors.New("ux delta index processing blocks out of order")
+		logger.Critical().Error(err.Error())
+		return err
+	}
+
+	return x.setHeight(tx, b.Head.BkSeq)
+}
+
+func (x *UxDeltaIndex) addBlock(tx *dbutil.Tx, b *coin.SignedBlock, spent coin.UxArray) error {
+	if len(b.Body.Transactions) == 0 {
+		return nil
+	}
+
+	d := UxDelta{
+		Spent: make([]UxDeltaOutput, len(spent)),
+	}
+
+	for i, ux := range spent {
+		d.Spent[i] = newUxDeltaOutput(ux)
+	}
+
+	for _, txn := range b.Body.Transactions {
+		for _, ux := range coin.CreateUnspents(b.Head, txn) {
+			d.Created = append(d.Created, newUxDeltaOutput(ux))
+		}
+	}
+
+	return dbutil.PutBucketValue(tx, UxDeltasBkt, dbutil.Itob(b.Head.BkSeq), encodeUxDelta(d))
+}
+
+// Get returns the outputs spent and created by the block at seq.
+// The delta is empty if the block has no transactions.
+func (x *UxDeltaIndex) Get(tx *dbutil.Tx, seq uint64) (UxDelta, error) {
+	v, err := dbutil.GetBucketValue(tx, UxDeltasBkt, dbutil.Itob(seq))
+	if err != nil {
+		return UxDelta{}, err
+	} else if v == nil {
+		return UxDelta{}, nil
+	}
+
+	return decodeUxDelta(v)
+}
+
+// MaybeBuild rebuilds the index if it is not at the height of the head block.
+// getBlock returns the block with a given seq from the blockchain.
+func (x *UxDeltaIndex) MaybeBuild(tx *dbutil.Tx, headSeq uint64, getBlock func(*dbutil.Tx, uint64) (*coin.SignedBlock, error)) error {
+	logger.Info("UxDeltaIndex.MaybeBuild")
+
+	height, ok, err := x.getHeight(tx)
+	if err != nil {
+		return err
+	}
+
+	if ok && height == headSeq {
+		return nil
+	}
+
+	if height > headSeq {
+		logger.Critical().Warningf("ux delta index height > headSeq (%d > %d)", height, headSeq)
+	}
+
+	logger.Infof("Rebuilding ux_deltas (heightExists=%v, height=%d, headSeq=%d)", ok, height, headSeq)
+
+	if err := dbutil.Reset(tx, UxDeltasBkt); err != nil {
+		return err
+	}
+
+	// Replay the blockchain, tracking the outputs which have not been spent yet
+	unspent := make(map[cipher.SHA256]coin.UxOut)
+	for seq := uint64(0); seq <= headSeq; seq++ {
+		b, err := getBlock(tx, seq)
+		if err != nil {
+			return err
+		}
+		if b == nil {
+			return fmt.Errorf("ux delta index rebuild failed: block %d not found", seq)
+		}
+
+		var spent coin.UxArray
+		for _, txn := range b.Body.Transactions {
+			for _, in := range txn.In {
+				ux, ok := unspent[in]
+				if !ok {
+					return NewErrUnspentNotExist(in.Hex())
+				}
+				spent = append(spent, ux)
+				delete(unspent, in)
+			}
+		}
+
+		if err := x.addBlock(tx, b, spent); err != nil {
+			return err
+		}
+
+		for _, txn := range b.Body.Transactions {
+			for _, ux := range coin.CreateUnspents(b.Head, txn) {
+				unspent[ux.Hash()] = ux
+			}
+		}
+	}
+
+	return x.setHeight(tx, headSeq)
+}
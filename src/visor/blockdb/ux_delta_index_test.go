@@ -0,0 +1,144 @@
+package blockdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+func TestUxDeltaEncoding(t *testing.T) {
+	d := UxDelta{
+		Spent: []UxDeltaOutput{
+			{Hash: testutil.RandSHA256(t), Address: testutil.MakeAddress(), Coins: 1e6, Hours: 20, Time: 100},
+		},
+		Created: []UxDeltaOutput{
+			{Hash: testutil.RandSHA256(t), Address: testutil.MakeAddress(), Coins: 4e5, Hours: 5, Time: 200},
+			{Hash: testutil.RandSHA256(t), Address: testutil.MakeAddress(), Coins: 6e5, Hours: 5, Time: 200},
+		},
+	}
+
+	v := encodeUxDelta(d)
+	require.Len(t, v, 4+3*uxDeltaOutputSize)
+
+	got, err := decodeUxDelta(v)
+	require.NoError(t, err)
+	require.Equal(t, d, got)
+
+	_, err = decodeUxDelta(v[:len(v)-1])
+	require.Error(t, err)
+
+	v[3] = 4
+	_, err = decodeUxDelta(v)
+	require.Error(t, err)
+}
+
+func TestAddressBalanceTotalsAtSeq(t *testing.T) {
+	db, closeDB := prepareDB(t)
+	defer closeDB()
+
+	bc, err := NewBlockchain(db, DefaultWalker)
+	require.NoError(t, err)
+
+	gb := makeGenesisBlock(t)
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return bc.AddBlock(tx, &gb)
+	})
+	require.NoError(t, err)
+
+	pubA, secA := cipher.GenerateKeyPair()
+	addrA := cipher.AddressFromPubKey(pubA)
+	addrB := testutil.MakeAddress()
+	addrs := []cipher.Address{genAddress, addrA, addrB}
+
+	// The balances after each block, as maintained by the balance index
+	var expect [][]BalanceTotal
+	recordBalances := func() {
+		err := db.View("", func(tx *dbutil.Tx) error {
+			totals, err := bc.AddressBalanceTotals(tx, addrs)
+			require.NoError(t, err)
+			expect = append(expect, totals)
+			return nil
+		})
+		require.NoError(t, err)
+	}
+	recordBalances()
+
+	genUx := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])[0]
+	b1 := addSpendBlock(t, db, bc, gb, genUx, genSecret, addrA, 400e3)
+	recordBalances()
+
+	uxA := coin.CreateUnspents(b1.Head, b1.Body.Transactions[0])[0]
+	b2 := addSpendBlock(t, db, bc, b1, uxA, secA, addrB, 100e3)
+	recordBalances()
+
+	uxA = coin.CreateUnspents(b2.Head, b2.Body.Transactions[0])[1]
+	addSpendBlock(t, db, bc, b2, uxA, secA, addrA, 50e3)
+	recordBalances()
+
+	require.Equal(t, uint64(1e6), expect[0][0].Coins)
+	require.Equal(t, []uint64{600e3, 400e3, 0}, []uint64{expect[1][0].Coins, expect[1][1].Coins, expect[1][2].Coins})
+	require.Equal(t, []uint64{600e3, 300e3, 100e3}, []uint64{expect[2][0].Coins, expect[2][1].Coins, expect[2][2].Coins})
+
+	requireBalances := func() {
+		err := db.View("", func(tx *dbutil.Tx) error {
+			for seq, totals := range expect {
+				got, err := bc.AddressBalanceTotalsAtSeq(tx, addrs, uint64(seq))
+				require.NoError(t, err)
+				require.Equal(t, totals, got, "seq %d", seq)
+			}
+
+			// Duplicate addresses
+			got, err := bc.AddressBalanceTotalsAtSeq(tx, []cipher.Address{addrA, addrA}, 1)
+			require.NoError(t, err)
+			require.Equal(t, []BalanceTotal{expect[1][1], expect[1][1]}, got)
+
+			_, err = bc.AddressBalanceTotalsAtSeq(tx, addrs, uint64(len(expect)))
+			require.Equal(t, ErrSeqAfterHead, err)
+
+			return nil
+		})
+		require.NoError(t, err)
+	}
+
+	requireBalances()
+
+	// A db created before the index existed is indexed from the blockchain
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		if err := dbutil.Reset(tx, UxDeltasBkt); err != nil {
+			return err
+		}
+		if err := dbutil.Reset(tx, UxDeltasMetaBkt); err != nil {
+			return err
+		}
+		return bc.MaybeBuildUxDeltaIndex(tx)
+	})
+	require.NoError(t, err)
+	requireBalances()
+}
+
+func TestUxDeltaIndexProcessBlockOutOfOrder(t *testing.T) {
+	db, closeDB := prepareDB(t)
+	defer closeDB()
+
+	x := NewUxDeltaIndex()
+	gb := makeGenesisBlock(t)
+
+	err := db.Update("", func(tx *dbutil.Tx) error {
+		require.NoError(t, x.ProcessBlock(tx, &gb, nil))
+
+		err := x.ProcessBlock(tx, &gb, nil)
+		require.EqualError(t, err, "ux delta index height is set but no block has been indexed yet")
+
+		gb.Head.BkSeq = 2
+		err = x.ProcessBlock(tx, &gb, nil)
+		require.EqualError(t, err, "ux delta index processing blocks out of order")
+
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -2,6 +2,7 @@ package blockdb
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 
 	"github.com/SkycoinProject/cx-chains/src/cipher"
@@ -154,3 +155,293 @@ func VerifyDBSkyencoderSafe(tx *dbutil.Tx, quit <-chan struct{}) error {
 
 	return nil
 }
+
+// maxVerifyDivergences is the maximum number of divergence descriptions kept in a VerifyResult
+const maxVerifyDivergences = 100
+
+// VerifyResult is the report produced by Verify
+type VerifyResult struct {
+	// HeadSeq is the head block sequence recorded in the blockchain metadata
+	HeadSeq uint64
+	// HasHead is false if no head block sequence is recorded
+	HasHead bool
+	// Blocks is the number of blocks walked in the main chain
+	Blocks uint64
+	// Unspents is the number of unspent outputs recomputed from the main chain
+	Unspents uint64
+	// Divergences describes the inconsistencies found, up to maxVerifyDivergences
+	Divergences []string
+	// DivergenceCount is the total number of inconsistencies found
+	DivergenceCount int
+}
+
+// OK returns true if no divergence was found
+func (r VerifyResult) OK() bool {
+	return r.DivergenceCount == 0
+}
+
+func (r *VerifyResult) addf(format string, args ...interface{}) {
+	r.DivergenceCount++
+	if len(r.Divergences) < maxVerifyDivergences {
+		r.Divergences = append(r.Divergences, fmt.Sprintf(format, args...))
+	}
+}
+
+// Verify walks the main chain from the genesis block to the head block, recomputes
+// the unspent output pool and compares it to the stored unspent pool, its address index,
+// commitment and metadata. The walker selects the main chain block when a height has several blocks.
+// Any inconsistency is recorded in the returned VerifyResult; an error is only returned
+// if the database could not be read or the verification was interrupted.
+func Verify(db *dbutil.DB, walker Walker, quit <-chan struct{}) (*VerifyResult, error) {
+	var res *VerifyResult
+	if err := db.View("Verify", func(tx *dbutil.Tx) error {
+		var err error
+		res, err = verify(tx, walker, quit)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func verify(tx *dbutil.Tx, walker Walker, quit <-chan struct{}) (*VerifyResult, error) {
+	if quit == nil {
+		quit = make(chan struct{})
+	}
+
+	res := &VerifyResult{}
+
+	headSeq, hasHead, err := chainMeta{}.GetHeadSeq(tx)
+	if err != nil {
+		return nil, err
+	}
+	res.HeadSeq = headSeq
+	res.HasHead = hasHead
+
+	// Find the highest height recorded in the block tree
+	var maxDepth uint64
+	var hasDepth bool
+	if err := dbutil.ForEach(tx, TreeBkt, func(k, _ []byte) error {
+		depth := dbutil.Btoi(k)
+		if !hasDepth || depth > maxDepth {
+			maxDepth = depth
+		}
+		hasDepth = true
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !hasHead && hasDepth:
+		res.addf("head seq is not set but the block tree has blocks up to seq %d", maxDepth)
+		headSeq = maxDepth
+	case hasHead && !hasDepth:
+		res.addf("head seq is %d but the block tree is empty", headSeq)
+	case hasHead && headSeq != maxDepth:
+		res.addf("head seq is %d but the block tree has blocks up to seq %d", headSeq, maxDepth)
+	}
+
+	if !hasDepth {
+		return res, nil
+	}
+
+	bt := &blockTree{}
+	sigs := &blockSigs{}
+	unspents := make(map[cipher.SHA256]coin.UxOut)
+	var xorHash cipher.SHA256
+	var prevHash cipher.SHA256
+
+	for seq := uint64(0); seq <= headSeq; seq++ {
+		select {
+		case <-quit:
+			return nil, ErrVerifyStopped
+		default:
+		}
+
+		b, err := bt.GetBlockInDepth(tx, seq, walker)
+		if err != nil {
+			return nil, err
+		}
+		if b == nil {
+			res.addf("block seq %d is missing, unable to verify the remaining blocks", seq)
+			return res, nil
+		}
+
+		hash := b.HashHeader()
+
+		if b.Seq() != seq {
+			res.addf("block %s is stored at seq %d but has seq %d", hash.Hex(), seq, b.Seq())
+		}
+
+		if seq > 0 && b.Head.PrevHash != prevHash {
+			res.addf("block seq %d prev hash %s does not match the hash %s of block seq %d", seq, b.Head.PrevHash.Hex(), prevHash.Hex(), seq-1)
+		}
+
+		if _, ok, err := sigs.Get(tx, hash); err != nil {
+			return nil, err
+		} else if !ok {
+			res.addf("block seq %d has no signature", seq)
+		}
+
+		if b.Head.UxHash != xorHash {
+			res.addf("block seq %d uxhash %s does not match the recomputed uxhash %s", seq, b.Head.UxHash.Hex(), xorHash.Hex())
+		}
+
+		for _, txn := range b.Body.Transactions {
+			for _, in := range txn.In {
+				ux, ok := unspents[in]
+				if !ok {
+					res.addf("block seq %d transaction %s spends unknown output %s", seq, txn.Hash().Hex(), in.Hex())
+					continue
+				}
+
+				xorHash = xorHash.Xor(ux.SnapshotHash())
+				delete(unspents, in)
+			}
+
+			for _, ux := range coin.CreateUnspents(b.Head, txn) {
+				h := ux.Hash()
+				if _, ok := unspents[h]; ok {
+					res.addf("block seq %d transaction %s creates duplicate output %s", seq, txn.Hash().Hex(), h.Hex())
+					continue
+				}
+
+				xorHash = xorHash.Xor(ux.SnapshotHash())
+				unspents[h] = ux
+			}
+		}
+
+		prevHash = hash
+		res.Blocks++
+	}
+
+	res.Unspents = uint64(len(unspents))
+
+	// Compare the recomputed unspent outputs to the unspent pool
+	addrHashes := make(map[cipher.Address]map[cipher.SHA256]struct{})
+	for h, ux := range unspents {
+		if _, ok := addrHashes[ux.Body.Address]; !ok {
+			addrHashes[ux.Body.Address] = make(map[cipher.SHA256]struct{})
+		}
+		addrHashes[ux.Body.Address][h] = struct{}{}
+	}
+
+	seen := make(map[cipher.SHA256]struct{}, len(unspents))
+	if err := dbutil.ForEach(tx, UnspentPoolBkt, func(k, v []byte) error {
+		select {
+		case <-quit:
+			return ErrVerifyStopped
+		default:
+		}
+
+		h, err := cipher.SHA256FromBytes(k)
+		if err != nil {
+			return err
+		}
+
+		var ux coin.UxOut
+		if err := decodeUxOutExact(v, &ux); err != nil {
+			return err
+		}
+
+		expected, ok := unspents[h]
+		switch {
+		case !ok:
+			res.addf("unspent pool has unexpected output %s", h.Hex())
+		case expected.SnapshotHash() != ux.SnapshotHash():
+			res.addf("unspent pool output %s does not match the recomputed output", h.Hex())
+		}
+
+		seen[h] = struct{}{}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	for h := range unspents {
+		if _, ok := seen[h]; !ok {
+			res.addf("unspent pool is missing output %s", h.Hex())
+		}
+	}
+
+	// Compare the recomputed address index to the unspent pool address index
+	seenAddrs := make(map[cipher.Address]struct{}, len(addrHashes))
+	if err := dbutil.ForEach(tx, UnspentPoolAddrIndexBkt, func(k, v []byte) error {
+		select {
+		case <-quit:
+			return ErrVerifyStopped
+		default:
+		}
+
+		addr, err := cipher.AddressFromBytes(k)
+		if err != nil {
+			return err
+		}
+
+		var hw hashesWrapper
+		if err := decodeHashesWrapperExact(v, &hw); err != nil {
+			return err
+		}
+
+		expected := addrHashes[addr]
+		matches := len(hw.Hashes) == len(expected)
+		for _, h := range hw.Hashes {
+			if _, ok := expected[h]; !ok {
+				matches = false
+				break
+			}
+		}
+
+		if !matches {
+			res.addf("unspent address index for %s does not match the recomputed unspent outputs", addr.String())
+		}
+
+		seenAddrs[addr] = struct{}{}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	for addr := range addrHashes {
+		if _, ok := seenAddrs[addr]; !ok {
+			res.addf("unspent address index is missing address %s", addr.String())
+		}
+	}
+
+	// Compare the unspent metadata
+	um := unspentMeta{}
+	storedXorHash, err := um.getXorHash(tx)
+	if err != nil {
+		return nil, err
+	}
+	if storedXorHash != xorHash {
+		res.addf("unspent pool uxhash %s does not match the recomputed uxhash %s", storedXorHash.Hex(), xorHash.Hex())
+	}
+
+	var uxa coin.UxArray
+	for _, ux := range unspents {
+		uxa = append(uxa, ux)
+	}
+	commitment := computeUnspentCommitment(uxa)
+	storedCommitment, err := chainMeta{}.GetUnspentCommitment(tx)
+	if err != nil {
+		return nil, err
+	}
+	if storedCommitment.Digest() != commitment.Digest() {
+		res.addf("unspent pool commitment %s does not match the recomputed commitment %s", storedCommitment.Digest().Hex(), commitment.Digest().Hex())
+	}
+
+	addrIndexHeight, ok, err := um.getAddrIndexHeight(tx)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		res.addf("unspent address index height is not set")
+	} else if addrIndexHeight != headSeq {
+		res.addf("unspent address index height is %d but head seq is %d", addrIndexHeight, headSeq)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,212 @@
+package blockdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+// makeVerifyChain writes a genesis block and a block spending the genesis output to db
+func makeVerifyChain(t *testing.T, db *dbutil.DB) []coin.SignedBlock {
+	bc, err := NewBlockchain(db, DefaultWalker)
+	require.NoError(t, err)
+
+	gb := makeGenesisBlock(t)
+
+	var uxHash cipher.SHA256
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		if err := bc.AddBlock(tx, &gb); err != nil {
+			return err
+		}
+
+		var err error
+		uxHash, err = bc.UnspentPool().GetUxHash(tx)
+		return err
+	})
+	require.NoError(t, err)
+
+	genUx := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])[0]
+
+	txn := coin.Transaction{}
+	err = txn.PushInput(genUx.Hash())
+	require.NoError(t, err)
+	err = txn.PushOutput(testutil.MakeAddress(), genUx.Body.Coins, genUx.Body.Hours/2, nil)
+	require.NoError(t, err)
+	txn.SignInputs([]cipher.SecKey{genSecret})
+	err = txn.UpdateHeader()
+	require.NoError(t, err)
+
+	b, err := coin.NewBlock(gb.Block, genTime+100, uxHash, coin.Transactions{txn}, feeCalc)
+	require.NoError(t, err)
+
+	sb := coin.SignedBlock{
+		Block: *b,
+		Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+	}
+
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return bc.AddBlock(tx, &sb)
+	})
+	require.NoError(t, err)
+
+	return []coin.SignedBlock{gb, sb}
+}
+
+func TestVerify(t *testing.T) {
+	tt := []struct {
+		name        string
+		corrupt     func(*testing.T, *dbutil.Tx, []coin.SignedBlock)
+		divergences int
+		blocks      uint64
+		unspents    uint64
+	}{
+		{
+			name:     "consistent",
+			corrupt:  func(*testing.T, *dbutil.Tx, []coin.SignedBlock) {},
+			blocks:   2,
+			unspents: 1,
+		},
+		{
+			name: "head seq ahead of block tree",
+			corrupt: func(t *testing.T, tx *dbutil.Tx, _ []coin.SignedBlock) {
+				err := chainMeta{}.SetHeadSeq(tx, 2)
+				require.NoError(t, err)
+			},
+			// the head seq mismatch, the missing block
+			divergences: 2,
+			blocks:      2,
+		},
+		{
+			name: "missing signature",
+			corrupt: func(t *testing.T, tx *dbutil.Tx, blocks []coin.SignedBlock) {
+				h := blocks[1].HashHeader()
+				err := dbutil.Delete(tx, BlockSigsBkt, h[:])
+				require.NoError(t, err)
+			},
+			divergences: 1,
+			blocks:      2,
+			unspents:    1,
+		},
+		{
+			name: "missing unspent",
+			corrupt: func(t *testing.T, tx *dbutil.Tx, blocks []coin.SignedBlock) {
+				ux := coin.CreateUnspents(blocks[1].Head, blocks[1].Body.Transactions[0])[0]
+				h := ux.Hash()
+				err := dbutil.Delete(tx, UnspentPoolBkt, h[:])
+				require.NoError(t, err)
+			},
+			divergences: 1,
+			blocks:      2,
+			unspents:    1,
+		},
+		{
+			name: "unexpected unspent",
+			corrupt: func(t *testing.T, tx *dbutil.Tx, blocks []coin.SignedBlock) {
+				ux := coin.CreateUnspents(blocks[0].Head, blocks[0].Body.Transactions[0])[0]
+				err := pool{}.put(tx, ux.Hash(), ux)
+				require.NoError(t, err)
+			},
+			divergences: 1,
+			blocks:      2,
+			unspents:    1,
+		},
+		{
+			name: "address index mismatch",
+			corrupt: func(t *testing.T, tx *dbutil.Tx, blocks []coin.SignedBlock) {
+				ux := coin.CreateUnspents(blocks[1].Head, blocks[1].Body.Transactions[0])[0]
+				err := poolAddrIndex{}.put(tx, ux.Body.Address, []cipher.SHA256{testutil.RandSHA256(t)})
+				require.NoError(t, err)
+			},
+			divergences: 1,
+			blocks:      2,
+			unspents:    1,
+		},
+		{
+			name: "xorhash mismatch",
+			corrupt: func(t *testing.T, tx *dbutil.Tx, _ []coin.SignedBlock) {
+				um := &unspentMeta{}
+				err := um.setXorHash(tx, testutil.RandSHA256(t))
+				require.NoError(t, err)
+			},
+			divergences: 1,
+			blocks:      2,
+			unspents:    1,
+		},
+		{
+			name: "commitment mismatch",
+			corrupt: func(t *testing.T, tx *dbutil.Tx, _ []coin.SignedBlock) {
+				c := cipher.NewMuHash()
+				c.Insert(testutil.RandBytes(t, 32))
+				err := chainMeta{}.SetUnspentCommitment(tx, c)
+				require.NoError(t, err)
+			},
+			divergences: 1,
+			blocks:      2,
+			unspents:    1,
+		},
+		{
+			name: "address index height mismatch",
+			corrupt: func(t *testing.T, tx *dbutil.Tx, _ []coin.SignedBlock) {
+				um := &unspentMeta{}
+				err := um.setAddrIndexHeight(tx, 0)
+				require.NoError(t, err)
+			},
+			divergences: 1,
+			blocks:      2,
+			unspents:    1,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			db, teardown := prepareDB(t)
+			defer teardown()
+
+			blocks := makeVerifyChain(t, db)
+
+			err := db.Update("", func(tx *dbutil.Tx) error {
+				tc.corrupt(t, tx, blocks)
+				return nil
+			})
+			require.NoError(t, err)
+
+			res, err := Verify(db, DefaultWalker, nil)
+			require.NoError(t, err)
+			require.Equal(t, tc.divergences, res.DivergenceCount, "%v", res.Divergences)
+			require.Len(t, res.Divergences, tc.divergences)
+			require.Equal(t, tc.divergences == 0, res.OK())
+			require.True(t, res.HasHead)
+			require.Equal(t, tc.blocks, res.Blocks)
+			require.Equal(t, tc.unspents, res.Unspents)
+		})
+	}
+}
+
+func TestVerifyEmpty(t *testing.T) {
+	db, teardown := prepareDB(t)
+	defer teardown()
+
+	res, err := Verify(db, DefaultWalker, nil)
+	require.NoError(t, err)
+	require.True(t, res.OK())
+	require.False(t, res.HasHead)
+	require.Equal(t, uint64(0), res.Blocks)
+}
+
+func TestVerifyStopped(t *testing.T) {
+	db, teardown := prepareDB(t)
+	defer teardown()
+
+	makeVerifyChain(t, db)
+
+	quit := make(chan struct{})
+	close(quit)
+
+	_, err := Verify(db, DefaultWalker, quit)
+	require.Equal(t, ErrVerifyStopped, err)
+}
@@ -0,0 +1,171 @@
+package visor
+
+import (
+	"fmt"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/visor/blockdb"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+// DefaultChain is the name of the chain of a node which is not configured with a chain name
+const DefaultChain = "default"
+
+var (
+	// chainKey is the name of the chain which the blockchain metadata belongs to
+	chainKey = []byte("chain")
+	// chainGenesisKey is the hash of the genesis block of the chain, recorded with its name
+	chainGenesisKey = []byte("chain_genesis")
+)
+
+// ErrChainMismatch is returned when the database holds the blockchain of a different chain
+type ErrChainMismatch struct {
+	Chain   string
+	DBChain string
+}
+
+func (e ErrChainMismatch) Error() string {
+	return fmt.Sprintf("database belongs to chain %q, not chain %q", e.DBChain, e.Chain)
+}
+
+// ErrChainUnnamed is returned when a named chain is opened on a database which holds a blockchain
+// but has no chain name. Such a database was created without -chain and belongs to the default chain.
+type ErrChainUnnamed struct {
+	Chain string
+}
+
+func (e ErrChainUnnamed) Error() string {
+	return fmt.Sprintf("database has no chain name and holds the blockchain of chain %q, not chain %q", DefaultChain, e.Chain)
+}
+
+// ErrChainGenesisMismatch is returned when the database holds a blockchain with a different genesis block
+// than the genesis parameters of the chain
+type ErrChainGenesisMismatch struct {
+	Chain     string
+	Genesis   cipher.SHA256
+	DBGenesis cipher.SHA256
+}
+
+func (e ErrChainGenesisMismatch) Error() string {
+	return fmt.Sprintf("database of chain %q has genesis block %s, not %s", e.Chain, e.DBGenesis.Hex(), e.Genesis.Hex())
+}
+
+// Chain returns the name of the chain of the blockchain
+func (bc *Blockchain) Chain() string {
+	if bc.cfg.Chain == "" {
+		return DefaultChain
+	}
+	return bc.cfg.Chain
+}
+
+// dbChain returns the name of the chain recorded in the database
+func dbChain(tx *dbutil.Tx) (string, bool, error) {
+	v, err := dbutil.GetBucketValue(tx, blockdb.BlockchainMetaBkt, chainKey)
+	if err != nil {
+		switch err.(type) {
+		case dbutil.ErrBucketNotExist:
+			return "", false, nil
+		default:
+			return "", false, err
+		}
+	} else if v == nil {
+		return "", false, nil
+	}
+
+	return string(v), true, nil
+}
+
+// dbChainGenesis returns the hash of the genesis block recorded with the name of the chain in the database
+func dbChainGenesis(tx *dbutil.Tx) (cipher.SHA256, bool, error) {
+	v, err := dbutil.GetBucketValue(tx, blockdb.BlockchainMetaBkt, chainGenesisKey)
+	if err != nil {
+		switch err.(type) {
+		case dbutil.ErrBucketNotExist:
+			return cipher.SHA256{}, false, nil
+		default:
+			return cipher.SHA256{}, false, err
+		}
+	} else if v == nil {
+		return cipher.SHA256{}, false, nil
+	}
+
+	h, err := cipher.SHA256FromBytes(v)
+	if err != nil {
+		return cipher.SHA256{}, false, err
+	}
+
+	return h, true, nil
+}
+
+// VerifyChain checks that the database holds the blockchain of the chain of the blockchain.
+// A database without a chain name, created before chains were named, is accepted by the default chain,
+// and by a named chain only if it is empty. If the genesis hash of the chain is configured, the genesis block
+// of the database and the genesis hash recorded with the chain name must match it.
+func (bc *Blockchain) VerifyChain(tx *dbutil.Tx) error {
+	name, ok, err := dbChain(tx)
+	if err != nil {
+		return err
+	}
+
+	if ok && name != bc.Chain() {
+		return ErrChainMismatch{
+			Chain:   bc.Chain(),
+			DBChain: name,
+		}
+	}
+
+	gb, err := bc.GetGenesisBlock(tx)
+	if err != nil {
+		return err
+	}
+
+	if !ok && gb != nil && bc.Chain() != DefaultChain {
+		return ErrChainUnnamed{
+			Chain: bc.Chain(),
+		}
+	}
+
+	if bc.cfg.GenesisHash == (cipher.SHA256{}) {
+		return nil
+	}
+
+	genesis, ok, err := dbChainGenesis(tx)
+	if err != nil {
+		return err
+	}
+
+	if !ok && gb != nil {
+		genesis = gb.HashHeader()
+	} else if !ok {
+		return nil
+	}
+
+	if genesis != bc.cfg.GenesisHash {
+		return ErrChainGenesisMismatch{
+			Chain:     bc.Chain(),
+			Genesis:   bc.cfg.GenesisHash,
+			DBGenesis: genesis,
+		}
+	}
+
+	return nil
+}
+
+// InitChain records the chain of the blockchain and its genesis hash in the database, so that the database
+// can not be opened by a node of another chain. Returns the errors of VerifyChain if the database
+// holds the blockchain of another chain.
+func (bc *Blockchain) InitChain(tx *dbutil.Tx) error {
+	if err := bc.VerifyChain(tx); err != nil {
+		return err
+	}
+
+	if err := dbutil.PutBucketValue(tx, blockdb.BlockchainMetaBkt, chainKey, []byte(bc.Chain())); err != nil {
+		return err
+	}
+
+	if bc.cfg.GenesisHash == (cipher.SHA256{}) {
+		return nil
+	}
+
+	return dbutil.PutBucketValue(tx, blockdb.BlockchainMetaBkt, chainGenesisKey, bc.cfg.GenesisHash[:])
+}
@@ -0,0 +1,188 @@
+package visor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/historydb"
+)
+
+func TestBlockchainChain(t *testing.T) {
+	db, closeDB := prepareDB(t)
+	defer closeDB()
+
+	newBlockchain := func(chain string) *Blockchain {
+		bc, err := NewBlockchain(db, BlockchainConfig{
+			Pubkey: genPublic,
+			Chain:  chain,
+		})
+		require.NoError(t, err)
+		return bc
+	}
+
+	defaultBc := newBlockchain("")
+	require.Equal(t, DefaultChain, defaultBc.Chain())
+
+	foo := newBlockchain("foo")
+	require.Equal(t, "foo", foo.Chain())
+
+	// A database without a chain name is accepted by any chain
+	err := db.View("", defaultBc.VerifyChain)
+	require.NoError(t, err)
+	err = db.View("", foo.VerifyChain)
+	require.NoError(t, err)
+
+	// The first chain to open the database for writing is recorded
+	err = db.Update("", foo.InitChain)
+	require.NoError(t, err)
+
+	err = db.Update("", foo.InitChain)
+	require.NoError(t, err)
+	err = db.View("", foo.VerifyChain)
+	require.NoError(t, err)
+
+	// Other chains can not open the database
+	mismatch := ErrChainMismatch{
+		Chain:   DefaultChain,
+		DBChain: "foo",
+	}
+
+	err = db.View("", defaultBc.VerifyChain)
+	require.Equal(t, mismatch, err)
+	require.EqualError(t, err, `database belongs to chain "foo", not chain "default"`)
+
+	err = db.Update("", defaultBc.InitChain)
+	require.Equal(t, mismatch, err)
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		name, ok, err := dbChain(tx)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "foo", name)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestBlockchainChainGenesis(t *testing.T) {
+	db, closeDB := prepareDB(t)
+	defer closeDB()
+
+	newBlockchain := func(chain string, genesisHash cipher.SHA256) *Blockchain {
+		bc, err := NewBlockchain(db, BlockchainConfig{
+			Pubkey:      genPublic,
+			Chain:       chain,
+			GenesisHash: genesisHash,
+		})
+		require.NoError(t, err)
+		return bc
+	}
+
+	bc := newBlockchain("", cipher.SHA256{})
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolPolicy{})
+	require.NoError(t, err)
+
+	cfg := NewConfig()
+	cfg.BlockchainPubkey = genPublic
+	cfg.GenesisAddress = genAddress
+
+	v := &Visor{
+		Config:      cfg,
+		unconfirmed: unconfirmed,
+		blockchain:  bc,
+		db:          db,
+		history:     historydb.New(),
+		events:      &eventPublisher{},
+	}
+	gb := addGenesisBlockToVisor(t, v)
+	genesisHash := gb.HashHeader()
+	otherHash := testutil.RandSHA256(t)
+
+	// A database without a chain name which holds a blockchain belongs to the default chain
+	err = db.View("", bc.VerifyChain)
+	require.NoError(t, err)
+
+	bar := newBlockchain("bar", genesisHash)
+	err = db.View("", bar.VerifyChain)
+	require.Equal(t, ErrChainUnnamed{
+		Chain: "bar",
+	}, err)
+	err = db.Update("", bar.InitChain)
+	require.Equal(t, ErrChainUnnamed{
+		Chain: "bar",
+	}, err)
+
+	// The genesis block must match the genesis hash of the chain
+	other := newBlockchain("", otherHash)
+	err = db.Update("", other.InitChain)
+	require.Equal(t, ErrChainGenesisMismatch{
+		Chain:     DefaultChain,
+		Genesis:   otherHash,
+		DBGenesis: genesisHash,
+	}, err)
+
+	// The genesis hash is recorded with the chain name
+	defaultBc := newBlockchain("", genesisHash)
+	err = db.Update("", defaultBc.InitChain)
+	require.NoError(t, err)
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		h, ok, err := dbChainGenesis(tx)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, genesisHash, h)
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = db.View("", other.VerifyChain)
+	require.Equal(t, ErrChainGenesisMismatch{
+		Chain:     DefaultChain,
+		Genesis:   otherHash,
+		DBGenesis: genesisHash,
+	}, err)
+
+	// The genesis is not checked if the genesis hash of the chain is not configured
+	err = db.View("", bc.VerifyChain)
+	require.NoError(t, err)
+}
+
+func TestBlockchainChainGenesisRecorded(t *testing.T) {
+	db, closeDB := prepareDB(t)
+	defer closeDB()
+
+	genesisHash := testutil.RandSHA256(t)
+	otherHash := testutil.RandSHA256(t)
+
+	foo, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey:      genPublic,
+		Chain:       "foo",
+		GenesisHash: genesisHash,
+	})
+	require.NoError(t, err)
+
+	// An empty database without a chain name can be claimed by a named chain
+	err = db.Update("", foo.InitChain)
+	require.NoError(t, err)
+
+	// The chain name can not be reused with other genesis parameters before the genesis block is created
+	otherFoo, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey:      genPublic,
+		Chain:       "foo",
+		GenesisHash: otherHash,
+	})
+	require.NoError(t, err)
+
+	err = db.View("", otherFoo.VerifyChain)
+	require.Equal(t, ErrChainGenesisMismatch{
+		Chain:     "foo",
+		Genesis:   otherHash,
+		DBGenesis: genesisHash,
+	}, err)
+	require.EqualError(t, err, fmt.Sprintf("database of chain \"foo\" has genesis block %s, not %s", genesisHash.Hex(), otherHash.Hex()))
+}
@@ -0,0 +1,172 @@
+package visor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/params"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+// Checkpoint is the hash of a known-good block
+type Checkpoint struct {
+	Seq  uint64
+	Hash cipher.SHA256
+}
+
+// ErrCheckpointMismatch is returned when a block at the seq of a checkpoint does not have the hash of the checkpoint
+type ErrCheckpointMismatch struct {
+	Seq      uint64
+	Expected cipher.SHA256
+	Hash     cipher.SHA256
+}
+
+func (e ErrCheckpointMismatch) Error() string {
+	return fmt.Sprintf("block %d hash %s does not match checkpoint hash %s", e.Seq, e.Hash.Hex(), e.Expected.Hex())
+}
+
+// ParseCheckpoints parses checkpoints of the form <seq>:<hash>, separated by comma
+func ParseCheckpoints(s string) ([]Checkpoint, error) {
+	var checkpoints []Checkpoint
+	for _, c := range strings.Split(s, ",") {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+
+		pts := strings.Split(c, ":")
+		if len(pts) != 2 {
+			return nil, fmt.Errorf("Invalid checkpoint %q, must be of the form <seq>:<hash>", c)
+		}
+
+		seq, err := strconv.ParseUint(pts[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid checkpoint %q seq: %v", c, err)
+		}
+
+		hash, err := cipher.SHA256FromHex(pts[1])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid checkpoint %q hash: %v", c, err)
+		}
+
+		checkpoints = append(checkpoints, Checkpoint{
+			Seq:  seq,
+			Hash: hash,
+		})
+	}
+
+	return checkpoints, nil
+}
+
+// NewCheckpointsFromParams converts the compiled-in checkpoints of the params package
+func NewCheckpointsFromParams(cps []params.Checkpoint) ([]Checkpoint, error) {
+	checkpoints := make([]Checkpoint, len(cps))
+	for i, c := range cps {
+		hash, err := cipher.SHA256FromHex(c.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid checkpoint %d hash: %v", c.Seq, err)
+		}
+
+		checkpoints[i] = Checkpoint{
+			Seq:  c.Seq,
+			Hash: hash,
+		}
+	}
+
+	return checkpoints, nil
+}
+
+// checkpoints is a table of known-good block hashes by seq. The zero value has no checkpoints.
+type checkpoints struct {
+	hashes    map[uint64]cipher.SHA256
+	latestSeq uint64
+}
+
+// newCheckpoints creates checkpoints. A seq can be checkpointed more than once, but only with the same hash.
+func newCheckpoints(cps []Checkpoint) (checkpoints, error) {
+	c := checkpoints{
+		hashes: make(map[uint64]cipher.SHA256, len(cps)),
+	}
+
+	for _, cp := range cps {
+		if h, ok := c.hashes[cp.Seq]; ok && h != cp.Hash {
+			return checkpoints{}, fmt.Errorf("Conflicting checkpoints for block %d", cp.Seq)
+		}
+
+		c.hashes[cp.Seq] = cp.Hash
+		if cp.Seq > c.latestSeq {
+			c.latestSeq = cp.Seq
+		}
+	}
+
+	return c, nil
+}
+
+// latest returns the checkpoint with the highest seq
+func (c checkpoints) latest() (Checkpoint, bool) {
+	if len(c.hashes) == 0 {
+		return Checkpoint{}, false
+	}
+
+	return Checkpoint{
+		Seq:  c.latestSeq,
+		Hash: c.hashes[c.latestSeq],
+	}, true
+}
+
+// verify checks that the block has the hash of the checkpoint at its seq, if there is one
+func (c checkpoints) verify(b coin.Block) error {
+	expected, ok := c.hashes[b.Head.BkSeq]
+	if !ok {
+		return nil
+	}
+
+	if hash := b.HashHeader(); hash != expected {
+		return ErrCheckpointMismatch{
+			Seq:      b.Head.BkSeq,
+			Expected: expected,
+			Hash:     hash,
+		}
+	}
+
+	return nil
+}
+
+// covers returns true if the block seq is not above the latest checkpoint
+func (c checkpoints) covers(seq uint64) bool {
+	return len(c.hashes) != 0 && seq <= c.latestSeq
+}
+
+// VerifyCheckpoints checks that the blocks of the blockchain at the seqs of the checkpoints have the checkpoint hashes.
+// Returns ErrCheckpointMismatch if the blockchain in the database is a fork below a checkpoint.
+func (bc *Blockchain) VerifyCheckpoints(tx *dbutil.Tx) error {
+	headSeq, ok, err := bc.HeadSeq(tx)
+	if err != nil {
+		return err
+	} else if !ok {
+		return nil
+	}
+
+	for seq := range bc.checkpoints.hashes {
+		if seq > headSeq {
+			continue
+		}
+
+		b, err := bc.GetSignedBlockBySeq(tx, seq)
+		if err != nil {
+			return err
+		}
+		if b == nil {
+			return NewErrBlockNotExist(seq)
+		}
+
+		if err := bc.checkpoints.verify(b.Block); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
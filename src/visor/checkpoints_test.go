@@ -0,0 +1,237 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/params"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/blockdb"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+func TestParseCheckpoints(t *testing.T) {
+	h1 := testutil.RandSHA256(t)
+	h2 := testutil.RandSHA256(t)
+
+	cases := []struct {
+		name        string
+		s           string
+		checkpoints []Checkpoint
+		err         string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "one",
+			s:    "10:" + h1.Hex(),
+			checkpoints: []Checkpoint{
+				{Seq: 10, Hash: h1},
+			},
+		},
+		{
+			name: "multiple with spaces",
+			s:    "10:" + h1.Hex() + ", 20:" + h2.Hex() + ",",
+			checkpoints: []Checkpoint{
+				{Seq: 10, Hash: h1},
+				{Seq: 20, Hash: h2},
+			},
+		},
+		{
+			name: "missing hash",
+			s:    "10",
+			err:  `Invalid checkpoint "10", must be of the form <seq>:<hash>`,
+		},
+		{
+			name: "invalid seq",
+			s:    "x:" + h1.Hex(),
+			err:  `Invalid checkpoint "x:` + h1.Hex() + `" seq: strconv.ParseUint: parsing "x": invalid syntax`,
+		},
+		{
+			name: "invalid hash",
+			s:    "10:abc",
+			err:  `Invalid checkpoint "10:abc" hash: encoding/hex: odd length hex string`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			checkpoints, err := ParseCheckpoints(tc.s)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.checkpoints, checkpoints)
+		})
+	}
+}
+
+func TestNewCheckpointsFromParams(t *testing.T) {
+	h := testutil.RandSHA256(t)
+
+	checkpoints, err := NewCheckpointsFromParams([]params.Checkpoint{
+		{Seq: 5, Hash: h.Hex()},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []Checkpoint{{Seq: 5, Hash: h}}, checkpoints)
+
+	_, err = NewCheckpointsFromParams([]params.Checkpoint{
+		{Seq: 5, Hash: "abc"},
+	})
+	require.EqualError(t, err, "Invalid checkpoint 5 hash: encoding/hex: odd length hex string")
+
+	// The compiled-in checkpoints must be valid
+	_, err = NewCheckpointsFromParams(params.MainNetCheckpoints)
+	require.NoError(t, err)
+}
+
+func TestCheckpoints(t *testing.T) {
+	// The zero value has no checkpoints
+	var c checkpoints
+	_, ok := c.latest()
+	require.False(t, ok)
+	require.False(t, c.covers(0))
+
+	blocks := makeBlocks(t, 3)
+
+	c, err := newCheckpoints([]Checkpoint{
+		{Seq: 2, Hash: blocks[2].HashHeader()},
+		{Seq: 1, Hash: blocks[1].HashHeader()},
+		{Seq: 1, Hash: blocks[1].HashHeader()},
+	})
+	require.NoError(t, err)
+
+	latest, ok := c.latest()
+	require.True(t, ok)
+	require.Equal(t, Checkpoint{Seq: 2, Hash: blocks[2].HashHeader()}, latest)
+
+	require.True(t, c.covers(0))
+	require.True(t, c.covers(2))
+	require.False(t, c.covers(3))
+
+	for _, b := range blocks {
+		require.NoError(t, c.verify(b.Block))
+	}
+
+	// A block at a checkpointed seq with a different hash is rejected
+	fork := blocks[1].Block
+	fork.Head.Time++
+	err = c.verify(fork)
+	require.Equal(t, ErrCheckpointMismatch{
+		Seq:      1,
+		Expected: blocks[1].HashHeader(),
+		Hash:     fork.HashHeader(),
+	}, err)
+
+	_, err = newCheckpoints([]Checkpoint{
+		{Seq: 1, Hash: blocks[1].HashHeader()},
+		{Seq: 1, Hash: blocks[2].HashHeader()},
+	})
+	require.EqualError(t, err, "Conflicting checkpoints for block 1")
+}
+
+func TestProcessBlockCheckpoints(t *testing.T) {
+	db, closeDB := prepareDB(t)
+	defer closeDB()
+
+	err := CreateBuckets(db)
+	require.NoError(t, err)
+
+	store, err := blockdb.NewBlockchain(db, DefaultWalker)
+	require.NoError(t, err)
+
+	bc := &Blockchain{
+		db:    db,
+		store: store,
+	}
+
+	gb := addGenesisBlockToBlockchain(t, bc)
+
+	// The transaction is signed by a key which does not own the genesis output
+	_, otherSecret := cipher.GenerateKeyPair()
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	txn := makeSpendTxn(t, uxs, []cipher.SecKey{otherSecret}, testutil.MakeAddress(), 10e6)
+	uxHash := getUxHash(t, db, bc)
+	b, err := coin.NewBlock(gb.Block, genTime+100, uxHash, coin.Transactions{txn}, feeCalc)
+	require.NoError(t, err)
+	sb := coin.SignedBlock{
+		Block: *b,
+		Sig:   cipher.MustSignHash(b.HashHeader(), genSecret),
+	}
+
+	processBlock := func() error {
+		return db.Update("", func(tx *dbutil.Tx) error {
+			_, err := bc.processBlock(tx, sb)
+			return err
+		})
+	}
+
+	// Without checkpoints, the input signatures are verified
+	err = processBlock()
+	require.IsType(t, ErrTxnViolatesHardConstraint{}, err)
+
+	// A checkpoint with a different hash rejects the block
+	bc.checkpoints, err = newCheckpoints([]Checkpoint{
+		{Seq: 1, Hash: testutil.RandSHA256(t)},
+	})
+	require.NoError(t, err)
+	err = processBlock()
+	require.IsType(t, ErrCheckpointMismatch{}, err)
+
+	// The input signatures of a block covered by a checkpoint are not verified
+	bc.checkpoints, err = newCheckpoints([]Checkpoint{
+		{Seq: 1, Hash: b.HashHeader()},
+	})
+	require.NoError(t, err)
+	require.NoError(t, processBlock())
+
+	// Unless configured to
+	bc.cfg.VerifyCheckpointedSignatures = true
+	err = processBlock()
+	require.IsType(t, ErrTxnViolatesHardConstraint{}, err)
+}
+
+func TestVerifyCheckpoints(t *testing.T) {
+	db, closeDB := prepareDB(t)
+	defer closeDB()
+
+	err := CreateBuckets(db)
+	require.NoError(t, err)
+
+	store, err := blockdb.NewBlockchain(db, DefaultWalker)
+	require.NoError(t, err)
+
+	bc := &Blockchain{
+		db:    db,
+		store: store,
+	}
+
+	verify := func() error {
+		return db.View("", bc.VerifyCheckpoints)
+	}
+
+	// An empty blockchain has nothing to verify
+	bc.checkpoints, err = newCheckpoints([]Checkpoint{
+		{Seq: 0, Hash: testutil.RandSHA256(t)},
+	})
+	require.NoError(t, err)
+	require.NoError(t, verify())
+
+	gb := addGenesisBlockToBlockchain(t, bc)
+
+	require.IsType(t, ErrCheckpointMismatch{}, verify())
+
+	// Checkpoints above the head are not verified yet
+	bc.checkpoints, err = newCheckpoints([]Checkpoint{
+		{Seq: 0, Hash: gb.HashHeader()},
+		{Seq: 10, Hash: testutil.RandSHA256(t)},
+	})
+	require.NoError(t, err)
+	require.NoError(t, verify())
+}
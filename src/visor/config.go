@@ -21,10 +21,14 @@ type Config struct {
 
 	// Transaction verification parameters used for unconfirmed transactions
 	UnconfirmedVerifyTxn params.VerifyTxn
+	// Size and fee limits of the unconfirmed transaction pool
+	UnconfirmedPolicy UnconfirmedPoolPolicy
 	// Transaction verification parameters used when creating a block
 	CreateBlockVerifyTxn params.VerifyTxn
 	// Maximum size of a block, in bytes for creating blocks
 	MaxBlockTransactionsSize uint32
+	// Transaction limits, ordering and address lists applied when creating blocks
+	BlockPolicy BlockPolicy
 
 	// Coin distribution parameters (necessary for txn verification)
 	Distribution params.Distribution
@@ -44,8 +48,24 @@ type Config struct {
 	GenesisCoinVolume uint64
 	// enable arbitrating mode
 	Arbitrating bool
+	// Name of the chain, defaults to DefaultChain
+	Chain string
+	// Hash of the genesis block computed from the genesis parameters, recorded in the database with the chain name
+	GenesisHash cipher.SHA256
+
+	// Known-good block hashes. Forks of the blockchain below the latest checkpoint are rejected
+	Checkpoints []Checkpoint
+	// Verify the transaction input signatures of the blocks up to the latest checkpoint
+	VerifyCheckpointedSignatures bool
+	// Number of decoded blocks cached in memory, 0 to disable the cache
+	BlockCacheSize int
+	// Single-node development chain with funded addresses and blocks generated on demand
+	Devnet DevnetConfig
 }
 
+// DefaultBlockCacheSize is the default number of decoded blocks cached in memory
+const DefaultBlockCacheSize = 1000
+
 // NewConfig creates Config
 func NewConfig() Config {
 	c := Config{
@@ -57,11 +77,14 @@ func NewConfig() Config {
 		UnconfirmedVerifyTxn:     params.UserVerifyTxn,
 		CreateBlockVerifyTxn:     params.UserVerifyTxn,
 		MaxBlockTransactionsSize: params.UserVerifyTxn.MaxTransactionSize,
+		BlockPolicy:              NewBlockPolicy(),
 
 		GenesisAddress:    cipher.Address{},
 		GenesisSignature:  cipher.Sig{},
 		GenesisTimestamp:  0,
 		GenesisCoinVolume: 0, //100e12, 100e6 * 10e6
+
+		BlockCacheSize: DefaultBlockCacheSize,
 	}
 
 	return c
@@ -111,9 +134,17 @@ func (c Config) Verify() error {
 		return errors.New("MaxBlockTransactionsSize must be >= CreateBlockVerifyTxn.MaxTransactionSize")
 	}
 
+	if err := c.BlockPolicy.Validate(); err != nil {
+		return err
+	}
+
 	if err := c.Distribution.Validate(); err != nil {
 		return err
 	}
 
+	if err := c.Devnet.verify(c); err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -198,6 +198,11 @@ func resetCorruptDB(db *dbutil.DB) (*dbutil.DB, error) {
 		return nil, fmt.Errorf("Failed to close db: %v", err)
 	}
 
+	// An in-memory database has no file to move aside
+	if dbPath == "" {
+		return dbutil.NewMemoryDB(), nil
+	}
+
 	corruptDBPath, err := moveCorruptDB(dbPath)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to copy corrupted db: %v", err)
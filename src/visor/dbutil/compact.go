@@ -0,0 +1,317 @@
+package dbutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+const (
+	// compactTxMaxSize is the size of the keys and values copied in a single transaction when compacting
+	compactTxMaxSize = 64 * 1024 * 1024
+	// compactOpenTimeout is the timeout of opening the bolt files when compacting
+	compactOpenTimeout = 5 * time.Second
+)
+
+var (
+	// ErrNotBoltStorage is returned if a bolt operation is requested on a database not stored in a bolt file
+	ErrNotBoltStorage = errors.New("Database is not stored in a bolt file")
+	// ErrCompactReadOnly is returned when compacting a database opened read-only
+	ErrCompactReadOnly = errors.New("Can't compact a database opened read-only")
+)
+
+// DBStats is a report of the size of a bolt database and of the free space in its file
+type DBStats struct {
+	// FileSize is the size of the database file
+	FileSize int64
+	PageSize int
+	// FreePages are the pages of the file which can be reused by a write
+	FreePages int
+	// PendingPages are the pages freed by a write, which can be reused once the read transactions open before it end
+	PendingPages int
+	// FreeBytes is the size of the free and pending pages
+	FreeBytes int
+	// FreelistBytes is the size of the list of free pages stored in the file
+	FreelistBytes int
+	Buckets       []BucketStats
+}
+
+// BucketStats is a report of the size of a top level bucket, including its nested buckets
+type BucketStats struct {
+	Name string
+	Keys int
+	// Depth is the number of levels of the B+tree of the bucket
+	Depth int
+	// AllocBytes is the size of the pages allocated to the bucket
+	AllocBytes int
+	// InuseBytes is the size of the keys, values and page headers stored in the pages of the bucket
+	InuseBytes int
+}
+
+// CompactResult is the size of a database before and after it was compacted
+type CompactResult struct {
+	Before DBStats
+	After  DBStats
+	// Duration is the time taken to compact the database
+	Duration time.Duration
+}
+
+// Stats returns a report of the size of the database file, of its free pages and of its buckets
+func (db *DB) Stats() (DBStats, error) {
+	db.shutdownLock.RLock()
+	defer db.shutdownLock.RUnlock()
+
+	s, ok := db.Storage.(boltStorage)
+	if !ok {
+		return DBStats{}, ErrNotBoltStorage
+	}
+
+	return boltStats(s.db)
+}
+
+// Compact copies the buckets of the database into a fresh bolt file and swaps it in place of the database file.
+// bolt never shrinks its file: the pages freed by deleted unconfirmed transactions, rolled back blocks
+// and rewritten values are only reused by later writes. The copy only contains the live pages.
+// The database is locked while it is compacted, so reads and writes wait for it to finish.
+// The copy is written to a temporary file next to the database file and renamed over it once complete,
+// so the database file is either the original or the complete copy if the process is interrupted.
+func (db *DB) Compact() (CompactResult, error) {
+	db.shutdownLock.Lock()
+	defer db.shutdownLock.Unlock()
+
+	s, ok := db.Storage.(boltStorage)
+	if !ok {
+		return CompactResult{}, ErrNotBoltStorage
+	}
+	if s.db.IsReadOnly() {
+		return CompactResult{}, ErrCompactReadOnly
+	}
+
+	t0 := time.Now()
+
+	before, err := boltStats(s.db)
+	if err != nil {
+		return CompactResult{}, err
+	}
+
+	path := s.db.Path()
+	tmpPath := path + ".compact"
+
+	// A copy left by an interrupted compaction is incomplete
+	if err := os.Remove(tmpPath); err != nil && !os.IsNotExist(err) {
+		return CompactResult{}, err
+	}
+
+	if err := copyBoltFile(tmpPath, s.db); err != nil {
+		if rErr := os.Remove(tmpPath); rErr != nil && !os.IsNotExist(rErr) {
+			logger.WithError(rErr).Errorf("Failed to remove %s", tmpPath)
+		}
+		return CompactResult{}, err
+	}
+
+	if err := s.db.Close(); err != nil {
+		return CompactResult{}, fmt.Errorf("Close database failed: %v", err)
+	}
+
+	renameErr := os.Rename(tmpPath, path)
+	if renameErr == nil {
+		renameErr = syncDir(filepath.Dir(path))
+	}
+
+	// The database is reopened even if the rename failed, so that the node can keep using the original file
+	bdb, err := bolt.Open(path, 0600, &bolt.Options{
+		Timeout: compactOpenTimeout,
+	})
+	if err != nil {
+		logger.Critical().WithError(err).Errorf("Reopening %s after compacting it failed", path)
+		return CompactResult{}, fmt.Errorf("Reopen database failed: %v", err)
+	}
+	db.Storage = boltStorage{bdb}
+
+	if renameErr != nil {
+		return CompactResult{}, fmt.Errorf("Replace database file failed: %v", renameErr)
+	}
+
+	after, err := boltStats(bdb)
+	if err != nil {
+		return CompactResult{}, err
+	}
+
+	return CompactResult{
+		Before:   before,
+		After:    after,
+		Duration: time.Since(t0),
+	}, nil
+}
+
+// boltStats returns the DBStats of a bolt database
+func boltStats(db *bolt.DB) (DBStats, error) {
+	fi, err := os.Stat(db.Path())
+	if err != nil {
+		return DBStats{}, err
+	}
+
+	st := db.Stats()
+	stats := DBStats{
+		FileSize:      fi.Size(),
+		PageSize:      db.Info().PageSize,
+		FreePages:     st.FreePageN,
+		PendingPages:  st.PendingPageN,
+		FreeBytes:     st.FreeAlloc,
+		FreelistBytes: st.FreelistInuse,
+	}
+
+	if err := db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			bs := b.Stats()
+			stats.Buckets = append(stats.Buckets, BucketStats{
+				Name:       string(name),
+				Keys:       bs.KeyN,
+				Depth:      bs.Depth,
+				AllocBytes: bs.BranchAlloc + bs.LeafAlloc,
+				InuseBytes: bs.BranchInuse + bs.LeafInuse + bs.InlineBucketInuse,
+			})
+			return nil
+		})
+	}); err != nil {
+		return DBStats{}, err
+	}
+
+	return stats, nil
+}
+
+// copyBoltFile copies the buckets of src into a new bolt file at path
+func copyBoltFile(path string, src *bolt.DB) error {
+	dst, err := bolt.Open(path, 0600, &bolt.Options{
+		Timeout: compactOpenTimeout,
+	})
+	if err != nil {
+		return err
+	}
+
+	c := &boltCopier{
+		dst:       dst,
+		txMaxSize: compactTxMaxSize,
+	}
+
+	if err := src.View(c.copy); err != nil {
+		if c.tx != nil {
+			if rErr := c.tx.Rollback(); rErr != nil {
+				logger.WithError(rErr).Error("copyBoltFile: tx.Rollback failed")
+			}
+		}
+		if cErr := dst.Close(); cErr != nil {
+			logger.WithError(cErr).Error("copyBoltFile: dst.Close failed")
+		}
+		return err
+	}
+
+	return dst.Close()
+}
+
+// boltCopier copies the buckets of a bolt transaction into another bolt database,
+// committing the copy every txMaxSize bytes of keys and values to limit the memory used by a write transaction
+type boltCopier struct {
+	dst       *bolt.DB
+	txMaxSize int64
+	tx        *bolt.Tx
+	size      int64
+}
+
+func (c *boltCopier) copy(src *bolt.Tx) error {
+	var err error
+	c.tx, err = c.dst.Begin(true)
+	if err != nil {
+		return err
+	}
+
+	if err := src.ForEach(func(name []byte, b *bolt.Bucket) error {
+		return c.copyBucket(b, [][]byte{name})
+	}); err != nil {
+		return err
+	}
+
+	err = c.tx.Commit()
+	c.tx = nil
+	return err
+}
+
+// copyBucket copies the keys and the nested buckets of src into the bucket at path
+func (c *boltCopier) copyBucket(src *bolt.Bucket, path [][]byte) error {
+	dst, err := c.bucket(path)
+	if err != nil {
+		return err
+	}
+	if err := dst.SetSequence(src.Sequence()); err != nil {
+		return err
+	}
+
+	return src.ForEach(func(k, v []byte) error {
+		// A nil value is a nested bucket
+		if v == nil {
+			nested := make([][]byte, len(path)+1)
+			copy(nested, path)
+			nested[len(path)] = k
+			return c.copyBucket(src.Bucket(k), nested)
+		}
+
+		if c.size+int64(len(k)+len(v)) > c.txMaxSize {
+			if err := c.tx.Commit(); err != nil {
+				c.tx = nil
+				return err
+			}
+
+			c.tx, err = c.dst.Begin(true)
+			if err != nil {
+				return err
+			}
+			c.size = 0
+		}
+
+		dst, err := c.bucket(path)
+		if err != nil {
+			return err
+		}
+		if err := dst.Put(k, v); err != nil {
+			return err
+		}
+		c.size += int64(len(k) + len(v))
+		return nil
+	})
+}
+
+// bucket returns the bucket at path in the current write transaction, creating it if necessary
+func (c *boltCopier) bucket(path [][]byte) (*bolt.Bucket, error) {
+	b, err := c.tx.CreateBucketIfNotExists(path[0])
+	if err != nil {
+		return nil, NewErrCreateBucketFailed(path[0], err)
+	}
+
+	for _, name := range path[1:] {
+		b, err = b.CreateBucketIfNotExists(name)
+		if err != nil {
+			return nil, NewErrCreateBucketFailed(name, err)
+		}
+	}
+
+	return b, nil
+}
+
+// syncDir flushes a directory to disk, so that a file renamed in it is persisted
+func syncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close() // nolint: errcheck
+		return err
+	}
+
+	return f.Close()
+}
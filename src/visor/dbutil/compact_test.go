@@ -0,0 +1,127 @@
+package dbutil
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/require"
+)
+
+func prepareBoltDB(t *testing.T) (*DB, func()) {
+	dir, err := ioutil.TempDir("", "compactdb")
+	require.NoError(t, err)
+
+	bdb, err := bolt.Open(filepath.Join(dir, "data.db"), 0600, nil)
+	require.NoError(t, err)
+
+	db := WrapDB(bdb)
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestDBCompact(t *testing.T) {
+	db, shutdown := prepareBoltDB(t)
+	defer shutdown()
+
+	nestedBkt := []byte("nested")
+	value := make([]byte, 512)
+
+	err := db.Update("", func(tx *Tx) error {
+		if err := CreateBuckets(tx, [][]byte{testBkt}); err != nil {
+			return err
+		}
+
+		b := tx.Bucket(testBkt)
+		for i := 0; i < 2000; i++ {
+			if err := b.Put([]byte(fmt.Sprintf("%05d", i)), value); err != nil {
+				return err
+			}
+		}
+
+		nb, err := tx.Bucket(testBkt).(boltBucket).CreateBucket(nestedBkt)
+		if err != nil {
+			return err
+		}
+		if _, err := nb.NextSequence(); err != nil {
+			return err
+		}
+		return nb.Put([]byte("a"), []byte("1"))
+	})
+	require.NoError(t, err)
+
+	// Deleting most of the keys frees their pages without shrinking the file
+	err = db.Update("", func(tx *Tx) error {
+		for i := 10; i < 2000; i++ {
+			if err := Delete(tx, testBkt, []byte(fmt.Sprintf("%05d", i))); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	stats, err := db.Stats()
+	require.NoError(t, err)
+	require.NotZero(t, stats.FreePages+stats.PendingPages)
+	require.Len(t, stats.Buckets, 1)
+	require.Equal(t, string(testBkt), stats.Buckets[0].Name)
+	require.Equal(t, 12, stats.Buckets[0].Keys)
+
+	// Compaction is serialized with the read transactions
+	tx, err := db.Begin("")
+	require.NoError(t, err)
+	var res CompactResult
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		res, err = db.Compact()
+	}()
+	require.NoError(t, tx.Rollback())
+	<-done
+
+	require.NoError(t, err)
+	require.Equal(t, stats.FileSize, res.Before.FileSize)
+	require.True(t, res.After.FileSize < res.Before.FileSize)
+	require.Equal(t, 0, res.After.PendingPages)
+	require.Equal(t, stats.Buckets[0].Keys, res.After.Buckets[0].Keys)
+
+	// The compacted database has the same data and is writable
+	err = db.View("", func(tx *Tx) error {
+		n, err := Len(tx, testBkt)
+		require.NoError(t, err)
+		require.Equal(t, uint64(stats.Buckets[0].Keys), n)
+
+		v, err := GetBucketValue(tx, testBkt, []byte("00009"))
+		require.NoError(t, err)
+		require.Equal(t, value, v)
+
+		nb := tx.Bucket(testBkt).(boltBucket).Bucket.Bucket(nestedBkt)
+		require.NotNil(t, nb)
+		require.Equal(t, uint64(1), nb.Sequence())
+		require.Equal(t, []byte("1"), nb.Get([]byte("a")))
+		return nil
+	})
+	require.NoError(t, err)
+
+	putValues(t, db, "b", "2")
+
+	fi, err := os.Stat(db.Path() + ".compact")
+	require.True(t, os.IsNotExist(err), "%v", fi)
+}
+
+func TestDBCompactUnsupported(t *testing.T) {
+	db := NewMemoryDB()
+	defer db.Close()
+
+	_, err := db.Compact()
+	require.Equal(t, ErrNotBoltStorage, err)
+
+	_, err = db.Stats()
+	require.Equal(t, ErrNotBoltStorage, err)
+}
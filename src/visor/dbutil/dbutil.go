@@ -4,6 +4,7 @@ Package dbutil provides boltdb utility methods
 package dbutil
 
 import (
+	"bytes"
 	"encoding/binary"
 	"encoding/json"
 	"errors"
@@ -28,19 +29,19 @@ var (
 	txDurationReportingThreshold = time.Millisecond * 100
 )
 
-// Tx wraps a Tx
+// Tx wraps a StorageTx
 type Tx struct {
-	*bolt.Tx
+	StorageTx
 }
 
 // String is implemented to prevent a panic when mocking methods with *Tx arguments.
 // The mock library forces arguments to be printed with %s which causes Tx to panic.
 // See https://github.com/stretchr/testify/pull/596
 func (tx *Tx) String() string {
-	return fmt.Sprintf("%v", tx.Tx)
+	return fmt.Sprintf("%v", tx.StorageTx)
 }
 
-// DB wraps a bolt.DB to add logging
+// DB wraps a Storage to add logging
 type DB struct {
 	ViewLog                    bool
 	ViewTrace                  bool
@@ -49,7 +50,7 @@ type DB struct {
 	DurationLog                bool
 	DurationReportingThreshold time.Duration
 
-	*bolt.DB
+	Storage
 
 	// shutdownLock is added to prevent closing the database while a View transaction is in progress
 	// bolt.DB will block for Update transactions but not for View transactions, and if
@@ -62,6 +63,11 @@ type DB struct {
 
 // WrapDB returns WrapDB
 func WrapDB(db *bolt.DB) *DB {
+	return WrapStorage(boltStorage{db})
+}
+
+// WrapStorage returns a DB wrapping a Storage
+func WrapStorage(s Storage) *DB {
 	return &DB{
 		ViewLog:                    txViewLog,
 		UpdateLog:                  txUpdateLog,
@@ -69,11 +75,11 @@ func WrapDB(db *bolt.DB) *DB {
 		UpdateTrace:                txUpdateTrace,
 		DurationLog:                txDurationLog,
 		DurationReportingThreshold: txDurationReportingThreshold,
-		DB:                         db,
+		Storage:                    s,
 	}
 }
 
-// View wraps *bolt.DB.View to add logging
+// View wraps Storage.View to add logging
 func (db *DB) View(name string, f func(*Tx) error) error {
 	db.shutdownLock.RLock()
 	defer db.shutdownLock.RUnlock()
@@ -88,7 +94,7 @@ func (db *DB) View(name string, f func(*Tx) error) error {
 
 	t0 := time.Now()
 
-	err := db.DB.View(func(tx *bolt.Tx) error {
+	err := db.Storage.View(func(tx StorageTx) error {
 		return f(&Tx{tx})
 	})
 
@@ -101,7 +107,7 @@ func (db *DB) View(name string, f func(*Tx) error) error {
 	return err
 }
 
-// Update wraps *bolt.DB.Update to add logging
+// Update wraps Storage.Update to add logging
 func (db *DB) Update(name string, f func(*Tx) error) error {
 	db.shutdownLock.RLock()
 	defer db.shutdownLock.RUnlock()
@@ -116,7 +122,7 @@ func (db *DB) Update(name string, f func(*Tx) error) error {
 
 	t0 := time.Now()
 
-	err := db.DB.Update(func(tx *bolt.Tx) error {
+	err := db.Storage.Update(func(tx StorageTx) error {
 		return f(&Tx{tx})
 	})
 
@@ -129,12 +135,71 @@ func (db *DB) Update(name string, f func(*Tx) error) error {
 	return err
 }
 
-// Close closes the underlying *bolt.DB
+// ReadTx is a read-only transaction started with DB.Begin
+type ReadTx struct {
+	Tx
+
+	db      *DB
+	name    string
+	t0      time.Time
+	storage StorageReadTx
+	once    sync.Once
+	err     error
+}
+
+// Begin starts a read-only transaction, which sees the data committed before it started until it is rolled back.
+// The database can't be closed until the transaction is rolled back, so it should be short-lived.
+// The transaction must not be used by multiple goroutines at the same time.
+func (db *DB) Begin(name string) (*ReadTx, error) {
+	db.shutdownLock.RLock()
+
+	if db.ViewLog {
+		logger.Debug("db.Begin [%s] starting", name)
+	}
+	if db.ViewTrace {
+		debug.PrintStack()
+	}
+
+	tx, err := db.Storage.Begin()
+	if err != nil {
+		db.shutdownLock.RUnlock()
+		return nil, err
+	}
+
+	return &ReadTx{
+		Tx:      Tx{tx},
+		db:      db,
+		name:    name,
+		t0:      time.Now(),
+		storage: tx,
+	}, nil
+}
+
+// Rollback ends the transaction. It is safe to call more than once
+func (tx *ReadTx) Rollback() error {
+	tx.once.Do(func() {
+		defer tx.db.shutdownLock.RUnlock()
+
+		tx.err = tx.storage.Rollback()
+
+		delta := time.Since(tx.t0)
+		if tx.db.DurationLog && delta > tx.db.DurationReportingThreshold {
+			logger.Debugf("db.Begin [%s] elapsed %s", tx.name, delta)
+		}
+		if tx.db.ViewLog {
+			logger.Debug("db.Begin [%s] done", tx.name)
+		}
+	})
+
+	return tx.err
+}
+
+// Close closes the underlying Storage
 func (db *DB) Close() error {
 	db.shutdownLock.Lock()
 	defer db.shutdownLock.Unlock()
 
-	return db.DB.Close()
+	return db.Storage.Close()
 }
 
 // ErrCreateBucketFailed is returned if creating a bolt.DB bucket fails
@@ -297,6 +362,23 @@ func ForEach(tx *Tx, bktName []byte, f func(k, v []byte) error) error {
 	return bkt.ForEach(f)
 }
 
+// ForEachPrefix calls f for each key in the bucket that starts with prefix, in key order
+func ForEachPrefix(tx *Tx, bktName, prefix []byte, f func(k, v []byte) error) error {
+	bkt := tx.Bucket(bktName)
+	if bkt == nil {
+		return NewErrBucketNotExist(bktName)
+	}
+
+	c := bkt.Cursor()
+	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		if err := f(k, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Delete deletes from a bucket
 func Delete(tx *Tx, bktName, key []byte) error {
 	bkt := tx.Bucket(bktName)
@@ -314,13 +396,13 @@ func Len(tx *Tx, bktName []byte) (uint64, error) {
 		return 0, NewErrBucketNotExist(bktName)
 	}
 
-	bstats := bkt.Stats()
+	n := bkt.KeyN()
 
-	if bstats.KeyN < 0 {
+	if n < 0 {
 		return 0, errors.New("Negative length queried from db stats")
 	}
 
-	return uint64(bstats.KeyN), nil
+	return uint64(n), nil
 }
 
 // IsEmpty returns true if the bucket is empty
@@ -0,0 +1,530 @@
+package dbutil
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/boltdb/bolt"
+)
+
+// NewMemoryDB returns a DB kept in memory, for tests and ephemeral chains.
+// Its data is lost when the process exits. The backup of the database with Tx.WriteTo is a bolt database file.
+func NewMemoryDB() *DB {
+	return WrapStorage(newMemoryStorage())
+}
+
+// memoryStorage is a Storage kept in memory.
+// Committed buckets are never modified: a read-write transaction copies a bucket on its first write,
+// and replaces the committed buckets when it commits. Read-only transactions read the buckets committed
+// when they started, without locking, like bolt transactions.
+type memoryStorage struct {
+	// writeLock serializes the read-write transactions
+	writeLock sync.Mutex
+	// lock protects buckets and closed
+	lock    sync.RWMutex
+	buckets map[string]*memoryBucketData
+	closed  bool
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{
+		buckets: make(map[string]*memoryBucketData),
+	}
+}
+
+func (s *memoryStorage) committed() (map[string]*memoryBucketData, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if s.closed {
+		return nil, bolt.ErrDatabaseNotOpen
+	}
+
+	return s.buckets, nil
+}
+
+func (s *memoryStorage) View(f func(StorageTx) error) error {
+	buckets, err := s.committed()
+	if err != nil {
+		return err
+	}
+
+	return f(&memoryTx{
+		buckets: buckets,
+	})
+}
+
+func (s *memoryStorage) Update(f func(StorageTx) error) error {
+	commitHandlers, err := s.update(f)
+	if err != nil {
+		return err
+	}
+
+	for _, h := range commitHandlers {
+		h()
+	}
+
+	return nil
+}
+
+// update runs f in a read-write transaction and returns its commit handlers if it is committed
+func (s *memoryStorage) update(f func(StorageTx) error) ([]func(), error) {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	committed, err := s.committed()
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &memoryTx{
+		writable: true,
+		buckets:  make(map[string]*memoryBucketData, len(committed)),
+		owned:    make(map[string]bool),
+	}
+	for name, b := range committed {
+		tx.buckets[name] = b
+	}
+
+	if err := f(tx); err != nil {
+		return nil, err
+	}
+
+	// Sort the keys of the modified buckets now, so that the committed buckets are only read
+	for name := range tx.owned {
+		if b, ok := tx.buckets[name]; ok {
+			b.sortedKeys()
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return nil, bolt.ErrDatabaseNotOpen
+	}
+	s.buckets = tx.buckets
+
+	return tx.commitHandlers, nil
+}
+
+func (s *memoryStorage) Begin() (StorageReadTx, error) {
+	buckets, err := s.committed()
+	if err != nil {
+		return nil, err
+	}
+
+	return &memoryTx{
+		buckets: buckets,
+	}, nil
+}
+
+func (s *memoryStorage) Close() error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.closed = true
+	s.buckets = nil
+	return nil
+}
+
+func (s *memoryStorage) IsReadOnly() bool {
+	return false
+}
+
+func (s *memoryStorage) Path() string {
+	return ""
+}
+
+func (s *memoryStorage) Sync() error {
+	return nil
+}
+
+// memoryBucketData holds the key-value pairs of a bucket
+type memoryBucketData struct {
+	values map[string][]byte
+	// keys are the sorted keys of values, if sorted is true
+	keys   []string
+	sorted bool
+	seq    uint64
+}
+
+func newMemoryBucketData() *memoryBucketData {
+	return &memoryBucketData{
+		values: make(map[string][]byte),
+		sorted: true,
+	}
+}
+
+func (d *memoryBucketData) clone() *memoryBucketData {
+	values := make(map[string][]byte, len(d.values))
+	for k, v := range d.values {
+		values[k] = v
+	}
+
+	// keys is replaced, not modified, when the bucket is modified, so it can be shared
+	return &memoryBucketData{
+		values: values,
+		keys:   d.keys,
+		sorted: d.sorted,
+		seq:    d.seq,
+	}
+}
+
+func (d *memoryBucketData) sortedKeys() []string {
+	if !d.sorted {
+		keys := make([]string, 0, len(d.values))
+		for k := range d.values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		d.keys = keys
+		d.sorted = true
+	}
+
+	return d.keys
+}
+
+type memoryTx struct {
+	writable bool
+	buckets  map[string]*memoryBucketData
+	// owned are the buckets copied or created by this transaction, which it can modify
+	owned          map[string]bool
+	commitHandlers []func()
+}
+
+func (tx *memoryTx) Bucket(name []byte) Bucket {
+	if _, ok := tx.buckets[string(name)]; !ok {
+		return nil
+	}
+
+	return &memoryBucket{
+		tx:   tx,
+		name: string(name),
+	}
+}
+
+func (tx *memoryTx) CreateBucket(name []byte) (Bucket, error) {
+	if !tx.writable {
+		return nil, bolt.ErrTxNotWritable
+	}
+	if len(name) == 0 {
+		return nil, bolt.ErrBucketNameRequired
+	}
+	if _, ok := tx.buckets[string(name)]; ok {
+		return nil, bolt.ErrBucketExists
+	}
+
+	tx.buckets[string(name)] = newMemoryBucketData()
+	tx.owned[string(name)] = true
+
+	return tx.Bucket(name), nil
+}
+
+func (tx *memoryTx) CreateBucketIfNotExists(name []byte) (Bucket, error) {
+	if b := tx.Bucket(name); b != nil {
+		if !tx.writable {
+			return nil, bolt.ErrTxNotWritable
+		}
+		return b, nil
+	}
+
+	return tx.CreateBucket(name)
+}
+
+func (tx *memoryTx) DeleteBucket(name []byte) error {
+	if !tx.writable {
+		return bolt.ErrTxNotWritable
+	}
+	if _, ok := tx.buckets[string(name)]; !ok {
+		return bolt.ErrBucketNotFound
+	}
+
+	delete(tx.buckets, string(name))
+	delete(tx.owned, string(name))
+	return nil
+}
+
+// Rollback ends a transaction started with Begin. The buckets it reads are never modified, so there is nothing to release
+func (tx *memoryTx) Rollback() error {
+	return nil
+}
+
+func (tx *memoryTx) OnCommit(f func()) {
+	tx.commitHandlers = append(tx.commitHandlers, f)
+}
+
+// Size returns the total size of the keys and values
+func (tx *memoryTx) Size() int64 {
+	var n int64
+	for _, b := range tx.buckets {
+		for k, v := range b.values {
+			n += int64(len(k) + len(v))
+		}
+	}
+	return n
+}
+
+// WriteTo writes the buckets to a temporary bolt database file, and copies the file to w
+func (tx *memoryTx) WriteTo(w io.Writer) (int64, error) {
+	f, err := ioutil.TempFile("", "memorydb")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(f.Name())
+
+	if err := f.Close(); err != nil {
+		return 0, err
+	}
+
+	db, err := bolt.Open(f.Name(), 0600, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	names := make([]string, 0, len(tx.buckets))
+	for name := range tx.buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if err := db.Update(func(btx *bolt.Tx) error {
+		for _, name := range names {
+			data := tx.buckets[name]
+
+			b, err := btx.CreateBucket([]byte(name))
+			if err != nil {
+				return err
+			}
+
+			if err := b.SetSequence(data.seq); err != nil {
+				return err
+			}
+
+			// Keys are inserted in order, so that the bolt pages are filled
+			b.FillPercent = 1
+			for _, k := range data.sortedKeys() {
+				if err := b.Put([]byte(k), data.values[k]); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		db.Close()
+		return 0, err
+	}
+
+	if err := db.Close(); err != nil {
+		return 0, err
+	}
+
+	f, err = os.Open(f.Name())
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	return io.Copy(w, f)
+}
+
+// memoryBucket is a bucket of a memoryTx.
+// The bucket data is looked up in the transaction on each call, since it is copied on the first write.
+type memoryBucket struct {
+	tx   *memoryTx
+	name string
+}
+
+func (b *memoryBucket) data() *memoryBucketData {
+	return b.tx.buckets[b.name]
+}
+
+// writableData returns the data of the bucket owned by the transaction, copying it on the first write
+func (b *memoryBucket) writableData() (*memoryBucketData, error) {
+	if !b.tx.writable {
+		return nil, bolt.ErrTxNotWritable
+	}
+
+	d := b.data()
+	if d == nil {
+		return nil, bolt.ErrBucketNotFound
+	}
+
+	if !b.tx.owned[b.name] {
+		d = d.clone()
+		b.tx.buckets[b.name] = d
+		b.tx.owned[b.name] = true
+	}
+
+	return d, nil
+}
+
+func (b *memoryBucket) Get(key []byte) []byte {
+	d := b.data()
+	if d == nil {
+		return nil
+	}
+	return d.values[string(key)]
+}
+
+func (b *memoryBucket) Put(key, value []byte) error {
+	if len(key) == 0 {
+		return bolt.ErrKeyRequired
+	}
+	if len(key) > bolt.MaxKeySize {
+		return bolt.ErrKeyTooLarge
+	}
+	if int64(len(value)) > bolt.MaxValueSize {
+		return bolt.ErrValueTooLarge
+	}
+
+	d, err := b.writableData()
+	if err != nil {
+		return err
+	}
+
+	k := string(key)
+	if _, ok := d.values[k]; !ok {
+		d.sorted = false
+	}
+	d.values[k] = append([]byte{}, value...)
+
+	return nil
+}
+
+func (b *memoryBucket) Delete(key []byte) error {
+	d, err := b.writableData()
+	if err != nil {
+		return err
+	}
+
+	k := string(key)
+	if _, ok := d.values[k]; ok {
+		delete(d.values, k)
+		d.sorted = false
+	}
+
+	return nil
+}
+
+func (b *memoryBucket) Cursor() Cursor {
+	return &memoryCursor{
+		bucket: b,
+	}
+}
+
+func (b *memoryBucket) ForEach(f func(k, v []byte) error) error {
+	d := b.data()
+	if d == nil {
+		return bolt.ErrBucketNotFound
+	}
+
+	for _, k := range d.sortedKeys() {
+		v, ok := d.values[k]
+		if !ok {
+			continue
+		}
+
+		if err := f([]byte(k), v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *memoryBucket) NextSequence() (uint64, error) {
+	d, err := b.writableData()
+	if err != nil {
+		return 0, err
+	}
+
+	d.seq++
+	return d.seq, nil
+}
+
+func (b *memoryBucket) KeyN() int {
+	d := b.data()
+	if d == nil {
+		return 0
+	}
+	return len(d.values)
+}
+
+// memoryCursor is a Cursor of a memoryBucket. It remembers its current key instead of an index,
+// so that it stays valid if the bucket is modified.
+type memoryCursor struct {
+	bucket *memoryBucket
+	key    string
+	// valid is false before the first move and after moving past either end
+	valid bool
+}
+
+// at moves the cursor to the key at index i of keys
+func (c *memoryCursor) at(d *memoryBucketData, keys []string, i int) ([]byte, []byte) {
+	if i < 0 || i >= len(keys) {
+		c.valid = false
+		return nil, nil
+	}
+
+	c.key = keys[i]
+	c.valid = true
+	return []byte(c.key), d.values[c.key]
+}
+
+func (c *memoryCursor) keys() (*memoryBucketData, []string) {
+	d := c.bucket.data()
+	if d == nil {
+		return nil, nil
+	}
+	return d, d.sortedKeys()
+}
+
+func (c *memoryCursor) First() ([]byte, []byte) {
+	d, keys := c.keys()
+	return c.at(d, keys, 0)
+}
+
+func (c *memoryCursor) Last() ([]byte, []byte) {
+	d, keys := c.keys()
+	return c.at(d, keys, len(keys)-1)
+}
+
+func (c *memoryCursor) Next() ([]byte, []byte) {
+	if !c.valid {
+		return nil, nil
+	}
+
+	d, keys := c.keys()
+	i := sort.Search(len(keys), func(i int) bool {
+		return keys[i] > c.key
+	})
+	return c.at(d, keys, i)
+}
+
+func (c *memoryCursor) Prev() ([]byte, []byte) {
+	if !c.valid {
+		return nil, nil
+	}
+
+	d, keys := c.keys()
+	i := sort.Search(len(keys), func(i int) bool {
+		return keys[i] >= c.key
+	})
+	return c.at(d, keys, i-1)
+}
+
+func (c *memoryCursor) Seek(seek []byte) ([]byte, []byte) {
+	d, keys := c.keys()
+	i := sort.Search(len(keys), func(i int) bool {
+		return bytes.Compare([]byte(keys[i]), seek) >= 0
+	})
+	return c.at(d, keys, i)
+}
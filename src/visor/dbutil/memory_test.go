@@ -0,0 +1,319 @@
+package dbutil
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/require"
+)
+
+var testBkt = []byte("test")
+
+func prepareMemoryDB(t *testing.T) *DB {
+	db := NewMemoryDB()
+
+	err := db.Update("", func(tx *Tx) error {
+		return CreateBuckets(tx, [][]byte{testBkt})
+	})
+	require.NoError(t, err)
+
+	return db
+}
+
+func putValues(t *testing.T, db *DB, kvs ...string) {
+	err := db.Update("", func(tx *Tx) error {
+		for i := 0; i < len(kvs); i += 2 {
+			if err := PutBucketValue(tx, testBkt, []byte(kvs[i]), []byte(kvs[i+1])); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestMemoryDBUpdate(t *testing.T) {
+	db := prepareMemoryDB(t)
+	defer db.Close()
+
+	putValues(t, db, "b", "2", "a", "1", "c", "3")
+
+	// A failed transaction is rolled back
+	errFailed := errors.New("failed")
+	err := db.Update("", func(tx *Tx) error {
+		if err := PutBucketValue(tx, testBkt, []byte("d"), []byte("4")); err != nil {
+			return err
+		}
+		if err := Delete(tx, testBkt, []byte("a")); err != nil {
+			return err
+		}
+
+		// The transaction sees its own writes
+		n, err := Len(tx, testBkt)
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), n)
+
+		return errFailed
+	})
+	require.Equal(t, errFailed, err)
+
+	err = db.View("", func(tx *Tx) error {
+		var keys []string
+		err := ForEach(tx, testBkt, func(k, v []byte) error {
+			keys = append(keys, string(k)+string(v))
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"a1", "b2", "c3"}, keys)
+
+		// The values can not be written in a read-only transaction
+		err = PutBucketValue(tx, testBkt, []byte("d"), []byte("4"))
+		require.Equal(t, bolt.ErrTxNotWritable, err)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Keys are required
+	err = db.Update("", func(tx *Tx) error {
+		return PutBucketValue(tx, testBkt, nil, []byte("4"))
+	})
+	require.Equal(t, bolt.ErrKeyRequired, err)
+
+	// The stored values are copies
+	err = db.Update("", func(tx *Tx) error {
+		k := []byte("e")
+		v := []byte("5")
+		if err := PutBucketValue(tx, testBkt, k, v); err != nil {
+			return err
+		}
+		k[0] = 'f'
+		v[0] = '6'
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = db.View("", func(tx *Tx) error {
+		v, err := GetBucketValue(tx, testBkt, []byte("e"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("5"), v)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestMemoryDBViewIsolation(t *testing.T) {
+	db := prepareMemoryDB(t)
+	defer db.Close()
+
+	putValues(t, db, "a", "1")
+
+	// A read-only transaction sees the data committed when it started
+	err := db.View("", func(tx *Tx) error {
+		putValues(t, db, "a", "2", "b", "3")
+
+		v, err := GetBucketValue(tx, testBkt, []byte("a"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("1"), v)
+
+		ok, err := BucketHasKey(tx, testBkt, []byte("b"))
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = db.View("", func(tx *Tx) error {
+		v, err := GetBucketValue(tx, testBkt, []byte("a"))
+		require.NoError(t, err)
+		require.Equal(t, []byte("2"), v)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestMemoryDBBegin(t *testing.T) {
+	db := prepareMemoryDB(t)
+	defer db.Close()
+
+	putValues(t, db, "a", "1")
+
+	tx, err := db.Begin("")
+	require.NoError(t, err)
+
+	putValues(t, db, "a", "2")
+
+	// The transaction sees the data committed when it started until it is rolled back
+	v, err := GetBucketValue(&tx.Tx, testBkt, []byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("1"), v)
+
+	_, err = tx.CreateBucket([]byte("foo"))
+	require.Equal(t, bolt.ErrTxNotWritable, err)
+
+	require.NoError(t, tx.Rollback())
+	require.NoError(t, tx.Rollback())
+
+	// The database can be closed after the transaction is rolled back
+	require.NoError(t, db.Close())
+
+	_, err = db.Begin("")
+	require.Equal(t, bolt.ErrDatabaseNotOpen, err)
+}
+
+func TestMemoryDBCursor(t *testing.T) {
+	db := prepareMemoryDB(t)
+	defer db.Close()
+
+	putValues(t, db, "b", "2", "d", "4", "a", "1", "e", "5")
+
+	err := db.Update("", func(tx *Tx) error {
+		c := tx.Bucket(testBkt).Cursor()
+
+		k, v := c.First()
+		require.Equal(t, []byte("a"), k)
+		require.Equal(t, []byte("1"), v)
+
+		k, _ = c.Next()
+		require.Equal(t, []byte("b"), k)
+
+		k, _ = c.Seek([]byte("c"))
+		require.Equal(t, []byte("d"), k)
+
+		// The cursor keeps its position when the bucket is modified
+		require.NoError(t, tx.Bucket(testBkt).Delete([]byte("d")))
+		require.NoError(t, tx.Bucket(testBkt).Put([]byte("c"), []byte("3")))
+
+		k, _ = c.Prev()
+		require.Equal(t, []byte("c"), k)
+		k, _ = c.Next()
+		require.Equal(t, []byte("e"), k)
+		k, _ = c.Next()
+		require.Nil(t, k)
+
+		k, _ = c.Seek([]byte("f"))
+		require.Nil(t, k)
+
+		k, v = c.Last()
+		require.Equal(t, []byte("e"), k)
+		require.Equal(t, []byte("5"), v)
+
+		var keys []string
+		err := ForEachPrefix(tx, testBkt, []byte("c"), func(k, v []byte) error {
+			keys = append(keys, string(k))
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"c"}, keys)
+
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestMemoryDBBuckets(t *testing.T) {
+	db := prepareMemoryDB(t)
+	defer db.Close()
+
+	putValues(t, db, "a", "1")
+
+	var commits int
+	err := db.Update("", func(tx *Tx) error {
+		tx.OnCommit(func() {
+			commits++
+		})
+
+		_, err := tx.CreateBucket(testBkt)
+		require.Equal(t, bolt.ErrBucketExists, err)
+
+		require.True(t, Exists(tx, testBkt))
+		require.False(t, Exists(tx, []byte("other")))
+
+		seq, err := NextSequence(tx, testBkt)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), seq)
+
+		return Reset(tx, testBkt)
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, commits)
+
+	err = db.View("", func(tx *Tx) error {
+		empty, err := IsEmpty(tx, testBkt)
+		require.NoError(t, err)
+		require.True(t, empty)
+
+		require.Equal(t, bolt.ErrTxNotWritable, tx.DeleteBucket(testBkt))
+
+		_, err = GetBucketValue(tx, []byte("other"), []byte("a"))
+		require.Equal(t, NewErrBucketNotExist([]byte("other")), err)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, db.Close())
+	err = db.View("", func(tx *Tx) error {
+		return nil
+	})
+	require.Equal(t, bolt.ErrDatabaseNotOpen, err)
+}
+
+func TestMemoryDBWriteTo(t *testing.T) {
+	db := prepareMemoryDB(t)
+	defer db.Close()
+
+	putValues(t, db, "b", "2", "a", "1")
+	err := db.Update("", func(tx *Tx) error {
+		_, err := NextSequence(tx, testBkt)
+		return err
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = db.View("", func(tx *Tx) error {
+		require.Equal(t, int64(4), tx.Size())
+		_, err := tx.WriteTo(&buf)
+		return err
+	})
+	require.NoError(t, err)
+
+	// The backup is a bolt database
+	f, err := ioutil.TempFile("", "memorydb")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.Write(buf.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	bdb, err := bolt.Open(f.Name(), 0600, nil)
+	require.NoError(t, err)
+	fdb := WrapDB(bdb)
+	defer fdb.Close()
+
+	err = fdb.Update("", func(tx *Tx) error {
+		var keys []string
+		err := ForEach(tx, testBkt, func(k, v []byte) error {
+			keys = append(keys, string(k)+string(v))
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"a1", "b2"}, keys)
+
+		n, err := Len(tx, testBkt)
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), n)
+
+		seq, err := NextSequence(tx, testBkt)
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), seq)
+
+		return nil
+	})
+	require.NoError(t, err)
+}
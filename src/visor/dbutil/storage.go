@@ -0,0 +1,152 @@
+package dbutil
+
+import (
+	"io"
+
+	"github.com/boltdb/bolt"
+)
+
+// Storage is the transactional key-value store of buckets wrapped by DB.
+// It is implemented by bolt.DB files and by an in-memory store.
+type Storage interface {
+	// View runs f in a read-only transaction. Read-only transactions see the data committed before they started
+	View(f func(StorageTx) error) error
+	// Update runs f in a read-write transaction, which is committed if f returns nil and rolled back otherwise.
+	// Read-write transactions are serialized
+	Update(f func(StorageTx) error) error
+	// Begin starts a read-only transaction, which sees the data committed before it started until it is rolled back
+	Begin() (StorageReadTx, error)
+	Close() error
+	IsReadOnly() bool
+	// Path returns the path of the database file, or an empty string if the storage has no file
+	Path() string
+	Sync() error
+}
+
+// StorageTx is a transaction of a Storage
+type StorageTx interface {
+	// Bucket returns the bucket with a name, or nil if it does not exist
+	Bucket(name []byte) Bucket
+	CreateBucket(name []byte) (Bucket, error)
+	CreateBucketIfNotExists(name []byte) (Bucket, error)
+	DeleteBucket(name []byte) error
+	// OnCommit registers f to be called after the transaction commits
+	OnCommit(f func())
+	// Size returns the size of the database, in bytes
+	Size() int64
+	// WriteTo writes the database to w in the bolt file format
+	WriteTo(w io.Writer) (int64, error)
+}
+
+// StorageReadTx is a read-only transaction started with Storage.Begin
+type StorageReadTx interface {
+	StorageTx
+	// Rollback ends the transaction
+	Rollback() error
+}
+
+// Bucket is a collection of key-value pairs ordered by key.
+// The keys and values returned by a Bucket are only valid during the transaction and must not be modified.
+type Bucket interface {
+	Get(key []byte) []byte
+	Put(key, value []byte) error
+	Delete(key []byte) error
+	Cursor() Cursor
+	// ForEach calls f with each key-value pair of the bucket in key order. The bucket must not be modified by f
+	ForEach(f func(k, v []byte) error) error
+	NextSequence() (uint64, error)
+	// KeyN returns the number of keys in the bucket
+	KeyN() int
+}
+
+// Cursor iterates the key-value pairs of a Bucket in key order.
+// All the methods return a nil key when there is no such pair.
+type Cursor interface {
+	First() (key, value []byte)
+	Last() (key, value []byte)
+	Next() (key, value []byte)
+	Prev() (key, value []byte)
+	// Seek moves the cursor to the first key greater than or equal to seek
+	Seek(seek []byte) (key, value []byte)
+}
+
+// boltStorage is a Storage in a bolt.DB file
+type boltStorage struct {
+	db *bolt.DB
+}
+
+func (s boltStorage) View(f func(StorageTx) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return f(boltTx{tx})
+	})
+}
+
+func (s boltStorage) Update(f func(StorageTx) error) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return f(boltTx{tx})
+	})
+}
+
+func (s boltStorage) Begin() (StorageReadTx, error) {
+	tx, err := s.db.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	return boltTx{tx}, nil
+}
+
+func (s boltStorage) Close() error {
+	return s.db.Close()
+}
+
+func (s boltStorage) IsReadOnly() bool {
+	return s.db.IsReadOnly()
+}
+
+func (s boltStorage) Path() string {
+	return s.db.Path()
+}
+
+func (s boltStorage) Sync() error {
+	return s.db.Sync()
+}
+
+type boltTx struct {
+	*bolt.Tx
+}
+
+func (tx boltTx) Bucket(name []byte) Bucket {
+	b := tx.Tx.Bucket(name)
+	if b == nil {
+		return nil
+	}
+	return boltBucket{b}
+}
+
+func (tx boltTx) CreateBucket(name []byte) (Bucket, error) {
+	b, err := tx.Tx.CreateBucket(name)
+	if err != nil {
+		return nil, err
+	}
+	return boltBucket{b}, nil
+}
+
+func (tx boltTx) CreateBucketIfNotExists(name []byte) (Bucket, error) {
+	b, err := tx.Tx.CreateBucketIfNotExists(name)
+	if err != nil {
+		return nil, err
+	}
+	return boltBucket{b}, nil
+}
+
+type boltBucket struct {
+	*bolt.Bucket
+}
+
+func (b boltBucket) Cursor() Cursor {
+	return b.Bucket.Cursor()
+}
+
+func (b boltBucket) KeyN() int {
+	return b.Stats().KeyN
+}
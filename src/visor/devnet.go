@@ -0,0 +1,276 @@
+package visor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/util/fee"
+	"github.com/SkycoinProject/cx-chains/src/util/mathutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+const (
+	// DefaultDevnetSeed is the default seed of the keys of a devnet
+	DefaultDevnetSeed = "devnet"
+	// DefaultDevnetAddresses is the default number of addresses funded by a devnet
+	DefaultDevnetAddresses = 10
+	// DefaultDevnetCoins is the default number of droplets sent to each address funded by a devnet
+	DefaultDevnetCoins = 1e6 * 1e6
+	// MaxDevnetGenerateBlocks is the maximum number of blocks generated at once by GenerateDevnetBlocks
+	MaxDevnetGenerateBlocks = 1000
+)
+
+var (
+	// ErrDevnetDisabled is returned if a devnet method is called on a node which is not running a devnet
+	ErrDevnetDisabled = errors.New("Devnet is disabled")
+	// ErrDevnetFaucetEmpty is returned if the genesis address has no outputs with coin hours to spend
+	ErrDevnetFaucetEmpty = errors.New("Devnet genesis address has no coin hours to spend")
+)
+
+// DevnetConfig configures a single-node development chain.
+// The genesis address of a devnet is the address of the blockchain key, so the node can spend the genesis coins
+// to fund the seeded addresses, and to create a transaction for each block generated while the unconfirmed pool is empty.
+type DevnetConfig struct {
+	Enabled bool
+	// Addresses are funded with Coins each in the first block after the genesis block
+	Addresses []cipher.Address
+	// Coins is the number of droplets sent to each of Addresses
+	Coins uint64
+}
+
+// DevnetKeys returns the key pair of the blockchain and the genesis address of a devnet, generated from seed.
+// It is the first key of a deterministic wallet created with seed.
+func DevnetKeys(seed string) (cipher.PubKey, cipher.SecKey) {
+	sk := cipher.MustGenerateDeterministicKeyPairs([]byte(seed), 1)[0]
+	return cipher.MustPubKeyFromSecKey(sk), sk
+}
+
+// DevnetAddresses returns the n addresses funded by a devnet, generated from seed.
+// They are the addresses following the genesis address in a deterministic wallet created with seed.
+func DevnetAddresses(seed string, n int) []cipher.Address {
+	if n == 0 {
+		return nil
+	}
+
+	keys := cipher.MustGenerateDeterministicKeyPairs([]byte(seed), n+1)[1:]
+	addrs := make([]cipher.Address, n)
+	for i, k := range keys {
+		addrs[i] = cipher.MustAddressFromSecKey(k)
+	}
+	return addrs
+}
+
+func (c DevnetConfig) verify(cfg Config) error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if !cfg.IsBlockPublisher {
+		return errors.New("Devnet must run as a block publisher")
+	}
+
+	if cfg.GenesisAddress != cipher.AddressFromPubKey(cfg.BlockchainPubkey) {
+		return errors.New("Devnet genesis address must be the address of the blockchain pubkey")
+	}
+
+	total, err := mathutil.MultUint64(c.Coins, uint64(len(c.Addresses)))
+	if err != nil || total >= cfg.GenesisCoinVolume {
+		return errors.New("Devnet funded addresses must receive less coins than the genesis coin volume")
+	}
+
+	return nil
+}
+
+// nextBlockTime returns the time of a block created after the head block
+func nextBlockTime(head *coin.SignedBlock) uint64 {
+	when := uint64(time.Now().UTC().Unix())
+	if when <= head.Time() {
+		when = head.Time() + 1
+	}
+	return when
+}
+
+// devnetBurnFactor returns the burn factor that transactions created by the devnet must meet
+func (vs *Visor) devnetBurnFactor() uint32 {
+	burnFactor := vs.Config.UnconfirmedVerifyTxn.BurnFactor
+	if vs.Config.CreateBlockVerifyTxn.BurnFactor > burnFactor {
+		burnFactor = vs.Config.CreateBlockVerifyTxn.BurnFactor
+	}
+	return burnFactor
+}
+
+// createDevnetFaucetTxn creates a transaction spending the output of the genesis address with the most coins,
+// sending coins to each of addrs and the change back to the genesis address.
+// The coin hours left after the fee are shared equally by the outputs.
+func (vs *Visor) createDevnetFaucetTxn(tx *dbutil.Tx, addrs []cipher.Address, coins uint64) (*coin.Transaction, error) {
+	head, err := vs.blockchain.Head(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	auxs, err := vs.blockchain.Unspent().GetUnspentsOfAddrs(tx, []cipher.Address{vs.Config.GenesisAddress})
+	if err != nil {
+		return nil, err
+	}
+
+	var ux *coin.UxOut
+	var hours uint64
+	for _, u := range auxs[vs.Config.GenesisAddress] {
+		u := u
+		h, err := u.CoinHours(head.Time())
+		if err != nil {
+			return nil, err
+		}
+
+		if h != 0 && (ux == nil || u.Body.Coins > ux.Body.Coins) {
+			ux = &u
+			hours = h
+		}
+	}
+
+	if ux == nil {
+		return nil, ErrDevnetFaucetEmpty
+	}
+
+	total, err := mathutil.MultUint64(coins, uint64(len(addrs)))
+	if err != nil {
+		return nil, err
+	}
+
+	if total >= ux.Body.Coins {
+		return nil, fmt.Errorf("Devnet genesis address output has %d droplets, cannot send %d", ux.Body.Coins, total)
+	}
+
+	remaining := fee.RemainingHours(hours, vs.devnetBurnFactor())
+	outHours := remaining / uint64(len(addrs)+1)
+
+	txn := coin.Transaction{}
+	if err := txn.PushInput(ux.Hash()); err != nil {
+		return nil, err
+	}
+
+	for _, a := range addrs {
+		if err := txn.PushOutput(a, coins, outHours, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	// The change keeps the program state of the genesis address
+	if err := txn.PushOutput(vs.Config.GenesisAddress, ux.Body.Coins-total, outHours, ux.Body.ProgramState); err != nil {
+		return nil, err
+	}
+
+	txn.SignInputs([]cipher.SecKey{vs.Config.BlockchainSeckey})
+	if err := txn.UpdateHeader(); err != nil {
+		return nil, err
+	}
+
+	return &txn, nil
+}
+
+// maybeFundDevnetAddresses funds the addresses of Config.Devnet in the first block after the genesis block
+func (vs *Visor) maybeFundDevnetAddresses(tx *dbutil.Tx) error {
+	if !vs.Config.Devnet.Enabled || len(vs.Config.Devnet.Addresses) == 0 {
+		return nil
+	}
+
+	headSeq, ok, err := vs.blockchain.HeadSeq(tx)
+	if err != nil {
+		return err
+	} else if !ok || headSeq != 0 {
+		return nil
+	}
+
+	logger.Infof("Funding %d devnet addresses with %d droplets each", len(vs.Config.Devnet.Addresses), vs.Config.Devnet.Coins)
+
+	txn, err := vs.createDevnetFaucetTxn(tx, vs.Config.Devnet.Addresses, vs.Config.Devnet.Coins)
+	if err != nil {
+		return err
+	}
+
+	head, err := vs.blockchain.Head(tx)
+	if err != nil {
+		return err
+	}
+
+	b, err := vs.blockchain.NewBlock(tx, coin.Transactions{*txn}, nextBlockTime(head))
+	if err != nil {
+		return err
+	}
+
+	return vs.executeSignedBlock(tx, vs.signBlock(*b))
+}
+
+// GenerateDevnetBlocks creates and executes n blocks from the unconfirmed pool.
+// If the unconfirmed pool is empty, a transaction from the genesis address to itself is added to the block,
+// since a block must have transactions.
+func (vs *Visor) GenerateDevnetBlocks(n int) ([]coin.SignedBlock, error) {
+	if !vs.Config.Devnet.Enabled {
+		return nil, ErrDevnetDisabled
+	}
+
+	if n <= 0 || n > MaxDevnetGenerateBlocks {
+		return nil, fmt.Errorf("Number of blocks must be > 0 and <= %d", MaxDevnetGenerateBlocks)
+	}
+
+	done, err := vs.acceptWrite()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	blocks := make([]coin.SignedBlock, 0, n)
+	for i := 0; i < n; i++ {
+		// Each block is committed separately, like blocks created by a block publisher
+		if err := vs.db.Update("GenerateDevnetBlocks", func(tx *dbutil.Tx) error {
+			sb, err := vs.generateDevnetBlock(tx)
+			if err != nil {
+				return err
+			}
+
+			blocks = append(blocks, sb)
+			return nil
+		}); err != nil {
+			return blocks, err
+		}
+	}
+
+	return blocks, nil
+}
+
+func (vs *Visor) generateDevnetBlock(tx *dbutil.Tx) (coin.SignedBlock, error) {
+	n, err := vs.unconfirmed.Len(tx)
+	if err != nil {
+		return coin.SignedBlock{}, err
+	}
+
+	if n == 0 {
+		txn, err := vs.createDevnetFaucetTxn(tx, nil, 0)
+		if err != nil {
+			return coin.SignedBlock{}, err
+		}
+
+		if _, _, err := vs.unconfirmed.InjectTransaction(tx, vs.blockchain, *txn, vs.Config.Distribution, vs.Config.UnconfirmedVerifyTxn); err != nil {
+			return coin.SignedBlock{}, err
+		}
+	}
+
+	head, err := vs.blockchain.Head(tx)
+	if err != nil {
+		return coin.SignedBlock{}, err
+	}
+
+	sb, err := vs.createBlock(tx, nextBlockTime(head))
+	if err != nil {
+		return coin.SignedBlock{}, err
+	}
+
+	if err := vs.executeSignedBlock(tx, sb); err != nil {
+		return coin.SignedBlock{}, err
+	}
+
+	return sb, nil
+}
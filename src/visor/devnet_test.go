@@ -0,0 +1,185 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/params"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/historydb"
+)
+
+func TestDevnetKeys(t *testing.T) {
+	pubkey, seckey := DevnetKeys("devnet")
+	require.Equal(t, pubkey, cipher.MustPubKeyFromSecKey(seckey))
+
+	// The genesis address and funded addresses are the addresses of a deterministic wallet with the same seed
+	keys := cipher.MustGenerateDeterministicKeyPairs([]byte("devnet"), 4)
+	require.Equal(t, keys[0], seckey)
+
+	addrs := DevnetAddresses("devnet", 3)
+	require.Len(t, addrs, 3)
+	for i, a := range addrs {
+		require.Equal(t, cipher.MustAddressFromSecKey(keys[i+1]), a)
+	}
+
+	require.Empty(t, DevnetAddresses("devnet", 0))
+	require.NotEqual(t, addrs, DevnetAddresses("other", 3))
+}
+
+func TestDevnetConfigVerify(t *testing.T) {
+	cfg := NewConfig()
+	cfg.IsBlockPublisher = true
+	cfg.BlockchainPubkey = genPublic
+	cfg.BlockchainSeckey = genSecret
+	cfg.GenesisAddress = genAddress
+	cfg.GenesisCoinVolume = genCoins
+	cfg.Devnet = DevnetConfig{
+		Enabled:   true,
+		Addresses: []cipher.Address{testutil.MakeAddress(), testutil.MakeAddress()},
+		Coins:     100e6,
+	}
+	require.NoError(t, cfg.Devnet.verify(cfg))
+
+	c := cfg
+	c.IsBlockPublisher = false
+	testutil.RequireError(t, c.Devnet.verify(c), "Devnet must run as a block publisher")
+
+	c = cfg
+	c.GenesisAddress = testutil.MakeAddress()
+	testutil.RequireError(t, c.Devnet.verify(c), "Devnet genesis address must be the address of the blockchain pubkey")
+
+	c = cfg
+	c.Devnet.Coins = genCoins / 2
+	testutil.RequireError(t, c.Devnet.verify(c), "Devnet funded addresses must receive less coins than the genesis coin volume")
+
+	c = cfg
+	c.IsBlockPublisher = false
+	c.Devnet.Enabled = false
+	require.NoError(t, c.Devnet.verify(c))
+}
+
+func TestVisorGenerateDevnetBlocks(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolPolicy{})
+	require.NoError(t, err)
+
+	addrs := []cipher.Address{testutil.MakeAddress(), testutil.MakeAddress()}
+
+	cfg := NewConfig()
+	cfg.IsBlockPublisher = true
+	cfg.BlockchainPubkey = genPublic
+	cfg.BlockchainSeckey = genSecret
+	cfg.GenesisAddress = genAddress
+	cfg.GenesisCoinVolume = genCoins
+	cfg.Distribution = params.MainNetDistribution
+
+	v := &Visor{
+		Config:      cfg,
+		unconfirmed: unconfirmed,
+		blockchain:  bc,
+		db:          db,
+		history:     historydb.New(),
+	}
+
+	_, err = v.GenerateDevnetBlocks(1)
+	require.Equal(t, ErrDevnetDisabled, err)
+
+	v.Config.Devnet = DevnetConfig{
+		Enabled:   true,
+		Addresses: addrs,
+		Coins:     100e6,
+	}
+
+	gb := addGenesisBlockToVisor(t, v)
+
+	// The funded addresses receive their coins in the first block
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return v.maybeFundDevnetAddresses(tx)
+	})
+	require.NoError(t, err)
+
+	head, err := v.GetHeadBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), head.Seq())
+	require.Len(t, head.Body.Transactions, 1)
+	require.Equal(t, coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])[0].Hash(), head.Body.Transactions[0].In[0])
+
+	balances := func() map[cipher.Address]uint64 {
+		b := make(map[cipher.Address]uint64)
+		err := db.View("", func(tx *dbutil.Tx) error {
+			auxs, err := bc.Unspent().GetUnspentsOfAddrs(tx, append([]cipher.Address{genAddress}, addrs...))
+			if err != nil {
+				return err
+			}
+			for a, uxs := range auxs {
+				for _, ux := range uxs {
+					b[a] += ux.Body.Coins
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		return b
+	}
+
+	require.Equal(t, map[cipher.Address]uint64{
+		genAddress: genCoins - 200e6,
+		addrs[0]:   100e6,
+		addrs[1]:   100e6,
+	}, balances())
+
+	// Funding is done only once
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return v.maybeFundDevnetAddresses(tx)
+	})
+	require.NoError(t, err)
+	head, err = v.GetHeadBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), head.Seq())
+
+	_, err = v.GenerateDevnetBlocks(0)
+	testutil.RequireError(t, err, "Number of blocks must be > 0 and <= 1000")
+
+	// Blocks are generated with a transaction of the genesis address to itself when the pool is empty
+	blocks, err := v.GenerateDevnetBlocks(3)
+	require.NoError(t, err)
+	require.Len(t, blocks, 3)
+	for i, b := range blocks {
+		require.Equal(t, uint64(i+2), b.Seq())
+		require.Len(t, b.Body.Transactions, 1)
+		require.Len(t, b.Body.Transactions[0].Out, 1)
+		require.Equal(t, genAddress, b.Body.Transactions[0].Out[0].Address)
+	}
+
+	// Blocks include the transactions of the unconfirmed pool
+	uxs := coin.CreateUnspents(blocks[2].Head, blocks[2].Body.Transactions[0])
+	txn := makeSpendTxWithFee(t, uxs, []cipher.SecKey{genSecret}, addrs[0], 50e6, 10)
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		_, _, err := unconfirmed.InjectTransaction(tx, bc, txn, v.Config.Distribution, v.Config.UnconfirmedVerifyTxn)
+		return err
+	})
+	require.NoError(t, err)
+
+	blocks, err = v.GenerateDevnetBlocks(1)
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+	require.Equal(t, coin.Transactions{txn}, blocks[0].Body.Transactions)
+
+	require.Equal(t, map[cipher.Address]uint64{
+		genAddress: genCoins - 250e6,
+		addrs[0]:   150e6,
+		addrs[1]:   100e6,
+	}, balances())
+}
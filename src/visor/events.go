@@ -0,0 +1,91 @@
+package visor
+
+import (
+	"sync"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+// eventBufferSize is the number of events buffered per subscriber.
+// Events published while a subscriber's buffer is full are dropped for that subscriber.
+const eventBufferSize = 256
+
+// EventType is the type of an Event
+type EventType int
+
+const (
+	// EventBlockExecuted is published when a block is added to the blockchain
+	EventBlockExecuted EventType = iota
+	// EventUnconfirmedInjected is published when new transactions are added to the unconfirmed pool
+	EventUnconfirmedInjected
+	// EventUnconfirmedRemoved is published when transactions are removed from the unconfirmed pool,
+	// because they were confirmed by a block or became invalid
+	EventUnconfirmedRemoved
+)
+
+// Event is published by the Visor once the database transaction that changed
+// the blockchain or the unconfirmed pool has been committed
+type Event struct {
+	Type EventType
+	// Block is the executed block, for EventBlockExecuted
+	Block *coin.SignedBlock
+	// Txids are the transactions added to or removed from the unconfirmed pool,
+	// for EventUnconfirmedInjected and EventUnconfirmedRemoved
+	Txids []cipher.SHA256
+}
+
+// eventPublisher delivers Events to subscribers. A nil *eventPublisher publishes nothing.
+type eventPublisher struct {
+	sync.Mutex
+	subscribers map[chan Event]struct{}
+}
+
+func (p *eventPublisher) subscribe() (<-chan Event, func()) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.subscribers == nil {
+		p.subscribers = make(map[chan Event]struct{})
+	}
+
+	c := make(chan Event, eventBufferSize)
+	p.subscribers[c] = struct{}{}
+
+	var once sync.Once
+	return c, func() {
+		once.Do(func() {
+			p.Lock()
+			defer p.Unlock()
+			delete(p.subscribers, c)
+			close(c)
+		})
+	}
+}
+
+// publish sends e to the subscribers after tx commits. Nothing is sent if tx is rolled back.
+func (p *eventPublisher) publish(tx *dbutil.Tx, e Event) {
+	if p == nil {
+		return
+	}
+
+	tx.OnCommit(func() {
+		p.Lock()
+		defer p.Unlock()
+
+		for c := range p.subscribers {
+			select {
+			case c <- e:
+			default:
+				logger.Warningf("Visor event subscriber is too slow, dropping event %d", e.Type)
+			}
+		}
+	})
+}
+
+// Subscribe returns a channel that receives Events, and a function that unsubscribes and closes the channel.
+// Events are sent without blocking; if the subscriber does not keep up, events are dropped.
+func (vs *Visor) Subscribe() (<-chan Event, func()) {
+	return vs.events.subscribe()
+}
@@ -0,0 +1,142 @@
+package visor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/historydb"
+)
+
+func requireNoEvent(t *testing.T, events <-chan Event) {
+	select {
+	case e := <-events:
+		t.Fatalf("unexpected event %+v", e)
+	default:
+	}
+}
+
+func TestVisorEvents(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolPolicy{})
+	require.NoError(t, err)
+
+	cfg := NewConfig()
+	cfg.IsBlockPublisher = true
+	cfg.BlockchainPubkey = genPublic
+	cfg.BlockchainSeckey = genSecret
+	cfg.GenesisAddress = genAddress
+
+	v := &Visor{
+		Config:      cfg,
+		unconfirmed: unconfirmed,
+		blockchain:  bc,
+		db:          db,
+		history:     historydb.New(),
+		events:      &eventPublisher{},
+	}
+
+	events, unsubscribe := v.Subscribe()
+
+	// Executing a block publishes the block, and the removal of its transactions from the pool
+	gb := addGenesisBlockToVisor(t, v)
+
+	e := <-events
+	require.Equal(t, EventBlockExecuted, e.Type)
+	require.Equal(t, gb.HashHeader(), e.Block.HashHeader())
+
+	e = <-events
+	require.Equal(t, EventUnconfirmedRemoved, e.Type)
+	require.Equal(t, []cipher.SHA256{gb.Body.Transactions[0].Hash()}, e.Txids)
+
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	txn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, 10e6)
+
+	// Nothing is published if the db transaction is rolled back
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		_, _, _, err := v.InjectUserTransactionTx(tx, txn)
+		require.NoError(t, err)
+		return errors.New("rollback")
+	})
+	require.EqualError(t, err, "rollback")
+	requireNoEvent(t, events)
+
+	// Injecting a new transaction publishes it
+	known, softErr, err := v.InjectForeignTransaction(txn)
+	require.NoError(t, err)
+	require.Nil(t, softErr)
+	require.False(t, known)
+
+	e = <-events
+	require.Equal(t, EventUnconfirmedInjected, e.Type)
+	require.Equal(t, []cipher.SHA256{txn.Hash()}, e.Txids)
+
+	// Injecting a known transaction does not
+	known, _, _, err = v.InjectUserTransaction(txn)
+	require.NoError(t, err)
+	require.True(t, known)
+	requireNoEvent(t, events)
+
+	sb, err := v.CreateAndExecuteBlock()
+	require.NoError(t, err)
+
+	e = <-events
+	require.Equal(t, EventBlockExecuted, e.Type)
+	require.Equal(t, uint64(1), e.Block.Head.BkSeq)
+	require.Equal(t, sb.HashHeader(), e.Block.HashHeader())
+
+	e = <-events
+	require.Equal(t, EventUnconfirmedRemoved, e.Type)
+	require.Equal(t, []cipher.SHA256{txn.Hash()}, e.Txids)
+
+	// Unsubscribing closes the channel
+	unsubscribe()
+	unsubscribe()
+	_, ok := <-events
+	require.False(t, ok)
+
+	// Publishing after all subscribers left is a no-op
+	uxs = coin.CreateUnspents(sb.Head, sb.Body.Transactions[0])
+	txn = makeSpendTxn(t, uxs[:1], []cipher.SecKey{genSecret}, genAddress, 1e6)
+	_, _, err = v.InjectForeignTransaction(txn)
+	require.NoError(t, err)
+}
+
+func TestEventPublisherDropsWhenFull(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	p := &eventPublisher{}
+	events, unsubscribe := p.subscribe()
+	defer unsubscribe()
+
+	err := db.Update("", func(tx *dbutil.Tx) error {
+		for i := 0; i < eventBufferSize+1; i++ {
+			p.publish(tx, Event{
+				Type: EventUnconfirmedInjected,
+			})
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, events, eventBufferSize)
+
+	// A nil publisher publishes nothing
+	var nilPublisher *eventPublisher
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		nilPublisher.publish(tx, Event{})
+		return nil
+	})
+	require.NoError(t, err)
+}
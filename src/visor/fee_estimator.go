@@ -0,0 +1,295 @@
+package visor
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/util/mathutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/blockdb"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+const (
+	// feeEstimateBlocks is the number of recent blocks the fee estimate is based on
+	feeEstimateBlocks = 20
+	// fullBlockPercent is the percentage of MaxBlockTransactionsSize above which a block is considered full
+	fullBlockPercent = 90
+)
+
+// FeeEstimate is the fee suggested for a transaction to be included in the next block.
+// Every transaction must burn the coin hours required by the burn factor;
+// the fees per kilobyte are the fees that a transaction may need to burn on top of it
+// to be chosen for the next block when blocks are full.
+type FeeEstimate struct {
+	HeadSeq uint64
+	// FeePerKB is the suggested fee in coin hours per 1000 bytes:
+	// the highest of MinFeePerKB, UnconfirmedFeePerKB and RecentFeePerKB
+	FeePerKB uint64
+	// MinFeePerKB is the minimum fee per kilobyte of a transaction accepted into the unconfirmed pool
+	MinFeePerKB uint64
+	// UnconfirmedFeePerKB is the fee per kilobyte needed to be included in a block created from
+	// the current unconfirmed pool, 0 if the whole pool fits in a block
+	UnconfirmedFeePerKB uint64
+	// RecentFeePerKB is the median of the lowest fee per kilobyte of the recent full blocks, 0 if none was full
+	RecentFeePerKB uint64
+	// UnconfirmedSize is the total size in bytes of the transactions in the unconfirmed pool
+	UnconfirmedSize uint64
+	// MaxBlockSize is the maximum total size in bytes of the transactions of a block
+	MaxBlockSize uint32
+	// Blocks is the number of recent blocks the estimate is based on
+	Blocks uint64
+	// FullBlocks is the number of recent blocks above fullBlockPercent of MaxBlockSize
+	FullBlocks uint64
+	// Fullness is the average fraction of MaxBlockSize used by the recent blocks
+	Fullness float64
+	// BurnedPerBlock is the average number of coin hours burned by the recent blocks
+	BurnedPerBlock uint64
+	// BurnedPerKB is the number of coin hours burned per 1000 bytes of transactions by the recent blocks
+	BurnedPerKB uint64
+}
+
+// blockFees are the sizes and the fees of the transactions of a block
+type blockFees struct {
+	size uint64
+	fee  uint64
+	// minFeePerKB is the lowest fee per kilobyte of the transactions of the block
+	minFeePerKB uint64
+}
+
+// feeEstimator caches the fees of the recent blocks, which are calculated from the spent outputs in the history db.
+// Blocks are immutable, so the cached fees are valid as long as the block is one of the recent blocks.
+type feeEstimator struct {
+	sync.Mutex
+	blocks map[cipher.SHA256]blockFees
+}
+
+// getBlockFees returns the fees of the transactions of b, calculated at the time of the previous block
+func (vs *Visor) getBlockFees(tx *dbutil.Tx, b *coin.SignedBlock) (blockFees, error) {
+	inputs, err := vs.getBlockInputs(tx, b)
+	if err != nil {
+		return blockFees{}, err
+	}
+
+	if len(inputs) != len(b.Body.Transactions) {
+		return blockFees{}, errors.New("getBlockFees: block inputs do not match the block transactions")
+	}
+
+	bf := blockFees{
+		minFeePerKB: math.MaxUint64,
+	}
+
+	for i, txn := range b.Body.Transactions {
+		size, err := txn.Size()
+		if err != nil {
+			return blockFees{}, err
+		}
+
+		var inHours uint64
+		for _, in := range inputs[i] {
+			inHours, err = mathutil.AddUint64(inHours, in.CalculatedHours)
+			if err != nil {
+				return blockFees{}, err
+			}
+		}
+
+		outHours, err := txn.OutputHours()
+		if err != nil {
+			return blockFees{}, err
+		}
+
+		if outHours > inHours {
+			return blockFees{}, errors.New("getBlockFees: transaction output hours exceed its input hours")
+		}
+
+		f := inHours - outHours
+
+		if bf.fee, err = mathutil.AddUint64(bf.fee, f); err != nil {
+			return blockFees{}, err
+		}
+		bf.size += uint64(size)
+
+		if rate := feePerKB(f, size); rate < bf.minFeePerKB {
+			bf.minFeePerKB = rate
+		}
+	}
+
+	if len(b.Body.Transactions) == 0 {
+		bf.minFeePerKB = 0
+	}
+
+	return bf, nil
+}
+
+// recentBlockFees returns the fees of the recent blocks, excluding the genesis block
+func (vs *Visor) recentBlockFees(tx *dbutil.Tx) ([]blockFees, error) {
+	blocks, err := vs.blockchain.GetLastBlocks(tx, feeEstimateBlocks)
+	if err != nil {
+		return nil, err
+	}
+
+	vs.feeEstimator.Lock()
+	defer vs.feeEstimator.Unlock()
+
+	cached := make(map[cipher.SHA256]blockFees, len(blocks))
+	fees := make([]blockFees, 0, len(blocks))
+	for i := range blocks {
+		b := &blocks[i]
+		if b.Seq() == 0 {
+			continue
+		}
+
+		hash := b.HashHeader()
+		bf, ok := vs.feeEstimator.blocks[hash]
+		if !ok {
+			bf, err = vs.getBlockFees(tx, b)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		cached[hash] = bf
+		fees = append(fees, bf)
+	}
+
+	// Only the recent blocks are kept in the cache
+	vs.feeEstimator.blocks = cached
+
+	return fees, nil
+}
+
+// GetFeeEstimate returns the fee suggested for a transaction to be included in the next block,
+// estimated from the fullness and the fees of the recent blocks and from the unconfirmed pool
+func (vs *Visor) GetFeeEstimate() (*FeeEstimate, error) {
+	var est *FeeEstimate
+	if err := vs.db.View("GetFeeEstimate", func(tx *dbutil.Tx) error {
+		var err error
+		est, err = vs.getFeeEstimate(tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return est, nil
+}
+
+func (vs *Visor) getFeeEstimate(tx *dbutil.Tx) (*FeeEstimate, error) {
+	headSeq, ok, err := vs.blockchain.HeadSeq(tx)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, blockdb.ErrNoHeadBlock
+	}
+
+	maxSize := vs.Config.MaxBlockTransactionsSize
+
+	est := &FeeEstimate{
+		HeadSeq:      headSeq,
+		MinFeePerKB:  vs.Config.UnconfirmedPolicy.MinFeePerKB,
+		MaxBlockSize: maxSize,
+	}
+
+	est.UnconfirmedFeePerKB, est.UnconfirmedSize, err = vs.unconfirmed.NextBlockFeePerKB(tx, maxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	fees, err := vs.recentBlockFees(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var totalSize, totalFee uint64
+	var fullRates []uint64
+	for _, bf := range fees {
+		totalSize += bf.size
+		if totalFee, err = mathutil.AddUint64(totalFee, bf.fee); err != nil {
+			return nil, err
+		}
+
+		if maxSize != 0 && bf.size*100 >= uint64(maxSize)*fullBlockPercent {
+			fullRates = append(fullRates, bf.minFeePerKB)
+		}
+	}
+
+	est.Blocks = uint64(len(fees))
+	est.FullBlocks = uint64(len(fullRates))
+
+	if len(fees) != 0 {
+		if maxSize != 0 {
+			est.Fullness = float64(totalSize) / float64(uint64(maxSize)*est.Blocks)
+		}
+		est.BurnedPerBlock = totalFee / est.Blocks
+		if totalSize > math.MaxUint32 {
+			totalSize = math.MaxUint32
+		}
+		est.BurnedPerKB = feePerKB(totalFee, uint32(totalSize))
+	}
+
+	if len(fullRates) != 0 {
+		sort.Slice(fullRates, func(i, j int) bool {
+			return fullRates[i] < fullRates[j]
+		})
+		est.RecentFeePerKB = fullRates[len(fullRates)/2]
+	}
+
+	est.FeePerKB = est.MinFeePerKB
+	if est.UnconfirmedFeePerKB > est.FeePerKB {
+		est.FeePerKB = est.UnconfirmedFeePerKB
+	}
+	if est.RecentFeePerKB > est.FeePerKB {
+		est.FeePerKB = est.RecentFeePerKB
+	}
+
+	return est, nil
+}
+
+// NextBlockFeePerKB returns the fee per kilobyte needed to be included in a block with maxSize bytes of
+// transactions created from the pool, and the total size of the pool. The fee per kilobyte is 0
+// if all the transactions of the pool fit in the block. Blocks are created with the transactions
+// with the highest fee per kilobyte first, so a transaction needs a higher fee per kilobyte
+// than the pooled transactions that would not fit.
+func (utp *UnconfirmedTransactionPool) NextBlockFeePerKB(tx *dbutil.Tx, maxSize uint32) (uint64, uint64, error) {
+	total, err := utp.feeIndex.totalSize(tx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if total <= uint64(maxSize) {
+		return 0, total, nil
+	}
+
+	excess := total - uint64(maxSize)
+	var excluded, rate uint64
+	if err := utp.feeIndex.forEachLowest(tx, func(p pooledTxnFee) (bool, error) {
+		excluded += uint64(p.size)
+		rate = p.feePerKB
+		return excluded < excess, nil
+	}); err != nil {
+		return 0, 0, err
+	}
+
+	if rate == math.MaxUint64 {
+		return rate, total, nil
+	}
+
+	return rate + 1, total, nil
+}
+
+// createTransactionFeePerKB returns the fee per kilobyte of a transaction created with wp,
+// the fee estimate for the next block unless wp.FeePerKB is set
+func (vs *Visor) createTransactionFeePerKB(tx *dbutil.Tx, wp CreateTransactionParams) (uint64, error) {
+	if wp.FeePerKB != nil {
+		return *wp.FeePerKB, nil
+	}
+
+	est, err := vs.getFeeEstimate(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	return est.FeePerKB, nil
+}
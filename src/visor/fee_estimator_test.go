@@ -0,0 +1,189 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/historydb"
+)
+
+func TestNextBlockFeePerKB(t *testing.T) {
+	db, shutdown := testutil.PrepareDB(t)
+	defer shutdown()
+
+	err := CreateBuckets(db)
+	require.NoError(t, err)
+
+	utp, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolPolicy{})
+	require.NoError(t, err)
+
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		rate, total, err := utp.NextBlockFeePerKB(tx, 1000)
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), rate)
+		require.Equal(t, uint64(0), total)
+
+		require.NoError(t, utp.feeIndex.put(tx, testutil.RandSHA256(t), 300, 10, 1))
+		require.NoError(t, utp.feeIndex.put(tx, testutil.RandSHA256(t), 300, 50, 1))
+		require.NoError(t, utp.feeIndex.put(tx, testutil.RandSHA256(t), 400, 90, 1))
+
+		// The pool fits in a block
+		rate, total, err = utp.NextBlockFeePerKB(tx, 1000)
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), rate)
+		require.Equal(t, uint64(1000), total)
+
+		// The lowest fee transaction does not fit
+		rate, total, err = utp.NextBlockFeePerKB(tx, 999)
+		require.NoError(t, err)
+		require.Equal(t, uint64(11), rate)
+		require.Equal(t, uint64(1000), total)
+
+		// Only the highest fee transaction fits
+		rate, _, err = utp.NextBlockFeePerKB(tx, 400)
+		require.NoError(t, err)
+		require.Equal(t, uint64(51), rate)
+
+		// No transaction fits
+		rate, _, err = utp.NextBlockFeePerKB(tx, 0)
+		require.NoError(t, err)
+		require.Equal(t, uint64(91), rate)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestGetFeeEstimate(t *testing.T) {
+	db, shutdown := testutil.PrepareDB(t)
+	defer shutdown()
+
+	bc := &MockBlockchainer{}
+	history := &MockHistoryer{}
+	unconfirmed := &MockUnconfirmedTransactionPooler{}
+
+	// makeBlock makes a block whose transactions burn fees, with inputs created at the time of the previous block
+	var uxOuts []historydb.UxOut
+	makeBlock := func(seq uint64, fees ...uint64) coin.SignedBlock {
+		var txns coin.Transactions
+		for _, f := range fees {
+			txn, ux := makePolicyTxn(t, (seq-1)*100, 100, 100-f)
+			txns = append(txns, txn)
+			uxOuts = append(uxOuts, historydb.UxOut{Out: ux})
+			history.On("GetUxOuts", matchDBTx, txn.In).Return([]historydb.UxOut{{Out: ux}}, nil)
+		}
+
+		return coin.SignedBlock{
+			Block: coin.Block{
+				Head: coin.BlockHeader{
+					BkSeq: seq,
+					Time:  seq * 100,
+				},
+				Body: coin.BlockBody{
+					Transactions: txns,
+				},
+			},
+		}
+	}
+
+	genesis := coin.SignedBlock{
+		Block: coin.Block{
+			Body: coin.BlockBody{
+				Transactions: coin.Transactions{{}},
+			},
+		},
+	}
+
+	blocks := []coin.SignedBlock{
+		genesis,
+		makeBlock(1, 10),
+		makeBlock(2, 50, 100),
+		makeBlock(3, 20, 100),
+	}
+
+	for i := range blocks[1:] {
+		b := blocks[i]
+		bc.On("GetSignedBlockBySeq", matchDBTx, b.Seq()).Return(&b, nil)
+	}
+
+	size, err := blocks[1].Body.Transactions[0].Size()
+	require.NoError(t, err)
+	txnSize := uint64(size)
+
+	bc.On("HeadSeq", matchDBTx).Return(uint64(3), true, nil)
+	bc.On("GetLastBlocks", matchDBTx, uint64(feeEstimateBlocks)).Return(blocks, nil)
+	unconfirmed.On("NextBlockFeePerKB", matchDBTx, size*2).Return(uint64(7), uint64(500), nil)
+
+	v := &Visor{
+		Config: Config{
+			MaxBlockTransactionsSize: size * 2,
+			UnconfirmedPolicy: UnconfirmedPoolPolicy{
+				MinFeePerKB: 3,
+			},
+		},
+		db:          db,
+		blockchain:  bc,
+		history:     history,
+		unconfirmed: unconfirmed,
+	}
+
+	// Blocks 2 and 3 are full, the median of their lowest fees per kilobyte is that of block 2
+	expected := &FeeEstimate{
+		HeadSeq:             3,
+		FeePerKB:            feePerKB(50, size),
+		MinFeePerKB:         3,
+		UnconfirmedFeePerKB: 7,
+		RecentFeePerKB:      feePerKB(50, size),
+		UnconfirmedSize:     500,
+		MaxBlockSize:        size * 2,
+		Blocks:              3,
+		FullBlocks:          2,
+		Fullness:            5.0 / 6.0,
+		BurnedPerBlock:      280 / 3,
+		BurnedPerKB:         feePerKB(280, uint32(txnSize*5)),
+	}
+
+	est, err := v.GetFeeEstimate()
+	require.NoError(t, err)
+	require.Equal(t, expected, est)
+	history.AssertNumberOfCalls(t, "GetUxOuts", len(uxOuts))
+
+	// The fees of the blocks are cached
+	est, err = v.GetFeeEstimate()
+	require.NoError(t, err)
+	require.Equal(t, expected, est)
+	history.AssertNumberOfCalls(t, "GetUxOuts", len(uxOuts))
+
+	// The fee per kilobyte of the transactions created by the visor defaults to the estimate
+	err = db.View("", func(tx *dbutil.Tx) error {
+		rate, err := v.createTransactionFeePerKB(tx, CreateTransactionParams{})
+		require.NoError(t, err)
+		require.Equal(t, expected.FeePerKB, rate)
+
+		override := uint64(0)
+		rate, err = v.createTransactionFeePerKB(tx, CreateTransactionParams{
+			FeePerKB: &override,
+		})
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), rate)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The unconfirmed pool needs a higher fee
+	unconfirmed = &MockUnconfirmedTransactionPooler{}
+	unconfirmed.On("NextBlockFeePerKB", matchDBTx, size*2).Return(uint64(1e6), uint64(500), nil)
+	v.unconfirmed = unconfirmed
+
+	est, err = v.GetFeeEstimate()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1e6), est.FeePerKB)
+}
+
+var _ = mock.Anything
+var _ = cipher.SHA256{}
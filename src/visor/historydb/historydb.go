@@ -207,6 +207,16 @@ func (hd HistoryDB) GetTransactionsForAddress(tx *dbutil.Tx, address cipher.Addr
 	return hd.txns.getArray(tx, hashes)
 }
 
+// AddressSeen returns true if the address appears in any confirmed transaction
+func (hd HistoryDB) AddressSeen(tx *dbutil.Tx, address cipher.Address) (bool, error) {
+	hashes, err := hd.addrTxns.get(tx, address)
+	if err != nil {
+		return false, err
+	}
+
+	return len(hashes) > 0, nil
+}
+
 // ForEachTxn traverses the transactions bucket
 func (hd HistoryDB) ForEachTxn(tx *dbutil.Tx, f func(cipher.SHA256, *Transaction) error) error {
 	return hd.txns.forEach(tx, f)
@@ -0,0 +1,92 @@
+package visor
+
+import (
+	"sync"
+
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+// BlockAppliedHook is called with a block once it has been committed to the blockchain
+type BlockAppliedHook func(*coin.SignedBlock)
+
+// TxnConfirmedHook is called with a transaction and the block confirming it once the block has been committed
+type TxnConfirmedHook func(*coin.Transaction, *coin.SignedBlock)
+
+// ReorgHook is called with the previous and the new head block when the head block is replaced by a block of another branch
+type ReorgHook func(oldHead, newHead *coin.SignedBlock)
+
+// hooks holds the callbacks registered by the embedder of the Visor
+type hooks struct {
+	sync.RWMutex
+	blockApplied []BlockAppliedHook
+	txnConfirmed []TxnConfirmedHook
+	reorg        []ReorgHook
+}
+
+// OnBlockApplied registers f to be called after each block is committed to the blockchain, in block order.
+// Hooks are called synchronously by the goroutine that executed the block and delay the processing of the next blocks,
+// so slow work should be handed off. Hooks must not write blocks or transactions through the Visor.
+func (vs *Visor) OnBlockApplied(f BlockAppliedHook) {
+	vs.hooks.Lock()
+	defer vs.hooks.Unlock()
+	vs.hooks.blockApplied = append(vs.hooks.blockApplied, f)
+}
+
+// OnTxnConfirmed registers f to be called for each transaction of a block after the block is committed
+// to the blockchain, after the OnBlockApplied hooks. The same restrictions as OnBlockApplied apply.
+func (vs *Visor) OnTxnConfirmed(f TxnConfirmedHook) {
+	vs.hooks.Lock()
+	defer vs.hooks.Unlock()
+	vs.hooks.txnConfirmed = append(vs.hooks.txnConfirmed, f)
+}
+
+// OnReorg registers f to be called when the head block is replaced by a block of another branch.
+// Under the current consensus the hooks are never called: blocks are signed by the single block publisher
+// of the chain and are only appended to the head block, so the visor never reorganizes the blockchain.
+func (vs *Visor) OnReorg(f ReorgHook) {
+	vs.hooks.Lock()
+	defer vs.hooks.Unlock()
+	vs.hooks.reorg = append(vs.hooks.reorg, f)
+}
+
+// callBlockApplied calls the OnBlockApplied and OnTxnConfirmed hooks for b after tx commits.
+// Nothing is called if tx is rolled back.
+func (h *hooks) callBlockApplied(tx *dbutil.Tx, b *coin.SignedBlock) {
+	h.RLock()
+	blockApplied := h.blockApplied
+	txnConfirmed := h.txnConfirmed
+	h.RUnlock()
+
+	if len(blockApplied) == 0 && len(txnConfirmed) == 0 {
+		return
+	}
+
+	tx.OnCommit(func() {
+		for _, f := range blockApplied {
+			callHook("OnBlockApplied", func() {
+				f(b)
+			})
+		}
+
+		for i := range b.Body.Transactions {
+			txn := &b.Body.Transactions[i]
+			for _, f := range txnConfirmed {
+				callHook("OnTxnConfirmed", func() {
+					f(txn, b)
+				})
+			}
+		}
+	})
+}
+
+// callHook calls f, logging instead of crashing the node if it panics
+func callHook(name string, f func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Critical().Errorf("Visor %s hook panicked: %v", name, r)
+		}
+	}()
+
+	f()
+}
@@ -0,0 +1,100 @@
+package visor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/historydb"
+)
+
+func TestVisorHooks(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolPolicy{})
+	require.NoError(t, err)
+
+	cfg := NewConfig()
+	cfg.IsBlockPublisher = true
+	cfg.BlockchainPubkey = genPublic
+	cfg.BlockchainSeckey = genSecret
+	cfg.GenesisAddress = genAddress
+
+	v := &Visor{
+		Config:      cfg,
+		unconfirmed: unconfirmed,
+		blockchain:  bc,
+		db:          db,
+		history:     historydb.New(),
+	}
+
+	var calls []string
+	var blocks []cipher.SHA256
+	var txids []cipher.SHA256
+
+	// A panicking hook does not stop the other hooks
+	v.OnBlockApplied(func(*coin.SignedBlock) {
+		calls = append(calls, "panic")
+		panic("hook failed")
+	})
+	v.OnBlockApplied(func(b *coin.SignedBlock) {
+		// The block is committed when the hook is called
+		err := db.View("", func(tx *dbutil.Tx) error {
+			head, err := bc.Head(tx)
+			require.NoError(t, err)
+			require.Equal(t, b.HashHeader(), head.HashHeader())
+			return nil
+		})
+		require.NoError(t, err)
+
+		calls = append(calls, "block")
+		blocks = append(blocks, b.HashHeader())
+	})
+	v.OnTxnConfirmed(func(txn *coin.Transaction, b *coin.SignedBlock) {
+		calls = append(calls, "txn")
+		txids = append(txids, txn.Hash())
+	})
+	v.OnReorg(func(oldHead, newHead *coin.SignedBlock) {
+		t.Fatal("unexpected reorg")
+	})
+
+	gb := addGenesisBlockToVisor(t, v)
+	require.Equal(t, []string{"panic", "block", "txn"}, calls)
+	require.Equal(t, []cipher.SHA256{gb.HashHeader()}, blocks)
+	require.Equal(t, []cipher.SHA256{gb.Body.Transactions[0].Hash()}, txids)
+
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	txn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, 10e6)
+	_, _, _, err = v.InjectUserTransaction(txn)
+	require.NoError(t, err)
+
+	sb, err := v.CreateAndExecuteBlock()
+	require.NoError(t, err)
+
+	// Nothing is called if the db transaction is rolled back
+	calls = nil
+	uxs = coin.CreateUnspents(sb.Head, sb.Body.Transactions[0])
+	txn = makeSpendTxn(t, uxs[:1], []cipher.SecKey{genSecret}, genAddress, 1e6)
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		b, err := bc.NewBlock(tx, coin.Transactions{txn}, sb.Time()+10)
+		require.NoError(t, err)
+		err = v.executeSignedBlock(tx, v.signBlock(*b))
+		require.NoError(t, err)
+		return errors.New("rollback")
+	})
+	require.EqualError(t, err, "rollback")
+	require.Empty(t, calls)
+
+	require.Equal(t, []cipher.SHA256{gb.HashHeader(), sb.HashHeader()}, blocks)
+	require.Equal(t, []cipher.SHA256{gb.Body.Transactions[0].Hash(), sb.Body.Transactions[0].Hash()}, txids)
+}
@@ -20,6 +20,7 @@ type Historyer interface {
 	GetTransaction(tx *dbutil.Tx, hash cipher.SHA256) (*historydb.Transaction, error)
 	GetOutputsForAddress(tx *dbutil.Tx, address cipher.Address) ([]historydb.UxOut, error)
 	GetTransactionsForAddress(tx *dbutil.Tx, address cipher.Address) ([]historydb.Transaction, error)
+	AddressSeen(tx *dbutil.Tx, address cipher.Address) (bool, error)
 	NeedsReset(tx *dbutil.Tx) (bool, error)
 	Erase(tx *dbutil.Tx) error
 	ParsedBlockSeq(tx *dbutil.Tx) (uint64, bool, error)
@@ -35,6 +36,13 @@ type Blockchainer interface {
 	GetSignedBlockByHash(tx *dbutil.Tx, hash cipher.SHA256) (*coin.SignedBlock, error)
 	GetSignedBlockBySeq(tx *dbutil.Tx, seq uint64) (*coin.SignedBlock, error)
 	Unspent() blockdb.UnspentPooler
+	UnspentCommitment(tx *dbutil.Tx) (cipher.SHA256, error)
+	AddressHistory(tx *dbutil.Tx, addr cipher.Address, offset, limit uint64) ([]blockdb.HistoryEntry, uint64, error)
+	TxnProof(tx *dbutil.Tx, txid cipher.SHA256) (*blockdb.TxnProof, error)
+	Supply(tx *dbutil.Tx) (blockdb.BalanceTotal, error)
+	AddressBalanceTotals(tx *dbutil.Tx, addrs []cipher.Address) ([]blockdb.BalanceTotal, error)
+	AddressBalanceTotalsAtSeq(tx *dbutil.Tx, addrs []cipher.Address, seq uint64) ([]blockdb.BalanceTotal, error)
+	ForEachAddressByCoins(tx *dbutil.Tx, f func(cipher.Address, uint64) (bool, error)) error
 	Len(tx *dbutil.Tx) (uint64, error)
 	Head(tx *dbutil.Tx) (*coin.SignedBlock, error)
 	HeadSeq(tx *dbutil.Tx) (uint64, bool, error)
@@ -56,6 +64,8 @@ type UnconfirmedTransactionPooler interface {
 	RemoveTransactions(tx *dbutil.Tx, txns []cipher.SHA256) error
 	Refresh(tx *dbutil.Tx, bc Blockchainer, distParams params.Distribution, verifyParams params.VerifyTxn) ([]cipher.SHA256, error)
 	RemoveInvalid(tx *dbutil.Tx, bc Blockchainer) ([]cipher.SHA256, error)
+	EnforcePolicy(tx *dbutil.Tx, bc Blockchainer) ([]cipher.SHA256, error)
+	NextBlockFeePerKB(tx *dbutil.Tx, maxSize uint32) (uint64, uint64, error)
 	FilterKnown(tx *dbutil.Tx, txns []cipher.SHA256) ([]cipher.SHA256, error)
 	GetKnown(tx *dbutil.Tx, txns []cipher.SHA256) (coin.Transactions, error)
 	RecvOfAddresses(tx *dbutil.Tx, bh coin.BlockHeader, addrs []cipher.Address) (coin.AddressUxOuts, error)
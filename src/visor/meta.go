@@ -5,6 +5,7 @@ import (
 
 	"github.com/blang/semver"
 
+	"github.com/SkycoinProject/cx-chains/src/visor/blockdb"
 	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
 )
 
@@ -13,6 +14,9 @@ var (
 	MetaBkt = []byte("db_meta")
 
 	versionKey = []byte("version")
+
+	// uncleanShutdownKey is set in the blockchain metadata while the DB is open for writing
+	uncleanShutdownKey = []byte("unclean_shutdown")
 )
 
 // GetDBVersion returns the saved DB version
@@ -69,3 +73,42 @@ func SetDBVersion(db *dbutil.DB, version semver.Version) error {
 		return dbutil.PutBucketValue(tx, MetaBkt, versionKey, []byte(version.String()))
 	})
 }
+
+// GetUncleanShutdown returns true if the DB was not closed by a clean shutdown the last time it was opened for writing,
+// e.g. because the process crashed or the machine lost power
+func GetUncleanShutdown(db *dbutil.DB) (bool, error) {
+	var unclean bool
+	if err := db.View("GetUncleanShutdown", func(tx *dbutil.Tx) error {
+		var err error
+		unclean, err = dbutil.BucketHasKey(tx, blockdb.BlockchainMetaBkt, uncleanShutdownKey)
+		switch err.(type) {
+		case dbutil.ErrBucketNotExist:
+			return nil
+		default:
+			return err
+		}
+	}); err != nil {
+		return false, err
+	}
+
+	return unclean, nil
+}
+
+// SetUncleanShutdown sets the unclean shutdown flag. The flag is set after the DB is opened for writing
+// and cleared after a clean shutdown, so that a DB left open by a crash is verified on the next startup.
+func SetUncleanShutdown(db *dbutil.DB, unclean bool) error {
+	return db.Update("SetUncleanShutdown", func(tx *dbutil.Tx) error {
+		if !unclean {
+			if !dbutil.Exists(tx, blockdb.BlockchainMetaBkt) {
+				return nil
+			}
+			return dbutil.Delete(tx, blockdb.BlockchainMetaBkt, uncleanShutdownKey)
+		}
+
+		if _, err := tx.CreateBucketIfNotExists(blockdb.BlockchainMetaBkt); err != nil {
+			return err
+		}
+
+		return dbutil.PutBucketValue(tx, blockdb.BlockchainMetaBkt, uncleanShutdownKey, []byte{1})
+	})
+}
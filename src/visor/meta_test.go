@@ -43,3 +43,31 @@ func TestGetSetDBVersion(t *testing.T) {
 	err = SetDBVersion(db, x)
 	testutil.RequireError(t, err, "SetDBVersion cannot regress version from 0.26.0 to 0.25.0")
 }
+
+func TestGetSetUncleanShutdown(t *testing.T) {
+	db, shutdown := testutil.PrepareDB(t)
+	defer shutdown()
+
+	// No blockchain metadata bucket yet
+	unclean, err := GetUncleanShutdown(db)
+	require.NoError(t, err)
+	require.False(t, unclean)
+
+	// Clearing the flag without the bucket is a no-op
+	err = SetUncleanShutdown(db, false)
+	require.NoError(t, err)
+
+	err = SetUncleanShutdown(db, true)
+	require.NoError(t, err)
+
+	unclean, err = GetUncleanShutdown(db)
+	require.NoError(t, err)
+	require.True(t, unclean)
+
+	err = SetUncleanShutdown(db, false)
+	require.NoError(t, err)
+
+	unclean, err = GetUncleanShutdown(db)
+	require.NoError(t, err)
+	require.False(t, unclean)
+}
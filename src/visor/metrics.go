@@ -0,0 +1,31 @@
+package visor
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	promBlockExecuteDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "block_execute_duration_seconds",
+			Help:    "Time to verify and execute a block, excluding the database commit",
+			Buckets: []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		})
+	promBlocksCommitDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "blocks_commit_duration_seconds",
+			Help:    "Time to execute a batch of blocks and commit them to the database",
+			Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		})
+)
+
+func init() {
+	prometheus.MustRegister(promBlockExecuteDuration)
+	prometheus.MustRegister(promBlocksCommitDuration)
+}
+
+func observeSince(h prometheus.Histogram, start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
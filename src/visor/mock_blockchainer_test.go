@@ -14,6 +14,82 @@ type MockBlockchainer struct {
 	mock.Mock
 }
 
+// AddressBalanceTotals provides a mock function with given fields: tx, addrs
+func (_m *MockBlockchainer) AddressBalanceTotals(tx *dbutil.Tx, addrs []cipher.Address) ([]blockdb.BalanceTotal, error) {
+	ret := _m.Called(tx, addrs)
+
+	var r0 []blockdb.BalanceTotal
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx, []cipher.Address) []blockdb.BalanceTotal); ok {
+		r0 = rf(tx, addrs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]blockdb.BalanceTotal)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*dbutil.Tx, []cipher.Address) error); ok {
+		r1 = rf(tx, addrs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddressBalanceTotalsAtSeq provides a mock function with given fields: tx, addrs, seq
+func (_m *MockBlockchainer) AddressBalanceTotalsAtSeq(tx *dbutil.Tx, addrs []cipher.Address, seq uint64) ([]blockdb.BalanceTotal, error) {
+	ret := _m.Called(tx, addrs, seq)
+
+	var r0 []blockdb.BalanceTotal
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx, []cipher.Address, uint64) []blockdb.BalanceTotal); ok {
+		r0 = rf(tx, addrs, seq)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]blockdb.BalanceTotal)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*dbutil.Tx, []cipher.Address, uint64) error); ok {
+		r1 = rf(tx, addrs, seq)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddressHistory provides a mock function with given fields: tx, addr, offset, limit
+func (_m *MockBlockchainer) AddressHistory(tx *dbutil.Tx, addr cipher.Address, offset uint64, limit uint64) ([]blockdb.HistoryEntry, uint64, error) {
+	ret := _m.Called(tx, addr, offset, limit)
+
+	var r0 []blockdb.HistoryEntry
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx, cipher.Address, uint64, uint64) []blockdb.HistoryEntry); ok {
+		r0 = rf(tx, addr, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]blockdb.HistoryEntry)
+		}
+	}
+
+	var r1 uint64
+	if rf, ok := ret.Get(1).(func(*dbutil.Tx, cipher.Address, uint64, uint64) uint64); ok {
+		r1 = rf(tx, addr, offset, limit)
+	} else {
+		r1 = ret.Get(1).(uint64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(*dbutil.Tx, cipher.Address, uint64, uint64) error); ok {
+		r2 = rf(tx, addr, offset, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // ExecuteBlock provides a mock function with given fields: tx, sb
 func (_m *MockBlockchainer) ExecuteBlock(tx *dbutil.Tx, sb *coin.SignedBlock) error {
 	ret := _m.Called(tx, sb)
@@ -28,6 +104,20 @@ func (_m *MockBlockchainer) ExecuteBlock(tx *dbutil.Tx, sb *coin.SignedBlock) er
 	return r0
 }
 
+// ForEachAddressByCoins provides a mock function with given fields: tx, f
+func (_m *MockBlockchainer) ForEachAddressByCoins(tx *dbutil.Tx, f func(cipher.Address, uint64) (bool, error)) error {
+	ret := _m.Called(tx, f)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx, func(cipher.Address, uint64) (bool, error)) error); ok {
+		r0 = rf(tx, f)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetBlocks provides a mock function with given fields: tx, seqs
 func (_m *MockBlockchainer) GetBlocks(tx *dbutil.Tx, seqs []uint64) ([]coin.SignedBlock, error) {
 	ret := _m.Called(tx, seqs)
@@ -261,6 +351,27 @@ func (_m *MockBlockchainer) NewBlock(tx *dbutil.Tx, txns coin.Transactions, curr
 	return r0, r1
 }
 
+// Supply provides a mock function with given fields: tx
+func (_m *MockBlockchainer) Supply(tx *dbutil.Tx) (blockdb.BalanceTotal, error) {
+	ret := _m.Called(tx)
+
+	var r0 blockdb.BalanceTotal
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx) blockdb.BalanceTotal); ok {
+		r0 = rf(tx)
+	} else {
+		r0 = ret.Get(0).(blockdb.BalanceTotal)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*dbutil.Tx) error); ok {
+		r1 = rf(tx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Time provides a mock function with given fields: tx
 func (_m *MockBlockchainer) Time(tx *dbutil.Tx) (uint64, error) {
 	ret := _m.Called(tx)
@@ -298,6 +409,29 @@ func (_m *MockBlockchainer) TransactionFee(tx *dbutil.Tx, hours uint64) coin.Fee
 	return r0
 }
 
+// TxnProof provides a mock function with given fields: tx, txid
+func (_m *MockBlockchainer) TxnProof(tx *dbutil.Tx, txid cipher.SHA256) (*blockdb.TxnProof, error) {
+	ret := _m.Called(tx, txid)
+
+	var r0 *blockdb.TxnProof
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx, cipher.SHA256) *blockdb.TxnProof); ok {
+		r0 = rf(tx, txid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*blockdb.TxnProof)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*dbutil.Tx, cipher.SHA256) error); ok {
+		r1 = rf(tx, txid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Unspent provides a mock function with given fields:
 func (_m *MockBlockchainer) Unspent() blockdb.UnspentPooler {
 	ret := _m.Called()
@@ -314,6 +448,29 @@ func (_m *MockBlockchainer) Unspent() blockdb.UnspentPooler {
 	return r0
 }
 
+// UnspentCommitment provides a mock function with given fields: tx
+func (_m *MockBlockchainer) UnspentCommitment(tx *dbutil.Tx) (cipher.SHA256, error) {
+	ret := _m.Called(tx)
+
+	var r0 cipher.SHA256
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx) cipher.SHA256); ok {
+		r0 = rf(tx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(cipher.SHA256)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*dbutil.Tx) error); ok {
+		r1 = rf(tx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // VerifyBlockTxnConstraints provides a mock function with given fields: tx, txn
 func (_m *MockBlockchainer) VerifyBlockTxnConstraints(tx *dbutil.Tx, txn coin.Transaction) error {
 	ret := _m.Called(tx, txn)
@@ -36,6 +36,29 @@ func (_m *MockUnconfirmedTransactionPooler) AllRawTransactions(tx *dbutil.Tx) (c
 	return r0, r1
 }
 
+// EnforcePolicy provides a mock function with given fields: tx, bc
+func (_m *MockUnconfirmedTransactionPooler) EnforcePolicy(tx *dbutil.Tx, bc Blockchainer) ([]cipher.SHA256, error) {
+	ret := _m.Called(tx, bc)
+
+	var r0 []cipher.SHA256
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx, Blockchainer) []cipher.SHA256); ok {
+		r0 = rf(tx, bc)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]cipher.SHA256)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*dbutil.Tx, Blockchainer) error); ok {
+		r1 = rf(tx, bc)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FilterKnown provides a mock function with given fields: tx, txns
 func (_m *MockUnconfirmedTransactionPooler) FilterKnown(tx *dbutil.Tx, txns []cipher.SHA256) ([]cipher.SHA256, error) {
 	ret := _m.Called(tx, txns)
@@ -262,6 +285,34 @@ func (_m *MockUnconfirmedTransactionPooler) Len(tx *dbutil.Tx) (uint64, error) {
 	return r0, r1
 }
 
+// NextBlockFeePerKB provides a mock function with given fields: tx, maxSize
+func (_m *MockUnconfirmedTransactionPooler) NextBlockFeePerKB(tx *dbutil.Tx, maxSize uint32) (uint64, uint64, error) {
+	ret := _m.Called(tx, maxSize)
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx, uint32) uint64); ok {
+		r0 = rf(tx, maxSize)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	var r1 uint64
+	if rf, ok := ret.Get(1).(func(*dbutil.Tx, uint32) uint64); ok {
+		r1 = rf(tx, maxSize)
+	} else {
+		r1 = ret.Get(1).(uint64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(*dbutil.Tx, uint32) error); ok {
+		r2 = rf(tx, maxSize)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // RecvOfAddresses provides a mock function with given fields: tx, bh, addrs
 func (_m *MockUnconfirmedTransactionPooler) RecvOfAddresses(tx *dbutil.Tx, bh coin.BlockHeader, addrs []cipher.Address) (coin.AddressUxOuts, error) {
 	ret := _m.Called(tx, bh, addrs)
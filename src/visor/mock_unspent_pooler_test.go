@@ -245,3 +245,24 @@ func (_m *MockUnspentPooler) ProcessBlock(_a0 *dbutil.Tx, _a1 *coin.SignedBlock)
 
 	return r0
 }
+
+// Stats provides a mock function with given fields: _a0
+func (_m *MockUnspentPooler) Stats(_a0 *dbutil.Tx) (blockdb.UnspentStats, error) {
+	ret := _m.Called(_a0)
+
+	var r0 blockdb.UnspentStats
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx) blockdb.UnspentStats); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(blockdb.UnspentStats)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*dbutil.Tx) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
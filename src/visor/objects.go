@@ -3,6 +3,7 @@ package visor
 import (
 	"time"
 
+	"github.com/SkycoinProject/cx-chains/src/cipher"
 	"github.com/SkycoinProject/cx-chains/src/coin"
 	"github.com/SkycoinProject/cx-chains/src/transaction"
 )
@@ -129,14 +130,17 @@ type BlockchainMetadata struct {
 	Unspents uint64
 	// Number of known unconfirmed txns
 	Unconfirmed uint64
+	// Commitment hash of the unspent outputs
+	UnspentCommitment cipher.SHA256
 }
 
 // NewBlockchainMetadata creates blockchain meta data
-func NewBlockchainMetadata(head coin.SignedBlock, unconfirmedLen, unspentsLen uint64) (*BlockchainMetadata, error) {
+func NewBlockchainMetadata(head coin.SignedBlock, unconfirmedLen, unspentsLen uint64, unspentCommitment cipher.SHA256) (*BlockchainMetadata, error) {
 	return &BlockchainMetadata{
-		HeadBlock:   head,
-		Unspents:    unspentsLen,
-		Unconfirmed: unconfirmedLen,
+		HeadBlock:         head,
+		Unspents:          unspentsLen,
+		Unconfirmed:       unconfirmedLen,
+		UnspentCommitment: unspentCommitment,
 	}, nil
 }
 
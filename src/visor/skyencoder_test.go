@@ -7,7 +7,7 @@ import (
 )
 
 func TestSkyencoderDBSafe(t *testing.T) {
-	dbFile := "../api/integration/testdata/blockchain-180.db"
+	dbFile := "./testdata/cx/blockchain-180.db"
 
 	db, err := OpenDB(dbFile, true)
 	require.NoError(t, err)
@@ -0,0 +1,181 @@
+package visor
+
+import (
+	"errors"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/visor/blockdb"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+	"github.com/SkycoinProject/cx-chains/src/wallet"
+)
+
+// ErrSnapshotReleased is returned when reading from a Snapshot after it was released
+var ErrSnapshotReleased = errors.New("Snapshot was released")
+
+// Snapshot is an immutable read view of the blockchain, pinned to a single database read transaction.
+// All of its reads see the state of the database when the snapshot was taken, regardless of the blocks
+// executed since, so that a burst of reads for a request are consistent with each other.
+// The database can't be closed while a snapshot is held, and a write that grows the database file
+// waits for the open read transactions, so a snapshot must be released with Release as soon as the reads are done.
+// A Snapshot is not safe for concurrent use, because a read transaction can't be used by multiple
+// goroutines at the same time. Take a snapshot for each request instead; the read transactions of
+// separate snapshots run concurrently.
+type Snapshot struct {
+	vs       *Visor
+	head     *coin.SignedBlock
+	tx       *dbutil.ReadTx
+	released bool
+}
+
+// Snapshot returns a read view of the blockchain at the current head block.
+// The snapshot must be released with Release.
+func (vs *Visor) Snapshot() (*Snapshot, error) {
+	tx, err := vs.db.Begin("Snapshot")
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := vs.blockchain.Head(&tx.Tx)
+	if err != nil && err != blockdb.ErrNoHeadBlock {
+		if rerr := tx.Rollback(); rerr != nil {
+			logger.WithError(rerr).Error("Snapshot: tx.Rollback failed")
+		}
+		return nil, err
+	}
+
+	return &Snapshot{
+		vs:   vs,
+		head: head,
+		tx:   tx,
+	}, nil
+}
+
+// Release ends the read transaction of the snapshot. It is safe to call more than once
+func (s *Snapshot) Release() error {
+	s.released = true
+	return s.tx.Rollback()
+}
+
+// view calls f with the read transaction of the snapshot
+func (s *Snapshot) view(f func(*dbutil.Tx) error) error {
+	if s.released {
+		return ErrSnapshotReleased
+	}
+
+	return f(&s.tx.Tx)
+}
+
+// HeadBkSeq returns the seq of the head block of the snapshot. Returns false if the blockchain was empty
+func (s *Snapshot) HeadBkSeq() (uint64, bool) {
+	if s.head == nil {
+		return 0, false
+	}
+	return s.head.Seq(), true
+}
+
+// Head returns the head block of the snapshot. Returns nil if the blockchain was empty
+func (s *Snapshot) Head() *coin.SignedBlock {
+	return s.head
+}
+
+// GetSignedBlockBySeq returns the block of the snapshot with the given seq. Returns nil if not found
+func (s *Snapshot) GetSignedBlockBySeq(seq uint64) (*coin.SignedBlock, error) {
+	var b *coin.SignedBlock
+	if err := s.view(func(tx *dbutil.Tx) error {
+		var err error
+		b, err = s.vs.blockchain.GetSignedBlockBySeq(tx, seq)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// GetUnspentOutputs returns the unspent outputs of the snapshot with the given hashes.
+// Returns an error if any of the outputs is not unspent.
+func (s *Snapshot) GetUnspentOutputs(hashes []cipher.SHA256) (coin.UxArray, error) {
+	var outputs coin.UxArray
+	if err := s.view(func(tx *dbutil.Tx) error {
+		var err error
+		outputs, err = s.vs.blockchain.Unspent().GetArray(tx, hashes)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return outputs, nil
+}
+
+// GetUnspentsOfAddrs returns the unspent outputs of the snapshot owned by the given addresses
+func (s *Snapshot) GetUnspentsOfAddrs(addrs []cipher.Address) (coin.AddressUxOuts, error) {
+	var uxa coin.AddressUxOuts
+	if err := s.view(func(tx *dbutil.Tx) error {
+		var err error
+		uxa, err = s.vs.blockchain.Unspent().GetUnspentsOfAddrs(tx, addrs)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return uxa, nil
+}
+
+// GetBalancesOfAddrs returns the balances of the given addresses in the snapshot,
+// like Visor.GetBalancesOfAddrs. Returns the head block seq of the snapshot.
+func (s *Snapshot) GetBalancesOfAddrs(addrs []cipher.Address, minConfirmations uint64) ([]AddressBalance, uint64, error) {
+	var bals []AddressBalance
+	var headSeq uint64
+	if err := s.view(func(tx *dbutil.Tx) error {
+		var err error
+		bals, headSeq, err = s.vs.getBalancesOfAddrs(tx, addrs, minConfirmations)
+		return err
+	}); err != nil {
+		return nil, 0, err
+	}
+
+	return bals, headSeq, nil
+}
+
+// GetBalanceOfAddrs returns the balance pairs of the given addresses in the snapshot, like Visor.GetBalanceOfAddrs
+func (s *Snapshot) GetBalanceOfAddrs(addrs []cipher.Address) ([]wallet.BalancePair, error) {
+	var bps []wallet.BalancePair
+	if err := s.view(func(tx *dbutil.Tx) error {
+		var err error
+		bps, err = s.vs.getBalanceOfAddrs(tx, addrs)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return bps, nil
+}
+
+// GetUnspentOutputsSummary returns the unspent outputs of the snapshot, like Visor.GetUnspentOutputsSummary
+func (s *Snapshot) GetUnspentOutputsSummary(filters []OutputsFilter) (*UnspentOutputsSummary, error) {
+	var summary *UnspentOutputsSummary
+	if err := s.view(func(tx *dbutil.Tx) error {
+		var err error
+		summary, err = s.vs.getUnspentOutputsSummary(tx, filters)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// GetBlockchainMetadata returns the blockchain metadata of the snapshot, like Visor.GetBlockchainMetadata
+func (s *Snapshot) GetBlockchainMetadata() (*BlockchainMetadata, error) {
+	var m *BlockchainMetadata
+	if err := s.view(func(tx *dbutil.Tx) error {
+		var err error
+		m, err = s.vs.getBlockchainMetadata(tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
@@ -0,0 +1,119 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/visor/historydb"
+)
+
+func TestVisorSnapshot(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolPolicy{})
+	require.NoError(t, err)
+
+	cfg := NewConfig()
+	cfg.IsBlockPublisher = true
+	cfg.BlockchainPubkey = genPublic
+	cfg.BlockchainSeckey = genSecret
+	cfg.GenesisAddress = genAddress
+
+	v := &Visor{
+		Config:      cfg,
+		unconfirmed: unconfirmed,
+		blockchain:  bc,
+		db:          db,
+		history:     historydb.New(),
+		events:      &eventPublisher{},
+	}
+
+	// A snapshot of an empty blockchain has no head block
+	s, err := v.Snapshot()
+	require.NoError(t, err)
+	_, ok := s.HeadBkSeq()
+	require.False(t, ok)
+	require.Nil(t, s.Head())
+	require.NoError(t, s.Release())
+
+	gb := addGenesisBlockToVisor(t, v)
+	genUxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+
+	s, err = v.Snapshot()
+	require.NoError(t, err)
+	defer s.Release()
+
+	// Block 1 spends the genesis output after the snapshot is taken
+	addr := cipher.AddressFromPubKey(genPublic)
+	txn := makeSpendTxn(t, genUxs, []cipher.SecKey{genSecret}, addr, 10e6)
+	_, _, _, err = v.InjectUserTransaction(txn)
+	require.NoError(t, err)
+
+	_, err = v.CreateAndExecuteBlock()
+	require.NoError(t, err)
+
+	headSeq, ok, err := v.HeadBkSeq()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, uint64(1), headSeq)
+
+	// The snapshot still sees the blockchain at the genesis block
+	headSeq, ok = s.HeadBkSeq()
+	require.True(t, ok)
+	require.Equal(t, uint64(0), headSeq)
+	require.Equal(t, *gb, *s.Head())
+
+	b, err := s.GetSignedBlockBySeq(1)
+	require.NoError(t, err)
+	require.Nil(t, b)
+
+	uxs, err := s.GetUnspentOutputs([]cipher.SHA256{genUxs[0].Hash()})
+	require.NoError(t, err)
+	require.Equal(t, genUxs, uxs)
+
+	addrUxs, err := s.GetUnspentsOfAddrs([]cipher.Address{genAddress})
+	require.NoError(t, err)
+	require.Equal(t, genUxs, addrUxs[genAddress])
+
+	bals, headSeq, err := s.GetBalancesOfAddrs([]cipher.Address{genAddress}, 1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), headSeq)
+	require.Len(t, bals, 1)
+	require.Equal(t, genUxs[0].Body.Coins, bals[0].Confirmed.Coins)
+
+	bps, err := s.GetBalanceOfAddrs([]cipher.Address{genAddress})
+	require.NoError(t, err)
+	require.Len(t, bps, 1)
+	require.Equal(t, genUxs[0].Body.Coins, bps[0].Confirmed.Coins)
+
+	summary, err := s.GetUnspentOutputsSummary(nil)
+	require.NoError(t, err)
+	require.Equal(t, *gb, *summary.HeadBlock)
+	require.Len(t, summary.Confirmed, 1)
+	require.Equal(t, genUxs[0].Hash(), summary.Confirmed[0].Hash())
+
+	metadata, err := s.GetBlockchainMetadata()
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), metadata.HeadBlock.Head.BkSeq)
+	require.Equal(t, uint64(1), metadata.Unspents)
+
+	// The genesis output is spent outside of the snapshot
+	_, err = v.GetUnspentOutputs([]cipher.SHA256{genUxs[0].Hash()})
+	require.Error(t, err)
+
+	// Reads fail after the snapshot is released
+	require.NoError(t, s.Release())
+	require.NoError(t, s.Release())
+
+	_, err = s.GetUnspentOutputs([]cipher.SHA256{genUxs[0].Hash()})
+	require.Equal(t, ErrSnapshotReleased, err)
+}
@@ -174,10 +174,14 @@ type UnconfirmedTransactionPool struct {
 	// our future balance and avoid double spending our own coins
 	// Maps from Transaction.Hash() to UxArray.
 	unspent *txnUnspents
+	// Indexes the transactions by fee per kilobyte for the pool policy
+	feeIndex *txnFeeIndex
+	// Limits on the transactions accepted into the pool
+	policy UnconfirmedPoolPolicy
 }
 
 // NewUnconfirmedTransactionPool creates an UnconfirmedTransactionPool instance
-func NewUnconfirmedTransactionPool(db *dbutil.DB) (*UnconfirmedTransactionPool, error) {
+func NewUnconfirmedTransactionPool(db *dbutil.DB, policy UnconfirmedPoolPolicy) (*UnconfirmedTransactionPool, error) {
 	if err := db.View("Check unconfirmed txn pool size", func(tx *dbutil.Tx) error {
 		n, err := dbutil.Len(tx, UnconfirmedTxnsBkt)
 		if err != nil {
@@ -191,9 +195,11 @@ func NewUnconfirmedTransactionPool(db *dbutil.DB) (*UnconfirmedTransactionPool,
 	}
 
 	return &UnconfirmedTransactionPool{
-		db:      db,
-		txns:    &unconfirmedTxns{},
-		unspent: &txnUnspents{},
+		db:       db,
+		txns:     &unconfirmedTxns{},
+		unspent:  &txnUnspents{},
+		feeIndex: &txnFeeIndex{},
+		policy:   policy,
 	}, nil
 }
 
@@ -270,6 +276,22 @@ func (utp *UnconfirmedTransactionPool) InjectTransaction(tx *dbutil.Tx, bc Block
 		return true, softErr, nil
 	}
 
+	head, err := bc.Head(tx)
+	if err != nil {
+		logger.Errorf("InjectTransaction bc.Head() failed: %v", err)
+		return false, nil, err
+	}
+
+	if err := utp.replaceConflicts(tx, bc, txn, head); err != nil {
+		logger.Warningf("InjectTransaction txn %s conflicts with the unconfirmed pool: %v", hash.Hex(), err)
+		return false, nil, err
+	}
+
+	if err := utp.applyPolicy(tx, bc, txn, head); err != nil {
+		logger.Warningf("InjectTransaction txn %s rejected by the unconfirmed pool policy: %v", hash.Hex(), err)
+		return false, nil, err
+	}
+
 	utx := NewUnconfirmedTransaction(txn)
 	utx.IsValid = isValid
 
@@ -279,9 +301,8 @@ func (utp *UnconfirmedTransactionPool) InjectTransaction(tx *dbutil.Tx, bc Block
 		return false, nil, err
 	}
 
-	head, err := bc.Head(tx)
-	if err != nil {
-		logger.Errorf("InjectTransaction bc.Head() failed: %v", err)
+	if err := utp.addToFeeIndex(tx, bc, utx, head); err != nil {
+		logger.Errorf("InjectTransaction add to fee index failed: %v", err)
 		return false, nil, err
 	}
 
@@ -315,6 +336,10 @@ func (utp *UnconfirmedTransactionPool) removeTransaction(tx *dbutil.Tx, txHash c
 		return err
 	}
 
+	if err := utp.feeIndex.delete(tx, txHash); err != nil {
+		return err
+	}
+
 	return utp.unspent.delete(tx, txHash)
 }
 
@@ -0,0 +1,169 @@
+package visor
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+var (
+	// UnconfirmedFeeIndexBkt indexes the unconfirmed transactions in eviction order.
+	// The keys are the fee per kilobyte, the inverted received time and the hash of a transaction, and the values are its size
+	UnconfirmedFeeIndexBkt = []byte("unconfirmed_fee_index")
+	// UnconfirmedFeeIndexTxnsBkt maps an unconfirmed transaction hash to its key in UnconfirmedFeeIndexBkt
+	UnconfirmedFeeIndexTxnsBkt = []byte("unconfirmed_fee_index_txns")
+	// UnconfirmedMetaBkt holds unconfirmed pool metadata
+	UnconfirmedMetaBkt = []byte("unconfirmed_meta")
+
+	// total size in bytes of the transactions in the fee index
+	unconfirmedSizeKey = []byte("size")
+
+	errStopFeeIndexIteration = errors.New("stop fee index iteration")
+)
+
+const feeIndexKeyLen = 8 + 8 + len(cipher.SHA256{})
+
+// txnFeeIndex keeps the transactions in the unconfirmed pool ordered by their fee per kilobyte,
+// and the total size of the pool, so that the pool policy does not need to walk the whole pool.
+// The fee per kilobyte of a transaction is calculated when it is added to the pool.
+type txnFeeIndex struct{}
+
+// feeIndexKey orders the transactions by fee per kilobyte, and the most recently received first for equal fees
+func feeIndexKey(hash cipher.SHA256, rate uint64, received int64) []byte {
+	if received < 0 {
+		received = 0
+	}
+
+	k := make([]byte, feeIndexKeyLen)
+	binary.BigEndian.PutUint64(k[:8], rate)
+	binary.BigEndian.PutUint64(k[8:16], uint64(math.MaxInt64-received))
+	copy(k[16:], hash[:])
+	return k
+}
+
+func (fi *txnFeeIndex) put(tx *dbutil.Tx, hash cipher.SHA256, size uint32, rate uint64, received int64) error {
+	if err := fi.delete(tx, hash); err != nil {
+		return err
+	}
+
+	k := feeIndexKey(hash, rate, received)
+	if err := dbutil.PutBucketValue(tx, UnconfirmedFeeIndexBkt, k, dbutil.Itob(uint64(size))); err != nil {
+		return err
+	}
+
+	if err := dbutil.PutBucketValue(tx, UnconfirmedFeeIndexTxnsBkt, []byte(hash.Hex()), k); err != nil {
+		return err
+	}
+
+	total, err := fi.totalSize(tx)
+	if err != nil {
+		return err
+	}
+
+	return fi.setTotalSize(tx, total+uint64(size))
+}
+
+func (fi *txnFeeIndex) delete(tx *dbutil.Tx, hash cipher.SHA256) error {
+	k, err := dbutil.GetBucketValue(tx, UnconfirmedFeeIndexTxnsBkt, []byte(hash.Hex()))
+	if err != nil {
+		return err
+	} else if k == nil {
+		return nil
+	}
+
+	v, err := dbutil.GetBucketValue(tx, UnconfirmedFeeIndexBkt, k)
+	if err != nil {
+		return err
+	}
+
+	if err := dbutil.Delete(tx, UnconfirmedFeeIndexBkt, k); err != nil {
+		return err
+	}
+
+	if err := dbutil.Delete(tx, UnconfirmedFeeIndexTxnsBkt, []byte(hash.Hex())); err != nil {
+		return err
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	total, err := fi.totalSize(tx)
+	if err != nil {
+		return err
+	}
+
+	size := dbutil.Btoi(v)
+	if size > total {
+		size = total
+	}
+
+	return fi.setTotalSize(tx, total-size)
+}
+
+// totalSize returns the total size in bytes of the indexed transactions
+func (fi *txnFeeIndex) totalSize(tx *dbutil.Tx) (uint64, error) {
+	v, err := dbutil.GetBucketValue(tx, UnconfirmedMetaBkt, unconfirmedSizeKey)
+	if err != nil {
+		return 0, err
+	} else if v == nil {
+		return 0, nil
+	}
+
+	return dbutil.Btoi(v), nil
+}
+
+func (fi *txnFeeIndex) setTotalSize(tx *dbutil.Tx, size uint64) error {
+	return dbutil.PutBucketValue(tx, UnconfirmedMetaBkt, unconfirmedSizeKey, dbutil.Itob(size))
+}
+
+// forEachLowest calls f with the indexed transactions in eviction order, the lowest fee per kilobyte first.
+// Iteration stops when f returns false or an error.
+func (fi *txnFeeIndex) forEachLowest(tx *dbutil.Tx, f func(p pooledTxnFee) (bool, error)) error {
+	err := dbutil.ForEach(tx, UnconfirmedFeeIndexBkt, func(k, v []byte) error {
+		if len(k) != feeIndexKeyLen {
+			return errors.New("invalid unconfirmed fee index key")
+		}
+
+		hash, err := cipher.SHA256FromBytes(k[16:])
+		if err != nil {
+			return err
+		}
+
+		more, err := f(pooledTxnFee{
+			hash:     hash,
+			size:     uint32(dbutil.Btoi(v)),
+			feePerKB: binary.BigEndian.Uint64(k[:8]),
+			received: math.MaxInt64 - int64(binary.BigEndian.Uint64(k[8:16])),
+		})
+		if err != nil {
+			return err
+		}
+
+		if !more {
+			return errStopFeeIndexIteration
+		}
+
+		return nil
+	})
+
+	if err == errStopFeeIndexIteration {
+		return nil
+	}
+
+	return err
+}
+
+// reset removes all transactions from the index
+func (fi *txnFeeIndex) reset(tx *dbutil.Tx) error {
+	for _, b := range [][]byte{UnconfirmedFeeIndexBkt, UnconfirmedFeeIndexTxnsBkt} {
+		if err := dbutil.Reset(tx, b); err != nil {
+			return err
+		}
+	}
+
+	return fi.setTotalSize(tx, 0)
+}
@@ -0,0 +1,326 @@
+package visor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/util/fee"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+var (
+	// ErrTxnBelowMinFee is returned if a transaction's fee per kilobyte is below the unconfirmed pool minimum fee
+	ErrTxnBelowMinFee = errors.New("Transaction fee per kilobyte is below the unconfirmed pool minimum fee")
+	// ErrUnconfirmedPoolFull is returned if the unconfirmed pool is full and the transaction's fee
+	// is not high enough to evict pooled transactions
+	ErrUnconfirmedPoolFull = errors.New("Unconfirmed pool is full and the transaction fee per kilobyte is too low to evict pooled transactions")
+)
+
+// ErrTxnConflict is returned when a transaction spends the same inputs as transactions in the unconfirmed pool,
+// and its fee is not high enough to replace them
+type ErrTxnConflict struct {
+	Txids []cipher.SHA256
+}
+
+// NewErrTxnConflict creates ErrTxnConflict
+func NewErrTxnConflict(txids []cipher.SHA256) error {
+	return ErrTxnConflict{
+		Txids: txids,
+	}
+}
+
+func (e ErrTxnConflict) Error() string {
+	txids := make([]string, len(e.Txids))
+	for i, h := range e.Txids {
+		txids[i] = h.Hex()
+	}
+	return fmt.Sprintf("Transaction spends the same inputs as unconfirmed transaction %s and its fee is too low to replace it", strings.Join(txids, ","))
+}
+
+// ErrTxnViolatesPoolPolicy is returned when a transaction is rejected by the UnconfirmedPoolPolicy
+type ErrTxnViolatesPoolPolicy struct {
+	Err error
+}
+
+// NewErrTxnViolatesPoolPolicy creates ErrTxnViolatesPoolPolicy
+func NewErrTxnViolatesPoolPolicy(err error) error {
+	if err == nil {
+		return nil
+	}
+	return ErrTxnViolatesPoolPolicy{
+		Err: err,
+	}
+}
+
+func (e ErrTxnViolatesPoolPolicy) Error() string {
+	return fmt.Sprintf("Transaction violates unconfirmed pool policy: %v", e.Err)
+}
+
+// UnconfirmedPoolPolicy limits the transactions accepted into the unconfirmed pool
+type UnconfirmedPoolPolicy struct {
+	// MaxSize is the maximum total size in bytes of the transactions in the pool. 0 means no limit.
+	// When a new transaction does not fit, the transactions with the lowest fee per kilobyte are evicted,
+	// if their fee per kilobyte is lower than the new transaction's.
+	MaxSize uint64
+	// MinFeePerKB is the minimum fee, in coin hours per 1000 bytes, of a transaction accepted into the pool.
+	// 0 means no minimum.
+	MinFeePerKB uint64
+}
+
+// feePerKB returns the fee in coin hours per 1000 bytes of a transaction
+func feePerKB(fee uint64, size uint32) uint64 {
+	if size == 0 {
+		return 0
+	}
+	if fee > math.MaxUint64/1000 {
+		r := fee / uint64(size)
+		if r > math.MaxUint64/1000 {
+			return math.MaxUint64
+		}
+		return r * 1000
+	}
+	return fee * 1000 / uint64(size)
+}
+
+// pooledTxnFee is the size and fee per kilobyte of a transaction in the unconfirmed pool fee index
+type pooledTxnFee struct {
+	hash     cipher.SHA256
+	size     uint32
+	feePerKB uint64
+	received int64
+}
+
+// txnFee returns the size and the fee of txn at the head block time.
+// A transaction whose inputs are not in the unspent pool has no fee.
+func txnFee(tx *dbutil.Tx, bc Blockchainer, txn coin.Transaction, headTime uint64) (uint32, uint64, error) {
+	size, err := txn.Size()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	uxIn, err := bc.Unspent().GetArray(tx, txn.In)
+	if err != nil {
+		return size, 0, nil
+	}
+
+	f, err := fee.TransactionFee(&txn, headTime, uxIn)
+	if err != nil {
+		return size, 0, nil
+	}
+
+	return size, f, nil
+}
+
+// txnFeePerKB returns the size and the fee per kilobyte of txn at the head block time
+func txnFeePerKB(tx *dbutil.Tx, bc Blockchainer, txn coin.Transaction, headTime uint64) (uint32, uint64, error) {
+	size, f, err := txnFee(tx, bc, txn, headTime)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return size, feePerKB(f, size), nil
+}
+
+// applyPolicy checks txn against the pool policy before it is added to the pool.
+// If the pool is full, transactions with a lower fee per kilobyte are evicted to make room for txn.
+func (utp *UnconfirmedTransactionPool) applyPolicy(tx *dbutil.Tx, bc Blockchainer, txn coin.Transaction, head *coin.SignedBlock) error {
+	if utp.policy.MinFeePerKB == 0 && utp.policy.MaxSize == 0 {
+		return nil
+	}
+
+	size, rate, err := txnFeePerKB(tx, bc, txn, head.Time())
+	if err != nil {
+		return err
+	}
+
+	if rate < utp.policy.MinFeePerKB {
+		return NewErrTxnViolatesPoolPolicy(ErrTxnBelowMinFee)
+	}
+
+	if utp.policy.MaxSize == 0 {
+		return nil
+	}
+
+	if uint64(size) > utp.policy.MaxSize {
+		return NewErrTxnViolatesPoolPolicy(ErrUnconfirmedPoolFull)
+	}
+
+	total, err := utp.feeIndex.totalSize(tx)
+	if err != nil {
+		return err
+	}
+
+	if total+uint64(size) <= utp.policy.MaxSize {
+		return nil
+	}
+
+	// The fee per kilobyte of the pooled transactions is the one calculated when they were added to the pool
+	var evict []cipher.SHA256
+	errFull := NewErrTxnViolatesPoolPolicy(ErrUnconfirmedPoolFull)
+	if err := utp.feeIndex.forEachLowest(tx, func(p pooledTxnFee) (bool, error) {
+		if total+uint64(size) <= utp.policy.MaxSize {
+			return false, nil
+		}
+
+		if p.feePerKB >= rate {
+			return false, errFull
+		}
+
+		evict = append(evict, p.hash)
+		total -= uint64(p.size)
+		return true, nil
+	}); err != nil {
+		return err
+	}
+
+	if total+uint64(size) > utp.policy.MaxSize {
+		return errFull
+	}
+
+	if err := utp.RemoveTransactions(tx, evict); err != nil {
+		return err
+	}
+
+	logger.Infof("Evicted %d txns from the full unconfirmed pool for txn %s", len(evict), txn.Hash().Hex())
+
+	return nil
+}
+
+// addToFeeIndex adds txn to the fee index with its fee per kilobyte at the head block time
+func (utp *UnconfirmedTransactionPool) addToFeeIndex(tx *dbutil.Tx, bc Blockchainer, utxn UnconfirmedTransaction, head *coin.SignedBlock) error {
+	size, rate, err := txnFeePerKB(tx, bc, utxn.Transaction, head.Time())
+	if err != nil {
+		return err
+	}
+
+	return utp.feeIndex.put(tx, utxn.Transaction.Hash(), size, rate, utxn.Received)
+}
+
+// buildFeeIndex rebuilds the fee index from the transactions in the pool, with their fees per kilobyte at the head block time
+func (utp *UnconfirmedTransactionPool) buildFeeIndex(tx *dbutil.Tx, bc Blockchainer, head *coin.SignedBlock) error {
+	if err := utp.feeIndex.reset(tx); err != nil {
+		return err
+	}
+
+	return utp.txns.forEach(tx, func(_ cipher.SHA256, utxn UnconfirmedTransaction) error {
+		return utp.addToFeeIndex(tx, bc, utxn, head)
+	})
+}
+
+// EnforcePolicy removes the transactions in the pool that violate the pool policy, and returns their hashes.
+// The policy is applied as transactions are injected, but a pool reloaded from the db on startup
+// violates it if the policy was made stricter since the transactions were injected.
+// Transactions below MinFeePerKB are removed, then the transactions with the lowest fee per kilobyte
+// are evicted until the pool fits in MaxSize.
+// The fee index is rebuilt first, since the pool may have been persisted without it
+// and the fees of the pooled transactions change with the head block time.
+func (utp *UnconfirmedTransactionPool) EnforcePolicy(tx *dbutil.Tx, bc Blockchainer) ([]cipher.SHA256, error) {
+	head, err := bc.Head(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := utp.buildFeeIndex(tx, bc, head); err != nil {
+		return nil, err
+	}
+
+	if utp.policy.MinFeePerKB == 0 && utp.policy.MaxSize == 0 {
+		return nil, nil
+	}
+
+	total, err := utp.feeIndex.totalSize(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []cipher.SHA256
+	if err := utp.feeIndex.forEachLowest(tx, func(p pooledTxnFee) (bool, error) {
+		overSize := utp.policy.MaxSize != 0 && total > utp.policy.MaxSize
+		if p.feePerKB >= utp.policy.MinFeePerKB && !overSize {
+			return false, nil
+		}
+
+		removed = append(removed, p.hash)
+		total -= uint64(p.size)
+		return true, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if len(removed) == 0 {
+		return nil, nil
+	}
+
+	if err := utp.RemoveTransactions(tx, removed); err != nil {
+		return nil, err
+	}
+
+	return removed, nil
+}
+
+// replaceConflicts removes the transactions in the pool that spend any of txn's inputs, if txn may replace them.
+// txn may replace the conflicting transactions if its fee is higher than their total fee,
+// and its fee per kilobyte is higher than each of theirs.
+// Since the conflicting transactions spend the same inputs, only the owner of those inputs
+// can sign a replacement; any other conflict is a double spend attempt and is rejected with ErrTxnConflict.
+func (utp *UnconfirmedTransactionPool) replaceConflicts(tx *dbutil.Tx, bc Blockchainer, txn coin.Transaction, head *coin.SignedBlock) error {
+	inputs := make(map[cipher.SHA256]struct{}, len(txn.In))
+	for _, in := range txn.In {
+		inputs[in] = struct{}{}
+	}
+
+	var conflicts []cipher.SHA256
+	var conflictFee, conflictMaxRate uint64
+	if err := utp.txns.forEach(tx, func(hash cipher.SHA256, utxn UnconfirmedTransaction) error {
+		conflict := false
+		for _, in := range utxn.Transaction.In {
+			if _, ok := inputs[in]; ok {
+				conflict = true
+				break
+			}
+		}
+
+		if !conflict {
+			return nil
+		}
+
+		size, f, err := txnFee(tx, bc, utxn.Transaction, head.Time())
+		if err != nil {
+			return err
+		}
+
+		conflicts = append(conflicts, hash)
+		conflictFee += f
+		if rate := feePerKB(f, size); rate > conflictMaxRate {
+			conflictMaxRate = rate
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	size, f, err := txnFee(tx, bc, txn, head.Time())
+	if err != nil {
+		return err
+	}
+
+	if f <= conflictFee || feePerKB(f, size) <= conflictMaxRate {
+		return NewErrTxnConflict(conflicts)
+	}
+
+	if err := utp.RemoveTransactions(tx, conflicts); err != nil {
+		return err
+	}
+
+	logger.Infof("Replaced %d conflicting unconfirmed txns with txn %s", len(conflicts), txn.Hash().Hex())
+
+	return nil
+}
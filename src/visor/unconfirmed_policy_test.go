@@ -0,0 +1,370 @@
+package visor
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
+)
+
+func TestFeePerKB(t *testing.T) {
+	require.Equal(t, uint64(0), feePerKB(100, 0))
+	require.Equal(t, uint64(1000), feePerKB(100, 100))
+	require.Equal(t, uint64(333), feePerKB(1, 3))
+	require.Equal(t, uint64(math.MaxUint64), feePerKB(math.MaxUint64, 1))
+	require.Equal(t, uint64(math.MaxUint64/1000*1000), feePerKB(math.MaxUint64, 1000))
+}
+
+// makePolicyTxn makes a transaction spending an output with inHours, and creating an output with outHours
+func makePolicyTxn(t *testing.T, headTime, inHours, outHours uint64) (coin.Transaction, coin.UxOut) {
+	ux := coin.UxOut{
+		Head: coin.UxHead{
+			Time: headTime,
+		},
+		Body: coin.UxBody{
+			SrcTransaction: testutil.RandSHA256(t),
+			Address:        testutil.MakeAddress(),
+			Coins:          1e6,
+			Hours:          inHours,
+		},
+	}
+
+	txn := coin.Transaction{}
+	err := txn.PushInput(ux.Hash())
+	require.NoError(t, err)
+	err = txn.PushOutput(testutil.MakeAddress(), 1e6, outHours, nil)
+	require.NoError(t, err)
+	txn.Sigs = make([]cipher.Sig, 1)
+	err = txn.UpdateHeader()
+	require.NoError(t, err)
+
+	return txn, ux
+}
+
+func TestUnconfirmedPoolApplyPolicy(t *testing.T) {
+	var headTime uint64 = 1000
+	head := &coin.SignedBlock{
+		Block: coin.Block{
+			Head: coin.BlockHeader{
+				Time: headTime,
+			},
+		},
+	}
+
+	// All transactions have the same size, so their fee per kilobyte is ordered by their fee
+	lowTxn, lowUx := makePolicyTxn(t, headTime, 100, 90)
+	midTxn, midUx := makePolicyTxn(t, headTime, 100, 50)
+	highTxn, highUx := makePolicyTxn(t, headTime, 100, 0)
+
+	size, err := lowTxn.Size()
+	require.NoError(t, err)
+	txnSize := uint64(size)
+
+	tt := []struct {
+		name   string
+		policy UnconfirmedPoolPolicy
+		pooled []coin.Transaction
+		txn    coin.Transaction
+		err    error
+		remain []coin.Transaction
+	}{
+		{
+			name:   "no policy",
+			pooled: []coin.Transaction{lowTxn, midTxn},
+			txn:    highTxn,
+			remain: []coin.Transaction{lowTxn, midTxn},
+		},
+		{
+			name: "below min fee",
+			policy: UnconfirmedPoolPolicy{
+				MinFeePerKB: feePerKB(20, size),
+			},
+			txn: lowTxn,
+			err: NewErrTxnViolatesPoolPolicy(ErrTxnBelowMinFee),
+		},
+		{
+			name: "above min fee",
+			policy: UnconfirmedPoolPolicy{
+				MinFeePerKB: feePerKB(20, size),
+			},
+			txn: midTxn,
+		},
+		{
+			name: "pool not full",
+			policy: UnconfirmedPoolPolicy{
+				MaxSize: txnSize * 3,
+			},
+			pooled: []coin.Transaction{lowTxn, midTxn},
+			txn:    highTxn,
+			remain: []coin.Transaction{lowTxn, midTxn},
+		},
+		{
+			name: "pool full, evicts lowest fee",
+			policy: UnconfirmedPoolPolicy{
+				MaxSize: txnSize * 2,
+			},
+			pooled: []coin.Transaction{lowTxn, midTxn},
+			txn:    highTxn,
+			remain: []coin.Transaction{midTxn},
+		},
+		{
+			name: "pool full, fee too low to evict",
+			policy: UnconfirmedPoolPolicy{
+				MaxSize: txnSize * 2,
+			},
+			pooled: []coin.Transaction{midTxn, highTxn},
+			txn:    lowTxn,
+			err:    NewErrTxnViolatesPoolPolicy(ErrUnconfirmedPoolFull),
+			remain: []coin.Transaction{midTxn, highTxn},
+		},
+		{
+			name: "txn larger than pool",
+			policy: UnconfirmedPoolPolicy{
+				MaxSize: txnSize - 1,
+			},
+			txn: highTxn,
+			err: NewErrTxnViolatesPoolPolicy(ErrUnconfirmedPoolFull),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			db, shutdown := testutil.PrepareDB(t)
+			defer shutdown()
+
+			err := CreateBuckets(db)
+			require.NoError(t, err)
+
+			up := &MockUnspentPooler{}
+			for _, ux := range []coin.UxOut{lowUx, midUx, highUx} {
+				up.On("GetArray", mock.Anything, []cipher.SHA256{ux.Hash()}).Return(coin.UxArray{ux}, nil)
+			}
+
+			bc := &MockBlockchainer{}
+			bc.On("Unspent").Return(up)
+
+			utp, err := NewUnconfirmedTransactionPool(db, tc.policy)
+			require.NoError(t, err)
+
+			err = db.Update("", func(tx *dbutil.Tx) error {
+				for _, txn := range tc.pooled {
+					utx := NewUnconfirmedTransaction(txn)
+					if err := utp.txns.put(tx, &utx); err != nil {
+						return err
+					}
+					if err := utp.addToFeeIndex(tx, bc, utx, head); err != nil {
+						return err
+					}
+				}
+
+				return utp.applyPolicy(tx, bc, tc.txn, head)
+			})
+			require.Equal(t, tc.err, err)
+
+			if tc.err != nil {
+				return
+			}
+
+			err = db.View("", func(tx *dbutil.Tx) error {
+				txns, err := utp.AllRawTransactions(tx)
+				require.NoError(t, err)
+				require.Len(t, txns, len(tc.remain))
+				for _, txn := range tc.remain {
+					known, err := utp.txns.hasKey(tx, txn.Hash())
+					require.NoError(t, err)
+					require.True(t, known)
+				}
+
+				total, err := utp.feeIndex.totalSize(tx)
+				require.NoError(t, err)
+				require.Equal(t, uint64(len(tc.remain))*txnSize, total)
+				return nil
+			})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestUnconfirmedFeeIndex(t *testing.T) {
+	db, shutdown := testutil.PrepareDB(t)
+	defer shutdown()
+
+	err := CreateBuckets(db)
+	require.NoError(t, err)
+
+	fi := &txnFeeIndex{}
+	h1 := testutil.RandSHA256(t)
+	h2 := testutil.RandSHA256(t)
+	h3 := testutil.RandSHA256(t)
+	h4 := testutil.RandSHA256(t)
+
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		require.NoError(t, fi.put(tx, h1, 100, 50, 1))
+		require.NoError(t, fi.put(tx, h2, 200, 10, 1))
+		// Equal fee per kilobyte, the most recently received is evicted first
+		require.NoError(t, fi.put(tx, h3, 300, 50, 2))
+		require.NoError(t, fi.put(tx, h4, 400, 90, 1))
+
+		// Putting an indexed transaction again replaces its entry
+		require.NoError(t, fi.put(tx, h4, 400, 90, 1))
+
+		total, err := fi.totalSize(tx)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1000), total)
+
+		var order []cipher.SHA256
+		err = fi.forEachLowest(tx, func(p pooledTxnFee) (bool, error) {
+			order = append(order, p.hash)
+			return true, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []cipher.SHA256{h2, h3, h1, h4}, order)
+
+		// Iteration stops when f returns false
+		order = nil
+		err = fi.forEachLowest(tx, func(p pooledTxnFee) (bool, error) {
+			order = append(order, p.hash)
+			return len(order) < 2, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []cipher.SHA256{h2, h3}, order)
+
+		require.NoError(t, fi.delete(tx, h3))
+		// Deleting a transaction that is not indexed is a no-op
+		require.NoError(t, fi.delete(tx, h3))
+
+		total, err = fi.totalSize(tx)
+		require.NoError(t, err)
+		require.Equal(t, uint64(700), total)
+
+		require.NoError(t, fi.reset(tx))
+
+		total, err = fi.totalSize(tx)
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), total)
+
+		err = fi.forEachLowest(tx, func(p pooledTxnFee) (bool, error) {
+			t.Fatalf("unexpected indexed txn %s", p.hash.Hex())
+			return false, nil
+		})
+		require.NoError(t, err)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestUnconfirmedPoolReplaceConflicts(t *testing.T) {
+	var headTime uint64 = 1000
+	head := &coin.SignedBlock{
+		Block: coin.Block{
+			Head: coin.BlockHeader{
+				Time: headTime,
+			},
+		},
+	}
+
+	lowTxn, ux := makePolicyTxn(t, headTime, 100, 90)
+	otherTxn, otherUx := makePolicyTxn(t, headTime, 100, 90)
+
+	// Transactions spending the same input with the same and a higher fee
+	sameTxn := coin.Transaction{}
+	err := sameTxn.PushInput(ux.Hash())
+	require.NoError(t, err)
+	err = sameTxn.PushOutput(testutil.MakeAddress(), 1e6, 90, nil)
+	require.NoError(t, err)
+	sameTxn.Sigs = make([]cipher.Sig, 1)
+	err = sameTxn.UpdateHeader()
+	require.NoError(t, err)
+
+	highTxn := coin.Transaction{}
+	err = highTxn.PushInput(ux.Hash())
+	require.NoError(t, err)
+	err = highTxn.PushOutput(testutil.MakeAddress(), 1e6, 10, nil)
+	require.NoError(t, err)
+	highTxn.Sigs = make([]cipher.Sig, 1)
+	err = highTxn.UpdateHeader()
+	require.NoError(t, err)
+
+	tt := []struct {
+		name   string
+		pooled []coin.Transaction
+		txn    coin.Transaction
+		err    error
+		remain []coin.Transaction
+	}{
+		{
+			name:   "no conflict",
+			pooled: []coin.Transaction{otherTxn},
+			txn:    lowTxn,
+			remain: []coin.Transaction{otherTxn},
+		},
+		{
+			name:   "conflict, fee not higher",
+			pooled: []coin.Transaction{lowTxn, otherTxn},
+			txn:    sameTxn,
+			err:    NewErrTxnConflict([]cipher.SHA256{lowTxn.Hash()}),
+		},
+		{
+			name:   "conflict replaced by higher fee",
+			pooled: []coin.Transaction{lowTxn, otherTxn},
+			txn:    highTxn,
+			remain: []coin.Transaction{otherTxn},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			db, shutdown := testutil.PrepareDB(t)
+			defer shutdown()
+
+			err := CreateBuckets(db)
+			require.NoError(t, err)
+
+			up := &MockUnspentPooler{}
+			for _, ux := range []coin.UxOut{ux, otherUx} {
+				up.On("GetArray", mock.Anything, []cipher.SHA256{ux.Hash()}).Return(coin.UxArray{ux}, nil)
+			}
+
+			bc := &MockBlockchainer{}
+			bc.On("Unspent").Return(up)
+
+			utp, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolPolicy{})
+			require.NoError(t, err)
+
+			err = db.Update("", func(tx *dbutil.Tx) error {
+				for _, txn := range tc.pooled {
+					utx := NewUnconfirmedTransaction(txn)
+					if err := utp.txns.put(tx, &utx); err != nil {
+						return err
+					}
+				}
+
+				return utp.replaceConflicts(tx, bc, tc.txn, head)
+			})
+			require.Equal(t, tc.err, err)
+
+			if tc.err != nil {
+				return
+			}
+
+			err = db.View("", func(tx *dbutil.Tx) error {
+				txns, err := utp.AllRawTransactions(tx)
+				require.NoError(t, err)
+				require.Len(t, txns, len(tc.remain))
+				for _, txn := range tc.remain {
+					known, err := utp.txns.hasKey(tx, txn.Hash())
+					require.NoError(t, err)
+					require.True(t, known)
+				}
+				return nil
+			})
+			require.NoError(t, err)
+		})
+	}
+}
@@ -15,7 +15,7 @@ func encodeSizeUxArray(obj *UxArray) uint64 {
 
 	// obj.UxArray
 	i0 += 4
-	{
+	for _, x := range obj.UxArray {
 		i1 := uint64(0)
 
 		// x.Head.Time
@@ -39,11 +39,10 @@ func encodeSizeUxArray(obj *UxArray) uint64 {
 		// x.Body.Hours
 		i1 += 8
 
-		// WARNING: this whole entry was manually added
-		// WARNING: This is not considering program states in different `Out`s with different lengths
-		i1 += 4 + uint64(len(obj.UxArray[0].Body.ProgramState))
+		// x.Body.ProgramState
+		i1 += 4 + uint64(len(x.Body.ProgramState))
 
-		i0 += uint64(len(obj.UxArray)) * i1
+		i0 += i1
 	}
 
 	return i0
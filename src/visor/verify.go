@@ -72,6 +72,9 @@ var (
 	ErrTxnExceedsMaxBlockSize = errors.New("Transaction size bigger than max block size")
 	// ErrTxnIsLocked transaction has locked address inputs
 	ErrTxnIsLocked = errors.New("Transaction has locked address inputs")
+	// ErrMultisigNotActive transaction is a multisig transaction or has outputs to multisig addresses,
+	// and would be included in a block before params.MultisigActivationSeq
+	ErrMultisigNotActive = errors.New("Multisig transactions are not active")
 )
 
 // TxnSignedFlag indicates if the transaction is unsigned or not
@@ -82,6 +85,9 @@ const (
 	TxnSigned TxnSignedFlag = 1
 	// TxnUnsigned is used for unsigned transactions
 	TxnUnsigned TxnSignedFlag = 2
+	// txnSignedCheckpointed is used for the signed transactions of blocks up to the latest checkpoint.
+	// The transactions must be signed, but their input signatures are not verified.
+	txnSignedCheckpointed TxnSignedFlag = 3
 )
 
 // ErrTxnViolatesHardConstraint is returned when a transaction violates hard constraints
@@ -246,7 +252,11 @@ func VerifySingleTxnHardConstraints(txn coin.Transaction, head coin.BlockHeader,
 // NOTE: output hours overflow is treated as a soft constraint for transactions inside of a block, due to a bug
 //       which allowed some blocks to be published with overflowing output hours.
 func VerifyBlockTxnConstraints(txn coin.Transaction, head coin.BlockHeader, uxIn coin.UxArray) error {
-	if err := verifyTxnHardConstraints(txn, head, uxIn, TxnSigned); err != nil {
+	return verifyBlockTxnConstraints(txn, head, uxIn, TxnSigned)
+}
+
+func verifyBlockTxnConstraints(txn coin.Transaction, head coin.BlockHeader, uxIn coin.UxArray, signed TxnSignedFlag) error {
+	if err := verifyTxnHardConstraints(txn, head, uxIn, signed); err != nil {
 		return NewErrTxnViolatesHardConstraint(err)
 	}
 
@@ -285,6 +295,11 @@ func verifyTxnHardConstraints(txn coin.Transaction, head coin.BlockHeader, uxIn
 		if err := txn.VerifyInputSignatures(uxIn); err != nil {
 			return err
 		}
+	case txnSignedCheckpointed:
+		// The block is known-good, skip verifying that the signatures are allowed to spend inputs
+		if err := txn.Verify(); err != nil {
+			return err
+		}
 	case TxnUnsigned:
 		if err := txn.VerifyUnsigned(); err != nil {
 			return err
@@ -298,6 +313,13 @@ func verifyTxnHardConstraints(txn coin.Transaction, head coin.BlockHeader, uxIn
 		logger.Panic("Invalid TxnSignedFlag")
 	}
 
+	// Multisig transactions and outputs to multisig addresses are a hard fork,
+	// allowed from the block with seq params.MultisigActivationSeq.
+	// head is the block before the one which would include the transaction
+	if txn.UsesMultisig() && !params.MultisigActive(head.BkSeq+1) {
+		return ErrMultisigNotActive
+	}
+
 	uxOut := coin.CreateUnspents(head, txn)
 
 	// Check that there are any duplicates within this set
@@ -13,7 +13,9 @@ package visor
 import (
 	"errors"
 	"fmt"
+	"io"
 	"sort"
+	"sync"
 
 	"time"
 
@@ -29,10 +31,14 @@ import (
 	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
 	"github.com/SkycoinProject/cx-chains/src/visor/historydb"
 	"github.com/SkycoinProject/cx-chains/src/wallet"
+	"github.com/SkycoinProject/cx-chains/src/wallet/hardware"
 )
 
 var logger = logging.MustGetLogger("visor")
 
+// ErrVisorShutdown is returned when a block or transaction is submitted to a visor that is shut down
+var ErrVisorShutdown = errors.New("Visor is shut down")
+
 // Visor manages the blockchain
 type Visor struct {
 	Config Config
@@ -43,6 +49,16 @@ type Visor struct {
 	blockchain  Blockchainer
 	history     Historyer
 	wallets     *wallet.Service
+	hwDevice    hardware.Device
+	events      *eventPublisher
+	hooks       hooks
+	// feeEstimator caches the fees of the recent blocks for GetFeeEstimate
+	feeEstimator feeEstimator
+
+	// shutdownLock is held for reading while blocks and transactions are written,
+	// so that Shutdown waits for the writes in progress
+	shutdownLock sync.RWMutex
+	shutdown     bool
 }
 
 // New creates a Visor for managing the blockchain database
@@ -63,6 +79,18 @@ func New(c Config, db *dbutil.DB, wltServ *wallet.Service) (*Visor, error) {
 	logger.Infof("Max transaction size for transactions when creating blocks is %d", c.CreateBlockVerifyTxn.MaxTransactionSize)
 	logger.Infof("Max decimals for transactions when creating blocks is %d", c.CreateBlockVerifyTxn.MaxDropletPrecision)
 	logger.Infof("Max block size is %d", c.MaxBlockTransactionsSize)
+	if c.IsBlockPublisher {
+		logger.Infof("Block policy: max %d transactions, %s ordering, %d allowed and %d denied addresses",
+			c.BlockPolicy.maxTransactions(), c.BlockPolicy.ordering(), len(c.BlockPolicy.AllowAddresses), len(c.BlockPolicy.DenyAddresses))
+	}
+	if c.Devnet.Enabled {
+		logger.Infof("Running a devnet with genesis address %s and %d funded addresses", c.GenesisAddress, len(c.Devnet.Addresses))
+		for _, a := range c.Devnet.Addresses {
+			logger.Infof("Devnet funded address %s", a)
+		}
+	}
+	logger.Infof("Max unconfirmed pool size is %d bytes", c.UnconfirmedPolicy.MaxSize)
+	logger.Infof("Min fee per kilobyte for unconfirmed transactions is %d", c.UnconfirmedPolicy.MinFeePerKB)
 
 	if !db.IsReadOnly() {
 		if err := CreateBuckets(db); err != nil {
@@ -72,17 +100,47 @@ func New(c Config, db *dbutil.DB, wltServ *wallet.Service) (*Visor, error) {
 	}
 
 	bc, err := NewBlockchain(db, BlockchainConfig{
-		Pubkey:      c.BlockchainPubkey,
-		Arbitrating: c.Arbitrating,
+		Pubkey:                       c.BlockchainPubkey,
+		Arbitrating:                  c.Arbitrating,
+		Chain:                        c.Chain,
+		GenesisHash:                  c.GenesisHash,
+		Checkpoints:                  c.Checkpoints,
+		VerifyCheckpointedSignatures: c.VerifyCheckpointedSignatures,
+		BlockCacheSize:               c.BlockCacheSize,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	logger.Infof("Chain is %q", bc.Chain())
+
+	if db.IsReadOnly() {
+		err = db.View("verify chain", bc.VerifyChain)
+	} else {
+		err = db.Update("init chain", bc.InitChain)
+	}
+	if err != nil {
+		logger.WithError(err).Error("The database does not belong to the chain")
+		return nil, err
+	}
+
+	if cp, ok := bc.checkpoints.latest(); ok {
+		logger.Infof("Latest checkpoint is block %d %s", cp.Seq, cp.Hash.Hex())
+	}
+
+	if err := db.View("verify checkpoints", bc.VerifyCheckpoints); err != nil {
+		logger.WithError(err).Error("The blockchain in the database does not match the checkpoints")
+		return nil, err
+	}
+
 	history := historydb.New()
 
 	if !db.IsReadOnly() {
-		if err := db.Update("build unspent indexes and init history", func(tx *dbutil.Tx) error {
+		if err := db.Update("build indexes and init history", func(tx *dbutil.Tx) error {
+			if err := blockdb.Migrate(tx, blockdb.Migrations); err != nil {
+				return err
+			}
+
 			headSeq, _, err := bc.HeadSeq(tx)
 			if err != nil {
 				return err
@@ -92,13 +150,61 @@ func New(c Config, db *dbutil.DB, wltServ *wallet.Service) (*Visor, error) {
 				return err
 			}
 
+			if err := bc.MaybeBuildHistoryIndex(tx); err != nil {
+				return err
+			}
+
+			if err := bc.MaybeBuildTxnIndex(tx); err != nil {
+				return err
+			}
+
+			if err := bc.MaybeBuildBalanceIndex(tx); err != nil {
+				return err
+			}
+
+			if err := bc.MaybeBuildUxDeltaIndex(tx); err != nil {
+				return err
+			}
+
+			if err := bc.MaybeBuildProgramStateIndex(tx); err != nil {
+				return err
+			}
+
 			return initHistory(tx, bc, history)
 		}); err != nil {
 			return nil, err
 		}
+	} else {
+		if err := db.View("check blockdb schema version", func(tx *dbutil.Tx) error {
+			version, err := blockdb.SchemaVersion(tx)
+			if err != nil {
+				return err
+			}
+
+			latest := blockdb.LatestSchemaVersion(blockdb.Migrations)
+			if version > latest {
+				return blockdb.ErrSchemaVersionUnsupported{
+					Version:       version,
+					LatestVersion: latest,
+				}
+			} else if version < latest {
+				logger.Warningf("blockdb schema version %d is older than %d, it will be migrated when the db is opened read-write", version, latest)
+			}
+
+			// The indexes can't be built on a read-only db. Refuse to serve them if they don't match the
+			// blocks, for example if the db was last written by a version without an index
+			if err := bc.CheckIndexes(tx); err != nil {
+				logger.WithError(err).Error("Open the db read-write once to build the indexes")
+				return err
+			}
+
+			return nil
+		}); err != nil {
+			return nil, err
+		}
 	}
 
-	utp, err := NewUnconfirmedTransactionPool(db)
+	utp, err := NewUnconfirmedTransactionPool(db, c.UnconfirmedPolicy)
 	if err != nil {
 		return nil, err
 	}
@@ -111,6 +217,7 @@ func New(c Config, db *dbutil.DB, wltServ *wallet.Service) (*Visor, error) {
 		unconfirmed: utp,
 		history:     history,
 		wallets:     wltServ,
+		events:      &eventPublisher{},
 	}
 
 	return v, nil
@@ -121,6 +228,51 @@ func (vs *Visor) VisorConfig() Config {
 	return vs.Config
 }
 
+// IsReadOnly returns true if the database is opened read-only.
+// A read-only visor cannot execute blocks or inject transactions.
+func (vs *Visor) IsReadOnly() bool {
+	return vs.db.IsReadOnly()
+}
+
+// SetHardwareDevice sets the hardware wallet device used to sign transactions of hardware wallets.
+// It must be called before the visor is used, since it is not safe for concurrent use.
+func (vs *Visor) SetHardwareDevice(d hardware.Device) {
+	vs.hwDevice = d
+}
+
+// Shutdown stops the visor from executing blocks and injecting transactions.
+// It waits for the writes in progress, then flushes the database to disk so that the
+// blockchain and the unconfirmed pool are persisted before the database is closed.
+func (vs *Visor) Shutdown() error {
+	vs.shutdownLock.Lock()
+	defer vs.shutdownLock.Unlock()
+
+	if vs.shutdown {
+		return nil
+	}
+	vs.shutdown = true
+
+	logger.Info("Visor shutdown")
+
+	if vs.db.IsReadOnly() {
+		return nil
+	}
+
+	return vs.db.Sync()
+}
+
+// acceptWrite returns ErrVisorShutdown if the visor is shut down.
+// Otherwise, Shutdown is blocked until the returned func is called.
+func (vs *Visor) acceptWrite() (func(), error) {
+	vs.shutdownLock.RLock()
+	if vs.shutdown {
+		vs.shutdownLock.RUnlock()
+		return nil, ErrVisorShutdown
+	}
+
+	return vs.shutdownLock.RUnlock, nil
+}
+
 // Init initializes starts the visor
 func (vs *Visor) Init() error {
 	logger.Info("Visor init")
@@ -134,12 +286,37 @@ func (vs *Visor) Init() error {
 			return err
 		}
 
+		if err := vs.maybeFundDevnetAddresses(tx); err != nil {
+			return err
+		}
+
+		// The unconfirmed pool is persisted in the db as transactions are injected,
+		// so it survives restarts. Revalidate the reloaded transactions against the blockchain.
+		n, err := vs.unconfirmed.Len(tx)
+		if err != nil {
+			return err
+		}
+		logger.Infof("Reloaded %d txns into the unconfirmed pool", n)
+
 		removed, err := vs.unconfirmed.RemoveInvalid(tx, vs.blockchain)
 		if err != nil {
 			return err
 		}
 		logger.Infof("Removed %d invalid txns from pool", len(removed))
 
+		nowValid, err := vs.unconfirmed.Refresh(tx, vs.blockchain, vs.Config.Distribution, vs.Config.UnconfirmedVerifyTxn)
+		if err != nil {
+			return err
+		}
+		logger.Infof("Refreshed unconfirmed pool, %d txns became valid", len(nowValid))
+
+		// The pool policy may have been made stricter since the reloaded transactions were injected
+		evicted, err := vs.unconfirmed.EnforcePolicy(tx, vs.blockchain)
+		if err != nil {
+			return err
+		}
+		logger.Infof("Removed %d txns violating the unconfirmed pool policy", len(evicted))
+
 		return nil
 	})
 }
@@ -272,7 +449,18 @@ func (vs *Visor) RemoveInvalidUnconfirmed() ([]cipher.SHA256, error) {
 	if err := vs.db.Update("RemoveInvalidUnconfirmed", func(tx *dbutil.Tx) error {
 		var err error
 		hashes, err = vs.unconfirmed.RemoveInvalid(tx, vs.blockchain)
-		return err
+		if err != nil {
+			return err
+		}
+
+		if len(hashes) != 0 {
+			vs.events.publish(tx, Event{
+				Type:  EventUnconfirmedRemoved,
+				Txids: hashes,
+			})
+		}
+
+		return nil
 	}); err != nil {
 		return nil, err
 	}
@@ -286,88 +474,36 @@ func (vs *Visor) createBlock(tx *dbutil.Tx, when uint64) (coin.SignedBlock, erro
 		logger.Panic("Only a block publisher node can create blocks")
 	}
 
-	// Gather all unconfirmed transactions
-	txns, err := vs.unconfirmed.AllRawTransactions(tx)
+	nb, err := vs.nextBlock(tx, when)
 	if err != nil {
 		return coin.SignedBlock{}, err
 	}
 
-	if len(txns) == 0 {
+	if nb.Pending == 0 {
 		return coin.SignedBlock{}, errors.New("No transactions")
 	}
 
-	logger.Infof("unconfirmed pool has %d transactions pending", len(txns))
-
-	// Filter transactions that violate all constraints
-	var filteredTxns coin.Transactions
-	for _, txn := range txns {
-		if _, _, err := vs.blockchain.VerifySingleTxnSoftHardConstraints(tx, txn, vs.Config.Distribution, vs.Config.CreateBlockVerifyTxn, TxnSigned); err != nil {
-			switch err.(type) {
-			case ErrTxnViolatesHardConstraint, ErrTxnViolatesSoftConstraint:
-				logger.Warningf("Transaction %s violates constraints: %v", txn.Hash().Hex(), err)
-			default:
-				return coin.SignedBlock{}, err
-			}
-		} else {
-			filteredTxns = append(filteredTxns, txn)
-		}
-	}
-
-	nRemoved := len(txns) - len(filteredTxns)
-	if nRemoved > 0 {
-		logger.Infof("CreateBlock ignored %d transactions violating constraints", nRemoved)
-	}
-
-	txns = filteredTxns
-
-	if len(txns) == 0 {
-		logger.Info("No transactions after filtering for constraint violations")
-		return coin.SignedBlock{}, errors.New("No transactions after filtering for constraint violations")
-	}
-
-	head, err := vs.blockchain.Head(tx)
-	if err != nil {
-		return coin.SignedBlock{}, err
+	if nb.Block == nil {
+		logger.Info("No transactions after filtering for constraint violations and the block policy")
+		return coin.SignedBlock{}, errors.New("No transactions after filtering for constraint violations and the block policy")
 	}
 
-	// Sort them by highest fee per kilobyte
-	txns, err = coin.SortTransactions(txns, vs.blockchain.TransactionFee(tx, head.Time()))
-	if err != nil {
-		logger.Critical().WithError(err).Error("SortTransactions failed, no block can be made until the offending transaction is removed")
-		return coin.SignedBlock{}, err
-	}
-
-	// Apply block size transaction limit
-	txns, err = txns.TruncateBytesTo(vs.Config.MaxBlockTransactionsSize)
-	if err != nil {
-		logger.Critical().WithError(err).Error("TruncateBytesTo failed, no block can be made until the offending transaction is removed")
-		return coin.SignedBlock{}, err
-	}
-
-	if len(txns) > coin.MaxBlockTransactions {
-		txns = txns[:coin.MaxBlockTransactions]
-	}
-
-	if len(txns) == 0 {
-		logger.Panic("TruncateBytesTo removed all transactions")
-	}
+	logger.Infof("Creating new block with %d transactions, head time %d", len(nb.Block.Body.Transactions), when)
 
-	logger.Infof("Creating new block with %d transactions, head time %d", len(txns), when)
+	return vs.signBlock(*nb.Block), nil
+}
 
-	b, err := vs.blockchain.NewBlock(tx, txns, when)
+// CreateAndExecuteBlock creates a SignedBlock from pending transactions and executes it
+func (vs *Visor) CreateAndExecuteBlock() (coin.SignedBlock, error) {
+	done, err := vs.acceptWrite()
 	if err != nil {
-		logger.Warningf("blockchain.NewBlock failed: %v", err)
 		return coin.SignedBlock{}, err
 	}
+	defer done()
 
-	return vs.signBlock(*b), nil
-}
-
-// CreateAndExecuteBlock creates a SignedBlock from pending transactions and executes it
-func (vs *Visor) CreateAndExecuteBlock() (coin.SignedBlock, error) {
 	var sb coin.SignedBlock
 
-	err := vs.db.Update("CreateAndExecuteBlock", func(tx *dbutil.Tx) error {
+	err = vs.db.Update("CreateAndExecuteBlock", func(tx *dbutil.Tx) error {
 		var err error
 		sb, err = vs.createBlock(tx, uint64(time.Now().UTC().Unix()))
 		if err != nil {
@@ -383,14 +519,46 @@ func (vs *Visor) CreateAndExecuteBlock() (coin.SignedBlock, error) {
 // ExecuteSignedBlock adds a block to the blockchain, or returns error.
 // Blocks must be executed in sequence, and be signed by a block publisher node
 func (vs *Visor) ExecuteSignedBlock(b coin.SignedBlock) error {
+	defer observeSince(promBlocksCommitDuration, time.Now())
+
+	done, err := vs.acceptWrite()
+	if err != nil {
+		return err
+	}
+	defer done()
+
 	return vs.db.Update("ExecuteSignedBlock", func(tx *dbutil.Tx) error {
 		return vs.executeSignedBlock(tx, b)
 	})
 }
 
+// ProcessBlocks adds a batch of signed blocks to the blockchain in a single database transaction.
+// This avoids a database commit per block when catching up with the network.
+// If any block fails to execute, none of the blocks are applied.
+func (vs *Visor) ProcessBlocks(blocks []coin.SignedBlock) error {
+	defer observeSince(promBlocksCommitDuration, time.Now())
+
+	done, err := vs.acceptWrite()
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	return vs.db.Update("ProcessBlocks", func(tx *dbutil.Tx) error {
+		for _, b := range blocks {
+			if err := vs.executeSignedBlock(tx, b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // executeSignedBlock adds a block to the blockchain, or returns error.
 // Blocks must be executed in sequence, and be signed by a block publisher node
 func (vs *Visor) executeSignedBlock(tx *dbutil.Tx, b coin.SignedBlock) error {
+	defer observeSince(promBlockExecuteDuration, time.Now())
+
 	if err := b.VerifySignature(vs.Config.BlockchainPubkey); err != nil {
 		return err
 	}
@@ -410,7 +578,25 @@ func (vs *Visor) executeSignedBlock(tx *dbutil.Tx, b coin.SignedBlock) error {
 	}
 
 	// Update the HistoryDB
-	return vs.history.ParseBlock(tx, b.Block)
+	if err := vs.history.ParseBlock(tx, b.Block); err != nil {
+		return err
+	}
+
+	vs.events.publish(tx, Event{
+		Type:  EventBlockExecuted,
+		Block: &b,
+	})
+
+	vs.hooks.callBlockApplied(tx, &b)
+
+	if len(txnHashes) != 0 {
+		vs.events.publish(tx, Event{
+			Type:  EventUnconfirmedRemoved,
+			Txids: txnHashes,
+		})
+	}
+
+	return nil
 }
 
 // signBlock signs a block for a block publisher node. Will panic if anything is invalid
@@ -571,30 +757,94 @@ func (vs *Visor) HeadBkSeq() (uint64, bool, error) {
 	return headSeq, ok, nil
 }
 
+// DBSize returns the size of the database, in bytes
+func (vs *Visor) DBSize() (int64, error) {
+	var size int64
+	if err := vs.db.View("DBSize", func(tx *dbutil.Tx) error {
+		size = tx.Size()
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+// BackupDB writes a consistent copy of the database to w, while blocks and transactions
+// continue to be written to the database. Returns the number of bytes written.
+func (vs *Visor) BackupDB(w io.Writer) (int64, error) {
+	var n int64
+	if err := vs.db.View("BackupDB", func(tx *dbutil.Tx) error {
+		var err error
+		n, err = tx.WriteTo(w)
+		return err
+	}); err != nil {
+		return n, err
+	}
+
+	return n, nil
+}
+
+// DBStats returns a report of the size of the database file, of its free pages and of its buckets
+func (vs *Visor) DBStats() (dbutil.DBStats, error) {
+	return vs.db.Stats()
+}
+
+// CompactDB copies the database into a fresh file without its free pages and swaps it in place of the database file.
+// Reads and writes wait until the compaction is done.
+func (vs *Visor) CompactDB() (dbutil.CompactResult, error) {
+	done, err := vs.acceptWrite()
+	if err != nil {
+		return dbutil.CompactResult{}, err
+	}
+	defer done()
+
+	res, err := vs.db.Compact()
+	if err != nil {
+		return dbutil.CompactResult{}, err
+	}
+
+	logger.Infof("Compacted the database from %d to %d bytes in %s", res.Before.FileSize, res.After.FileSize, res.Duration)
+	return res, nil
+}
+
 // GetBlockchainMetadata returns descriptive blockchain information
 func (vs *Visor) GetBlockchainMetadata() (*BlockchainMetadata, error) {
-	var head *coin.SignedBlock
-	var unconfirmedLen, unspentsLen uint64
+	var m *BlockchainMetadata
 
 	if err := vs.db.View("GetBlockchainMetadata", func(tx *dbutil.Tx) error {
 		var err error
-		head, err = vs.blockchain.Head(tx)
-		if err != nil {
-			return err
-		}
-
-		unconfirmedLen, err = vs.unconfirmed.Len(tx)
-		if err != nil {
-			return err
-		}
-
-		unspentsLen, err = vs.blockchain.Unspent().Len(tx)
+		m, err = vs.getBlockchainMetadata(tx)
 		return err
 	}); err != nil {
 		return nil, err
 	}
 
-	return NewBlockchainMetadata(*head, unconfirmedLen, unspentsLen)
+	return m, nil
+}
+
+func (vs *Visor) getBlockchainMetadata(tx *dbutil.Tx) (*BlockchainMetadata, error) {
+	head, err := vs.blockchain.Head(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	unconfirmedLen, err := vs.unconfirmed.Len(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	unspentsLen, err := vs.blockchain.Unspent().Len(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	unspentCommitment, err := vs.blockchain.UnspentCommitment(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBlockchainMetadata(*head, unconfirmedLen, unspentsLen, unspentCommitment)
 }
 
 // GetBlock returns a copy of the block at seq. Returns error if seq out of range
@@ -751,13 +1001,27 @@ func (vs *Visor) getBlocksVerbose(tx *dbutil.Tx, getBlocks func(*dbutil.Tx) ([]c
 // If the transaction only violates soft constraints, it is still injected, and the soft constraint violation is returned.
 // This method is intended for transactions received over the network.
 func (vs *Visor) InjectForeignTransaction(txn coin.Transaction) (bool, *ErrTxnViolatesSoftConstraint, error) {
+	done, err := vs.acceptWrite()
+	if err != nil {
+		return false, nil, err
+	}
+	defer done()
+
 	var known bool
 	var softErr *ErrTxnViolatesSoftConstraint
 
 	if err := vs.db.Update("InjectForeignTransaction", func(tx *dbutil.Tx) error {
 		var err error
 		known, softErr, err = vs.unconfirmed.InjectTransaction(tx, vs.blockchain, txn, vs.Config.Distribution, vs.Config.UnconfirmedVerifyTxn)
-		return err
+		if err != nil {
+			return err
+		}
+
+		if !known {
+			vs.publishInjected(tx, txn)
+		}
+
+		return nil
 	}); err != nil {
 		return false, nil, err
 	}
@@ -770,13 +1034,19 @@ func (vs *Visor) InjectForeignTransaction(txn coin.Transaction) (bool, *ErrTxnVi
 // The bool return value is whether or not the transaction was already in the pool.
 // If the transaction violates hard or soft constraints, it is rejected, and error will not be nil.
 func (vs *Visor) InjectUserTransaction(txn coin.Transaction) (bool, *coin.SignedBlock, coin.UxArray, error) {
+	done, err := vs.acceptWrite()
+	if err != nil {
+		return false, nil, nil, err
+	}
+	defer done()
+
 	var known bool
 	var head *coin.SignedBlock
 	var inputs coin.UxArray
 
 	if err := vs.db.Update("InjectUserTransaction", func(tx *dbutil.Tx) error {
 		var err error
-		known, head, inputs, err = vs.InjectUserTransactionTx(tx, txn)
+		known, head, inputs, err = vs.injectUserTransactionTx(tx, txn)
 		return err
 	}); err != nil {
 		return false, nil, nil, err
@@ -791,6 +1061,18 @@ func (vs *Visor) InjectUserTransaction(txn coin.Transaction) (bool, *coin.Signed
 // If the transaction violates hard or soft constraints, it is rejected, and error will not be nil.
 // This method is only exported for use by the daemon gateway's InjectBroadcastTransaction method.
 func (vs *Visor) InjectUserTransactionTx(tx *dbutil.Tx, txn coin.Transaction) (bool, *coin.SignedBlock, coin.UxArray, error) {
+	vs.shutdownLock.RLock()
+	shutdown := vs.shutdown
+	vs.shutdownLock.RUnlock()
+
+	if shutdown {
+		return false, nil, nil, ErrVisorShutdown
+	}
+
+	return vs.injectUserTransactionTx(tx, txn)
+}
+
+func (vs *Visor) injectUserTransactionTx(tx *dbutil.Tx, txn coin.Transaction) (bool, *coin.SignedBlock, coin.UxArray, error) {
 	if err := VerifySingleTxnUserConstraints(txn); err != nil {
 		return false, nil, nil, err
 	}
@@ -805,9 +1087,20 @@ func (vs *Visor) InjectUserTransactionTx(tx *dbutil.Tx, txn coin.Transaction) (b
 		logger.WithError(softErr).Warning("InjectUserTransaction vs.unconfirmed.InjectTransaction returned a softErr unexpectedly")
 	}
 
+	if err == nil && !known {
+		vs.publishInjected(tx, txn)
+	}
+
 	return known, head, inputs, err
 }
 
+func (vs *Visor) publishInjected(tx *dbutil.Tx, txn coin.Transaction) {
+	vs.events.publish(tx, Event{
+		Type:  EventUnconfirmedInjected,
+		Txids: []cipher.SHA256{txn.Hash()},
+	})
+}
+
 // GetTransactionsForAddress returns the Transactions whose unspents give coins to a cipher.Address.
 // This includes both confirmed and unconfirmed transactions.
 func (vs *Visor) GetTransactionsForAddress(a cipher.Address) ([]Transaction, error) {
@@ -824,34 +1117,74 @@ func (vs *Visor) GetTransactionsForAddress(a cipher.Address) ([]Transaction, err
 	return txns[a], nil
 }
 
-// GetTransaction returns a Transaction by hash.
-func (vs *Visor) GetTransaction(txnHash cipher.SHA256) (*Transaction, error) {
-	var txn *Transaction
+// GetAddressHistory returns up to limit confirmed transactions that touched an address after skipping the first offset,
+// oldest first, and the total number of confirmed transactions that touched the address
+func (vs *Visor) GetAddressHistory(a cipher.Address, offset, limit uint64) ([]blockdb.HistoryEntry, uint64, error) {
+	var entries []blockdb.HistoryEntry
+	var total uint64
 
-	if err := vs.db.View("GetTransaction", func(tx *dbutil.Tx) error {
+	if err := vs.db.View("GetAddressHistory", func(tx *dbutil.Tx) error {
 		var err error
-		txn, err = vs.getTransaction(tx, txnHash)
+		entries, total, err = vs.blockchain.AddressHistory(tx, a, offset, limit)
 		return err
 	}); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	return txn, nil
+	return entries, total, nil
 }
 
-// GetTransactionWithInputs returns a Transaction by hash, along with the unspent outputs of its inputs
-func (vs *Visor) GetTransactionWithInputs(txnHash cipher.SHA256) (*Transaction, []TransactionInput, error) {
-	var txn *Transaction
-	var inputs []TransactionInput
-
-	if err := vs.db.View("GetTransactionWithInputs", func(tx *dbutil.Tx) error {
-		var err error
-		txn, err = vs.getTransaction(tx, txnHash)
-		if err != nil {
-			return err
-		}
+// GetTxnProofs returns the proofs that confirmed transactions are in their blocks.
+// Transactions which are not confirmed are skipped.
+func (vs *Visor) GetTxnProofs(txids []cipher.SHA256) ([]blockdb.TxnProof, error) {
+	var proofs []blockdb.TxnProof
 
-		if txn == nil {
+	if err := vs.db.View("GetTxnProofs", func(tx *dbutil.Tx) error {
+		for _, txid := range txids {
+			p, err := vs.blockchain.TxnProof(tx, txid)
+			if err != nil {
+				return err
+			}
+			if p != nil {
+				proofs = append(proofs, *p)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return proofs, nil
+}
+
+// GetTransaction returns a Transaction by hash.
+func (vs *Visor) GetTransaction(txnHash cipher.SHA256) (*Transaction, error) {
+	var txn *Transaction
+
+	if err := vs.db.View("GetTransaction", func(tx *dbutil.Tx) error {
+		var err error
+		txn, err = vs.getTransaction(tx, txnHash)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return txn, nil
+}
+
+// GetTransactionWithInputs returns a Transaction by hash, along with the unspent outputs of its inputs
+func (vs *Visor) GetTransactionWithInputs(txnHash cipher.SHA256) (*Transaction, []TransactionInput, error) {
+	var txn *Transaction
+	var inputs []TransactionInput
+
+	if err := vs.db.View("GetTransactionWithInputs", func(tx *dbutil.Tx) error {
+		var err error
+		txn, err = vs.getTransaction(tx, txnHash)
+		if err != nil {
+			return err
+		}
+
+		if txn == nil {
 			return nil
 		}
 
@@ -1713,7 +2046,7 @@ func (vs *Visor) getTransactionInputs(tx *dbutil.Tx, feeCalcTime uint64, inputs
 }
 
 // GetHeadBlock gets head block.
-func (vs Visor) GetHeadBlock() (*coin.SignedBlock, error) {
+func (vs *Visor) GetHeadBlock() (*coin.SignedBlock, error) {
 	var b *coin.SignedBlock
 
 	if err := vs.db.View("GetHeadBlock", func(tx *dbutil.Tx) error {
@@ -1728,7 +2061,7 @@ func (vs Visor) GetHeadBlock() (*coin.SignedBlock, error) {
 }
 
 // GetHeadBlockTime returns the time of the head block.
-func (vs Visor) GetHeadBlockTime() (uint64, error) {
+func (vs *Visor) GetHeadBlockTime() (uint64, error) {
 	var t uint64
 
 	if err := vs.db.View("GetHeadBlockTime", func(tx *dbutil.Tx) error {
@@ -1743,7 +2076,7 @@ func (vs Visor) GetHeadBlockTime() (uint64, error) {
 }
 
 // GetUxOutByID gets UxOut by hash id.
-func (vs Visor) GetUxOutByID(id cipher.SHA256) (*historydb.UxOut, error) {
+func (vs *Visor) GetUxOutByID(id cipher.SHA256) (*historydb.UxOut, error) {
 	var outs []historydb.UxOut
 
 	if err := vs.db.View("GetUxOutByID", func(tx *dbutil.Tx) error {
@@ -1762,7 +2095,7 @@ func (vs Visor) GetUxOutByID(id cipher.SHA256) (*historydb.UxOut, error) {
 }
 
 // GetSpentOutputsForAddresses gets all the spent outputs of a set of addresses
-func (vs Visor) GetSpentOutputsForAddresses(addresses []cipher.Address) ([][]historydb.UxOut, error) {
+func (vs *Visor) GetSpentOutputsForAddresses(addresses []cipher.Address) ([][]historydb.UxOut, error) {
 	out := make([][]historydb.UxOut, len(addresses))
 
 	if err := vs.db.View("GetSpentOutputsForAddresses", func(tx *dbutil.Tx) error {
@@ -1925,58 +2258,98 @@ func (vs *Visor) SetTransactionsAnnounced(hashes map[cipher.SHA256]int64) error
 	})
 }
 
-// GetBalanceOfAddrs returns balance pairs of given addreses
-func (vs Visor) GetBalanceOfAddrs(addrs []cipher.Address) ([]wallet.BalancePair, error) {
+// AddressesActivity returns whether or not each address appears in any confirmed or unconfirmed transaction.
+// An address appears in an unconfirmed transaction if it receives one of its outputs;
+// an address can only spend outputs that it received in a confirmed transaction.
+func (vs *Visor) AddressesActivity(addrs []cipher.Address) ([]bool, error) {
 	if len(addrs) == 0 {
 		return nil, nil
 	}
 
-	auxs := make(coin.AddressUxOuts, len(addrs))
-	recvUxs := make(coin.AddressUxOuts, len(addrs))
-	var uxa coin.UxArray
-	var head *coin.SignedBlock
+	active := make([]bool, len(addrs))
+	addrsMap := make(map[cipher.Address][]int, len(addrs))
+	for i, a := range addrs {
+		addrsMap[a] = append(addrsMap[a], i)
+	}
 
-	if err := vs.db.View("GetBalanceOfAddrs", func(tx *dbutil.Tx) error {
-		var err error
-		head, err = vs.blockchain.Head(tx)
-		if err != nil {
-			return err
+	if err := vs.db.View("AddressesActivity", func(tx *dbutil.Tx) error {
+		for i, a := range addrs {
+			seen, err := vs.history.AddressSeen(tx, a)
+			if err != nil {
+				return err
+			}
+			active[i] = seen
 		}
 
-		// Get all transactions from the unconfirmed pool
-		txns, err := vs.unconfirmed.AllRawTransactions(tx)
-		if err != nil {
-			return err
-		}
+		return vs.unconfirmed.ForEach(tx, func(_ cipher.SHA256, txn UnconfirmedTransaction) error {
+			for _, o := range txn.Transaction.Out {
+				for _, i := range addrsMap[o.Address] {
+					active[i] = true
+				}
+			}
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
 
-		// Create predicted unspent outputs from the unconfirmed transactions
-		recvUxs, err = txnOutputsForAddrs(head.Head, addrs, txns)
-		if err != nil {
-			return err
-		}
+	return active, nil
+}
 
-		var inputs []cipher.SHA256
-		for _, txn := range txns {
-			inputs = append(inputs, txn.In...)
-		}
+// GetBalanceOfAddrs returns balance pairs of given addreses
+func (vs *Visor) GetBalanceOfAddrs(addrs []cipher.Address) ([]wallet.BalancePair, error) {
+	var bps []wallet.BalancePair
 
-		// Get unspents for the inputs being spent
-		uxa, err = vs.blockchain.Unspent().GetArray(tx, inputs)
-		if err != nil {
-			return fmt.Errorf("GetArray failed when checking addresses balance: %v", err)
-		}
+	if err := vs.db.View("GetBalanceOfAddrs", func(tx *dbutil.Tx) error {
+		var err error
+		bps, err = vs.getBalanceOfAddrs(tx, addrs)
+		return err
+	}); err != nil {
+		return nil, err
+	}
 
-		// Get unspents owned by the addresses
-		auxs, err = vs.blockchain.Unspent().GetUnspentsOfAddrs(tx, addrs)
-		if err != nil {
-			return fmt.Errorf("GetUnspentsOfAddrs failed when checking addresses balance: %v", err)
-		}
+	return bps, nil
+}
 
-		return nil
-	}); err != nil {
+func (vs *Visor) getBalanceOfAddrs(tx *dbutil.Tx, addrs []cipher.Address) ([]wallet.BalancePair, error) {
+	if len(addrs) == 0 {
+		return nil, nil
+	}
+
+	head, err := vs.blockchain.Head(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get all transactions from the unconfirmed pool
+	txns, err := vs.unconfirmed.AllRawTransactions(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create predicted unspent outputs from the unconfirmed transactions
+	recvUxs, err := txnOutputsForAddrs(head.Head, addrs, txns)
+	if err != nil {
 		return nil, err
 	}
 
+	var inputs []cipher.SHA256
+	for _, txn := range txns {
+		inputs = append(inputs, txn.In...)
+	}
+
+	// Get unspents for the inputs being spent
+	uxa, err := vs.blockchain.Unspent().GetArray(tx, inputs)
+	if err != nil {
+		return nil, fmt.Errorf("GetArray failed when checking addresses balance: %v", err)
+	}
+
+	// Get unspents owned by the addresses
+	auxs, err := vs.blockchain.Unspent().GetUnspentsOfAddrs(tx, addrs)
+	if err != nil {
+		return nil, fmt.Errorf("GetUnspentsOfAddrs failed when checking addresses balance: %v", err)
+	}
+
 	// Build all unconfirmed transaction inputs that are associated with the addresses
 	spendUxs := make(coin.AddressUxOuts, len(addrs))
 
@@ -2052,6 +2425,167 @@ func (vs Visor) GetBalanceOfAddrs(addrs []cipher.Address) ([]wallet.BalancePair,
 	return bps, nil
 }
 
+// AddressBalance is the balance of an address for a number of confirmations
+type AddressBalance struct {
+	// Confirmed is the balance of the unspent outputs with at least the required number of confirmations
+	Confirmed wallet.Balance
+	// Unconfirmed is the balance of the unspent outputs with fewer confirmations
+	// and of the outputs created by unconfirmed transactions
+	Unconfirmed wallet.Balance
+	// Spendable is the confirmed balance minus the outputs spent by unconfirmed transactions
+	Spendable wallet.Balance
+}
+
+// GetBalancesOfAddrs returns the balances of the given addresses, counting the unspent outputs with at least
+// minConfirmations confirmations as confirmed. An output of the head block has 1 confirmation,
+// and a minConfirmations of 0 is treated as 1. The balances are read in a single database transaction
+// from the address index of the unspent pool. Returns the head block seq that the balances were read at.
+func (vs *Visor) GetBalancesOfAddrs(addrs []cipher.Address, minConfirmations uint64) ([]AddressBalance, uint64, error) {
+	var bals []AddressBalance
+	var headSeq uint64
+
+	if err := vs.db.View("GetBalancesOfAddrs", func(tx *dbutil.Tx) error {
+		var err error
+		bals, headSeq, err = vs.getBalancesOfAddrs(tx, addrs, minConfirmations)
+		return err
+	}); err != nil {
+		return nil, 0, err
+	}
+
+	return bals, headSeq, nil
+}
+
+func (vs *Visor) getBalancesOfAddrs(tx *dbutil.Tx, addrs []cipher.Address, minConfirmations uint64) ([]AddressBalance, uint64, error) {
+	if minConfirmations == 0 {
+		minConfirmations = 1
+	}
+
+	head, err := vs.blockchain.Head(tx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if len(addrs) == 0 {
+		return []AddressBalance{}, head.Seq(), nil
+	}
+
+	txns, err := vs.unconfirmed.AllRawTransactions(tx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	recvUxs, err := txnOutputsForAddrs(head.Head, addrs, txns)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var inputs []cipher.SHA256
+	for _, txn := range txns {
+		inputs = append(inputs, txn.In...)
+	}
+
+	spentUxs, err := vs.blockchain.Unspent().GetArray(tx, inputs)
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetArray failed when checking addresses balances: %v", err)
+	}
+
+	auxs, err := vs.blockchain.Unspent().GetUnspentsOfAddrs(tx, addrs)
+	if err != nil {
+		return nil, 0, fmt.Errorf("GetUnspentsOfAddrs failed when checking addresses balances: %v", err)
+	}
+
+	spent := spentUxs.Set()
+	headSeq := head.Seq()
+	headTime := head.Time()
+
+	bals := make([]AddressBalance, len(addrs))
+	for i, addr := range addrs {
+		var confirmed, unconfirmed, spendable coin.UxArray
+		for _, ux := range auxs[addr] {
+			if headSeq-ux.Head.BkSeq+1 < minConfirmations {
+				unconfirmed = append(unconfirmed, ux)
+				continue
+			}
+
+			confirmed = append(confirmed, ux)
+			if _, ok := spent[ux.Hash()]; !ok {
+				spendable = append(spendable, ux)
+			}
+		}
+		unconfirmed = append(unconfirmed, recvUxs[addr]...)
+
+		if bals[i].Confirmed, err = uxBalance(confirmed, headTime); err != nil {
+			return nil, 0, err
+		}
+		if bals[i].Unconfirmed, err = uxBalance(unconfirmed, headTime); err != nil {
+			return nil, 0, err
+		}
+		if bals[i].Spendable, err = uxBalance(spendable, headTime); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return bals, headSeq, nil
+}
+
+// GetBalancesOfAddrsAtSeq returns the confirmed balances of addresses after the block at seq was executed.
+// The coin hours are the coin hours at the time of the block.
+// Returns blockdb.ErrSeqAfterHead if seq is after the head block.
+func (vs *Visor) GetBalancesOfAddrsAtSeq(addrs []cipher.Address, seq uint64) ([]wallet.Balance, error) {
+	var bals []wallet.Balance
+
+	if err := vs.db.View("GetBalancesOfAddrsAtSeq", func(tx *dbutil.Tx) error {
+		totals, err := vs.blockchain.AddressBalanceTotalsAtSeq(tx, addrs, seq)
+		if err != nil {
+			return err
+		}
+
+		b, err := vs.blockchain.GetSignedBlockBySeq(tx, seq)
+		if err != nil {
+			return err
+		}
+		if b == nil {
+			return fmt.Errorf("block %d not found", seq)
+		}
+
+		bals = make([]wallet.Balance, len(addrs))
+		for i, t := range totals {
+			hours, err := t.CoinHours(b.Time())
+			if err != nil {
+				return err
+			}
+			bals[i] = wallet.NewBalance(t.Coins, hours)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return bals, nil
+}
+
+// uxBalance returns the coins and the coin hours at headTime of uxs.
+// The hours are 0 if they overflow, like the hours of GetBalanceOfAddrs.
+func uxBalance(uxs coin.UxArray, headTime uint64) (wallet.Balance, error) {
+	coins, err := uxs.Coins()
+	if err != nil {
+		return wallet.Balance{}, fmt.Errorf("uxs.Coins failed: %v", err)
+	}
+
+	hours, err := uxs.CoinHours(headTime)
+	if err != nil {
+		switch err {
+		case coin.ErrAddEarnedCoinHoursAdditionOverflow:
+			hours = 0
+		default:
+			return wallet.Balance{}, fmt.Errorf("uxs.CoinHours failed: %v", err)
+		}
+	}
+
+	return wallet.NewBalance(coins, hours), nil
+}
+
 // GetUnspentsOfAddrs returns unspent outputs of multiple addresses
 func (vs *Visor) GetUnspentsOfAddrs(addrs []cipher.Address) (coin.AddressUxOuts, error) {
 	var uxa coin.AddressUxOuts
@@ -2320,36 +2854,38 @@ func newSHA256Set(keys []cipher.SHA256) map[cipher.SHA256]struct{} {
 // GetUnspentOutputsSummary gets unspent outputs and returns the filtered results,
 // Note: all filters will be executed as the pending sequence in 'AND' mode.
 func (vs *Visor) GetUnspentOutputsSummary(filters []OutputsFilter) (*UnspentOutputsSummary, error) {
-	var confirmedOutputs []coin.UxOut
-	var outgoingOutputs coin.UxArray
-	var incomingOutputs coin.UxArray
-	var head *coin.SignedBlock
+	var summary *UnspentOutputsSummary
 
 	if err := vs.db.View("GetUnspentOutputsSummary", func(tx *dbutil.Tx) error {
 		var err error
-		head, err = vs.blockchain.Head(tx)
-		if err != nil {
-			return fmt.Errorf("vs.blockchain.Head failed: %v", err)
-		}
+		summary, err = vs.getUnspentOutputsSummary(tx, filters)
+		return err
+	}); err != nil {
+		return nil, err
+	}
 
-		confirmedOutputs, err = vs.blockchain.Unspent().GetAll(tx)
-		if err != nil {
-			return fmt.Errorf("vs.blockchain.Unspent().GetAll failed: %v", err)
-		}
+	return summary, nil
+}
 
-		outgoingOutputs, err = vs.unconfirmedOutgoingOutputs(tx)
-		if err != nil {
-			return fmt.Errorf("vs.unconfirmedOutgoingOutputs failed: %v", err)
-		}
+func (vs *Visor) getUnspentOutputsSummary(tx *dbutil.Tx, filters []OutputsFilter) (*UnspentOutputsSummary, error) {
+	head, err := vs.blockchain.Head(tx)
+	if err != nil {
+		return nil, fmt.Errorf("vs.blockchain.Head failed: %v", err)
+	}
 
-		incomingOutputs, err = vs.unconfirmedIncomingOutputs(tx)
-		if err != nil {
-			return fmt.Errorf("vs.unconfirmedIncomingOutputs failed: %v", err)
-		}
+	confirmedOutputs, err := vs.blockchain.Unspent().GetAll(tx)
+	if err != nil {
+		return nil, fmt.Errorf("vs.blockchain.Unspent().GetAll failed: %v", err)
+	}
 
-		return nil
-	}); err != nil {
-		return nil, err
+	outgoingOutputs, err := vs.unconfirmedOutgoingOutputs(tx)
+	if err != nil {
+		return nil, fmt.Errorf("vs.unconfirmedOutgoingOutputs failed: %v", err)
+	}
+
+	incomingOutputs, err := vs.unconfirmedIncomingOutputs(tx)
+	if err != nil {
+		return nil, fmt.Errorf("vs.unconfirmedIncomingOutputs failed: %v", err)
 	}
 
 	for _, flt := range filters {
@@ -2381,49 +2917,162 @@ func (vs *Visor) GetUnspentOutputsSummary(filters []OutputsFilter) (*UnspentOutp
 	}, nil
 }
 
-// GetRichlist returns a Richlist
-func (vs *Visor) GetRichlist(includeDistribution bool) (Richlist, error) {
-	rbOuts, err := vs.GetUnspentOutputsSummary(nil)
-	if err != nil {
-		return nil, err
+// GetRichlist returns the top n addresses by coins, or all addresses with coins if n <= 0.
+// If includeDistribution is false, the distribution addresses are excluded.
+func (vs *Visor) GetRichlist(includeDistribution bool, n int) (Richlist, error) {
+	lockedAddrs := vs.Config.Distribution.LockedAddressesDecoded()
+	lockedMap := make(map[cipher.Address]struct{}, len(lockedAddrs))
+	for _, a := range lockedAddrs {
+		lockedMap[a] = struct{}{}
 	}
 
-	// Build a map from addresses to total coins held
-	allAccounts := map[cipher.Address]uint64{}
-	for _, out := range rbOuts.Confirmed {
-		if _, ok := allAccounts[out.Body.Address]; ok {
-			var err error
-			allAccounts[out.Body.Address], err = mathutil.AddUint64(allAccounts[out.Body.Address], out.Body.Coins)
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			allAccounts[out.Body.Address] = out.Body.Coins
+	excluded := make(map[cipher.Address]struct{})
+	if !includeDistribution {
+		for a := range lockedMap {
+			excluded[a] = struct{}{}
+		}
+		for _, a := range vs.Config.Distribution.UnlockedAddressesDecoded() {
+			excluded[a] = struct{}{}
 		}
 	}
 
-	lockedAddrs := vs.Config.Distribution.LockedAddressesDecoded()
-	addrsMap := make(map[cipher.Address]struct{}, len(lockedAddrs))
-	for _, a := range lockedAddrs {
-		addrsMap[a] = struct{}{}
+	allAccounts := map[cipher.Address]uint64{}
+	if err := vs.db.View("GetRichlist", func(tx *dbutil.Tx) error {
+		// Addresses with the same coins are collected together,
+		// so that NewRichlist can order them with locked addresses first
+		var lastCoins uint64
+		return vs.blockchain.ForEachAddressByCoins(tx, func(a cipher.Address, coins uint64) (bool, error) {
+			if _, ok := excluded[a]; ok {
+				return true, nil
+			}
+
+			if n > 0 && len(allAccounts) >= n && coins != lastCoins {
+				return false, nil
+			}
+
+			allAccounts[a] = coins
+			lastCoins = coins
+			return true, nil
+		})
+	}); err != nil {
+		return nil, err
 	}
 
-	richlist, err := NewRichlist(allAccounts, addrsMap)
+	richlist, err := NewRichlist(allAccounts, lockedMap)
 	if err != nil {
 		return nil, err
 	}
 
-	if !includeDistribution {
-		unlockedAddrs := vs.Config.Distribution.UnlockedAddressesDecoded()
-		for _, a := range unlockedAddrs {
-			addrsMap[a] = struct{}{}
-		}
-		richlist = richlist.FilterAddresses(addrsMap)
+	if n > 0 && n < len(richlist) {
+		richlist = richlist[:n]
 	}
 
 	return richlist, nil
 }
 
+// CoinSupply are the coins and coin hours held by the distribution addresses and the other addresses,
+// at the time of the head block
+type CoinSupply struct {
+	// UnlockedCoins are the coins held by the unlocked distribution addresses
+	UnlockedCoins uint64
+	// TotalCoinHours are the coin hours held by all addresses except the locked distribution addresses
+	TotalCoinHours uint64
+	// CurrentCoinHours are the coin hours held by all addresses except the distribution addresses
+	CurrentCoinHours uint64
+}
+
+// GetCoinSupply returns the CoinSupply, computed from the balance index rather than the unspent outputs
+func (vs *Visor) GetCoinSupply() (*CoinSupply, error) {
+	lockedAddrs := vs.Config.Distribution.LockedAddressesDecoded()
+	unlockedAddrs := vs.Config.Distribution.UnlockedAddressesDecoded()
+
+	var cs CoinSupply
+	if err := vs.db.View("GetCoinSupply", func(tx *dbutil.Tx) error {
+		headTime, err := vs.blockchain.Time(tx)
+		if err != nil {
+			return err
+		}
+
+		supply, err := vs.blockchain.Supply(tx)
+		if err != nil {
+			return err
+		}
+
+		locked, err := vs.blockchain.AddressBalanceTotals(tx, lockedAddrs)
+		if err != nil {
+			return err
+		}
+
+		unlocked, err := vs.blockchain.AddressBalanceTotals(tx, unlockedAddrs)
+		if err != nil {
+			return err
+		}
+
+		total := supply
+		for _, b := range locked {
+			if total, err = total.Sub(b); err != nil {
+				return fmt.Errorf("subtracting locked distribution addresses from supply failed: %v", err)
+			}
+		}
+
+		current := total
+		for _, b := range unlocked {
+			if current, err = current.Sub(b); err != nil {
+				return fmt.Errorf("subtracting unlocked distribution addresses from supply failed: %v", err)
+			}
+			if cs.UnlockedCoins, err = mathutil.AddUint64(cs.UnlockedCoins, b.Coins); err != nil {
+				return fmt.Errorf("uint64 overflow while adding up unlocked supply coins: %v", err)
+			}
+		}
+
+		if cs.TotalCoinHours, err = total.CoinHours(headTime); err != nil {
+			return err
+		}
+		if cs.CurrentCoinHours, err = current.CoinHours(headTime); err != nil {
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return &cs, nil
+}
+
+// UnspentStats are the statistics of the unspent outputs at the head block
+type UnspentStats struct {
+	blockdb.UnspentStats
+	HeadSeq uint64
+	// CoinHours are the coin hours of all unspent outputs at the time of the head block
+	CoinHours uint64
+}
+
+// GetUnspentStats returns the UnspentStats, which are maintained as blocks are executed rather than computed
+// from the unspent outputs
+func (vs *Visor) GetUnspentStats() (*UnspentStats, error) {
+	var s UnspentStats
+	if err := vs.db.View("GetUnspentStats", func(tx *dbutil.Tx) error {
+		head, err := vs.blockchain.Head(tx)
+		if err != nil {
+			return err
+		}
+
+		s.UnspentStats, err = vs.blockchain.Unspent().Stats(tx)
+		if err != nil {
+			return err
+		}
+
+		s.HeadSeq = head.Seq()
+		s.CoinHours, err = s.Total.CoinHours(head.Time())
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
 // WithUpdateTx executes a function inside of a db.Update transaction.
 // This is exported for use by the daemon gateway's InjectBroadcastTransaction method.
 // Do not use it for other purposes.
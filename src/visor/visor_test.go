@@ -27,6 +27,7 @@ import (
 	"github.com/SkycoinProject/cx-chains/src/visor/blockdb"
 	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
 	"github.com/SkycoinProject/cx-chains/src/visor/historydb"
+	"github.com/SkycoinProject/cx-chains/src/wallet"
 )
 
 const (
@@ -90,7 +91,7 @@ func removeCorruptDBFiles(t *testing.T, badDBFile string) {
 
 func addGenesisBlockToVisor(t *testing.T, vs *Visor) *coin.SignedBlock {
 	// create genesis block
-	gb, err := coin.NewGenesisBlock(genAddress, genCoins, genTime)
+	gb, err := coin.NewGenesisBlock(genAddress, genCoins, genTime, nil)
 	require.NoError(t, err)
 	gbSig := cipher.MustSignHash(gb.HashHeader(), genSecret)
 	vs.Config.GenesisSignature = gbSig
@@ -110,7 +111,7 @@ func addGenesisBlockToVisor(t *testing.T, vs *Visor) *coin.SignedBlock {
 }
 
 func TestErrMissingSignatureRecreateDB(t *testing.T) {
-	badDBFile := "./testdata/data.db.nosig" // about 8MB size
+	badDBFile := "./testdata/cx/data.db.nosig" // about 8MB size
 	badDBData := readAll(t, badDBFile)
 
 	pubkey := mustParsePubkey(t)
@@ -203,27 +204,27 @@ func TestHistorydbVerifier(t *testing.T) {
 	}{
 		{
 			name:   "db is ok",
-			dbPath: "./testdata/data.db.ok",
+			dbPath: "./testdata/cx/data.db.ok",
 		},
 		{
 			name:      "missing transaction",
-			dbPath:    "./testdata/data.db.notxn",
-			expectErr: historydb.NewErrHistoryDBCorrupted(errors.New("HistoryDB.Verify: transaction 98db7eb30e13853d3dd93d5d8b4061596d5d288b6f8b92c4d43c46c6599f67fb does not exist in historydb")),
+			dbPath:    "./testdata/cx/data.db.notxn",
+			expectErr: historydb.NewErrHistoryDBCorrupted(errors.New("HistoryDB.Verify: transaction 775950d9209eb011a6e05cfeea44652d90f99051f5c967966686807073dce23f does not exist in historydb")),
 		},
 		{
 			name:      "missing uxout",
-			dbPath:    "./testdata/data.db.nouxout",
-			expectErr: historydb.NewErrHistoryDBCorrupted(errors.New("HistoryDB.Verify: transaction (input|output) 2f87d77c2a7d00b547db1af50e0ba04bafc5b05711e4939e9ec2640a21127dc0 does not exist in historydb")),
+			dbPath:    "./testdata/cx/data.db.nouxout",
+			expectErr: historydb.NewErrHistoryDBCorrupted(errors.New("HistoryDB.Verify: transaction (input|output) 7015640cc74004fa2c1a24e414f489e34e311fa812b00c4839f02f31a5cfe8d4 does not exist in historydb")),
 		},
 		{
 			name:      "missing addr transaction index",
-			dbPath:    "./testdata/data.db.no-addr-txn-index",
-			expectErr: historydb.NewErrHistoryDBCorrupted(errors.New(`HistoryDB.Verify: index of address transaction \[2fGC7kwAM9yZyEF1QqBqp8uo9RUsF6ENGJF:98db7eb30e13853d3dd93d5d8b4061596d5d288b6f8b92c4d43c46c6599f67fb\] does not exist in historydb`)),
+			dbPath:    "./testdata/cx/data.db.no-addr-txn-index",
+			expectErr: historydb.NewErrHistoryDBCorrupted(errors.New(`HistoryDB.Verify: index of address transaction \[2fGC7kwAM9yZyEF1QqBqp8uo9RUsF6ENGJF:775950d9209eb011a6e05cfeea44652d90f99051f5c967966686807073dce23f\] does not exist in historydb`)),
 		},
 		{
 			name:      "missing addr uxout index",
-			dbPath:    "./testdata/data.db.no-addr-uxout-index",
-			expectErr: historydb.NewErrHistoryDBCorrupted(errors.New(`HistoryDB.Verify: index of address uxout \[2fGC7kwAM9yZyEF1QqBqp8uo9RUsF6ENGJF:2f87d77c2a7d00b547db1af50e0ba04bafc5b05711e4939e9ec2640a21127dc0\] does not exist in historydb`)),
+			dbPath:    "./testdata/cx/data.db.no-addr-uxout-index",
+			expectErr: historydb.NewErrHistoryDBCorrupted(errors.New(`HistoryDB.Verify: index of address uxout \[2fGC7kwAM9yZyEF1QqBqp8uo9RUsF6ENGJF:7015640cc74004fa2c1a24e414f489e34e311fa812b00c4839f02f31a5cfe8d4\] does not exist in historydb`)),
 		},
 	}
 
@@ -270,7 +271,7 @@ func TestVisorCreateBlock(t *testing.T) {
 		Pubkey: genPublic,
 	})
 
-	unconfirmed, err := NewUnconfirmedTransactionPool(db)
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolPolicy{})
 	require.NoError(t, err)
 
 	his := historydb.New()
@@ -452,7 +453,7 @@ func TestVisorCreateBlock(t *testing.T) {
 
 	blockTxns := sb.Block.Body.Transactions
 	require.NotEqual(t, len(txns), len(blockTxns), "Transactions should be truncated")
-	require.Equal(t, 18, len(blockTxns))
+	require.Equal(t, 17, len(blockTxns))
 
 	// Check fee ordering
 	err = db.View("", func(tx *dbutil.Tx) error {
@@ -495,7 +496,7 @@ func TestVisorInjectTransaction(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	unconfirmed, err := NewUnconfirmedTransactionPool(db)
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolPolicy{})
 	require.NoError(t, err)
 
 	his := historydb.New()
@@ -648,9 +649,9 @@ func makeOverflowCoinsSpendTxn(t *testing.T, uxs coin.UxArray, keys []cipher.Sec
 	hours := totalHours / 12
 
 	// These two outputs' coins added up will overflow
-	err := spendTxn.PushOutput(toAddr, 18446744073709551000, hours)
+	err := spendTxn.PushOutput(toAddr, 18446744073709551000, hours, nil)
 	require.NoError(t, err)
-	err = spendTxn.PushOutput(toAddr, totalCoins, hours)
+	err = spendTxn.PushOutput(toAddr, totalCoins, hours, nil)
 	require.NoError(t, err)
 
 	spendTxn.SignInputs(keys)
@@ -673,9 +674,9 @@ func makeOverflowHoursSpendTxn(t *testing.T, uxs coin.UxArray, keys []cipher.Sec
 	hours := totalHours / 12
 
 	// These two outputs' hours added up will overflow
-	err := spendTxn.PushOutput(toAddr, totalCoins/2, 18446744073709551615)
+	err := spendTxn.PushOutput(toAddr, totalCoins/2, 18446744073709551615, nil)
 	require.NoError(t, err)
-	err = spendTxn.PushOutput(toAddr, totalCoins-totalCoins/2, hours)
+	err = spendTxn.PushOutput(toAddr, totalCoins-totalCoins/2, hours, nil)
 	require.NoError(t, err)
 
 	spendTxn.SignInputs(keys)
@@ -1928,7 +1929,7 @@ func TestRefreshUnconfirmed(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	unconfirmed, err := NewUnconfirmedTransactionPool(db)
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolPolicy{})
 	require.NoError(t, err)
 
 	his := historydb.New()
@@ -1957,13 +1958,15 @@ func TestRefreshUnconfirmed(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, gb)
 
-	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	// Split the genesis output, so that the transactions spend different outputs
+	// and do not conflict with each other in the unconfirmed pool
+	uxs := splitUnspent(t, v, coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])[0], 3)
 
 	toAddr := testutil.MakeAddress()
 	var coins uint64 = 10e6
 
 	// Create a valid transaction that will remain valid
-	validTxn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, coins)
+	validTxn := makeSpendTxn(t, uxs[:1], []cipher.SecKey{genSecret}, genAddress, coins)
 	known, softErr, err := v.InjectForeignTransaction(validTxn)
 	require.False(t, known)
 	require.Nil(t, softErr)
@@ -1981,7 +1984,7 @@ func TestRefreshUnconfirmed(t *testing.T) {
 	// It's still injected, because this is considered a soft error
 	// This transaction will stay invalid on refresh
 	invalidCoins := coins + (params.UserVerifyTxn.MaxDropletDivisor() / 10)
-	alwaysInvalidTxn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, toAddr, invalidCoins)
+	alwaysInvalidTxn := makeSpendTxn(t, uxs[1:2], []cipher.SecKey{genSecret}, toAddr, invalidCoins)
 	_, softErr, err = v.InjectForeignTransaction(alwaysInvalidTxn)
 	require.NoError(t, err)
 	testutil.RequireError(t, softErr.Err, params.ErrInvalidDecimals.Error())
@@ -1999,7 +2002,7 @@ func TestRefreshUnconfirmed(t *testing.T) {
 	// This transaction will become valid on refresh (by increasing UnconfirmedVerifyTxn.MaxTransactionSize)
 	originalMaxUnconfirmedTxnSize := v.Config.UnconfirmedVerifyTxn.MaxTransactionSize
 	v.Config.UnconfirmedVerifyTxn.MaxTransactionSize = 1
-	sometimesInvalidTxn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, toAddr, coins)
+	sometimesInvalidTxn := makeSpendTxn(t, uxs[2:], []cipher.SecKey{genSecret}, toAddr, coins)
 	_, softErr, err = v.InjectForeignTransaction(sometimesInvalidTxn)
 	require.NoError(t, err)
 	require.NotNil(t, softErr)
@@ -2049,6 +2052,39 @@ func TestRefreshUnconfirmed(t *testing.T) {
 	require.Equal(t, expectedHashes, hashes)
 }
 
+// splitUnspent executes a block with a transaction splitting ux into n outputs owned by genAddress, and returns the outputs
+func splitUnspent(t *testing.T, v *Visor, ux coin.UxOut, n int) coin.UxArray {
+	txn := coin.Transaction{}
+	err := txn.PushInput(ux.Hash())
+	require.NoError(t, err)
+
+	coins := ux.Body.Coins / uint64(n)
+	coins -= coins % params.UserVerifyTxn.MaxDropletDivisor()
+	hours := ux.Body.Hours / uint64(2*n)
+	for i := 0; i < n; i++ {
+		c := coins
+		if i == n-1 {
+			c = ux.Body.Coins - coins*uint64(n-1)
+		}
+		// Vary the hours, a transaction can't have duplicate outputs
+		err := txn.PushOutput(genAddress, c, hours-uint64(i), nil)
+		require.NoError(t, err)
+	}
+
+	txn.SignInputs([]cipher.SecKey{genSecret})
+	err = txn.UpdateHeader()
+	require.NoError(t, err)
+
+	_, softErr, err := v.InjectForeignTransaction(txn)
+	require.NoError(t, err)
+	require.Nil(t, softErr)
+
+	sb, err := v.CreateAndExecuteBlock()
+	require.NoError(t, err)
+
+	return coin.CreateUnspents(sb.Head, txn)
+}
+
 func TestRemoveInvalidUnconfirmedDoubleSpendArbitrating(t *testing.T) {
 	db, shutdown := prepareDB(t)
 	defer shutdown()
@@ -2059,7 +2095,7 @@ func TestRemoveInvalidUnconfirmedDoubleSpendArbitrating(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	unconfirmed, err := NewUnconfirmedTransactionPool(db)
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolPolicy{})
 	require.NoError(t, err)
 
 	his := historydb.New()
@@ -2091,10 +2127,10 @@ func TestRemoveInvalidUnconfirmedDoubleSpendArbitrating(t *testing.T) {
 
 	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
 
-	// Create two valid transactions, both spending the same inputs, one with a higher fee
-	// Then, create a block from these transactions.
-	// The one with the higher fee should be included in the block, and the other should be ignored.
-	// A call to RemoveInvalidUnconfirmed will remove the other txn, because it would now be a double spend.
+	// Create two valid transactions, both spending the same inputs.
+	// The first is injected into the unconfirmed pool, which rejects or replaces the second as a conflict,
+	// but the second can still be included in a block received from the network.
+	// A call to RemoveInvalidUnconfirmed will remove the first txn, because it would now be a double spend.
 
 	var coins uint64 = 10e6
 	txn1 := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, coins)
@@ -2111,23 +2147,22 @@ func TestRemoveInvalidUnconfirmedDoubleSpendArbitrating(t *testing.T) {
 	})
 	require.NoError(t, err)
 
+	// Execute a block with txn2, which was never in the unconfirmed pool
 	var fee uint64 = 1
 	txn2 := makeSpendTxWithFee(t, uxs, []cipher.SecKey{genSecret}, genAddress, coins, fee)
-	known, softErr, err = v.InjectForeignTransaction(txn2)
-	require.False(t, known)
-	require.Nil(t, softErr)
-	require.NoError(t, err)
 
+	var sb coin.SignedBlock
 	err = db.View("", func(tx *dbutil.Tx) error {
-		length, err := unconfirmed.Len(tx)
-		require.NoError(t, err)
-		require.Equal(t, uint64(2), length)
+		b, err := bc.NewBlock(tx, coin.Transactions{txn2}, uint64(time.Now().UTC().Unix()))
+		if err != nil {
+			return err
+		}
+		sb = v.signBlock(*b)
 		return nil
 	})
 	require.NoError(t, err)
 
-	// Execute a block, txn2 should be included because it has a higher fee
-	sb, err := v.CreateAndExecuteBlock()
+	err = v.ExecuteSignedBlock(sb)
 	require.NoError(t, err)
 	require.Equal(t, 1, len(sb.Body.Transactions))
 	require.Equal(t, 2, len(sb.Body.Transactions[0].Out))
@@ -2188,6 +2223,414 @@ func makeTxn(t *testing.T, headTime uint64, in, out []coin.UxOut, keys []cipher.
 	return txn, txnInputs
 }
 
+func TestVisorInitEnforcesUnconfirmedPoolPolicy(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolPolicy{})
+	require.NoError(t, err)
+
+	cfg := NewConfig()
+	cfg.IsBlockPublisher = true
+	cfg.BlockchainSeckey = genSecret
+	cfg.BlockchainPubkey = genPublic
+	cfg.GenesisAddress = genAddress
+
+	v := &Visor{
+		Config:      cfg,
+		unconfirmed: unconfirmed,
+		blockchain:  bc,
+		db:          db,
+		history:     historydb.New(),
+	}
+
+	gb := addGenesisBlockToVisor(t, v)
+	ux := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])[0]
+
+	// makeFeeTxn spends the genesis output with a fee of fee coin hours.
+	// The transactions have the same size, so their fee per kilobyte is ordered by their fee.
+	makeFeeTxn := func(fee uint64) coin.Transaction {
+		txn := coin.Transaction{}
+		err := txn.PushInput(ux.Hash())
+		require.NoError(t, err)
+		err = txn.PushOutput(genAddress, ux.Body.Coins, ux.Body.Hours-fee, nil)
+		require.NoError(t, err)
+		txn.SignInputs([]cipher.SecKey{genSecret})
+		err = txn.UpdateHeader()
+		require.NoError(t, err)
+		return txn
+	}
+
+	lowTxn := makeFeeTxn(1)
+	midTxn := makeFeeTxn(100)
+	highTxn := makeFeeTxn(1000)
+
+	size, err := lowTxn.Size()
+	require.NoError(t, err)
+
+	// Write the transactions to the pool as a pool without a policy would have persisted them
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		for _, txn := range []coin.Transaction{lowTxn, midTxn, highTxn} {
+			utx := NewUnconfirmedTransaction(txn)
+			if err := unconfirmed.txns.put(tx, &utx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Reload the pool with a policy that the low fee txn is below the minimum fee of,
+	// and that only has room for one txn
+	v.unconfirmed, err = NewUnconfirmedTransactionPool(db, UnconfirmedPoolPolicy{
+		MinFeePerKB: feePerKB(10, size),
+		MaxSize:     uint64(size),
+	})
+	require.NoError(t, err)
+
+	err = v.Init()
+	require.NoError(t, err)
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		hashes, err := v.unconfirmed.GetHashes(tx, All)
+		require.NoError(t, err)
+		require.Equal(t, []cipher.SHA256{highTxn.Hash()}, hashes)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestVisorShutdown(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolPolicy{})
+	require.NoError(t, err)
+
+	cfg := NewConfig()
+	cfg.IsBlockPublisher = true
+	cfg.BlockchainSeckey = genSecret
+	cfg.BlockchainPubkey = genPublic
+	cfg.GenesisAddress = genAddress
+
+	v := &Visor{
+		Config:      cfg,
+		unconfirmed: unconfirmed,
+		blockchain:  bc,
+		db:          db,
+		history:     historydb.New(),
+		events:      &eventPublisher{},
+	}
+
+	gb := addGenesisBlockToVisor(t, v)
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+
+	txn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, testutil.MakeAddress(), 10e6)
+	known, _, _, err := v.InjectUserTransaction(txn)
+	require.NoError(t, err)
+	require.False(t, known)
+
+	err = v.Shutdown()
+	require.NoError(t, err)
+
+	// Shutdown can be called more than once
+	err = v.Shutdown()
+	require.NoError(t, err)
+
+	// Blocks and transactions are rejected after shutdown
+	otherTxn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, testutil.MakeAddress(), 20e6)
+
+	_, _, _, err = v.InjectUserTransaction(otherTxn)
+	require.Equal(t, ErrVisorShutdown, err)
+
+	_, _, err = v.InjectForeignTransaction(otherTxn)
+	require.Equal(t, ErrVisorShutdown, err)
+
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		_, _, _, err := v.InjectUserTransactionTx(tx, otherTxn)
+		return err
+	})
+	require.Equal(t, ErrVisorShutdown, err)
+
+	_, err = v.CreateAndExecuteBlock()
+	require.Equal(t, ErrVisorShutdown, err)
+
+	err = v.ExecuteSignedBlock(*gb)
+	require.Equal(t, ErrVisorShutdown, err)
+
+	err = v.ProcessBlocks([]coin.SignedBlock{*gb})
+	require.Equal(t, ErrVisorShutdown, err)
+
+	// The transaction injected before shutdown is persisted
+	err = db.View("", func(tx *dbutil.Tx) error {
+		hashes, err := v.unconfirmed.GetHashes(tx, All)
+		require.NoError(t, err)
+		require.Equal(t, []cipher.SHA256{txn.Hash()}, hashes)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestVisorBackupDB(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolPolicy{})
+	require.NoError(t, err)
+
+	cfg := NewConfig()
+	cfg.BlockchainPubkey = genPublic
+	cfg.GenesisAddress = genAddress
+
+	v := &Visor{
+		Config:      cfg,
+		unconfirmed: unconfirmed,
+		blockchain:  bc,
+		db:          db,
+		history:     historydb.New(),
+		events:      &eventPublisher{},
+	}
+
+	gb := addGenesisBlockToVisor(t, v)
+
+	f, err := ioutil.TempFile("", "testbackup")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+
+	n, err := v.BackupDB(f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	fi, err := os.Stat(f.Name())
+	require.NoError(t, err)
+	require.Equal(t, fi.Size(), n)
+
+	// The copy is a database with the same blockchain
+	backupDB, err := OpenDB(f.Name(), true)
+	require.NoError(t, err)
+	defer backupDB.Close()
+
+	backupBc, err := NewBlockchain(backupDB, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	err = backupDB.View("", func(tx *dbutil.Tx) error {
+		head, err := backupBc.Head(tx)
+		require.NoError(t, err)
+		require.Equal(t, *gb, *head)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestVisorGetBalancesOfAddrs(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolPolicy{})
+	require.NoError(t, err)
+
+	cfg := NewConfig()
+	cfg.IsBlockPublisher = true
+	cfg.BlockchainPubkey = genPublic
+	cfg.BlockchainSeckey = genSecret
+	cfg.GenesisAddress = genAddress
+
+	v := &Visor{
+		Config:      cfg,
+		unconfirmed: unconfirmed,
+		blockchain:  bc,
+		db:          db,
+		history:     historydb.New(),
+		events:      &eventPublisher{},
+	}
+
+	gb := addGenesisBlockToVisor(t, v)
+
+	// Block 1 sends 10 coins to addrA
+	pubA, secA := cipher.GenerateKeyPair()
+	addrA := cipher.AddressFromPubKey(pubA)
+	genUxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	txn := makeSpendTxn(t, genUxs, []cipher.SecKey{genSecret}, addrA, 10e6)
+	_, _, _, err = v.InjectUserTransaction(txn)
+	require.NoError(t, err)
+
+	b1, err := v.CreateAndExecuteBlock()
+	require.NoError(t, err)
+
+	// An unconfirmed transaction sends 1 coin from addrA to addrB, with the change back to addrA
+	addrB := testutil.MakeAddress()
+	b1Uxs := coin.CreateUnspents(b1.Head, b1.Body.Transactions[0])
+	uxA := b1Uxs[0]
+	uxGen := b1Uxs[1]
+	require.Equal(t, addrA, uxA.Body.Address)
+	require.Equal(t, genAddress, uxGen.Body.Address)
+
+	txn = makeSpendTxn(t, coin.UxArray{uxA}, []cipher.SecKey{secA}, addrB, 1e6)
+	_, _, _, err = v.InjectUserTransaction(txn)
+	require.NoError(t, err)
+	pendingUxs := coin.CreateUnspents(b1.Head, txn)
+
+	balance := func(uxs ...coin.UxOut) wallet.Balance {
+		var bal wallet.Balance
+		for _, ux := range uxs {
+			b, err := wallet.NewBalanceFromUxOut(b1.Time(), &ux)
+			require.NoError(t, err)
+			bal, err = bal.Add(b)
+			require.NoError(t, err)
+		}
+		return bal
+	}
+
+	addrs := []cipher.Address{addrA, addrB, genAddress, testutil.MakeAddress()}
+
+	bals, headSeq, err := v.GetBalancesOfAddrs(addrs, 0)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), headSeq)
+	require.Equal(t, []AddressBalance{
+		{
+			Confirmed:   balance(uxA),
+			Unconfirmed: balance(pendingUxs[1]),
+		},
+		{
+			Unconfirmed: balance(pendingUxs[0]),
+		},
+		{
+			Confirmed: balance(uxGen),
+			Spendable: balance(uxGen),
+		},
+		{},
+	}, bals)
+
+	// The outputs of block 1 have a single confirmation
+	bals, headSeq, err = v.GetBalancesOfAddrs(addrs, 2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), headSeq)
+	require.Equal(t, []AddressBalance{
+		{
+			Unconfirmed: balance(uxA, pendingUxs[1]),
+		},
+		{
+			Unconfirmed: balance(pendingUxs[0]),
+		},
+		{
+			Unconfirmed: balance(uxGen),
+		},
+		{},
+	}, bals)
+
+	bals, headSeq, err = v.GetBalancesOfAddrs(nil, 1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), headSeq)
+	require.Empty(t, bals)
+}
+
+func TestVisorGetBalancesOfAddrsAtSeq(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTransactionPool(db, UnconfirmedPoolPolicy{})
+	require.NoError(t, err)
+
+	cfg := NewConfig()
+	cfg.IsBlockPublisher = true
+	cfg.BlockchainPubkey = genPublic
+	cfg.BlockchainSeckey = genSecret
+	cfg.GenesisAddress = genAddress
+
+	v := &Visor{
+		Config:      cfg,
+		unconfirmed: unconfirmed,
+		blockchain:  bc,
+		db:          db,
+		history:     historydb.New(),
+		events:      &eventPublisher{},
+	}
+
+	gb := addGenesisBlockToVisor(t, v)
+
+	// Block 1 sends 10 coins to addrA
+	pubA, secA := cipher.GenerateKeyPair()
+	addrA := cipher.AddressFromPubKey(pubA)
+	genUxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	txn := makeSpendTxn(t, genUxs, []cipher.SecKey{genSecret}, addrA, 10e6)
+	_, _, _, err = v.InjectUserTransaction(txn)
+	require.NoError(t, err)
+
+	b1, err := v.CreateAndExecuteBlock()
+	require.NoError(t, err)
+
+	// Block 2 sends 1 coin from addrA to addrB, with the change back to addrA
+	addrB := testutil.MakeAddress()
+	uxA := coin.CreateUnspents(b1.Head, b1.Body.Transactions[0])[0]
+	txn = makeSpendTxn(t, coin.UxArray{uxA}, []cipher.SecKey{secA}, addrB, 1e6)
+	_, _, _, err = v.InjectUserTransaction(txn)
+	require.NoError(t, err)
+
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		sb, err := v.createBlock(tx, b1.Time()+3600)
+		if err != nil {
+			return err
+		}
+		return v.executeSignedBlock(tx, sb)
+	})
+	require.NoError(t, err)
+
+	addrs := []cipher.Address{genAddress, addrA, addrB}
+	coins := func(bals []wallet.Balance) []uint64 {
+		c := make([]uint64, len(bals))
+		for i, b := range bals {
+			c[i] = b.Coins
+		}
+		return c
+	}
+
+	bals, err := v.GetBalancesOfAddrsAtSeq(addrs, 0)
+	require.NoError(t, err)
+	require.Equal(t, []wallet.Balance{
+		{Coins: genUxs[0].Body.Coins, Hours: genUxs[0].Body.Hours},
+		{},
+		{},
+	}, bals)
+
+	bals, err = v.GetBalancesOfAddrsAtSeq(addrs, 1)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{genUxs[0].Body.Coins - 10e6, 10e6, 0}, coins(bals))
+
+	bals, err = v.GetBalancesOfAddrsAtSeq(addrs, 2)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{genUxs[0].Body.Coins - 10e6, 9e6, 1e6}, coins(bals))
+
+	_, err = v.GetBalancesOfAddrsAtSeq(addrs, 3)
+	require.Equal(t, blockdb.ErrSeqAfterHead, err)
+}
+
 func TestVerifyTxnVerbose(t *testing.T) {
 	head := coin.SignedBlock{
 		Block: coin.Block{
@@ -2298,7 +2741,7 @@ func TestVerifyTxnVerbose(t *testing.T) {
 			Body: coin.UxBody{
 				Address: testutil.MakeAddress(),
 				Coins:   1e6,
-				Hours:   950,
+				Hours:   995,
 			},
 		},
 	}
@@ -12,6 +12,7 @@ import (
 	"github.com/SkycoinProject/cx-chains/src/util/mathutil"
 	"github.com/SkycoinProject/cx-chains/src/visor/dbutil"
 	"github.com/SkycoinProject/cx-chains/src/wallet"
+	"github.com/SkycoinProject/cx-chains/src/wallet/hardware"
 )
 
 // UserError wraps user input-related errors.
@@ -46,6 +47,10 @@ var (
 	ErrUxOutsOrAddressesRequired = NewUserError(errors.New("UxOuts or Addresses must not be empty"))
 	// ErrNoSpendableOutputs after filtering unconfirmed spend outputs, there are no remaining outputs available for transaction creation
 	ErrNoSpendableOutputs = NewUserError(errors.New("All selected outputs are unavailable for spending"))
+	// ErrReplaceParamsConflict Replace cannot be combined with UxOuts or Addresses
+	ErrReplaceParamsConflict = NewUserError(errors.New("Replace cannot be combined with UxOuts or Addresses"))
+	// ErrReplacedTxnNotFound the transaction to replace is not in the unconfirmed pool
+	ErrReplacedTxnNotFound = NewUserError(errors.New("Transaction to replace is not an unconfirmed transaction"))
 )
 
 // GetWalletBalance returns balance pairs of specific wallet
@@ -142,6 +147,7 @@ func (vs *Visor) GetWalletUnconfirmedTransactionsVerbose(wltID string) ([]Unconf
 
 // WalletSignTransaction signs a transaction. Specific inputs may be signed by specifying signIndexes.
 // If signIndexes is empty, all inputs will be signed. The transaction must be fully valid and spendable.
+// For a multisig transaction, signIndexes is ignored, and the wallet adds its signatures to every input it is a signer of.
 func (vs *Visor) WalletSignTransaction(wltID string, password []byte, txn *coin.Transaction, signIndexes []int) (*coin.Transaction, []TransactionInput, error) {
 	var inputs []TransactionInput
 	var signedTxn *coin.Transaction
@@ -176,30 +182,77 @@ func (vs *Visor) WalletSignTransaction(wltID string, password []byte, txn *coin.
 				uxOuts[i] = in.UxOut
 			}
 
-			signedTxn, err = w.SignTransaction(txn, signIndexes, uxOuts)
-			if err != nil {
-				logger.WithError(err).Error("wallet.SignTransaction failed")
+			if txn.IsMultisig() {
+				signedTxn, err = w.SignMultisigTransaction(txn, uxOuts)
+				if err != nil {
+					logger.WithError(err).Error("wallet.SignMultisigTransaction failed")
+					return err
+				}
+			} else {
+				signedTxn, err = w.SignTransaction(txn, signIndexes, uxOuts)
+				if err != nil {
+					logger.WithError(err).Error("wallet.SignTransaction failed")
+					return err
+				}
+			}
+
+			// This shouldn't fail since we verified in the beginning; if it does, then wallet.SignTransaction has a bug
+			return vs.verifySignedTransaction(tx, signedTxn)
+		})
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	return signedTxn, inputs, nil
+}
+
+// WalletSignTransactionHardware signs all inputs of a transaction with the hardware wallet device.
+// The wallet must be a watch-only wallet of the device's addresses. The transaction must be fully valid and spendable.
+func (vs *Visor) WalletSignTransactionHardware(wltID string, txn *coin.Transaction) (*coin.Transaction, []TransactionInput, error) {
+	if vs.hwDevice == nil {
+		return nil, nil, hardware.ErrNoDevice
+	}
+
+	if txn.IsFullySigned() {
+		return nil, nil, ErrTransactionAlreadySigned
+	}
+
+	var inputs []TransactionInput
+	var signedTxn *coin.Transaction
+
+	if err := vs.wallets.View(wltID, func(w *wallet.Wallet) error {
+		return vs.db.View("WalletSignTransactionHardware", func(tx *dbutil.Tx) error {
+			// Verify the transaction before signing
+			if err := VerifySingleTxnUserConstraints(*txn); err != nil {
+				return err
+			}
+			if _, _, err := vs.blockchain.VerifySingleTxnSoftHardConstraints(tx, *txn, vs.Config.Distribution, params.UserVerifyTxn, TxnUnsigned); err != nil {
 				return err
 			}
 
-			signed := TxnSigned
-			if !signedTxn.IsFullySigned() {
-				signed = TxnUnsigned
+			headTime, err := vs.blockchain.Time(tx)
+			if err != nil {
+				logger.WithError(err).Error("blockchain.Time failed")
+				return err
 			}
 
-			if err := VerifySingleTxnUserConstraints(*signedTxn); err != nil {
-				// This shouldn't happen since we verified in the beginning; if it does, then wallet.SignTransaction has a bug
-				logger.Critical().WithError(err).Error("Signed transaction violates transaction user constraints")
+			inputs, err = vs.getTransactionInputs(tx, headTime, txn.In)
+			if err != nil {
 				return err
 			}
 
-			if _, _, err := vs.blockchain.VerifySingleTxnSoftHardConstraints(tx, *signedTxn, vs.Config.Distribution, params.UserVerifyTxn, signed); err != nil {
-				// This shouldn't happen since we verified in the beginning; if it does, then wallet.SignTransaction has a bug
-				logger.Critical().WithError(err).Error("Signed transaction violates transaction constraints")
+			uxOuts := make([]coin.UxOut, len(inputs))
+			for i, in := range inputs {
+				uxOuts[i] = in.UxOut
+			}
+
+			signedTxn, err = hardware.SignTransaction(vs.hwDevice, w, txn, uxOuts)
+			if err != nil {
+				logger.WithError(err).Error("hardware.SignTransaction failed")
 				return err
 			}
 
-			return nil
+			return vs.verifySignedTransaction(tx, signedTxn)
 		})
 	}); err != nil {
 		return nil, nil, err
@@ -208,6 +261,54 @@ func (vs *Visor) WalletSignTransaction(wltID string, password []byte, txn *coin.
 	return signedTxn, inputs, nil
 }
 
+// WalletConfirmAddressHardware shows a wallet address on the hardware wallet device, for the user to confirm
+// that the device owns it. The wallet must be a watch-only wallet of the device's addresses.
+func (vs *Visor) WalletConfirmAddressHardware(wltID string, addr cipher.Address) error {
+	if vs.hwDevice == nil {
+		return hardware.ErrNoDevice
+	}
+
+	return vs.wallets.View(wltID, func(w *wallet.Wallet) error {
+		return hardware.ConfirmAddress(vs.hwDevice, w, addr)
+	})
+}
+
+// CreateHardwareWallet creates a watch-only wallet of n addresses of the hardware wallet device, from address index 0.
+// The address index of each address is stored as the child number of its entry
+func (vs *Visor) CreateHardwareWallet(wltName string, opts wallet.Options, n uint32) (*wallet.Wallet, error) {
+	if vs.hwDevice == nil {
+		return nil, hardware.ErrNoDevice
+	}
+
+	opts, err := hardware.WalletOptions(vs.hwDevice, opts, n, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return vs.wallets.CreateWallet(wltName, opts, nil, nil)
+}
+
+// verifySignedTransaction verifies a transaction after signing it.
+// The transaction is verified as unsigned if not all of its inputs are signed.
+func (vs *Visor) verifySignedTransaction(tx *dbutil.Tx, signedTxn *coin.Transaction) error {
+	signed := TxnSigned
+	if !signedTxn.IsFullySigned() {
+		signed = TxnUnsigned
+	}
+
+	if err := VerifySingleTxnUserConstraints(*signedTxn); err != nil {
+		logger.Critical().WithError(err).Error("Signed transaction violates transaction user constraints")
+		return err
+	}
+
+	if _, _, err := vs.blockchain.VerifySingleTxnSoftHardConstraints(tx, *signedTxn, vs.Config.Distribution, params.UserVerifyTxn, signed); err != nil {
+		logger.Critical().WithError(err).Error("Signed transaction violates transaction constraints")
+		return err
+	}
+
+	return nil
+}
+
 // CreateTransactionParams parameters for transaction creation
 type CreateTransactionParams struct {
 	UxOuts    []cipher.SHA256
@@ -215,6 +316,14 @@ type CreateTransactionParams struct {
 	// IgnoreUnconfirmed if true, outputs matching Addresses or UxOuts spent by
 	// an unconfirmed transactions will be ignored, otherwise an error will be returned
 	IgnoreUnconfirmed bool
+	// Replace is the hash of a pending unconfirmed transaction to replace.
+	// The new transaction spends the outputs spent by the pending transaction, and it must pay
+	// a higher fee than the pending transaction to replace it in the unconfirmed pool.
+	// Cannot be combined with UxOuts or Addresses.
+	Replace cipher.SHA256
+	// FeePerKB is the minimum fee of the transaction in coin hours per 1000 bytes.
+	// If nil, the fee estimate for the next block is used, see GetFeeEstimate.
+	FeePerKB *uint64
 }
 
 // Validate validates params
@@ -223,6 +332,10 @@ func (p CreateTransactionParams) Validate() error {
 		return ErrCreateTransactionParamsConflict
 	}
 
+	if !p.Replace.Null() && (len(p.UxOuts) != 0 || len(p.Addresses) != 0) {
+		return ErrReplaceParamsConflict
+	}
+
 	// Check for duplicate addresses
 	addressMap := make(map[cipher.Address]struct{}, len(p.Addresses))
 	for _, a := range p.Addresses {
@@ -297,6 +410,57 @@ func (vs *Visor) WalletCreateTransaction(wltID string, p transaction.Params, wp
 	return txn, inputs, nil
 }
 
+// WalletCreateTransactionHardware creates a transaction based upon the parameters in CreateTransactionParams,
+// and signs it with the hardware wallet device. The wallet must be a watch-only wallet of the device's addresses.
+func (vs *Visor) WalletCreateTransactionHardware(wltID string, p transaction.Params, wp CreateTransactionParams) (*coin.Transaction, []TransactionInput, error) {
+	if vs.hwDevice == nil {
+		return nil, nil, hardware.ErrNoDevice
+	}
+
+	// Validate params before opening wallet
+	if err := p.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if err := wp.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	var txn *coin.Transaction
+	var inputs []TransactionInput
+
+	if err := vs.wallets.View(wltID, func(w *wallet.Wallet) error {
+		unsignedTxn, unsignedInputs, err := vs.walletCreateTransaction("WalletCreateTransactionHardware", w, p, wp, TxnUnsigned)
+		if err != nil {
+			return err
+		}
+
+		uxOuts := make([]coin.UxOut, len(unsignedInputs))
+		for i, in := range unsignedInputs {
+			uxOuts[i] = in.UxOut
+		}
+
+		signedTxn, err := hardware.SignTransaction(vs.hwDevice, w, unsignedTxn, uxOuts)
+		if err != nil {
+			logger.WithError(err).Error("hardware.SignTransaction failed")
+			return err
+		}
+
+		if err := vs.db.View("WalletCreateTransactionHardware", func(tx *dbutil.Tx) error {
+			return vs.verifySignedTransaction(tx, signedTxn)
+		}); err != nil {
+			return err
+		}
+
+		txn = signedTxn
+		inputs = unsignedInputs
+		return nil
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	return txn, inputs, nil
+}
+
 func (vs *Visor) walletCreateTransaction(methodName string, w *wallet.Wallet, p transaction.Params, wp CreateTransactionParams, signed TxnSignedFlag) (*coin.Transaction, []TransactionInput, error) {
 	if err := p.Validate(); err != nil {
 		return nil, nil, err
@@ -358,9 +522,13 @@ func (vs *Visor) walletCreateTransactionTx(tx *dbutil.Tx, methodName string,
 
 	// Get mapping of addresses to uxOuts based upon CreateTransactionParams
 	var auxs coin.AddressUxOuts
-	if len(wp.UxOuts) != 0 {
+	if !wp.Replace.Null() || len(wp.UxOuts) != 0 {
 		var err error
-		auxs, err = vs.getCreateTransactionAuxsUxOut(tx, wp.UxOuts, wp.IgnoreUnconfirmed)
+		if !wp.Replace.Null() {
+			auxs, err = vs.getCreateTransactionAuxsReplace(tx, wp.Replace)
+		} else {
+			auxs, err = vs.getCreateTransactionAuxsUxOut(tx, wp.UxOuts, wp.IgnoreUnconfirmed)
+		}
 		if err != nil {
 			return nil, nil, err
 		}
@@ -379,6 +547,11 @@ func (vs *Visor) walletCreateTransactionTx(tx *dbutil.Tx, methodName string,
 		}
 	}
 
+	p.FeePerKB, err = vs.createTransactionFeePerKB(tx, wp)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Create and sign transaction
 	var txn *coin.Transaction
 	var uxb []transaction.UxBalance
@@ -422,7 +595,7 @@ func (vs *Visor) CreateTransaction(p transaction.Params, wp CreateTransactionPar
 	if err := wp.Validate(); err != nil {
 		return nil, nil, err
 	}
-	if len(wp.Addresses) == 0 && len(wp.UxOuts) == 0 {
+	if len(wp.Addresses) == 0 && len(wp.UxOuts) == 0 && wp.Replace.Null() {
 		return nil, nil, ErrUxOutsOrAddressesRequired
 	}
 
@@ -452,15 +625,23 @@ func (vs *Visor) createTransactionTx(tx *dbutil.Tx, p transaction.Params, wp Cre
 
 	// Get mapping of addresses to uxOuts based upon CreateTransactionParams
 	var auxs coin.AddressUxOuts
-	if len(wp.UxOuts) != 0 {
+	switch {
+	case !wp.Replace.Null():
+		auxs, err = vs.getCreateTransactionAuxsReplace(tx, wp.Replace)
+	case len(wp.UxOuts) != 0:
 		auxs, err = vs.getCreateTransactionAuxsUxOut(tx, wp.UxOuts, wp.IgnoreUnconfirmed)
-	} else {
+	default:
 		auxs, err = vs.getCreateTransactionAuxsAddress(tx, wp.Addresses, wp.IgnoreUnconfirmed)
 	}
 	if err != nil {
 		return nil, nil, err
 	}
 
+	p.FeePerKB, err = vs.createTransactionFeePerKB(tx, wp)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	txn, uxb, err := transaction.Create(p, auxs, head.Time(), p.MainExpressions)
 	if err != nil {
 		return nil, nil, err
@@ -541,6 +722,28 @@ func (vs *Visor) getCreateTransactionAuxsUxOut(tx *dbutil.Tx, uxOutHashes []ciph
 	return coin.NewAddressUxOuts(coin.UxArray(uxOuts)), nil
 }
 
+// getCreateTransactionAuxsReplace returns a map of addresses to the unspent outputs spent by
+// the unconfirmed transaction being replaced
+func (vs *Visor) getCreateTransactionAuxsReplace(tx *dbutil.Tx, replace cipher.SHA256) (coin.AddressUxOuts, error) {
+	utxn, err := vs.unconfirmed.Get(tx, replace)
+	if err != nil {
+		return nil, err
+	}
+
+	if utxn == nil {
+		return nil, ErrReplacedTxnNotFound
+	}
+
+	// An error is returned if any of the outputs were spent by a block since the transaction was injected,
+	// in which case the transaction can no longer be replaced
+	uxOuts, err := vs.blockchain.Unspent().GetArray(tx, utxn.Transaction.In)
+	if err != nil {
+		return nil, err
+	}
+
+	return coin.NewAddressUxOuts(coin.UxArray(uxOuts)), nil
+}
+
 // getCreateTransactionAuxsAddress returns a map of the addresses to their unspent outputs,
 // filtering or erroring on unconfirmed outputs depending on the value of ignoreUnconfirmed
 func (vs *Visor) getCreateTransactionAuxsAddress(tx *dbutil.Tx, addrs []cipher.Address, ignoreUnconfirmed bool) (coin.AddressUxOuts, error) {
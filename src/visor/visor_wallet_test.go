@@ -120,6 +120,21 @@ func TestCreateTransaction(t *testing.T) {
 	err = invalidParamsTxn.UpdateHeader()
 	require.NoError(t, err)
 
+	// A pending transaction spending the same input, to be replaced
+	pendingTxn := coin.Transaction{
+		Sigs: make([]cipher.Sig, 1),
+		In:   []cipher.SHA256{getArrayRet[0].Hash()},
+		Out: []coin.TransactionOutput{
+			{
+				Address: testutil.MakeAddress(),
+				Coins:   1e6,
+				Hours:   1,
+			},
+		},
+	}
+	err = pendingTxn.UpdateHeader()
+	require.NoError(t, err)
+
 	headBlock := &coin.SignedBlock{
 		Block: coin.Block{
 			Head: coin.BlockHeader{
@@ -150,6 +165,9 @@ func TestCreateTransaction(t *testing.T) {
 		getUnspentHashesOfAddrs    blockdb.AddressHashes
 		getUnspentHashesOfAddrsErr error
 
+		getUnconfirmed    *UnconfirmedTransaction
+		getUnconfirmedErr error
+
 		verifyErr error
 	}{
 		{
@@ -289,6 +307,43 @@ func TestCreateTransaction(t *testing.T) {
 			txn:            txn,
 			inputs:         inputs,
 		},
+
+		{
+			name: "replaced txn not found",
+			p:    validParams,
+			wp: CreateTransactionParams{
+				Replace: pendingTxn.Hash(),
+			},
+			blockchainHead: headBlock,
+			err:            ErrReplacedTxnNotFound,
+		},
+
+		{
+			name: "Unconfirmed.Get failed",
+			p:    validParams,
+			wp: CreateTransactionParams{
+				Replace: pendingTxn.Hash(),
+			},
+			blockchainHead:    headBlock,
+			getUnconfirmedErr: errors.New("failure"),
+			err:               errors.New("failure"),
+		},
+
+		{
+			name: "ok, replace",
+			p:    validParams,
+			wp: CreateTransactionParams{
+				Replace: pendingTxn.Hash(),
+			},
+			blockchainHead: headBlock,
+			getUnconfirmed: &UnconfirmedTransaction{
+				Transaction: pendingTxn,
+			},
+			getArrayInputs: pendingTxn.In,
+			getArray:       getArrayRet,
+			txn:            txn,
+			inputs:         inputs,
+		},
 	}
 
 	for _, tc := range cases {
@@ -300,10 +355,16 @@ func TestCreateTransaction(t *testing.T) {
 			b.On("Head", matchDBTx).Return(tc.blockchainHead, tc.blockchainHeadErr)
 			up.On("GetUnspentHashesOfAddrs", matchDBTx, tc.wp.Addresses).Return(tc.getUnspentHashesOfAddrs, tc.getUnspentHashesOfAddrsErr)
 
+			// The fee estimate of an idle chain is 0
+			b.On("HeadSeq", matchDBTx).Return(uint64(1), true, nil)
+			b.On("GetLastBlocks", matchDBTx, uint64(feeEstimateBlocks)).Return(nil, nil)
+			ut.On("NextBlockFeePerKB", matchDBTx, mock.Anything).Return(uint64(0), uint64(0), nil)
+
 			ut.On("ForEach", matchDBTx, mock.MatchedBy(func(f func(cipher.SHA256, UnconfirmedTransaction) error) bool {
 				return true
 			})).Return(tc.forEachErr).Run(unconfirmedForEachMockRun(t, tc.unconfirmedTxns, tc.uxOuts, tc.wp.IgnoreUnconfirmed))
 
+			ut.On("Get", matchDBTx, tc.wp.Replace).Return(tc.getUnconfirmed, tc.getUnconfirmedErr)
 			up.On("GetArray", matchDBTx, mock.MatchedBy(matchUxOutsAnyOrder(tc.getArrayInputs))).Return(tc.getArray, tc.getArrayErr)
 			b.On("Unspent").Return(up)
 
@@ -458,6 +519,21 @@ func TestWalletCreateTransaction(t *testing.T) {
 	err = invalidParamsTxn.UpdateHeader()
 	require.NoError(t, err)
 
+	// A pending transaction spending the same input, to be replaced
+	pendingTxn := coin.Transaction{
+		Sigs: make([]cipher.Sig, 1),
+		In:   []cipher.SHA256{getArrayRet[0].Hash()},
+		Out: []coin.TransactionOutput{
+			{
+				Address: testutil.MakeAddress(),
+				Coins:   1e6,
+				Hours:   1,
+			},
+		},
+	}
+	err = pendingTxn.UpdateHeader()
+	require.NoError(t, err)
+
 	headBlock := &coin.SignedBlock{
 		Block: coin.Block{
 			Head: coin.BlockHeader{
@@ -491,6 +567,9 @@ func TestWalletCreateTransaction(t *testing.T) {
 		getUnspentHashesOfAddrs    blockdb.AddressHashes
 		getUnspentHashesOfAddrsErr error
 
+		getUnconfirmed    *UnconfirmedTransaction
+		getUnconfirmedErr error
+
 		verifyErr error
 	}
 
@@ -598,6 +677,50 @@ func TestWalletCreateTransaction(t *testing.T) {
 			err:            transaction.ErrNullAddressReceiver,
 		},
 
+		{
+			name: "replace pending txn",
+			p:    validParams,
+			wp: CreateTransactionParams{
+				Replace: pendingTxn.Hash(),
+			},
+			walletID:       "foo.wlt",
+			blockchainHead: headBlock,
+			getUnconfirmed: &UnconfirmedTransaction{
+				Transaction: pendingTxn,
+			},
+			getArrayInputs: pendingTxn.In,
+			getArray:       getArrayRet,
+			txn:            txn,
+			inputs:         inputs,
+		},
+
+		{
+			name: "replace pending txn of another wallet",
+			p:    validParams,
+			wp: CreateTransactionParams{
+				Replace: pendingTxn.Hash(),
+			},
+			walletID:       "foo.wlt",
+			blockchainHead: headBlock,
+			getUnconfirmed: &UnconfirmedTransaction{
+				Transaction: pendingTxn,
+			},
+			getArrayInputs: pendingTxn.In,
+			getArray:       unknownUxOutGetArrayRet,
+			err:            wallet.ErrUnknownUxOut,
+		},
+
+		{
+			name: "replaced txn not found",
+			p:    validParams,
+			wp: CreateTransactionParams{
+				Replace: pendingTxn.Hash(),
+			},
+			walletID:       "foo.wlt",
+			blockchainHead: headBlock,
+			err:            ErrReplacedTxnNotFound,
+		},
+
 		{
 			name:              "Blockchain.Head failed",
 			p:                 validParams,
@@ -654,7 +777,7 @@ func TestWalletCreateTransaction(t *testing.T) {
 				Password:   tc.password,
 				CryptoType: wallet.CryptoTypeScryptChacha20poly1305Insecure,
 				GenerateN:  5,
-			}, nil)
+			}, nil, nil)
 			require.NoError(t, err)
 
 			err = ws.UpdateSecrets(tc.walletID, tc.password, func(w *wallet.Wallet) error {
@@ -686,10 +809,16 @@ func TestWalletCreateTransaction(t *testing.T) {
 			b.On("Head", matchDBTx).Return(tc.blockchainHead, tc.blockchainHeadErr)
 			up.On("GetUnspentHashesOfAddrs", matchDBTx, addrs).Return(tc.getUnspentHashesOfAddrs, tc.getUnspentHashesOfAddrsErr)
 
+			// The fee estimate of an idle chain is 0
+			b.On("HeadSeq", matchDBTx).Return(uint64(1), true, nil)
+			b.On("GetLastBlocks", matchDBTx, uint64(feeEstimateBlocks)).Return(nil, nil)
+			ut.On("NextBlockFeePerKB", matchDBTx, mock.Anything).Return(uint64(0), uint64(0), nil)
+
 			ut.On("ForEach", matchDBTx, mock.MatchedBy(func(f func(cipher.SHA256, UnconfirmedTransaction) error) bool {
 				return true
 			})).Return(tc.forEachErr).Run(unconfirmedForEachMockRun(t, tc.unconfirmedTxns, tc.uxOuts, tc.wp.IgnoreUnconfirmed))
 
+			ut.On("Get", matchDBTx, tc.wp.Replace).Return(tc.getUnconfirmed, tc.getUnconfirmedErr)
 			up.On("GetArray", matchDBTx, mock.MatchedBy(matchUxOutsAnyOrder(tc.getArrayInputs))).Return(tc.getArray, tc.getArrayErr)
 			b.On("Unspent").Return(up)
 
@@ -772,6 +901,24 @@ func TestCreateTransactionParamsValidate(t *testing.T) {
 			err: ErrCreateTransactionParamsConflict,
 		},
 
+		{
+			name: "replace and uxouts specified",
+			p: CreateTransactionParams{
+				Replace: hash,
+				UxOuts:  []cipher.SHA256{testutil.RandSHA256(t)},
+			},
+			err: ErrReplaceParamsConflict,
+		},
+
+		{
+			name: "replace and addrs specified",
+			p: CreateTransactionParams{
+				Replace:   hash,
+				Addresses: []cipher.Address{addr},
+			},
+			err: ErrReplaceParamsConflict,
+		},
+
 		{
 			name: "null address in addrs",
 			p: CreateTransactionParams{
@@ -809,6 +956,13 @@ func TestCreateTransactionParamsValidate(t *testing.T) {
 				UxOuts: []cipher.SHA256{hash},
 			},
 		},
+
+		{
+			name: "ok, replace specified",
+			p: CreateTransactionParams{
+				Replace: hash,
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -24,6 +24,8 @@ func CryptoTypeFromString(s string) (CryptoType, error) {
 		return CryptoTypeScryptChacha20poly1305, nil
 	case CryptoTypeScryptChacha20poly1305Insecure:
 		return CryptoTypeScryptChacha20poly1305Insecure, nil
+	case CryptoTypeScryptAES256GCM:
+		return CryptoTypeScryptAES256GCM, nil
 	default:
 		return "", errors.New("unknown crypto type")
 	}
@@ -37,6 +39,8 @@ const (
 	CryptoTypeScryptChacha20poly1305 = CryptoType("scrypt-chacha20poly1305")
 	// CryptoTypeScryptChacha20poly1305Insecure uses chacha20poly1305 + scrypt key derivation with a weak work factor (unsafe)
 	CryptoTypeScryptChacha20poly1305Insecure = CryptoType("scrypt-chacha20poly1305-insecure")
+	// CryptoTypeScryptAES256GCM uses AES-256-GCM + scrypt key derivation
+	CryptoTypeScryptAES256GCM = CryptoType("scrypt-aes256gcm")
 )
 
 // cryptoTable records all supported wallet crypto methods
@@ -50,6 +54,7 @@ var cryptoTable = map[CryptoType]cryptor{
 		P:      encrypt.ScryptP,
 		KeyLen: encrypt.ScryptKeyLen,
 	},
+	CryptoTypeScryptAES256GCM: encrypt.DefaultScryptAES256GCM,
 }
 
 // getCrypto gets crypto of given type
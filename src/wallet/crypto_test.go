@@ -1,6 +1,7 @@
 package wallet
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -8,16 +9,16 @@ import (
 
 func TestSecrets(t *testing.T) {
 	s := make(secrets)
-	s.set("k1", "v1")
+	s.set("k1", []byte("v1"))
 
 	v, ok := s.get("k1")
 	require.True(t, ok)
-	require.Equal(t, "v1", v)
+	require.Equal(t, []byte("v1"), v)
 
 	_, ok = s.get("k2")
 	require.False(t, ok)
 
-	s.set("k2", "v2")
+	s.set("k2", []byte("v2"))
 
 	b, err := s.serialize()
 	require.NoError(t, err)
@@ -27,3 +28,72 @@ func TestSecrets(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, s, s1)
 }
+
+func TestSecretsSerializeJSON(t *testing.T) {
+	// The secrets are serialized as a JSON object of strings
+	values := map[string]string{
+		secretSeed:           "voyage say extend find sheriff surge priority merit ignore maple cash argue",
+		secretLastSeed:       "",
+		secretSeedPassphrase: "quote \" backslash \\ slash / tab \t newline \n nul \x00 unicode ü€😀",
+	}
+
+	s := make(secrets)
+	for k, v := range values {
+		s.set(k, []byte(v))
+	}
+
+	b, err := s.serialize()
+	require.NoError(t, err)
+
+	var m map[string]string
+	err = json.Unmarshal(b, &m)
+	require.NoError(t, err)
+	require.Equal(t, values, m)
+
+	// Secrets serialized with encoding/json by earlier versions can be deserialized
+	jb, err := json.Marshal(values)
+	require.NoError(t, err)
+
+	s1 := make(secrets)
+	err = s1.deserialize(jb)
+	require.NoError(t, err)
+	require.Equal(t, s, s1)
+
+	s2 := make(secrets)
+	err = s2.deserialize([]byte(` { "a" : "ü😀\/" , "b":"" } `))
+	require.NoError(t, err)
+	require.Equal(t, secrets{
+		"a": []byte("ü😀/"),
+		"b": []byte{},
+	}, s2)
+
+	for _, d := range []string{
+		``,
+		`{`,
+		`{"a"}`,
+		`{"a":}`,
+		`{"a":"b",}`,
+		`{"a":"b" "c":"d"}`,
+		`{"a":"b`,
+		`{"a":"\x"}`,
+		`{"a":"\u00"}`,
+		"{\"a\":\"\n\"}",
+	} {
+		err := make(secrets).deserialize([]byte(d))
+		require.Equal(t, errInvalidSecrets, err, d)
+	}
+}
+
+func TestSecretsErase(t *testing.T) {
+	seed := []byte("seed")
+	sk := []byte("0123456789abcdef")
+
+	s := make(secrets)
+	s.set(secretSeed, seed)
+	s.set("addr", sk)
+
+	s.erase()
+	require.Empty(t, s)
+	require.Equal(t, make([]byte, len(seed)), seed)
+	require.Equal(t, make([]byte, len(sk)), sk)
+}
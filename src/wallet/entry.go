@@ -8,9 +8,10 @@ import (
 
 // Entry represents the wallet entry
 type Entry struct {
-	Address cipher.Addresser
-	Public  cipher.PubKey
-	Secret  cipher.SecKey
+	Address     cipher.Addresser
+	Public      cipher.PubKey
+	Secret      cipher.SecKey
+	ChildNumber uint32 // bip32 child number of the address on a bip44 wallet's external chain, or the address index of a hardware wallet address
 }
 
 // SkycoinAddress returns the Skycoin address of an entry. Panics if Address is not a Skycoin address
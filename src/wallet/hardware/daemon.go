@@ -0,0 +1,293 @@
+package hardware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SkycoinProject/cx-chains/src/util/droplet"
+)
+
+const (
+	// DefaultDaemonAddr is the default address of the Skycoin hardware wallet daemon
+	DefaultDaemonAddr = "127.0.0.1:9510"
+	// DefaultDaemonTimeout is the default timeout of a request to the hardware wallet daemon.
+	// It is long, because the daemon does not respond until the user confirms the operation on the device.
+	DefaultDaemonTimeout = 5 * time.Minute
+
+	daemonCSRFHeader = "X-CSRF-Token"
+)
+
+// DaemonDevice is a Device connected through the Skycoin hardware wallet daemon.
+// The daemon exposes an HTTP API and relays the Skycoin hardware wallet protocol messages to the device over USB.
+type DaemonDevice struct {
+	addr   string
+	client http.Client
+
+	csrfLock  sync.Mutex
+	csrfToken string
+}
+
+// NewDaemonDevice creates a DaemonDevice for the hardware wallet daemon listening on addr.
+// If addr does not have a scheme, http:// is used.
+func NewDaemonDevice(addr string, timeout time.Duration) *DaemonDevice {
+	if !strings.HasPrefix(addr, "http://") && !strings.HasPrefix(addr, "https://") {
+		addr = "http://" + addr
+	}
+
+	return &DaemonDevice{
+		addr: strings.TrimRight(addr, "/"),
+		client: http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// DaemonError is an error response of the hardware wallet daemon
+type DaemonError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e DaemonError) Error() string {
+	return fmt.Sprintf("hardware wallet daemon error %d: %s", e.StatusCode, e.Message)
+}
+
+type daemonErrorResponse struct {
+	Error *struct {
+		Message string `json:"message"`
+		Code    int    `json:"code"`
+	} `json:"error,omitempty"`
+}
+
+// Connected returns true if the daemon is reachable and a device is plugged in
+func (d *DaemonDevice) Connected() bool {
+	var available bool
+	if err := d.do(http.MethodGet, "/api/v1/available", nil, &available); err != nil {
+		logger.WithError(err).Debug("hardware wallet daemon /api/v1/available failed")
+		return false
+	}
+	return available
+}
+
+type daemonGenerateAddressesRequest struct {
+	AddressN       uint32 `json:"address_n"`
+	StartIndex     uint32 `json:"start_index"`
+	ConfirmAddress bool   `json:"confirm_address"`
+}
+
+type daemonGenerateAddressesResponse struct {
+	Addresses []string `json:"addresses"`
+}
+
+// AddressGen returns n addresses of the device, starting from startIndex.
+// If confirm is true, the device shows the address and waits for the user to confirm it; n must be 1.
+func (d *DaemonDevice) AddressGen(n, startIndex uint32, confirm bool) ([]string, error) {
+	if confirm && n != 1 {
+		return nil, errors.New("only one address can be confirmed at a time")
+	}
+
+	var rsp daemonGenerateAddressesResponse
+	if err := d.do(http.MethodPost, "/api/v1/generate_addresses", daemonGenerateAddressesRequest{
+		AddressN:       n,
+		StartIndex:     startIndex,
+		ConfirmAddress: confirm,
+	}, &rsp); err != nil {
+		return nil, err
+	}
+
+	if len(rsp.Addresses) != int(n) {
+		return nil, fmt.Errorf("hardware wallet daemon returned %d addresses, expected %d", len(rsp.Addresses), n)
+	}
+
+	return rsp.Addresses, nil
+}
+
+type daemonTransactionInput struct {
+	Index uint32 `json:"index"`
+	Hash  string `json:"hash"`
+}
+
+type daemonTransactionOutput struct {
+	AddressIndex *uint32 `json:"address_index,omitempty"`
+	Address      string  `json:"address"`
+	Coins        string  `json:"coins"`
+	Hours        string  `json:"hours"`
+}
+
+type daemonTransactionSignRequest struct {
+	TransactionInputs  []daemonTransactionInput  `json:"transaction_inputs"`
+	TransactionOutputs []daemonTransactionOutput `json:"transaction_outputs"`
+}
+
+// TransactionSign signs all inputs of a transaction on the device, after the user confirms its outputs.
+// It returns a hex encoded signature for each input.
+func (d *DaemonDevice) TransactionSign(inputs []TransactionInput, outputs []TransactionOutput) ([]string, error) {
+	req := daemonTransactionSignRequest{
+		TransactionInputs:  make([]daemonTransactionInput, len(inputs)),
+		TransactionOutputs: make([]daemonTransactionOutput, len(outputs)),
+	}
+
+	for i, in := range inputs {
+		req.TransactionInputs[i] = daemonTransactionInput{
+			Index: in.Index,
+			Hash:  in.HashIn,
+		}
+	}
+
+	for i, o := range outputs {
+		coins, err := droplet.ToString(o.Coins)
+		if err != nil {
+			return nil, err
+		}
+
+		req.TransactionOutputs[i] = daemonTransactionOutput{
+			AddressIndex: o.AddressIndex,
+			Address:      o.Address,
+			Coins:        coins,
+			Hours:        strconv.FormatUint(o.Hours, 10),
+		}
+	}
+
+	var sigs []string
+	if err := d.do(http.MethodPost, "/api/v1/transaction_sign", req, &sigs); err != nil {
+		return nil, err
+	}
+
+	return sigs, nil
+}
+
+// do makes a request to the daemon and decodes the "data" field of the response into data.
+// POST requests carry a CSRF token, which is fetched again if the daemon rejects it.
+func (d *DaemonDevice) do(method, endpoint string, body, data interface{}) error {
+	var b []byte
+	if body != nil {
+		var err error
+		b, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := d.doOnce(method, endpoint, b, data)
+	if e, ok := err.(DaemonError); ok && e.StatusCode == http.StatusForbidden && method == http.MethodPost {
+		d.setCSRFToken("")
+		err = d.doOnce(method, endpoint, b, data)
+	}
+
+	return err
+}
+
+func (d *DaemonDevice) doOnce(method, endpoint string, body []byte, data interface{}) error {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, d.addr+endpoint, r)
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if method == http.MethodPost {
+		token, err := d.getCSRFToken()
+		if err != nil {
+			return err
+		}
+		req.Header.Set(daemonCSRFHeader, token)
+	}
+
+	rsp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	return decodeDaemonResponse(rsp, data)
+}
+
+func decodeDaemonResponse(rsp *http.Response, data interface{}) error {
+	b, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return err
+	}
+
+	if rsp.StatusCode != http.StatusOK {
+		var e daemonErrorResponse
+		if err := json.Unmarshal(b, &e); err == nil && e.Error != nil {
+			return DaemonError{
+				StatusCode: rsp.StatusCode,
+				Message:    e.Error.Message,
+			}
+		}
+
+		return DaemonError{
+			StatusCode: rsp.StatusCode,
+			Message:    strings.TrimSpace(string(b)),
+		}
+	}
+
+	v := struct {
+		Data interface{} `json:"data"`
+	}{
+		Data: data,
+	}
+
+	return json.Unmarshal(b, &v)
+}
+
+func (d *DaemonDevice) getCSRFToken() (string, error) {
+	d.csrfLock.Lock()
+	token := d.csrfToken
+	d.csrfLock.Unlock()
+	if token != "" {
+		return token, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, d.addr+"/api/v1/csrf", nil)
+	if err != nil {
+		return "", err
+	}
+
+	rsp, err := d.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return "", decodeDaemonResponse(rsp, nil)
+	}
+
+	var v struct {
+		CSRFToken string `json:"csrf_token"`
+	}
+	if err := json.NewDecoder(rsp.Body).Decode(&v); err != nil {
+		return "", err
+	}
+
+	if v.CSRFToken == "" {
+		return "", errors.New("hardware wallet daemon returned an empty CSRF token")
+	}
+
+	d.setCSRFToken(v.CSRFToken)
+	return v.CSRFToken, nil
+}
+
+func (d *DaemonDevice) setCSRFToken(token string) {
+	d.csrfLock.Lock()
+	defer d.csrfLock.Unlock()
+	d.csrfToken = token
+}
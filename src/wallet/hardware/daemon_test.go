@@ -0,0 +1,161 @@
+package hardware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDaemon serves the hardware wallet daemon API endpoints used by DaemonDevice
+type fakeDaemon struct {
+	t          *testing.T
+	csrfToken  string
+	csrfCalls  int
+	available  bool
+	lastBody   map[string]interface{}
+	addresses  []string
+	signatures []string
+}
+
+func (f *fakeDaemon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	writeJSON := func(status int, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		err := json.NewEncoder(w).Encode(v)
+		require.NoError(f.t, err)
+	}
+
+	writeData := func(v interface{}) {
+		writeJSON(http.StatusOK, map[string]interface{}{
+			"data": v,
+		})
+	}
+
+	if r.URL.Path == "/api/v1/csrf" {
+		f.csrfCalls++
+		writeJSON(http.StatusOK, map[string]string{
+			"csrf_token": f.csrfToken,
+		})
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		if r.Header.Get(daemonCSRFHeader) != f.csrfToken {
+			writeJSON(http.StatusForbidden, map[string]interface{}{
+				"error": map[string]interface{}{
+					"message": "invalid CSRF token",
+					"code":    http.StatusForbidden,
+				},
+			})
+			return
+		}
+
+		f.lastBody = nil
+		err := json.NewDecoder(r.Body).Decode(&f.lastBody)
+		require.NoError(f.t, err)
+	}
+
+	switch r.URL.Path {
+	case "/api/v1/available":
+		writeData(f.available)
+	case "/api/v1/generate_addresses":
+		writeData(map[string]interface{}{
+			"addresses": f.addresses,
+		})
+	case "/api/v1/transaction_sign":
+		if f.signatures == nil {
+			writeJSON(http.StatusConflict, map[string]interface{}{
+				"error": map[string]interface{}{
+					"message": "Action cancelled by user",
+					"code":    http.StatusConflict,
+				},
+			})
+			return
+		}
+		writeData(f.signatures)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestDaemonDevice(t *testing.T) {
+	f := &fakeDaemon{
+		t:         t,
+		csrfToken: "token",
+		available: true,
+		addresses: []string{"2HTnQe3ZupkG6k8S81brNC3JycGV2Em71F2"},
+	}
+	s := httptest.NewServer(f)
+	defer s.Close()
+
+	d := NewDaemonDevice(s.URL+"/", time.Second)
+	require.True(t, d.Connected())
+
+	f.available = false
+	require.False(t, d.Connected())
+
+	addrs, err := d.AddressGen(1, 3, true)
+	require.NoError(t, err)
+	require.Equal(t, f.addresses, addrs)
+	require.Equal(t, map[string]interface{}{
+		"address_n":       float64(1),
+		"start_index":     float64(3),
+		"confirm_address": true,
+	}, f.lastBody)
+
+	_, err = d.AddressGen(2, 0, true)
+	require.EqualError(t, err, "only one address can be confirmed at a time")
+
+	_, err = d.AddressGen(2, 0, false)
+	require.EqualError(t, err, "hardware wallet daemon returned 1 addresses, expected 2")
+
+	index := uint32(1)
+	f.signatures = []string{"sig0", "sig1"}
+	sigs, err := d.TransactionSign([]TransactionInput{
+		{HashIn: "hash0", Index: 0},
+		{HashIn: "hash1", Index: 4},
+	}, []TransactionOutput{
+		{Address: "addr0", Coins: 1e6, Hours: 2},
+		{Address: "addr1", Coins: 1500000, Hours: 0, AddressIndex: &index},
+	})
+	require.NoError(t, err)
+	require.Equal(t, f.signatures, sigs)
+	require.Equal(t, map[string]interface{}{
+		"transaction_inputs": []interface{}{
+			map[string]interface{}{"index": float64(0), "hash": "hash0"},
+			map[string]interface{}{"index": float64(4), "hash": "hash1"},
+		},
+		"transaction_outputs": []interface{}{
+			map[string]interface{}{"address": "addr0", "coins": "1.000000", "hours": "2"},
+			map[string]interface{}{"address": "addr1", "coins": "1.500000", "hours": "0", "address_index": float64(1)},
+		},
+	}, f.lastBody)
+
+	// The CSRF token is fetched once, and again if the daemon rejects it
+	require.Equal(t, 1, f.csrfCalls)
+	f.csrfToken = "token2"
+	_, err = d.AddressGen(1, 0, false)
+	require.NoError(t, err)
+	require.Equal(t, 2, f.csrfCalls)
+
+	// Error responses are returned as DaemonError
+	f.signatures = nil
+	_, err = d.TransactionSign(nil, nil)
+	require.Equal(t, DaemonError{
+		StatusCode: http.StatusConflict,
+		Message:    "Action cancelled by user",
+	}, err)
+
+	// The device is not connected if the daemon is not reachable
+	s.Close()
+	require.False(t, d.Connected())
+}
+
+func TestNewDaemonDevice(t *testing.T) {
+	require.Equal(t, "http://127.0.0.1:9510", NewDaemonDevice(DefaultDaemonAddr, DefaultDaemonTimeout).addr)
+	require.Equal(t, "https://example.com", NewDaemonDevice("https://example.com/", DefaultDaemonTimeout).addr)
+}
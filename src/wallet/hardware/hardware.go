@@ -0,0 +1,234 @@
+/*
+Package hardware delegates the signing of transaction inputs to a hardware wallet device,
+such as the Skycoin hardware wallet, so that secret keys never leave the device.
+
+The node only stores the device's addresses, in a watch-only wallet created from the addresses
+listed by the device. The child number of each wallet entry is the address index of the address on the device.
+DaemonDevice connects to the device through the Skycoin hardware wallet daemon.
+*/
+package hardware
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/util/logging"
+	"github.com/SkycoinProject/cx-chains/src/wallet"
+)
+
+var (
+	logger = logging.MustGetLogger("hardware")
+
+	// ErrNoDevice is returned if no hardware wallet device is connected
+	ErrNoDevice = errors.New("hardware wallet device is not connected")
+	// ErrAddressMismatch is returned if the device's address at an address index is not the wallet's address
+	ErrAddressMismatch = wallet.NewError(errors.New("hardware wallet device address does not match the wallet address"))
+	// ErrProgramStateNotSupported is returned if a transaction output has a program state, which the device cannot sign
+	ErrProgramStateNotSupported = wallet.NewError(errors.New("hardware wallet device cannot sign transaction outputs with a program state"))
+	// ErrInvalidSignature is returned if a signature returned by the device is not valid for the input
+	ErrInvalidSignature = errors.New("hardware wallet device returned an invalid signature")
+)
+
+// TransactionInput is a transaction input sent to the device for signing
+type TransactionInput struct {
+	HashIn string
+	// Index is the address index of the input's address on the device
+	Index uint32
+}
+
+// TransactionOutput is a transaction output sent to the device for signing
+type TransactionOutput struct {
+	Address string
+	Coins   uint64
+	Hours   uint64
+	// AddressIndex is the address index of the output's address on the device, if the device owns it.
+	// The device does not ask the user to confirm outputs to its own addresses, such as change outputs.
+	AddressIndex *uint32
+}
+
+// Device is a hardware wallet device, connected over the Skycoin hardware wallet protocol
+type Device interface {
+	// Connected returns true if a device is connected
+	Connected() bool
+	// AddressGen returns n addresses of the device, starting from startIndex.
+	// If confirm is true, the device shows the address and waits for the user to confirm it; n must be 1.
+	AddressGen(n, startIndex uint32, confirm bool) ([]string, error)
+	// TransactionSign signs all inputs of a transaction on the device, after the user confirms its outputs.
+	// It returns a hex encoded signature for each input.
+	TransactionSign(inputs []TransactionInput, outputs []TransactionOutput) ([]string, error)
+}
+
+// AddressIndexes returns the device address index of each address in the wallet, which is the entry's child number
+func AddressIndexes(w *wallet.Wallet) map[cipher.Address]uint32 {
+	indexes := make(map[cipher.Address]uint32, len(w.Entries))
+	for _, e := range w.Entries {
+		indexes[e.SkycoinAddress()] = e.ChildNumber
+	}
+	return indexes
+}
+
+// WalletOptions returns the options of a watch-only wallet of the device's n addresses starting from startIndex,
+// with the address index of each address as its child number
+func WalletOptions(d Device, opts wallet.Options, n, startIndex uint32) (wallet.Options, error) {
+	if d == nil || !d.Connected() {
+		return wallet.Options{}, ErrNoDevice
+	}
+
+	if n == 0 {
+		return wallet.Options{}, wallet.NewError(errors.New("the number of hardware wallet addresses must be > 0"))
+	}
+
+	addrs, err := d.AddressGen(n, startIndex, false)
+	if err != nil {
+		return wallet.Options{}, err
+	}
+
+	if len(addrs) != int(n) {
+		return wallet.Options{}, fmt.Errorf("hardware wallet device returned %d addresses, expected %d", len(addrs), n)
+	}
+
+	opts.Type = wallet.WalletTypeWatchOnly
+	opts.Addresses = make([]cipher.Addresser, n)
+	opts.ChildNumbers = make([]uint32, n)
+	for i, s := range addrs {
+		a, err := cipher.DecodeBase58Address(s)
+		if err != nil {
+			return wallet.Options{}, fmt.Errorf("hardware wallet device returned an invalid address %q: %v", s, err)
+		}
+
+		opts.Addresses[i] = a
+		opts.ChildNumbers[i] = startIndex + uint32(i)
+	}
+
+	return opts, nil
+}
+
+// ConfirmAddress shows the address at the wallet's address index on the device, for the user to confirm.
+// It returns an error if the wallet does not have the address, or the device's address does not match it.
+func ConfirmAddress(d Device, w *wallet.Wallet, addr cipher.Address) error {
+	if d == nil || !d.Connected() {
+		return ErrNoDevice
+	}
+
+	index, ok := AddressIndexes(w)[addr]
+	if !ok {
+		return wallet.ErrUnknownAddress
+	}
+
+	addrs, err := d.AddressGen(1, index, true)
+	if err != nil {
+		return err
+	}
+
+	return checkDeviceAddress(addrs, addr)
+}
+
+// SignTransaction signs all inputs of txn with the device. uxOuts are the outputs spent by the inputs of txn,
+// whose addresses must be in the wallet. The device must not have signed any of the inputs already.
+// The device's addresses are checked against the wallet's, and the returned signatures are verified.
+func SignTransaction(d Device, w *wallet.Wallet, txn *coin.Transaction, uxOuts []coin.UxOut) (*coin.Transaction, error) {
+	if d == nil || !d.Connected() {
+		return nil, ErrNoDevice
+	}
+
+	if len(uxOuts) != len(txn.In) {
+		return nil, errors.New("len(uxOuts) != len(txn.In)")
+	}
+
+	if txn.InnerHash != txn.HashInner() {
+		return nil, wallet.NewError(errors.New("Transaction inner hash does not match computed inner hash"))
+	}
+
+	for _, s := range txn.Sigs {
+		if !s.Null() {
+			return nil, wallet.NewError(errors.New("Transaction is already partially signed"))
+		}
+	}
+
+	indexes := AddressIndexes(w)
+
+	inputs := make([]TransactionInput, len(txn.In))
+	checked := make(map[cipher.Address]struct{}, len(uxOuts))
+	for i, ux := range uxOuts {
+		addr := ux.Body.Address
+		index, ok := indexes[addr]
+		if !ok {
+			return nil, wallet.NewError(errors.New("Wallet cannot sign all requested inputs"))
+		}
+
+		// Make sure the device derives the wallet's address at the index, so that it is the right device
+		if _, ok := checked[addr]; !ok {
+			addrs, err := d.AddressGen(1, index, false)
+			if err != nil {
+				return nil, err
+			}
+			if err := checkDeviceAddress(addrs, addr); err != nil {
+				return nil, err
+			}
+			checked[addr] = struct{}{}
+		}
+
+		inputs[i] = TransactionInput{
+			HashIn: txn.In[i].Hex(),
+			Index:  index,
+		}
+	}
+
+	outputs := make([]TransactionOutput, len(txn.Out))
+	for i, o := range txn.Out {
+		if len(o.ProgramState) != 0 {
+			return nil, ErrProgramStateNotSupported
+		}
+
+		outputs[i] = TransactionOutput{
+			Address: o.Address.String(),
+			Coins:   o.Coins,
+			Hours:   o.Hours,
+		}
+
+		if index, ok := indexes[o.Address]; ok {
+			index := index
+			outputs[i].AddressIndex = &index
+		}
+	}
+
+	sigs, err := d.TransactionSign(inputs, outputs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sigs) != len(txn.In) {
+		return nil, fmt.Errorf("hardware wallet device returned %d signatures for %d inputs", len(sigs), len(txn.In))
+	}
+
+	signedTxn := *txn
+	signedTxn.Sigs = make([]cipher.Sig, len(sigs))
+	for i, s := range sigs {
+		sig, err := cipher.SigFromHex(s)
+		if err != nil {
+			return nil, ErrInvalidSignature
+		}
+
+		h := cipher.AddSHA256(txn.InnerHash, txn.In[i])
+		if err := cipher.VerifyAddressSignedHash(uxOuts[i].Body.Address, sig, h); err != nil {
+			return nil, ErrInvalidSignature
+		}
+
+		signedTxn.Sigs[i] = sig
+	}
+
+	if err := signedTxn.UpdateHeader(); err != nil {
+		return nil, err
+	}
+
+	return &signedTxn, nil
+}
+
+func checkDeviceAddress(addrs []string, addr cipher.Address) error {
+	if len(addrs) != 1 || addrs[0] != addr.String() {
+		return ErrAddressMismatch
+	}
+	return nil
+}
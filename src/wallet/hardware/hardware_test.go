@@ -0,0 +1,308 @@
+package hardware
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+	"github.com/SkycoinProject/cx-chains/src/wallet"
+)
+
+// fakeDevice is a Device that derives its addresses from a seed
+type fakeDevice struct {
+	keys      []cipher.SecKey
+	connected bool
+	confirmed []uint32
+	outputs   []TransactionOutput
+	// badSig makes the device return a signature of a different key
+	badSig bool
+}
+
+func newFakeDevice(seed string, n int) *fakeDevice {
+	return &fakeDevice{
+		keys:      cipher.MustGenerateDeterministicKeyPairs([]byte(seed), n),
+		connected: true,
+	}
+}
+
+func (d *fakeDevice) Connected() bool {
+	return d.connected
+}
+
+func (d *fakeDevice) AddressGen(n, startIndex uint32, confirm bool) ([]string, error) {
+	if int(startIndex+n) > len(d.keys) {
+		return nil, errors.New("address index out of range")
+	}
+
+	if confirm {
+		d.confirmed = append(d.confirmed, startIndex)
+	}
+
+	addrs := make([]string, n)
+	for i := range addrs {
+		addrs[i] = cipher.MustAddressFromSecKey(d.keys[startIndex+uint32(i)]).String()
+	}
+	return addrs, nil
+}
+
+// TransactionSign computes the inner hash of the transaction from its inputs and outputs, like the device does,
+// and signs each input with the key at its address index
+func (d *fakeDevice) TransactionSign(inputs []TransactionInput, outputs []TransactionOutput) ([]string, error) {
+	d.outputs = outputs
+
+	txn := coin.Transaction{}
+	for _, in := range inputs {
+		h, err := cipher.SHA256FromHex(in.HashIn)
+		if err != nil {
+			return nil, err
+		}
+		if err := txn.PushInput(h); err != nil {
+			return nil, err
+		}
+	}
+	for _, o := range outputs {
+		addr, err := cipher.DecodeBase58Address(o.Address)
+		if err != nil {
+			return nil, err
+		}
+		if err := txn.PushOutput(addr, o.Coins, o.Hours, nil); err != nil {
+			return nil, err
+		}
+	}
+	innerHash := txn.HashInner()
+
+	sigs := make([]string, len(inputs))
+	for i, in := range inputs {
+		key := d.keys[in.Index]
+		if d.badSig {
+			key = d.keys[(in.Index+1)%uint32(len(d.keys))]
+		}
+
+		sigs[i] = cipher.MustSignHash(cipher.AddSHA256(innerHash, txn.In[i]), key).Hex()
+	}
+	return sigs, nil
+}
+
+func makeDeviceWallet(t *testing.T, d *fakeDevice, n int) *wallet.Wallet {
+	opts, err := WalletOptions(d, wallet.Options{
+		Coin: wallet.CoinTypeSkycoin,
+	}, uint32(n), 0)
+	require.NoError(t, err)
+
+	w, err := wallet.NewWallet("hw.wlt", opts)
+	require.NoError(t, err)
+	return w
+}
+
+func makeUxOut(t *testing.T, addr cipher.Address) coin.UxOut {
+	return coin.UxOut{
+		Body: coin.UxBody{
+			SrcTransaction: testutil.RandSHA256(t),
+			Address:        addr,
+			Coins:          2e6,
+			Hours:          100,
+		},
+	}
+}
+
+func TestSignTransaction(t *testing.T) {
+	d := newFakeDevice("hardware", 4)
+	w := makeDeviceWallet(t, d, 3)
+
+	ux0 := makeUxOut(t, w.Entries[0].SkycoinAddress())
+	ux0b := makeUxOut(t, w.Entries[0].SkycoinAddress())
+	ux2 := makeUxOut(t, w.Entries[2].SkycoinAddress())
+	uxOther := makeUxOut(t, testutil.MakeAddress())
+	// The device has this address, but the wallet does not
+	uxNotInWallet := makeUxOut(t, cipher.MustAddressFromSecKey(d.keys[3]))
+
+	makeTxn := func(uxOuts []coin.UxOut, programState []byte) *coin.Transaction {
+		txn := &coin.Transaction{}
+		for _, ux := range uxOuts {
+			err := txn.PushInput(ux.Hash())
+			require.NoError(t, err)
+		}
+		err := txn.PushOutput(testutil.MakeAddress(), 1e6, 10, programState)
+		require.NoError(t, err)
+		// Change output to a wallet address
+		err = txn.PushOutput(w.Entries[1].SkycoinAddress(), uint64(len(uxOuts))*2e6-1e6, 10, nil)
+		require.NoError(t, err)
+		txn.Sigs = make([]cipher.Sig, len(uxOuts))
+		err = txn.UpdateHeader()
+		require.NoError(t, err)
+		return txn
+	}
+
+	tt := []struct {
+		name         string
+		uxOuts       []coin.UxOut
+		programState []byte
+		disconnected bool
+		badSig       bool
+		otherDevice  bool
+		err          error
+	}{
+		{
+			name:   "sign all inputs",
+			uxOuts: []coin.UxOut{ux0, ux2, ux0b},
+		},
+		{
+			name:         "device not connected",
+			uxOuts:       []coin.UxOut{ux0},
+			disconnected: true,
+			err:          ErrNoDevice,
+		},
+		{
+			name:   "input not owned by wallet",
+			uxOuts: []coin.UxOut{ux0, uxOther},
+			err:    wallet.NewError(errors.New("Wallet cannot sign all requested inputs")),
+		},
+		{
+			name:   "input on device but not in wallet",
+			uxOuts: []coin.UxOut{uxNotInWallet},
+			err:    wallet.NewError(errors.New("Wallet cannot sign all requested inputs")),
+		},
+		{
+			name:         "output program state",
+			uxOuts:       []coin.UxOut{ux0},
+			programState: []byte("state"),
+			err:          ErrProgramStateNotSupported,
+		},
+		{
+			name:   "invalid signature",
+			uxOuts: []coin.UxOut{ux0},
+			badSig: true,
+			err:    ErrInvalidSignature,
+		},
+		{
+			name:        "wallet of another device",
+			uxOuts:      []coin.UxOut{ux0},
+			otherDevice: true,
+			err:         ErrAddressMismatch,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			txn := makeTxn(tc.uxOuts, tc.programState)
+
+			fd := d
+			if tc.otherDevice {
+				fd = newFakeDevice("other", 4)
+			}
+			fd.connected = !tc.disconnected
+			fd.badSig = tc.badSig
+			defer func() {
+				d.connected = true
+				d.badSig = false
+			}()
+
+			signedTxn, err := SignTransaction(fd, w, txn, tc.uxOuts)
+			require.Equal(t, tc.err, err)
+			if tc.err != nil {
+				return
+			}
+
+			require.True(t, signedTxn.IsFullySigned())
+			require.Equal(t, txn.InnerHash, signedTxn.InnerHash)
+			require.NotEqual(t, txn.Hash(), signedTxn.Hash())
+
+			// The unsigned transaction is not modified
+			require.False(t, txn.IsFullySigned())
+
+			uxIn := coin.UxArray(tc.uxOuts)
+			err = signedTxn.VerifyInputSignatures(uxIn)
+			require.NoError(t, err)
+
+			// The change output is marked with its address index, so that the device does not ask to confirm it
+			require.Len(t, fd.outputs, 2)
+			require.Nil(t, fd.outputs[0].AddressIndex)
+			require.NotNil(t, fd.outputs[1].AddressIndex)
+			require.Equal(t, uint32(1), *fd.outputs[1].AddressIndex)
+		})
+	}
+}
+
+func TestSignTransactionAlreadySigned(t *testing.T) {
+	d := newFakeDevice("hardware", 2)
+	w := makeDeviceWallet(t, d, 2)
+	ux := makeUxOut(t, w.Entries[0].SkycoinAddress())
+
+	txn := &coin.Transaction{}
+	err := txn.PushInput(ux.Hash())
+	require.NoError(t, err)
+	err = txn.PushOutput(testutil.MakeAddress(), 2e6, 10, nil)
+	require.NoError(t, err)
+	txn.Sigs = []cipher.Sig{cipher.MustSignHash(testutil.RandSHA256(t), d.keys[0])}
+	err = txn.UpdateHeader()
+	require.NoError(t, err)
+
+	_, err = SignTransaction(d, w, txn, []coin.UxOut{ux})
+	require.Equal(t, wallet.NewError(errors.New("Transaction is already partially signed")), err)
+}
+
+func TestConfirmAddress(t *testing.T) {
+	d := newFakeDevice("hardware", 3)
+	w := makeDeviceWallet(t, d, 2)
+
+	err := ConfirmAddress(d, w, w.Entries[1].SkycoinAddress())
+	require.NoError(t, err)
+	require.Equal(t, []uint32{1}, d.confirmed)
+
+	err = ConfirmAddress(d, w, cipher.MustAddressFromSecKey(d.keys[2]))
+	require.Equal(t, wallet.ErrUnknownAddress, err)
+
+	other := newFakeDevice("other", 3)
+	err = ConfirmAddress(other, w, w.Entries[0].SkycoinAddress())
+	require.Equal(t, ErrAddressMismatch, err)
+
+	err = ConfirmAddress(nil, w, w.Entries[0].SkycoinAddress())
+	require.Equal(t, ErrNoDevice, err)
+
+	d.connected = false
+	err = ConfirmAddress(d, w, w.Entries[0].SkycoinAddress())
+	require.Equal(t, ErrNoDevice, err)
+}
+
+func TestWalletOptions(t *testing.T) {
+	d := newFakeDevice("seed", 6)
+
+	opts, err := WalletOptions(d, wallet.Options{
+		Label: "hw",
+	}, 3, 2)
+	require.NoError(t, err)
+	require.Equal(t, "hw", opts.Label)
+	require.Equal(t, wallet.WalletTypeWatchOnly, opts.Type)
+	require.Equal(t, []uint32{2, 3, 4}, opts.ChildNumbers)
+	require.Len(t, opts.Addresses, 3)
+	for i, a := range opts.Addresses {
+		require.Equal(t, cipher.MustAddressFromSecKey(d.keys[i+2]), a)
+	}
+
+	// The device address index is the entry's child number, not its position in the wallet
+	w, err := wallet.NewWallet("hw.wlt", opts)
+	require.NoError(t, err)
+	require.Equal(t, map[cipher.Address]uint32{
+		opts.Addresses[0].(cipher.Address): 2,
+		opts.Addresses[1].(cipher.Address): 3,
+		opts.Addresses[2].(cipher.Address): 4,
+	}, AddressIndexes(w))
+
+	err = ConfirmAddress(d, w, opts.Addresses[1].(cipher.Address))
+	require.NoError(t, err)
+	require.Equal(t, []uint32{3}, d.confirmed)
+
+	_, err = WalletOptions(d, wallet.Options{}, 0, 0)
+	require.Error(t, err)
+
+	_, err = WalletOptions(d, wallet.Options{}, 5, 2)
+	require.EqualError(t, err, "address index out of range")
+
+	d.connected = false
+	_, err = WalletOptions(d, wallet.Options{}, 1, 0)
+	require.Equal(t, ErrNoDevice, err)
+}
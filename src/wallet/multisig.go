@@ -0,0 +1,99 @@
+package wallet
+
+import (
+	"errors"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+)
+
+// MultisigAddress creates a multisig address shared by the wallet address addr and the cosigners' public keys,
+// which requires signatures by required of the public keys to spend. It returns the address and its sorted public keys.
+func (w *Wallet) MultisigAddress(addr cipher.Address, required int, cosigners []cipher.PubKey) (cipher.Address, []cipher.PubKey, error) {
+	e, ok := w.GetEntry(addr)
+	if !ok {
+		return cipher.Address{}, nil, ErrUnknownAddress
+	}
+
+	if e.Public == (cipher.PubKey{}) {
+		return cipher.Address{}, nil, NewError(errors.New("wallet address has no public key"))
+	}
+
+	pubKeys := append([]cipher.PubKey{e.Public}, cosigners...)
+	msAddr, err := cipher.MultisigAddress(required, pubKeys)
+	if err != nil {
+		return cipher.Address{}, nil, NewError(err)
+	}
+
+	return msAddr, cipher.SortMultisigPubKeys(pubKeys), nil
+}
+
+// SignMultisigTransaction signs the inputs of a multisig transaction whose witnesses include
+// a public key of the wallet. uxOuts are the outputs spent by the inputs of txn.
+// Inputs that already have the required number of signatures, or the wallet's signature, are not signed again.
+func (w *Wallet) SignMultisigTransaction(txn *coin.Transaction, uxOuts []coin.UxOut) (*coin.Transaction, error) {
+	if w.IsWatchOnly() {
+		return nil, ErrWalletWatchOnly
+	}
+
+	if w.IsEncrypted() {
+		return nil, ErrWalletEncrypted
+	}
+
+	if !txn.IsMultisig() {
+		return nil, NewError(coin.ErrNotMultisigTransaction)
+	}
+
+	if txn.InnerHash != txn.HashInner() {
+		return nil, NewError(errors.New("Transaction inner hash does not match computed inner hash"))
+	}
+
+	if len(uxOuts) != len(txn.In) {
+		return nil, errors.New("len(uxOuts) != len(txn.In)")
+	}
+
+	witnesses, err := txn.MultisigWitnesses()
+	if err != nil {
+		return nil, NewError(err)
+	}
+
+	keys := make(map[cipher.PubKey]cipher.SecKey, len(w.Entries))
+	for _, e := range w.Entries {
+		keys[e.Public] = e.Secret
+	}
+
+	signedTxn := copyTransaction(txn)
+
+	signed := 0
+	for i, wit := range witnesses {
+		addr, err := wit.Address()
+		if err != nil {
+			return nil, NewError(err)
+		}
+		if addr != uxOuts[i].Body.Address {
+			return nil, NewError(errors.New("Multisig witness does not match the address of the output being spent"))
+		}
+
+		if wit.Complete() {
+			continue
+		}
+
+		for j, p := range wit.PubKeys {
+			key, ok := keys[p]
+			if !ok || !wit.Sigs[j].Null() {
+				continue
+			}
+
+			if err := signedTxn.SignMultisigInput(key, i); err != nil {
+				return nil, err
+			}
+			signed++
+		}
+	}
+
+	if signed == 0 {
+		return nil, NewError(errors.New("Wallet cannot sign any multisig inputs"))
+	}
+
+	return signedTxn, nil
+}
@@ -0,0 +1,127 @@
+package wallet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/params"
+	"github.com/SkycoinProject/cx-chains/src/testutil"
+)
+
+// enableMultisig sets params.MultisigActivationSeq and returns a function which restores it
+func enableMultisig(seq uint64) func() {
+	prev := params.MultisigActivationSeq
+	params.MultisigActivationSeq = seq
+	return func() {
+		params.MultisigActivationSeq = prev
+	}
+}
+
+func TestWalletMultisigAddress(t *testing.T) {
+	w := &Wallet{}
+	e := makeEntry()
+	err := w.AddEntry(e)
+	require.NoError(t, err)
+
+	cosigner, _ := cipher.GenerateKeyPair()
+
+	addr, pubKeys, err := w.MultisigAddress(e.SkycoinAddress(), 2, []cipher.PubKey{cosigner})
+	require.NoError(t, err)
+	require.True(t, addr.IsMultisig())
+	require.Equal(t, cipher.MustMultisigAddress(2, []cipher.PubKey{cosigner, e.Public}), addr)
+	require.Equal(t, cipher.SortMultisigPubKeys([]cipher.PubKey{e.Public, cosigner}), pubKeys)
+
+	_, _, err = w.MultisigAddress(makeAddress(), 2, []cipher.PubKey{cosigner})
+	require.Equal(t, ErrUnknownAddress, err)
+
+	_, _, err = w.MultisigAddress(e.SkycoinAddress(), 3, []cipher.PubKey{cosigner})
+	require.Equal(t, NewError(cipher.ErrMultisigRequired), err)
+
+	_, _, err = w.MultisigAddress(e.SkycoinAddress(), 1, []cipher.PubKey{e.Public})
+	require.Equal(t, NewError(cipher.ErrMultisigDuplicatePubKey), err)
+}
+
+func TestWalletSignMultisigTransaction(t *testing.T) {
+	defer enableMultisig(0)()
+
+	w := &Wallet{}
+	e0 := makeEntry()
+	e1 := makeEntry()
+	for _, e := range []Entry{e0, e1, makeEntry()} {
+		err := w.AddEntry(e)
+		require.NoError(t, err)
+	}
+
+	cosigner, cosignerKey := cipher.GenerateKeyPair()
+
+	// A 2-of-3 address with two of the wallet's keys, and a 2-of-2 address with one of the wallet's keys
+	pubKeys0 := []cipher.PubKey{e0.Public, e1.Public, cosigner}
+	pubKeys1 := []cipher.PubKey{e0.Public, cosigner}
+	uxs := []coin.UxOut{
+		{Body: coin.UxBody{SrcTransaction: testutil.RandSHA256(t), Address: cipher.MustMultisigAddress(2, pubKeys0), Coins: 1e6, Hours: 100}},
+		{Body: coin.UxBody{SrcTransaction: testutil.RandSHA256(t), Address: cipher.MustMultisigAddress(2, pubKeys1), Coins: 1e6, Hours: 100}},
+	}
+
+	w0, err := coin.NewMultisigWitness(2, pubKeys0)
+	require.NoError(t, err)
+	w1, err := coin.NewMultisigWitness(2, pubKeys1)
+	require.NoError(t, err)
+
+	txn := coin.Transaction{}
+	for _, ux := range uxs {
+		err := txn.PushInput(ux.Hash())
+		require.NoError(t, err)
+	}
+	err = txn.PushOutput(makeAddress(), 2e6, 50, nil)
+	require.NoError(t, err)
+	err = txn.SetMultisigWitnesses([]coin.MultisigWitness{w0, w1})
+	require.NoError(t, err)
+
+	signedTxn, err := w.SignMultisigTransaction(&txn, uxs)
+	require.NoError(t, err)
+
+	// The unsigned transaction is not modified
+	require.True(t, txn.IsFullyUnsigned())
+
+	// The first input is fully signed by the wallet, the second needs the cosigner
+	witnesses, err := signedTxn.MultisigWitnesses()
+	require.NoError(t, err)
+	require.True(t, witnesses[0].Complete())
+	require.Equal(t, 1, witnesses[1].SignedCount())
+	require.False(t, signedTxn.IsFullySigned())
+	require.NoError(t, signedTxn.VerifyPartialInputSignatures(uxs))
+
+	// The wallet cannot sign the transaction again
+	_, err = w.SignMultisigTransaction(signedTxn, uxs)
+	require.Equal(t, NewError(errors.New("Wallet cannot sign any multisig inputs")), err)
+
+	err = signedTxn.SignMultisigInput(cosignerKey, 1)
+	require.NoError(t, err)
+	require.True(t, signedTxn.IsFullySigned())
+	require.NoError(t, signedTxn.Verify())
+	require.NoError(t, signedTxn.VerifyInputSignatures(uxs))
+
+	// Standard signing of a multisig transaction is not allowed
+	_, err = w.SignTransaction(&txn, nil, uxs)
+	require.Equal(t, ErrMultisigTransaction, err)
+
+	// The outputs being spent must match the witnesses
+	_, err = w.SignMultisigTransaction(&txn, []coin.UxOut{uxs[1], uxs[0]})
+	require.Equal(t, NewError(errors.New("Multisig witness does not match the address of the output being spent")), err)
+
+	// A standard transaction is not a multisig transaction
+	stdTxn, stdUxs, _ := makeTransaction(t, 1)
+	_, err = w.SignMultisigTransaction(&stdTxn, stdUxs)
+	require.Equal(t, NewError(coin.ErrNotMultisigTransaction), err)
+
+	// A wallet without the keys cannot sign
+	other := &Wallet{}
+	err = other.AddEntry(makeEntry())
+	require.NoError(t, err)
+	_, err = other.SignMultisigTransaction(&txn, uxs)
+	require.Equal(t, NewError(errors.New("Wallet cannot sign any multisig inputs")), err)
+}
@@ -10,14 +10,17 @@ import (
 
 // ReadableEntry wallet entry with json tags
 type ReadableEntry struct {
-	Address string `json:"address"`
-	Public  string `json:"public_key"`
-	Secret  string `json:"secret_key"`
+	Address     string `json:"address"`
+	Public      string `json:"public_key"`
+	Secret      string `json:"secret_key"`
+	ChildNumber uint32 `json:"child_number,omitempty"`
 }
 
 // NewReadableEntry creates readable wallet entry
 func NewReadableEntry(coinType CoinType, w Entry) ReadableEntry {
-	re := ReadableEntry{}
+	re := ReadableEntry{
+		ChildNumber: w.ChildNumber,
+	}
 	if !w.Address.Null() {
 		re.Address = w.Address.String()
 	}
@@ -45,10 +48,10 @@ type ReadableEntries []ReadableEntry
 
 // ToWalletEntries convert readable entries to entries
 // converts base on the wallet version.
-func (res ReadableEntries) toWalletEntries(coinType CoinType, isEncrypted bool) ([]Entry, error) {
+func (res ReadableEntries) toWalletEntries(coinType CoinType, walletType string, isEncrypted bool) ([]Entry, error) {
 	entries := make([]Entry, len(res))
 	for i, re := range res {
-		e, err := newEntryFromReadable(coinType, &re)
+		e, err := newEntryFromReadable(coinType, walletType, &re)
 		if err != nil {
 			return []Entry{}, err
 		}
@@ -66,7 +69,7 @@ func (res ReadableEntries) toWalletEntries(coinType CoinType, isEncrypted bool)
 }
 
 // newEntryFromReadable creates WalletEntry base one ReadableWalletEntry
-func newEntryFromReadable(coinType CoinType, w *ReadableEntry) (*Entry, error) {
+func newEntryFromReadable(coinType CoinType, walletType string, w *ReadableEntry) (*Entry, error) {
 	var a cipher.Addresser
 	var err error
 
@@ -83,9 +86,13 @@ func newEntryFromReadable(coinType CoinType, w *ReadableEntry) (*Entry, error) {
 		return nil, err
 	}
 
-	p, err := cipher.PubKeyFromHex(w.Public)
-	if err != nil {
-		return nil, err
+	// Watch-only wallets created from addresses have no public keys
+	var p cipher.PubKey
+	if w.Public != "" || walletType != WalletTypeWatchOnly {
+		p, err = cipher.PubKeyFromHex(w.Public)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Decodes the secret hex string if any
@@ -105,9 +112,10 @@ func newEntryFromReadable(coinType CoinType, w *ReadableEntry) (*Entry, error) {
 	}
 
 	return &Entry{
-		Address: a,
-		Public:  p,
-		Secret:  secret,
+		Address:     a,
+		Public:      p,
+		Secret:      secret,
+		ChildNumber: w.ChildNumber,
 	}, nil
 }
 
@@ -154,7 +162,7 @@ func (rw *ReadableWallet) ToWallet() (*Wallet, error) {
 		return nil, fmt.Errorf("invalid wallet %s: %v", w.Filename(), err)
 	}
 
-	ets, err := rw.Entries.toWalletEntries(w.coin(), w.IsEncrypted())
+	ets, err := rw.Entries.toWalletEntries(w.coin(), w.Type(), w.IsEncrypted())
 	if err != nil {
 		return nil, err
 	}
@@ -190,6 +198,7 @@ func (rw *ReadableWallet) Erase() {
 	delete(rw.Meta, metaSeed)
 	delete(rw.Meta, metaLastSeed)
 	delete(rw.Meta, metaSecrets)
+	delete(rw.Meta, metaSeedPassphrase)
 	for i := range rw.Entries {
 		rw.Entries[i].Secret = ""
 	}
@@ -1,35 +1,231 @@
 package wallet
 
-import "encoding/json"
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"unicode/utf16"
+	"unicode/utf8"
+)
 
 // secrets key name
 const (
-	secretSeed     = "seed"
-	secretLastSeed = "lastSeed"
+	secretSeed           = "seed"
+	secretLastSeed       = "lastSeed"
+	secretSeedPassphrase = "seedPassphrase"
 )
 
-type secrets map[string]string
+const hexDigits = "0123456789abcdef"
+
+var errInvalidSecrets = errors.New("invalid secrets data")
+
+// secrets holds the unencrypted secrets of a wallet.
+// The values are kept in byte slices instead of strings, so that erase can wipe them from memory.
+// They are serialized as a JSON object of strings, the format used by earlier versions.
+type secrets map[string][]byte
 
-func (s secrets) get(key string) (string, bool) {
+func (s secrets) get(key string) ([]byte, bool) {
 	v, ok := s[key]
 	return v, ok
 }
 
-func (s secrets) set(key, v string) {
+func (s secrets) set(key string, v []byte) {
 	s[key] = v
 }
 
+// serialize encodes the secrets as a JSON object, without copying the values into strings.
+// The caller should wipe the returned bytes once done with them
 func (s secrets) serialize() ([]byte, error) {
-	return json.Marshal(s)
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// Allocate enough for every byte to be escaped, so that append does not leave
+	// copies of the secrets behind when it grows the slice
+	n := 2
+	for _, k := range keys {
+		n += 6*(len(k)+len(s[k])) + 6
+	}
+
+	b := make([]byte, 0, n)
+	b = append(b, '{')
+	for i, k := range keys {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = appendJSONString(b, []byte(k))
+		b = append(b, ':')
+		b = appendJSONString(b, s[k])
+	}
+	b = append(b, '}')
+
+	return b, nil
 }
 
+// deserialize decodes a JSON object of strings created by serialize
 func (s secrets) deserialize(data []byte) error {
-	return json.Unmarshal(data, &s)
+	d := bytes.TrimSpace(data)
+	if len(d) < 2 || d[0] != '{' || d[len(d)-1] != '}' {
+		return errInvalidSecrets
+	}
+	d = bytes.TrimSpace(d[1 : len(d)-1])
+
+	for len(d) > 0 {
+		k, rest, err := readJSONString(d)
+		if err != nil {
+			return err
+		}
+
+		rest = bytes.TrimSpace(rest)
+		if len(rest) == 0 || rest[0] != ':' {
+			return errInvalidSecrets
+		}
+
+		v, rest, err := readJSONString(bytes.TrimSpace(rest[1:]))
+		if err != nil {
+			return err
+		}
+
+		s[string(k)] = v
+
+		rest = bytes.TrimSpace(rest)
+		if len(rest) > 0 {
+			if rest[0] != ',' {
+				return errInvalidSecrets
+			}
+			rest = bytes.TrimSpace(rest[1:])
+			if len(rest) == 0 {
+				return errInvalidSecrets
+			}
+		}
+		d = rest
+	}
+
+	return nil
 }
 
+// erase wipes the values and removes them
 func (s secrets) erase() {
-	for k := range s {
-		s[k] = ""
+	for k, v := range s {
+		wipe(v)
 		delete(s, k)
 	}
 }
+
+// wipe overwrites b with zeros
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// appendJSONString appends v to b as a quoted JSON string
+func appendJSONString(b, v []byte) []byte {
+	b = append(b, '"')
+	for _, c := range v {
+		switch {
+		case c == '"' || c == '\\':
+			b = append(b, '\\', c)
+		case c < 0x20:
+			b = append(b, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+		default:
+			b = append(b, c)
+		}
+	}
+	return append(b, '"')
+}
+
+// readJSONString reads the quoted JSON string at the start of d, and returns it unquoted and the remaining data
+func readJSONString(d []byte) ([]byte, []byte, error) {
+	if len(d) == 0 || d[0] != '"' {
+		return nil, nil, errInvalidSecrets
+	}
+
+	// The unquoted string is not longer than the quoted string
+	end := 1
+	for end < len(d) && d[end] != '"' {
+		if d[end] == '\\' {
+			end++
+		}
+		end++
+	}
+
+	v := make([]byte, 0, end)
+	for i := 1; i < len(d); i++ {
+		c := d[i]
+		switch {
+		case c == '"':
+			return v, d[i+1:], nil
+		case c < 0x20:
+			return nil, nil, errInvalidSecrets
+		case c != '\\':
+			v = append(v, c)
+			continue
+		}
+
+		i++
+		if i >= len(d) {
+			break
+		}
+
+		switch d[i] {
+		case '"', '\\', '/':
+			v = append(v, d[i])
+		case 'b':
+			v = append(v, '\b')
+		case 'f':
+			v = append(v, '\f')
+		case 'n':
+			v = append(v, '\n')
+		case 'r':
+			v = append(v, '\r')
+		case 't':
+			v = append(v, '\t')
+		case 'u':
+			r, ok := readJSONRune(d[i+1:])
+			if !ok {
+				return nil, nil, errInvalidSecrets
+			}
+			i += 4
+
+			if utf16.IsSurrogate(r) {
+				r2, ok := rune(0), false
+				if len(d) > i+2 && d[i+1] == '\\' && d[i+2] == 'u' {
+					r2, ok = readJSONRune(d[i+3:])
+				}
+
+				if dr := utf16.DecodeRune(r, r2); ok && dr != utf8.RuneError {
+					r = dr
+					i += 6
+				} else {
+					r = utf8.RuneError
+				}
+			}
+
+			var rb [utf8.UTFMax]byte
+			n := utf8.EncodeRune(rb[:], r)
+			v = append(v, rb[:n]...)
+		default:
+			return nil, nil, errInvalidSecrets
+		}
+	}
+
+	return nil, nil, errInvalidSecrets
+}
+
+// readJSONRune reads the 4 hex digits of a \u escape
+func readJSONRune(d []byte) (rune, bool) {
+	if len(d) < 4 {
+		return 0, false
+	}
+
+	var b [2]byte
+	if _, err := hex.Decode(b[:], d[:4]); err != nil {
+		return 0, false
+	}
+
+	return rune(b[0])<<8 | rune(b[1]), true
+}
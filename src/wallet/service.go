@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/SkycoinProject/cx-chains/src/cipher"
 )
@@ -13,6 +14,11 @@ type BalanceGetter interface {
 	GetBalanceOfAddrs(addrs []cipher.Address) ([]BalancePair, error)
 }
 
+// TransactionsFinder interface for finding whether addresses have been used in any transaction
+type TransactionsFinder interface {
+	AddressesActivity(addrs []cipher.Address) ([]bool, error)
+}
+
 // Service wallet service struct
 type Service struct {
 	sync.RWMutex
@@ -20,6 +26,18 @@ type Service struct {
 	config  Config
 	// firstAddrIDMap Key: first address in wallet; Value: wallet id
 	firstAddrIDMap map[string]string
+	// unlocked Key: wallet id; Value: decrypted copy of an encrypted wallet, kept until the unlock timeout expires
+	unlocked map[string]*unlockedWallet
+}
+
+// MaxUnlockTimeout is the maximum time that an encrypted wallet can be kept unlocked
+const MaxUnlockTimeout = time.Hour * 24
+
+// unlockedWallet is a decrypted copy of an encrypted wallet, which is erased when the timer fires
+type unlockedWallet struct {
+	wallet *Wallet
+	timer  *time.Timer
+	until  time.Time
 }
 
 // Config wallet service config
@@ -45,6 +63,7 @@ func NewService(c Config) (*Service, error) {
 	serv := &Service{
 		config:         c,
 		firstAddrIDMap: make(map[string]string),
+		unlocked:       make(map[string]*unlockedWallet),
 	}
 
 	if !serv.config.EnableWalletAPI {
@@ -93,7 +112,8 @@ func (serv *Service) WalletDir() (string, error) {
 
 // CreateWallet creates a wallet with the given wallet file name and options.
 // A address will be automatically generated by default.
-func (serv *Service) CreateWallet(wltName string, options Options, bg BalanceGetter) (*Wallet, error) {
+// Deterministic wallets are scanned for balances with bg, bip44 wallets are scanned for used addresses with tf.
+func (serv *Service) CreateWallet(wltName string, options Options, bg BalanceGetter, tf TransactionsFinder) (*Wallet, error) {
 	serv.Lock()
 	defer serv.Unlock()
 	if !serv.config.EnableWalletAPI {
@@ -103,17 +123,17 @@ func (serv *Service) CreateWallet(wltName string, options Options, bg BalanceGet
 		wltName = serv.generateUniqueWalletFilename()
 	}
 
-	return serv.loadWallet(wltName, options, bg)
+	return serv.loadWallet(wltName, options, bg, tf)
 }
 
 // loadWallet loads wallet from seed and scan the first N addresses
-func (serv *Service) loadWallet(wltName string, options Options, bg BalanceGetter) (*Wallet, error) {
+func (serv *Service) loadWallet(wltName string, options Options, bg BalanceGetter, tf TransactionsFinder) (*Wallet, error) {
 	// service decides what crypto type the wallet should use.
 	if options.Encrypt {
 		options.CryptoType = serv.config.CryptoType
 	}
 
-	w, err := NewWalletScanAhead(wltName, options, bg)
+	w, err := NewWalletScanAhead(wltName, options, bg, tf)
 	if err != nil {
 		return nil, err
 	}
@@ -211,6 +231,7 @@ func (serv *Service) DecryptWallet(wltID string, password []byte) (*Wallet, erro
 	}
 
 	// Sets the decrypted wallet in memory
+	serv.lockWallet(wltID)
 	serv.wallets.set(unlockWlt)
 	return unlockWlt, nil
 }
@@ -350,6 +371,7 @@ func (serv *Service) UnloadWallet(wltID string) error {
 		delete(serv.firstAddrIDMap, addr)
 	}
 
+	serv.lockWallet(wltID)
 	serv.wallets.remove(wltID)
 	return nil
 }
@@ -381,6 +403,10 @@ func (serv *Service) GetWalletSeed(wltID string, password []byte) (string, error
 		return "", err
 	}
 
+	if w.IsWatchOnly() {
+		return "", ErrWalletWatchOnly
+	}
+
 	if !w.IsEncrypted() {
 		return "", ErrWalletNotEncrypted
 	}
@@ -426,6 +452,8 @@ func (serv *Service) UpdateSecrets(wltID string, password []byte, f func(*Wallet
 		return err
 	}
 
+	// The unlocked copy no longer matches the wallet
+	serv.lockWallet(wltID)
 	serv.wallets.set(w)
 
 	return nil
@@ -453,12 +481,15 @@ func (serv *Service) Update(wltID string, f func(*Wallet) error) error {
 		return err
 	}
 
+	// The unlocked copy no longer matches the wallet
+	serv.lockWallet(wltID)
 	serv.wallets.set(w)
 
 	return nil
 }
 
-// ViewSecrets opens a wallet for reading secret data
+// ViewSecrets opens a wallet for reading secret data.
+// If the wallet is encrypted and no password is provided, the wallet must have been unlocked with UnlockWallet.
 func (serv *Service) ViewSecrets(wltID string, password []byte, f func(*Wallet) error) error {
 	serv.RLock()
 	defer serv.RUnlock()
@@ -471,7 +502,16 @@ func (serv *Service) ViewSecrets(wltID string, password []byte, f func(*Wallet)
 		return err
 	}
 
+	if w.IsWatchOnly() {
+		return ErrWalletWatchOnly
+	}
+
 	if w.IsEncrypted() {
+		if u, ok := serv.unlocked[wltID]; ok && len(password) == 0 {
+			wlt := u.wallet.clone()
+			defer wlt.Erase()
+			return f(wlt)
+		}
 		return w.GuardView(password, f)
 	} else if len(password) != 0 {
 		return ErrWalletNotEncrypted
@@ -553,7 +593,102 @@ func (serv *Service) RecoverWallet(wltName, seed string, password []byte) (*Wall
 		return nil, err
 	}
 
+	serv.lockWallet(wltName)
 	serv.wallets.set(w2)
 
 	return w2.clone(), nil
 }
+
+// UnlockWallet decrypts an encrypted wallet and keeps the decrypted copy in memory until the timeout expires,
+// so that the wallet can be used to sign transactions without a password.
+// If the wallet is already unlocked, the decrypted copy is replaced and the timeout is restarted.
+// Returns the time when the wallet will be locked again.
+func (serv *Service) UnlockWallet(wltID string, password []byte, timeout time.Duration) (time.Time, error) {
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return time.Time{}, ErrWalletAPIDisabled
+	}
+
+	if timeout <= 0 || timeout > MaxUnlockTimeout {
+		return time.Time{}, ErrInvalidUnlockTimeout
+	}
+
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if w.IsWatchOnly() {
+		return time.Time{}, ErrWalletWatchOnly
+	}
+
+	if !w.IsEncrypted() {
+		return time.Time{}, ErrWalletNotEncrypted
+	}
+
+	unlockWlt, err := w.Unlock(password)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	serv.lockWallet(wltID)
+
+	u := &unlockedWallet{
+		wallet: unlockWlt,
+		until:  time.Now().Add(timeout),
+	}
+	u.timer = time.AfterFunc(timeout, func() {
+		serv.Lock()
+		defer serv.Unlock()
+		// The wallet may have been locked and unlocked again since the timer was started
+		if serv.unlocked[wltID] == u {
+			serv.lockWallet(wltID)
+		}
+	})
+	serv.unlocked[wltID] = u
+
+	return u.until, nil
+}
+
+// LockWallet erases the decrypted copy of a wallet unlocked with UnlockWallet.
+// Locking a wallet that is not unlocked is not an error.
+func (serv *Service) LockWallet(wltID string) error {
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return ErrWalletAPIDisabled
+	}
+
+	if _, err := serv.getWallet(wltID); err != nil {
+		return err
+	}
+
+	serv.lockWallet(wltID)
+	return nil
+}
+
+// UnlockedUntil returns the time when an unlocked wallet will be locked again,
+// and false if the wallet is not unlocked
+func (serv *Service) UnlockedUntil(wltID string) (time.Time, bool) {
+	serv.RLock()
+	defer serv.RUnlock()
+
+	u, ok := serv.unlocked[wltID]
+	if !ok {
+		return time.Time{}, false
+	}
+	return u.until, true
+}
+
+// lockWallet erases and removes the decrypted copy of the wallet, if it is unlocked
+func (serv *Service) lockWallet(wltID string) {
+	u, ok := serv.unlocked[wltID]
+	if !ok {
+		return
+	}
+
+	u.timer.Stop()
+	u.wallet.Erase()
+	delete(serv.unlocked, wltID)
+}
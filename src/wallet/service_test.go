@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -134,7 +135,7 @@ func TestServiceCreateWallet(t *testing.T) {
 					Seed:     seed,
 					Encrypt:  tc.encrypt,
 					Password: tc.password,
-				}, nil)
+				}, nil, nil)
 				require.Equal(t, tc.err, err)
 				if err != nil {
 					return
@@ -155,14 +156,14 @@ func TestServiceCreateWallet(t *testing.T) {
 				}
 
 				// create wallet with dup wallet name
-				_, err = s.CreateWallet(wltName, Options{Seed: "seed2"}, nil)
+				_, err = s.CreateWallet(wltName, Options{Seed: "seed2"}, nil, nil)
 				require.Equal(t, err, ErrWalletNameConflict)
 
 				// create wallet with dup seed
 				dupWlt := "dup_wallet.wlt"
 				_, err = s.CreateWallet(dupWlt, Options{
 					Seed: seed,
-				}, nil)
+				}, nil, nil)
 				require.Equal(t, err, ErrSeedUsed)
 
 				// check if the dup wallet is created
@@ -267,7 +268,7 @@ func TestServiceLoadWallet(t *testing.T) {
 				require.NoError(t, err)
 				wltName := NewWalletFilename()
 
-				w, err := s.loadWallet(wltName, tc.opts, tc.bg)
+				w, err := s.loadWallet(wltName, tc.opts, tc.bg, nil)
 				require.Equal(t, tc.err, err)
 				if err != nil {
 					return
@@ -415,7 +416,7 @@ func TestServiceNewAddress(t *testing.T) {
 
 				wltName := NewWalletFilename()
 
-				w, err := s.CreateWallet(wltName, tc.opts, nil)
+				w, err := s.CreateWallet(wltName, tc.opts, nil, nil)
 				if err != nil {
 					require.Equal(t, tc.expectErr, err)
 					return
@@ -529,7 +530,7 @@ func TestServiceGetWallet(t *testing.T) {
 				w, err := s.CreateWallet("t.wlt", Options{
 					Label: "label",
 					Seed:  "seed",
-				}, nil)
+				}, nil, nil)
 				require.NoError(t, err)
 
 				w1, err := s.GetWallet(w.Filename())
@@ -576,7 +577,7 @@ func TestServiceGetWallets(t *testing.T) {
 				w, err := s.CreateWallet("t.wlt", Options{
 					Label: "label",
 					Seed:  "seed",
-				}, nil)
+				}, nil, nil)
 				require.NoError(t, err)
 
 				var wallets []*Wallet
@@ -588,7 +589,7 @@ func TestServiceGetWallets(t *testing.T) {
 				w1, err := s.CreateWallet(wltName, Options{
 					Label: "label1",
 					Seed:  "seed1",
-				}, nil)
+				}, nil, nil)
 				require.NoError(t, err)
 				wallets = append(wallets, w1)
 
@@ -666,7 +667,7 @@ func TestServiceUpdateWalletLabel(t *testing.T) {
 				}
 
 				// Create a new wallet
-				w, err := s.CreateWallet(tc.wltName, tc.opts, nil)
+				w, err := s.CreateWallet(tc.wltName, tc.opts, nil, nil)
 				require.NoError(t, err)
 
 				err = s.UpdateWalletLabel(tc.updateWltName, tc.label)
@@ -761,7 +762,7 @@ func TestServiceEncryptWallet(t *testing.T) {
 				}
 
 				// Create a new wallet
-				w, err := s.CreateWallet(tc.wltName, tc.opts, nil)
+				w, err := s.CreateWallet(tc.wltName, tc.opts, nil, nil)
 				require.NoError(t, err)
 
 				// Encrypt the wallet
@@ -887,7 +888,7 @@ func TestServiceDecryptWallet(t *testing.T) {
 					return
 				}
 
-				_, err = s.CreateWallet(tc.wltName, tc.opts, nil)
+				_, err = s.CreateWallet(tc.wltName, tc.opts, nil, nil)
 				require.NoError(t, err)
 
 				_, err = s.DecryptWallet(tc.decryptWltName, tc.password)
@@ -1336,7 +1337,7 @@ func TestServiceCreateWalletWithScan(t *testing.T) {
 				require.NoError(t, err)
 
 				wltName := NewWalletFilename()
-				w, err := s.CreateWallet(wltName, tc.opts, tc.balGetter)
+				w, err := s.CreateWallet(wltName, tc.opts, tc.balGetter, nil)
 				require.Equal(t, tc.expect.err, err)
 				if err != nil {
 					return
@@ -1448,7 +1449,7 @@ func TestGetWalletSeed(t *testing.T) {
 				}
 
 				// Create a wallet
-				_, err = s.CreateWallet(tc.wltName, tc.opts, nil)
+				_, err = s.CreateWallet(tc.wltName, tc.opts, nil, nil)
 				require.NoError(t, err)
 
 				seed, err := s.GetWalletSeed(tc.id, tc.pwd)
@@ -1553,7 +1554,7 @@ func TestServiceView(t *testing.T) {
 			})
 			require.NoError(t, err)
 
-			w, err := s.CreateWallet(tc.wltName, tc.opts, nil)
+			w, err := s.CreateWallet(tc.wltName, tc.opts, nil, nil)
 			require.NoError(t, err)
 
 			s.config.EnableWalletAPI = !tc.disableWalletAPI
@@ -1714,7 +1715,7 @@ func TestServiceViewSecrets(t *testing.T) {
 			})
 			require.NoError(t, err)
 
-			w, err := s.CreateWallet(tc.wltName, tc.opts, nil)
+			w, err := s.CreateWallet(tc.wltName, tc.opts, nil, nil)
 			require.NoError(t, err)
 
 			s.config.EnableWalletAPI = !tc.disableWalletAPI
@@ -1848,7 +1849,7 @@ func TestServiceUpdate(t *testing.T) {
 			})
 			require.NoError(t, err)
 
-			_, err = s.CreateWallet(tc.wltName, tc.opts, nil)
+			_, err = s.CreateWallet(tc.wltName, tc.opts, nil, nil)
 			require.NoError(t, err)
 
 			s.config.EnableWalletAPI = !tc.disableWalletAPI
@@ -2030,7 +2031,7 @@ func TestServiceUpdateSecrets(t *testing.T) {
 			})
 			require.NoError(t, err)
 
-			_, err = s.CreateWallet(tc.wltName, tc.opts, nil)
+			_, err = s.CreateWallet(tc.wltName, tc.opts, nil, nil)
 			require.NoError(t, err)
 
 			s.config.EnableWalletAPI = !tc.disableWalletAPI
@@ -2070,3 +2071,237 @@ func checkNoSensitiveData(t *testing.T, w *Wallet) {
 		require.Equal(t, empty, e.Secret)
 	}
 }
+
+func TestServiceUnlockWallet(t *testing.T) {
+	tt := []struct {
+		name             string
+		wltName          string
+		opts             Options
+		unlockWltName    string
+		password         []byte
+		timeout          time.Duration
+		disableWalletAPI bool
+		err              error
+	}{
+		{
+			name:          "ok",
+			wltName:       "t.wlt",
+			unlockWltName: "t.wlt",
+			opts: Options{
+				Seed:     "fooseed",
+				Encrypt:  true,
+				Password: []byte("pwd"),
+			},
+			password: []byte("pwd"),
+			timeout:  time.Minute,
+		},
+		{
+			name:          "wallet not encrypted",
+			wltName:       "t.wlt",
+			unlockWltName: "t.wlt",
+			opts: Options{
+				Seed: "fooseed",
+			},
+			password: []byte("pwd"),
+			timeout:  time.Minute,
+			err:      ErrWalletNotEncrypted,
+		},
+		{
+			name:          "invalid password",
+			wltName:       "t.wlt",
+			unlockWltName: "t.wlt",
+			opts: Options{
+				Seed:     "fooseed",
+				Encrypt:  true,
+				Password: []byte("pwd"),
+			},
+			password: []byte("pwdpwd"),
+			timeout:  time.Minute,
+			err:      ErrInvalidPassword,
+		},
+		{
+			name:          "missing password",
+			wltName:       "t.wlt",
+			unlockWltName: "t.wlt",
+			opts: Options{
+				Seed:     "fooseed",
+				Encrypt:  true,
+				Password: []byte("pwd"),
+			},
+			timeout: time.Minute,
+			err:     ErrMissingPassword,
+		},
+		{
+			name:          "zero timeout",
+			wltName:       "t.wlt",
+			unlockWltName: "t.wlt",
+			opts: Options{
+				Seed:     "fooseed",
+				Encrypt:  true,
+				Password: []byte("pwd"),
+			},
+			password: []byte("pwd"),
+			err:      ErrInvalidUnlockTimeout,
+		},
+		{
+			name:          "timeout too large",
+			wltName:       "t.wlt",
+			unlockWltName: "t.wlt",
+			opts: Options{
+				Seed:     "fooseed",
+				Encrypt:  true,
+				Password: []byte("pwd"),
+			},
+			password: []byte("pwd"),
+			timeout:  MaxUnlockTimeout + time.Second,
+			err:      ErrInvalidUnlockTimeout,
+		},
+		{
+			name:          "wallet doesn't exist",
+			wltName:       "t.wlt",
+			unlockWltName: "t2.wlt",
+			opts: Options{
+				Seed:     "fooseed",
+				Encrypt:  true,
+				Password: []byte("pwd"),
+			},
+			password: []byte("pwd"),
+			timeout:  time.Minute,
+			err:      ErrWalletNotExist,
+		},
+		{
+			name:          "api disabled",
+			wltName:       "t.wlt",
+			unlockWltName: "t.wlt",
+			opts: Options{
+				Seed:     "fooseed",
+				Encrypt:  true,
+				Password: []byte("pwd"),
+			},
+			password:         []byte("pwd"),
+			timeout:          time.Minute,
+			disableWalletAPI: true,
+			err:              ErrWalletAPIDisabled,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := prepareWltDir()
+			s, err := NewService(Config{
+				WalletDir:       dir,
+				CryptoType:      CryptoTypeSha256Xor,
+				EnableWalletAPI: true,
+			})
+			require.NoError(t, err)
+
+			w, err := s.CreateWallet(tc.wltName, tc.opts, nil, nil)
+			require.NoError(t, err)
+
+			s.config.EnableWalletAPI = !tc.disableWalletAPI
+
+			now := time.Now()
+			until, err := s.UnlockWallet(tc.unlockWltName, tc.password, tc.timeout)
+			require.Equal(t, tc.err, err)
+			if err != nil {
+				_, ok := s.UnlockedUntil(tc.unlockWltName)
+				require.False(t, ok)
+				return
+			}
+
+			require.False(t, until.Before(now.Add(tc.timeout)))
+			unlockedUntil, ok := s.UnlockedUntil(tc.wltName)
+			require.True(t, ok)
+			require.Equal(t, until, unlockedUntil)
+
+			// Secrets can be viewed without the password while the wallet is unlocked
+			err = s.ViewSecrets(tc.wltName, nil, func(w *Wallet) error {
+				require.Equal(t, "fooseed", w.seed())
+				require.False(t, w.Entries[0].Secret == cipher.SecKey{})
+				return nil
+			})
+			require.NoError(t, err)
+
+			// The wallet is still encrypted
+			w2, err := s.GetWallet(tc.wltName)
+			require.NoError(t, err)
+			require.Equal(t, w, w2)
+			require.True(t, w2.IsEncrypted())
+			require.Empty(t, w2.seed())
+
+			// Locking the wallet erases the decrypted copy
+			u := s.unlocked[tc.wltName]
+			err = s.LockWallet(tc.wltName)
+			require.NoError(t, err)
+			_, ok = s.UnlockedUntil(tc.wltName)
+			require.False(t, ok)
+			require.Empty(t, u.wallet.seed())
+			require.Empty(t, u.wallet.lastSeed())
+			for _, e := range u.wallet.Entries {
+				require.Equal(t, cipher.SecKey{}, e.Secret)
+			}
+
+			err = s.ViewSecrets(tc.wltName, nil, func(w *Wallet) error {
+				return nil
+			})
+			require.Equal(t, ErrMissingPassword, err)
+
+			// Locking a locked wallet is not an error
+			err = s.LockWallet(tc.wltName)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestServiceUnlockWalletTimeout(t *testing.T) {
+	dir := prepareWltDir()
+	s, err := NewService(Config{
+		WalletDir:       dir,
+		CryptoType:      CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+
+	_, err = s.CreateWallet("t.wlt", Options{
+		Seed:     "fooseed",
+		Encrypt:  true,
+		Password: []byte("pwd"),
+	}, nil, nil)
+	require.NoError(t, err)
+
+	_, err = s.UnlockWallet("t.wlt", []byte("pwd"), time.Hour)
+	require.NoError(t, err)
+	u := s.unlocked["t.wlt"]
+
+	// Unlocking again replaces and erases the previous decrypted copy
+	_, err = s.UnlockWallet("t.wlt", []byte("pwd"), 100*time.Millisecond)
+	require.NoError(t, err)
+	require.Empty(t, u.wallet.seed())
+	require.NotEqual(t, u, s.unlocked["t.wlt"])
+	u = s.unlocked["t.wlt"]
+
+	// The wallet is locked when the timeout expires
+	time.Sleep(300 * time.Millisecond)
+	_, ok := s.UnlockedUntil("t.wlt")
+	require.False(t, ok)
+	require.Empty(t, u.wallet.seed())
+
+	// Updating the secrets of an unlocked wallet locks it
+	_, err = s.UnlockWallet("t.wlt", []byte("pwd"), time.Hour)
+	require.NoError(t, err)
+	err = s.UpdateSecrets("t.wlt", []byte("pwd"), func(w *Wallet) error {
+		_, err := w.GenerateAddresses(1)
+		return err
+	})
+	require.NoError(t, err)
+	_, ok = s.UnlockedUntil("t.wlt")
+	require.False(t, ok)
+
+	// Unloading an unlocked wallet locks it
+	_, err = s.UnlockWallet("t.wlt", []byte("pwd"), time.Hour)
+	require.NoError(t, err)
+	err = s.UnloadWallet("t.wlt")
+	require.NoError(t, err)
+	_, ok = s.UnlockedUntil("t.wlt")
+	require.False(t, ok)
+}
@@ -14,6 +14,8 @@ var (
 	ErrUnknownAddress = NewError(errors.New("address not found in wallet"))
 	// ErrUnknownUxOut is returned if a uxout is not owned by any address in a wallet
 	ErrUnknownUxOut = NewError(errors.New("uxout is not owned by any address in the wallet"))
+	// ErrMultisigTransaction is returned if a multisig transaction is signed as a standard transaction
+	ErrMultisigTransaction = NewError(errors.New("multisig transactions must be signed with SignMultisigTransaction"))
 )
 
 func validateSignIndexes(x []int, uxOuts []coin.UxOut) error {
@@ -66,6 +68,14 @@ func copyTransaction(txn *coin.Transaction) *coin.Transaction {
 // but a valid existing signature cannot be overwritten.
 // Clients should avoid signing the same transaction multiple times.
 func (w *Wallet) SignTransaction(txn *coin.Transaction, signIndexes []int, uxOuts []coin.UxOut) (*coin.Transaction, error) {
+	if w.IsWatchOnly() {
+		return nil, ErrWalletWatchOnly
+	}
+
+	if txn.IsMultisig() {
+		return nil, ErrMultisigTransaction
+	}
+
 	signedTxn := copyTransaction(txn)
 	txnInnerHash := signedTxn.HashInner()
 
@@ -203,6 +213,10 @@ func (w *Wallet) CreateTransaction(p transaction.Params, auxs coin.AddressUxOuts
 // Set the password as nil if the wallet is not encrypted, otherwise the password must be provided.
 // Refer to CreateTransaction for information about transaction creation.
 func (w *Wallet) CreateTransactionSigned(p transaction.Params, auxs coin.AddressUxOuts, headTime uint64) (*coin.Transaction, []transaction.UxBalance, error) {
+	if w.IsWatchOnly() {
+		return nil, nil, ErrWalletWatchOnly
+	}
+
 	txn, uxb, err := w.CreateTransaction(p, auxs, headTime)
 	if err != nil {
 		return nil, nil, err
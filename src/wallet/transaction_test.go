@@ -15,6 +15,7 @@ import (
 
 	"github.com/SkycoinProject/cx-chains/src/cipher"
 	"github.com/SkycoinProject/cx-chains/src/coin"
+	"github.com/SkycoinProject/cx-chains/src/params"
 	"github.com/SkycoinProject/cx-chains/src/testutil"
 	"github.com/SkycoinProject/cx-chains/src/transaction"
 	"github.com/SkycoinProject/cx-chains/src/util/fee"
@@ -312,6 +313,13 @@ func TestWalletSignTransaction(t *testing.T) {
 }
 
 func TestWalletCreateTransaction(t *testing.T) {
+	// The expected hours of the cases are calculated with a burn factor of 10
+	originalBurnFactor := params.UserVerifyTxn.BurnFactor
+	params.UserVerifyTxn.BurnFactor = 10
+	defer func() {
+		params.UserVerifyTxn.BurnFactor = originalBurnFactor
+	}()
+
 	headTime := uint64(time.Now().UTC().Unix())
 	seed := []byte("seed")
 
@@ -755,9 +763,9 @@ func makeTransaction(t *testing.T, nInputs int) (coin.Transaction, []coin.UxOut,
 		uxs = append(uxs, ux)
 	}
 
-	err := txn.PushOutput(makeAddress(), 1e6, 50)
+	err := txn.PushOutput(makeAddress(), 1e6, 50, nil)
 	require.NoError(t, err)
-	err = txn.PushOutput(makeAddress(), 5e6, 50)
+	err = txn.PushOutput(makeAddress(), 5e6, 50, nil)
 	require.NoError(t, err)
 	txn.SignInputs(toSign)
 	err = txn.UpdateHeader()
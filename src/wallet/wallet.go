@@ -16,6 +16,9 @@ import (
 	"encoding/hex"
 	
 	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/cipher/bip32"
+	"github.com/SkycoinProject/cx-chains/src/cipher/bip39"
+	"github.com/SkycoinProject/cx-chains/src/cipher/bip44"
 	"github.com/SkycoinProject/cx-chains/src/util/logging"
 )
 
@@ -75,6 +78,28 @@ var (
 	ErrWalletNotDeterministic = NewError(errors.New("wallet type is not deterministic"))
 	// ErrInvalidCoinType is returned for invalid coin types
 	ErrInvalidCoinType = NewError(errors.New("invalid coin type"))
+	// ErrInvalidWalletType is returned for invalid wallet types
+	ErrInvalidWalletType = NewError(errors.New("invalid wallet type"))
+	// ErrWalletNotBip44 is returned if a wallet's type is not bip44 but it is necessary for the requested operation
+	ErrWalletNotBip44 = NewError(errors.New("wallet type is not bip44"))
+	// ErrSeedPassphraseNotBip44 is returned if a seed passphrase is provided for a wallet type that is not bip44
+	ErrSeedPassphraseNotBip44 = NewError(errors.New("seed passphrase is only supported by bip44 wallets"))
+	// ErrBip44OptionsNotBip44 is returned if bip44 coin type or account options are provided for a wallet type that is not bip44
+	ErrBip44OptionsNotBip44 = NewError(errors.New("bip44 coin type and account are only supported by bip44 wallets"))
+	// ErrInvalidBip44Seed is returned if a bip44 wallet's seed is not a valid bip39 mnemonic
+	ErrInvalidBip44Seed = NewError(errors.New("bip44 wallet seed must be a valid bip39 mnemonic"))
+	// ErrNilTransactionsFinder is returned if Options.ScanN > 0 for a bip44 wallet but a nil TransactionsFinder was provided
+	ErrNilTransactionsFinder = NewError(errors.New("scan ahead requested but transactions finder is nil"))
+	// ErrWalletWatchOnly is returned when trying to sign with, encrypt or get secrets of a watch-only wallet
+	ErrWalletWatchOnly = NewError(errors.New("wallet is watch-only"))
+	// ErrWatchOnlySecrets is returned when trying to create a watch-only wallet with a seed or encryption
+	ErrWatchOnlySecrets = NewError(errors.New("watch-only wallets cannot have a seed or be encrypted"))
+	// ErrMissingWatchOnlyAddresses is returned when trying to create a watch-only wallet without an xpub or addresses
+	ErrMissingWatchOnlyAddresses = NewError(errors.New("watch-only wallet requires either an xpub or addresses"))
+	// ErrWatchOnlyNoXPub is returned when trying to generate addresses for a watch-only wallet created from addresses
+	ErrWatchOnlyNoXPub = NewError(errors.New("watch-only wallet has no xpub to generate addresses from"))
+	// ErrInvalidUnlockTimeout is returned when trying to unlock a wallet with a timeout that is not positive or exceeds MaxUnlockTimeout
+	ErrInvalidUnlockTimeout = NewError(fmt.Errorf("unlock timeout must be greater than 0 and at most %v", MaxUnlockTimeout))
 )
 
 const (
@@ -91,6 +116,14 @@ const (
 
 	// WalletTypeDeterministic deterministic wallet type
 	WalletTypeDeterministic = "deterministic"
+	// WalletTypeBip44 bip44 hierarchical deterministic wallet type
+	WalletTypeBip44 = "bip44"
+	// WalletTypeWatchOnly watch-only wallet type, which has public addresses but no secrets
+	WalletTypeWatchOnly = "watch-only"
+
+	// DefaultBip44GapLimit is the number of consecutive unused addresses scanned
+	// before a bip44 wallet stops looking for used addresses, as recommended by bip44
+	DefaultBip44GapLimit = 20
 )
 
 // ResolveCoinType normalizes a coin type string to a CoinType constant
@@ -118,6 +151,11 @@ const (
 	metaSeed       = "seed"       // wallet seed
 	metaLastSeed   = "lastSeed"   // seed for generating next address
 	metaSecrets    = "secrets"    // secrets which records the encrypted seeds and secrets of address entries
+
+	metaSeedPassphrase = "seedPassphrase" // bip39 seed passphrase of a bip44 wallet
+	metaBip44Coin      = "bip44Coin"      // bip44 coin type of a bip44 wallet
+	metaBip44Account   = "bip44Account"   // bip44 account of a bip44 wallet
+	metaXPub           = "xpub"           // bip32 extended public key of a bip44 or watch-only wallet's account
 )
 
 // CoinType represents the wallet coin type
@@ -139,8 +177,18 @@ type Options struct {
 	Encrypt    bool       // whether the wallet need to be encrypted.
 	Password   []byte     // password that would be used for encryption, and would only be used when 'Encrypt' is true.
 	CryptoType CryptoType // wallet encryption type, scrypt-chacha20poly1305 or sha256-xor.
-	ScanN      uint64     // number of addresses that're going to be scanned for a balance. The highest address with a balance will be used.
+	ScanN      uint64     // number of addresses that're going to be scanned for a balance. The highest address with a balance will be used. For bip44 wallets, this is the gap limit of unused addresses.
 	GenerateN  uint64     // number of addresses to generate, regardless of balance
+
+	Type           string          // wallet type, deterministic or bip44. Defaults to deterministic.
+	SeedPassphrase string          // bip39 seed passphrase, only for bip44 wallets.
+	Bip44Coin      *bip44.CoinType // bip44 coin type, only for bip44 wallets. Defaults to the bip44 coin type of Coin.
+	Bip44Account   uint32          // bip44 account, only for bip44 wallets.
+
+	XPub      string             // bip32 extended public key of a bip44 account, only for watch-only wallets.
+	Addresses []cipher.Addresser // addresses to watch, only for watch-only wallets without an XPub.
+	// ChildNumbers are the child numbers of the Addresses, optional. For a hardware wallet, these are the address indexes on the device.
+	ChildNumbers []uint32
 }
 
 // Wallet is consisted of meta and entries.
@@ -155,13 +203,59 @@ type Wallet struct {
 }
 
 // newWallet creates a wallet instance with given name and options.
-func newWallet(wltName string, opts Options, bg BalanceGetter) (*Wallet, error) {
-	if opts.Seed == "" {
+func newWallet(wltName string, opts Options, bg BalanceGetter, tf TransactionsFinder) (*Wallet, error) {
+	walletType := opts.Type
+	if walletType == "" {
+		walletType = WalletTypeDeterministic
+	}
+
+	if opts.Seed == "" && walletType != WalletTypeWatchOnly {
 		return nil, ErrMissingSeed
 	}
 
-	if opts.ScanN > 0 && bg == nil {
-		return nil, ErrNilBalanceGetter
+	if walletType != WalletTypeWatchOnly && (opts.XPub != "" || len(opts.Addresses) != 0 || len(opts.ChildNumbers) != 0) {
+		return nil, NewError(errors.New("xpub and addresses are only supported by watch-only wallets"))
+	}
+
+	switch walletType {
+	case WalletTypeDeterministic:
+		if opts.SeedPassphrase != "" {
+			return nil, ErrSeedPassphraseNotBip44
+		}
+		if opts.Bip44Coin != nil || opts.Bip44Account != 0 {
+			return nil, ErrBip44OptionsNotBip44
+		}
+		if opts.ScanN > 0 && bg == nil {
+			return nil, ErrNilBalanceGetter
+		}
+	case WalletTypeBip44:
+		if err := bip39.ValidateMnemonic(opts.Seed); err != nil {
+			return nil, ErrInvalidBip44Seed
+		}
+		if opts.Bip44Account >= bip32.FirstHardenedChild {
+			return nil, NewError(bip44.ErrInvalidAccount)
+		}
+		if opts.ScanN > 0 && tf == nil {
+			return nil, ErrNilTransactionsFinder
+		}
+	case WalletTypeWatchOnly:
+		if opts.Seed != "" || opts.SeedPassphrase != "" || opts.Encrypt || len(opts.Password) != 0 {
+			return nil, ErrWatchOnlySecrets
+		}
+		if opts.Bip44Coin != nil || opts.Bip44Account != 0 {
+			return nil, ErrBip44OptionsNotBip44
+		}
+		if (opts.XPub == "") == (len(opts.Addresses) == 0) {
+			return nil, ErrMissingWatchOnlyAddresses
+		}
+		if len(opts.ChildNumbers) != 0 && len(opts.ChildNumbers) != len(opts.Addresses) {
+			return nil, NewError(errors.New("the number of child numbers must match the number of addresses"))
+		}
+		if opts.ScanN > 0 && tf == nil {
+			return nil, ErrNilTransactionsFinder
+		}
+	default:
+		return nil, ErrInvalidWalletType
 	}
 
 	coin := opts.Coin
@@ -183,7 +277,7 @@ func newWallet(wltName string, opts Options, bg BalanceGetter) (*Wallet, error)
 			metaSeed:       opts.Seed,
 			metaLastSeed:   opts.Seed,
 			metaTimestamp:  strconv.FormatInt(time.Now().Unix(), 10),
-			metaType:       WalletTypeDeterministic,
+			metaType:       walletType,
 			metaCoin:       string(coin),
 			metaEncrypted:  "false",
 			metaCryptoType: "",
@@ -191,6 +285,31 @@ func newWallet(wltName string, opts Options, bg BalanceGetter) (*Wallet, error)
 		},
 	}
 
+	if walletType == WalletTypeBip44 {
+		bip44Coin := defaultBip44CoinType(coin)
+		if opts.Bip44Coin != nil {
+			bip44Coin = *opts.Bip44Coin
+		}
+		if uint32(bip44Coin) >= bip32.FirstHardenedChild {
+			return nil, NewError(bip44.ErrInvalidCoinType)
+		}
+
+		w.setLastSeed("")
+		w.setSeedPassphrase(opts.SeedPassphrase)
+		w.Meta[metaBip44Coin] = strconv.FormatUint(uint64(bip44Coin), 10)
+		w.Meta[metaBip44Account] = strconv.FormatUint(uint64(opts.Bip44Account), 10)
+
+		account, err := w.bip44AccountKey()
+		if err != nil {
+			return nil, err
+		}
+		w.Meta[metaXPub] = account.PublicKey().String()
+	}
+
+	if walletType == WalletTypeWatchOnly {
+		return newWatchOnlyWallet(w, opts, tf)
+	}
+
 	// Create a default wallet
 	generateN := opts.GenerateN
 	if generateN == 0 {
@@ -204,10 +323,20 @@ func newWallet(wltName string, opts Options, bg BalanceGetter) (*Wallet, error)
 		return nil, errors.New("Wallet address scanning is not supported for Bitcoin wallets")
 	}
 
-	if opts.ScanN > generateN {
-		// Scan for addresses with balances
-		if _, err := w.ScanAddresses(opts.ScanN, bg); err != nil {
-			return nil, err
+	switch walletType {
+	case WalletTypeBip44:
+		if opts.ScanN > 0 {
+			// Scan for used addresses until ScanN consecutive addresses are unused
+			if _, err := w.ScanUsedAddresses(opts.ScanN, tf); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		if opts.ScanN > generateN {
+			// Scan for addresses with balances
+			if _, err := w.ScanAddresses(opts.ScanN, bg); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -242,14 +371,73 @@ func newWallet(wltName string, opts Options, bg BalanceGetter) (*Wallet, error)
 	return w, nil
 }
 
+// newWatchOnlyWallet adds the addresses of a watch-only wallet, from opts.XPub or opts.Addresses
+func newWatchOnlyWallet(w *Wallet, opts Options, tf TransactionsFinder) (*Wallet, error) {
+	if opts.XPub != "" {
+		if _, err := bip32.DeserializeEncodedPublicKey(opts.XPub); err != nil {
+			return nil, NewError(fmt.Errorf("invalid xpub: %v", err))
+		}
+		w.Meta[metaXPub] = opts.XPub
+
+		generateN := opts.GenerateN
+		if generateN == 0 {
+			generateN = 1
+		}
+		if _, err := w.GenerateAddresses(generateN); err != nil {
+			return nil, err
+		}
+
+		if opts.ScanN != 0 && w.coin() != CoinTypeSkycoin {
+			return nil, errors.New("Wallet address scanning is not supported for Bitcoin wallets")
+		}
+
+		if opts.ScanN > 0 {
+			if _, err := w.ScanUsedAddresses(opts.ScanN, tf); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		for i, a := range opts.Addresses {
+			switch a.(type) {
+			case cipher.Address:
+				if w.coin() != CoinTypeSkycoin {
+					return nil, NewError(fmt.Errorf("address %s is not a %s address", a, w.coin()))
+				}
+			case cipher.BitcoinAddress:
+				if w.coin() != CoinTypeBitcoin {
+					return nil, NewError(fmt.Errorf("address %s is not a %s address", a, w.coin()))
+				}
+			}
+
+			e := Entry{
+				Address: a,
+			}
+			if len(opts.ChildNumbers) != 0 {
+				e.ChildNumber = opts.ChildNumbers[i]
+			}
+
+			if err := w.AddEntry(e); err != nil {
+				return nil, NewError(err)
+			}
+		}
+	}
+
+	if err := w.Validate(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
 // NewWallet creates wallet without scanning addresses
 func NewWallet(wltName string, opts Options) (*Wallet, error) {
-	return newWallet(wltName, opts, nil)
+	return newWallet(wltName, opts, nil, nil)
 }
 
-// NewWalletScanAhead creates wallet and scan ahead N addresses
-func NewWalletScanAhead(wltName string, opts Options, bg BalanceGetter) (*Wallet, error) {
-	return newWallet(wltName, opts, bg)
+// NewWalletScanAhead creates wallet and scan ahead N addresses.
+// Deterministic wallets are scanned for balances with bg, bip44 wallets are scanned for used addresses with tf.
+func NewWalletScanAhead(wltName string, opts Options, bg BalanceGetter, tf TransactionsFinder) (*Wallet, error) {
+	return newWallet(wltName, opts, bg, tf)
 }
 
 // Lock encrypts the wallet with the given password and specific crypto type
@@ -262,6 +450,10 @@ func (w *Wallet) Lock(password []byte, cryptoType CryptoType) error {
 		return ErrWalletEncrypted
 	}
 
+	if w.Type() == WalletTypeWatchOnly {
+		return ErrWalletWatchOnly
+	}
+
 	wlt := w.clone()
 
 	// Records seeds in secrets
@@ -272,18 +464,24 @@ func (w *Wallet) Lock(password []byte, cryptoType CryptoType) error {
 		wlt.Erase()
 	}()
 
-	ss.set(secretSeed, wlt.seed())
-	ss.set(secretLastSeed, wlt.lastSeed())
+	ss.set(secretSeed, []byte(wlt.seed()))
+	ss.set(secretLastSeed, []byte(wlt.lastSeed()))
+	if wlt.Type() == WalletTypeBip44 {
+		ss.set(secretSeedPassphrase, []byte(wlt.seedPassphrase()))
+	}
 
 	// Saves address's secret keys in secrets
 	for _, e := range wlt.Entries {
-		ss.set(e.Address.String(), e.Secret.Hex())
+		sk := make([]byte, hex.EncodedLen(len(e.Secret)))
+		hex.Encode(sk, e.Secret[:])
+		ss.set(e.Address.String(), sk)
 	}
 
 	sb, err := ss.serialize()
 	if err != nil {
 		return err
 	}
+	defer wipe(sb)
 
 	crypto, err := getCrypto(cryptoType)
 	if err != nil {
@@ -355,6 +553,7 @@ func (w *Wallet) Unlock(password []byte) (*Wallet, error) {
 	if err != nil {
 		return nil, ErrInvalidPassword
 	}
+	defer wipe(sb)
 
 	// Deserialize into secrets
 	ss := make(secrets)
@@ -367,26 +566,36 @@ func (w *Wallet) Unlock(password []byte) (*Wallet, error) {
 	if !ok {
 		return nil, errors.New("seed doesn't exist in secrets")
 	}
-	wlt.setSeed(seed)
+	wlt.setSeed(string(seed))
 
 	lastSeed, ok := ss.get(secretLastSeed)
 	if !ok {
 		return nil, errors.New("lastSeed doesn't exist in secrets")
 	}
-	wlt.setLastSeed(lastSeed)
+	wlt.setLastSeed(string(lastSeed))
+
+	if wlt.Type() == WalletTypeBip44 {
+		seedPassphrase, ok := ss.get(secretSeedPassphrase)
+		if !ok {
+			return nil, errors.New("seed passphrase doesn't exist in secrets")
+		}
+		wlt.setSeedPassphrase(string(seedPassphrase))
+	}
 
 	// Gets addresses related secrets
 	for i, e := range wlt.Entries {
-		sstr, ok := ss.get(e.Address.String())
+		sk, ok := ss.get(e.Address.String())
 		if !ok {
 			return nil, fmt.Errorf("secret of address %s doesn't exist in secrets", e.Address)
 		}
-		s, err := hex.DecodeString(sstr)
-		if err != nil {
-			return nil, fmt.Errorf("decode secret hex string failed: %v", err)
+
+		if hex.DecodedLen(len(sk)) != len(wlt.Entries[i].Secret) {
+			return nil, errors.New("decode secret hex string failed: invalid secret key length")
 		}
 
-		copy(wlt.Entries[i].Secret[:], s[:])
+		if _, err := hex.Decode(wlt.Entries[i].Secret[:], sk); err != nil {
+			return nil, fmt.Errorf("decode secret hex string failed: %v", err)
+		}
 	}
 
 	wlt.setEncrypted(false)
@@ -415,6 +624,9 @@ func (w *Wallet) Erase() {
 	// Wipes the seed and last seed
 	w.setSeed("")
 	w.setLastSeed("")
+	if w.Type() == WalletTypeBip44 {
+		w.setSeedPassphrase("")
+	}
 
 	// Wipes private keys in entries
 	for i := range w.Entries {
@@ -559,7 +771,9 @@ func filterDir(dir string, suffix string) ([]string, error) {
 // reset resets the wallet entries and move the lastSeed to origin
 func (w *Wallet) reset() {
 	w.Entries = []Entry{}
-	w.setLastSeed(w.seed())
+	if w.Type() != WalletTypeBip44 {
+		w.setLastSeed(w.seed())
+	}
 }
 
 // Validate validates the wallet
@@ -579,7 +793,26 @@ func (w *Wallet) Validate() error {
 	if !ok {
 		return errors.New("type field not set")
 	}
-	if walletType != WalletTypeDeterministic {
+	switch walletType {
+	case WalletTypeDeterministic:
+	case WalletTypeBip44:
+		if _, err := strconv.ParseUint(w.Meta[metaBip44Coin], 10, 31); err != nil {
+			return errors.New("bip44Coin field is not valid")
+		}
+		if _, err := strconv.ParseUint(w.Meta[metaBip44Account], 10, 31); err != nil {
+			return errors.New("bip44Account field is not valid")
+		}
+		if xpub := w.Meta[metaXPub]; xpub == "" {
+			return errors.New("xpub field not set")
+		}
+	case WalletTypeWatchOnly:
+		if w.Meta[metaSeed] != "" || w.Meta[metaLastSeed] != "" || w.Meta[metaSecrets] != "" {
+			return errors.New("watch-only wallet has secrets")
+		}
+		if w.IsEncrypted() {
+			return errors.New("watch-only wallet is encrypted")
+		}
+	default:
 		return errors.New("wallet type invalid")
 	}
 
@@ -611,12 +844,12 @@ func (w *Wallet) Validate() error {
 		if s := w.Meta[metaSecrets]; s == "" {
 			return errors.New("wallet is encrypted, but secrets field not set")
 		}
-	} else {
+	} else if walletType != WalletTypeWatchOnly {
 		if s := w.Meta[metaSeed]; s == "" {
 			return errors.New("seed missing in unencrypted wallet")
 		}
 
-		if s := w.Meta[metaLastSeed]; s == "" {
+		if s := w.Meta[metaLastSeed]; s == "" && walletType == WalletTypeDeterministic {
 			return errors.New("lastSeed missing in unencrypted wallet")
 		}
 	}
@@ -675,6 +908,74 @@ func (w *Wallet) setSeed(seed string) {
 	w.Meta[metaSeed] = seed
 }
 
+func (w *Wallet) seedPassphrase() string {
+	return w.Meta[metaSeedPassphrase]
+}
+
+func (w *Wallet) setSeedPassphrase(p string) {
+	w.Meta[metaSeedPassphrase] = p
+}
+
+func (w *Wallet) bip44Coin() bip44.CoinType {
+	// Intentionally ignore the error, this value is validated by wallet.Validate()
+	x, _ := strconv.ParseUint(w.Meta[metaBip44Coin], 10, 32) // nolint: errcheck
+	return bip44.CoinType(x)
+}
+
+func (w *Wallet) bip44Account() uint32 {
+	// Intentionally ignore the error, this value is validated by wallet.Validate()
+	x, _ := strconv.ParseUint(w.Meta[metaBip44Account], 10, 32) // nolint: errcheck
+	return uint32(x)
+}
+
+// XPub returns the bip32 extended public key of a bip44 wallet's account, or of the account watched by a watch-only wallet.
+// Addresses of the wallet's external chain are derived from it at path 0/address_index.
+func (w *Wallet) XPub() (string, error) {
+	switch w.Type() {
+	case WalletTypeBip44:
+	case WalletTypeWatchOnly:
+		if w.Meta[metaXPub] == "" {
+			return "", ErrWatchOnlyNoXPub
+		}
+	default:
+		return "", ErrWalletNotBip44
+	}
+	return w.Meta[metaXPub], nil
+}
+
+// IsWatchOnly returns true if the wallet has no secrets and cannot sign transactions
+func (w *Wallet) IsWatchOnly() bool {
+	return w.Type() == WalletTypeWatchOnly
+}
+
+// bip44AccountKey derives the bip44 account node of a bip44 wallet from its seed
+func (w *Wallet) bip44AccountKey() (*bip44.Account, error) {
+	seed, err := bip39.NewSeed(w.seed(), w.seedPassphrase())
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := bip44.NewCoin(seed, w.bip44Coin())
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Account(w.bip44Account())
+}
+
+// defaultBip44CoinType returns the registered bip44 coin type of a coin
+func defaultBip44CoinType(coin CoinType) bip44.CoinType {
+	switch coin {
+	case CoinTypeSkycoin:
+		return bip44.CoinTypeSkycoin
+	case CoinTypeBitcoin:
+		return bip44.CoinTypeBitcoin
+	default:
+		logger.Panicf("Invalid wallet coin type %q", coin)
+		return 0
+	}
+}
+
 func (w *Wallet) coin() CoinType {
 	return CoinType(w.Meta[metaCoin])
 }
@@ -755,6 +1056,13 @@ func (w *Wallet) GenerateAddresses(num uint64) ([]cipher.Addresser, error) {
 		return nil, ErrWalletEncrypted
 	}
 
+	switch w.Type() {
+	case WalletTypeBip44:
+		return w.generateBip44Addresses(num)
+	case WalletTypeWatchOnly:
+		return w.generateXPubAddresses(num)
+	}
+
 	var seckeys []cipher.SecKey
 	var seed []byte
 	if len(w.Entries) == 0 {
@@ -784,6 +1092,105 @@ func (w *Wallet) GenerateAddresses(num uint64) ([]cipher.Addresser, error) {
 	return addrs, nil
 }
 
+// generateBip44Addresses generates addresses on the external chain of a bip44 wallet's account
+func (w *Wallet) generateBip44Addresses(num uint64) ([]cipher.Addresser, error) {
+	account, err := w.bip44AccountKey()
+	if err != nil {
+		return nil, err
+	}
+
+	external, err := account.External()
+	if err != nil {
+		return nil, err
+	}
+
+	return w.generateChildAddresses(num, func(childNumber uint32) (cipher.PubKey, cipher.SecKey, error) {
+		k, err := external.NewPrivateChildKey(childNumber)
+		if err != nil {
+			return cipher.PubKey{}, cipher.SecKey{}, err
+		}
+
+		s, err := cipher.NewSecKey(k.Key)
+		if err != nil {
+			return cipher.PubKey{}, cipher.SecKey{}, err
+		}
+
+		return cipher.MustPubKeyFromSecKey(s), s, nil
+	})
+}
+
+// generateXPubAddresses generates addresses on the external chain of a watch-only wallet's xpub
+func (w *Wallet) generateXPubAddresses(num uint64) ([]cipher.Addresser, error) {
+	xpub := w.Meta[metaXPub]
+	if xpub == "" {
+		return nil, ErrWatchOnlyNoXPub
+	}
+
+	account, err := bip32.DeserializeEncodedPublicKey(xpub)
+	if err != nil {
+		return nil, err
+	}
+
+	external, err := account.NewPublicChildKey(bip44.ExternalChainIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.generateChildAddresses(num, func(childNumber uint32) (cipher.PubKey, cipher.SecKey, error) {
+		k, err := external.NewPublicChildKey(childNumber)
+		if err != nil {
+			return cipher.PubKey{}, cipher.SecKey{}, err
+		}
+
+		p, err := cipher.NewPubKey(k.Key)
+		if err != nil {
+			return cipher.PubKey{}, cipher.SecKey{}, err
+		}
+
+		return p, cipher.SecKey{}, nil
+	})
+}
+
+// generateChildAddresses generates addresses from the keys derived for consecutive child numbers,
+// continuing from the child number after the last entry
+func (w *Wallet) generateChildAddresses(num uint64, derive func(childNumber uint32) (cipher.PubKey, cipher.SecKey, error)) ([]cipher.Addresser, error) {
+	var childNumber uint32
+	if len(w.Entries) > 0 {
+		childNumber = w.Entries[len(w.Entries)-1].ChildNumber + 1
+	}
+
+	addrs := make([]cipher.Addresser, 0, num)
+	makeAddress := w.addressConstructor()
+	for uint64(len(addrs)) < num {
+		if childNumber >= bip32.FirstHardenedChild {
+			return nil, errors.New("wallet has no more addresses available")
+		}
+
+		p, s, err := derive(childNumber)
+		if err != nil {
+			// Child numbers that cannot produce a valid key are skipped, as specified by bip32
+			if bip32.IsImpossibleChildError(err) {
+				childNumber++
+				continue
+			}
+			return nil, err
+		}
+
+		a := makeAddress(p)
+		addrs = append(addrs, a)
+		w.Entries = append(w.Entries, Entry{
+			Address:     a,
+			Secret:      s,
+			Public:      p,
+			ChildNumber: childNumber,
+		})
+
+		childNumber++
+	}
+
+	return addrs, nil
+}
+
 // GenerateSkycoinAddresses generates Skycoin addresses. If the wallet's coin type is not Skycoin, returns an error
 func (w *Wallet) GenerateSkycoinAddresses(num uint64) ([]cipher.Address, error) {
 	if w.coin() != CoinTypeSkycoin {
@@ -870,6 +1277,70 @@ func (w *Wallet) ScanAddresses(scanN uint64, bg BalanceGetter) (uint64, error) {
 	return nAddAddrs, nil
 }
 
+// ScanUsedAddresses scans the external chain of a bip44 or xpub watch-only wallet for addresses used in any transaction,
+// generating addresses until gapLimit consecutive addresses after the last used address are unused.
+// The wallet keeps the addresses up to the last used address. Returns the number of addresses added.
+func (w *Wallet) ScanUsedAddresses(gapLimit uint64, tf TransactionsFinder) (uint64, error) {
+	if w.IsEncrypted() {
+		return 0, ErrWalletEncrypted
+	}
+
+	switch w.Type() {
+	case WalletTypeBip44:
+	case WalletTypeWatchOnly:
+		if w.Meta[metaXPub] == "" {
+			return 0, ErrWatchOnlyNoXPub
+		}
+	default:
+		return 0, ErrWalletNotBip44
+	}
+
+	if gapLimit == 0 {
+		return 0, nil
+	}
+
+	w2 := w.clone()
+
+	nExistingAddrs := len(w2.Entries)
+	keepNum := nExistingAddrs
+
+	for {
+		// Generate the addresses to scan, beyond the last used address
+		addrs, err := w2.GenerateSkycoinAddresses(gapLimit)
+		if err != nil {
+			return 0, err
+		}
+
+		active, err := tf.AddressesActivity(addrs)
+		if err != nil {
+			return 0, err
+		}
+
+		lastUsed := -1
+		for i := len(active) - 1; i >= 0; i-- {
+			if active[i] {
+				lastUsed = i
+				break
+			}
+		}
+
+		if lastUsed == -1 {
+			break
+		}
+
+		keepNum = len(w2.Entries) - len(addrs) + lastUsed + 1
+		w2.Entries = w2.Entries[:keepNum]
+	}
+
+	// The addresses are derived from their entry's child number,
+	// so the unused addresses can be dropped without regenerating the wallet
+	w2.Entries = w2.Entries[:keepNum]
+
+	*w = *w2
+
+	return uint64(keepNum - nExistingAddrs), nil
+}
+
 // GetAddresses returns all addresses in wallet
 func (w *Wallet) GetAddresses() []cipher.Addresser {
 	addrs := make([]cipher.Addresser, len(w.Entries))
@@ -7,13 +7,19 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/SkycoinProject/cx-chains/src/cipher"
+	"github.com/SkycoinProject/cx-chains/src/cipher/bip32"
+	"github.com/SkycoinProject/cx-chains/src/cipher/bip39"
+	"github.com/SkycoinProject/cx-chains/src/cipher/bip44"
 	"github.com/SkycoinProject/cx-chains/src/cipher/encrypt"
+	"github.com/SkycoinProject/cx-chains/src/coin"
 	"github.com/SkycoinProject/cx-chains/src/util/logging"
 )
 
@@ -31,8 +37,6 @@ var _ = func() int64 {
 var u = flag.Bool("u", false, "update test wallet file in ./testdata")
 
 func init() {
-	flag.Parse()
-
 	// Change the scrypt N value in cryptoTable to make test faster, otherwise
 	// it would take more than 200 seconds to finish.
 	cryptoTable[CryptoTypeScryptChacha20poly1305] = encrypt.ScryptChacha20poly1305{
@@ -41,6 +45,16 @@ func init() {
 		P:      encrypt.ScryptP,
 		KeyLen: encrypt.ScryptKeyLen,
 	}
+	cryptoTable[CryptoTypeScryptAES256GCM] = encrypt.ScryptAES256GCM{
+		N:      1 << 15,
+		R:      encrypt.ScryptR,
+		P:      encrypt.ScryptP,
+		KeyLen: encrypt.ScryptKeyLen,
+	}
+}
+
+func TestMain(m *testing.M) {
+	flag.Parse()
 
 	// When -u flag is specified, update the following wallet files:
 	//     - ./testdata/scrypt-chacha20poly1305-encrypted.wlt
@@ -87,6 +101,8 @@ func init() {
 			log.Panic(err)
 		}
 	}
+
+	os.Exit(m.Run())
 }
 
 type mockBalanceGetter map[cipher.Address]BalancePair
@@ -465,6 +481,52 @@ func TestLockAndUnLock(t *testing.T) {
 	}
 }
 
+// recordingCryptor records the unencrypted data it encrypts and decrypts
+type recordingCryptor struct {
+	cryptor
+	plaintexts [][]byte
+}
+
+func (c *recordingCryptor) Encrypt(data, password []byte) ([]byte, error) {
+	c.plaintexts = append(c.plaintexts, data)
+	return c.cryptor.Encrypt(data, password)
+}
+
+func (c *recordingCryptor) Decrypt(data, password []byte) ([]byte, error) {
+	b, err := c.cryptor.Decrypt(data, password)
+	c.plaintexts = append(c.plaintexts, b)
+	return b, err
+}
+
+func TestLockAndUnlockWipeSecrets(t *testing.T) {
+	ct := CryptoType("recording")
+	c := &recordingCryptor{
+		cryptor: encrypt.DefaultSha256Xor,
+	}
+	cryptoTable[ct] = c
+	defer delete(cryptoTable, ct)
+
+	w, err := NewWallet("wallet", Options{
+		Label: "wallet",
+		Seed:  "seed",
+	})
+	require.NoError(t, err)
+
+	err = w.Lock([]byte("pwd"), ct)
+	require.NoError(t, err)
+
+	uw, err := w.Unlock([]byte("pwd"))
+	require.NoError(t, err)
+	require.Equal(t, "seed", uw.seed())
+
+	// The serialized secrets are wiped once they have been encrypted or decrypted
+	require.Len(t, c.plaintexts, 2)
+	for _, b := range c.plaintexts {
+		require.NotEmpty(t, b)
+		require.Equal(t, make([]byte, len(b)), b)
+	}
+}
+
 func makeWallet(t *testing.T, opts Options, addrNum uint64) *Wallet { // nolint: unparam
 	// Create an unlocked wallet, then generate addresses, lock if the options.Encrypt is true.
 	preOpts := opts
@@ -552,6 +614,14 @@ func TestLoadWallet(t *testing.T) {
 				err:  fmt.Errorf("invalid wallet no_seed.wlt: seed missing in unencrypted wallet"),
 			},
 		},
+		{
+			"invalid wallet: no public key",
+			"./testdata/invalid_wallets/no_public_key.wlt",
+			expect{
+				meta: map[string]string{},
+				err:  cipher.ErrInvalidLengthPubKey,
+			},
+		},
 		{
 			"version=0.2 encrypted=true crypto=scrypt-chacha20poly1305",
 			"./testdata/scrypt-chacha20poly1305-encrypted.wlt",
@@ -1170,6 +1240,18 @@ func TestWalletValidate(t *testing.T) {
 		"secrets":    "xacsdasdasdasd",
 	}
 
+	goodMetaBip44 := map[string]string{
+		"filename":     "foo.wlt",
+		"type":         WalletTypeBip44,
+		"coin":         string(CoinTypeSkycoin),
+		"encrypted":    "false",
+		"seed":         "fooseed",
+		"lastSeed":     "",
+		"bip44Coin":    "8000",
+		"bip44Account": "0",
+		"xpub":         "fooxpub",
+	}
+
 	copyMap := func(m map[string]string) map[string]string {
 		n := make(map[string]string, len(m))
 		for k, v := range m {
@@ -1250,6 +1332,25 @@ func TestWalletValidate(t *testing.T) {
 			meta: setField(goodMetaEncrypted, metaSecrets, ""),
 			err:  errors.New("wallet is encrypted, but secrets field not set"),
 		},
+		{
+			name: "bip44 coin invalid",
+			meta: setField(goodMetaBip44, metaBip44Coin, "2147483648"),
+			err:  errors.New("bip44Coin field is not valid"),
+		},
+		{
+			name: "bip44 account missing",
+			meta: delField(goodMetaBip44, metaBip44Account),
+			err:  errors.New("bip44Account field is not valid"),
+		},
+		{
+			name: "bip44 xpub missing",
+			meta: delField(goodMetaBip44, metaXPub),
+			err:  errors.New("xpub field not set"),
+		},
+		{
+			name: "valid bip44",
+			meta: goodMetaBip44,
+		},
 		{
 			name: "valid unencrypted",
 			meta: goodMetaUnencrypted,
@@ -1270,3 +1371,444 @@ func TestWalletValidate(t *testing.T) {
 		})
 	}
 }
+
+type mockTransactionsFinder map[cipher.Address]bool
+
+func (mt mockTransactionsFinder) AddressesActivity(addrs []cipher.Address) ([]bool, error) {
+	active := make([]bool, len(addrs))
+	for i, addr := range addrs {
+		active[i] = mt[addr]
+	}
+	return active, nil
+}
+
+const testBip44Seed = "dizzy cigar grant ramp inmate uniform gold success able payment faith practice"
+
+func TestNewBip44Wallet(t *testing.T) {
+	bip44Coin := bip44.CoinTypeBitcoin
+
+	tt := []struct {
+		name    string
+		opts    Options
+		path    string
+		address func(cipher.PubKey) cipher.Addresser
+		err     error
+	}{
+		{
+			name: "skycoin",
+			opts: Options{
+				Type:      WalletTypeBip44,
+				Seed:      testBip44Seed,
+				GenerateN: 3,
+			},
+			path: "m/44'/8000'/0'/0",
+			address: func(p cipher.PubKey) cipher.Addresser {
+				return cipher.AddressFromPubKey(p)
+			},
+		},
+		{
+			name: "skycoin with passphrase, account and coin type",
+			opts: Options{
+				Type:           WalletTypeBip44,
+				Seed:           testBip44Seed,
+				SeedPassphrase: "foo",
+				Bip44Coin:      &bip44Coin,
+				Bip44Account:   3,
+				GenerateN:      3,
+			},
+			path: "m/44'/0'/3'/0",
+			address: func(p cipher.PubKey) cipher.Addresser {
+				return cipher.AddressFromPubKey(p)
+			},
+		},
+		{
+			name: "bitcoin",
+			opts: Options{
+				Type:      WalletTypeBip44,
+				Coin:      CoinTypeBitcoin,
+				Seed:      testBip44Seed,
+				GenerateN: 3,
+			},
+			path: "m/44'/0'/0'/0",
+			address: func(p cipher.PubKey) cipher.Addresser {
+				return cipher.BitcoinAddressFromPubKey(p)
+			},
+		},
+		{
+			name: "seed not a mnemonic",
+			opts: Options{
+				Type: WalletTypeBip44,
+				Seed: "foo",
+			},
+			err: ErrInvalidBip44Seed,
+		},
+		{
+			name: "invalid wallet type",
+			opts: Options{
+				Type: "foo",
+				Seed: testBip44Seed,
+			},
+			err: ErrInvalidWalletType,
+		},
+		{
+			name: "seed passphrase for deterministic wallet",
+			opts: Options{
+				Seed:           testBip44Seed,
+				SeedPassphrase: "foo",
+			},
+			err: ErrSeedPassphraseNotBip44,
+		},
+		{
+			name: "bip44 account for deterministic wallet",
+			opts: Options{
+				Seed:         testBip44Seed,
+				Bip44Account: 1,
+			},
+			err: ErrBip44OptionsNotBip44,
+		},
+		{
+			name: "scan without transactions finder",
+			opts: Options{
+				Type:  WalletTypeBip44,
+				Seed:  testBip44Seed,
+				ScanN: 5,
+			},
+			err: ErrNilTransactionsFinder,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := NewWallet("test.wlt", tc.opts)
+			require.Equal(t, tc.err, err)
+			if tc.err != nil {
+				return
+			}
+
+			require.NoError(t, w.Validate())
+			require.Equal(t, WalletTypeBip44, w.Type())
+			require.Empty(t, w.lastSeed())
+			require.Len(t, w.Entries, int(tc.opts.GenerateN))
+
+			seed, err := bip39.NewSeed(tc.opts.Seed, tc.opts.SeedPassphrase)
+			require.NoError(t, err)
+			external, err := bip32.NewPrivateKeyFromPath(seed, tc.path)
+			require.NoError(t, err)
+
+			for i, e := range w.Entries {
+				require.Equal(t, uint32(i), e.ChildNumber)
+
+				k, err := external.NewPrivateChildKey(uint32(i))
+				require.NoError(t, err)
+				require.Equal(t, k.Key, e.Secret[:])
+				require.Equal(t, tc.address(e.Public), e.Address)
+			}
+
+			// The xpub derives the same addresses as the wallet's external chain
+			xpub, err := w.XPub()
+			require.NoError(t, err)
+			accountPub, err := bip32.DeserializeEncodedPublicKey(xpub)
+			require.NoError(t, err)
+			externalPub, err := accountPub.NewPublicChildKey(bip44.ExternalChainIndex)
+			require.NoError(t, err)
+			for i, e := range w.Entries {
+				k, err := externalPub.NewPublicChildKey(uint32(i))
+				require.NoError(t, err)
+				require.Equal(t, k.Key, e.Public[:])
+			}
+
+			// Generating more addresses continues from the last child number
+			_, err = w.GenerateAddresses(2)
+			require.NoError(t, err)
+			require.Len(t, w.Entries, int(tc.opts.GenerateN)+2)
+			require.Equal(t, tc.opts.GenerateN+1, uint64(w.Entries[len(w.Entries)-1].ChildNumber))
+
+			// The seed passphrase is encrypted with the seed
+			entries := append([]Entry{}, w.Entries...)
+			err = w.Lock([]byte("pwd"), CryptoTypeSha256Xor)
+			require.NoError(t, err)
+			require.Empty(t, w.seedPassphrase())
+			require.NoError(t, w.Validate())
+
+			w2, err := w.Unlock([]byte("pwd"))
+			require.NoError(t, err)
+			require.Equal(t, tc.opts.SeedPassphrase, w2.seedPassphrase())
+			require.Equal(t, entries, w2.Entries)
+
+			// Child numbers are preserved when saving and loading
+			dir := prepareWltDir()
+			err = w2.Save(dir)
+			require.NoError(t, err)
+			w3, err := Load(filepath.Join(dir, w2.Filename()))
+			require.NoError(t, err)
+			require.Equal(t, entries, w3.Entries)
+		})
+	}
+}
+
+func TestWalletScanUsedAddresses(t *testing.T) {
+	w, err := NewWallet("test.wlt", Options{
+		Type:      WalletTypeBip44,
+		Seed:      testBip44Seed,
+		GenerateN: 30,
+	})
+	require.NoError(t, err)
+	addrs, err := w.GetSkycoinAddresses()
+	require.NoError(t, err)
+
+	tt := []struct {
+		name      string
+		used      []int
+		gapLimit  uint64
+		generateN uint64
+		expectN   uint64
+	}{
+		{
+			name:     "no used addresses",
+			gapLimit: 5,
+			expectN:  0,
+		},
+		{
+			name:     "used address in the first gap",
+			used:     []int{3},
+			gapLimit: 5,
+			expectN:  3,
+		},
+		{
+			name:     "used addresses across gaps",
+			used:     []int{3, 7, 12},
+			gapLimit: 5,
+			expectN:  12,
+		},
+		{
+			name:     "used address beyond the gap limit",
+			used:     []int{3, 10},
+			gapLimit: 5,
+			expectN:  3,
+		},
+		{
+			name:      "continues from existing addresses",
+			used:      []int{3, 7},
+			gapLimit:  5,
+			generateN: 5,
+			expectN:   3,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			generateN := tc.generateN
+			if generateN == 0 {
+				generateN = 1
+			}
+
+			w, err := NewWallet("test.wlt", Options{
+				Type:      WalletTypeBip44,
+				Seed:      testBip44Seed,
+				GenerateN: generateN,
+			})
+			require.NoError(t, err)
+
+			tf := make(mockTransactionsFinder)
+			for _, i := range tc.used {
+				tf[addrs[i]] = true
+			}
+
+			n, err := w.ScanUsedAddresses(tc.gapLimit, tf)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectN, n)
+			require.Len(t, w.Entries, int(generateN+tc.expectN))
+
+			for i, e := range w.Entries {
+				require.Equal(t, addrs[i], e.SkycoinAddress())
+			}
+		})
+	}
+
+	_, err = makeWallet(t, Options{Seed: "seed"}, 1).ScanUsedAddresses(5, mockTransactionsFinder{})
+	require.Equal(t, ErrWalletNotBip44, err)
+}
+
+func TestNewWatchOnlyWallet(t *testing.T) {
+	bip44Wlt, err := NewWallet("test.wlt", Options{
+		Type:      WalletTypeBip44,
+		Seed:      testBip44Seed,
+		GenerateN: 5,
+	})
+	require.NoError(t, err)
+	xpub, err := bip44Wlt.XPub()
+	require.NoError(t, err)
+	addrs := bip44Wlt.GetAddresses()
+
+	btcAddr := cipher.BitcoinAddressFromPubKey(bip44Wlt.Entries[0].Public)
+
+	tt := []struct {
+		name    string
+		opts    Options
+		tf      TransactionsFinder
+		expectN int
+		err     error
+	}{
+		{
+			name: "xpub",
+			opts: Options{
+				Type:      WalletTypeWatchOnly,
+				XPub:      xpub,
+				GenerateN: 3,
+			},
+			expectN: 3,
+		},
+		{
+			name: "xpub scan ahead",
+			opts: Options{
+				Type:  WalletTypeWatchOnly,
+				XPub:  xpub,
+				ScanN: 2,
+			},
+			tf:      mockTransactionsFinder{addrs[2].(cipher.Address): true},
+			expectN: 3,
+		},
+		{
+			name: "addresses",
+			opts: Options{
+				Type:      WalletTypeWatchOnly,
+				Addresses: addrs[:4],
+			},
+			expectN: 4,
+		},
+		{
+			name: "addresses with child numbers",
+			opts: Options{
+				Type:         WalletTypeWatchOnly,
+				Addresses:    addrs[:2],
+				ChildNumbers: []uint32{7, 3},
+			},
+			expectN: 2,
+		},
+		{
+			name: "child numbers mismatch",
+			opts: Options{
+				Type:         WalletTypeWatchOnly,
+				Addresses:    addrs[:2],
+				ChildNumbers: []uint32{7},
+			},
+			err: NewError(errors.New("the number of child numbers must match the number of addresses")),
+		},
+		{
+			name: "seed",
+			opts: Options{
+				Type: WalletTypeWatchOnly,
+				Seed: testBip44Seed,
+				XPub: xpub,
+			},
+			err: ErrWatchOnlySecrets,
+		},
+		{
+			name: "encrypt",
+			opts: Options{
+				Type:     WalletTypeWatchOnly,
+				XPub:     xpub,
+				Encrypt:  true,
+				Password: []byte("pwd"),
+			},
+			err: ErrWatchOnlySecrets,
+		},
+		{
+			name: "no xpub or addresses",
+			opts: Options{
+				Type: WalletTypeWatchOnly,
+			},
+			err: ErrMissingWatchOnlyAddresses,
+		},
+		{
+			name: "both xpub and addresses",
+			opts: Options{
+				Type:      WalletTypeWatchOnly,
+				XPub:      xpub,
+				Addresses: addrs[:1],
+			},
+			err: ErrMissingWatchOnlyAddresses,
+		},
+		{
+			name: "duplicate addresses",
+			opts: Options{
+				Type:      WalletTypeWatchOnly,
+				Addresses: []cipher.Addresser{addrs[0], addrs[0]},
+			},
+			err: NewError(errors.New("duplicate address entry")),
+		},
+		{
+			name: "address of another coin",
+			opts: Options{
+				Type:      WalletTypeWatchOnly,
+				Addresses: []cipher.Addresser{btcAddr},
+			},
+			err: NewError(fmt.Errorf("address %s is not a skycoin address", btcAddr)),
+		},
+		{
+			name: "xpub for a seeded wallet",
+			opts: Options{
+				Seed: testBip44Seed,
+				XPub: xpub,
+			},
+			err: NewError(errors.New("xpub and addresses are only supported by watch-only wallets")),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := NewWalletScanAhead("test.wlt", tc.opts, nil, tc.tf)
+			require.Equal(t, tc.err, err)
+			if tc.err != nil {
+				return
+			}
+
+			require.NoError(t, w.Validate())
+			require.True(t, w.IsWatchOnly())
+			require.Empty(t, w.seed())
+			require.Empty(t, w.lastSeed())
+			require.Len(t, w.Entries, tc.expectN)
+
+			// The watched addresses are the bip44 wallet's addresses
+			for i, e := range w.Entries {
+				require.Equal(t, addrs[i], e.Address)
+				require.True(t, e.Secret.Null())
+				if len(tc.opts.ChildNumbers) != 0 {
+					require.Equal(t, tc.opts.ChildNumbers[i], e.ChildNumber)
+				}
+				if tc.opts.XPub != "" {
+					require.Equal(t, bip44Wlt.Entries[i].Public, e.Public)
+				} else {
+					require.True(t, e.Public.Null())
+				}
+			}
+
+			if tc.opts.XPub != "" {
+				x, err := w.XPub()
+				require.NoError(t, err)
+				require.Equal(t, xpub, x)
+			} else {
+				_, err := w.XPub()
+				require.Equal(t, ErrWatchOnlyNoXPub, err)
+				_, err = w.GenerateAddresses(1)
+				require.Equal(t, ErrWatchOnlyNoXPub, err)
+			}
+
+			// Watch-only wallets cannot be encrypted or sign
+			err = w.Lock([]byte("pwd"), CryptoTypeSha256Xor)
+			require.Equal(t, ErrWalletWatchOnly, err)
+
+			_, err = w.SignTransaction(&coin.Transaction{}, nil, nil)
+			require.Equal(t, ErrWalletWatchOnly, err)
+
+			// Watch-only wallets are preserved when saving and loading
+			dir := prepareWltDir()
+			err = w.Save(dir)
+			require.NoError(t, err)
+			w2, err := Load(filepath.Join(dir, w.Filename()))
+			require.NoError(t, err)
+			require.Equal(t, w.Entries, w2.Entries)
+			require.True(t, w2.IsWatchOnly())
+		})
+	}
+}